@@ -0,0 +1,232 @@
+// Package agenthooks receives telemetry from the agent CLIs' own hook
+// systems. Hooks installed into Claude Code, Gemini CLI and Codex run
+// 'ntm hooks agent emit', which normalizes the hook payload into an Event and
+// posts it to a per-session Unix socket served by ntm. Events carry exact
+// turn boundaries, tool calls, shell commands with exit codes, edited files
+// and permission prompts, so consumers need not infer them from pane output.
+package agenthooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Kind is the normalized type of a hook event.
+type Kind string
+
+const (
+	KindSessionStart Kind = "session_start" // Agent CLI started or resumed a session
+	KindTurnStart    Kind = "turn_start"    // A prompt was submitted
+	KindToolStart    Kind = "tool_start"    // A tool call is about to run
+	KindToolEnd      Kind = "tool_end"      // A tool call finished
+	KindTurnEnd      Kind = "turn_end"      // The agent finished responding
+	KindPermission   Kind = "permission"    // The agent is waiting for a permission decision
+	KindNotification Kind = "notification"  // Any other notification (e.g. waiting for input)
+)
+
+// Event is one compact hook event as sent over the socket.
+type Event struct {
+	Time      time.Time `json:"ts"`
+	Session   string    `json:"session"`
+	Pane      string    `json:"pane,omitempty"` // tmux pane ID from $TMUX_PANE
+	Agent     string    `json:"agent"`          // cc, cod, gmi
+	Kind      Kind      `json:"kind"`
+	Hook      string    `json:"hook,omitempty"` // Agent-specific hook name (e.g. "PreToolUse")
+	Tool      string    `json:"tool,omitempty"`
+	ToolUseID string    `json:"tool_use_id,omitempty"`
+	Command   string    `json:"command,omitempty"`
+	ExitCode  *int      `json:"exit_code,omitempty"`
+	Files     []string  `json:"files,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	CWD       string    `json:"cwd,omitempty"`
+}
+
+// maxMessageLen bounds free-text fields so events stay compact.
+const maxMessageLen = 500
+
+// shellTools are the tool names agents use for shell commands.
+var shellTools = map[string]bool{
+	"Bash":              true, // Claude Code
+	"run_shell_command": true, // Gemini CLI
+	"shell":             true, // Codex
+}
+
+// editTools are the tool names agents use to create or modify files.
+var editTools = map[string]bool{
+	"Edit":         true,
+	"MultiEdit":    true,
+	"Write":        true,
+	"NotebookEdit": true,
+	"write_file":   true,
+	"replace":      true,
+}
+
+// IsShellTool reports whether tool runs shell commands.
+func IsShellTool(tool string) bool { return shellTools[tool] }
+
+// IsEditTool reports whether tool edits files.
+func IsEditTool(tool string) bool { return editTools[tool] }
+
+// hookKinds maps Claude Code and Gemini CLI hook names to event kinds.
+// Notification is classified from its payload.
+var hookKinds = map[string]Kind{
+	"SessionStart":     KindSessionStart,
+	"UserPromptSubmit": KindTurnStart,
+	"PreToolUse":       KindToolStart,
+	"PostToolUse":      KindToolEnd,
+	"Stop":             KindTurnEnd,
+	"BeforeAgent":      KindTurnStart,
+	"BeforeTool":       KindToolStart,
+	"AfterTool":        KindToolEnd,
+	"AfterAgent":       KindTurnEnd,
+}
+
+// hookPayload is the union of the Claude Code and Gemini CLI hook inputs.
+type hookPayload struct {
+	HookEventName    string          `json:"hook_event_name"`
+	CWD              string          `json:"cwd"`
+	ToolName         string          `json:"tool_name"`
+	ToolUseID        string          `json:"tool_use_id"`
+	ToolInput        json.RawMessage `json:"tool_input"`
+	ToolResponse     json.RawMessage `json:"tool_response"`
+	Message          string          `json:"message"`
+	NotificationType string          `json:"notification_type"`
+}
+
+// codexPayload is the JSON Codex passes to its notify program.
+type codexPayload struct {
+	Type                 string `json:"type"`
+	CWD                  string `json:"cwd"`
+	LastAssistantMessage string `json:"last-assistant-message"`
+}
+
+// Parse normalizes a hook payload from the given agent type into an Event.
+// Session, Pane and Time are left for the caller to fill in.
+func Parse(agent string, payload []byte) (*Event, error) {
+	if agent == "cod" {
+		return parseCodex(payload)
+	}
+
+	var p hookPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("parsing %s hook payload: %w", agent, err)
+	}
+	ev := &Event{Agent: agent, Hook: p.HookEventName, CWD: p.CWD, Tool: p.ToolName, ToolUseID: p.ToolUseID}
+
+	if p.HookEventName == "Notification" {
+		ev.Kind = KindNotification
+		ev.Message = truncate(p.Message)
+		if strings.Contains(strings.ToLower(p.NotificationType+" "+p.Message), "permission") {
+			ev.Kind = KindPermission
+		}
+		return ev, nil
+	}
+	kind, ok := hookKinds[p.HookEventName]
+	if !ok {
+		return nil, fmt.Errorf("unsupported %s hook %q", agent, p.HookEventName)
+	}
+	ev.Kind = kind
+
+	if ev.Tool != "" && len(p.ToolInput) > 0 {
+		var input map[string]interface{}
+		if err := json.Unmarshal(p.ToolInput, &input); err == nil {
+			if IsShellTool(ev.Tool) {
+				ev.Command = truncate(stringField(input, "command"))
+			}
+			if IsEditTool(ev.Tool) {
+				for _, key := range []string{"file_path", "notebook_path"} {
+					if path := stringField(input, key); path != "" {
+						ev.Files = append(ev.Files, path)
+					}
+				}
+			}
+		}
+	}
+	if kind == KindToolEnd && IsShellTool(ev.Tool) {
+		ev.ExitCode = exitCode(p.ToolResponse)
+	}
+	return ev, nil
+}
+
+func parseCodex(payload []byte) (*Event, error) {
+	var p codexPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("parsing cod notify payload: %w", err)
+	}
+	if p.Type != "agent-turn-complete" {
+		return nil, fmt.Errorf("unsupported cod notification %q", p.Type)
+	}
+	return &Event{Agent: "cod", Kind: KindTurnEnd, Hook: p.Type, CWD: p.CWD, Message: truncate(p.LastAssistantMessage)}, nil
+}
+
+// exitCodePattern matches the "Exit code N" line agents put in failed
+// shell tool output.
+var exitCodePattern = regexp.MustCompile(`(?i)exit code:?\s*(-?\d+)`)
+
+// exitCode extracts a shell tool's exit code from its response. Agents that
+// only fire post-tool hooks on success and report no code count as 0; an
+// interrupted command has no exit code.
+func exitCode(response json.RawMessage) *int {
+	if len(response) == 0 {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(response, &text); err == nil {
+		return matchExitCode(text)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(response, &fields); err != nil {
+		return nil
+	}
+	for _, key := range []string{"exit_code", "exitCode", "returnCode", "return_code"} {
+		if n, ok := fields[key].(float64); ok {
+			code := int(n)
+			return &code
+		}
+	}
+	if interrupted, _ := fields["interrupted"].(bool); interrupted {
+		return nil
+	}
+	for _, key := range []string{"error", "stderr", "output"} {
+		if code := matchExitCode(stringField(fields, key)); code != nil {
+			return code
+		}
+	}
+	code := 0
+	return &code
+}
+
+func matchExitCode(text string) *int {
+	m := exitCodePattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	code, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil
+	}
+	return &code
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func truncate(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= maxMessageLen {
+		return s
+	}
+	cut := maxMessageLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
@@ -0,0 +1,149 @@
+package agenthooks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseClaude(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		kind    Kind
+		check   func(t *testing.T, ev *Event)
+	}{
+		{
+			name:    "prompt submitted",
+			payload: `{"hook_event_name":"UserPromptSubmit","cwd":"/repo","prompt":"fix it"}`,
+			kind:    KindTurnStart,
+		},
+		{
+			name:    "bash pre tool use",
+			payload: `{"hook_event_name":"PreToolUse","tool_name":"Bash","tool_use_id":"toolu_1","tool_input":{"command":"go test ./..."}}`,
+			kind:    KindToolStart,
+			check: func(t *testing.T, ev *Event) {
+				if ev.Command != "go test ./..." || ev.ToolUseID != "toolu_1" || ev.ExitCode != nil {
+					t.Errorf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name:    "bash success",
+			payload: `{"hook_event_name":"PostToolUse","tool_name":"Bash","tool_input":{"command":"ls"},"tool_response":{"stdout":"a\n","stderr":"","interrupted":false}}`,
+			kind:    KindToolEnd,
+			check: func(t *testing.T, ev *Event) {
+				if ev.ExitCode == nil || *ev.ExitCode != 0 {
+					t.Errorf("exit code = %v, want 0", ev.ExitCode)
+				}
+			},
+		},
+		{
+			name:    "bash failure text",
+			payload: `{"hook_event_name":"PostToolUse","tool_name":"Bash","tool_input":{"command":"false"},"tool_response":"Error: Exit code 2\nboom"}`,
+			kind:    KindToolEnd,
+			check: func(t *testing.T, ev *Event) {
+				if ev.ExitCode == nil || *ev.ExitCode != 2 {
+					t.Errorf("exit code = %v, want 2", ev.ExitCode)
+				}
+			},
+		},
+		{
+			name:    "bash interrupted",
+			payload: `{"hook_event_name":"PostToolUse","tool_name":"Bash","tool_input":{"command":"sleep 100"},"tool_response":{"interrupted":true}}`,
+			kind:    KindToolEnd,
+			check: func(t *testing.T, ev *Event) {
+				if ev.ExitCode != nil {
+					t.Errorf("exit code = %d, want none", *ev.ExitCode)
+				}
+			},
+		},
+		{
+			name:    "edit",
+			payload: `{"hook_event_name":"PostToolUse","tool_name":"Edit","tool_input":{"file_path":"/repo/main.go","old_string":"a","new_string":"b"}}`,
+			kind:    KindToolEnd,
+			check: func(t *testing.T, ev *Event) {
+				if len(ev.Files) != 1 || ev.Files[0] != "/repo/main.go" || ev.Command != "" {
+					t.Errorf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name:    "permission notification",
+			payload: `{"hook_event_name":"Notification","message":"Claude needs your permission to use Bash"}`,
+			kind:    KindPermission,
+		},
+		{
+			name:    "idle notification",
+			payload: `{"hook_event_name":"Notification","message":"Claude is waiting for your input"}`,
+			kind:    KindNotification,
+		},
+		{
+			name:    "stop",
+			payload: `{"hook_event_name":"Stop","stop_hook_active":false}`,
+			kind:    KindTurnEnd,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, err := Parse("cc", []byte(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ev.Kind != tt.kind || ev.Agent != "cc" {
+				t.Errorf("kind = %s agent = %s, want %s cc", ev.Kind, ev.Agent, tt.kind)
+			}
+			if tt.check != nil {
+				tt.check(t, ev)
+			}
+		})
+	}
+}
+
+func TestParseGemini(t *testing.T) {
+	ev, err := Parse("gmi", []byte(`{"hook_event_name":"AfterTool","tool_name":"run_shell_command","tool_input":{"command":"make"},"tool_response":{"exitCode":3}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Kind != KindToolEnd || ev.Command != "make" || ev.ExitCode == nil || *ev.ExitCode != 3 {
+		t.Errorf("event = %+v", ev)
+	}
+
+	ev, err = Parse("gmi", []byte(`{"hook_event_name":"Notification","notification_type":"ToolPermission","message":"Approve?"}`))
+	if err != nil || ev.Kind != KindPermission {
+		t.Errorf("notification = %+v, %v", ev, err)
+	}
+}
+
+func TestParseCodex(t *testing.T) {
+	ev, err := Parse("cod", []byte(`{"type":"agent-turn-complete","turn-id":"1","cwd":"/repo","last-assistant-message":"Done."}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Kind != KindTurnEnd || ev.Message != "Done." || ev.CWD != "/repo" {
+		t.Errorf("event = %+v", ev)
+	}
+	if _, err := Parse("cod", []byte(`{"type":"something-else"}`)); err == nil {
+		t.Error("unsupported codex notification parsed")
+	}
+}
+
+func TestParseRejectsUnknownHooks(t *testing.T) {
+	if _, err := Parse("cc", []byte(`{"hook_event_name":"PreCompact"}`)); err == nil {
+		t.Error("unknown hook parsed")
+	}
+	if _, err := Parse("cc", []byte(`not json`)); err == nil {
+		t.Error("invalid payload parsed")
+	}
+}
+
+func TestTruncateKeepsUTF8(t *testing.T) {
+	s := truncate(strings.Repeat("é", maxMessageLen))
+	if !strings.HasSuffix(s, "…") || len(s) > maxMessageLen+len("…") {
+		t.Errorf("truncate length = %d", len(s))
+	}
+	for _, r := range strings.TrimSuffix(s, "…") {
+		if r != 'é' {
+			t.Fatalf("truncate split a rune: %q", s)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package agenthooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// EmitMarker identifies hook commands installed by ntm, so reinstalling is
+// idempotent and uninstalling leaves other hooks alone.
+const EmitMarker = "hooks agent emit"
+
+// Target is an agent CLI whose hook configuration ntm manages.
+type Target struct {
+	Agent string   `json:"agent"` // cc, gmi, cod
+	Name  string   `json:"name"`
+	Path  string   `json:"path"`
+	Hooks []string `json:"hooks"` // Hook events registered
+}
+
+// TargetStatus reports a target's installation state.
+type TargetStatus struct {
+	Target
+	Installed bool   `json:"installed"`
+	Changed   bool   `json:"changed,omitempty"` // Set by Install and Uninstall
+	Note      string `json:"note,omitempty"`
+}
+
+// claudeHooks and geminiHooks are the hook events registered per agent.
+// Tool events take a matcher; the others do not.
+var (
+	claudeHooks = []string{"SessionStart", "UserPromptSubmit", "PreToolUse", "PostToolUse", "Notification", "Stop"}
+	geminiHooks = []string{"SessionStart", "BeforeAgent", "BeforeTool", "AfterTool", "Notification", "AfterAgent"}
+	toolHooks   = map[string]bool{"PreToolUse": true, "PostToolUse": true, "BeforeTool": true, "AfterTool": true}
+)
+
+// Targets returns the agent CLIs hooks can be installed into. Codex has no
+// per-tool hooks; its notify program reports turn ends only.
+func Targets() ([]Target, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return []Target{
+		{Agent: "cc", Name: "Claude Code", Path: filepath.Join(home, ".claude", "settings.json"), Hooks: claudeHooks},
+		{Agent: "gmi", Name: "Gemini CLI", Path: filepath.Join(home, ".gemini", "settings.json"), Hooks: geminiHooks},
+		{Agent: "cod", Name: "Codex", Path: filepath.Join(home, ".codex", "config.toml"), Hooks: []string{"notify"}},
+	}, nil
+}
+
+// Install registers emitCommand (e.g. "ntm hooks agent emit") in every
+// target's configuration, adding "--agent <type>". Other settings and hooks
+// are preserved. Targets whose CLI is not set up are skipped.
+func Install(emitCommand string) ([]TargetStatus, error) {
+	return forEachTarget(func(t Target) (TargetStatus, error) {
+		command := emitCommand + " --agent " + t.Agent
+		if t.Agent == "cod" {
+			return installCodex(t, command)
+		}
+		return installJSONHooks(t, command)
+	})
+}
+
+// Uninstall removes ntm's hook commands from every target.
+func Uninstall() ([]TargetStatus, error) {
+	return forEachTarget(func(t Target) (TargetStatus, error) {
+		if t.Agent == "cod" {
+			return uninstallCodex(t)
+		}
+		return uninstallJSONHooks(t)
+	})
+}
+
+// Status reports which targets have ntm's hooks installed.
+func Status() ([]TargetStatus, error) {
+	return forEachTarget(func(t Target) (TargetStatus, error) {
+		st := TargetStatus{Target: t}
+		data, err := os.ReadFile(t.Path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return st, err
+			}
+			st.Note = "not configured"
+			return st, nil
+		}
+		st.Installed = hasMarker(string(data))
+		return st, nil
+	})
+}
+
+func forEachTarget(fn func(Target) (TargetStatus, error)) ([]TargetStatus, error) {
+	targets, err := Targets()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]TargetStatus, 0, len(targets))
+	for _, t := range targets {
+		st, err := fn(t)
+		if err != nil {
+			return results, fmt.Errorf("%s (%s): %w", t.Name, t.Path, err)
+		}
+		results = append(results, st)
+	}
+	return results, nil
+}
+
+// agentConfigured reports whether the agent CLI's config directory exists.
+func agentConfigured(t Target) bool {
+	_, err := os.Stat(filepath.Dir(t.Path))
+	return err == nil
+}
+
+func readSettings(path string) (map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return settings, nil
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("parsing settings: %w", err)
+	}
+	return settings, nil
+}
+
+func writeSettings(path string, settings map[string]interface{}) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// installJSONHooks adds command to each hook event in a Claude Code or
+// Gemini CLI settings file:
+//
+//	"hooks": {"PreToolUse": [{"matcher": "*", "hooks": [{"type": "command", "command": "..."}]}]}
+func installJSONHooks(t Target, command string) (TargetStatus, error) {
+	st := TargetStatus{Target: t}
+	if !agentConfigured(t) {
+		st.Note = "not configured"
+		return st, nil
+	}
+	settings, err := readSettings(t.Path)
+	if err != nil {
+		return st, err
+	}
+	hooks, _ := settings["hooks"].(map[string]interface{})
+	if hooks == nil {
+		hooks = make(map[string]interface{})
+	}
+
+	for _, event := range t.Hooks {
+		groups, _ := hooks[event].([]interface{})
+		if groupsHaveMarker(groups) {
+			continue
+		}
+		group := map[string]interface{}{
+			"hooks": []interface{}{
+				map[string]interface{}{"type": "command", "command": command},
+			},
+		}
+		if toolHooks[event] {
+			group["matcher"] = "*"
+		}
+		hooks[event] = append(groups, group)
+		st.Changed = true
+	}
+	st.Installed = true
+	if !st.Changed {
+		return st, nil
+	}
+	settings["hooks"] = hooks
+	return st, writeSettings(t.Path, settings)
+}
+
+func uninstallJSONHooks(t Target) (TargetStatus, error) {
+	st := TargetStatus{Target: t}
+	if _, err := os.Stat(t.Path); err != nil {
+		return st, nil
+	}
+	settings, err := readSettings(t.Path)
+	if err != nil {
+		return st, err
+	}
+	hooks, _ := settings["hooks"].(map[string]interface{})
+	for event, v := range hooks {
+		groups, _ := v.([]interface{})
+		var kept []interface{}
+		for _, g := range groups {
+			group, _ := g.(map[string]interface{})
+			entries, _ := group["hooks"].([]interface{})
+			var keptEntries []interface{}
+			for _, e := range entries {
+				if entryHasMarker(e) {
+					st.Changed = true
+					continue
+				}
+				keptEntries = append(keptEntries, e)
+			}
+			if len(keptEntries) == 0 && len(entries) > 0 {
+				continue
+			}
+			if group != nil {
+				group["hooks"] = keptEntries
+			}
+			kept = append(kept, g)
+		}
+		if len(kept) == 0 {
+			delete(hooks, event)
+		} else {
+			hooks[event] = kept
+		}
+	}
+	if !st.Changed {
+		return st, nil
+	}
+	if len(hooks) == 0 {
+		delete(settings, "hooks")
+	}
+	return st, writeSettings(t.Path, settings)
+}
+
+func groupsHaveMarker(groups []interface{}) bool {
+	for _, g := range groups {
+		group, _ := g.(map[string]interface{})
+		entries, _ := group["hooks"].([]interface{})
+		for _, e := range entries {
+			if entryHasMarker(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func entryHasMarker(e interface{}) bool {
+	entry, _ := e.(map[string]interface{})
+	command, _ := entry["command"].(string)
+	return strings.Contains(command, EmitMarker)
+}
+
+// hasMarker reports whether text contains ntm's emit command, either as a
+// shell command or as a TOML argument array.
+func hasMarker(text string) bool {
+	return strings.Contains(strings.NewReplacer(`", "`, " ", `","`, " ").Replace(text), EmitMarker)
+}
+
+// installCodex sets Codex's top-level notify program. An existing notify
+// setting that is not ntm's is left alone.
+func installCodex(t Target, command string) (TargetStatus, error) {
+	st := TargetStatus{Target: t}
+	if !agentConfigured(t) {
+		st.Note = "not configured"
+		return st, nil
+	}
+	data, err := os.ReadFile(t.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return st, err
+	}
+	lines := strings.Split(string(data), "\n")
+	if i := codexNotifyLine(lines); i >= 0 {
+		st.Installed = hasMarker(lines[i])
+		if !st.Installed {
+			st.Note = "notify is already set to another program; not replaced"
+		}
+		return st, nil
+	}
+
+	args := strings.Fields(command)
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = strconv.Quote(a)
+	}
+	line := "notify = [" + strings.Join(quoted, ", ") + "]"
+	// Top-level keys must precede the first table.
+	content := line + "\n"
+	if len(data) > 0 {
+		content += string(data)
+	}
+	if err := os.WriteFile(t.Path, []byte(content), 0600); err != nil {
+		return st, err
+	}
+	st.Installed, st.Changed = true, true
+	return st, nil
+}
+
+func uninstallCodex(t Target) (TargetStatus, error) {
+	st := TargetStatus{Target: t}
+	data, err := os.ReadFile(t.Path)
+	if err != nil {
+		return st, nil
+	}
+	lines := strings.Split(string(data), "\n")
+	i := codexNotifyLine(lines)
+	if i < 0 || !hasMarker(lines[i]) {
+		return st, nil
+	}
+	lines = append(lines[:i], lines[i+1:]...)
+	st.Changed = true
+	return st, os.WriteFile(t.Path, []byte(strings.Join(lines, "\n")), 0600)
+}
+
+// codexNotifyLine returns the index of the top-level notify key, or -1.
+func codexNotifyLine(lines []string) int {
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			return -1 // Entered a table; notify must be top-level
+		}
+		if key, _, ok := strings.Cut(trimmed, "="); ok && strings.TrimSpace(key) == "notify" {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,128 @@
+package agenthooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallAndUninstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	claude := filepath.Join(home, ".claude", "settings.json")
+	codex := filepath.Join(home, ".codex", "config.toml")
+	for _, dir := range []string{".claude", ".codex"} {
+		if err := os.MkdirAll(filepath.Join(home, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	existing := `{"model":"opus","hooks":{"PreToolUse":[{"matcher":"Bash","hooks":[{"type":"command","command":"~/.claude/hooks/PreToolUse/ntm-safety.sh"}]}]}}`
+	if err := os.WriteFile(claude, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(codex, []byte("model = \"o3\"\n\n[profiles.fast]\nmodel = \"mini\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := Install("ntm hooks agent emit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byAgent := map[string]TargetStatus{}
+	for _, r := range results {
+		byAgent[r.Agent] = r
+	}
+	if !byAgent["cc"].Changed || !byAgent["cod"].Changed {
+		t.Errorf("install results = %+v", results)
+	}
+	if gmi := byAgent["gmi"]; gmi.Installed || gmi.Note == "" {
+		t.Errorf("gemini without config dir = %+v, want skipped", gmi)
+	}
+
+	settings, err := readSettings(claude)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings["model"] != "opus" {
+		t.Error("install dropped unrelated settings")
+	}
+	hooks := settings["hooks"].(map[string]interface{})
+	for _, event := range claudeHooks {
+		if !groupsHaveMarker(hooks[event].([]interface{})) {
+			t.Errorf("%s hook not installed", event)
+		}
+	}
+	if pre := hooks["PreToolUse"].([]interface{}); len(pre) != 2 {
+		t.Errorf("PreToolUse groups = %d, want safety hook kept alongside", len(pre))
+	}
+
+	data, _ := os.ReadFile(codex)
+	if !strings.HasPrefix(string(data), `notify = ["ntm", "hooks", "agent", "emit", "--agent", "cod"]`) {
+		t.Errorf("codex config = %q", data)
+	}
+
+	// Reinstalling is a no-op.
+	results, err = Install("ntm hooks agent emit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Changed {
+			t.Errorf("reinstall changed %s", r.Agent)
+		}
+	}
+
+	if _, err := Uninstall(); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := os.ReadFile(claude)
+	var after map[string]interface{}
+	if err := json.Unmarshal(raw, &after); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), EmitMarker) || !strings.Contains(string(raw), "ntm-safety.sh") {
+		t.Errorf("claude settings after uninstall = %s", raw)
+	}
+	data, _ = os.ReadFile(codex)
+	if strings.Contains(string(data), "notify") || !strings.Contains(string(data), "[profiles.fast]") {
+		t.Errorf("codex config after uninstall = %q", data)
+	}
+
+	statuses, err := Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range statuses {
+		if st.Installed {
+			t.Errorf("%s still installed after uninstall", st.Agent)
+		}
+	}
+}
+
+func TestInstallCodexKeepsForeignNotify(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	codex := filepath.Join(home, ".codex", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(codex), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(codex, []byte("notify = [\"notify-send\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := Install("ntm hooks agent emit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Agent == "cod" && (r.Installed || r.Changed || r.Note == "") {
+			t.Errorf("codex result = %+v, want foreign notify kept", r)
+		}
+	}
+	if data, _ := os.ReadFile(codex); string(data) != "notify = [\"notify-send\"]\n" {
+		t.Errorf("codex config modified: %q", data)
+	}
+}
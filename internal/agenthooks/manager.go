@@ -0,0 +1,115 @@
+package agenthooks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// Manager keeps one listener open per tmux session, opening sockets for new
+// sessions and closing those of sessions that went away.
+type Manager struct {
+	handle   Handler
+	sessions func() ([]string, error)
+
+	mu        sync.Mutex
+	listeners map[string]*Listener
+}
+
+// NewManager creates a manager that dispatches every session's events to
+// handle.
+func NewManager(handle Handler) *Manager {
+	return &Manager{
+		handle:    handle,
+		sessions:  tmuxSessions,
+		listeners: make(map[string]*Listener),
+	}
+}
+
+func tmuxSessions() ([]string, error) {
+	sessions, err := tmux.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
+
+// Run syncs listeners every interval until ctx is cancelled, then closes
+// them all.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	defer m.Close()
+
+	_ = m.Sync()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = m.Sync()
+		}
+	}
+}
+
+// Sync opens listeners for new sessions and closes those of ended sessions,
+// dropping the hook states they left behind. Sessions whose socket is
+// already served by another process are skipped.
+func (m *Manager) Sync() error {
+	names, err := m.sessions()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	live := make(map[string]bool, len(names))
+	for _, name := range names {
+		live[name] = true
+		if _, ok := m.listeners[name]; ok {
+			continue
+		}
+		if l, err := Listen(name, m.handle); err == nil {
+			m.listeners[name] = l
+		}
+	}
+	for name, l := range m.listeners {
+		if !live[name] {
+			_ = l.Close()
+			delete(m.listeners, name)
+		}
+	}
+	return status.PruneHookStates(names)
+}
+
+// Sessions returns the sessions currently being served.
+func (m *Manager) Sessions() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.listeners))
+	for name := range m.listeners {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Close closes all listeners.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, l := range m.listeners {
+		_ = l.Close()
+		delete(m.listeners, name)
+	}
+}
@@ -0,0 +1,220 @@
+package agenthooks
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+// Recorder feeds hook events into the rest of ntm: the hook state used by
+// status detection, state transitions and the command timeline in the state
+// store, the file change tracker, and the events bus.
+type Recorder struct {
+	store     *state.Store // nil skips persistence
+	bus       *events.EventBus
+	files     *tracker.FileChangeStore
+	paneTitle func(paneID string) string
+
+	mu      sync.Mutex
+	states  map[string]status.AgentState // pane -> last hook-reported state
+	pending map[string]Event             // tool call key -> its tool_start
+	titles  map[string]string
+}
+
+// NewRecorder creates a recorder that persists to store (which may be nil)
+// and publishes on the default events bus.
+func NewRecorder(store *state.Store) *Recorder {
+	return &Recorder{
+		store:     store,
+		bus:       events.DefaultBus,
+		files:     tracker.GlobalFileChanges,
+		paneTitle: tmuxPaneTitle,
+		states:    make(map[string]status.AgentState),
+		pending:   make(map[string]Event),
+		titles:    make(map[string]string),
+	}
+}
+
+func tmuxPaneTitle(paneID string) string {
+	title, err := tmux.DefaultClient.Run("display-message", "-p", "-t", paneID, "#{pane_title}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(title)
+}
+
+// StateFor returns the agent state an event implies, or "" if it implies no
+// change.
+func StateFor(ev Event) status.AgentState {
+	switch ev.Kind {
+	case KindSessionStart, KindTurnEnd:
+		return status.StateIdle
+	case KindTurnStart, KindToolStart, KindToolEnd:
+		return status.StateWorking
+	case KindPermission:
+		return status.StateAwaitingApproval
+	case KindNotification:
+		if strings.Contains(strings.ToLower(ev.Message), "waiting for your input") {
+			return status.StateIdle
+		}
+	}
+	return ""
+}
+
+// Handle records one event. It is safe for concurrent use and suitable as a
+// listener Handler.
+func (r *Recorder) Handle(ev Event) {
+	if r.bus != nil {
+		busEvent := events.NewAgentHookEvent(ev.Session, ev.Pane, ev.Agent, string(ev.Kind))
+		busEvent.Timestamp = ev.Time
+		busEvent.Tool = ev.Tool
+		busEvent.Command = ev.Command
+		busEvent.ExitCode = ev.ExitCode
+		busEvent.Files = ev.Files
+		busEvent.Message = ev.Message
+		r.bus.Publish(busEvent)
+	}
+	if ev.Pane == "" {
+		return // Not running in tmux; nothing to attribute the event to
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if st := StateFor(ev); st != "" {
+		r.recordState(ev, st)
+	}
+
+	switch ev.Kind {
+	case KindToolStart:
+		r.pending[toolKey(ev)] = ev
+	case KindToolEnd:
+		key := toolKey(ev)
+		start, ok := r.pending[key]
+		delete(r.pending, key)
+		var startedAt *time.Time
+		if ok {
+			startedAt = &start.Time
+		}
+		r.recordTool(ev, startedAt)
+	case KindTurnEnd, KindSessionStart:
+		// Tool calls that never reported an end failed or were interrupted
+		// (some agents only fire post-tool hooks on success).
+		for key, start := range r.pending {
+			if start.Session != ev.Session || start.Pane != ev.Pane {
+				continue
+			}
+			delete(r.pending, key)
+			started := start.Time
+			end := start
+			end.Kind = KindToolEnd
+			end.Time = ev.Time
+			r.recordTool(end, &started)
+		}
+	}
+}
+
+func (r *Recorder) recordState(ev Event, st status.AgentState) {
+	_ = status.WriteHookState(status.HookState{
+		Session: ev.Session,
+		PaneID:  ev.Pane,
+		Agent:   ev.Agent,
+		State:   st,
+		Event:   string(ev.Kind),
+		Tool:    ev.Tool,
+		Message: ev.Message,
+		At:      ev.Time,
+	})
+
+	key := ev.Session + "\x00" + ev.Pane
+	from, seen := r.states[key]
+	if seen && from == st {
+		return
+	}
+	r.states[key] = st
+	if r.store == nil {
+		return
+	}
+	_ = r.store.RecordStateTransition(&state.AgentStateTransition{
+		SessionID:    ev.Session,
+		PaneID:       ev.Pane,
+		PaneName:     r.title(ev.Pane),
+		AgentType:    ev.Agent,
+		FromState:    string(from),
+		ToState:      string(st),
+		Source:       state.TransitionSourceHook,
+		Trigger:      status.TriggerAgentHook + ":" + string(ev.Kind),
+		Confidence:   0.99,
+		TransitionAt: ev.Time,
+	})
+}
+
+// recordTool adds a finished shell or edit tool call to the command timeline
+// and edited files to the change tracker. Other tools are not recorded.
+func (r *Recorder) recordTool(ev Event, startedAt *time.Time) {
+	if !IsShellTool(ev.Tool) && !IsEditTool(ev.Tool) {
+		return
+	}
+	if r.store != nil {
+		_ = r.store.RecordAgentCommand(&state.AgentCommand{
+			SessionID:  ev.Session,
+			PaneID:     ev.Pane,
+			AgentType:  ev.Agent,
+			Tool:       ev.Tool,
+			Command:    ev.Command,
+			ExitCode:   ev.ExitCode,
+			Files:      ev.Files,
+			StartedAt:  startedAt,
+			FinishedAt: ev.Time,
+		})
+	}
+	if r.files == nil || !IsEditTool(ev.Tool) {
+		return
+	}
+	agent := r.title(ev.Pane)
+	if agent == "" {
+		agent = ev.Agent + "@" + ev.Pane
+	}
+	for _, path := range ev.Files {
+		if ev.CWD != "" && filepath.IsAbs(path) {
+			if rel, err := filepath.Rel(ev.CWD, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+		r.files.Add(tracker.RecordedFileChange{
+			Timestamp: ev.Time,
+			Session:   ev.Session,
+			Agents:    []string{agent},
+			Change:    tracker.FileChange{Path: path, Type: tracker.FileModified},
+		})
+	}
+}
+
+// title returns a pane's title, cached since titles rarely change.
+func (r *Recorder) title(paneID string) string {
+	if t, ok := r.titles[paneID]; ok {
+		return t
+	}
+	t := ""
+	if r.paneTitle != nil {
+		t = r.paneTitle(paneID)
+	}
+	r.titles[paneID] = t
+	return t
+}
+
+// toolKey pairs a tool's start and end events: by tool use ID when the agent
+// reports one, else by pane, tool and input.
+func toolKey(ev Event) string {
+	if ev.ToolUseID != "" {
+		return ev.Session + "\x00" + ev.Pane + "\x00" + ev.ToolUseID
+	}
+	return strings.Join([]string{ev.Session, ev.Pane, ev.Tool, ev.Command, strings.Join(ev.Files, "\n")}, "\x00")
+}
@@ -0,0 +1,149 @@
+package agenthooks
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// sendTimeout bounds how long a hook waits on the socket. Hooks run inline
+// with the agent, so a missing or stuck listener must never stall it.
+const sendTimeout = 300 * time.Millisecond
+
+// maxEventSize bounds one newline-delimited event on the socket.
+const maxEventSize = 64 * 1024
+
+// SocketDir is where per-session sockets live. Tests may replace it.
+var SocketDir = func() string {
+	dir, err := util.NTMDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "ntm-hooks")
+	}
+	return filepath.Join(dir, "hooks", "sockets")
+}
+
+var unsafeSocketChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// SocketPath returns the Unix socket ntm serves hook events on for session.
+func SocketPath(session string) string {
+	return filepath.Join(SocketDir(), unsafeSocketChars.ReplaceAllString(session, "_")+".sock")
+}
+
+// Send posts an event to its session's socket. It fails fast when nobody is
+// listening.
+func Send(ev *Event) error {
+	if ev.Session == "" {
+		return errors.New("event has no session")
+	}
+	conn, err := net.DialTimeout("unix", SocketPath(ev.Session), sendTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_ = conn.SetWriteDeadline(time.Now().Add(sendTimeout))
+
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(append(data, '\n'))
+	return err
+}
+
+// Handler receives events from a listener. It is called from connection
+// goroutines and must be safe for concurrent use.
+type Handler func(Event)
+
+// Listener serves one session's hook socket.
+type Listener struct {
+	session string
+	path    string
+	ln      net.Listener
+	handle  Handler
+	wg      sync.WaitGroup
+}
+
+// Listen opens the hook socket for session and dispatches received events to
+// handle. A stale socket left by a dead listener is replaced; a live one is
+// an error.
+func Listen(session string, handle Handler) (*Listener, error) {
+	path := SocketPath(session)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("creating socket dir: %w", err)
+	}
+	if conn, err := net.DialTimeout("unix", path, sendTimeout); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("hook socket for session %s is already being served (%s)", session, path)
+	}
+	_ = os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("securing %s: %w", path, err)
+	}
+
+	l := &Listener{session: session, path: path, ln: ln, handle: handle}
+	l.wg.Add(1)
+	go l.accept()
+	return l, nil
+}
+
+// Session returns the session the listener serves.
+func (l *Listener) Session() string { return l.session }
+
+// Path returns the socket path.
+func (l *Listener) Path() string { return l.path }
+
+// Close stops accepting events, waits for in-flight connections and removes
+// the socket.
+func (l *Listener) Close() error {
+	err := l.ln.Close()
+	l.wg.Wait()
+	_ = os.Remove(l.path)
+	return err
+}
+
+func (l *Listener) accept() {
+	defer l.wg.Done()
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			return
+		}
+		l.wg.Add(1)
+		go l.serve(conn)
+	}
+}
+
+func (l *Listener) serve(conn net.Conn) {
+	defer l.wg.Done()
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 4096), maxEventSize)
+	for scanner.Scan() {
+		var ev Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil || ev.Kind == "" {
+			continue
+		}
+		ev.Session = l.session
+		if ev.Time.IsZero() {
+			ev.Time = time.Now().UTC()
+		}
+		l.handle(ev)
+	}
+}
@@ -0,0 +1,136 @@
+package agenthooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+// useTempDirs points sockets and hook state at short temporary directories
+// (Unix socket paths are length-limited).
+func useTempDirs(t *testing.T) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "ntmh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	origSockets, origState := SocketDir, status.HookStateDir
+	SocketDir = func() string { return filepath.Join(dir, "s") }
+	status.HookStateDir = func() string { return filepath.Join(dir, "h") }
+	t.Cleanup(func() { SocketDir, status.HookStateDir = origSockets, origState })
+}
+
+func TestSendToListener(t *testing.T) {
+	useTempDirs(t)
+
+	got := make(chan Event, 4)
+	l, err := Listen("my:proj", func(ev Event) { got <- ev })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if _, err := Listen("my:proj", func(Event) {}); err == nil {
+		t.Error("second listener on a live socket succeeded")
+	}
+
+	if err := Send(&Event{Session: "my:proj", Pane: "%3", Agent: "cc", Kind: KindTurnStart}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-got:
+		if ev.Kind != KindTurnStart || ev.Pane != "%3" || ev.Session != "my:proj" || ev.Time.IsZero() {
+			t.Errorf("received %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event received")
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Send(&Event{Session: "my:proj", Kind: KindTurnEnd}); err == nil {
+		t.Error("Send succeeded with no listener")
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	useTempDirs(t)
+
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRecorder(store)
+	r.bus = events.NewEventBus(10)
+	r.files = tracker.NewFileChangeStore(10)
+	r.paneTitle = func(string) string { return "proj__cc_1" }
+
+	base := time.Now().UTC().Truncate(time.Second)
+	exit1 := 1
+	for i, ev := range []Event{
+		{Kind: KindTurnStart},
+		{Kind: KindToolStart, Tool: "Bash", ToolUseID: "t1", Command: "go test ./..."},
+		{Kind: KindToolEnd, Tool: "Bash", ToolUseID: "t1", Command: "go test ./...", ExitCode: &exit1},
+		{Kind: KindToolStart, Tool: "Edit", Files: []string{"/repo/a.go"}, CWD: "/repo"},
+		{Kind: KindToolEnd, Tool: "Edit", Files: []string{"/repo/a.go"}, CWD: "/repo"},
+		{Kind: KindToolStart, Tool: "Bash", ToolUseID: "t2", Command: "make lint"}, // never ends
+		{Kind: KindToolEnd, Tool: "Read"},
+		{Kind: KindTurnEnd},
+	} {
+		ev.Session, ev.Pane, ev.Agent = "proj", "%1", "cc"
+		ev.Time = base.Add(time.Duration(i) * time.Second)
+		r.Handle(ev)
+	}
+
+	commands, err := store.ListAgentCommands(state.AgentCommandQuery{SessionID: "proj"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) != 3 {
+		t.Fatalf("commands = %+v, want test, edit and unfinished lint", commands)
+	}
+	if c := commands[0]; c.Command != "go test ./..." || c.ExitCode == nil || *c.ExitCode != 1 || c.Duration() != time.Second {
+		t.Errorf("test command = %+v", c)
+	}
+	if c := commands[1]; c.Tool != "Edit" || len(c.Files) != 1 {
+		t.Errorf("edit = %+v", c)
+	}
+	if c := commands[2]; c.Command != "make lint" || c.ExitCode != nil || c.StartedAt == nil {
+		t.Errorf("unfinished command = %+v", c)
+	}
+
+	transitions, err := store.ListStateTransitions(state.TransitionQuery{SessionID: "proj", Source: state.TransitionSourceHook})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transitions) != 2 || transitions[0].ToState != "working" || transitions[1].ToState != "idle" || transitions[1].PaneName != "proj__cc_1" {
+		t.Errorf("transitions = %+v", transitions)
+	}
+
+	if hs, ok := status.ReadHookState("proj", "%1"); !ok || hs.State != status.StateIdle || hs.Event != string(KindTurnEnd) {
+		t.Errorf("hook state = %+v, %v", hs, ok)
+	}
+
+	changes := r.files.All()
+	if len(changes) != 1 || changes[0].Change.Path != "a.go" || changes[0].Agents[0] != "proj__cc_1" {
+		t.Errorf("file changes = %+v", changes)
+	}
+
+	if history := r.bus.History(20); len(history) != 8 {
+		t.Errorf("bus history = %d events, want 8", len(history))
+	}
+}
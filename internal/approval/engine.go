@@ -22,6 +22,10 @@ import (
 // DefaultExpiry is the default time before an approval request expires.
 const DefaultExpiry = 24 * time.Hour
 
+// WaitPollInterval is how often WaitForApproval re-reads the store for
+// decisions recorded by other processes.
+const WaitPollInterval = 2 * time.Second
+
 // Config holds configuration for the approval engine.
 type Config struct {
 	// DefaultExpiry is how long approvals stay pending before expiring.
@@ -376,16 +380,28 @@ func (e *Engine) WaitForApproval(ctx context.Context, id string, timeout time.Du
 	waitCtx, cancel := context.WithTimeout(baseCtx, timeout)
 	defer cancel()
 
-	select {
-	case <-waitCh:
-		// Approval was decided
-		return e.Check(baseCtx, id)
-	case <-waitCtx.Done():
-		if baseCtx.Err() != nil {
-			return nil, baseCtx.Err()
+	// Decisions made by another process (e.g. 'ntm approve' while an MCP
+	// server or prompt watcher waits) only show up in the store, so poll it.
+	poll := time.NewTicker(WaitPollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-waitCh:
+			// Approval was decided
+			return e.Check(baseCtx, id)
+		case <-poll.C:
+			approval, err := e.Check(baseCtx, id)
+			if err == nil && approval.Status != state.ApprovalPending {
+				return approval, nil
+			}
+		case <-waitCtx.Done():
+			if baseCtx.Err() != nil {
+				return nil, baseCtx.Err()
+			}
+			// Timeout - check final status
+			return e.Check(baseCtx, id)
 		}
-		// Timeout - check final status
-		return e.Check(baseCtx, id)
 	}
 }
 
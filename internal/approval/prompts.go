@@ -0,0 +1,234 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/notify"
+	"github.com/Dicklesworthstone/ntm/internal/policy"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+)
+
+// DefaultPromptTimeout is how long an escalated agent prompt waits for a decision.
+const DefaultPromptTimeout = 10 * time.Minute
+
+// Prompt response outcomes reported in PromptOutcome.Response.
+const (
+	PromptResponseApproved = "approved" // approve keys were sent
+	PromptResponseDenied   = "denied"   // deny keys were sent
+	PromptResponseNotified = "notified" // left for a human
+	PromptResponseTimeout  = "timeout"  // escalation undecided; left for a human
+	PromptResponseGone     = "gone"     // prompt disappeared before it could be answered
+)
+
+// PromptResponderConfig configures a PromptResponder.
+type PromptResponderConfig struct {
+	// Policy decides how each prompt is answered. Required.
+	Policy *policy.Policy
+
+	// Engine handles escalated prompts. When nil, escalations degrade to notify.
+	Engine *Engine
+
+	// Notifier receives agent.approval events for notify/escalate decisions.
+	Notifier *notify.Notifier
+
+	// SendKeys delivers tmux key names to a pane. Required.
+	SendKeys func(target string, keys ...string) error
+
+	// Capture returns the current pane output. Used to re-verify that an
+	// escalated prompt is still showing before answering it.
+	Capture func(target string) (string, error)
+
+	// Timeout bounds how long escalations wait for a decision.
+	Timeout time.Duration
+
+	// RequestedBy identifies the requester recorded on approval requests.
+	RequestedBy string
+}
+
+// PromptOutcome records how a single agent prompt was handled.
+type PromptOutcome struct {
+	Session    string                 `json:"session"`
+	Pane       string                 `json:"pane"`
+	Prompt     *status.ApprovalPrompt `json:"prompt"`
+	Decision   policy.PromptAction    `json:"decision"`
+	Rule       string                 `json:"rule,omitempty"`
+	Reason     string                 `json:"reason,omitempty"`
+	Response   string                 `json:"response"`
+	ApprovalID string                 `json:"approval_id,omitempty"`
+}
+
+// PromptResponder answers agent confirmation prompts according to policy.
+// Each prompt is handled once; Clear must be called when a pane leaves the
+// awaiting-approval state so an identical later prompt is handled again.
+type PromptResponder struct {
+	cfg     PromptResponderConfig
+	mu      sync.Mutex
+	handled map[string]string // pane -> prompt fingerprint
+}
+
+// NewPromptResponder creates a prompt responder.
+func NewPromptResponder(cfg PromptResponderConfig) *PromptResponder {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultPromptTimeout
+	}
+	if cfg.RequestedBy == "" {
+		cfg.RequestedBy = "ntm"
+	}
+	return &PromptResponder{
+		cfg:     cfg,
+		handled: make(map[string]string),
+	}
+}
+
+// Clear forgets the prompt handled for a pane.
+func (r *PromptResponder) Clear(pane string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.handled, pane)
+}
+
+// Handle applies policy to a prompt shown in pane. It returns nil if the same
+// prompt was already handled for that pane. Escalations block until the
+// approval is decided or the configured timeout expires. A prompt that could
+// not be answered, or whose escalation went undecided, is not remembered, so
+// the next call tries again.
+func (r *PromptResponder) Handle(ctx context.Context, session, pane string, prompt *status.ApprovalPrompt) (outcome *PromptOutcome, err error) {
+	if prompt == nil {
+		return nil, nil
+	}
+	if r.cfg.Policy == nil || r.cfg.SendKeys == nil {
+		return nil, fmt.Errorf("prompt responder requires a policy and a key sender")
+	}
+
+	fp := promptFingerprint(prompt)
+	r.mu.Lock()
+	if r.handled[pane] == fp {
+		r.mu.Unlock()
+		return nil, nil
+	}
+	r.handled[pane] = fp
+	r.mu.Unlock()
+	defer func() {
+		if err != nil || (outcome != nil && outcome.Response == PromptResponseTimeout) {
+			r.forget(pane, fp)
+		}
+	}()
+
+	decision := r.cfg.Policy.CheckPrompt(prompt.AgentType, string(prompt.Kind), prompt.Action)
+	outcome = &PromptOutcome{
+		Session:  session,
+		Pane:     pane,
+		Prompt:   prompt,
+		Decision: decision.Action,
+		Rule:     decision.Rule,
+		Reason:   decision.Reason,
+	}
+
+	switch decision.Action {
+	case policy.PromptApprove:
+		if err := r.cfg.SendKeys(pane, prompt.ApproveKeys...); err != nil {
+			return outcome, fmt.Errorf("answering prompt in %s: %w", pane, err)
+		}
+		outcome.Response = PromptResponseApproved
+	case policy.PromptDeny:
+		if err := r.cfg.SendKeys(pane, prompt.DenyKeys...); err != nil {
+			return outcome, fmt.Errorf("denying prompt in %s: %w", pane, err)
+		}
+		outcome.Response = PromptResponseDenied
+	case policy.PromptEscalate:
+		if r.cfg.Engine == nil {
+			r.notify(session, pane, prompt)
+			outcome.Response = PromptResponseNotified
+			return outcome, nil
+		}
+		return outcome, r.escalate(ctx, outcome, decision)
+	default:
+		r.notify(session, pane, prompt)
+		outcome.Response = PromptResponseNotified
+	}
+	return outcome, nil
+}
+
+// forget clears the prompt handled for pane if it is still fp.
+func (r *PromptResponder) forget(pane, fp string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.handled[pane] == fp {
+		delete(r.handled, pane)
+	}
+}
+
+func (r *PromptResponder) escalate(ctx context.Context, outcome *PromptOutcome, decision *policy.PromptDecision) error {
+	prompt := outcome.Prompt
+	reason := decision.Reason
+	if reason == "" {
+		reason = prompt.Question
+	}
+
+	appr, err := r.cfg.Engine.Request(ctx, RequestParams{
+		Action:        "agent_prompt:" + string(prompt.Kind),
+		Resource:      fmt.Sprintf("%s %s", outcome.Pane, prompt.Action),
+		Reason:        reason,
+		RequestedBy:   r.cfg.RequestedBy,
+		CorrelationID: outcome.Session,
+		RequiresSLB:   decision.SLB,
+		ExpiresIn:     r.cfg.Timeout,
+	})
+	if err != nil {
+		return fmt.Errorf("requesting approval for prompt in %s: %w", outcome.Pane, err)
+	}
+	outcome.ApprovalID = appr.ID
+
+	appr, err = r.cfg.Engine.WaitForApproval(ctx, appr.ID, r.cfg.Timeout)
+	if err != nil {
+		return fmt.Errorf("waiting for approval %s: %w", outcome.ApprovalID, err)
+	}
+
+	var keys []string
+	switch appr.Status {
+	case state.ApprovalApproved:
+		keys = prompt.ApproveKeys
+		outcome.Response = PromptResponseApproved
+	case state.ApprovalDenied:
+		keys = prompt.DenyKeys
+		outcome.Response = PromptResponseDenied
+	default:
+		outcome.Response = PromptResponseTimeout
+		return nil
+	}
+
+	// The human may have answered the pane directly while we waited; only
+	// send keys if the same prompt is still showing.
+	if r.cfg.Capture != nil {
+		out, err := r.cfg.Capture(outcome.Pane)
+		if err != nil {
+			return fmt.Errorf("re-checking prompt in %s: %w", outcome.Pane, err)
+		}
+		current := status.DetectApprovalPrompt(out, prompt.AgentType)
+		if current == nil || promptFingerprint(current) != promptFingerprint(prompt) {
+			outcome.Response = PromptResponseGone
+			return nil
+		}
+	}
+
+	if err := r.cfg.SendKeys(outcome.Pane, keys...); err != nil {
+		return fmt.Errorf("answering prompt in %s: %w", outcome.Pane, err)
+	}
+	return nil
+}
+
+func (r *PromptResponder) notify(session, pane string, prompt *status.ApprovalPrompt) {
+	if r.cfg.Notifier == nil {
+		return
+	}
+	// Notification is best-effort; the prompt stays visible in the pane.
+	_ = r.cfg.Notifier.Notify(notify.NewAgentApprovalEvent(session, pane, prompt.AgentType, string(prompt.Kind), prompt.Action))
+}
+
+func promptFingerprint(p *status.ApprovalPrompt) string {
+	return p.Pattern + "\x00" + p.Question + "\x00" + p.Action
+}
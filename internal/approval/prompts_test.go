@@ -0,0 +1,269 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/policy"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+)
+
+type keyRecorder struct {
+	mu   sync.Mutex
+	sent map[string][][]string
+}
+
+func (k *keyRecorder) send(target string, keys ...string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.sent == nil {
+		k.sent = make(map[string][][]string)
+	}
+	k.sent[target] = append(k.sent[target], keys)
+	return nil
+}
+
+func (k *keyRecorder) get(target string) [][]string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.sent[target]
+}
+
+const codexPrompt = "Would you like to run the following command?\n\n  $ %s\n\n  1. Yes\n  2. No\n"
+
+func testPromptPolicy(t *testing.T) *policy.Policy {
+	t.Helper()
+	p := policy.DefaultPolicy()
+	p.ApprovalPrompts = []policy.PromptRule{
+		{Match: `^go test\b`, Action: policy.PromptApprove},
+		{Match: `^make deploy`, Action: policy.PromptEscalate, Reason: "deploys need sign-off"},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("policy validate: %v", err)
+	}
+	return p
+}
+
+func codexApproval(t *testing.T, command string) *status.ApprovalPrompt {
+	t.Helper()
+	prompt := status.DetectApprovalPrompt(fmt.Sprintf(codexPrompt, command), "cod")
+	if prompt == nil {
+		t.Fatalf("no prompt detected for %q", command)
+	}
+	return prompt
+}
+
+func TestPromptResponder_ApproveDenyNotify(t *testing.T) {
+	keys := &keyRecorder{}
+	r := NewPromptResponder(PromptResponderConfig{
+		Policy:   testPromptPolicy(t),
+		SendKeys: keys.send,
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		pane     string
+		command  string
+		wantResp string
+		wantKeys [][]string
+	}{
+		{"approve", "%1", "go test ./...", PromptResponseApproved, [][]string{{"y"}}},
+		{"deny blocked", "%2", "git reset --hard", PromptResponseDenied, [][]string{{"Escape"}}},
+		{"notify unmatched", "%3", "npm publish", PromptResponseNotified, nil},
+		{"escalate without engine", "%4", "make deploy", PromptResponseNotified, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := r.Handle(ctx, "proj", tt.pane, codexApproval(t, tt.command))
+			if err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+			if out.Response != tt.wantResp {
+				t.Errorf("Response = %q, want %q", out.Response, tt.wantResp)
+			}
+			if got := keys.get(tt.pane); !reflect.DeepEqual(got, tt.wantKeys) {
+				t.Errorf("keys sent = %v, want %v", got, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestPromptResponder_Dedupe(t *testing.T) {
+	keys := &keyRecorder{}
+	r := NewPromptResponder(PromptResponderConfig{Policy: testPromptPolicy(t), SendKeys: keys.send})
+	ctx := context.Background()
+	prompt := codexApproval(t, "go test ./...")
+
+	if out, _ := r.Handle(ctx, "proj", "%1", prompt); out == nil {
+		t.Fatal("first Handle returned nil")
+	}
+	if out, _ := r.Handle(ctx, "proj", "%1", prompt); out != nil {
+		t.Errorf("second Handle for same prompt = %+v, want nil", out)
+	}
+
+	r.Clear("%1")
+	if out, _ := r.Handle(ctx, "proj", "%1", prompt); out == nil {
+		t.Error("Handle after Clear returned nil")
+	}
+	if got := len(keys.get("%1")); got != 2 {
+		t.Errorf("expected 2 key sends, got %d", got)
+	}
+}
+
+func TestPromptResponder_RetriesAfterSendFailure(t *testing.T) {
+	keys := &keyRecorder{}
+	fail := true
+	r := NewPromptResponder(PromptResponderConfig{
+		Policy: testPromptPolicy(t),
+		SendKeys: func(target string, k ...string) error {
+			if fail {
+				return fmt.Errorf("pane busy")
+			}
+			return keys.send(target, k...)
+		},
+	})
+	ctx := context.Background()
+	prompt := codexApproval(t, "go test ./...")
+
+	if _, err := r.Handle(ctx, "proj", "%1", prompt); err == nil {
+		t.Fatal("Handle with failing key sender returned nil error")
+	}
+
+	// The prompt is still showing and was never answered, so it is handled again.
+	fail = false
+	out, err := r.Handle(ctx, "proj", "%1", prompt)
+	if err != nil || out == nil || out.Response != PromptResponseApproved {
+		t.Fatalf("retry Handle = %+v, %v", out, err)
+	}
+	if got := keys.get("%1"); !reflect.DeepEqual(got, [][]string{{"y"}}) {
+		t.Errorf("keys sent = %v", got)
+	}
+}
+
+func TestPromptResponder_Escalate(t *testing.T) {
+	store := setupTestStore(t)
+	engine := New(store, nil, nil, DefaultConfig())
+	keys := &keyRecorder{}
+	command := "make deploy"
+
+	r := NewPromptResponder(PromptResponderConfig{
+		Policy:   testPromptPolicy(t),
+		Engine:   engine,
+		SendKeys: keys.send,
+		Capture: func(string) (string, error) {
+			return fmt.Sprintf(codexPrompt, command), nil
+		},
+		Timeout: 5 * time.Second,
+	})
+
+	ctx := context.Background()
+	go func() {
+		deadline := time.Now().Add(3 * time.Second)
+		for time.Now().Before(deadline) {
+			pending, _ := engine.ListPending(ctx)
+			if len(pending) > 0 {
+				_ = engine.Approve(ctx, pending[0].ID, "reviewer")
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+
+	out, err := r.Handle(ctx, "proj", "%5", codexApproval(t, command))
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if out.Decision != policy.PromptEscalate || out.ApprovalID == "" {
+		t.Errorf("outcome = %+v, want escalation with approval id", out)
+	}
+	if out.Response != PromptResponseApproved {
+		t.Errorf("Response = %q, want %q", out.Response, PromptResponseApproved)
+	}
+	if got := keys.get("%5"); !reflect.DeepEqual(got, [][]string{{"y"}}) {
+		t.Errorf("keys sent = %v", got)
+	}
+}
+
+func TestPromptResponder_EscalatePromptGone(t *testing.T) {
+	store := setupTestStore(t)
+	engine := New(store, nil, nil, DefaultConfig())
+	keys := &keyRecorder{}
+
+	r := NewPromptResponder(PromptResponderConfig{
+		Policy:   testPromptPolicy(t),
+		Engine:   engine,
+		SendKeys: keys.send,
+		Capture: func(string) (string, error) {
+			return "deployed.\n> ", nil
+		},
+		Timeout: 5 * time.Second,
+	})
+
+	ctx := context.Background()
+	go func() {
+		for i := 0; i < 150; i++ {
+			pending, _ := engine.ListPending(ctx)
+			if len(pending) > 0 {
+				_ = engine.Deny(ctx, pending[0].ID, "reviewer", "not today")
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+
+	out, err := r.Handle(ctx, "proj", "%6", codexApproval(t, "make deploy"))
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if out.Response != PromptResponseGone {
+		t.Errorf("Response = %q, want %q", out.Response, PromptResponseGone)
+	}
+	if got := keys.get("%6"); got != nil {
+		t.Errorf("no keys should be sent once the prompt is gone, got %v", got)
+	}
+}
+
+func TestPromptResponder_EscalateTimeoutEscalatesAgain(t *testing.T) {
+	store := setupTestStore(t)
+	engine := New(store, nil, nil, DefaultConfig())
+	keys := &keyRecorder{}
+
+	r := NewPromptResponder(PromptResponderConfig{
+		Policy:   testPromptPolicy(t),
+		Engine:   engine,
+		SendKeys: keys.send,
+		Timeout:  50 * time.Millisecond,
+	})
+
+	ctx := context.Background()
+	prompt := codexApproval(t, "make deploy")
+	first, err := r.Handle(ctx, "proj", "%7", prompt)
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if first.Response != PromptResponseTimeout {
+		t.Fatalf("Response = %q, want %q", first.Response, PromptResponseTimeout)
+	}
+
+	// Nobody decided, so the prompt still showing on the next poll is
+	// escalated again rather than skipped as already handled.
+	second, err := r.Handle(ctx, "proj", "%7", prompt)
+	if err != nil {
+		t.Fatalf("second Handle: %v", err)
+	}
+	if second == nil || second.Response != PromptResponseTimeout {
+		t.Fatalf("second outcome = %+v, want another timed-out escalation", second)
+	}
+	if second.ApprovalID == "" || second.ApprovalID == first.ApprovalID {
+		t.Errorf("second approval id = %q, want a new request (first %q)", second.ApprovalID, first.ApprovalID)
+	}
+	if got := keys.get("%7"); got != nil {
+		t.Errorf("no keys should be sent for undecided escalations, got %v", got)
+	}
+}
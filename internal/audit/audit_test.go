@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/redaction"
+	"github.com/Dicklesworthstone/ntm/internal/tracing"
 )
 
 func TestAuditLogger_LogPopulatesFields(t *testing.T) {
@@ -225,6 +226,25 @@ func TestNewCorrelationID_IsUnique(t *testing.T) {
 	}
 }
 
+func TestNewCorrelationID_EmbedsCommandTrace(t *testing.T) {
+	if got := TraceIDFromCorrelationID(NewCorrelationID()); got != "" {
+		t.Fatalf("Expected no trace ID without a command span, got %q", got)
+	}
+
+	tracing.SetProvider(tracing.NewProvider(tracing.Config{}))
+	defer tracing.SetProvider(nil)
+	span := tracing.StartCommand("ntm test")
+	defer tracing.EndCommand(nil)
+
+	id := NewCorrelationID()
+	if !strings.HasPrefix(id, "cmd-") {
+		t.Fatalf("Expected cmd- prefix, got %q", id)
+	}
+	if got := TraceIDFromCorrelationID(id); got != span.TraceID() {
+		t.Fatalf("TraceIDFromCorrelationID(%q) = %q, want %q", id, got, span.TraceID())
+	}
+}
+
 func TestAuditLogger_FlushMethod(t *testing.T) {
 	tempDir := t.TempDir()
 	t.Setenv("HOME", tempDir)
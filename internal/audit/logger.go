@@ -16,6 +16,7 @@ import (
 
 	"github.com/Dicklesworthstone/ntm/internal/privacy"
 	"github.com/Dicklesworthstone/ntm/internal/redaction"
+	"github.com/Dicklesworthstone/ntm/internal/tracing"
 )
 
 // EventType represents the type of audit event
@@ -102,13 +103,35 @@ func SetRedactionConfig(cfg *redaction.Config) {
 }
 
 // NewCorrelationID returns a unique correlation ID for command tracing.
+// While an OTLP command span is active the ID embeds its trace ID
+// (cmd-<trace-id>-<rand>), so audit entries can be joined to the trace.
 func NewCorrelationID() string {
-	ms := time.Now().UnixMilli()
 	b := make([]byte, 4)
 	_, _ = rand.Read(b)
+	if traceID := tracing.CommandTraceID(); traceID != "" {
+		return fmt.Sprintf("cmd-%s-%x", traceID, b)
+	}
+	ms := time.Now().UnixMilli()
 	return fmt.Sprintf("cmd-%d-%x", ms, b)
 }
 
+// TraceIDFromCorrelationID returns the trace ID embedded by NewCorrelationID,
+// or "" for IDs minted without an active trace.
+func TraceIDFromCorrelationID(id string) string {
+	rest, ok := strings.CutPrefix(id, "cmd-")
+	if !ok {
+		return ""
+	}
+	traceID, _, ok := strings.Cut(rest, "-")
+	if !ok || len(traceID) != 32 {
+		return ""
+	}
+	if _, err := hex.DecodeString(traceID); err != nil {
+		return ""
+	}
+	return traceID
+}
+
 // LogEvent writes an audit event. It is safe to ignore returned errors.
 func LogEvent(session string, eventType EventType, actor Actor, target string, payload, metadata map[string]interface{}) error {
 	if shouldSkipAudit(session) {
@@ -127,6 +150,14 @@ func LogEvent(session string, eventType EventType, actor Actor, target string, p
 		Payload:   sanitizeMap(payload),
 		Metadata:  sanitizeMap(metadata),
 	}
+	if traceID := tracing.CommandTraceID(); traceID != "" {
+		if entry.Metadata == nil {
+			entry.Metadata = make(map[string]interface{})
+		}
+		if _, ok := entry.Metadata["trace_id"]; !ok {
+			entry.Metadata["trace_id"] = traceID
+		}
+	}
 
 	return logger.Log(entry)
 }
@@ -0,0 +1,295 @@
+// Package budget enforces real-money spend budgets per session, per agent
+// type and per day. Spend comes from cost.CostTracker; daily and agent-type
+// budgets are evaluated against a per-day ledger in the state store so they
+// span sessions and survive restarts.
+package budget
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// Where a limit was defined.
+const (
+	SourceConfig = "config" // config.toml [budgets]
+	SourceCLI    = "cli"    // ntm budget set
+)
+
+// Limit is one spend cap. A session limit without a target applies to every
+// session that has no limit of its own.
+type Limit struct {
+	Scope  state.BudgetScope `json:"scope"`
+	Target string            `json:"target,omitempty"`
+	USD    float64           `json:"usd"`
+	Source string            `json:"source"`
+}
+
+// ID names the limit as accepted by ParseID: "daily", "session",
+// "session:<name>" or "agent_type:<type>".
+func (l Limit) ID() string {
+	if l.Target == "" {
+		return string(l.Scope)
+	}
+	return string(l.Scope) + ":" + l.Target
+}
+
+// ParseID parses a limit ID into its scope and target.
+func ParseID(id string) (state.BudgetScope, string, error) {
+	scopeStr, target, _ := strings.Cut(strings.TrimSpace(id), ":")
+	scope := state.BudgetScope(scopeStr)
+	if !scope.Valid() {
+		return "", "", fmt.Errorf("unknown budget %q (want daily, session, session:<name> or agent_type:<type>)", id)
+	}
+	switch {
+	case scope == state.BudgetScopeDaily && target != "":
+		return "", "", fmt.Errorf("daily budget takes no target, got %q", id)
+	case scope == state.BudgetScopeAgentType && target == "":
+		return "", "", fmt.Errorf("agent_type budget needs a type, e.g. agent_type:cc")
+	}
+	return scope, target, nil
+}
+
+// ParseUSD parses a dollar amount such as "25", "25.50" or "$25".
+func ParseUSD(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(s), "$"), 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid amount %q: must be a positive number of dollars", s)
+	}
+	return v, nil
+}
+
+// Limits merges the configured budgets with those set via 'ntm budget set',
+// which take precedence. The result is sorted by ID.
+func Limits(cfg config.BudgetsConfig, stored []state.Budget) []Limit {
+	byID := make(map[string]Limit)
+	add := func(l Limit) {
+		if l.USD > 0 {
+			byID[l.ID()] = l
+		}
+	}
+	add(Limit{Scope: state.BudgetScopeDaily, USD: cfg.DailyUSD, Source: SourceConfig})
+	add(Limit{Scope: state.BudgetScopeSession, USD: cfg.SessionUSD, Source: SourceConfig})
+	for name, usd := range cfg.Sessions {
+		add(Limit{Scope: state.BudgetScopeSession, Target: name, USD: usd, Source: SourceConfig})
+	}
+	for agentType, usd := range cfg.AgentTypes {
+		add(Limit{Scope: state.BudgetScopeAgentType, Target: agentType, USD: usd, Source: SourceConfig})
+	}
+	for _, b := range stored {
+		add(Limit{Scope: b.Scope, Target: b.Target, USD: b.LimitUSD, Source: SourceCLI})
+	}
+
+	limits := make([]Limit, 0, len(byID))
+	for _, l := range byID {
+		limits = append(limits, l)
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].ID() < limits[j].ID() })
+	return limits
+}
+
+// Status is a limit evaluated against current spend.
+type Status struct {
+	Limit
+	Session  string  `json:"session,omitempty"` // session a session limit was evaluated for
+	Day      string  `json:"day"`
+	SpentUSD float64 `json:"spent_usd"`
+	Fraction float64 `json:"fraction"`
+	Exceeded bool    `json:"exceeded"`
+}
+
+// Key identifies the evaluated budget: the limit ID with the session of a
+// default session limit filled in.
+func (s Status) Key() string {
+	if s.Scope == state.BudgetScopeSession {
+		return "session:" + s.Session
+	}
+	return s.ID()
+}
+
+func newStatus(l Limit, session, day string, spent float64) Status {
+	return Status{
+		Limit:    l,
+		Session:  session,
+		Day:      day,
+		SpentUSD: spent,
+		Fraction: spent / l.USD,
+		Exceeded: spent >= l.USD,
+	}
+}
+
+// Day returns the local calendar day that daily budgets reset on.
+func Day(t time.Time) string {
+	return t.Local().Format("2006-01-02")
+}
+
+// Evaluator computes budget statuses from a cost tracker and the spend
+// ledger. Store may be nil, in which case only session budgets are evaluated.
+type Evaluator struct {
+	Store  *state.Store
+	Costs  *cost.CostTracker
+	Limits []Limit
+	Now    func() time.Time
+
+	pending []state.BudgetSpend // unrecorded spend from Preview
+}
+
+func (e *Evaluator) now() time.Time {
+	if e.Now != nil {
+		return e.Now()
+	}
+	return time.Now()
+}
+
+// Record adds the tracked cost of the given sessions' agents to today's
+// ledger, or of every tracked session when none are given.
+func (e *Evaluator) Record(sessions ...string) error {
+	if e.Store == nil || e.Costs == nil {
+		return nil
+	}
+	if len(sessions) == 0 {
+		sessions = e.Costs.GetAllSessions()
+	}
+	day := Day(e.now())
+	pricing := cost.ActiveCatalog().Version()
+	for _, name := range sessions {
+		sc := e.Costs.GetSession(name)
+		if sc == nil {
+			continue
+		}
+		for agent, ac := range sc.Agents {
+			agentType := string(tmux.AgentTypeFromTitle(agent))
+			if _, err := e.Store.RecordBudgetSpend(day, name, agent, agentType, pricing, ac.Cost()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Preview is the read-only counterpart of Record: it adds the tracked cost
+// of the given sessions' agents that exceeds the ledger's last observation
+// to the next Evaluate, without writing the ledger. Trackers other than the
+// ledger's writer see the same spend through a different view (other cost
+// files, a partial transcript sync), so a smaller cumulative cost is never
+// taken for a restart here; it simply adds nothing.
+func (e *Evaluator) Preview(sessions ...string) error {
+	e.pending = nil
+	if e.Store == nil || e.Costs == nil {
+		return nil
+	}
+	if len(sessions) == 0 {
+		sessions = e.Costs.GetAllSessions()
+	}
+	day := Day(e.now())
+	pricing := cost.ActiveCatalog().Version()
+	for _, name := range sessions {
+		sc := e.Costs.GetSession(name)
+		if sc == nil {
+			continue
+		}
+		for agent, ac := range sc.Agents {
+			last, lastPricing, ok, err := e.Store.LastBudgetSpend(name, agent)
+			if err != nil {
+				return err
+			}
+			delta := ac.Cost()
+			if ok {
+				if lastPricing != pricing {
+					continue
+				}
+				delta -= last
+			}
+			if delta <= 0 {
+				continue
+			}
+			e.pending = append(e.pending, state.BudgetSpend{
+				Day:       day,
+				SessionID: name,
+				Agent:     agent,
+				AgentType: string(tmux.AgentTypeFromTitle(agent)),
+				SpentUSD:  delta,
+			})
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the status of every limit. Session limits are evaluated
+// against the tracker's cumulative session cost; daily and agent-type limits
+// against today's ledger.
+func (e *Evaluator) Evaluate() ([]Status, error) {
+	day := Day(e.now())
+
+	var (
+		spend    []state.BudgetSpend
+		loaded   bool
+		explicit = make(map[string]bool)
+	)
+	for _, l := range e.Limits {
+		if l.Scope == state.BudgetScopeSession {
+			if l.Target != "" {
+				explicit[l.Target] = true
+			}
+			continue
+		}
+		if !loaded && e.Store != nil {
+			var err error
+			if spend, err = e.Store.ListBudgetSpend(day); err != nil {
+				return nil, err
+			}
+			loaded = true
+		}
+	}
+	spend = append(spend, e.pending...)
+
+	var sessions []string
+	if e.Costs != nil {
+		sessions = e.Costs.GetAllSessions()
+		sort.Strings(sessions)
+	}
+	sessionCost := func(name string) float64 {
+		if e.Costs == nil {
+			return 0
+		}
+		return e.Costs.GetSessionCost(name)
+	}
+
+	var statuses []Status
+	for _, l := range e.Limits {
+		switch l.Scope {
+		case state.BudgetScopeDaily:
+			var total float64
+			for _, s := range spend {
+				total += s.SpentUSD
+			}
+			statuses = append(statuses, newStatus(l, "", day, total))
+		case state.BudgetScopeAgentType:
+			var total float64
+			for _, s := range spend {
+				if s.AgentType == l.Target {
+					total += s.SpentUSD
+				}
+			}
+			statuses = append(statuses, newStatus(l, "", day, total))
+		case state.BudgetScopeSession:
+			if l.Target != "" {
+				statuses = append(statuses, newStatus(l, l.Target, day, sessionCost(l.Target)))
+				continue
+			}
+			for _, name := range sessions {
+				if !explicit[name] {
+					statuses = append(statuses, newStatus(l, name, day, sessionCost(name)))
+				}
+			}
+		}
+	}
+	return statuses, nil
+}
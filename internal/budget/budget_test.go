@@ -0,0 +1,281 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/approval"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+func testStore(t *testing.T) *state.Store {
+	t.Helper()
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return store
+}
+
+// spend sets an agent's exact usage so that it costs roughly usd with the
+// default model pricing (output tokens only).
+func spend(t *testing.T, costs *cost.CostTracker, session, agent string, usd float64) {
+	t.Helper()
+	pricing := cost.GetModelPricing("")
+	costs.SetExactUsage(session, agent, "", 0, int(usd/pricing.OutputPer1K*1000), 0, 0)
+}
+
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		id     string
+		scope  state.BudgetScope
+		target string
+		ok     bool
+	}{
+		{"daily", state.BudgetScopeDaily, "", true},
+		{"session", state.BudgetScopeSession, "", true},
+		{"session:proj", state.BudgetScopeSession, "proj", true},
+		{"agent_type:cc", state.BudgetScopeAgentType, "cc", true},
+		{"agent_type", "", "", false},
+		{"daily:x", "", "", false},
+		{"weekly", "", "", false},
+	}
+	for _, tt := range tests {
+		scope, target, err := ParseID(tt.id)
+		if (err == nil) != tt.ok || scope != tt.scope || target != tt.target {
+			t.Errorf("ParseID(%q) = %q, %q, %v", tt.id, scope, target, err)
+		}
+	}
+	if v, err := ParseUSD("$12.50"); err != nil || v != 12.5 {
+		t.Errorf("ParseUSD($12.50) = %v, %v", v, err)
+	}
+	if _, err := ParseUSD("-3"); err == nil {
+		t.Error("ParseUSD(-3) should fail")
+	}
+}
+
+func TestLimitsStoredOverrideConfig(t *testing.T) {
+	cfg := config.BudgetsConfig{
+		DailyUSD:   50,
+		AgentTypes: map[string]float64{"cc": 30},
+	}
+	limits := Limits(cfg, []state.Budget{
+		{Scope: state.BudgetScopeDaily, LimitUSD: 20},
+		{Scope: state.BudgetScopeSession, Target: "proj", LimitUSD: 5},
+	})
+	if len(limits) != 3 {
+		t.Fatalf("Limits = %+v", limits)
+	}
+	want := []struct {
+		id     string
+		usd    float64
+		source string
+	}{{"agent_type:cc", 30, SourceConfig}, {"daily", 20, SourceCLI}, {"session:proj", 5, SourceCLI}}
+	for i, w := range want {
+		if limits[i].ID() != w.id || limits[i].USD != w.usd || limits[i].Source != w.source {
+			t.Errorf("limits[%d] = %+v, want %+v", i, limits[i], w)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	store := testStore(t)
+	costs := cost.NewCostTracker("")
+	spend(t, costs, "alpha", "alpha__cc_1", 4)
+	spend(t, costs, "alpha", "alpha__cod_1", 1)
+	spend(t, costs, "beta", "beta__cc_1", 2)
+
+	e := &Evaluator{
+		Store: store,
+		Costs: costs,
+		Limits: Limits(config.BudgetsConfig{
+			DailyUSD:   10,
+			SessionUSD: 3,
+			Sessions:   map[string]float64{"beta": 1},
+			AgentTypes: map[string]float64{"cc": 5},
+		}, nil),
+	}
+	if err := e.Record(); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	statuses, err := e.Evaluate()
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+
+	got := make(map[string]Status)
+	for _, st := range statuses {
+		got[st.Key()] = st
+	}
+	check := func(key string, spent float64, exceeded bool) {
+		t.Helper()
+		st, ok := got[key]
+		if !ok {
+			t.Fatalf("no status for %s in %+v", key, statuses)
+		}
+		if diff := st.SpentUSD - spent; diff > 0.01 || diff < -0.01 || st.Exceeded != exceeded {
+			t.Errorf("%s = $%.4f exceeded=%v, want $%.2f exceeded=%v", key, st.SpentUSD, st.Exceeded, spent, exceeded)
+		}
+	}
+	check("daily", 7, false)
+	check("agent_type:cc", 6, true)
+	check("session:alpha", 5, true) // default session cap
+	check("session:beta", 2, true)  // explicit cap replaces the default
+	if len(statuses) != 4 {
+		t.Errorf("got %d statuses, want 4", len(statuses))
+	}
+
+	// Recording the same totals again adds nothing to the ledger.
+	if err := e.Record(); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	again, _ := e.Evaluate()
+	for _, st := range again {
+		if st.Key() == "daily" && (st.SpentUSD < 6.99 || st.SpentUSD > 7.01) {
+			t.Errorf("daily after re-record = %v, want 7", st.SpentUSD)
+		}
+	}
+}
+
+func TestEnforceWarnsOncePerThreshold(t *testing.T) {
+	store := testStore(t)
+	costs := cost.NewCostTracker("")
+	bus := events.NewEventBus(10)
+
+	var interrupted []string
+	e := &Enforcer{
+		Evaluator: Evaluator{Store: store, Costs: costs},
+		Config: config.BudgetsConfig{
+			Enabled:   true,
+			Sessions:  map[string]float64{"proj": 10},
+			WarnAt:    []float64{0.5, 0.8},
+			Interrupt: true,
+		},
+		Bus:       bus,
+		Interrupt: func(st Status) error { interrupted = append(interrupted, st.Key()); return nil },
+	}
+
+	ctx := context.Background()
+	for _, usd := range []float64{2, 6, 6.5, 9, 12, 13} {
+		spend(t, costs, "proj", "proj__cc_1", usd)
+		if _, err := e.Enforce(ctx); err != nil {
+			t.Fatalf("Enforce: %v", err)
+		}
+	}
+	// History is newest first.
+	var alerts []events.AlertEvent
+	for _, ev := range bus.History(10) {
+		if a, ok := ev.(events.AlertEvent); ok {
+			alerts = append([]events.AlertEvent{a}, alerts...)
+		}
+	}
+
+	// 50%, 80% and exceeded each fire exactly once.
+	if len(alerts) != 3 {
+		t.Fatalf("got %d alerts: %+v", len(alerts), alerts)
+	}
+	if alerts[0].AlertType != "budget_warning" || alerts[2].AlertType != "budget_exceeded" || alerts[2].Severity != "critical" {
+		t.Errorf("unexpected alerts: %+v", alerts)
+	}
+	if len(interrupted) != 1 || interrupted[0] != "session:proj" {
+		t.Errorf("interrupted = %v", interrupted)
+	}
+}
+
+func TestGuardBlocksUntilOverrideApproved(t *testing.T) {
+	store := testStore(t)
+	costs := cost.NewCostTracker("")
+	spend(t, costs, "proj", "proj__cc_1", 3)
+	engine := approval.New(store, nil, nil, approval.DefaultConfig())
+	e := &Enforcer{
+		Evaluator: Evaluator{Store: store, Costs: costs},
+		Config:    config.BudgetsConfig{Enabled: true, Block: true, AgentTypes: map[string]float64{"cc": 2}},
+		Approvals: engine,
+	}
+	ctx := context.Background()
+
+	// Other agent types are not affected by the cc budget.
+	if err := e.Guard(ctx, "proj", []string{"cod"}, "test"); err != nil {
+		t.Fatalf("Guard(cod) = %v, want nil", err)
+	}
+
+	err := e.Guard(ctx, "proj", []string{"cc"}, "test")
+	var blocked *BlockedError
+	if !errors.As(err, &blocked) || blocked.ApprovalID == "" {
+		t.Fatalf("Guard(cc) = %v, want BlockedError with approval", err)
+	}
+	// A second block reuses the pending request.
+	err = e.Guard(ctx, "proj", []string{"cc"}, "test")
+	var again *BlockedError
+	if !errors.As(err, &again) || again.ApprovalID != blocked.ApprovalID {
+		t.Fatalf("second Guard = %v, want same approval %s", err, blocked.ApprovalID)
+	}
+
+	if err := engine.Approve(ctx, blocked.ApprovalID, "human"); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if err := e.Guard(ctx, "proj", []string{"cc"}, "test"); err != nil {
+		t.Errorf("Guard after approval = %v, want nil", err)
+	}
+}
+
+func TestGuardDoesNotWriteLedger(t *testing.T) {
+	store := testStore(t)
+	cfg := config.BudgetsConfig{Enabled: true, Block: true, DailyUSD: 10}
+
+	// serve's enforcer is the ledger's writer and has seen $6.
+	served := cost.NewCostTracker("")
+	spend(t, served, "proj", "proj__cc_1", 6)
+	writer := &Enforcer{Evaluator: Evaluator{Store: store, Costs: served}, Config: cfg}
+	if _, err := writer.Enforce(context.Background()); err != nil {
+		t.Fatalf("Enforce: %v", err)
+	}
+
+	// Sends see a partial view ($2, e.g. without saved costs). Before, each
+	// of these was taken for a restart and added $2 to the day.
+	for i := 0; i < 5; i++ {
+		view := cost.NewCostTracker("")
+		spend(t, view, "proj", "proj__cc_1", 2)
+		e := &Enforcer{Evaluator: Evaluator{Store: store, Costs: view}, Config: cfg}
+		if err := e.Guard(context.Background(), "proj", []string{"cc"}, "test"); err != nil {
+			t.Fatalf("Guard %d = %v, want nil", i, err)
+		}
+	}
+
+	rows, err := store.ListBudgetSpend(Day(time.Now()))
+	if err != nil {
+		t.Fatalf("ListBudgetSpend: %v", err)
+	}
+	if len(rows) != 1 || rows[0].SpentUSD < 5.9 || rows[0].SpentUSD > 6.1 {
+		t.Fatalf("ledger = %+v, want $6 from the writer only", rows)
+	}
+
+	// Spend the ledger has not seen yet still counts towards the check.
+	view := cost.NewCostTracker("")
+	spend(t, view, "proj", "proj__cc_1", 11)
+	e := &Enforcer{Evaluator: Evaluator{Store: store, Costs: view}, Config: cfg}
+	var blocked *BlockedError
+	if err := e.Guard(context.Background(), "proj", []string{"cc"}, "test"); !errors.As(err, &blocked) {
+		t.Fatalf("Guard with unrecorded spend = %v, want BlockedError", err)
+	}
+}
+
+func TestUntilMidnight(t *testing.T) {
+	now := time.Date(2026, 3, 1, 23, 0, 0, 0, time.Local)
+	if d := untilMidnight(now); d != time.Hour {
+		t.Errorf("untilMidnight(23:00) = %v, want 1h", d)
+	}
+	if d := untilMidnight(time.Date(2026, 3, 1, 23, 59, 59, 0, time.Local)); d != time.Minute {
+		t.Errorf("untilMidnight(23:59:59) = %v, want 1m", d)
+	}
+}
@@ -0,0 +1,96 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/approval"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/transcript"
+)
+
+// GuardTimeout bounds a GuardSend check.
+const GuardTimeout = 15 * time.Second
+
+// LoadConfig returns the [budgets] section of the user's config.toml, or the
+// defaults if it cannot be read.
+func LoadConfig() config.BudgetsConfig {
+	cfg, err := config.Load("")
+	if err != nil {
+		return config.DefaultBudgetsConfig()
+	}
+	return cfg.Budgets
+}
+
+// Check guards new work (a send or pipeline step) in a session from a fresh
+// view of its spend: costs saved under dir plus the agents' transcripts,
+// on top of the ledger. It is read-only. It returns a *BlockedError when the
+// work must not start, and nil when budgets are disabled or not blocking.
+// Other errors mean the check itself failed.
+func Check(ctx context.Context, cfg config.BudgetsConfig, dir, session string, agentTypes []string, requestedBy string) error {
+	if !cfg.Enabled || !cfg.Block {
+		return nil
+	}
+
+	store, err := state.Open("")
+	if err != nil {
+		return fmt.Errorf("open state store: %w", err)
+	}
+	defer store.Close()
+	if err := store.Migrate(); err != nil {
+		return fmt.Errorf("apply migrations: %w", err)
+	}
+	stored, err := store.ListBudgets()
+	if err != nil {
+		return err
+	}
+	if len(Limits(cfg, stored)) == 0 {
+		return nil
+	}
+
+	costs := cost.NewCostTracker("")
+	if dir != "" {
+		_ = costs.LoadFromDir(dir)
+	}
+	if panes, err := transcript.PanesFromTmux(ctx, session); err == nil {
+		transcript.NewIngester(costs, nil).Sync(session, panes)
+	}
+
+	enforcer := &Enforcer{
+		Evaluator: Evaluator{Store: store, Costs: costs},
+		Config:    cfg,
+		Approvals: approval.New(store, nil, nil, approval.DefaultConfig()),
+	}
+	return enforcer.Guard(ctx, session, agentTypes, requestedBy)
+}
+
+// GuardSend is the budget check on every path that sends new work to
+// agents: ntm send, robot and REST sends (and through them cron, trigger
+// and queue sends) and pipeline prompt steps. It runs Check against the
+// given agent types with GuardTimeout and is a no-op when there are none.
+// As with Check, a *BlockedError means the send must not happen and any
+// other error means the check itself failed. Tests may replace it.
+var GuardSend = func(ctx context.Context, cfg config.BudgetsConfig, dir, session string, agentTypes []string, requestedBy string) error {
+	if len(agentTypes) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, GuardTimeout)
+	defer cancel()
+	return Check(ctx, cfg, dir, session, agentTypes, requestedBy)
+}
+
+// PaneAgentTypes returns the agent types of panes for GuardSend, leaving
+// out user panes, which spend nothing.
+func PaneAgentTypes(panes []tmux.Pane) []string {
+	var agentTypes []string
+	for _, p := range panes {
+		if p.Type != tmux.AgentUser {
+			agentTypes = append(agentTypes, string(p.Type))
+		}
+	}
+	return agentTypes
+}
@@ -0,0 +1,299 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/approval"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/notify"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// OverrideAction is the approval action that lifts an exceeded budget's
+// block for the rest of the local day.
+const OverrideAction = "budget_override"
+
+// OverrideResource is the approval resource for overriding s on its day.
+func (s Status) OverrideResource() string {
+	return s.Key() + "@" + s.Day
+}
+
+// BlockedError reports new work refused because a budget is exceeded.
+type BlockedError struct {
+	Status     Status
+	ApprovalID string // pending override request, if one could be filed
+}
+
+func (e *BlockedError) Error() string {
+	msg := fmt.Sprintf("budget %s exceeded ($%.2f of $%.2f)", e.Status.Key(), e.Status.SpentUSD, e.Status.USD)
+	if e.ApprovalID != "" {
+		msg += fmt.Sprintf("; to override for today run: ntm approve %s", e.ApprovalID)
+	}
+	return msg
+}
+
+// Enforcer applies the graduated budget actions: notifications and alert
+// events at each warning threshold and at the cap, blocking new work past
+// the cap unless an override is approved, and optionally interrupting the
+// panes that are spending.
+type Enforcer struct {
+	Evaluator
+	Config    config.BudgetsConfig
+	Notifier  *notify.Notifier   // optional
+	Bus       *events.EventBus   // optional
+	Approvals *approval.Engine   // files override requests; optional
+	Interrupt func(Status) error // defaults to sending Ctrl-C to the affected agent panes
+
+	mu      sync.Mutex
+	reached map[string]float64 // status key@day -> highest level acted on
+}
+
+// Enforce records spend for every tracked session, evaluates all budgets
+// and acts on those that crossed a new threshold since the last call. It is
+// the ledger's only writer and runs in 'ntm serve'.
+func (e *Enforcer) Enforce(ctx context.Context) ([]Status, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.loadLimits(); err != nil {
+		return nil, err
+	}
+	if err := e.Record(); err != nil {
+		return nil, err
+	}
+	statuses, err := e.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+
+	if e.reached == nil {
+		e.reached = make(map[string]float64)
+	}
+	for _, st := range statuses {
+		level := e.level(st)
+		key := st.OverrideResource()
+		if level == 0 || level <= e.reached[key] {
+			continue
+		}
+		e.reached[key] = level
+		e.act(ctx, st)
+	}
+	return statuses, nil
+}
+
+// Statuses evaluates all budgets without acting on them or writing the
+// ledger; spend the ledger has not seen yet is included via Preview.
+func (e *Enforcer) Statuses() ([]Status, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.loadLimits(); err != nil {
+		return nil, err
+	}
+	if err := e.Preview(); err != nil {
+		return nil, err
+	}
+	return e.Evaluate()
+}
+
+// loadLimits merges the configured budgets with those currently stored, so
+// 'ntm budget set' takes effect without a restart.
+func (e *Enforcer) loadLimits() error {
+	var stored []state.Budget
+	if e.Store != nil {
+		var err error
+		if stored, err = e.Store.ListBudgets(); err != nil {
+			return err
+		}
+	}
+	e.Limits = Limits(e.Config, stored)
+	return nil
+}
+
+// level returns 1 for an exceeded budget, the highest warning threshold
+// crossed otherwise, or 0.
+func (e *Enforcer) level(st Status) float64 {
+	if st.Exceeded {
+		return 1
+	}
+	var level float64
+	for _, t := range e.Config.WarnAt {
+		if st.Fraction >= t && t > level {
+			level = t
+		}
+	}
+	return level
+}
+
+func (e *Enforcer) act(ctx context.Context, st Status) {
+	if e.Notifier != nil {
+		if err := e.Notifier.Notify(notify.NewBudgetEvent(st.Session, st.Key(), st.SpentUSD, st.USD, st.Exceeded)); err != nil {
+			slog.Warn("budget notification failed", "budget", st.Key(), "error", err)
+		}
+	}
+	if e.Bus != nil {
+		alertType, severity := "budget_warning", "warning"
+		msg := fmt.Sprintf("Budget %s at %.0f%%: $%.2f of $%.2f", st.Key(), 100*st.Fraction, st.SpentUSD, st.USD)
+		if st.Exceeded {
+			alertType, severity = "budget_exceeded", "critical"
+			msg = fmt.Sprintf("Budget %s exceeded: $%.2f of $%.2f", st.Key(), st.SpentUSD, st.USD)
+		}
+		e.Bus.Publish(events.NewAlertEvent(st.Session, st.OverrideResource(), alertType, severity, msg))
+	}
+	if st.Exceeded && e.Config.Interrupt && !e.Overridden(st) {
+		interrupt := e.Interrupt
+		if interrupt == nil {
+			interrupt = interruptPanes
+		}
+		if err := interrupt(st); err != nil {
+			slog.Warn("budget interrupt failed", "budget", st.Key(), "error", err)
+		}
+	}
+}
+
+// Guard returns a *BlockedError if new work in session, on any of the given
+// agent types, would run past an exceeded budget without an approved
+// override. It does not write the ledger: only Enforce records spend, so
+// short-lived trackers with their own view of the costs cannot inflate it.
+// The first block for a budget and day files an override request with the
+// approval engine; later blocks reuse it.
+func (e *Enforcer) Guard(ctx context.Context, session string, agentTypes []string, requestedBy string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.loadLimits(); err != nil {
+		return err
+	}
+	if err := e.Preview(session); err != nil {
+		return err
+	}
+	statuses, err := e.Evaluate()
+	if err != nil {
+		return err
+	}
+	for _, st := range statuses {
+		if !st.Exceeded || !appliesTo(st, session, agentTypes) || e.Overridden(st) {
+			continue
+		}
+		id, err := e.requestOverride(ctx, st, requestedBy)
+		if err != nil {
+			slog.Warn("budget override request failed", "budget", st.Key(), "error", err)
+		}
+		return &BlockedError{Status: st, ApprovalID: id}
+	}
+	return nil
+}
+
+func appliesTo(st Status, session string, agentTypes []string) bool {
+	switch st.Scope {
+	case state.BudgetScopeSession:
+		return st.Session == session
+	case state.BudgetScopeAgentType:
+		return slices.Contains(agentTypes, st.Target)
+	}
+	return true
+}
+
+// Overridden reports whether an override for st's budget and day has been
+// approved.
+func (e *Enforcer) Overridden(st Status) bool {
+	if e.Store == nil {
+		return false
+	}
+	appr, err := e.Store.FindLatestApproval(OverrideAction, st.OverrideResource())
+	return err == nil && appr != nil && appr.Status == state.ApprovalApproved
+}
+
+// requestOverride returns the pending override request for st, filing one
+// if there is none.
+func (e *Enforcer) requestOverride(ctx context.Context, st Status, requestedBy string) (string, error) {
+	if e.Store == nil || e.Approvals == nil {
+		return "", nil
+	}
+	appr, err := e.Store.FindLatestApproval(OverrideAction, st.OverrideResource())
+	if err != nil {
+		return "", err
+	}
+	if appr != nil && appr.Status == state.ApprovalPending && time.Now().Before(appr.ExpiresAt) {
+		return appr.ID, nil
+	}
+	appr, err = e.Approvals.Request(ctx, approval.RequestParams{
+		Action:      OverrideAction,
+		Resource:    st.OverrideResource(),
+		Reason:      fmt.Sprintf("Budget %s exceeded: $%.2f of $%.2f", st.Key(), st.SpentUSD, st.USD),
+		RequestedBy: requestedBy,
+		ExpiresIn:   untilMidnight(e.now()),
+	})
+	if err != nil {
+		return "", err
+	}
+	return appr.ID, nil
+}
+
+// untilMidnight is how long an override request stays meaningful: the
+// ledger day it names ends at local midnight.
+func untilMidnight(now time.Time) time.Duration {
+	now = now.Local()
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	if d := midnight.Sub(now); d > time.Minute {
+		return d
+	}
+	return time.Minute
+}
+
+// interruptPanes sends Ctrl-C to the agent panes spending against st: the
+// session's agents, the agents of the budget's type, or every agent.
+func interruptPanes(st Status) error {
+	sessions := []string{st.Session}
+	if st.Scope != state.BudgetScopeSession {
+		list, err := tmux.ListSessions()
+		if err != nil {
+			return err
+		}
+		sessions = sessions[:0]
+		for _, s := range list {
+			sessions = append(sessions, s.Name)
+		}
+	}
+	for _, name := range sessions {
+		panes, err := tmux.GetPanes(name)
+		if err != nil {
+			continue
+		}
+		for _, p := range panes {
+			if p.Type == tmux.AgentUser {
+				continue
+			}
+			if st.Scope == state.BudgetScopeAgentType && string(p.Type) != st.Target {
+				continue
+			}
+			if err := tmux.SendInterrupt(p.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Run enforces budgets every interval until ctx is done.
+func (e *Enforcer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := e.Enforce(ctx); err != nil {
+			slog.Warn("budget check failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -140,10 +140,20 @@ func (a *AutoCheckpointer) rotateAutoCheckpoints(sessionName string, maxCount in
 
 	// Delete oldest auto-checkpoints (list is sorted newest first)
 	toDelete := autoCheckpoints[maxCount:]
+	deleted := 0
 	for _, cp := range toDelete {
 		if err := a.storage.Delete(sessionName, cp.ID); err != nil {
 			// Log but continue
 			log.Printf("Warning: failed to delete old auto-checkpoint %s: %v", cp.ID, err)
+			continue
+		}
+		deleted++
+	}
+
+	// Free scrollback chunks only the rotated checkpoints referenced
+	if deleted > 0 {
+		if _, err := a.storage.GC(GCOptions{}); err != nil {
+			log.Printf("Warning: failed to collect checkpoint blobs: %v", err)
 		}
 	}
 
@@ -0,0 +1,439 @@
+package checkpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log/slog"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// BlobsDir is the subdirectory of the checkpoint root holding the
+	// content-addressed blob store. tmux does not allow dots in session
+	// names, so it can never collide with a session directory.
+	BlobsDir = ".blobs"
+	// BlobChunkLines is the average number of lines stored per blob.
+	// Chunk boundaries follow the content, so consecutive checkpoints of a
+	// pane share every chunk but the first and last few, even after tmux
+	// drops old lines from the top of the history.
+	BlobChunkLines = 256
+	// BlobGCGracePeriod protects blobs written by a capture whose metadata
+	// has not been saved yet from being collected.
+	BlobGCGracePeriod = 15 * time.Minute
+)
+
+// ErrBlobNotFound is returned when a referenced blob is not in the store.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobPath returns the file path of the blob with the given hash.
+func (s *Storage) BlobPath(hash string) string {
+	prefix := hash
+	if len(prefix) > 2 {
+		prefix = prefix[:2]
+	}
+	return filepath.Join(s.BaseDir, BlobsDir, prefix, hash)
+}
+
+// PutBlob stores data in the blob store and returns its SHA256 hash.
+// Blobs are gzip-compressed on disk; storing content that is already
+// present is a no-op.
+func (s *Storage) PutBlob(data []byte) (string, error) {
+	hash := sha256sum(data)
+	path := s.BlobPath(hash)
+	if fileExists(path) {
+		return hash, nil
+	}
+
+	compressed, err := gzipCompress(data)
+	if err != nil {
+		return "", fmt.Errorf("compressing blob: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("creating blob directory: %w", err)
+	}
+	if err := writeStoredFile(path, compressed); err != nil {
+		return "", fmt.Errorf("saving blob: %w", err)
+	}
+	return hash, nil
+}
+
+// HasBlob reports whether the blob with the given hash is in the store.
+func (s *Storage) HasBlob(hash string) bool {
+	return isBlobHash(hash) && fileExists(s.BlobPath(hash))
+}
+
+// GetBlob reads a blob and checks that its content matches the hash.
+func (s *Storage) GetBlob(hash string) ([]byte, error) {
+	if !isBlobHash(hash) {
+		return nil, fmt.Errorf("invalid blob hash %q", hash)
+	}
+	compressed, err := readStoredFile(s.BlobPath(hash))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, hash)
+		}
+		return nil, fmt.Errorf("reading blob: %w", err)
+	}
+	data, err := gzipDecompress(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing blob %s: %w", hash, err)
+	}
+	if actual := sha256sum(data); actual != hash {
+		return nil, fmt.Errorf("blob %s is corrupt (content hashes to %s)", hash, actual)
+	}
+	return data, nil
+}
+
+// PutChunks splits content into blocks of about BlobChunkLines lines,
+// stores each block as a blob and returns the hashes in order.
+func (s *Storage) PutChunks(content string) ([]string, error) {
+	chunks := splitChunks(content, BlobChunkLines)
+	hashes := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		hash, err := s.PutBlob([]byte(chunk))
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+// GetChunks reassembles content stored with PutChunks.
+func (s *Storage) GetChunks(hashes []string) (string, error) {
+	var sb strings.Builder
+	for _, hash := range hashes {
+		data, err := s.GetBlob(hash)
+		if err != nil {
+			return "", err
+		}
+		sb.Write(data)
+	}
+	return sb.String(), nil
+}
+
+// chunkWindowLines is the number of lines the chunk boundary hash covers.
+const chunkWindowLines = 3
+
+// splitChunks splits content into blocks of about n lines. A block ends
+// after a line when the hash of that line and the chunkWindowLines-1 lines
+// before it is 0 mod n, so boundaries move with the text instead of with
+// line numbers. Blocks hold at least n/4 lines (except the last) and at
+// most 4n. Each block keeps its line terminators, so concatenating the
+// blocks restores content.
+func splitChunks(content string, n int) []string {
+	if content == "" {
+		return nil
+	}
+	minLines, maxLines := max(n/4, 1), 4*n
+	var window [chunkWindowLines]uint64
+	var chunks []string
+	start, lineStart, lines := 0, 0, 0
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\n' {
+			continue
+		}
+		h := fnv.New64a()
+		h.Write([]byte(content[lineStart:i]))
+		copy(window[:], window[1:])
+		window[chunkWindowLines-1] = h.Sum64()
+		lineStart = i + 1
+		lines++
+
+		var sum uint64
+		for k, lineHash := range window {
+			sum ^= bits.RotateLeft64(lineHash, k)
+		}
+		if lines >= maxLines || (lines >= minLines && sum%uint64(n) == 0) {
+			chunks = append(chunks, content[start:i+1])
+			start, lines = i+1, 0
+		}
+	}
+	if start < len(content) {
+		chunks = append(chunks, content[start:])
+	}
+	return chunks
+}
+
+func isBlobHash(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// BlobRefs returns the hashes of every blob the checkpoint references.
+func (c *Checkpoint) BlobRefs() []string {
+	var refs []string
+	for _, pane := range c.Session.Panes {
+		refs = append(refs, pane.ScrollbackBlobs...)
+	}
+	return append(refs, c.Git.PatchBlobs...)
+}
+
+// ReadScrollback returns the captured scrollback of a pane, from the blob
+// store or, for checkpoints that predate it, from the pane's scrollback file.
+func (s *Storage) ReadScrollback(cp *Checkpoint, pane PaneState) (string, error) {
+	if len(pane.ScrollbackBlobs) > 0 {
+		return s.GetChunks(pane.ScrollbackBlobs)
+	}
+	if pane.ScrollbackFile == "" {
+		return "", nil
+	}
+	return s.readCheckpointFile(s.CheckpointDir(cp.SessionName, cp.ID), pane.ScrollbackFile)
+}
+
+// ReadGitPatch returns the checkpoint's git patch, or "" if none was captured.
+func (s *Storage) ReadGitPatch(cp *Checkpoint) (string, error) {
+	if len(cp.Git.PatchBlobs) > 0 {
+		return s.GetChunks(cp.Git.PatchBlobs)
+	}
+	if cp.Git.PatchFile == "" {
+		return "", nil
+	}
+	patch, err := s.readCheckpointFile(s.CheckpointDir(cp.SessionName, cp.ID), cp.Git.PatchFile)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return patch, err
+}
+
+// readCheckpointFile reads a file stored inside a checkpoint directory,
+// decompressing it if it is gzipped.
+func (s *Storage) readCheckpointFile(dir, rel string) (string, error) {
+	data, err := readStoredFile(filepath.Join(dir, rel))
+	if err != nil {
+		return "", err
+	}
+	if strings.HasSuffix(rel, ".gz") {
+		if data, err = gzipDecompress(data); err != nil {
+			return "", fmt.Errorf("decompressing %s: %w", rel, err)
+		}
+	}
+	return string(data), nil
+}
+
+// findPane returns the pane with the given tmux ID.
+func (c *Checkpoint) findPane(paneID string) (PaneState, bool) {
+	for _, pane := range c.Session.Panes {
+		if pane.ID == paneID {
+			return pane, true
+		}
+	}
+	return PaneState{}, false
+}
+
+// migrateToBlobs moves the scrollback and patch files of a checkpoint
+// written before the blob store into it, rewrites the metadata in dir as
+// CurrentVersion and removes the old files. cp is left untouched on error.
+func (s *Storage) migrateToBlobs(dir string, cp *Checkpoint) error {
+	migrated := *cp
+	migrated.Session.Panes = append([]PaneState(nil), cp.Session.Panes...)
+	var obsolete []string
+
+	for i := range migrated.Session.Panes {
+		pane := &migrated.Session.Panes[i]
+		if pane.ScrollbackFile == "" || len(pane.ScrollbackBlobs) > 0 {
+			continue
+		}
+		content, err := s.readCheckpointFile(dir, pane.ScrollbackFile)
+		if os.IsNotExist(err) {
+			continue // dangling reference; verify reports it
+		}
+		if err != nil {
+			return fmt.Errorf("migrating scrollback for pane %s: %w", pane.ID, err)
+		}
+		if pane.ScrollbackBlobs, err = s.PutChunks(content); err != nil {
+			return fmt.Errorf("migrating scrollback for pane %s: %w", pane.ID, err)
+		}
+		obsolete = append(obsolete, pane.ScrollbackFile)
+		pane.ScrollbackFile = ""
+	}
+
+	if migrated.Git.PatchFile != "" && len(migrated.Git.PatchBlobs) == 0 {
+		patch, err := s.readCheckpointFile(dir, migrated.Git.PatchFile)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return fmt.Errorf("migrating git patch: %w", err)
+		default:
+			if migrated.Git.PatchBlobs, err = s.PutChunks(patch); err != nil {
+				return fmt.Errorf("migrating git patch: %w", err)
+			}
+			obsolete = append(obsolete, migrated.Git.PatchFile)
+			migrated.Git.PatchFile = ""
+		}
+	}
+
+	migrated.Version = CurrentVersion
+	if err := writeJSON(filepath.Join(dir, MetadataFile), &migrated); err != nil {
+		return fmt.Errorf("saving migrated metadata: %w", err)
+	}
+	if err := writeJSON(filepath.Join(dir, SessionFile), migrated.Session); err != nil {
+		return fmt.Errorf("saving migrated session state: %w", err)
+	}
+	for _, rel := range obsolete {
+		_ = os.Remove(filepath.Join(dir, rel))
+	}
+
+	*cp = migrated
+	return nil
+}
+
+// MigrateResult summarizes a checkpoint migration run.
+type MigrateResult struct {
+	// Checkpoints is the number of checkpoints scanned
+	Checkpoints int `json:"checkpoints"`
+	// Migrated lists the upgraded checkpoints as session/id
+	Migrated []string `json:"migrated"`
+	// Failed lists the checkpoints that could not be upgraded
+	Failed []string `json:"failed,omitempty"`
+}
+
+// Migrate upgrades every checkpoint written before the blob store, moving
+// its scrollback and git patch into it. Loading a checkpoint never rewrites
+// it, so old checkpoints stay readable in their own format until this runs.
+func (s *Storage) Migrate() (*MigrateResult, error) {
+	result := &MigrateResult{Migrated: []string{}}
+	checkpoints, err := s.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, cp := range checkpoints {
+		result.Checkpoints++
+		if cp.Version < MinVersion || cp.Version >= CurrentVersion {
+			continue
+		}
+		name := cp.SessionName + "/" + cp.ID
+		if err := s.migrateToBlobs(s.CheckpointDir(cp.SessionName, cp.ID), cp); err != nil {
+			slog.Warn("checkpoint migration failed", "checkpoint", name, "from_version", cp.Version, "error", err)
+			result.Failed = append(result.Failed, name)
+			continue
+		}
+		result.Migrated = append(result.Migrated, name)
+	}
+	sort.Strings(result.Migrated)
+	if len(result.Failed) > 0 {
+		return result, fmt.Errorf("%d checkpoint(s) could not be migrated", len(result.Failed))
+	}
+	return result, nil
+}
+
+// GCOptions configures blob garbage collection.
+type GCOptions struct {
+	// DryRun reports what would be removed without deleting anything
+	DryRun bool
+	// GracePeriod keeps unreferenced blobs younger than this
+	// (default BlobGCGracePeriod; negative disables it)
+	GracePeriod time.Duration
+}
+
+// GCResult summarizes a blob garbage collection run.
+type GCResult struct {
+	// Checkpoints is the number of checkpoints scanned for references
+	Checkpoints int `json:"checkpoints"`
+	// Blobs is the number of blobs in the store before collection
+	Blobs int `json:"blobs"`
+	// Referenced is the number of blobs still in use
+	Referenced int `json:"referenced"`
+	// Removed lists the hashes of unreferenced blobs (removed unless DryRun)
+	Removed []string `json:"removed"`
+	// FreedBytes is the on-disk size of the removed blobs
+	FreedBytes int64 `json:"freed_bytes"`
+	// Kept is the number of unreferenced blobs spared by the grace period
+	Kept int `json:"kept"`
+	// DryRun is true if nothing was deleted
+	DryRun bool `json:"dry_run"`
+}
+
+// GC removes blobs that no checkpoint references. It refuses to run if any
+// checkpoint's metadata cannot be read, since its references are unknown.
+func (s *Storage) GC(opts GCOptions) (*GCResult, error) {
+	grace := opts.GracePeriod
+	if grace == 0 {
+		grace = BlobGCGracePeriod
+	}
+	result := &GCResult{DryRun: opts.DryRun, Removed: []string{}}
+
+	referenced := make(map[string]bool)
+	sessions, err := os.ReadDir(s.BaseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("reading checkpoints directory: %w", err)
+	}
+	for _, session := range sessions {
+		if !session.IsDir() || session.Name() == BlobsDir {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(s.BaseDir, session.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading session directory: %w", err)
+		}
+		for _, entry := range entries {
+			metaPath := filepath.Join(s.BaseDir, session.Name(), entry.Name(), MetadataFile)
+			if !entry.IsDir() || !fileExists(metaPath) {
+				continue
+			}
+			cp, err := s.Load(session.Name(), entry.Name())
+			if err != nil {
+				return nil, fmt.Errorf("checkpoint %s/%s: %w (not collecting blobs it may reference)", session.Name(), entry.Name(), err)
+			}
+			result.Checkpoints++
+			for _, hash := range cp.BlobRefs() {
+				referenced[hash] = true
+			}
+		}
+	}
+
+	cutoff := time.Now().Add(-grace)
+	blobRoot := filepath.Join(s.BaseDir, BlobsDir)
+	err = filepath.WalkDir(blobRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == blobRoot {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !isBlobHash(d.Name()) {
+			return nil
+		}
+		result.Blobs++
+		if referenced[d.Name()] {
+			result.Referenced++
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if grace > 0 && info.ModTime().After(cutoff) {
+			result.Kept++
+			return nil
+		}
+		if !opts.DryRun {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("removing blob %s: %w", d.Name(), err)
+			}
+		}
+		result.Removed = append(result.Removed, d.Name())
+		result.FreedBytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	sort.Strings(result.Removed)
+	return result, nil
+}
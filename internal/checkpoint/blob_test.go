@@ -0,0 +1,403 @@
+package checkpoint
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func numberedLines(from, to int) string {
+	var sb strings.Builder
+	for i := from; i < to; i++ {
+		fmt.Fprintf(&sb, "line %d\n", i)
+	}
+	return sb.String()
+}
+
+func TestSplitChunks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"unterminated", "a"},
+		{"short", "a\nb\n"},
+		{"numbered", numberedLines(0, 5000)},
+		{"numbered with tail", numberedLines(0, 3*BlobChunkLines) + "tail"},
+		{"repeated line", strings.Repeat("same\n", 10*BlobChunkLines)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := splitChunks(tt.content, BlobChunkLines)
+			if got := strings.Join(chunks, ""); got != tt.content {
+				t.Fatal("chunks do not reassemble to the original content")
+			}
+			if tt.content == "" && len(chunks) != 0 {
+				t.Errorf("splitChunks(\"\") = %d chunks, want 0", len(chunks))
+			}
+			for i, chunk := range chunks {
+				lines := strings.Count(chunk, "\n")
+				if lines > 4*BlobChunkLines {
+					t.Errorf("chunk %d has %d lines, want at most %d", i, lines, 4*BlobChunkLines)
+				}
+				if i < len(chunks)-1 && lines < BlobChunkLines/4 {
+					t.Errorf("chunk %d has %d lines, want at least %d", i, lines, BlobChunkLines/4)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitChunksSurvivesShiftedWindow(t *testing.T) {
+	// tmux keeps a fixed number of history lines, so a later capture of a
+	// busy pane has lost lines at the top and gained lines at the bottom.
+	before := splitChunks(numberedLines(0, 5000), BlobChunkLines)
+	after := splitChunks(numberedLines(137, 5137), BlobChunkLines)
+
+	seen := make(map[string]bool)
+	for _, chunk := range before {
+		seen[chunk] = true
+	}
+	reused := 0
+	for _, chunk := range after {
+		if seen[chunk] {
+			reused++
+		}
+	}
+	// Only the chunks touching the dropped head and the new tail change.
+	if len(after)-reused > 3 {
+		t.Errorf("reused %d of %d chunks after shifting the window, want all but at most 3", reused, len(after))
+	}
+}
+
+func TestPutChunksSharesUnchangedBlocks(t *testing.T) {
+	storage := NewStorageWithDir(t.TempDir())
+
+	first, err := storage.PutChunks(numberedLines(0, 2*BlobChunkLines+10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := storage.PutChunks(numberedLines(0, 2*BlobChunkLines+50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) < 2 || len(second) < len(first) {
+		t.Fatalf("chunks = %d and %d", len(first), len(second))
+	}
+	last := len(first) - 1
+	for i := 0; i < last; i++ {
+		if first[i] != second[i] {
+			t.Errorf("chunk %d not shared: %s vs %s", i, first[i], second[i])
+		}
+	}
+	if first[last] == second[last] {
+		t.Error("expected the grown last chunk to change")
+	}
+
+	content, err := storage.GetChunks(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != numberedLines(0, 2*BlobChunkLines+50) {
+		t.Error("GetChunks returned different content")
+	}
+
+	// Corrupt a blob: reading it must fail rather than return wrong content.
+	compressed, _ := gzipCompress([]byte("tampered\n"))
+	if err := os.WriteFile(storage.BlobPath(second[last]), compressed, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.GetChunks(second); err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Errorf("GetChunks on corrupt blob = %v, want corruption error", err)
+	}
+}
+
+// writeV1Checkpoint writes a checkpoint the way versions before the blob
+// store did: scrollback and patch as files in the checkpoint directory.
+func writeV1Checkpoint(t *testing.T, storage *Storage, session, id, scrollback, patch string) {
+	t.Helper()
+	cp := &Checkpoint{
+		Version: 1, ID: id, SessionName: session, CreatedAt: time.Now(), PaneCount: 2,
+		Session: SessionState{Panes: []PaneState{{Index: 0, ID: "%0"}, {Index: 1, ID: "%1"}}},
+		Git:     GitState{Branch: "main", Commit: "abc"},
+	}
+	if err := storage.Save(cp); err != nil {
+		t.Fatal(err)
+	}
+	file, err := storage.SaveScrollback(session, id, "%0", scrollback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.Session.Panes[0].ScrollbackFile = file
+
+	gz, _ := gzipCompress([]byte(scrollback + "second pane\n"))
+	if cp.Session.Panes[1].ScrollbackFile, err = storage.SaveCompressedScrollback(session, id, "%1", gz); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.SaveGitPatch(session, id, patch); err != nil {
+		t.Fatal(err)
+	}
+	cp.Git.PatchFile = GitPatchFile
+	if err := storage.Save(cp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeBlobCheckpoint writes a v1 checkpoint and migrates it into the blob store.
+func writeBlobCheckpoint(t *testing.T, storage *Storage, session, id, scrollback, patch string) {
+	t.Helper()
+	writeV1Checkpoint(t, storage, session, id, scrollback, patch)
+	if _, err := storage.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateUpgradesV1Checkpoint(t *testing.T) {
+	storage := NewStorageWithDir(t.TempDir())
+	writeV1Checkpoint(t, storage, "proj", "cp1", "hello\nworld\n", "+patch\n")
+
+	// Loading is read-only: the checkpoint keeps its old format.
+	old, err := storage.Load("proj", "cp1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.Version != 1 || old.Session.Panes[0].ScrollbackFile == "" {
+		t.Errorf("Load rewrote the checkpoint: %+v", old)
+	}
+	if got, err := storage.LoadScrollback("proj", "cp1", "%0"); err != nil || got != "hello\nworld\n" {
+		t.Errorf("LoadScrollback before migration = %q, %v", got, err)
+	}
+
+	result, err := storage.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Checkpoints != 1 || len(result.Migrated) != 1 || result.Migrated[0] != "proj/cp1" {
+		t.Errorf("Migrate = %+v", result)
+	}
+
+	cp, err := storage.Load("proj", "cp1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", cp.Version, CurrentVersion)
+	}
+	for _, pane := range cp.Session.Panes {
+		if pane.ScrollbackFile != "" || len(pane.ScrollbackBlobs) == 0 {
+			t.Errorf("pane %s not migrated: %+v", pane.ID, pane)
+		}
+	}
+	if cp.Git.PatchFile != "" || len(cp.Git.PatchBlobs) == 0 || !cp.HasGitPatch() {
+		t.Errorf("patch not migrated: %+v", cp.Git)
+	}
+
+	dir := storage.CheckpointDir("proj", "cp1")
+	for _, old := range []string{"panes/pane__0.txt", "panes/pane__1.txt.gz", GitPatchFile} {
+		if fileExists(filepath.Join(dir, old)) {
+			t.Errorf("%s still present after migration", old)
+		}
+	}
+
+	// Readers see the same content as before.
+	if got, err := storage.LoadScrollback("proj", "cp1", "%0"); err != nil || got != "hello\nworld\n" {
+		t.Errorf("LoadScrollback = %q, %v", got, err)
+	}
+	if got, err := storage.LoadCompressedScrollback("proj", "cp1", "%1"); err != nil || got != "hello\nworld\nsecond pane\n" {
+		t.Errorf("LoadCompressedScrollback = %q, %v", got, err)
+	}
+	if got, err := storage.LoadGitPatch("proj", "cp1"); err != nil || got != "+patch\n" {
+		t.Errorf("LoadGitPatch = %q, %v", got, err)
+	}
+
+	// The migration is persisted.
+	again, err := storage.Load("proj", "cp1")
+	if err != nil || again.Version != CurrentVersion || len(again.Session.Panes[0].ScrollbackBlobs) == 0 {
+		t.Errorf("reloaded checkpoint = %+v, %v", again, err)
+	}
+	if result := again.Verify(storage); !result.FilesPresent || !result.ChecksumsValid {
+		t.Errorf("Verify after migration = %+v", result)
+	}
+	if result, err := storage.Migrate(); err != nil || len(result.Migrated) != 0 {
+		t.Errorf("second Migrate = %+v, %v", result, err)
+	}
+}
+
+func TestGCRemovesUnreferencedBlobs(t *testing.T) {
+	storage := NewStorageWithDir(t.TempDir())
+	shared := numberedLines(0, 4*BlobChunkLines)
+	writeBlobCheckpoint(t, storage, "proj", "keep", shared+"kept tail\n", "+keep\n")
+	writeBlobCheckpoint(t, storage, "proj", "drop", shared+"dropped tail\n", "+drop\n")
+
+	keep, err := storage.Load("proj", "keep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	drop, err := storage.Load("proj", "drop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Delete("proj", "drop"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fresh blobs are protected by the grace period.
+	result, err := storage.GC(GCOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Removed) != 0 || result.Kept == 0 {
+		t.Errorf("GC within grace period = %+v", result)
+	}
+
+	result, err = storage.GC(GCOptions{DryRun: true, GracePeriod: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Removed) == 0 || result.Checkpoints != 1 {
+		t.Fatalf("dry run = %+v", result)
+	}
+	for _, hash := range result.Removed {
+		if !storage.HasBlob(hash) {
+			t.Errorf("dry run removed %s", hash)
+		}
+	}
+
+	result, err = storage.GC(GCOptions{GracePeriod: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FreedBytes == 0 || result.Referenced+len(result.Removed) != result.Blobs {
+		t.Errorf("GC = %+v", result)
+	}
+	for _, hash := range keep.BlobRefs() {
+		if !storage.HasBlob(hash) {
+			t.Errorf("referenced blob %s was removed", hash)
+		}
+	}
+	removed := 0
+	for _, hash := range drop.BlobRefs() {
+		if !storage.HasBlob(hash) {
+			removed++
+		}
+	}
+	if removed == 0 || removed == len(drop.BlobRefs()) {
+		t.Errorf("removed %d of the deleted checkpoint's %d blobs; shared chunks must stay", removed, len(drop.BlobRefs()))
+	}
+	if _, err := storage.Load("proj", "keep"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := storage.LoadScrollback("proj", "keep", "%0"); err != nil || got != shared+"kept tail\n" {
+		t.Errorf("scrollback after GC = %q, %v", got, err)
+	}
+
+	// A checkpoint with unreadable metadata stops collection.
+	bad := storage.CheckpointDir("other", "broken")
+	if err := os.MkdirAll(bad, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bad, MetadataFile), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.GC(GCOptions{GracePeriod: -1}); err == nil {
+		t.Error("GC succeeded with an unreadable checkpoint")
+	}
+}
+
+func TestVerifyDetectsBadBlobs(t *testing.T) {
+	storage := NewStorageWithDir(t.TempDir())
+	writeBlobCheckpoint(t, storage, "proj", "cp1", "hello\n", "+patch\n")
+	cp, err := storage.Load("proj", "cp1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compressed, _ := gzipCompress([]byte("bye\n"))
+	if err := os.WriteFile(storage.BlobPath(cp.Session.Panes[0].ScrollbackBlobs[0]), compressed, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(storage.BlobPath(cp.Git.PatchBlobs[0])); err != nil {
+		t.Fatal(err)
+	}
+
+	result := cp.Verify(storage)
+	if result.Valid || result.ChecksumsValid || result.FilesPresent {
+		t.Errorf("Verify = %+v, want corrupt and missing blobs reported", result)
+	}
+	joined := strings.Join(result.Errors, "\n")
+	if !strings.Contains(joined, "bad scrollback blob") || !strings.Contains(joined, "missing git patch blob") {
+		t.Errorf("errors = %v", result.Errors)
+	}
+}
+
+func TestExportImportMaterializesBlobs(t *testing.T) {
+	tmpDir := t.TempDir()
+	exportStorage := NewStorageWithDir(filepath.Join(tmpDir, "export"))
+	importStorage := NewStorageWithDir(filepath.Join(tmpDir, "import"))
+	writeBlobCheckpoint(t, exportStorage, "proj", "cp1", "hello\nworld\n", "+patch\n")
+
+	archive := filepath.Join(tmpDir, "cp.tar.gz")
+	if _, err := exportStorage.Export("proj", "cp1", archive, DefaultExportOptions()); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	// The archive carries plain files, not blob references.
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	contents := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		contents[header.Name] = string(data)
+	}
+	if contents["panes/pane__0.txt"] != "hello\nworld\n" || contents["panes/pane__1.txt"] != "hello\nworld\nsecond pane\n" || contents[GitPatchFile] != "+patch\n" {
+		t.Errorf("archive files = %v", contents)
+	}
+	if strings.Contains(contents[MetadataFile], "scrollback_blobs") || strings.Contains(contents[MetadataFile], "patch_blobs") {
+		t.Errorf("exported metadata references blobs:\n%s", contents[MetadataFile])
+	}
+
+	imported, err := importStorage.Import(archive, ImportOptions{VerifyChecksums: true, TargetDir: "/work"})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(imported.Session.Panes[0].ScrollbackBlobs) == 0 || len(imported.Git.PatchBlobs) == 0 {
+		t.Errorf("imported checkpoint not stored as blobs: %+v", imported)
+	}
+	loaded, err := importStorage.Load("proj", "cp1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.WorkingDir != "/work" {
+		t.Errorf("WorkingDir = %q", loaded.WorkingDir)
+	}
+	if got, err := importStorage.ReadScrollback(loaded, loaded.Session.Panes[1]); err != nil || got != "hello\nworld\nsecond pane\n" {
+		t.Errorf("imported scrollback = %q, %v", got, err)
+	}
+	if got, err := importStorage.ReadGitPatch(loaded); err != nil || got != "+patch\n" {
+		t.Errorf("imported patch = %q, %v", got, err)
+	}
+	if result := loaded.Verify(importStorage); !result.FilesPresent || !result.ChecksumsValid {
+		t.Errorf("Verify imported = %+v", result)
+	}
+}
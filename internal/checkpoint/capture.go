@@ -220,8 +220,8 @@ func (c *Capturer) captureGitState(workingDir, sessionName, checkpointID string)
 			return state, fmt.Errorf("getting git diff: %w", err)
 		}
 		if patch != "" {
-			if err := c.storage.SaveGitPatch(sessionName, checkpointID, patch); err == nil {
-				state.PatchFile = GitPatchFile
+			if hashes, err := c.storage.PutChunks(patch); err == nil {
+				state.PatchBlobs = hashes
 			}
 		}
 	}
@@ -498,13 +498,13 @@ func TestCapturer_CaptureGitState_DirtySavesPatch(t *testing.T) {
 	if !state.IsDirty {
 		t.Fatal("expected dirty state")
 	}
-	if state.PatchFile != GitPatchFile {
-		t.Fatalf("expected patch file %q, got %q", GitPatchFile, state.PatchFile)
+	if len(state.PatchBlobs) == 0 || state.PatchFile != "" {
+		t.Fatalf("expected patch in the blob store, got file %q blobs %v", state.PatchFile, state.PatchBlobs)
 	}
 
-	patch, err := storage.LoadGitPatch("session", checkpointID)
+	patch, err := storage.ReadGitPatch(&Checkpoint{SessionName: "session", ID: checkpointID, Git: state})
 	if err != nil {
-		t.Fatalf("LoadGitPatch failed: %v", err)
+		t.Fatalf("ReadGitPatch failed: %v", err)
 	}
 	if patch == "" {
 		t.Fatal("expected git patch content")
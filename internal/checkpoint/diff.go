@@ -0,0 +1,486 @@
+package checkpoint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/output"
+)
+
+// Diff output formats.
+const (
+	DiffFormatText     = "text"
+	DiffFormatJSON     = "json"
+	DiffFormatMarkdown = "markdown"
+)
+
+// Assignment change kinds.
+const (
+	AssignmentAdded   = "added"
+	AssignmentRemoved = "removed"
+	AssignmentChanged = "changed"
+)
+
+// Diff describes what changed between two checkpoints.
+type Diff struct {
+	SessionName  string             `json:"session_name"`
+	From         DiffRef            `json:"from"`
+	To           DiffRef            `json:"to"`
+	PanesAdded   []PaneState        `json:"panes_added,omitempty"`
+	PanesRemoved []PaneState        `json:"panes_removed,omitempty"`
+	Panes        []PaneDiff         `json:"panes,omitempty"`
+	Git          *GitDiff           `json:"git,omitempty"`
+	Assignments  []AssignmentChange `json:"assignments,omitempty"`
+	BV           *BVDiff            `json:"bv,omitempty"`
+}
+
+// DiffRef identifies one side of a diff.
+type DiffRef struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PaneDiff describes a pane present in both checkpoints. Panes are matched by
+// title, or by pane ID when untitled.
+type PaneDiff struct {
+	Title        string   `json:"title"`
+	FromIndex    int      `json:"from_index"`
+	ToIndex      int      `json:"to_index"`
+	FromAgent    string   `json:"from_agent_type,omitempty"`
+	ToAgent      string   `json:"to_agent_type,omitempty"`
+	FromCommand  string   `json:"from_command,omitempty"`
+	ToCommand    string   `json:"to_command,omitempty"`
+	NewOutput    []string `json:"new_output,omitempty"`    // scrollback lines not in the earlier capture
+	DroppedLines int      `json:"dropped_lines,omitempty"` // earlier lines no longer in the capture
+}
+
+// Changed reports whether anything about the pane differs.
+func (p PaneDiff) Changed() bool {
+	return p.FromIndex != p.ToIndex || p.FromAgent != p.ToAgent || p.FromCommand != p.ToCommand ||
+		len(p.NewOutput) > 0 || p.DroppedLines > 0
+}
+
+// GitDiff describes git state changes. Patch lines compare the uncommitted
+// changes captured with each checkpoint.
+type GitDiff struct {
+	FromBranch   string   `json:"from_branch"`
+	ToBranch     string   `json:"to_branch"`
+	FromCommit   string   `json:"from_commit"`
+	ToCommit     string   `json:"to_commit"`
+	FromDirty    bool     `json:"from_dirty"`
+	ToDirty      bool     `json:"to_dirty"`
+	PatchAdded   []string `json:"patch_added,omitempty"`
+	PatchRemoved []string `json:"patch_removed,omitempty"`
+}
+
+// BranchChanged reports whether the branch differs.
+func (g *GitDiff) BranchChanged() bool { return g.FromBranch != g.ToBranch }
+
+// HeadChanged reports whether HEAD moved.
+func (g *GitDiff) HeadChanged() bool { return g.FromCommit != g.ToCommit }
+
+// PatchChanged reports whether the uncommitted changes differ.
+func (g *GitDiff) PatchChanged() bool { return len(g.PatchAdded) > 0 || len(g.PatchRemoved) > 0 }
+
+// AssignmentChange is a bead assignment that appeared, disappeared or
+// changed status, pane or agent.
+type AssignmentChange struct {
+	BeadID    string              `json:"bead_id"`
+	BeadTitle string              `json:"bead_title,omitempty"`
+	Kind      string              `json:"kind"`
+	From      *AssignmentSnapshot `json:"from,omitempty"`
+	To        *AssignmentSnapshot `json:"to,omitempty"`
+}
+
+// BVDiff describes BV triage changes.
+type BVDiff struct {
+	From            *BVSnapshot `json:"from,omitempty"`
+	To              *BVSnapshot `json:"to,omitempty"`
+	OpenDelta       int         `json:"open_delta"`
+	ActionableDelta int         `json:"actionable_delta"`
+	BlockedDelta    int         `json:"blocked_delta"`
+	InProgressDelta int         `json:"in_progress_delta"`
+	TopPicksAdded   []string    `json:"top_picks_added,omitempty"`
+	TopPicksRemoved []string    `json:"top_picks_removed,omitempty"`
+}
+
+// Empty reports whether the checkpoints are equivalent.
+func (d *Diff) Empty() bool {
+	if len(d.PanesAdded) > 0 || len(d.PanesRemoved) > 0 || len(d.Assignments) > 0 || d.Git != nil || d.BV != nil {
+		return false
+	}
+	for _, p := range d.Panes {
+		if p.Changed() {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffCheckpoints compares two checkpoints of a session, reading scrollback
+// and git patches from storage.
+func DiffCheckpoints(storage *Storage, from, to *Checkpoint) (*Diff, error) {
+	if from == nil || to == nil {
+		return nil, fmt.Errorf("diff requires two checkpoints")
+	}
+	d := &Diff{
+		SessionName: to.SessionName,
+		From:        DiffRef{ID: from.ID, Name: from.Name, CreatedAt: from.CreatedAt},
+		To:          DiffRef{ID: to.ID, Name: to.Name, CreatedAt: to.CreatedAt},
+	}
+
+	fromPanes := panesByKey(from.Session.Panes)
+	toPanes := panesByKey(to.Session.Panes)
+	for _, p := range from.Session.Panes {
+		if _, ok := toPanes[paneKey(p)]; !ok {
+			d.PanesRemoved = append(d.PanesRemoved, p)
+		}
+	}
+	for _, p := range to.Session.Panes {
+		before, ok := fromPanes[paneKey(p)]
+		if !ok {
+			d.PanesAdded = append(d.PanesAdded, p)
+			continue
+		}
+		pd := PaneDiff{
+			Title:       p.Title,
+			FromIndex:   before.Index,
+			ToIndex:     p.Index,
+			FromAgent:   before.AgentType,
+			ToAgent:     p.AgentType,
+			FromCommand: before.Command,
+			ToCommand:   p.Command,
+		}
+		if pd.Title == "" {
+			pd.Title = p.ID
+		}
+		if before.HasScrollback() || p.HasScrollback() {
+			delta := output.DiffLines(
+				loadDiffScrollback(storage, from, before),
+				loadDiffScrollback(storage, to, p),
+			)
+			pd.NewOutput = delta.Added
+			pd.DroppedLines = len(delta.Removed)
+		}
+		d.Panes = append(d.Panes, pd)
+	}
+
+	git, err := diffGit(storage, from, to)
+	if err != nil {
+		return nil, err
+	}
+	d.Git = git
+	d.Assignments = diffAssignments(from.Assignments, to.Assignments)
+	d.BV = diffBV(from.BVSummary, to.BVSummary)
+	return d, nil
+}
+
+func paneKey(p PaneState) string {
+	if p.Title != "" {
+		return "title:" + p.Title
+	}
+	return "id:" + p.ID
+}
+
+func panesByKey(panes []PaneState) map[string]PaneState {
+	m := make(map[string]PaneState, len(panes))
+	for _, p := range panes {
+		m[paneKey(p)] = p
+	}
+	return m
+}
+
+func loadDiffScrollback(storage *Storage, cp *Checkpoint, p PaneState) string {
+	content, err := storage.ReadScrollback(cp, p)
+	if err != nil {
+		return ""
+	}
+	return content
+}
+
+func diffGit(storage *Storage, from, to *Checkpoint) (*GitDiff, error) {
+	g := &GitDiff{
+		FromBranch: from.Git.Branch,
+		ToBranch:   to.Git.Branch,
+		FromCommit: from.Git.Commit,
+		ToCommit:   to.Git.Commit,
+		FromDirty:  from.Git.IsDirty,
+		ToDirty:    to.Git.IsDirty,
+	}
+	fromPatch, err := storage.ReadGitPatch(from)
+	if err != nil {
+		return nil, err
+	}
+	toPatch, err := storage.ReadGitPatch(to)
+	if err != nil {
+		return nil, err
+	}
+	if fromPatch != toPatch {
+		delta := output.DiffLines(fromPatch, toPatch)
+		g.PatchAdded, g.PatchRemoved = delta.Added, delta.Removed
+	}
+	if !g.BranchChanged() && !g.HeadChanged() && !g.PatchChanged() && g.FromDirty == g.ToDirty {
+		return nil, nil
+	}
+	return g, nil
+}
+
+func diffAssignments(from, to []AssignmentSnapshot) []AssignmentChange {
+	before := make(map[string]AssignmentSnapshot, len(from))
+	for _, a := range from {
+		before[a.BeadID] = a
+	}
+	after := make(map[string]AssignmentSnapshot, len(to))
+	for _, a := range to {
+		after[a.BeadID] = a
+	}
+
+	var changes []AssignmentChange
+	for id, a := range before {
+		a := a
+		b, ok := after[id]
+		switch {
+		case !ok:
+			changes = append(changes, AssignmentChange{BeadID: id, BeadTitle: a.BeadTitle, Kind: AssignmentRemoved, From: &a})
+		case a.Status != b.Status || a.Pane != b.Pane || a.AgentType != b.AgentType || a.AgentName != b.AgentName:
+			b := b
+			changes = append(changes, AssignmentChange{BeadID: id, BeadTitle: b.BeadTitle, Kind: AssignmentChanged, From: &a, To: &b})
+		}
+	}
+	for id, b := range after {
+		if _, ok := before[id]; !ok {
+			b := b
+			changes = append(changes, AssignmentChange{BeadID: id, BeadTitle: b.BeadTitle, Kind: AssignmentAdded, To: &b})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].BeadID < changes[j].BeadID })
+	return changes
+}
+
+func diffBV(from, to *BVSnapshot) *BVDiff {
+	if from == nil && to == nil {
+		return nil
+	}
+	var a, b BVSnapshot
+	if from != nil {
+		a = *from
+	}
+	if to != nil {
+		b = *to
+	}
+	d := &BVDiff{
+		From:            from,
+		To:              to,
+		OpenDelta:       b.OpenCount - a.OpenCount,
+		ActionableDelta: b.ActionableCount - a.ActionableCount,
+		BlockedDelta:    b.BlockedCount - a.BlockedCount,
+		InProgressDelta: b.InProgressCount - a.InProgressCount,
+		TopPicksAdded:   missingFrom(b.TopPicks, a.TopPicks),
+		TopPicksRemoved: missingFrom(a.TopPicks, b.TopPicks),
+	}
+	if from != nil && to != nil && d.OpenDelta == 0 && d.ActionableDelta == 0 && d.BlockedDelta == 0 &&
+		d.InProgressDelta == 0 && len(d.TopPicksAdded) == 0 && len(d.TopPicksRemoved) == 0 {
+		return nil
+	}
+	return d
+}
+
+// missingFrom returns the items of a not in b, in order.
+func missingFrom(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, s := range b {
+		in[s] = true
+	}
+	var out []string
+	for _, s := range a {
+		if !in[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// ============================================================================
+// Rendering
+// ============================================================================
+
+// Render formats the diff as text or markdown. maxLines caps the new output
+// and patch lines shown per section (0 = no limit); JSON callers get
+// everything.
+func (d *Diff) Render(format string, maxLines int) (string, error) {
+	var markdown bool
+	switch format {
+	case "", DiffFormatText:
+	case DiffFormatMarkdown, "md":
+		markdown = true
+	default:
+		return "", fmt.Errorf("unknown diff format %q (want text, json or markdown)", format)
+	}
+
+	var b strings.Builder
+	heading := func(title string) {
+		if markdown {
+			fmt.Fprintf(&b, "\n## %s\n\n", title)
+		} else {
+			fmt.Fprintf(&b, "\n%s\n", title)
+		}
+	}
+	item := func(format string, args ...interface{}) {
+		if markdown {
+			b.WriteString("- ")
+		} else {
+			b.WriteString("  ")
+		}
+		fmt.Fprintf(&b, format, args...)
+		b.WriteString("\n")
+	}
+	block := func(lines []string) {
+		indent := "      "
+		if markdown {
+			indent = ""
+			b.WriteString("\n```\n")
+		}
+		shown := lines
+		if maxLines > 0 && len(shown) > maxLines {
+			shown = shown[len(shown)-maxLines:]
+			fmt.Fprintf(&b, "%s… %d earlier lines\n", indent, len(lines)-len(shown))
+		}
+		for _, l := range shown {
+			b.WriteString(indent + l + "\n")
+		}
+		if markdown {
+			b.WriteString("```\n\n")
+		}
+	}
+
+	title := fmt.Sprintf("Checkpoint diff %s: %s → %s (%s)", d.SessionName, d.From.ID, d.To.ID,
+		d.To.CreatedAt.Sub(d.From.CreatedAt).Round(time.Second))
+	if markdown {
+		fmt.Fprintf(&b, "# %s\n", title)
+	} else {
+		b.WriteString(title + "\n")
+	}
+	if d.Empty() {
+		b.WriteString("\nNo changes.\n")
+		return b.String(), nil
+	}
+
+	if len(d.PanesAdded) > 0 || len(d.PanesRemoved) > 0 || len(d.Panes) > 0 {
+		heading("Panes")
+		for _, p := range d.PanesAdded {
+			item("+ %s (%s, pane %d)", paneLabel(p), orUnknown(p.AgentType), p.Index)
+		}
+		for _, p := range d.PanesRemoved {
+			item("- %s (%s, pane %d)", paneLabel(p), orUnknown(p.AgentType), p.Index)
+		}
+		for _, p := range d.Panes {
+			if !p.Changed() {
+				continue
+			}
+			var notes []string
+			if p.FromIndex != p.ToIndex {
+				notes = append(notes, fmt.Sprintf("moved %d → %d", p.FromIndex, p.ToIndex))
+			}
+			if p.FromAgent != p.ToAgent {
+				notes = append(notes, fmt.Sprintf("agent %s → %s", orUnknown(p.FromAgent), orUnknown(p.ToAgent)))
+			}
+			if p.FromCommand != p.ToCommand {
+				notes = append(notes, fmt.Sprintf("command %q → %q", p.FromCommand, p.ToCommand))
+			}
+			notes = append(notes, fmt.Sprintf("%d new lines", len(p.NewOutput)))
+			if p.DroppedLines > 0 {
+				notes = append(notes, fmt.Sprintf("%d lines scrolled off", p.DroppedLines))
+			}
+			item("~ %s: %s", p.Title, strings.Join(notes, ", "))
+			if len(p.NewOutput) > 0 {
+				block(p.NewOutput)
+			}
+		}
+	}
+
+	if g := d.Git; g != nil {
+		heading("Git")
+		if g.BranchChanged() {
+			item("branch: %s → %s", orUnknown(g.FromBranch), orUnknown(g.ToBranch))
+		}
+		if g.HeadChanged() {
+			item("HEAD: %s → %s", shortCommit(g.FromCommit), shortCommit(g.ToCommit))
+		}
+		if g.FromDirty != g.ToDirty {
+			item("working tree: %s → %s", dirtyLabel(g.FromDirty), dirtyLabel(g.ToDirty))
+		}
+		if g.PatchChanged() {
+			item("uncommitted changes: +%d −%d patch lines", len(g.PatchAdded), len(g.PatchRemoved))
+			lines := make([]string, 0, len(g.PatchAdded)+len(g.PatchRemoved))
+			for _, l := range g.PatchRemoved {
+				lines = append(lines, "- "+l)
+			}
+			for _, l := range g.PatchAdded {
+				lines = append(lines, "+ "+l)
+			}
+			block(lines)
+		}
+	}
+
+	if len(d.Assignments) > 0 {
+		heading("Assignments")
+		for _, c := range d.Assignments {
+			bead := c.BeadID
+			if c.BeadTitle != "" {
+				bead += " " + c.BeadTitle
+			}
+			switch c.Kind {
+			case AssignmentAdded:
+				item("+ %s: %s on pane %d (%s)", bead, c.To.Status, c.To.Pane, orUnknown(c.To.AgentType))
+			case AssignmentRemoved:
+				item("- %s (was %s on pane %d)", bead, c.From.Status, c.From.Pane)
+			default:
+				item("~ %s: %s on pane %d → %s on pane %d", bead, c.From.Status, c.From.Pane, c.To.Status, c.To.Pane)
+			}
+		}
+	}
+
+	if v := d.BV; v != nil {
+		heading("BV triage")
+		item("open %+d, ready %+d, blocked %+d, in progress %+d",
+			v.OpenDelta, v.ActionableDelta, v.BlockedDelta, v.InProgressDelta)
+		if len(v.TopPicksAdded) > 0 {
+			item("new top picks: %s", strings.Join(v.TopPicksAdded, ", "))
+		}
+		if len(v.TopPicksRemoved) > 0 {
+			item("no longer top picks: %s", strings.Join(v.TopPicksRemoved, ", "))
+		}
+	}
+	return b.String(), nil
+}
+
+func paneLabel(p PaneState) string {
+	if p.Title != "" {
+		return p.Title
+	}
+	return p.ID
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return orUnknown(sha)
+}
+
+func dirtyLabel(dirty bool) string {
+	if dirty {
+		return "dirty"
+	}
+	return "clean"
+}
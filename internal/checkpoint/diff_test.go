@@ -0,0 +1,135 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func saveDiffFixture(t *testing.T, storage *Storage, cp *Checkpoint, scrollback map[string]string, patch string) {
+	t.Helper()
+	for i, p := range cp.Session.Panes {
+		content, ok := scrollback[p.ID]
+		if !ok {
+			continue
+		}
+		file, err := storage.SaveScrollback(cp.SessionName, cp.ID, p.ID, content)
+		if err != nil {
+			t.Fatalf("SaveScrollback: %v", err)
+		}
+		cp.Session.Panes[i].ScrollbackFile = file
+	}
+	if patch != "" {
+		if err := storage.SaveGitPatch(cp.SessionName, cp.ID, patch); err != nil {
+			t.Fatalf("SaveGitPatch: %v", err)
+		}
+		cp.Git.PatchFile = GitPatchFile
+	}
+	if err := storage.Save(cp); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+}
+
+func TestDiffCheckpoints(t *testing.T) {
+	storage := NewStorageWithDir(t.TempDir())
+	created := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	from := &Checkpoint{
+		Version: CurrentVersion, ID: "20260501-100000-a", Name: "a", SessionName: "proj", CreatedAt: created,
+		Session: SessionState{Panes: []PaneState{
+			{Index: 1, ID: "%1", Title: "proj__cc_1", AgentType: "cc"},
+			{Index: 2, ID: "%2", Title: "proj__cod_1", AgentType: "cod"},
+		}},
+		Git:         GitState{Branch: "main", Commit: "aaaaaaaaaaaaaaaa", IsDirty: true},
+		Assignments: []AssignmentSnapshot{{BeadID: "bd-1", Status: "assigned", Pane: 1}, {BeadID: "bd-2", Status: "working", Pane: 2}},
+		BVSummary:   &BVSnapshot{OpenCount: 5, ActionableCount: 3, TopPicks: []string{"bd-1", "bd-3"}},
+	}
+	saveDiffFixture(t, storage, from, map[string]string{"%1": "$ claude\nreading files\n"}, "+old line\n")
+
+	to := &Checkpoint{
+		Version: CurrentVersion, ID: "20260501-110000-b", Name: "b", SessionName: "proj", CreatedAt: created.Add(time.Hour),
+		Session: SessionState{Panes: []PaneState{
+			{Index: 1, ID: "%1", Title: "proj__cc_1", AgentType: "cc"},
+			{Index: 3, ID: "%5", Title: "proj__gmi_1", AgentType: "gmi"},
+		}},
+		Git:         GitState{Branch: "feature", Commit: "bbbbbbbbbbbbbbbb", IsDirty: true},
+		Assignments: []AssignmentSnapshot{{BeadID: "bd-1", Status: "working", Pane: 1}, {BeadID: "bd-4", Status: "assigned", Pane: 3}},
+		BVSummary:   &BVSnapshot{OpenCount: 4, ActionableCount: 3, TopPicks: []string{"bd-3", "bd-4"}},
+	}
+	saveDiffFixture(t, storage, to, map[string]string{"%1": "$ claude\nreading files\nedited main.go\ntests pass\n"}, "+new line\n")
+
+	d, err := DiffCheckpoints(storage, from, to)
+	if err != nil {
+		t.Fatalf("DiffCheckpoints: %v", err)
+	}
+
+	if len(d.PanesAdded) != 1 || d.PanesAdded[0].Title != "proj__gmi_1" {
+		t.Errorf("PanesAdded = %+v", d.PanesAdded)
+	}
+	if len(d.PanesRemoved) != 1 || d.PanesRemoved[0].Title != "proj__cod_1" {
+		t.Errorf("PanesRemoved = %+v", d.PanesRemoved)
+	}
+	if len(d.Panes) != 1 || strings.Join(d.Panes[0].NewOutput, "|") != "edited main.go|tests pass" || d.Panes[0].DroppedLines != 0 {
+		t.Errorf("Panes = %+v", d.Panes)
+	}
+	if d.Git == nil || !d.Git.BranchChanged() || !d.Git.HeadChanged() ||
+		strings.Join(d.Git.PatchAdded, "") != "+new line" || strings.Join(d.Git.PatchRemoved, "") != "+old line" {
+		t.Errorf("Git = %+v", d.Git)
+	}
+
+	var kinds []string
+	for _, c := range d.Assignments {
+		kinds = append(kinds, c.BeadID+":"+c.Kind)
+	}
+	if got := strings.Join(kinds, ","); got != "bd-1:changed,bd-2:removed,bd-4:added" {
+		t.Errorf("Assignments = %s", got)
+	}
+	if d.BV == nil || d.BV.OpenDelta != -1 || strings.Join(d.BV.TopPicksAdded, ",") != "bd-4" || strings.Join(d.BV.TopPicksRemoved, ",") != "bd-1" {
+		t.Errorf("BV = %+v", d.BV)
+	}
+
+	text, err := d.Render(DiffFormatText, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"+ proj__gmi_1", "- proj__cod_1", "2 new lines", "… 1 earlier lines", "tests pass", "branch: main → feature", "bd-4", "open -1"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text rendering missing %q:\n%s", want, text)
+		}
+	}
+	md, err := d.Render(DiffFormatMarkdown, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(md, "# Checkpoint diff proj") || !strings.Contains(md, "## Git") || !strings.Contains(md, "```\nedited main.go\ntests pass\n```") {
+		t.Errorf("markdown rendering:\n%s", md)
+	}
+	if _, err := d.Render("html", 0); err == nil {
+		t.Error("Render(html) succeeded, want error")
+	}
+	if _, err := json.Marshal(d); err != nil {
+		t.Errorf("json.Marshal: %v", err)
+	}
+}
+
+func TestDiffCheckpointsIdentical(t *testing.T) {
+	storage := NewStorageWithDir(t.TempDir())
+	cp := &Checkpoint{
+		Version: CurrentVersion, ID: "20260501-100000-a", SessionName: "proj",
+		Session: SessionState{Panes: []PaneState{{Index: 1, ID: "%1", Title: "proj__cc_1"}}},
+		Git:     GitState{Branch: "main", Commit: "abc"},
+	}
+	saveDiffFixture(t, storage, cp, map[string]string{"%1": "hello\n"}, "")
+
+	d, err := DiffCheckpoints(storage, cp, cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Empty() {
+		t.Errorf("diff of a checkpoint with itself = %+v", d)
+	}
+	if text, _ := d.Render(DiffFormatText, 0); !strings.Contains(text, "No changes.") {
+		t.Errorf("text = %q", text)
+	}
+}
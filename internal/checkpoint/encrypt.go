@@ -0,0 +1,176 @@
+package checkpoint
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Dicklesworthstone/ntm/internal/encryption"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+var (
+	// encryptionEnabled indicates whether checkpoint files are encrypted.
+	encryptionEnabled bool
+	// encryptKey is the active AES-256 key for writing checkpoint files.
+	encryptKey []byte
+	// decryptKeys holds all keyring keys for decryption (includes encryptKey).
+	decryptKeys [][]byte
+	encryptMu   sync.RWMutex
+)
+
+// EncryptionConfig holds resolved encryption keys for checkpoint storage.
+type EncryptionConfig struct {
+	Enabled     bool
+	EncryptKey  []byte   // Active key for writing files
+	DecryptKeys [][]byte // All keys for reading (keyring)
+}
+
+// SetEncryptionConfig sets the global encryption config for checkpoint
+// metadata, scrollback blobs and git patches. Pass nil to disable encryption.
+// Files written while encryption was off stay readable either way.
+func SetEncryptionConfig(cfg *EncryptionConfig) {
+	encryptMu.Lock()
+	defer encryptMu.Unlock()
+	if cfg != nil && cfg.Enabled && len(cfg.EncryptKey) > 0 {
+		encryptionEnabled = true
+		encryptKey = make([]byte, len(cfg.EncryptKey))
+		copy(encryptKey, cfg.EncryptKey)
+		decryptKeys = make([][]byte, len(cfg.DecryptKeys))
+		for i, k := range cfg.DecryptKeys {
+			decryptKeys[i] = make([]byte, len(k))
+			copy(decryptKeys[i], k)
+		}
+	} else {
+		encryptionEnabled = false
+		encryptKey = nil
+		decryptKeys = nil
+	}
+}
+
+// GetEncryptionEnabled returns whether encryption is currently enabled.
+func GetEncryptionEnabled() bool {
+	encryptMu.RLock()
+	defer encryptMu.RUnlock()
+	return encryptionEnabled
+}
+
+// sealData encrypts file contents if encryption is enabled.
+func sealData(data []byte) ([]byte, error) {
+	encryptMu.RLock()
+	enabled := encryptionEnabled
+	key := encryptKey
+	encryptMu.RUnlock()
+
+	if !enabled || key == nil {
+		return data, nil
+	}
+	return encryption.EncryptData(key, data)
+}
+
+// openData decrypts file contents written by sealData. Plaintext files are
+// returned as-is for backward compatibility.
+func openData(data []byte) ([]byte, error) {
+	if !encryption.IsEncryptedData(data) {
+		return data, nil
+	}
+
+	encryptMu.RLock()
+	keys := decryptKeys
+	encryptMu.RUnlock()
+
+	plaintext, err := encryption.DecryptDataWithKeyring(keys, data)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting checkpoint data: %w", err)
+	}
+	return plaintext, nil
+}
+
+// writeStoredFile writes a checkpoint file atomically, encrypting it if
+// encryption is enabled.
+func writeStoredFile(path string, data []byte) error {
+	sealed, err := sealData(data)
+	if err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(path, sealed, 0600)
+}
+
+// readStoredFile reads a checkpoint file, decrypting it if needed.
+func readStoredFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return openData(data)
+}
+
+// isEncryptedFile reports whether the file at path is encrypted.
+func isEncryptedFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 16)
+	n, _ := f.Read(head)
+	return encryption.IsEncryptedData(head[:n])
+}
+
+// RekeyResult summarizes a re-encryption run.
+type RekeyResult struct {
+	// Files is the number of files re-encrypted with the active key
+	Files int `json:"files"`
+	// Plaintext is how many of them were not encrypted before
+	Plaintext int `json:"plaintext"`
+	// Failed lists files that could not be decrypted with any keyring key
+	Failed []string `json:"failed,omitempty"`
+}
+
+// Rekey re-encrypts every checkpoint file and blob with the active key, so
+// older keys can be dropped from the keyring afterwards. Plaintext files
+// written before encryption was enabled are encrypted as well.
+func (s *Storage) Rekey() (*RekeyResult, error) {
+	if !GetEncryptionEnabled() {
+		return nil, fmt.Errorf("encryption is not enabled")
+	}
+	result := &RekeyResult{}
+	err := filepath.WalkDir(s.BaseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == s.BaseDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.Type().IsRegular() || strings.HasPrefix(d.Name(), "ntm-atomic-") {
+			return nil
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		data, err := openData(raw)
+		if err != nil {
+			result.Failed = append(result.Failed, path)
+			return nil
+		}
+		if err := writeStoredFile(path, data); err != nil {
+			return fmt.Errorf("re-encrypting %s: %w", path, err)
+		}
+		result.Files++
+		if !encryption.IsEncryptedData(raw) {
+			result.Plaintext++
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	if len(result.Failed) > 0 {
+		return result, fmt.Errorf("%d file(s) could not be decrypted with the configured keyring", len(result.Failed))
+	}
+	return result, nil
+}
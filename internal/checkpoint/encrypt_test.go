@@ -0,0 +1,205 @@
+package checkpoint
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/encryption"
+)
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, encryption.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func useKeys(active []byte, keyring ...[]byte) {
+	SetEncryptionConfig(&EncryptionConfig{Enabled: true, EncryptKey: active, DecryptKeys: keyring})
+}
+
+func TestEncryptedCheckpointRoundTrip(t *testing.T) {
+	t.Cleanup(func() { SetEncryptionConfig(nil) })
+	key := testKey(t)
+	useKeys(key, key)
+
+	storage := NewStorageWithDir(t.TempDir())
+	writeBlobCheckpoint(t, storage, "proj", "cp1", "secret scrollback\n", "+patch\n")
+
+	cp, err := storage.Load("proj", "cp1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEncryptedFile(filepath.Join(storage.CheckpointDir("proj", "cp1"), MetadataFile)) {
+		t.Error("metadata is not encrypted on disk")
+	}
+	for _, hash := range cp.BlobRefs() {
+		if !isEncryptedFile(storage.BlobPath(hash)) {
+			t.Errorf("blob %s is not encrypted on disk", hash)
+		}
+	}
+
+	if got, err := storage.ReadScrollback(cp, cp.Session.Panes[0]); err != nil || got != "secret scrollback\n" {
+		t.Errorf("ReadScrollback = %q, %v", got, err)
+	}
+	if got, err := storage.ReadGitPatch(cp); err != nil || got != "+patch\n" {
+		t.Errorf("ReadGitPatch = %q, %v", got, err)
+	}
+	result := cp.Verify(storage)
+	if !result.Valid || result.Details["encrypted"] != "true" {
+		t.Errorf("Verify = %+v", result)
+	}
+
+	// A keyring without the key cannot read it back.
+	useKeys(testKey(t))
+	if _, err := storage.Load("proj", "cp1"); !encryption.IsWrongKey(err) {
+		t.Errorf("Load with wrong key = %v, want wrong key error", err)
+	}
+}
+
+func TestImportEncryptsEveryFile(t *testing.T) {
+	t.Cleanup(func() { SetEncryptionConfig(nil) })
+	key := testKey(t)
+	useKeys(key, key)
+
+	meta, err := json.Marshal(&Checkpoint{
+		Version: 1, ID: "cp1", SessionName: "proj", CreatedAt: time.Now(), PaneCount: 1,
+		Session: SessionState{Panes: []PaneState{{Index: 0, ID: "%0", ScrollbackFile: "panes/pane__0.txt"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := []struct{ name, data string }{
+		{MetadataFile, string(meta)},
+		{"panes/pane__0.txt", "secret scrollback\n"},
+		{GitStatusFile, " M secret.go\n"},
+	}
+
+	for _, format := range []ExportFormat{FormatTarGz, FormatZip} {
+		t.Run(string(format), func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), "cp."+string(format))
+			f, err := os.Create(archive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format == FormatZip {
+				zw := zip.NewWriter(f)
+				for _, m := range members {
+					if err := writeZipEntry(zw, m.name, []byte(m.data)); err != nil {
+						t.Fatal(err)
+					}
+				}
+				err = zw.Close()
+			} else {
+				gw := gzip.NewWriter(f)
+				tw := tar.NewWriter(gw)
+				for _, m := range members {
+					if err := writeTarEntry(tw, m.name, []byte(m.data)); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err = tw.Close(); err == nil {
+					err = gw.Close()
+				}
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			storage := NewStorageWithDir(filepath.Join(t.TempDir(), "import"))
+			if _, err := storage.Import(archive, DefaultImportOptions()); err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+
+			dir := storage.CheckpointDir("proj", "cp1")
+			if status, err := readStoredFile(filepath.Join(dir, GitStatusFile)); err != nil || string(status) != " M secret.go\n" {
+				t.Fatalf("imported git status = %q, %v", status, err)
+			}
+			err = filepath.WalkDir(storage.BaseDir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				if !isEncryptedFile(path) {
+					t.Errorf("%s is stored in plaintext", path)
+				}
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				if perm := info.Mode().Perm(); perm&0077 != 0 {
+					t.Errorf("%s has mode %v, want owner-only", path, perm)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRekeyRotatesToActiveKey(t *testing.T) {
+	t.Cleanup(func() { SetEncryptionConfig(nil) })
+	storage := NewStorageWithDir(t.TempDir())
+
+	// A plaintext checkpoint from before encryption was enabled.
+	SetEncryptionConfig(nil)
+	writeV1Checkpoint(t, storage, "proj", "plain", "old\n", "+old\n")
+	if _, err := storage.Load("proj", "plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	oldKey, newKey := testKey(t), testKey(t)
+	useKeys(oldKey, oldKey)
+	writeV1Checkpoint(t, storage, "proj", "cp1", "hello\n", "+patch\n")
+	if _, err := storage.Load("proj", "cp1"); err != nil {
+		t.Fatal(err)
+	}
+
+	useKeys(newKey, newKey, oldKey)
+	result, err := storage.Rekey()
+	if err != nil {
+		t.Fatalf("Rekey: %v (%+v)", err, result)
+	}
+	if result.Files == 0 || result.Plaintext == 0 {
+		t.Errorf("Rekey result = %+v, want encrypted and plaintext files rewritten", result)
+	}
+
+	// The old key can now be dropped from the keyring.
+	useKeys(newKey, newKey)
+	for _, id := range []string{"plain", "cp1"} {
+		cp, err := storage.Load("proj", id)
+		if err != nil {
+			t.Fatalf("Load(%s) after rekey: %v", id, err)
+		}
+		if r := cp.Verify(storage); !r.Valid {
+			t.Errorf("Verify(%s) after rekey = %+v", id, r)
+		}
+	}
+
+	// Files the keyring cannot decrypt are reported rather than skipped silently.
+	useKeys(testKey(t))
+	result, err = storage.Rekey()
+	if err == nil || len(result.Failed) == 0 {
+		t.Errorf("Rekey with foreign key = %+v, %v; want failures", result, err)
+	}
+}
+
+func TestRekeyRequiresEncryption(t *testing.T) {
+	SetEncryptionConfig(nil)
+	storage := NewStorageWithDir(t.TempDir())
+	if _, err := storage.Rekey(); err == nil {
+		t.Error("Rekey succeeded without encryption enabled")
+	}
+}
@@ -127,9 +127,6 @@ func (s *Storage) Export(sessionName, checkpointID string, destPath string, opts
 		return nil, fmt.Errorf("failed to load checkpoint: %w", err)
 	}
 
-	// Build the checkpoint directory path
-	cpDir := s.CheckpointDir(sessionName, checkpointID)
-
 	// Determine output path
 	if destPath == "" {
 		ext := ".tar.gz"
@@ -139,20 +136,47 @@ func (s *Storage) Export(sessionName, checkpointID string, destPath string, opts
 		destPath = fmt.Sprintf("%s_%s%s", sessionName, checkpointID, ext)
 	}
 
-	// Collect files to export
-	var files []string
-	files = append(files, MetadataFile)
+	// Prepare checkpoint data (potentially with path rewriting)
+	cpData := *cp
+	if opts.RewritePaths {
+		cpData = *rewriteCheckpointPaths(cp)
+	}
+	cpData.Session.Panes = append([]PaneState(nil), cp.Session.Panes...)
 
-	if opts.IncludeScrollback {
-		for _, pane := range cp.Session.Panes {
-			if pane.ScrollbackFile != "" {
-				files = append(files, pane.ScrollbackFile)
-			}
+	// Collect files to export. Scrollback and patches are read out of the
+	// blob store and written as plain files so the archive is self-contained.
+	var files []archiveFile
+	for i := range cpData.Session.Panes {
+		pane := &cpData.Session.Panes[i]
+		if !pane.HasScrollback() {
+			continue
 		}
+		var content string
+		if opts.IncludeScrollback {
+			content, err = s.ReadScrollback(cp, *pane)
+		}
+		pane.ScrollbackFile, pane.ScrollbackBlobs = "", nil
+		if !opts.IncludeScrollback || err != nil {
+			continue
+		}
+		pane.ScrollbackFile = filepath.ToSlash(filepath.Join(PanesDir, fmt.Sprintf("pane_%s.txt", sanitizeName(pane.ID))))
+		data := []byte(content)
+		if opts.RedactSecrets {
+			data = redactSecrets(data)
+		}
+		files = append(files, archiveFile{name: pane.ScrollbackFile, data: data})
 	}
 
-	if opts.IncludeGitPatch && cp.Git.PatchFile != "" {
-		files = append(files, cp.Git.PatchFile)
+	if cpData.HasGitPatch() {
+		var patch string
+		if opts.IncludeGitPatch {
+			patch, err = s.ReadGitPatch(cp)
+		}
+		cpData.Git.PatchFile, cpData.Git.PatchBlobs = "", nil
+		if opts.IncludeGitPatch && err == nil && patch != "" {
+			cpData.Git.PatchFile = GitPatchFile
+			files = append(files, archiveFile{name: GitPatchFile, data: []byte(patch)})
+		}
 	}
 
 	// Create manifest
@@ -166,18 +190,12 @@ func (s *Storage) Export(sessionName, checkpointID string, destPath string, opts
 		Checksums:      make(map[string]string),
 	}
 
-	// Prepare checkpoint data (potentially with path rewriting)
-	cpData := cp
-	if opts.RewritePaths {
-		cpData = rewriteCheckpointPaths(cp)
-	}
-
 	// Create the archive
 	switch opts.Format {
 	case FormatTarGz:
-		err = s.exportTarGz(destPath, cpDir, cpData, files, opts, manifest)
+		err = s.exportTarGz(destPath, &cpData, files, manifest)
 	case FormatZip:
-		err = s.exportZip(destPath, cpDir, cpData, files, opts, manifest)
+		err = s.exportZip(destPath, &cpData, files, manifest)
 	default:
 		return nil, fmt.Errorf("unsupported export format: %s", opts.Format)
 	}
@@ -189,7 +207,24 @@ func (s *Storage) Export(sessionName, checkpointID string, destPath string, opts
 	return manifest, nil
 }
 
-func (s *Storage) exportTarGz(destPath, cpDir string, cp *Checkpoint, files []string, opts ExportOptions, manifest *ExportManifest) error {
+// archiveFile is a file written to an export archive besides the metadata.
+type archiveFile struct {
+	name string
+	data []byte
+}
+
+// addToManifest records a file's size and checksum in the manifest.
+func addToManifest(manifest *ExportManifest, name string, data []byte) {
+	checksum := sha256sum(data)
+	manifest.Checksums[name] = checksum
+	manifest.Files = append(manifest.Files, ManifestEntry{
+		Path:     name,
+		Size:     int64(len(data)),
+		Checksum: checksum,
+	})
+}
+
+func (s *Storage) exportTarGz(destPath string, cp *Checkpoint, files []archiveFile, manifest *ExportManifest) error {
 	f, err := os.Create(destPath)
 	if err != nil {
 		return fmt.Errorf("failed to create export file: %w", err)
@@ -208,44 +243,15 @@ func (s *Storage) exportTarGz(destPath, cpDir string, cp *Checkpoint, files []st
 		return fmt.Errorf("failed to marshal checkpoint: %w", err)
 	}
 
-	checksum := sha256sum(cpJSON)
-	manifest.Checksums[MetadataFile] = checksum
-	manifest.Files = append(manifest.Files, ManifestEntry{
-		Path:     MetadataFile,
-		Size:     int64(len(cpJSON)),
-		Checksum: checksum,
-	})
-
+	addToManifest(manifest, MetadataFile, cpJSON)
 	if err := writeTarEntry(tw, MetadataFile, cpJSON); err != nil {
 		return err
 	}
 
 	// Write other files
 	for _, file := range files {
-		if file == MetadataFile {
-			continue
-		}
-
-		srcPath := filepath.Join(cpDir, file)
-		data, err := os.ReadFile(srcPath)
-		if err != nil {
-			continue
-		}
-
-		// Redact secrets from scrollback files
-		if opts.RedactSecrets && strings.HasPrefix(file, PanesDir+"/") {
-			data = redactSecrets(data)
-		}
-
-		checksum := sha256sum(data)
-		manifest.Checksums[file] = checksum
-		manifest.Files = append(manifest.Files, ManifestEntry{
-			Path:     file,
-			Size:     int64(len(data)),
-			Checksum: checksum,
-		})
-
-		if err := writeTarEntry(tw, file, data); err != nil {
+		addToManifest(manifest, file.name, file.data)
+		if err := writeTarEntry(tw, file.name, file.data); err != nil {
 			return err
 		}
 	}
@@ -262,7 +268,7 @@ func (s *Storage) exportTarGz(destPath, cpDir string, cp *Checkpoint, files []st
 	return nil
 }
 
-func (s *Storage) exportZip(destPath, cpDir string, cp *Checkpoint, files []string, opts ExportOptions, manifest *ExportManifest) error {
+func (s *Storage) exportZip(destPath string, cp *Checkpoint, files []archiveFile, manifest *ExportManifest) error {
 	f, err := os.Create(destPath)
 	if err != nil {
 		return fmt.Errorf("failed to create export file: %w", err)
@@ -278,43 +284,15 @@ func (s *Storage) exportZip(destPath, cpDir string, cp *Checkpoint, files []stri
 		return fmt.Errorf("failed to marshal checkpoint: %w", err)
 	}
 
-	checksum := sha256sum(cpJSON)
-	manifest.Checksums[MetadataFile] = checksum
-	manifest.Files = append(manifest.Files, ManifestEntry{
-		Path:     MetadataFile,
-		Size:     int64(len(cpJSON)),
-		Checksum: checksum,
-	})
-
+	addToManifest(manifest, MetadataFile, cpJSON)
 	if err := writeZipEntry(zw, MetadataFile, cpJSON); err != nil {
 		return err
 	}
 
 	// Write other files
 	for _, file := range files {
-		if file == MetadataFile {
-			continue
-		}
-
-		srcPath := filepath.Join(cpDir, file)
-		data, err := os.ReadFile(srcPath)
-		if err != nil {
-			continue
-		}
-
-		if opts.RedactSecrets && strings.HasPrefix(file, PanesDir+"/") {
-			data = redactSecrets(data)
-		}
-
-		checksum := sha256sum(data)
-		manifest.Checksums[file] = checksum
-		manifest.Files = append(manifest.Files, ManifestEntry{
-			Path:     file,
-			Size:     int64(len(data)),
-			Checksum: checksum,
-		})
-
-		if err := writeZipEntry(zw, file, data); err != nil {
+		addToManifest(manifest, file.name, file.data)
+		if err := writeZipEntry(zw, file.name, file.data); err != nil {
 			return err
 		}
 	}
@@ -479,11 +457,16 @@ func (s *Storage) importTarGz(archivePath string, opts ImportOptions) (*Checkpoi
 			return nil, fmt.Errorf("invalid path in archive (symlink escape): %s", name)
 		}
 
-		if err := os.WriteFile(resolvedPath, data, 0644); err != nil {
+		if err := writeStoredFile(resolvedPath, data); err != nil {
 			return nil, fmt.Errorf("failed to write %s: %w", name, err)
 		}
 	}
 
+	// Move scrollback and patch files into the blob store
+	if err := s.migrateToBlobs(cpDir, cp); err != nil {
+		return nil, fmt.Errorf("failed to store imported checkpoint: %w", err)
+	}
+
 	return cp, nil
 }
 
@@ -603,11 +586,16 @@ func (s *Storage) importZip(archivePath string, opts ImportOptions) (*Checkpoint
 			return nil, fmt.Errorf("invalid path in archive (symlink escape): %s", name)
 		}
 
-		if err := os.WriteFile(resolvedPath, data, 0644); err != nil {
+		if err := writeStoredFile(resolvedPath, data); err != nil {
 			return nil, fmt.Errorf("failed to write %s: %w", name, err)
 		}
 	}
 
+	// Move scrollback and patch files into the blob store
+	if err := s.migrateToBlobs(cpDir, cp); err != nil {
+		return nil, fmt.Errorf("failed to store imported checkpoint: %w", err)
+	}
+
 	return cp, nil
 }
 
@@ -8,8 +8,6 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
-
-	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 const (
@@ -352,7 +350,7 @@ func (ic *IncrementalCreator) save(inc *IncrementalCheckpoint) error {
 				return fmt.Errorf("compressing pane diff: %w", err)
 			}
 
-			if err := util.AtomicWriteFile(fullPath, compressed, 0600); err != nil {
+			if err := writeStoredFile(fullPath, compressed); err != nil {
 				return fmt.Errorf("saving pane diff: %w", err)
 			}
 
@@ -368,7 +366,7 @@ func (ic *IncrementalCreator) save(inc *IncrementalCheckpoint) error {
 		patch, err := generateGitPatch(inc.Changes.GitChange.FromCommit, inc.Changes.GitChange.ToCommit)
 		if err == nil && patch != "" {
 			patchPath := filepath.Join(dir, IncrementalPatchFile)
-			if err := util.AtomicWriteFile(patchPath, []byte(patch), 0600); err != nil {
+			if err := writeStoredFile(patchPath, []byte(patch)); err != nil {
 				return fmt.Errorf("saving git patch: %w", err)
 			}
 			inc.Changes.GitChange.PatchFile = IncrementalPatchFile
@@ -382,7 +380,7 @@ func (ic *IncrementalCreator) save(inc *IncrementalCheckpoint) error {
 		return fmt.Errorf("marshaling incremental metadata: %w", err)
 	}
 
-	if err := util.AtomicWriteFile(metaPath, data, 0600); err != nil {
+	if err := writeStoredFile(metaPath, data); err != nil {
 		return fmt.Errorf("saving incremental metadata: %w", err)
 	}
 
@@ -516,7 +514,7 @@ func (ir *IncrementalResolver) loadIncremental(sessionName, incrementalID string
 	dir := filepath.Join(ir.storage.BaseDir, sessionName, "incremental", incrementalID)
 	metaPath := filepath.Join(dir, IncrementalMetadataFile)
 
-	data, err := os.ReadFile(metaPath)
+	data, err := readStoredFile(metaPath)
 	if err != nil {
 		return nil, fmt.Errorf("reading incremental metadata: %w", err)
 	}
@@ -694,8 +692,8 @@ func (inc *IncrementalCheckpoint) StorageSavings(storage *Storage) (savedBytes i
 
 	var fullSize int64
 	for _, pane := range base.Session.Panes {
-		if pane.ScrollbackFile != "" {
-			scrollback, _ := storage.LoadCompressedScrollback(inc.SessionName, inc.BaseCheckpointID, pane.ID)
+		if pane.HasScrollback() {
+			scrollback, _ := storage.ReadScrollback(base, pane)
 			fullSize += int64(len(scrollback))
 		}
 	}
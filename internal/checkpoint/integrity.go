@@ -11,7 +11,8 @@ import (
 )
 
 // CurrentVersion is the current checkpoint format version.
-const CurrentVersion = 1
+// Version 2 stores scrollback and git patches in the blob store.
+const CurrentVersion = 2
 
 // MinVersion is the minimum supported checkpoint format version.
 const MinVersion = 1
@@ -67,11 +68,11 @@ func (c *Checkpoint) Verify(storage *Storage) *IntegrityResult {
 	// Run all checks
 	c.validateSchema(result)
 	c.checkFiles(storage, dir, result)
+	c.checkBlobs(storage, result)
 	c.validateConsistency(result)
 
 	// Overall validity
-	result.Valid = result.SchemaValid && result.FilesPresent && result.ConsistencyValid
-	// Checksums are optional (only checked if manifest exists)
+	result.Valid = result.SchemaValid && result.FilesPresent && result.ChecksumsValid && result.ConsistencyValid
 
 	return result
 }
@@ -159,10 +160,41 @@ func (c *Checkpoint) checkFiles(storage *Storage, dir string, result *IntegrityR
 		}
 	}
 
+	result.Details["encrypted"] = fmt.Sprintf("%v", isEncryptedFile(metaPath))
 	result.Details["panes_dir"] = filepath.Join(dir, PanesDir)
 	result.Details["files_checked"] = fmt.Sprintf("%d", 2+len(c.Session.Panes))
 }
 
+// checkBlobs verifies that every referenced blob exists and that its
+// content still matches its hash.
+func (c *Checkpoint) checkBlobs(storage *Storage, result *IntegrityResult) {
+	checked := make(map[string]bool)
+	check := func(what string, hashes []string) {
+		for _, hash := range hashes {
+			if checked[hash] {
+				continue
+			}
+			checked[hash] = true
+			_, err := storage.GetBlob(hash)
+			switch {
+			case err == nil:
+			case errors.Is(err, ErrBlobNotFound):
+				result.FilesPresent = false
+				result.Errors = append(result.Errors, fmt.Sprintf("missing %s blob: %s", what, hash))
+			default:
+				result.ChecksumsValid = false
+				result.Errors = append(result.Errors, fmt.Sprintf("bad %s blob: %v", what, err))
+			}
+		}
+	}
+	for _, pane := range c.Session.Panes {
+		check("scrollback", pane.ScrollbackBlobs)
+	}
+	check("git patch", c.Git.PatchBlobs)
+
+	result.Details["blobs_checked"] = fmt.Sprintf("%d", len(checked))
+}
+
 // validateConsistency checks internal consistency of the checkpoint data.
 func (c *Checkpoint) validateConsistency(result *IntegrityResult) {
 	// Check pane count matches
@@ -277,7 +277,7 @@ func (r *Restorer) injectContext(cp *Checkpoint, maxLines int) error {
 
 	var lastErr error
 	for i, paneState := range cp.Session.Panes {
-		if paneState.ScrollbackFile == "" {
+		if !paneState.HasScrollback() {
 			continue
 		}
 
@@ -288,7 +288,7 @@ func (r *Restorer) injectContext(cp *Checkpoint, maxLines int) error {
 		targetPane := panes[i]
 
 		// Load scrollback content
-		content, err := r.storage.LoadScrollback(cp.SessionName, cp.ID, paneState.ID)
+		content, err := r.storage.ReadScrollback(cp, paneState)
 		if err != nil {
 			lastErr = err
 			continue
@@ -478,6 +478,13 @@ func (r *Restorer) ValidateCheckpoint(cp *Checkpoint, opts RestoreOptions) []str
 						fmt.Sprintf("scrollback file missing for pane %s", pane.ID))
 				}
 			}
+			for _, hash := range pane.ScrollbackBlobs {
+				if !r.storage.HasBlob(hash) {
+					issues = append(issues,
+						fmt.Sprintf("scrollback blob missing for pane %s", pane.ID))
+					break
+				}
+			}
 		}
 	}
 
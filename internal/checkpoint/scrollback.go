@@ -13,7 +13,6 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
-	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 // ScrollbackCapture holds the captured scrollback data for a pane.
@@ -155,7 +154,7 @@ func (s *Storage) SaveCompressedScrollback(sessionName, checkpointID, paneID str
 	filename := fmt.Sprintf("pane_%s.txt.gz", sanitizeName(paneID))
 	fullPath := filepath.Join(panesDir, filename)
 
-	if err := util.AtomicWriteFile(fullPath, data, 0600); err != nil {
+	if err := writeStoredFile(fullPath, data); err != nil {
 		return "", fmt.Errorf("saving compressed scrollback: %w", err)
 	}
 
@@ -168,7 +167,7 @@ func (s *Storage) LoadCompressedScrollback(sessionName, checkpointID, paneID str
 	filename := fmt.Sprintf("pane_%s.txt.gz", sanitizeName(paneID))
 	fullPath := filepath.Join(s.PanesDirPath(sessionName, checkpointID), filename)
 
-	data, err := os.ReadFile(fullPath)
+	data, err := readStoredFile(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Fall back to uncompressed file
@@ -202,22 +201,15 @@ func (c *Capturer) captureScrollbackEnhanced(cp *Checkpoint, config ScrollbackCo
 			continue
 		}
 
-		// Save scrollback
-		var relativePath string
-		var saveErr error
-
-		if config.Compress && len(capture.Compressed) > 0 {
-			relativePath, saveErr = c.storage.SaveCompressedScrollback(cp.SessionName, cp.ID, pane.ID, capture.Compressed)
-		} else {
-			relativePath, saveErr = c.storage.SaveScrollback(cp.SessionName, cp.ID, pane.ID, capture.Content)
-		}
-
-		if saveErr != nil {
-			slog.Warn("failed to save scrollback", "pane", pane.Index, "error", saveErr)
+		// Save scrollback into the blob store; chunks unchanged since an
+		// earlier checkpoint are shared rather than written again.
+		hashes, err := c.storage.PutChunks(capture.Content)
+		if err != nil {
+			slog.Warn("failed to save scrollback", "pane", pane.Index, "error", err)
 			continue
 		}
 
-		pane.ScrollbackFile = relativePath
+		pane.ScrollbackBlobs = hashes
 		pane.ScrollbackLines = countLines(capture.Content)
 	}
 
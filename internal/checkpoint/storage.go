@@ -9,8 +9,6 @@ import (
 	"strings"
 	"time"
 	"unicode/utf8"
-
-	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 const (
@@ -158,7 +156,7 @@ func (s *Storage) Load(sessionName, checkpointID string) (*Checkpoint, error) {
 	dir := s.CheckpointDir(sessionName, checkpointID)
 	metaPath := filepath.Join(dir, MetadataFile)
 
-	data, err := os.ReadFile(metaPath)
+	data, err := readStoredFile(metaPath)
 	if err != nil {
 		return nil, fmt.Errorf("reading checkpoint metadata: %w", err)
 	}
@@ -217,7 +215,7 @@ func (s *Storage) ListAll() ([]*Checkpoint, error) {
 
 	var all []*Checkpoint
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() || entry.Name() == BlobsDir {
 			continue
 		}
 		sessionCheckpoints, err := s.List(entry.Name())
@@ -264,7 +262,7 @@ func (s *Storage) SaveScrollback(sessionName, checkpointID string, paneID string
 	filename := fmt.Sprintf("pane_%s.txt", sanitizeName(paneID))
 	fullPath := filepath.Join(panesDir, filename)
 
-	if err := util.AtomicWriteFile(fullPath, []byte(content), 0600); err != nil {
+	if err := writeStoredFile(fullPath, []byte(content)); err != nil {
 		return "", fmt.Errorf("saving scrollback: %w", err)
 	}
 
@@ -276,14 +274,37 @@ func (s *Storage) LoadScrollback(sessionName, checkpointID string, paneID string
 	filename := fmt.Sprintf("pane_%s.txt", sanitizeName(paneID))
 	fullPath := filepath.Join(s.PanesDirPath(sessionName, checkpointID), filename)
 
-	data, err := os.ReadFile(fullPath)
+	data, err := readStoredFile(fullPath)
 	if err != nil {
+		if os.IsNotExist(err) {
+			if content, ok, blobErr := s.loadPaneBlobs(sessionName, checkpointID, paneID); ok {
+				return content, blobErr
+			}
+		}
 		return "", fmt.Errorf("reading scrollback: %w", err)
 	}
 
 	return string(data), nil
 }
 
+// loadPaneBlobs reads a pane's scrollback from the blob store. ok is false
+// if the checkpoint holds no blob references for the pane.
+func (s *Storage) loadPaneBlobs(sessionName, checkpointID, paneID string) (content string, ok bool, err error) {
+	cp, err := s.Load(sessionName, checkpointID)
+	if err != nil {
+		return "", false, nil
+	}
+	pane, found := cp.findPane(paneID)
+	if !found || len(pane.ScrollbackBlobs) == 0 {
+		return "", false, nil
+	}
+	content, err = s.GetChunks(pane.ScrollbackBlobs)
+	if err != nil {
+		return "", true, fmt.Errorf("reading scrollback: %w", err)
+	}
+	return content, true, nil
+}
+
 // SaveGitPatch writes the git diff patch to the checkpoint.
 func (s *Storage) SaveGitPatch(sessionName, checkpointID, patch string) error {
 	if patch == "" {
@@ -291,7 +312,7 @@ func (s *Storage) SaveGitPatch(sessionName, checkpointID, patch string) error {
 	}
 	dir := s.CheckpointDir(sessionName, checkpointID)
 	path := filepath.Join(dir, GitPatchFile)
-	return util.AtomicWriteFile(path, []byte(patch), 0600)
+	return writeStoredFile(path, []byte(patch))
 }
 
 // LoadGitPatch reads the git diff patch from the checkpoint.
@@ -299,10 +320,18 @@ func (s *Storage) LoadGitPatch(sessionName, checkpointID string) (string, error)
 	dir := s.CheckpointDir(sessionName, checkpointID)
 	path := filepath.Join(dir, GitPatchFile)
 
-	data, err := os.ReadFile(path)
+	data, err := readStoredFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", nil
+			cp, loadErr := s.Load(sessionName, checkpointID)
+			if loadErr != nil || len(cp.Git.PatchBlobs) == 0 {
+				return "", nil
+			}
+			patch, err := s.GetChunks(cp.Git.PatchBlobs)
+			if err != nil {
+				return "", fmt.Errorf("reading git patch: %w", err)
+			}
+			return patch, nil
 		}
 		return "", fmt.Errorf("reading git patch: %w", err)
 	}
@@ -314,17 +343,18 @@ func (s *Storage) LoadGitPatch(sessionName, checkpointID string) (string, error)
 func (s *Storage) SaveGitStatus(sessionName, checkpointID, status string) error {
 	dir := s.CheckpointDir(sessionName, checkpointID)
 	path := filepath.Join(dir, GitStatusFile)
-	return util.AtomicWriteFile(path, []byte(status), 0600)
+	return writeStoredFile(path, []byte(status))
 }
 
-// writeJSON writes data as formatted JSON to a file atomically.
+// writeJSON writes data as formatted JSON to a file atomically,
+// encrypted if encryption is enabled.
 func writeJSON(path string, data interface{}) error {
 	bytes, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return util.AtomicWriteFile(path, bytes, 0600)
+	return writeStoredFile(path, bytes)
 }
 
 // Exists returns true if a checkpoint exists.
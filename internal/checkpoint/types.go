@@ -103,7 +103,10 @@ type PaneState struct {
 	// Height is the pane height in rows
 	Height int `json:"height"`
 	// ScrollbackFile is the relative path to scrollback capture
+	// (version 1 checkpoints and imported archives before migration)
 	ScrollbackFile string `json:"scrollback_file,omitempty"`
+	// ScrollbackBlobs are the hashes of the scrollback chunks in the blob store
+	ScrollbackBlobs []string `json:"scrollback_blobs,omitempty"`
 	// ScrollbackLines is the number of lines captured
 	ScrollbackLines int `json:"scrollback_lines"`
 }
@@ -117,7 +120,10 @@ type GitState struct {
 	// IsDirty indicates uncommitted changes exist
 	IsDirty bool `json:"is_dirty"`
 	// PatchFile is the relative path to the git diff patch
+	// (version 1 checkpoints and imported archives before migration)
 	PatchFile string `json:"patch_file,omitempty"`
+	// PatchBlobs are the hashes of the patch chunks in the blob store
+	PatchBlobs []string `json:"patch_blobs,omitempty"`
 	// StagedCount is the number of staged files
 	StagedCount int `json:"staged_count"`
 	// UnstagedCount is the number of modified but unstaged files
@@ -136,9 +142,14 @@ func (c *Checkpoint) Age() time.Duration {
 	return time.Since(c.CreatedAt)
 }
 
-// HasGitPatch returns true if a git patch file exists.
+// HasGitPatch returns true if a git patch was captured.
 func (c *Checkpoint) HasGitPatch() bool {
-	return c.Git.PatchFile != ""
+	return c.Git.PatchFile != "" || len(c.Git.PatchBlobs) > 0
+}
+
+// HasScrollback returns true if the pane's scrollback was captured.
+func (p PaneState) HasScrollback() bool {
+	return p.ScrollbackFile != "" || len(p.ScrollbackBlobs) > 0
 }
 
 // FromTmuxPane converts a tmux.Pane to PaneState.
@@ -16,6 +16,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/state"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 	"github.com/Dicklesworthstone/ntm/internal/tui/theme"
 )
@@ -84,6 +85,8 @@ Examples:
 	cmd.ValidArgsFunction = completeSessionArgs
 	_ = cmd.RegisterFlagCompletionFunc("pane", completePaneIndexes)
 
+	cmd.AddCommand(newActivityTransitionsCmd(), newActivityCommandsCmd())
+
 	return cmd
 }
 
@@ -160,6 +163,13 @@ func runActivityWatch(session string, opts activityOptions) error {
 	fmt.Print("\033[?25l")
 	defer fmt.Print("\033[?25h")
 
+	// Record state changes while watching; the log is best-effort.
+	var recorder *state.TransitionRecorder
+	if store, err := openTransitionStore(); err == nil {
+		defer store.Close()
+		recorder = state.NewTransitionRecorder(store)
+	}
+
 	ticker := time.NewTicker(opts.interval)
 	defer ticker.Stop()
 
@@ -191,6 +201,7 @@ func runActivityWatch(session string, opts activityOptions) error {
 			firstRun = false
 			continue
 		}
+		recordActivityTransitions(recorder, result)
 
 		if err := renderActivityTUI(result, true); err != nil {
 			fmt.Printf("Render error: %v\n", err)
@@ -209,12 +220,15 @@ type activityResult struct {
 
 type agentInfo struct {
 	Pane       int
+	PaneID     string
+	PaneName   string
 	AgentType  string
 	State      string
 	Confidence float64
 	Velocity   float64
 	Duration   time.Duration
 	StateSince time.Time
+	Trigger    string
 }
 
 func collectActivityData(session string, opts activityOptions) (*activityResult, error) {
@@ -268,6 +282,8 @@ func collectActivityData(session string, opts activityOptions) (*activityResult,
 
 		info := agentInfo{
 			Pane:       pane.Index,
+			PaneID:     pane.ID,
+			PaneName:   pane.Title,
 			AgentType:  agentType,
 			State:      string(activity.State),
 			Confidence: activity.Confidence,
@@ -275,6 +291,9 @@ func collectActivityData(session string, opts activityOptions) (*activityResult,
 			Duration:   duration,
 			StateSince: activity.StateSince,
 		}
+		if n := len(activity.StateHistory); n > 0 {
+			info.Trigger = activity.StateHistory[n-1].Trigger
+		}
 
 		result.Agents = append(result.Agents, info)
 		result.Summary[string(activity.State)]++
@@ -283,6 +302,39 @@ func collectActivityData(session string, opts activityOptions) (*activityResult,
 	return result, nil
 }
 
+// openTransitionStore opens the default state store for transition recording.
+func openTransitionStore() (*state.Store, error) {
+	store, err := state.Open("")
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Migrate(); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// recordActivityTransitions persists state changes seen by the activity classifier.
+func recordActivityTransitions(recorder *state.TransitionRecorder, result *activityResult) {
+	if recorder == nil {
+		return
+	}
+	for _, a := range result.Agents {
+		_, _ = recorder.Observe(state.StateObservation{
+			SessionID:  result.Session,
+			PaneID:     a.PaneID,
+			PaneName:   a.PaneName,
+			AgentType:  a.AgentType,
+			State:      a.State,
+			Source:     state.TransitionSourceRobot,
+			Trigger:    a.Trigger,
+			Confidence: a.Confidence,
+			At:         result.CapturedAt,
+		})
+	}
+}
+
 func detectAgentTypeFromPane(pane tmux.Pane) string {
 	// Use the pane's Type field which is already parsed
 	switch pane.Type {
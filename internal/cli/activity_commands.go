@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func newActivityCommandsCmd() *cobra.Command {
+	var (
+		pane  string
+		since string
+		limit int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "commands [session]",
+		Short: "Show the shell commands and file edits agents ran",
+		Long: `Show the per-agent command timeline: every shell command an agent ran
+with its exit code and duration, and every file it edited.
+
+The timeline comes from the agent CLIs' own hooks, so it is exact rather
+than scraped from pane output. Install the hooks with
+'ntm hooks agent install'; events are recorded while 'ntm serve' or
+'ntm hooks agent listen' is running.
+
+Examples:
+  ntm activity commands                   # Auto-detect session
+  ntm activity commands myproject --since 1h
+  ntm activity commands --pane cc_1 --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session := ""
+			if len(args) > 0 {
+				session = args[0]
+			}
+			// The timeline outlives the session, so explicit names are used as given.
+			if session == "" {
+				res, err := ResolveSession(session, os.Stdout)
+				if err != nil {
+					return err
+				}
+				if res.Session == "" {
+					return nil
+				}
+				res.ExplainIfInferred(os.Stderr)
+				session = res.Session
+			}
+
+			q := state.AgentCommandQuery{SessionID: session, Limit: limit}
+			if pane != "" {
+				q.PaneID = resolveHookPane(session, pane)
+			}
+			if since != "" {
+				t, err := parseTimeArg(since)
+				if err != nil {
+					return err
+				}
+				q.Since = t
+			}
+
+			store, err := openTransitionStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			commands, err := store.ListAgentCommands(q)
+			if err != nil {
+				return err
+			}
+			if IsJSONOutput() {
+				if commands == nil {
+					commands = []state.AgentCommand{}
+				}
+				return output.PrintJSON(map[string]interface{}{
+					"session":  session,
+					"commands": commands,
+				})
+			}
+			if len(commands) == 0 {
+				fmt.Printf("No agent commands recorded for %s (see 'ntm hooks agent install')\n", session)
+				return nil
+			}
+			printAgentCommands(commands, hookPaneTitles(session))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&pane, "pane", "", "Filter by pane ID (%3) or agent name (cc_1)")
+	cmd.Flags().StringVar(&since, "since", "", "Only commands since (e.g. 30m, 2h, 1d or RFC3339)")
+	cmd.Flags().IntVar(&limit, "limit", 50, "Show only the most recent N commands (0 = all)")
+	cmd.ValidArgsFunction = completeSessionArgs
+
+	return cmd
+}
+
+func printAgentCommands(commands []state.AgentCommand, titles map[string]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tPANE\tTOOL\tEXIT\tDURATION\tCOMMAND")
+	for _, c := range commands {
+		pane := c.PaneID
+		if title := titles[c.PaneID]; title != "" {
+			pane = title
+		}
+		exit := "-"
+		if c.ExitCode != nil {
+			exit = strconv.Itoa(*c.ExitCode)
+		}
+		duration := "-"
+		if c.StartedAt != nil {
+			duration = formatActivityDuration(c.Duration())
+		}
+		detail := c.Command
+		if detail == "" {
+			detail = strings.Join(c.Files, ", ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", c.FinishedAt.Local().Format("2006-01-02 15:04:05"),
+			pane, c.Tool, exit, duration, truncatePrompt(detail, 80))
+	}
+	w.Flush()
+}
+
+// hookPaneTitles maps the session's live pane IDs to their titles.
+func hookPaneTitles(session string) map[string]string {
+	titles := make(map[string]string)
+	panes, err := tmux.GetPanes(session)
+	if err != nil {
+		return titles
+	}
+	for _, p := range panes {
+		titles[p.ID] = p.Title
+	}
+	return titles
+}
+
+// resolveHookPane turns an agent name or pane title into a pane ID. Pane IDs
+// and names that match no live pane are returned unchanged.
+func resolveHookPane(session, pane string) string {
+	if strings.HasPrefix(pane, "%") {
+		return pane
+	}
+	for id, title := range hookPaneTitles(session) {
+		if title == pane || strings.HasSuffix(title, "__"+pane) || strings.Contains(title, "__"+pane+"_") {
+			return id
+		}
+	}
+	return pane
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+func newActivityTransitionsCmd() *cobra.Command {
+	var opts robot.TransitionsOptions
+
+	cmd := &cobra.Command{
+		Use:   "transitions [session]",
+		Short: "Show the recorded agent state-transition log",
+		Long: `Show every recorded agent state change for a session: from/to state,
+when it happened, the detection rule that triggered it and its confidence.
+
+Transitions are recorded while the dashboard, 'ntm serve' or
+'ntm activity --watch' is observing the session.
+
+Time ranges accept durations relative to now (30m, 2h, 1d) or ISO8601
+timestamps. --stats adds the time each pane spent in each state.
+
+Examples:
+  ntm activity transitions                       # Auto-detect session
+  ntm activity transitions myproject --since 1h  # Last hour
+  ntm activity transitions --pane %3 --state error
+  ntm activity transitions --stats --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session := ""
+			if len(args) > 0 {
+				session = args[0]
+			}
+			return runActivityTransitions(session, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Pane, "pane", "", "Filter by pane ID or name")
+	cmd.Flags().StringVar(&opts.State, "state", "", "Filter by from/to state")
+	cmd.Flags().StringVar(&opts.Since, "since", "", "Start of range (e.g. 1h, 2025-12-15T10:00:00Z)")
+	cmd.Flags().StringVar(&opts.Until, "until", "", "End of range (e.g. 10m, 2025-12-15T11:00:00Z)")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 50, "Show only the most recent N transitions (0 = all)")
+	cmd.Flags().BoolVar(&opts.Stats, "stats", false, "Show dwell time per state per pane")
+	cmd.ValidArgsFunction = completeSessionArgs
+
+	return cmd
+}
+
+func runActivityTransitions(session string, opts robot.TransitionsOptions) error {
+	// The log outlives the session, so explicit names are used as given.
+	if session == "" {
+		res, err := ResolveSession(session, os.Stdout)
+		if err != nil {
+			return err
+		}
+		if res.Session == "" {
+			return nil
+		}
+		res.ExplainIfInferred(os.Stderr)
+		session = res.Session
+	}
+	opts.Session = session
+
+	result, err := robot.GetTransitions(opts)
+	if err != nil {
+		return err
+	}
+	if IsJSONOutput() {
+		return output.PrintJSON(result)
+	}
+	if !result.Success {
+		return fmt.Errorf("%s", result.Error)
+	}
+
+	if len(result.Transitions) == 0 {
+		fmt.Printf("No state transitions recorded for %s\n", result.Session)
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tPANE\tAGENT\tFROM\tTO\tTRIGGER\tCONF")
+		for _, t := range result.Transitions {
+			pane := t.PaneName
+			if pane == "" {
+				pane = t.PaneID
+			}
+			from := t.FromState
+			if from == "" {
+				from = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%.2f\n",
+				t.TransitionAt.Local().Format("2006-01-02 15:04:05"), pane, t.AgentType, from, t.ToState, t.Trigger, t.Confidence)
+		}
+		w.Flush()
+	}
+
+	if result.Stats != nil {
+		printTransitionStats(result.Stats)
+	}
+	return nil
+}
+
+func printTransitionStats(stats *state.TransitionStats) {
+	fmt.Printf("\n%d transitions\n", stats.TotalTransitions)
+	if len(stats.Dwell) == 0 {
+		return
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PANE\tSOURCE\tSTATE\tENTRIES\tTOTAL\tAVG\tMAX")
+	for _, d := range stats.Dwell {
+		pane := d.PaneName
+		if pane == "" {
+			pane = d.PaneID
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", pane, d.Source, d.State, d.Entries,
+			formatActivityDuration(secondsToDuration(d.TotalSeconds)),
+			formatActivityDuration(secondsToDuration(d.AvgSeconds)),
+			formatActivityDuration(secondsToDuration(d.MaxSeconds)))
+	}
+	w.Flush()
+}
+
+func secondsToDuration(secs float64) time.Duration {
+	return time.Duration(secs * float64(time.Second))
+}
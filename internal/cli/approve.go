@@ -34,6 +34,7 @@ Subcommands:
   approve deny <token>     Deny a pending request
   approve history          Show approval history
   approve show <token>     Show details of an approval
+  approve watch [session]  Answer agent permission prompts per policy
 
 Examples:
   ntm approve abc123                  # Approve request abc123
@@ -93,7 +94,7 @@ Examples:
 	}
 	historyCmd.Flags().BoolVar(&robotJSON, "json", false, "Output in JSON format")
 
-	cmd.AddCommand(listCmd, denyCmd, showCmd, historyCmd)
+	cmd.AddCommand(listCmd, denyCmd, showCmd, historyCmd, newApproveWatchCmd())
 	return cmd
 }
 
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/approval"
+	"github.com/Dicklesworthstone/ntm/internal/notify"
+	"github.com/Dicklesworthstone/ntm/internal/policy"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/transitions"
+)
+
+type approveWatchOptions struct {
+	interval time.Duration
+	timeout  time.Duration
+	once     bool
+	json     bool
+}
+
+func newApproveWatchCmd() *cobra.Command {
+	opts := approveWatchOptions{
+		interval: 2 * time.Second,
+		timeout:  approval.DefaultPromptTimeout,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "watch [session]",
+		Short: "Answer agent permission prompts according to policy",
+		Long: `Watch a session for agents blocked on confirmation prompts
+("Do you want to proceed?", tool-approval menus, trust-folder dialogs) and
+respond according to the approval_prompts rules in .ntm/policy.yaml:
+
+  approve   send the agent's "yes" keys
+  deny      send the agent's "no" keys
+  escalate  create an approval request; answer once it is approved/denied
+  notify    leave the prompt for a human and send a notification (default)
+
+Command prompts are also checked against the blocked and approval_required
+rules, so a blocked command is always denied and an approval_required
+command is always escalated.
+
+Example policy:
+  approval_prompts:
+    - match: '^(go|npm) test'
+      kind: command
+      action: approve
+    - match: '.*'
+      kind: trust
+      action: deny
+
+Examples:
+  ntm approve watch myproject          # Run until interrupted
+  ntm approve watch --once --json      # Handle current prompts and exit`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session := ""
+			if len(args) > 0 {
+				session = args[0]
+			}
+			return runApproveWatch(session, opts)
+		},
+	}
+
+	cmd.Flags().DurationVar(&opts.interval, "interval", opts.interval, "Polling interval")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", opts.timeout, "How long escalated prompts wait for a decision")
+	cmd.Flags().BoolVar(&opts.once, "once", false, "Handle prompts currently showing and exit")
+	cmd.Flags().BoolVar(&opts.json, "json", false, "Output outcomes as JSON lines")
+
+	return cmd
+}
+
+func runApproveWatch(session string, opts approveWatchOptions) error {
+	if err := tmux.EnsureInstalled(); err != nil {
+		return err
+	}
+
+	res, err := ResolveSession(session, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if res.Session == "" {
+		return nil
+	}
+	res.ExplainIfInferred(os.Stderr)
+	session = res.Session
+
+	if !tmux.SessionExists(session) {
+		return fmt.Errorf("session '%s' not found", session)
+	}
+
+	pol, err := policy.LoadOrDefault()
+	if err != nil {
+		return fmt.Errorf("loading policy: %w", err)
+	}
+
+	respCfg := approval.PromptResponderConfig{
+		Policy:      pol,
+		SendKeys:    tmux.SendKeyNames,
+		Capture:     tmux.CaptureForStatusDetection,
+		Timeout:     opts.timeout,
+		RequestedBy: "prompt-watch:" + getCurrentApprover(),
+	}
+	if cfg != nil {
+		respCfg.Notifier = notify.NewWithRedaction(cfg.Notifications, cfg.Redaction.ToRedactionLibConfig())
+	}
+	engine, store, err := getApprovalEngine()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: approval engine unavailable, escalations will notify only: %v\n", err)
+	} else {
+		defer store.Close()
+		respCfg.Engine = engine
+	}
+	responder := approval.NewPromptResponder(respCfg)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		outMu sync.Mutex
+	)
+	report := func(out *approval.PromptOutcome, err error) {
+		outMu.Lock()
+		defer outMu.Unlock()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		if out == nil {
+			return
+		}
+		if opts.json {
+			_ = json.NewEncoder(os.Stdout).Encode(out)
+			return
+		}
+		fmt.Printf("%s %s [%s] %s -> %s (%s)\n",
+			time.Now().Format("15:04:05"), out.Pane, out.Prompt.Kind, out.Prompt.Action, out.Response, out.Decision)
+	}
+
+	var observer *transitions.Observer
+	if store, err := openTransitionStore(); err == nil {
+		defer store.Close()
+		observer = transitions.NewObserver(store)
+	}
+
+	detector := status.NewDetector()
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+
+	for {
+		statuses, err := detector.DetectAllContext(ctx, session)
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Warning: status detection failed: %v\n", err)
+		}
+		observer.Record(session, statuses)
+		for _, st := range statuses {
+			if st.State != status.StateAwaitingApproval || st.PendingApproval == nil {
+				responder.Clear(st.PaneID)
+				continue
+			}
+			// Escalations block until decided; handle each pane concurrently.
+			wg.Add(1)
+			go func(pane string, prompt *status.ApprovalPrompt) {
+				defer wg.Done()
+				report(responder.Handle(ctx, session, pane, prompt))
+			}(st.PaneID, st.PendingApproval)
+		}
+
+		if opts.once {
+			wg.Wait()
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
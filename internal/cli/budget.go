@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/budget"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/transcript"
+)
+
+func newBudgetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "budget",
+		Short: "Cap estimated spend per session, agent type and day",
+		Long: `Manage spend budgets.
+
+Budgets are named:
+  daily              all spend per local day
+  session            default cap on each session's cumulative spend
+  session:<name>     cap on one session, replacing the default
+  agent_type:<type>  spend of one agent type (cc, cod, gmi) per local day
+
+Budgets can also be set in config.toml under [budgets]; those set here take
+precedence. 'ntm serve' evaluates budgets continuously and notifies at the
+warn_at thresholds. Past a cap, 'ntm send' and pipeline prompt steps are
+refused until the override request is approved with 'ntm approve <id>'.
+An approved override lasts until local midnight.
+
+Examples:
+  ntm budget set daily 50
+  ntm budget set session:nightly 5
+  ntm budget set agent_type:cc 30
+  ntm budget status
+  ntm budget unset session:nightly`,
+	}
+
+	cmd.AddCommand(
+		newBudgetSetCmd(),
+		newBudgetUnsetCmd(),
+		newBudgetListCmd(),
+		newBudgetStatusCmd(),
+	)
+	return cmd
+}
+
+func newBudgetSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <budget> <usd>",
+		Short: "Set a budget",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scope, target, err := budget.ParseID(args[0])
+			if err != nil {
+				return err
+			}
+			usd, err := budget.ParseUSD(args[1])
+			if err != nil {
+				return err
+			}
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			b := &state.Budget{Scope: scope, Target: target, LimitUSD: usd}
+			if err := store.SetBudget(b); err != nil {
+				return err
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(b)
+			}
+			fmt.Printf("Set budget %s to %s\n", args[0], cost.FormatCost(usd))
+			return nil
+		},
+	}
+}
+
+func newBudgetUnsetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "unset <budget>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a budget set with 'ntm budget set'",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scope, target, err := budget.ParseID(args[0])
+			if err != nil {
+				return err
+			}
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			removed, err := store.DeleteBudget(scope, target)
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("no budget %s set with 'ntm budget set'", args[0])
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(map[string]interface{}{"success": true, "budget": args[0]})
+			}
+			fmt.Printf("Removed budget %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newBudgetListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List budgets from config.toml and 'ntm budget set'",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			stored, err := store.ListBudgets()
+			if err != nil {
+				return err
+			}
+			limits := budget.Limits(budgetsConfig(), stored)
+			if IsJSONOutput() {
+				return output.PrintJSON(limits)
+			}
+			if len(limits) == 0 {
+				fmt.Println("No budgets. Set one with 'ntm budget set'.")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "BUDGET\tLIMIT\tSOURCE")
+			for _, l := range limits {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", l.ID(), cost.FormatCost(l.USD), l.Source)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// budgetStatusRow is a budget status with its override state.
+type budgetStatusRow struct {
+	budget.Status
+	Key        string `json:"key"`
+	Overridden bool   `json:"overridden"`
+}
+
+func newBudgetStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show spend against each budget",
+		Long: `Show today's spend against each budget. Spend is read from the agents'
+transcripts in every tmux session and from .ntm/costs.json in the current
+directory, on top of the daily ledger kept by 'ntm serve'.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			costs := cost.NewCostTracker("")
+			if wd, err := os.Getwd(); err == nil {
+				_ = costs.LoadFromDir(wd)
+			}
+			transcript.NewIngester(costs, nil).SyncAll(ctx)
+
+			enforcer := &budget.Enforcer{
+				Evaluator: budget.Evaluator{Store: store, Costs: costs},
+				Config:    budgetsConfig(),
+			}
+			statuses, err := enforcer.Statuses()
+			if err != nil {
+				return err
+			}
+			rows := make([]budgetStatusRow, 0, len(statuses))
+			for _, st := range statuses {
+				rows = append(rows, budgetStatusRow{Status: st, Key: st.Key(), Overridden: st.Exceeded && enforcer.Overridden(st)})
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(rows)
+			}
+			if len(rows) == 0 {
+				fmt.Println("No budgets. Set one with 'ntm budget set'.")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "BUDGET\tSPENT\tLIMIT\tUSED\tSTATE")
+			for _, r := range rows {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%.0f%%\t%s\n",
+					r.Key, cost.FormatCost(r.SpentUSD), cost.FormatCost(r.USD), 100*r.Fraction, budgetState(r, enforcer.Config.WarnAt))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func budgetState(r budgetStatusRow, warnAt []float64) string {
+	switch {
+	case r.Overridden:
+		return "exceeded (override approved)"
+	case r.Exceeded:
+		return "EXCEEDED"
+	}
+	for _, t := range warnAt {
+		if r.Fraction >= t {
+			return "warning"
+		}
+	}
+	return "ok"
+}
+
+// budgetsConfig returns the [budgets] settings of the loaded config.
+func budgetsConfig() config.BudgetsConfig {
+	if cfg == nil {
+		return config.DefaultBudgetsConfig()
+	}
+	return cfg.Budgets
+}
+
+// maybeBlockSendForBudget refuses a send when a budget covering the session
+// or the target panes' agent types is exceeded without an approved override.
+// Failures of the check itself are reported but do not block the send.
+func maybeBlockSendForBudget(session string, panes []tmux.Pane) error {
+	wd, _ := os.Getwd()
+	err := budget.GuardSend(context.Background(), budgetsConfig(), wd, session, budget.PaneAgentTypes(panes), "ntm send")
+	var blocked *budget.BlockedError
+	if errors.As(err, &blocked) {
+		return err
+	}
+	if err != nil && !IsJSONOutput() {
+		fmt.Fprintf(os.Stderr, "Warning: budget check failed: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+func TestBudgetSetUnset(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	for _, args := range [][]string{
+		{"set", "daily", "50"},
+		{"set", "agent_type:cc", "$12.50"},
+	} {
+		cmd := newBudgetCmd()
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("budget %v: %v", args, err)
+		}
+	}
+
+	store, err := openScheduleStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	budgets, err := store.ListBudgets()
+	if err != nil || len(budgets) != 2 {
+		t.Fatalf("ListBudgets = %+v, %v", budgets, err)
+	}
+	if budgets[0].Scope != state.BudgetScopeAgentType || budgets[0].Target != "cc" || budgets[0].LimitUSD != 12.5 {
+		t.Errorf("agent_type budget = %+v", budgets[0])
+	}
+
+	for _, args := range [][]string{
+		{"set", "weekly", "10"},
+		{"set", "daily", "0"},
+		{"unset", "session:none"},
+	} {
+		cmd := newBudgetCmd()
+		cmd.SetArgs(args)
+		cmd.SilenceUsage, cmd.SilenceErrors = true, true
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("budget %v succeeded, want error", args)
+		}
+	}
+
+	cmd := newBudgetCmd()
+	cmd.SetArgs([]string{"unset", "daily"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("budget unset: %v", err)
+	}
+	if budgets, _ := store.ListBudgets(); len(budgets) != 1 {
+		t.Errorf("after unset: %+v", budgets)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/Dicklesworthstone/ntm/internal/checkpoint"
+	sessionPkg "github.com/Dicklesworthstone/ntm/internal/session"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 	"github.com/Dicklesworthstone/ntm/internal/tui/theme"
 )
@@ -33,16 +34,23 @@ Examples:
   ntm checkpoint list                     # List all checkpoints
   ntm checkpoint list myproject           # List checkpoints for session
   ntm checkpoint show myproject <id>      # Show checkpoint details
-  ntm checkpoint delete myproject <id>    # Delete a checkpoint`,
+  ntm checkpoint diff myproject ~2 last   # What changed between two checkpoints
+  ntm checkpoint delete myproject <id>    # Delete a checkpoint
+  ntm checkpoint gc                       # Remove scrollback no checkpoint uses
+  ntm checkpoint rekey                    # Re-encrypt everything with the active key`,
 	}
 
 	cmd.AddCommand(newCheckpointSaveCmd())
 	cmd.AddCommand(newCheckpointListCmd())
 	cmd.AddCommand(newCheckpointShowCmd())
+	cmd.AddCommand(newCheckpointDiffCmd())
 	cmd.AddCommand(newCheckpointDeleteCmd())
 	cmd.AddCommand(newCheckpointVerifyCmd())
 	cmd.AddCommand(newCheckpointExportCmd())
 	cmd.AddCommand(newCheckpointImportCmd())
+	cmd.AddCommand(newCheckpointGCCmd())
+	cmd.AddCommand(newCheckpointMigrateCmd())
+	cmd.AddCommand(newCheckpointRekeyCmd())
 	// TODO: newCheckpointRestoreCmd() not yet implemented
 
 	return cmd
@@ -248,7 +256,7 @@ func listCheckpointSessions(storage *checkpoint.Storage) ([]string, error) {
 
 	var sessions []string
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() && entry.Name() != checkpoint.BlobsDir {
 			sessions = append(sessions, entry.Name())
 		}
 	}
@@ -359,7 +367,7 @@ Examples:
 					fmt.Printf("    Status: %sdirty%s (%d staged, %d unstaged, %d untracked)\n",
 						colorize(t.Warning), "\033[0m",
 						cp.Git.StagedCount, cp.Git.UnstagedCount, cp.Git.UntrackedCount)
-					if cp.Git.PatchFile != "" {
+					if cp.HasGitPatch() {
 						fmt.Printf("    Patch: captured\n")
 					}
 				} else {
@@ -389,6 +397,64 @@ Examples:
 	return cmd
 }
 
+func newCheckpointDiffCmd() *cobra.Command {
+	var (
+		format   string
+		maxLines int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <session> <a> <b>",
+		Short: "Show what changed between two checkpoints",
+		Long: `Compare two checkpoints of a session: panes added and removed, new
+scrollback output per pane, git branch/HEAD/uncommitted-change differences,
+bead assignment changes and BV triage changes.
+
+Checkpoints can be given by ID, name, "last" or ~N (N-th most recent).
+
+Examples:
+  ntm checkpoint diff myproject 20251210-143052 20251210-153010
+  ntm checkpoint diff myproject ~2 last
+  ntm checkpoint diff myproject ~2 last --format=markdown > changes.md
+  ntm checkpoint diff myproject ~2 last --lines=0     # all new output`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session := args[0]
+			storage := checkpoint.NewStorage()
+			capturer := checkpoint.NewCapturerWithStorage(storage)
+
+			from, err := capturer.ParseCheckpointRef(session, args[1])
+			if err != nil {
+				return fmt.Errorf("loading checkpoint %s: %w", args[1], err)
+			}
+			to, err := capturer.ParseCheckpointRef(session, args[2])
+			if err != nil {
+				return fmt.Errorf("loading checkpoint %s: %w", args[2], err)
+			}
+
+			diff, err := checkpoint.DiffCheckpoints(storage, from, to)
+			if err != nil {
+				return fmt.Errorf("comparing checkpoints: %w", err)
+			}
+
+			if jsonOutput || format == checkpoint.DiffFormatJSON {
+				return json.NewEncoder(os.Stdout).Encode(diff)
+			}
+			rendered, err := diff.Render(format, maxLines)
+			if err != nil {
+				return err
+			}
+			fmt.Print(rendered)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", checkpoint.DiffFormatText, "output format: text, json or markdown")
+	cmd.Flags().IntVar(&maxLines, "lines", 20, "new output and patch lines shown per section (0 = all)")
+
+	return cmd
+}
+
 func newCheckpointDeleteCmd() *cobra.Command {
 	var force bool
 
@@ -453,6 +519,7 @@ func newCheckpointVerifyCmd() *cobra.Command {
 Performs the following checks:
 - Schema validation (version, required fields)
 - File existence (metadata.json, session.json, scrollback files)
+- Blob hashes (stored scrollback and git patch chunks are intact)
 - Consistency checks (pane count, valid indices)
 
 Examples:
@@ -516,6 +583,17 @@ func verifySingleCheckpoint(storage *checkpoint.Storage, session, id string) err
 		fmt.Printf("  %s\u2717%s Missing files\n", colorize(t.Error), "\033[0m")
 	}
 
+	// Blob hashes
+	if result.ChecksumsValid {
+		fmt.Printf("  %s\u2713%s Blob hashes match\n", colorize(t.Success), "\033[0m")
+	} else {
+		fmt.Printf("  %s\u2717%s Corrupt blobs\n", colorize(t.Error), "\033[0m")
+	}
+
+	if result.Details["encrypted"] == "true" {
+		fmt.Printf("  %s\u2713%s Encrypted at rest\n", colorize(t.Success), "\033[0m")
+	}
+
 	// Consistency
 	if result.ConsistencyValid {
 		fmt.Printf("  %s\u2713%s Consistency checks passed\n", colorize(t.Success), "\033[0m")
@@ -611,6 +689,161 @@ func verifyAllCheckpoints(storage *checkpoint.Storage, session string) error {
 	return nil
 }
 
+func newCheckpointGCCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove stored scrollback no checkpoint references",
+		Long: `Remove unreferenced blobs from the checkpoint blob store.
+
+Checkpoints store scrollback and git patches as content-addressed chunks
+shared between checkpoints, so deleting a checkpoint does not free its
+data immediately. gc removes every chunk no remaining checkpoint refers
+to. Chunks written in the last 15 minutes are kept, so a checkpoint being
+captured concurrently is not affected.
+
+Examples:
+  ntm checkpoint gc            # Remove unreferenced blobs
+  ntm checkpoint gc --dry-run  # Show what would be removed`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			storage := checkpoint.NewStorage()
+
+			result, err := storage.GC(checkpoint.GCOptions{DryRun: dryRun})
+			if err != nil {
+				return fmt.Errorf("collecting blobs: %w", err)
+			}
+
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(result)
+			}
+
+			t := theme.Current()
+			verb := "Removed"
+			if dryRun {
+				verb = "Would remove"
+			}
+			fmt.Printf("%s\u2713%s %s %d of %d blob(s), %s\n",
+				colorize(t.Success), "\033[0m", verb, len(result.Removed), result.Blobs, formatBytes(result.FreedBytes))
+			fmt.Printf("  %d referenced by %d checkpoint(s)", result.Referenced, result.Checkpoints)
+			if result.Kept > 0 {
+				fmt.Printf(", %d recent kept", result.Kept)
+			}
+			fmt.Println()
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report unreferenced blobs without removing them")
+
+	return cmd
+}
+
+func newCheckpointMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Move checkpoints from older versions into the blob store",
+		Long: `Upgrade checkpoints written before the blob store.
+
+Older checkpoints keep each pane's scrollback and the git patch as files
+in the checkpoint directory. They remain readable as they are; migrate
+moves their content into the shared blob store so it is deduplicated with
+newer checkpoints. Reading, listing or serving checkpoints never rewrites
+them.
+
+Examples:
+  ntm checkpoint migrate
+  ntm checkpoint migrate --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			storage := checkpoint.NewStorage()
+			result, err := storage.Migrate()
+			if err != nil {
+				if result != nil {
+					for _, name := range result.Failed {
+						fmt.Fprintf(os.Stderr, "  could not migrate: %s\n", name)
+					}
+				}
+				return fmt.Errorf("migrating checkpoints: %w", err)
+			}
+
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(result)
+			}
+
+			t := theme.Current()
+			fmt.Printf("%s\u2713%s Migrated %d of %d checkpoint(s)\n",
+				colorize(t.Success), "\033[0m", len(result.Migrated), result.Checkpoints)
+			for _, name := range result.Migrated {
+				fmt.Printf("  %s\n", name)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newCheckpointRekeyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rekey",
+		Short: "Re-encrypt checkpoints and saved sessions with the active key",
+		Long: `Re-encrypt every checkpoint file, blob and saved session with the
+active encryption key.
+
+Requires [encryption] enabled = true. To rotate keys, add the new key to
+the keyring, point active_key_id at it, run rekey, then remove the old
+key. Checkpoints written before encryption was enabled are encrypted too.
+
+Examples:
+  ntm checkpoint rekey
+  ntm checkpoint rekey --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !checkpoint.GetEncryptionEnabled() {
+				return fmt.Errorf("encryption is not enabled; set [encryption] enabled = true in config")
+			}
+
+			storage := checkpoint.NewStorage()
+			result, err := storage.Rekey()
+			if err != nil {
+				if result != nil {
+					for _, path := range result.Failed {
+						fmt.Fprintf(os.Stderr, "  could not decrypt: %s\n", path)
+					}
+				}
+				return fmt.Errorf("re-encrypting checkpoints: %w", err)
+			}
+
+			sessions, err := sessionPkg.Rekey()
+			if err != nil {
+				return fmt.Errorf("re-encrypting saved sessions: %w", err)
+			}
+
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(map[string]interface{}{
+					"checkpoint_files": result.Files,
+					"newly_encrypted":  result.Plaintext,
+					"sessions":         sessions,
+				})
+			}
+
+			t := theme.Current()
+			fmt.Printf("%s\u2713%s Re-encrypted %d checkpoint file(s) and %d saved session(s)\n",
+				colorize(t.Success), "\033[0m", result.Files, sessions)
+			if result.Plaintext > 0 {
+				fmt.Printf("  %d file(s) were previously unencrypted\n", result.Plaintext)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
 func newCheckpointExportCmd() *cobra.Command {
 	var (
 		output        string
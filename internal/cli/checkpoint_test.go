@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/checkpoint"
+	"github.com/Dicklesworthstone/ntm/internal/encryption"
+	sessionPkg "github.com/Dicklesworthstone/ntm/internal/session"
 )
 
 func TestNewCheckpointCmd(t *testing.T) {
@@ -26,7 +28,7 @@ func TestNewCheckpointCmd(t *testing.T) {
 		names[sub.Use] = true
 	}
 
-	expected := []string{"save <session>", "list [session]", "show <session> <id>", "delete <session> <id>"} // restore not yet implemented
+	expected := []string{"save <session>", "list [session]", "show <session> <id>", "diff <session> <a> <b>", "delete <session> <id>", "gc", "rekey"} // restore not yet implemented
 	for _, exp := range expected {
 		if !names[exp] {
 			t.Errorf("missing subcommand %q", exp)
@@ -66,6 +68,105 @@ func TestNewCheckpointShowCmd(t *testing.T) {
 	}
 }
 
+func TestCheckpointDiffCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	storage := checkpoint.NewStorage()
+	for i, id := range []string{"20260501-100000-a", "20260501-110000-b"} {
+		cp := &checkpoint.Checkpoint{
+			Version:     checkpoint.CurrentVersion,
+			ID:          id,
+			Name:        id,
+			SessionName: "proj",
+			CreatedAt:   time.Date(2026, 5, 1, 10+i, 0, 0, 0, time.UTC),
+			Git:         checkpoint.GitState{Branch: "main", Commit: strings.Repeat(string(rune('a'+i)), 40)},
+		}
+		if err := storage.Save(cp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := newCheckpointDiffCmd()
+	cmd.SetArgs([]string{"proj", "~2", "last", "--format=markdown"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("checkpoint diff: %v", err)
+	}
+
+	cmd = newCheckpointDiffCmd()
+	cmd.SetArgs([]string{"proj", "last", "missing"})
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	if err := cmd.Execute(); err == nil {
+		t.Error("diff against a missing checkpoint succeeded")
+	}
+}
+
+func TestCheckpointGCCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	storage := checkpoint.NewStorage()
+	hashes, err := storage.PutChunks("orphaned scrollback\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(storage.BlobPath(hashes[0]), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newCheckpointGCCmd()
+	cmd.SetArgs([]string{"--dry-run"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("checkpoint gc --dry-run: %v", err)
+	}
+	if !storage.HasBlob(hashes[0]) {
+		t.Fatal("dry run removed a blob")
+	}
+
+	cmd = newCheckpointGCCmd()
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("checkpoint gc: %v", err)
+	}
+	if storage.HasBlob(hashes[0]) {
+		t.Error("unreferenced blob survived gc")
+	}
+}
+
+func TestCheckpointRekeyCmd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(func() {
+		checkpoint.SetEncryptionConfig(nil)
+		sessionPkg.SetEncryptionConfig(nil)
+	})
+
+	cmd := newCheckpointRekeyCmd()
+	cmd.SetArgs([]string{})
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("rekey without encryption enabled succeeded")
+	}
+
+	storage := checkpoint.NewStorage()
+	hashes, err := storage.PutChunks("plaintext scrollback\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := make([]byte, encryption.KeySize)
+	checkpoint.SetEncryptionConfig(&checkpoint.EncryptionConfig{Enabled: true, EncryptKey: key, DecryptKeys: [][]byte{key}})
+	sessionPkg.SetEncryptionConfig(&sessionPkg.EncryptionConfig{Enabled: true, EncryptKey: key, DecryptKeys: [][]byte{key}})
+	cmd = newCheckpointRekeyCmd()
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("checkpoint rekey: %v", err)
+	}
+	data, err := os.ReadFile(storage.BlobPath(hashes[0]))
+	if err != nil || !encryption.IsEncryptedData(data) {
+		t.Errorf("blob not encrypted after rekey: %v", err)
+	}
+	if content, err := storage.GetChunks(hashes); err != nil || content != "plaintext scrollback\n" {
+		t.Errorf("GetChunks after rekey = %q, %v", content, err)
+	}
+}
+
 func TestNewCheckpointDeleteCmd(t *testing.T) {
 	cmd := newCheckpointDeleteCmd()
 
@@ -49,6 +49,13 @@ func resetFlags() {
 	robotSendDelay = 0
 	robotDiff = ""
 	robotDiffSince = "15m"
+	robotTransitions = ""
+	robotTransitionsPane = ""
+	robotTransitionsState = ""
+	robotTransitionsSince = ""
+	robotTransitionsUntil = ""
+	robotTransitionsLimit = 0
+	robotTransitionsStats = false
 	robotFormat = ""
 }
 
@@ -67,6 +67,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "ControllerInput",
 			Ref:  "cli.ControllerInput",
+			Type: ControllerInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "ControllerResponse",
@@ -105,6 +106,10 @@ func init() {
 			if value != nil {
 				opts = *value
 			}
+		default:
+			if err := kernel.DecodeInput(value, &opts); err != nil {
+				return nil, err
+			}
 		}
 		if strings.TrimSpace(opts.Session) == "" {
 			return nil, fmt.Errorf("session is required")
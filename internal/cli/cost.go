@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+)
+
+func newCostCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cost",
+		Short: "Inspect how agent usage is priced",
+	}
+	cmd.AddCommand(newCostPricingCmd())
+	return cmd
+}
+
+func newCostPricingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pricing",
+		Short: "Show and validate the model pricing catalog",
+		Long: `Model prices come from a catalog compiled into ntm, overridden by
+~/.config/ntm/pricing.toml and then by the project's .ntm/pricing.toml.
+Prices are USD per million tokens. Entries can carry an effective date
+(the new price applies from that day on), cache read/write prices and a
+batch discount; providers declare name prefixes, a default model for
+unknown variants and short aliases.
+
+Example override:
+  [providers.anthropic]
+  aliases = { big = "claude-opus-4" }
+
+  [[models]]
+  name = "claude-opus-4"
+  provider = "anthropic"
+  effective = "2026-03-01"
+  input = 5.0
+  output = 25.0
+  cache_read = 0.5
+  cache_write = 6.25
+  batch_discount = 0.5
+
+Costs are recomputed from token counts whenever the catalog changes;
+'ntm serve' picks up edits without a restart.`,
+	}
+	cmd.AddCommand(newCostPricingListCmd(), newCostPricingValidateCmd())
+	return cmd
+}
+
+// pricingRow is one catalog entry as listed.
+type pricingRow struct {
+	cost.PriceEntry
+	Current bool `json:"current"` // the entry in effect today
+}
+
+func newCostPricingListCmd() *cobra.Command {
+	var (
+		history bool
+		model   string
+	)
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List model prices in effect",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wd, _ := os.Getwd()
+			catalog, err := cost.LoadCatalog(wd)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			now := time.Now()
+
+			if model != "" {
+				entry, _ := catalog.Entry(model, now)
+				if IsJSONOutput() {
+					return output.PrintJSON(map[string]interface{}{
+						"model":    model,
+						"resolved": catalog.Resolve(model),
+						"entry":    entry,
+						"pricing":  entry.Pricing(),
+					})
+				}
+				fmt.Printf("%s is priced as %s (%s)\n", model, entry.Model, entry.Source)
+				return printPricingRows([]pricingRow{{PriceEntry: entry, Current: true}})
+			}
+
+			var rows []pricingRow
+			for _, name := range catalog.ModelNames() {
+				current, _ := catalog.Entry(name, now)
+				for _, e := range catalog.Models[name] {
+					isCurrent := e.Effective.Equal(current.Effective)
+					if isCurrent || history {
+						rows = append(rows, pricingRow{PriceEntry: e, Current: isCurrent})
+					}
+				}
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(map[string]interface{}{
+					"version":   catalog.Version(),
+					"sources":   catalog.Sources,
+					"fallback":  catalog.Fallback,
+					"providers": catalog.Providers,
+					"models":    rows,
+				})
+			}
+			if err := printPricingRows(rows); err != nil {
+				return err
+			}
+			fmt.Printf("\nUSD per million tokens. Sources: %s (version %s)\n", strings.Join(catalog.Sources, ", "), catalog.Version())
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&history, "history", false, "Include past and scheduled prices")
+	cmd.Flags().StringVar(&model, "model", "", "Show the price applied to this model name")
+	return cmd
+}
+
+func printPricingRows(rows []pricingRow) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tPROVIDER\tEFFECTIVE\tINPUT\tOUTPUT\tCACHE READ\tCACHE WRITE\tBATCH\tSOURCE")
+	for _, r := range rows {
+		p := r.Pricing()
+		effective := "-"
+		if !r.Effective.IsZero() {
+			effective = r.Effective.Format("2006-01-02")
+		}
+		if !r.Current {
+			effective += " (inactive)"
+		}
+		batch := "-"
+		if r.BatchDiscount > 0 {
+			batch = fmt.Sprintf("-%.0f%%", r.BatchDiscount*100)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Model, orDash(r.Provider), effective,
+			perMillion(r.InputPerM), perMillion(r.OutputPerM),
+			perMillion(p.CacheReadPrice()*1000), perMillion(p.CacheWritePrice()*1000),
+			batch, r.Source)
+	}
+	return w.Flush()
+}
+
+func perMillion(usd float64) string {
+	return "$" + strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.4f", usd), "0"), ".")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func newCostPricingValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [file...]",
+		Short: "Check pricing.toml files",
+		Long: `Check pricing catalog files for syntax errors, unknown keys, invalid
+prices and dates, and aliases or defaults that point at unpriced models.
+Each file is checked layered over the catalogs before it. Without
+arguments, the user and project overrides are checked.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := args
+			if len(paths) == 0 {
+				wd, _ := os.Getwd()
+				for _, path := range cost.CatalogPaths(wd) {
+					if _, err := os.Stat(path); err == nil {
+						paths = append(paths, path)
+					}
+				}
+			}
+
+			type result struct {
+				File  string `json:"file"`
+				Valid bool   `json:"valid"`
+				Error string `json:"error,omitempty"`
+			}
+			catalog := cost.DefaultCatalog()
+			results := make([]result, 0, len(paths))
+			failed := false
+			for _, path := range paths {
+				r := result{File: path, Valid: true}
+				data, err := os.ReadFile(path)
+				if err == nil {
+					var merged *cost.Catalog
+					if merged, err = catalog.MergeFile(data, path); err == nil {
+						catalog = merged
+					}
+				}
+				if err != nil {
+					r.Valid, r.Error, failed = false, err.Error(), true
+				}
+				results = append(results, r)
+			}
+
+			if IsJSONOutput() {
+				if err := output.PrintJSON(map[string]interface{}{"valid": !failed, "files": results, "version": catalog.Version()}); err != nil {
+					return err
+				}
+			} else {
+				if len(results) == 0 {
+					fmt.Println("No pricing overrides; using the built-in catalog.")
+				}
+				for _, r := range results {
+					if r.Valid {
+						fmt.Printf("✓ %s\n", r.File)
+					} else {
+						fmt.Printf("✗ %s\n", strings.ReplaceAll(r.Error, "\n", "\n    "))
+					}
+				}
+			}
+			if failed {
+				return errors.New("pricing catalog is invalid")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCostPricingValidate(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.toml")
+	bad := filepath.Join(dir, "bad.toml")
+	if err := os.WriteFile(good, []byte("[[models]]\nname = \"local-llm\"\ninput = 0.0\noutput = 0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte("[providers.openai]\naliases = { mini = \"gpt-nano\" }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newCostCmd()
+	cmd.SetArgs([]string{"pricing", "validate", good})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("validate good: %v", err)
+	}
+
+	cmd = newCostCmd()
+	cmd.SetArgs([]string{"pricing", "validate", good, bad})
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	if err := cmd.Execute(); err == nil {
+		t.Error("validate bad succeeded, want error")
+	}
+}
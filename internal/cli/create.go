@@ -31,6 +31,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "SessionCreateInput",
 			Ref:  "cli.SessionCreateInput",
+			Type: SessionCreateInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "CreateResponse",
@@ -64,6 +65,10 @@ func init() {
 			if value != nil {
 				opts = *value
 			}
+		default:
+			if err := kernel.DecodeInput(value, &opts); err != nil {
+				return nil, err
+			}
 		}
 		if strings.TrimSpace(opts.Session) == "" {
 			return nil, fmt.Errorf("session is required")
@@ -23,6 +23,7 @@ func newDashboardCmd() *cobra.Command {
 	var noTUI bool
 	var jsonOutput bool
 	var debug bool
+	var fleetMode bool
 
 	cmd := &cobra.Command{
 		Use:     "dashboard [session-name]",
@@ -43,6 +44,7 @@ Flags:
   --no-tui    Plain text output (no interactive UI)
   --json      JSON output (implies --no-tui)
   --debug     Enable debug mode with state inspection
+  --fleet     Show sessions from every [fleet] host, grouped by host
 
 Environment:
   CI=1              Auto-selects plain mode
@@ -55,6 +57,7 @@ Examples:
   ntm dash                  # Auto-detect session
   ntm dashboard --no-tui    # Plain text output for scripting
   ntm dashboard --json      # JSON output for automation
+  ntm dashboard --fleet     # Sessions across all fleet hosts
   CI=1 ntm dashboard        # Auto-detects plain mode in CI`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -78,6 +81,12 @@ Examples:
 				debug = true
 			}
 
+			if fleetMode {
+				if session != "" {
+					return fmt.Errorf("--fleet does not take a session name")
+				}
+				return runFleetDashboard(jsonOutput, noTUI)
+			}
 			if jsonOutput {
 				return runDashboardJSON(cmd.OutOrStdout(), cmd.ErrOrStderr(), session)
 			}
@@ -91,6 +100,7 @@ Examples:
 	cmd.Flags().BoolVar(&noTUI, "no-tui", false, "Plain text output (no interactive UI)")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "JSON output (implies --no-tui)")
 	cmd.Flags().BoolVar(&debug, "debug", false, "Enable debug mode with state inspection")
+	cmd.Flags().BoolVar(&fleetMode, "fleet", false, "Show sessions from every [fleet] host, grouped by host")
 	cmd.ValidArgsFunction = completeSessionArgs
 
 	return cmd
@@ -342,5 +352,13 @@ func runDashboard(w io.Writer, errW io.Writer, session string, debug bool) error
 		cancel()
 	}
 
+	// Refresh loops capture every pane; multiplex them over one tmux
+	// control-mode connection. Failure just leaves the exec path in place.
+	if cfg == nil || cfg.Tmux.ControlMode {
+		if _, err := tmux.EnableControlMode(context.Background(), session); err == nil {
+			defer tmux.CloseControlMode()
+		}
+	}
+
 	return dashboard.Run(session, projectDir)
 }
@@ -74,6 +74,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "DepsInput",
 			Ref:  "cli.DepsInput",
+			Type: DepsInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "DepsResponse",
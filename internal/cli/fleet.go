@@ -0,0 +1,302 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/fleet"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/tui/dashboard"
+)
+
+func newFleetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fleet",
+		Short: "View and drive sessions across several ntm serve hosts",
+		Long: `Aggregate several 'ntm serve' instances into one fleet.
+
+Hosts are configured in config.toml:
+
+  [fleet]
+  poll_interval_seconds = 5
+
+  [[fleet.hosts]]
+  name = "gpu-box"
+  url = "http://gpu-box:7337"
+  api_key_env = "NTM_GPU_BOX_KEY"
+
+Sessions are addressed as host:session. Use * as the host to target every
+host that has the session, or as the session to target every session on a
+host. Hosts that are unreachable are reported offline with their last known
+sessions.
+
+Examples:
+  ntm fleet                                   # Status of every host
+  ntm fleet send gpu-box:proj --cc "run the benchmarks"
+  ntm fleet send '*:proj' --all "git pull"    # Same session on every host
+  ntm dashboard --fleet                       # Live TUI grouped by host
+  ntm --robot-fleet-status                    # JSON for automation`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFleetStatus()
+		},
+	}
+
+	cmd.AddCommand(
+		newFleetStatusCmd(),
+		newFleetSendCmd(),
+	)
+	return cmd
+}
+
+func newFleetStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show sessions on every fleet host",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFleetStatus()
+		},
+	}
+}
+
+// loadFleet builds the fleet from the [fleet] config section.
+func loadFleet() (*fleet.Fleet, config.FleetConfig, error) {
+	fleetCfg := config.DefaultFleetConfig()
+	if cfg != nil {
+		fleetCfg = cfg.Fleet
+	}
+	if len(fleetCfg.Hosts) == 0 {
+		return nil, fleetCfg, errors.New("no fleet hosts configured; add [[fleet.hosts]] entries with name and url to config.toml")
+	}
+	f, err := fleet.NewFromConfig(fleetCfg)
+	return f, fleetCfg, err
+}
+
+func runFleetStatus() error {
+	f, _, err := loadFleet()
+	if err != nil {
+		return err
+	}
+	st := f.Poll(context.Background())
+	if IsJSONOutput() {
+		return output.PrintJSON(st)
+	}
+	return printFleetStatus(st)
+}
+
+func printFleetStatus(st *fleet.Status) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HOST\tSTATUS\tLATENCY\tSESSIONS\tURL")
+	for _, hs := range st.Hosts {
+		status := "online"
+		latency := fmt.Sprintf("%dms", hs.LatencyMs)
+		if !hs.Online {
+			status = "offline"
+			latency = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", hs.Name, status, latency, len(hs.Sessions), hs.URL)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, hs := range st.Hosts {
+		if !hs.Online {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", hs.Name, hs.Error)
+		}
+	}
+
+	if st.Summary.Sessions == 0 {
+		fmt.Println("\nNo sessions on any host.")
+		return nil
+	}
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TARGET\tSTATE\tPANES\tAGENTS")
+	for _, hs := range st.Hosts {
+		for _, s := range hs.Sessions {
+			state := "live"
+			switch {
+			case hs.Stale:
+				state = "stale"
+			case !s.Live:
+				state = s.Status
+			}
+			fmt.Fprintf(w, "%s:%s\t%s\t%d\t%s\n", hs.Name, s.Name, state, s.Panes, describeFleetAgents(s.Agents))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d hosts (%d online), %d sessions, %d agents\n",
+		st.Summary.Hosts, st.Summary.Online, st.Summary.Sessions, st.Summary.Agents)
+	return nil
+}
+
+func describeFleetAgents(agents []fleet.Agent) string {
+	if len(agents) == 0 {
+		return "-"
+	}
+	counts := make(map[string]int)
+	for _, a := range agents {
+		counts[a.Type]++
+	}
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[t], t))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func newFleetSendCmd() *cobra.Command {
+	var (
+		cc, cod, gmi bool
+		all          bool
+		panes        []int
+		queuePrompt  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "send <host:session>... <prompt>",
+		Short: "Send a prompt to sessions on one or more fleet hosts",
+		Long: `Send a prompt to sessions on fleet hosts concurrently.
+
+Every argument but the last is a host:session target; the last is the
+prompt. Agent filters work as in 'ntm send'. A failure on one host does not
+stop delivery to the others.
+
+Examples:
+  ntm fleet send gpu-box:proj --cc "run the benchmarks"
+  ntm fleet send web:api gpu-box:proj --cod "rebase on main"
+  ntm fleet send '*:proj' --all --queue "summarize your progress"`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			targets := make([]fleet.Target, 0, len(args)-1)
+			for _, arg := range args[:len(args)-1] {
+				t, err := fleet.ParseTarget(arg)
+				if err != nil {
+					return err
+				}
+				targets = append(targets, t)
+			}
+			req := fleet.SendRequest{
+				Message: args[len(args)-1],
+				All:     all,
+				Queue:   queuePrompt,
+			}
+			if cc {
+				req.AgentTypes = append(req.AgentTypes, "claude")
+			}
+			if cod {
+				req.AgentTypes = append(req.AgentTypes, "codex")
+			}
+			if gmi {
+				req.AgentTypes = append(req.AgentTypes, "gemini")
+			}
+			for _, p := range panes {
+				req.Panes = append(req.Panes, strconv.Itoa(p))
+			}
+			return runFleetSend(targets, req)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cc, "cc", false, "Send to Claude agents")
+	cmd.Flags().BoolVar(&cod, "cod", false, "Send to Codex agents")
+	cmd.Flags().BoolVar(&gmi, "gmi", false, "Send to Gemini agents")
+	cmd.Flags().BoolVar(&all, "all", false, "Send to all panes including the user pane")
+	cmd.Flags().IntSliceVarP(&panes, "pane", "p", nil, "Pane indices to send to (repeatable)")
+	cmd.Flags().BoolVar(&queuePrompt, "queue", false, "Queue the prompt and deliver it when each target agent is idle")
+	return cmd
+}
+
+func runFleetSend(targets []fleet.Target, req fleet.SendRequest) error {
+	f, _, err := loadFleet()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	var st *fleet.Status
+	for _, t := range targets {
+		if t.Host == "*" || t.Session == "*" {
+			st = f.Poll(ctx)
+			break
+		}
+	}
+	if st == nil {
+		st = f.Snapshot()
+	}
+	resolved, err := f.Resolve(targets, st)
+	if err != nil {
+		return err
+	}
+
+	results := f.Send(ctx, resolved, req)
+	failed := 0
+	for _, r := range results {
+		if !r.Success {
+			failed++
+		}
+	}
+
+	if IsJSONOutput() {
+		if err := output.PrintJSON(map[string]interface{}{
+			"success": failed == 0,
+			"results": results,
+		}); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range results {
+			switch {
+			case !r.Success:
+				fmt.Printf("✗ %s: %s\n", r.Target, r.Error)
+			case req.Queue:
+				fmt.Printf("✓ %s: queued for %d pane(s)\n", r.Target, r.Queued)
+			default:
+				fmt.Printf("✓ %s: sent to %d pane(s)\n", r.Target, len(r.Successful))
+			}
+			for _, fail := range r.Failed {
+				fmt.Printf("    pane %s\n", fail)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d fleet sends failed", failed, len(results))
+	}
+	return nil
+}
+
+// runFleetDashboard opens the dashboard's fleet mode, or prints the fleet
+// status when the TUI is disabled.
+func runFleetDashboard(jsonOutput, noTUI bool) error {
+	f, fleetCfg, err := loadFleet()
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return robot.PrintFleetStatus(fleetCfg)
+	}
+	if noTUI {
+		return printFleetStatus(f.Poll(context.Background()))
+	}
+	return dashboard.RunFleet(f, fleet.PollInterval(fleetCfg))
+}
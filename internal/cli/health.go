@@ -52,6 +52,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "SessionHealthInput",
 			Ref:  "cli.SessionHealthInput",
+			Type: SessionHealthInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "HealthOutput",
@@ -85,6 +86,10 @@ func init() {
 			if value != nil {
 				opts = *value
 			}
+		default:
+			if err := kernel.DecodeInput(value, &opts); err != nil {
+				return nil, err
+			}
 		}
 		if strings.TrimSpace(opts.Session) == "" {
 			return nil, fmt.Errorf("session is required")
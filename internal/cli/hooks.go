@@ -17,7 +17,7 @@ import (
 func newHooksCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "hooks",
-		Short: "Manage git hooks for quality checks and coordination",
+		Short: "Manage git and agent CLI hooks for quality checks and coordination",
 		Long: `Install and manage git hooks for quality checks (UBS) and coordination (Agent Mail).
 
 UBS pre-commit hook:
@@ -38,7 +38,10 @@ Examples:
 
   ntm hooks guard install           # Install Agent Mail pre-commit guard
   ntm hooks guard install --warn-only  # Print warn-only setup instructions
-  ntm hooks guard uninstall          # Remove Agent Mail pre-commit guard`,
+  ntm hooks guard uninstall          # Remove Agent Mail pre-commit guard
+
+  ntm hooks agent install           # Report agent activity via Claude/Gemini/Codex hooks
+  ntm hooks agent status            # Show which agent CLIs have the hooks`,
 	}
 
 	cmd.AddCommand(
@@ -47,6 +50,7 @@ Examples:
 		newHooksStatusCmd(),
 		newHooksRunCmd(),
 		newHooksGuardCmd(),
+		newHooksAgentCmd(),
 	)
 
 	return cmd
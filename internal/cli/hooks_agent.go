@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/agenthooks"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tui/theme"
+)
+
+// agentEmitCommand is the command agent CLI hooks run.
+const agentEmitCommand = "ntm hooks agent emit"
+
+// maxHookPayload bounds how much hook input emit reads.
+const maxHookPayload = 1 << 20
+
+func newHooksAgentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Manage agent CLI hooks that report exact agent activity",
+		Long: `Register hooks in the agent CLIs themselves so they report what they do:
+turn start and stop, tool calls, shell commands with exit codes, edited
+files and permission prompts. Events are posted to a per-session Unix
+socket served by 'ntm serve' (or 'ntm hooks agent listen') and feed status
+detection, the file change tracker, the events bus and the command
+timeline ('ntm activity commands').
+
+Hooks are installed for:
+  Claude Code  ~/.claude/settings.json  SessionStart, UserPromptSubmit, PreToolUse,
+                                        PostToolUse, Notification, Stop
+  Gemini CLI   ~/.gemini/settings.json  SessionStart, BeforeAgent, BeforeTool,
+                                        AfterTool, Notification, AfterAgent
+  Codex        ~/.codex/config.toml     notify (turn completion only)
+
+Existing settings and hooks, including the 'ntm safety' PreToolUse hook,
+are kept. Hooks exit immediately when ntm is not listening.`,
+	}
+
+	cmd.AddCommand(
+		newHooksAgentInstallCmd(),
+		newHooksAgentUninstallCmd(),
+		newHooksAgentStatusCmd(),
+		newHooksAgentEmitCmd(),
+		newHooksAgentListenCmd(),
+	)
+	return cmd
+}
+
+func newHooksAgentInstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install",
+		Short: "Register ntm's hooks with Claude Code, Gemini CLI and Codex",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := agenthooks.Install(agentEmitCommand)
+			if err != nil {
+				return err
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(results)
+			}
+			printAgentHookResults(results, "Installed", "already installed")
+			fmt.Println("\nEvents are received while 'ntm serve' or 'ntm hooks agent listen' runs.")
+			fmt.Println("Restart running agents to pick up the hooks.")
+			return nil
+		},
+	}
+}
+
+func newHooksAgentUninstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove ntm's hooks from the agent CLIs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := agenthooks.Uninstall()
+			if err != nil {
+				return err
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(results)
+			}
+			printAgentHookResults(results, "Removed", "not installed")
+			return nil
+		},
+	}
+}
+
+func newHooksAgentStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show which agent CLIs have ntm's hooks installed",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := agenthooks.Status()
+			if err != nil {
+				return err
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(results)
+			}
+			t := theme.Current()
+			for _, r := range results {
+				switch {
+				case r.Installed:
+					fmt.Printf("%s✓%s %-12s %s\n", colorize(t.Success), "\033[0m", r.Name, r.Path)
+				case r.Note != "":
+					fmt.Printf("- %-12s %s\n", r.Name, r.Note)
+				default:
+					fmt.Printf("✗ %-12s not installed (%s)\n", r.Name, r.Path)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func printAgentHookResults(results []agenthooks.TargetStatus, changed, unchanged string) {
+	t := theme.Current()
+	for _, r := range results {
+		switch {
+		case r.Changed:
+			fmt.Printf("%s✓%s %s hooks for %s (%s)\n", colorize(t.Success), "\033[0m", changed, r.Name, r.Path)
+		case r.Note != "":
+			fmt.Printf("- %s: %s\n", r.Name, r.Note)
+		default:
+			fmt.Printf("- %s: %s\n", r.Name, unchanged)
+		}
+	}
+}
+
+func newHooksAgentEmitCmd() *cobra.Command {
+	var agentType string
+
+	cmd := &cobra.Command{
+		Use:   "emit [payload]",
+		Short: "Forward one hook event to ntm (run by agent CLI hooks)",
+		Long: `Read a hook payload from stdin (or the last argument, as Codex passes it),
+normalize it and post it to the session's hook socket. Always exits 0 so a
+missing listener or malformed payload never disrupts the agent.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pane := os.Getenv("TMUX_PANE")
+			if pane == "" {
+				return nil // Not in tmux, so not an ntm agent
+			}
+
+			var payload []byte
+			if len(args) > 0 {
+				payload = []byte(args[0])
+			} else {
+				payload, _ = io.ReadAll(io.LimitReader(cmd.InOrStdin(), maxHookPayload))
+			}
+			ev, err := agenthooks.Parse(agentType, payload)
+			if err != nil {
+				return nil
+			}
+
+			session, err := tmux.DefaultClient.Run("display-message", "-p", "-t", pane, "#{session_name}")
+			if err != nil || strings.TrimSpace(session) == "" {
+				return nil
+			}
+			ev.Session = strings.TrimSpace(session)
+			ev.Pane = pane
+			ev.Time = time.Now().UTC()
+			_ = agenthooks.Send(ev)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&agentType, "agent", "cc", "Agent type sending the event: cc, cod, gmi")
+	return cmd
+}
+
+func newHooksAgentListenCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "listen [session]",
+		Short: "Receive hook events in the foreground",
+		Long: `Serve hook sockets and record events without running 'ntm serve'.
+With a session, only that session is served and each event is printed;
+otherwise every tmux session is served.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openTransitionStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			recorder := agenthooks.NewRecorder(store)
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			if len(args) == 0 {
+				fmt.Println("Serving agent hook sockets for all sessions (Ctrl+C to stop)")
+				agenthooks.NewManager(recorder.Handle).Run(ctx, agentHookSyncInterval)
+				return nil
+			}
+
+			l, err := agenthooks.Listen(args[0], func(ev agenthooks.Event) {
+				recorder.Handle(ev)
+				printHookEvent(ev)
+			})
+			if err != nil {
+				return err
+			}
+			defer l.Close()
+			if !IsJSONOutput() {
+				fmt.Printf("Listening on %s (Ctrl+C to stop)\n", l.Path())
+			}
+			<-ctx.Done()
+			return nil
+		},
+	}
+}
+
+func printHookEvent(ev agenthooks.Event) {
+	if IsJSONOutput() {
+		_ = output.PrintJSON(ev)
+		return
+	}
+	line := fmt.Sprintf("%s %s %-4s %-13s", ev.Time.Local().Format("15:04:05"), ev.Pane, ev.Agent, ev.Kind)
+	if ev.Tool != "" {
+		line += " " + ev.Tool
+	}
+	switch {
+	case ev.Command != "":
+		line += " $ " + truncatePrompt(ev.Command, 80)
+	case len(ev.Files) > 0:
+		line += " " + strings.Join(ev.Files, ", ")
+	case ev.Message != "":
+		line += " " + truncatePrompt(ev.Message, 80)
+	}
+	if ev.ExitCode != nil {
+		line += fmt.Sprintf(" (exit %d)", *ev.ExitCode)
+	}
+	fmt.Println(line)
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+func TestHooksAgentInstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".claude"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newHooksAgentCmd()
+	cmd.SetArgs([]string{"install"})
+	if _, err := captureStdout(t, cmd.Execute); err != nil {
+		t.Fatalf("hooks agent install: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, ".claude", "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), agentEmitCommand+" --agent cc") {
+		t.Errorf("settings.json = %s", data)
+	}
+
+	cmd = newHooksAgentCmd()
+	cmd.SetArgs([]string{"status"})
+	out, err := captureStdout(t, cmd.Execute)
+	if err != nil {
+		t.Fatalf("hooks agent status: %v", err)
+	}
+	if !strings.Contains(out, "✓") || !strings.Contains(out, "Claude Code") {
+		t.Errorf("status output = %q", out)
+	}
+}
+
+func TestHooksAgentEmitOutsideTmux(t *testing.T) {
+	t.Setenv("TMUX_PANE", "")
+	cmd := newHooksAgentCmd()
+	cmd.SetArgs([]string{"emit", "--agent", "cc"})
+	cmd.SetIn(strings.NewReader(`{"hook_event_name":"Stop"}`))
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("emit outside tmux = %v, want silent success", err)
+	}
+}
+
+func TestActivityCommands(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	store, err := openTransitionStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := 2
+	started := time.Now().Add(-time.Minute)
+	if err := store.RecordAgentCommand(&state.AgentCommand{
+		SessionID: "proj", PaneID: "%1", AgentType: "cc", Tool: "Bash",
+		Command: "go test ./...", ExitCode: &code, StartedAt: &started, FinishedAt: started.Add(5 * time.Second),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	cmd := newActivityCommandsCmd()
+	cmd.SetArgs([]string{"proj"})
+	out, err := captureStdout(t, cmd.Execute)
+	if err != nil {
+		t.Fatalf("activity commands: %v", err)
+	}
+	if !strings.Contains(out, "go test ./...") || !strings.Contains(out, "Bash") || !strings.Contains(out, " 2 ") {
+		t.Errorf("output = %q", out)
+	}
+}
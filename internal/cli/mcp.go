@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/mcp"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/serve"
+)
+
+type mcpServeOptions struct {
+	HTTPAddr        string
+	HTTPPath        string
+	AuthMode        string
+	APIKey          string
+	ApprovalTimeout time.Duration
+	NoApproval      bool
+}
+
+func newMCPCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Model Context Protocol server exposing ntm commands as tools",
+		Long: `Run ntm as a Model Context Protocol (MCP) server.
+
+Every command in the kernel registry is published as an MCP tool, with its
+input schema, safety level and examples taken from the registry metadata.
+Danger-level commands (e.g. sessions_kill) are routed through the approval
+engine and only run after a human approves them with 'ntm approve'.
+
+Examples:
+  ntm mcp serve                          # stdio transport (for agent configs)
+  ntm mcp serve --http 127.0.0.1:7338    # streamable HTTP transport
+  ntm mcp serve --http 0.0.0.0:7338 --auth-mode api_key --api-key $KEY
+  ntm mcp tools --json                   # Show the published tool list`,
+	}
+
+	cmd.AddCommand(newMCPServeCmd(), newMCPToolsCmd())
+	return cmd
+}
+
+func newMCPServeCmd() *cobra.Command {
+	opts := mcpServeOptions{
+		HTTPPath:        "/mcp",
+		ApprovalTimeout: mcp.DefaultApprovalTimeout,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve kernel commands over MCP (stdio or HTTP)",
+		Long: `Serve kernel commands over MCP.
+
+By default the server speaks newline-delimited JSON-RPC on stdin/stdout, which
+is what agent CLIs expect for locally configured MCP servers. Diagnostics are
+written to stderr. With --http, the streamable HTTP transport is served on the
+given address instead.
+
+The HTTP transport runs commands with your tmux access, so it only binds a
+loopback address unless --auth-mode api_key is set. Clients then send the
+key as "Authorization: Bearer <key>" (or X-API-Key), as with ntm serve.
+
+Example agent configuration (Claude Code .mcp.json):
+  {"mcpServers": {"ntm": {"command": "ntm", "args": ["mcp", "serve"]}}}`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMCPServe(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.HTTPAddr, "http", "", "Serve streamable HTTP on this address instead of stdio (e.g. 127.0.0.1:7338)")
+	cmd.Flags().StringVar(&opts.HTTPPath, "path", opts.HTTPPath, "HTTP endpoint path for the streamable HTTP transport")
+	cmd.Flags().StringVar(&opts.AuthMode, "auth-mode", "local", "HTTP auth mode: local (loopback only) or api_key")
+	cmd.Flags().StringVar(&opts.APIKey, "api-key", "", "API key clients must send as a bearer token in api_key auth mode")
+	cmd.Flags().DurationVar(&opts.ApprovalTimeout, "approval-timeout", opts.ApprovalTimeout, "How long danger-level tool calls wait for approval")
+	cmd.Flags().BoolVar(&opts.NoApproval, "no-approval", false, "Disable the approval engine (danger-level tools are refused)")
+
+	return cmd
+}
+
+func newMCPToolsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "tools",
+		Short: "List the MCP tools derived from the kernel registry",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srv := mcp.NewServer(mcp.Config{Version: Version})
+			tools := srv.Tools()
+			if IsJSONOutput() {
+				return output.PrintJSON(mcp.ListToolsResult{Tools: tools})
+			}
+			if len(tools) == 0 {
+				fmt.Println("No MCP tools available.")
+				return nil
+			}
+			for _, tool := range tools {
+				safety, _ := tool.Meta["ntm/safety"].(string)
+				fmt.Printf("%-24s %-8s %s\n", tool.Name, safety, tool.Title)
+			}
+			return nil
+		},
+	}
+}
+
+func runMCPServe(opts mcpServeOptions) error {
+	// Stdout carries protocol traffic in stdio mode; keep logs on stderr.
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	cfg := mcp.Config{
+		Version:         Version,
+		ApprovalTimeout: opts.ApprovalTimeout,
+		RequestedBy:     "mcp:" + getCurrentApprover(),
+		Logger:          logger,
+	}
+
+	if !opts.NoApproval {
+		engine, store, err := getApprovalEngine()
+		if err != nil {
+			logger.Warn("approval engine unavailable; danger-level tools will be refused", "error", err)
+		} else {
+			defer store.Close()
+			cfg.Approver = engine
+		}
+	}
+
+	srv := mcp.NewServer(cfg)
+
+	var handler http.Handler
+	if opts.HTTPAddr != "" {
+		h, err := mcpHTTPHandler(opts, srv)
+		if err != nil {
+			return err
+		}
+		handler = h
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if opts.HTTPAddr == "" {
+		logger.Info("mcp server starting", "transport", "stdio", "tools", len(srv.Tools()))
+		err := srv.ServeStdio(ctx, os.Stdin, os.Stdout)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+
+	httpSrv := &http.Server{
+		Addr:              opts.HTTPAddr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = httpSrv.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("mcp server starting", "transport", "http", "addr", opts.HTTPAddr, "path", opts.HTTPPath, "auth", opts.AuthMode, "tools", len(srv.Tools()))
+	if err := httpSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("mcp http server: %w", err)
+	}
+	return nil
+}
+
+// mcpHTTPHandler builds the HTTP transport handler, applying the same rules
+// as ntm serve: without an API key the server must bind a loopback address.
+func mcpHTTPHandler(opts mcpServeOptions, srv *mcp.Server) (http.Handler, error) {
+	mode, err := serve.ParseAuthMode(opts.AuthMode)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(opts.HTTPPath, srv.HTTPHandler())
+
+	switch mode {
+	case serve.AuthModeLocal:
+		// ":7338" listens on every interface, so only an explicit loopback
+		// host is safe without credentials.
+		host, _, err := net.SplitHostPort(opts.HTTPAddr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --http address %q: %w", opts.HTTPAddr, err)
+		}
+		if host == "" || !serve.IsLoopbackHost(host) {
+			return nil, fmt.Errorf("refusing to serve MCP on %s without auth; bind a loopback address or set --auth-mode api_key --api-key", opts.HTTPAddr)
+		}
+		return mux, nil
+	case serve.AuthModeAPIKey:
+		if opts.APIKey == "" {
+			return nil, fmt.Errorf("auth mode api_key requires --api-key")
+		}
+		return serve.RequireAPIKey(opts.APIKey, mux), nil
+	default:
+		return nil, fmt.Errorf("auth mode %s is not supported by mcp serve (valid: local, api_key)", mode)
+	}
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/kernel"
+	"github.com/Dicklesworthstone/ntm/internal/mcp"
+)
+
+func TestKernelInputsPublishMCPSchemas(t *testing.T) {
+	for _, cmd := range kernel.List() {
+		if cmd.Input == nil {
+			continue
+		}
+		if _, ok := kernel.SchemaOf(cmd.Input); !ok {
+			t.Errorf("%s: no schema for input %q; set SchemaRef.Type", cmd.Name, cmd.Input.Ref)
+		}
+	}
+}
+
+func TestMCPHTTPHandlerAuth(t *testing.T) {
+	srv := mcp.NewServer(mcp.Config{})
+
+	for _, tc := range []struct {
+		name string
+		opts mcpServeOptions
+		ok   bool
+	}{
+		{"loopback", mcpServeOptions{HTTPAddr: "127.0.0.1:7338", AuthMode: "local"}, true},
+		{"localhost", mcpServeOptions{HTTPAddr: "localhost:7338"}, true},
+		{"all interfaces", mcpServeOptions{HTTPAddr: ":7338", AuthMode: "local"}, false},
+		{"public host", mcpServeOptions{HTTPAddr: "0.0.0.0:7338", AuthMode: "local"}, false},
+		{"api key without key", mcpServeOptions{HTTPAddr: "0.0.0.0:7338", AuthMode: "api_key"}, false},
+		{"api key", mcpServeOptions{HTTPAddr: "0.0.0.0:7338", AuthMode: "api_key", APIKey: "secret"}, true},
+		{"oidc", mcpServeOptions{HTTPAddr: "127.0.0.1:7338", AuthMode: "oidc"}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.HTTPPath = "/mcp"
+			_, err := mcpHTTPHandler(tc.opts, srv)
+			if (err == nil) != tc.ok {
+				t.Errorf("mcpHTTPHandler err = %v, want ok=%v", err, tc.ok)
+			}
+		})
+	}
+
+	h, err := mcpHTTPHandler(mcpServeOptions{HTTPAddr: "0.0.0.0:7338", HTTPPath: "/mcp", AuthMode: "api_key", APIKey: "secret"}, srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ping := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	for _, tc := range []struct {
+		auth string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(ping))
+		req.Header.Set("Content-Type", "application/json")
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("Authorization %q: status = %d, want %d", tc.auth, rec.Code, tc.want)
+		}
+	}
+}
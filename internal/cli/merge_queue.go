@@ -0,0 +1,416 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/agentmail"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/mergequeue"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/worktrees"
+)
+
+func newMergeQueueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge-queue",
+		Short: "Land agent worktree branches one at a time behind a test gate",
+		Long: `Serialize agent worktree branches onto a target branch.
+
+'ntm merge-queue run' takes queued branches in order, rebases each onto the
+current target in a scratch worktree, runs the configured test command
+there, and fast-forwards the target only when the tests pass. Your own
+checkout and the agents' worktrees are never modified. When a branch
+conflicts or its tests fail, the owning agent is told via 'ntm send' (or
+Agent Mail with --notify mail) and the queue moves on.
+
+The target must not be checked out in any worktree, since updating it would
+leave that checkout stale; detach it with 'git switch --detach' or use an
+integration branch.
+
+Configure defaults in config.toml:
+
+  [merge_queue]
+  target = "main"
+  test_command = "go test ./..."
+  notify = "send"
+
+An entry left running by a runner that died is queued again once it has run
+longer than the test timeout plus ten minutes (two hours without a
+timeout); 'ntm merge-queue remove' drops an entry right away.
+
+Examples:
+  ntm merge-queue add cc_1 cod_1
+  ntm merge-queue list
+  ntm merge-queue run --test "make test"
+  ntm merge-queue remove 12`,
+	}
+
+	cmd.AddCommand(
+		newMergeQueueAddCmd(),
+		newMergeQueueListCmd(),
+		newMergeQueueRunCmd(),
+		newMergeQueueRemoveCmd(),
+	)
+	return cmd
+}
+
+func newMergeQueueAddCmd() *cobra.Command {
+	var (
+		sessionName string
+		target      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <agent-name>...",
+		Short: "Queue agents' worktree branches for merging",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+
+			session := sessionName
+			if session == "" {
+				session = tmux.GetCurrentSession()
+				if session == "" {
+					session = filepath.Base(dir)
+				}
+			}
+
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			manager := worktrees.NewManager(dir, session)
+			var queued []state.MergeRequest
+			for _, agentName := range args {
+				info, err := manager.GetWorktreeForAgent(agentName)
+				if err != nil {
+					return fmt.Errorf("failed to get worktree info: %w", err)
+				}
+				if !info.Created {
+					return fmt.Errorf("no worktree found for agent: %s", agentName)
+				}
+
+				req := state.MergeRequest{
+					ProjectPath: dir,
+					SessionID:   session,
+					Agent:       agentName,
+					Branch:      info.BranchName,
+					Target:      target,
+				}
+				if err := store.EnqueueMerge(&req); err != nil {
+					return err
+				}
+				queued = append(queued, req)
+			}
+
+			if IsJSONOutput() {
+				return output.PrintJSON(queued)
+			}
+			for _, req := range queued {
+				fmt.Printf("Queued %s (#%d)\n", req.Branch, req.ID)
+			}
+			fmt.Println("Run 'ntm merge-queue run' to land queued branches.")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&sessionName, "session", "", "Session the worktrees belong to (defaults to current session)")
+	cmd.Flags().StringVar(&target, "target", "", "Branch to merge into (default: merge_queue.target, else main or master)")
+	return cmd
+}
+
+func newMergeQueueListCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the merge queue for this project",
+		Long: `List queued and running entries for the project in the current
+directory. Use --all to include finished entries and their outcomes.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			q := state.MergeQuery{ProjectPath: dir}
+			if !all {
+				q.Statuses = []state.MergeStatus{state.MergeStatusQueued, state.MergeStatusRunning}
+			}
+			requests, err := store.ListMergeRequests(q)
+			if err != nil {
+				return err
+			}
+
+			if IsJSONOutput() {
+				if requests == nil {
+					requests = []state.MergeRequest{}
+				}
+				return output.PrintJSON(requests)
+			}
+			if len(requests) == 0 {
+				fmt.Println("Merge queue is empty.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tAGENT\tBRANCH\tTARGET\tSTATUS\tQUEUED\tDETAIL")
+			for _, req := range requests {
+				target := req.Target
+				if target == "" {
+					target = "-"
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					req.ID, req.Agent, req.Branch, target, req.Status,
+					req.EnqueuedAt.Local().Format("2006-01-02 15:04"), mergeRequestDetail(req))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Include merged and failed entries")
+	return cmd
+}
+
+func newMergeQueueRunCmd() *cobra.Command {
+	var (
+		testCommand string
+		target      string
+		timeout     time.Duration
+		notify      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Land queued branches in order",
+		Long: `Process the merge queue for the project in the current directory
+until it is empty. Each branch is rebased onto the current target, tested,
+and fast-forwarded only on green; conflicts and test failures are reported
+to the owning agent and the queue continues with the next branch.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+
+			mqCfg := config.DefaultMergeQueueConfig()
+			if cfg != nil {
+				mqCfg = cfg.MergeQueue
+			}
+			opts := worktrees.LandOptions{
+				Target:      mqCfg.Target,
+				TestCommand: mqCfg.TestCommand,
+				TestTimeout: time.Duration(mqCfg.TestTimeoutSeconds) * time.Second,
+			}
+			if cmd.Flags().Changed("test") {
+				opts.TestCommand = testCommand
+			}
+			if target != "" {
+				opts.Target = target
+			}
+			if cmd.Flags().Changed("timeout") {
+				opts.TestTimeout = timeout
+			}
+			if !cmd.Flags().Changed("notify") {
+				notify = mqCfg.Notify
+			}
+
+			var notifier mergequeue.NotifyFunc
+			switch notify {
+			case "", "send":
+				notifier = mergequeue.NotifyPane
+			case "mail":
+				notifier = mailMergeNotifier(dir)
+			case "none":
+			default:
+				return fmt.Errorf("invalid --notify %q (use send, mail or none)", notify)
+			}
+
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			if opts.TestCommand == "" && !IsJSONOutput() {
+				fmt.Println("Warning: no test command configured; branches land after a clean rebase (set merge_queue.test_command or --test)")
+			}
+
+			runner := mergequeue.NewRunner(store, opts, notifier)
+			var done []state.MergeRequest
+			for {
+				req, err := runner.Step(dir)
+				if err != nil {
+					return err
+				}
+				if req == nil {
+					break
+				}
+				done = append(done, *req)
+				if !IsJSONOutput() {
+					printMergeResult(*req)
+				}
+			}
+
+			if IsJSONOutput() {
+				if done == nil {
+					done = []state.MergeRequest{}
+				}
+				return output.PrintJSON(map[string]interface{}{
+					"project":   dir,
+					"processed": done,
+				})
+			}
+			if len(done) == 0 {
+				fmt.Println("Merge queue is empty.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&testCommand, "test", "", "Test command run in the rebased tree (default: merge_queue.test_command)")
+	cmd.Flags().StringVar(&target, "target", "", "Branch to merge into for entries queued without one")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Fail the tests after this long (default: merge_queue.test_timeout_seconds)")
+	cmd.Flags().StringVar(&notify, "notify", "send", "How to tell agents about conflicts and failures: send, mail, none")
+	return cmd
+}
+
+func newMergeQueueRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <id>...",
+		Short: "Remove entries from the merge queue",
+		Long: `Remove merge queue entries by ID, as shown by 'ntm merge-queue list'.
+Use it to drop a queued branch or an entry stuck running after its runner
+died; the branch can then be queued again.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids := make([]int64, 0, len(args))
+			for _, arg := range args {
+				id, err := strconv.ParseInt(strings.TrimPrefix(arg, "#"), 10, 64)
+				if err != nil || id <= 0 {
+					return fmt.Errorf("invalid merge queue entry ID %q", arg)
+				}
+				ids = append(ids, id)
+			}
+
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			for _, id := range ids {
+				if err := store.RemoveMergeRequest(id); err != nil {
+					return err
+				}
+			}
+
+			if IsJSONOutput() {
+				return output.PrintJSON(map[string]interface{}{"removed": ids})
+			}
+			for _, id := range ids {
+				fmt.Printf("Removed #%d\n", id)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func printMergeResult(req state.MergeRequest) {
+	switch req.Status {
+	case state.MergeStatusMerged:
+		fmt.Printf("✓ %s merged into %s (%s)\n", req.Branch, req.Target, shortCommit(req.MergedCommit))
+	default:
+		line := fmt.Sprintf("✗ %s %s", req.Branch, strings.ReplaceAll(string(req.Status), "_", " "))
+		if detail := mergeRequestDetail(req); detail != "" {
+			line += ": " + detail
+		}
+		if req.Notified {
+			line += " (agent notified)"
+		}
+		fmt.Println(line)
+	}
+}
+
+// mergeRequestDetail summarizes an entry's outcome for one table cell.
+func mergeRequestDetail(req state.MergeRequest) string {
+	switch req.Status {
+	case state.MergeStatusMerged:
+		return shortCommit(req.MergedCommit)
+	case state.MergeStatusConflict:
+		return strings.Join(req.Conflicts, ", ")
+	case state.MergeStatusTestsFailed, state.MergeStatusFailed:
+		lines := strings.Split(strings.TrimSpace(req.Output), "\n")
+		return truncatePrompt(lines[len(lines)-1], 60)
+	}
+	return ""
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// mailMergeNotifier reports failures to the owning agent through Agent Mail,
+// falling back to typing into its pane when the server is unavailable.
+func mailMergeNotifier(projectKey string) mergequeue.NotifyFunc {
+	return func(req state.MergeRequest, message string) error {
+		client := newAgentMailClient(projectKey)
+		if !client.IsAvailable() {
+			return mergequeue.NotifyPane(req, message)
+		}
+		pane, err := mergequeue.FindAgentPane(req.SessionID, req.Agent)
+		if err != nil {
+			return err
+		}
+		recipient := resolveAgentName(*pane)
+		if recipient == "" {
+			return mergequeue.NotifyPane(req, message)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		project, err := client.EnsureProject(ctx, projectKey)
+		if err != nil {
+			return fmt.Errorf("ensuring project: %w", err)
+		}
+		projectSlug := agentmail.ProjectSlugFromPath(projectKey)
+		if project.Slug != "" {
+			projectSlug = project.Slug
+		}
+		_, err = client.SendOverseerMessage(ctx, agentmail.OverseerMessageOptions{
+			ProjectSlug: projectSlug,
+			Recipients:  []string{recipient},
+			Subject:     fmt.Sprintf("Merge queue: %s was not merged", req.Branch),
+			BodyMD:      message,
+		})
+		return err
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/worktrees"
+)
+
+func TestMergeQueueAddAndRun(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	repo := t.TempDir()
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git(repo, "init", "-b", "main")
+	git(repo, "config", "user.email", "test@test.com")
+	git(repo, "config", "user.name", "Test")
+	git(repo, "commit", "--allow-empty", "-m", "init")
+	git(repo, "checkout", "--detach")
+
+	info, err := worktrees.NewManager(repo, "proj").CreateForAgent("cc_1")
+	if err != nil {
+		t.Fatalf("CreateForAgent: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(info.Path, "work.txt"), []byte("done\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(info.Path, "add", "work.txt")
+	git(info.Path, "commit", "-m", "agent work")
+	t.Chdir(repo)
+
+	cmd := newMergeQueueCmd()
+	cmd.SetArgs([]string{"add", "cc_1", "--session", "proj"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("merge-queue add: %v", err)
+	}
+
+	cmd = newMergeQueueCmd()
+	cmd.SetArgs([]string{"run", "--test", "test -f work.txt", "--notify", "none"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("merge-queue run: %v", err)
+	}
+
+	if got := git(repo, "show", "main:work.txt"); got != "done" {
+		t.Errorf("main:work.txt = %q", got)
+	}
+
+	store, err := openScheduleStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	cwd, _ := os.Getwd()
+	requests, err := store.ListMergeRequests(state.MergeQuery{ProjectPath: cwd})
+	if err != nil || len(requests) != 1 || requests[0].Status != state.MergeStatusMerged {
+		t.Fatalf("requests = %+v, %v", requests, err)
+	}
+	if requests[0].MergedCommit != git(repo, "rev-parse", "main") {
+		t.Errorf("merged commit = %s", requests[0].MergedCommit)
+	}
+
+	cmd = newMergeQueueCmd()
+	cmd.SetArgs([]string{"remove", strconv.FormatInt(requests[0].ID, 10)})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("merge-queue remove: %v", err)
+	}
+	if requests, err := store.ListMergeRequests(state.MergeQuery{ProjectPath: cwd}); err != nil || len(requests) != 0 {
+		t.Errorf("requests after remove = %+v, %v", requests, err)
+	}
+	cmd = newMergeQueueCmd()
+	cmd.SetArgs([]string{"remove", "abc"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("remove with an invalid ID succeeded")
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/summary"
 	"github.com/Dicklesworthstone/ntm/internal/supervisor"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/transcript"
 	"github.com/Dicklesworthstone/ntm/internal/webhook"
 )
 
@@ -96,12 +97,19 @@ func runMonitor(session string) error {
 	// Load plugins to populate config
 	configDir := filepath.Dir(config.DefaultPath())
 	pluginsDir := filepath.Join(configDir, "agents")
+	pluginMap := make(map[string]plugins.AgentPlugin)
 	if loadedPlugins, err := plugins.LoadAgentPlugins(pluginsDir); err == nil {
 		if cfg.Agents.Plugins == nil {
 			cfg.Agents.Plugins = make(map[string]string)
 		}
 		for _, p := range loadedPlugins {
-			cfg.Agents.Plugins[p.Name] = p.Command
+			pluginMap[p.Name] = p
+			// Plugins may restart with a different command (e.g. --resume)
+			command := p.Command
+			if p.RestartCommand != "" {
+				command = p.RestartCommand
+			}
+			cfg.Agents.Plugins[p.Name] = command
 		}
 	}
 
@@ -110,9 +118,30 @@ func runMonitor(session string) error {
 
 	// Register agents
 	for _, agent := range manifest.Agents {
-		monitor.RegisterAgent(agent.PaneID, agent.PaneIndex, 0, agent.Type, agent.Model, agent.Command)
+		command := agent.Command
+		if p, ok := pluginMap[agent.Type]; ok && p.RestartCommand != "" {
+			restart, err := config.GenerateAgentCommand(p.RestartCommand, config.AgentTemplateVars{
+				Model:       agent.Model,
+				SessionName: session,
+				PaneIndex:   agent.PaneIndex,
+				AgentType:   agent.Type,
+				ProjectDir:  manifest.ProjectDir,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid restart_command for plugin %s: %v\n", p.Name, err)
+			} else {
+				command = restart
+			}
+		}
+		monitor.RegisterAgent(agent.PaneID, agent.PaneIndex, 0, agent.Type, agent.Model, command)
+		monitor.SetNativeSession(agent.PaneID, agent.NativeSessionID, agent.Cwd)
 	}
 
+	// Track each agent's native session so restarts resume its conversation
+	ingester := transcript.NewIngester(nil, nil)
+	lastOutputs := make(map[string]string)
+	syncNativeSessions(session, manifest, monitor, ingester, lastOutputs)
+
 	// Start monitoring
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -145,7 +174,6 @@ func runMonitor(session string) error {
 	// Snapshot output periodically to generate summary on exit
 	snapshotTicker := time.NewTicker(30 * time.Second)
 	defer snapshotTicker.Stop()
-	lastOutputs := make(map[string]string)
 
 	fmt.Printf("Monitoring session '%s' for resilience...\n", session)
 
@@ -177,6 +205,52 @@ func runMonitor(session string) error {
 			}
 		case <-snapshotTicker.C:
 			captureSessionOutputs(session, lastOutputs)
+			syncNativeSessions(session, manifest, monitor, ingester, lastOutputs)
+		}
+	}
+}
+
+// syncNativeSessions records each agent's native session ID in the manifest
+// and the monitor. IDs come from the agent's transcript, or from the resume
+// hint a CLI prints when it exits.
+func syncNativeSessions(session string, manifest *resilience.SpawnManifest, monitor *resilience.Monitor, ingester *transcript.Ingester, lastOutputs map[string]string) {
+	panes, err := transcript.PanesFromTmux(context.Background(), session)
+	if err != nil {
+		return
+	}
+	for i := range panes {
+		panes[i].Dir = manifest.ProjectDir
+	}
+
+	changed := false
+	matched := make(map[string]bool)
+	for _, r := range ingester.Sync(session, panes) {
+		if r.Snapshot.SessionID == "" {
+			continue
+		}
+		matched[r.PaneID] = true
+		if manifest.SetNativeSession(r.PaneID, r.Snapshot.SessionID, r.Snapshot.Cwd) {
+			changed = true
+		}
+		monitor.SetNativeSession(r.PaneID, r.Snapshot.SessionID, r.Snapshot.Cwd)
+	}
+	for _, p := range panes {
+		if matched[p.ID] {
+			continue
+		}
+		id := transcript.SessionIDFromOutput(p.AgentType, lastOutputs[p.ID])
+		if id == "" {
+			continue
+		}
+		if manifest.SetNativeSession(p.ID, id, "") {
+			changed = true
+		}
+		monitor.SetNativeSession(p.ID, id, "")
+	}
+
+	if changed {
+		if err := resilience.SaveManifest(manifest); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save native session IDs: %v\n", err)
 		}
 	}
 }
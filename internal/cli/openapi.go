@@ -20,6 +20,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "OpenAPIGenerateInput",
 			Ref:  "cli.OpenAPIGenerateInput",
+			Type: OpenAPIGenerateInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "OpenAPIGenerateResponse",
@@ -45,10 +46,10 @@ func init() {
 
 // OpenAPIGenerateInput holds input parameters for OpenAPI generation.
 type OpenAPIGenerateInput struct {
-	Output    string `json:"output"`
-	Version   string `json:"version"`
-	ServerURL string `json:"server_url"`
-	Stdout    bool   `json:"stdout"`
+	Output    string `json:"output,omitempty"`
+	Version   string `json:"version,omitempty"`
+	ServerURL string `json:"server_url,omitempty"`
+	Stdout    bool   `json:"stdout,omitempty"`
 }
 
 // OpenAPIGenerateResponse holds the result of OpenAPI generation.
@@ -797,30 +797,34 @@ Examples:
 // Helper functions for human-friendly output
 
 func printProgressEvent(event pipeline.ProgressEvent) {
+	// Sub-workflow events are indented under the step that invoked them
+	indent := strings.Repeat("    ", event.Depth)
 	switch event.Type {
 	case "workflow_start":
-		fmt.Printf("📋 %s\n", event.Message)
+		fmt.Printf("%s📋 %s\n", indent, event.Message)
 	case "workflow_complete":
-		fmt.Printf("✅ %s\n", event.Message)
+		fmt.Printf("%s✅ %s\n", indent, event.Message)
 	case "workflow_error":
-		fmt.Printf("❌ %s\n", event.Message)
+		fmt.Printf("%s❌ %s\n", indent, event.Message)
 	case "step_start":
-		fmt.Printf("  ▶ [%s] %s\n", event.StepID, event.Message)
+		fmt.Printf("%s  ▶ [%s] %s\n", indent, event.StepID, event.Message)
 	case "step_complete":
-		fmt.Printf("  ✓ [%s] %s\n", event.StepID, event.Message)
+		fmt.Printf("%s  ✓ [%s] %s\n", indent, event.StepID, event.Message)
 	case "step_error":
-		fmt.Printf("  ✗ [%s] %s\n", event.StepID, event.Message)
+		fmt.Printf("%s  ✗ [%s] %s\n", indent, event.StepID, event.Message)
 	case "step_skip":
-		fmt.Printf("  ⊘ [%s] %s\n", event.StepID, event.Message)
+		fmt.Printf("%s  ⊘ [%s] %s\n", indent, event.StepID, event.Message)
 	case "step_retry":
-		fmt.Printf("  ↻ [%s] %s\n", event.StepID, event.Message)
-	case "parallel_start":
-		fmt.Printf("  ⫘ [%s] %s\n", event.StepID, event.Message)
+		fmt.Printf("%s  ↻ [%s] %s\n", indent, event.StepID, event.Message)
+	case "parallel_start", "matrix_start":
+		fmt.Printf("%s  ⫘ [%s] %s\n", indent, event.StepID, event.Message)
+	case "gate_waiting":
+		fmt.Printf("%s  ⏸ [%s] %s\n", indent, event.StepID, event.Message)
 	default:
 		if event.StepID != "" {
-			fmt.Printf("  • [%s] %s\n", event.StepID, event.Message)
+			fmt.Printf("%s  • [%s] %s\n", indent, event.StepID, event.Message)
 		} else {
-			fmt.Printf("• %s\n", event.Message)
+			fmt.Printf("%s• %s\n", indent, event.Message)
 		}
 	}
 }
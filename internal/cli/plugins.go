@@ -4,14 +4,34 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/output"
 	"github.com/Dicklesworthstone/ntm/internal/plugins"
 )
 
+var registerAgentPluginsOnce sync.Once
+
+// registerAgentPlugins installs the detection patterns, context settings and
+// rate-limit phrases of every agent plugin so plugin panes are tracked like
+// the built-in agents. It runs at most once per process.
+func registerAgentPlugins() {
+	registerAgentPluginsOnce.Do(func() {
+		agentsDir := filepath.Join(filepath.Dir(config.DefaultPath()), "agents")
+		agentPlugins, err := plugins.LoadAgentPlugins(agentsDir)
+		if err != nil {
+			return
+		}
+		if err := plugins.RegisterAgentPlugins(agentPlugins); err != nil {
+			output.PrintWarningf("agent plugin registration: %v", err)
+		}
+	})
+}
+
 func newPluginsCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "plugins",
@@ -46,13 +66,17 @@ func newPluginsListCmd() *cobra.Command {
 
 			if len(agentPlugins) > 0 {
 				fmt.Println("Agent Plugins:")
-				fmt.Fprintln(w, "NAME\tALIAS\tDESCRIPTION")
+				fmt.Fprintln(w, "NAME\tALIAS\tDETECTION\tDESCRIPTION")
 				for _, p := range agentPlugins {
 					alias := p.Alias
 					if alias == "" {
 						alias = "-"
 					}
-					fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, alias, p.Description)
+					detection := "-"
+					if p.Detection.HasPatterns() {
+						detection = "custom"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Name, alias, detection, p.Description)
 				}
 				w.Flush()
 				fmt.Println()
@@ -68,6 +68,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "PreflightInput",
 			Ref:  "cli.PreflightInput",
+			Type: PreflightInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "PreflightResult",
@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/queue"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// QueueSendResult is the JSON output of 'ntm send --queue'.
+type QueueSendResult struct {
+	Success   bool                 `json:"success"`
+	Session   string               `json:"session"`
+	Queued    []state.QueuedPrompt `json:"queued"`
+	Redaction *RedactionSummary    `json:"redaction,omitempty"`
+	Warnings  []string             `json:"warnings,omitempty"`
+}
+
+func newQueueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Manage prompts queued until their agent is idle",
+		Long: `Manage per-pane prompt queues.
+
+'ntm send --queue' appends a prompt to each target pane's queue instead of
+typing it immediately. Queued prompts are delivered in order, one at a time,
+whenever the pane's agent is detected idle. Delivery happens while
+'ntm serve', the dashboard, or 'ntm queue dispatch' is running.
+
+Examples:
+  ntm send proj --cc --queue "Now add tests for that"
+  ntm queue list proj
+  ntm queue reorder 42 1
+  ntm queue clear proj --pane 2
+  ntm queue dispatch`,
+	}
+
+	cmd.AddCommand(
+		newQueueListCmd(),
+		newQueueClearCmd(),
+		newQueueReorderCmd(),
+		newQueueDispatchCmd(),
+	)
+	return cmd
+}
+
+func newQueueListCmd() *cobra.Command {
+	var (
+		pane string
+		all  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list [session]",
+		Short: "List queued prompts",
+		Long: `List pending prompts, in delivery order. Without a session, lists the
+queues of every session. Use --all to include delivered and failed prompts.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q := state.QueueQuery{Pane: pane}
+			if len(args) > 0 {
+				q.SessionID = args[0]
+			}
+			if !all {
+				q.Statuses = []state.QueueStatus{state.QueueStatusPending, state.QueueStatusDelivering}
+			}
+			return runQueueList(q)
+		},
+	}
+	cmd.Flags().StringVar(&pane, "pane", "", "Filter by pane index or ID")
+	cmd.Flags().BoolVar(&all, "all", false, "Include delivered and failed prompts")
+	cmd.ValidArgsFunction = completeSessionArgs
+	return cmd
+}
+
+func runQueueList(q state.QueueQuery) error {
+	store, err := openScheduleStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	prompts, err := store.ListQueuedPrompts(q)
+	if err != nil {
+		return err
+	}
+	if IsJSONOutput() {
+		if prompts == nil {
+			prompts = []state.QueuedPrompt{}
+		}
+		return output.PrintJSON(prompts)
+	}
+	if len(prompts) == 0 {
+		fmt.Println("No queued prompts.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSESSION\tPANE\tPOS\tSTATUS\tQUEUED\tWAITED\tPROMPT")
+	for _, p := range prompts {
+		pos := strconv.Itoa(p.Position)
+		if p.Status != state.QueueStatusPending {
+			pos = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			p.ID, p.SessionID, p.PaneIndex, pos, p.Status,
+			p.EnqueuedAt.Local().Format("2006-01-02 15:04"), queueDeliveryAge(p), truncatePrompt(p.Prompt, 40))
+	}
+	return w.Flush()
+}
+
+func newQueueClearCmd() *cobra.Command {
+	var pane string
+
+	cmd := &cobra.Command{
+		Use:   "clear <session>",
+		Short: "Remove pending prompts from a session's queues",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			n, err := store.ClearQueuedPrompts(args[0], pane)
+			if err != nil {
+				return err
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(map[string]interface{}{"success": true, "session": args[0], "cleared": n})
+			}
+			fmt.Printf("Cleared %d queued prompt(s)\n", n)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&pane, "pane", "", "Only clear this pane's queue (index or ID)")
+	cmd.ValidArgsFunction = completeSessionArgs
+	return cmd
+}
+
+func newQueueReorderCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reorder <id> <position>",
+		Short: "Move a queued prompt to a new position in its pane's queue",
+		Long: `Move a pending prompt within its pane's queue. Position 1 is delivered
+next; positions past the end move the prompt to the back.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid queue id %q", args[0])
+			}
+			position, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid position %q", args[1])
+			}
+
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			if err := store.ReorderQueuedPrompt(id, position); err != nil {
+				return err
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(map[string]interface{}{"success": true, "id": id, "position": position})
+			}
+			fmt.Printf("Moved queued prompt %d to position %d\n", id, position)
+			return nil
+		},
+	}
+}
+
+func newQueueDispatchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "dispatch",
+		Short: "Deliver queued prompts in the foreground",
+		Long: `Watch every session with queued prompts and deliver each pane's next
+prompt when its agent goes idle. Runs until interrupted.
+
+Not needed while 'ntm serve' or the dashboard is running.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+
+			fmt.Printf("Queue dispatcher running (poll every %s). Press Ctrl+C to stop.\n", queue.DefaultPollInterval)
+			queue.NewDispatcher(store).Run(ctx)
+			return nil
+		},
+	}
+}
+
+// runQueueSend appends prompt to the queue of each selected pane.
+func runQueueSend(session string, panes []tmux.Pane, prompt, templateName string, redactionSummary *RedactionSummary, warnings []string) error {
+	store, err := openScheduleStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	queued, err := queue.Enqueue(store, session, panes, prompt, queue.SourceCLI, templateName)
+	if err != nil {
+		return err
+	}
+
+	if IsJSONOutput() {
+		return output.PrintJSON(QueueSendResult{
+			Success:   true,
+			Session:   session,
+			Queued:    queued,
+			Redaction: redactionSummary,
+			Warnings:  warnings,
+		})
+	}
+	for _, p := range queued {
+		fmt.Printf("Queued for pane %d (position %d, id %d)\n", p.PaneIndex, p.Position, p.ID)
+	}
+	fmt.Println("Prompts are delivered when each agent is idle, while 'ntm serve', the dashboard, or 'ntm queue dispatch' is running.")
+	return nil
+}
+
+// queueDeliveryAge formats how long a prompt waited before delivery.
+func queueDeliveryAge(p state.QueuedPrompt) string {
+	if p.DeliveredAt == nil {
+		return "-"
+	}
+	return p.DeliveredAt.Sub(p.EnqueuedAt).Round(time.Second).String()
+}
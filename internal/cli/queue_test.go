@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/queue"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func TestQueueReorderAndClear(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	store, err := openScheduleStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	panes := []tmux.Pane{{ID: "%1", Index: 1}, {ID: "%2", Index: 2}}
+	first, err := queue.Enqueue(store, "proj", panes, "first", queue.SourceCLI, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := queue.Enqueue(store, "proj", panes[:1], "second", queue.SourceCLI, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newQueueCmd()
+	cmd.SetArgs([]string{"reorder", strconv.FormatInt(second[0].ID, 10), "1"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("queue reorder: %v", err)
+	}
+	next, err := store.NextQueuedPrompt("proj", "%1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == nil || next.ID != second[0].ID {
+		t.Fatalf("next = %+v, want prompt %d", next, second[0].ID)
+	}
+
+	cmd = newQueueCmd()
+	cmd.SetArgs([]string{"clear", "proj", "--pane", "1"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("queue clear: %v", err)
+	}
+	prompts, err := store.ListQueuedPrompts(state.QueueQuery{SessionID: "proj"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prompts) != 1 || prompts[0].ID != first[1].ID {
+		t.Errorf("remaining = %+v, want only pane 2's prompt", prompts)
+	}
+
+	cmd = newQueueCmd()
+	cmd.SetArgs([]string{"reorder", "abc", "1"})
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	if err := cmd.Execute(); err == nil {
+		t.Error("reorder with invalid id succeeded")
+	}
+}
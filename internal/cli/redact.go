@@ -2,9 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -37,13 +39,16 @@ func newRedactCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "redact",
 		Short: "Redaction utilities",
-		Long: `Redaction utilities for previewing and debugging secret detection.
+		Long: `Redaction utilities for previewing and debugging secret detection,
+and for validating and benchmarking rule packs.
 
 These commands NEVER print raw matched secrets. Output is always safe-redacted.`,
 	}
 
 	cmd.AddCommand(
 		newRedactPreviewCmd(),
+		newRedactValidateCmd(),
+		newRedactBenchCmd(),
 	)
 
 	return cmd
@@ -168,3 +173,276 @@ Examples:
 
 	return cmd
 }
+
+type RedactPackReport struct {
+	Path   string   `json:"path"`
+	ID     string   `json:"id,omitempty"`
+	Rules  int      `json:"rules"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"` // never includes example text
+}
+
+type RedactValidateResponse struct {
+	output.TimestampedResponse
+
+	Valid bool               `json:"valid"`
+	Packs []RedactPackReport `json:"packs"`
+}
+
+func newRedactValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [pack.toml...]",
+		Short: "Validate redaction rule packs",
+		Long: `Parse and compile redaction rule packs and check every rule against its
+examples: each example must be detected and no negative example may be.
+
+Without arguments, validates the packs listed in [redaction] rule_packs.
+
+Examples:
+  ntm redact validate
+  ntm redact validate ./acme.toml
+  ntm redact validate "~/.config/ntm/redaction/*.toml" --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files, err := redactPackFiles(args)
+			if err != nil {
+				return err
+			}
+
+			resp := RedactValidateResponse{
+				TimestampedResponse: output.NewTimestamped(),
+				Valid:               true,
+				Packs:               make([]RedactPackReport, 0, len(files)),
+			}
+			for _, f := range files {
+				report := RedactPackReport{Path: f, Valid: true}
+				pack, err := redaction.LoadRulePack(f)
+				if err != nil {
+					report.Errors = append(report.Errors, err.Error())
+				} else {
+					report.ID = pack.ID
+					report.Rules = len(pack.Rules)
+					for _, e := range pack.Test() {
+						report.Errors = append(report.Errors, e.Error())
+					}
+				}
+				if len(report.Errors) > 0 {
+					report.Valid = false
+					resp.Valid = false
+				}
+				resp.Packs = append(resp.Packs, report)
+			}
+
+			if IsJSONOutput() {
+				return output.PrintJSON(resp)
+			}
+
+			failed := 0
+			for _, p := range resp.Packs {
+				if p.Valid {
+					fmt.Printf("✓ %s (%s, %d rules)\n", p.Path, p.ID, p.Rules)
+					continue
+				}
+				failed++
+				fmt.Printf("✗ %s\n", p.Path)
+				for _, e := range p.Errors {
+					fmt.Printf("  - %s\n", e)
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d rule packs invalid", failed, len(resp.Packs))
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+type RedactBenchRule struct {
+	Pack        string             `json:"pack"`
+	Rule        string             `json:"rule"`
+	Category    redaction.Category `json:"category"`
+	Matches     int                `json:"matches"`
+	TotalMs     float64            `json:"total_ms"`
+	MBPerSecond float64            `json:"mb_per_second"`
+}
+
+type RedactBenchResponse struct {
+	output.TimestampedResponse
+
+	Fixtures    int               `json:"fixtures"`
+	Bytes       int               `json:"bytes"`
+	Iterations  int               `json:"iterations"`
+	Rules       []RedactBenchRule `json:"rules"`
+	Findings    int               `json:"findings"` // full scan, per pass
+	ScanMs      float64           `json:"scan_ms"`
+	MBPerSecond float64           `json:"mb_per_second"`
+}
+
+func newRedactBenchCmd() *cobra.Command {
+	var (
+		fixtures   []string
+		iterations int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bench [pack.toml...]",
+		Short: "Benchmark redaction rule packs against fixtures",
+		Long: `Run every rule of the given rule packs over fixture files and report
+matches and time per rule, plus full-scan throughput with the configured
+detectors and the packs combined.
+
+--fixtures accepts files or directories (read recursively). Without fixtures,
+the packs' own examples are used. Without pack arguments, the packs listed in
+[redaction] rule_packs are benchmarked.
+
+Examples:
+  ntm redact bench ./acme.toml --fixtures ./testdata/logs
+  ntm redact bench --fixtures session.log --iterations 100 --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			currentFixtures := fixtures
+			currentIterations := iterations
+			fixtures = nil
+			iterations = 10
+
+			files, err := redactPackFiles(args)
+			if err != nil {
+				return err
+			}
+			packs := make([]*redaction.RulePack, 0, len(files))
+			for _, f := range files {
+				pack, err := redaction.LoadRulePack(f)
+				if err != nil {
+					return err
+				}
+				packs = append(packs, pack)
+			}
+
+			inputs, err := readRedactFixtures(currentFixtures)
+			if err != nil {
+				return err
+			}
+			if len(currentFixtures) == 0 {
+				for _, p := range packs {
+					for _, r := range p.Rules {
+						inputs = append(inputs, r.Examples...)
+						inputs = append(inputs, r.NegativeExamples...)
+					}
+				}
+			}
+			if len(inputs) == 0 {
+				return fmt.Errorf("no fixtures: pass --fixtures or add examples to the rule packs")
+			}
+
+			if cfg == nil {
+				cfg = config.Default()
+			}
+			libCfg := cfg.Redaction.ToRedactionLibConfig()
+			libCfg.RulePacks = nil // Benchmark the given packs only
+
+			res := redaction.BenchmarkRulePacks(libCfg, packs, inputs, currentIterations)
+			processed := float64(res.Bytes) * float64(res.Iterations) / 1e6
+			resp := RedactBenchResponse{
+				TimestampedResponse: output.NewTimestamped(),
+				Fixtures:            len(inputs),
+				Bytes:               res.Bytes,
+				Iterations:          res.Iterations,
+				Rules:               make([]RedactBenchRule, 0, len(res.Rules)),
+				Findings:            res.Findings,
+				ScanMs:              durationMs(res.ScanDuration),
+				MBPerSecond:         res.MBPerSecond(),
+			}
+			for _, r := range res.Rules {
+				rule := RedactBenchRule{
+					Pack:     r.Pack,
+					Rule:     r.Rule,
+					Category: r.Category,
+					Matches:  r.Matches,
+					TotalMs:  durationMs(r.Duration),
+				}
+				if r.Duration > 0 {
+					rule.MBPerSecond = processed / r.Duration.Seconds()
+				}
+				resp.Rules = append(resp.Rules, rule)
+			}
+
+			if IsJSONOutput() {
+				return output.PrintJSON(resp)
+			}
+
+			fmt.Printf("Fixtures: %d (%d bytes) × %d iterations\n\n", resp.Fixtures, resp.Bytes, resp.Iterations)
+			fmt.Printf("%-20s %-28s %-24s %8s %10s %10s\n", "PACK", "RULE", "CATEGORY", "MATCHES", "TIME", "MB/s")
+			for _, r := range resp.Rules {
+				fmt.Printf("%-20s %-28s %-24s %8d %9.2fms %10.1f\n", r.Pack, r.Rule, r.Category, r.Matches, r.TotalMs, r.MBPerSecond)
+			}
+			fmt.Printf("\nFull scan: %d findings per pass, %.2fms, %.1f MB/s\n", resp.Findings, resp.ScanMs, resp.MBPerSecond)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&fixtures, "fixtures", nil, "Fixture files or directories to scan (default: pack examples)")
+	cmd.Flags().IntVar(&iterations, "iterations", 10, "Passes over the fixtures")
+
+	return cmd
+}
+
+// redactPackFiles resolves rule pack arguments (globs allowed) to files,
+// falling back to the configured rule_packs.
+func redactPackFiles(args []string) ([]string, error) {
+	patterns := make([]string, 0, len(args))
+	for _, a := range args {
+		patterns = append(patterns, util.ExpandPath(a))
+	}
+	if len(patterns) == 0 {
+		if cfg == nil {
+			cfg = config.Default()
+		}
+		patterns = cfg.Redaction.RulePackPaths()
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no rule packs: pass pack files or set [redaction] rule_packs")
+	}
+
+	var files []string
+	for _, p := range patterns {
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("rule pack pattern %q: %w", p, err)
+		}
+		if len(matches) == 0 {
+			// Keep literal paths so the missing file is reported per pack.
+			matches = []string{p}
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// readRedactFixtures reads fixture files, walking directories recursively.
+func readRedactFixtures(paths []string) ([]string, error) {
+	var inputs []string
+	for _, p := range paths {
+		root := util.ExpandPath(p)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			inputs = append(inputs, string(data))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("read fixtures: %w", err)
+		}
+	}
+	return inputs, nil
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
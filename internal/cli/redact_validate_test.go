@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testRulePack = `id = "acme"
+
+[[rules]]
+id = "acme-deploy-key"
+regex = 'acme_live_[A-Za-z0-9]{24}'
+examples = ["acme_live_Q3m9Vx2Lk7Pw4Rt8Yb1Nz6Hc"]
+negative_examples = ["acme_test_Q3m9Vx2Lk7Pw4Rt8Yb1Nz6Hc"]
+`
+
+func writeRulePack(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+func TestRedactValidate_ReportsPerPack(t *testing.T) {
+	resetFlags()
+
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	oldCfg := cfg
+	cfg = nil
+	t.Cleanup(func() { cfg = oldCfg })
+
+	good := writeRulePack(t, tmpDir, "good.toml", testRulePack)
+	bad := writeRulePack(t, tmpDir, "bad.toml", strings.Replace(testRulePack, "acme_live_Q3m9", "nope_live_Q3m9", 1))
+
+	out, err := captureStdout(t, func() error {
+		rootCmd.SetArgs([]string{"redact", "validate", good, bad, "--json"})
+		return rootCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("redact validate failed: %v\noutput:\n%s", err, out)
+	}
+	if strings.Contains(out, "Q3m9Vx2Lk7Pw4Rt8Yb1Nz6Hc") {
+		t.Fatalf("expected output to never include example text, got:\n%s", out)
+	}
+
+	var resp RedactValidateResponse
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		t.Fatalf("failed to parse JSON: %v\noutput:\n%s", err, out)
+	}
+	if resp.Valid || len(resp.Packs) != 2 {
+		t.Fatalf("expected 2 packs and valid=false, got %+v", resp)
+	}
+	if !resp.Packs[0].Valid || resp.Packs[0].ID != "acme" || resp.Packs[0].Rules != 1 {
+		t.Errorf("good pack report = %+v", resp.Packs[0])
+	}
+	if resp.Packs[1].Valid || len(resp.Packs[1].Errors) != 1 {
+		t.Errorf("bad pack report = %+v", resp.Packs[1])
+	}
+}
+
+func TestRedactValidate_TextOutputFailsOnInvalidPack(t *testing.T) {
+	resetFlags()
+
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	oldCfg := cfg
+	cfg = nil
+	t.Cleanup(func() { cfg = oldCfg })
+
+	bad := writeRulePack(t, tmpDir, "bad.toml", "id = \"broken\"\n")
+
+	out, err := captureStdout(t, func() error {
+		rootCmd.SetArgs([]string{"redact", "validate", bad})
+		return rootCmd.Execute()
+	})
+	if err == nil {
+		t.Fatalf("expected error for invalid pack, output:\n%s", out)
+	}
+	if !strings.Contains(out, "no rules") {
+		t.Fatalf("expected pack error in output, got:\n%s", out)
+	}
+}
+
+func TestRedactBench_JSONOutput(t *testing.T) {
+	resetFlags()
+
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	oldCfg := cfg
+	cfg = nil
+	t.Cleanup(func() { cfg = oldCfg })
+
+	pack := writeRulePack(t, tmpDir, "acme.toml", testRulePack)
+	fixtures := filepath.Join(tmpDir, "fixtures")
+	if err := os.MkdirAll(filepath.Join(fixtures, "nested"), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	writeRulePack(t, fixtures, "a.log", "deploy acme_live_Z9y8X7w6V5u4T3s2R1q0P9o8\n")
+	writeRulePack(t, filepath.Join(fixtures, "nested"), "b.log", "nothing here\n")
+
+	out, err := captureStdout(t, func() error {
+		rootCmd.SetArgs([]string{"redact", "bench", pack, "--fixtures", fixtures, "--iterations", "2", "--json"})
+		return rootCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("redact bench failed: %v\noutput:\n%s", err, out)
+	}
+	if strings.Contains(out, "Z9y8X7w6V5u4T3s2R1q0P9o8") {
+		t.Fatalf("expected output to never include matches, got:\n%s", out)
+	}
+
+	var resp RedactBenchResponse
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		t.Fatalf("failed to parse JSON: %v\noutput:\n%s", err, out)
+	}
+	if resp.Fixtures != 2 || resp.Iterations != 2 {
+		t.Fatalf("expected 2 fixtures x 2 iterations, got %+v", resp)
+	}
+	if len(resp.Rules) != 1 || resp.Rules[0].Matches != 1 || resp.Rules[0].Category != "ACME_DEPLOY_KEY" {
+		t.Fatalf("unexpected rule results: %+v", resp.Rules)
+	}
+	if resp.Findings != 1 {
+		t.Fatalf("expected 1 full-scan finding, got %d", resp.Findings)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -311,7 +310,7 @@ func spawnWithHandoff(cmd *cobra.Command, sessionName string, h *handoff.Handoff
 	}
 
 	// Format context for injection
-	contextText := formatHandoffContext(h)
+	contextText := h.ResumeContext()
 
 	// Check if session already exists
 	if tmux.SessionExists(sessionName) {
@@ -411,7 +410,7 @@ func injectHandoff(cmd *cobra.Command, sessionName string, h *handoff.Handoff,
 	}
 
 	// Format context
-	contextText := formatHandoffContext(h)
+	contextText := h.ResumeContext()
 
 	// Get panes
 	panes, err := tmux.GetPanes(sessionName)
@@ -468,66 +467,6 @@ func injectHandoff(cmd *cobra.Command, sessionName string, h *handoff.Handoff,
 	return nil
 }
 
-// formatHandoffContext formats a handoff for injection into an agent context.
-func formatHandoffContext(h *handoff.Handoff) string {
-	var sb strings.Builder
-
-	sb.WriteString("=== Resuming from Previous Session ===\n\n")
-	sb.WriteString(fmt.Sprintf("**Goal (previous session):** %s\n\n", h.Goal))
-	sb.WriteString(fmt.Sprintf("**Now (your first task):** %s\n\n", h.Now))
-
-	if len(h.Decisions) > 0 {
-		sb.WriteString("**Key Decisions Made:**\n")
-		for k, v := range h.Decisions {
-			sb.WriteString(fmt.Sprintf("- %s: %s\n", k, v))
-		}
-		sb.WriteString("\n")
-	}
-
-	if len(h.Next) > 0 {
-		sb.WriteString("**Next Steps:**\n")
-		for i, step := range h.Next {
-			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
-		}
-		sb.WriteString("\n")
-	}
-
-	if len(h.Blockers) > 0 {
-		sb.WriteString("**Blockers to Address:**\n")
-		for _, b := range h.Blockers {
-			sb.WriteString(fmt.Sprintf("- %s\n", b))
-		}
-		sb.WriteString("\n")
-	}
-
-	if len(h.Findings) > 0 {
-		sb.WriteString("**Important Findings:**\n")
-		for k, v := range h.Findings {
-			sb.WriteString(fmt.Sprintf("- %s: %s\n", k, v))
-		}
-		sb.WriteString("\n")
-	}
-
-	if h.HasChanges() {
-		sb.WriteString(fmt.Sprintf("**Files Changed:** %d files\n", h.TotalFileChanges()))
-		if len(h.Files.Created) > 0 {
-			sb.WriteString(fmt.Sprintf("  Created: %s\n", strings.Join(h.Files.Created, ", ")))
-		}
-		if len(h.Files.Modified) > 0 {
-			sb.WriteString(fmt.Sprintf("  Modified: %s\n", strings.Join(h.Files.Modified, ", ")))
-		}
-		sb.WriteString("\n")
-	}
-
-	if h.Test != "" {
-		sb.WriteString(fmt.Sprintf("**Test Command:** %s\n\n", h.Test))
-	}
-
-	sb.WriteString("Please continue from where the previous session left off.\n")
-
-	return sb.String()
-}
-
 // humanizeDuration returns a human-readable duration string.
 func humanizeDuration(d time.Duration) string {
 	if d < time.Minute {
@@ -121,7 +121,7 @@ func showRollbackPreview(cp *checkpoint.Checkpoint, workDir string, noStash, noG
 			if !noStash {
 				actions = append(actions, "stash_current_changes")
 			}
-			if cp.Git.PatchFile != "" {
+			if cp.HasGitPatch() {
 				actions = append(actions, "apply_git_patch")
 			}
 			actions = append(actions, fmt.Sprintf("checkout_commit_%s", cp.Git.Commit[:min(8, len(cp.Git.Commit))]))
@@ -160,7 +160,7 @@ func showRollbackPreview(cp *checkpoint.Checkpoint, workDir string, noStash, noG
 			fmt.Printf("    %d. Checkout commit %s\n", actionNum, cp.Git.Commit[:min(8, len(cp.Git.Commit))])
 			actionNum++
 
-			if cp.Git.PatchFile != "" && cp.Git.IsDirty {
+			if cp.HasGitPatch() && cp.Git.IsDirty {
 				fmt.Printf("    %d. Apply saved patch (%d staged, %d unstaged changes)\n",
 					actionNum, cp.Git.StagedCount, cp.Git.UnstagedCount)
 				actionNum++
@@ -220,9 +220,9 @@ func performRollback(cp *checkpoint.Checkpoint, workDir string, noStash, noGit b
 		}
 
 		// Apply patch if available
-		if cp.Git.PatchFile != "" && cp.Git.IsDirty {
+		if cp.HasGitPatch() && cp.Git.IsDirty {
 			storage := checkpoint.NewStorage()
-			patch, err := storage.LoadGitPatch(cp.SessionName, cp.ID)
+			patch, err := storage.ReadGitPatch(cp)
 			if err == nil && patch != "" {
 				if err := gitApplyPatch(workDir, patch); err != nil {
 					if !jsonOutput {
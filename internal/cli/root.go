@@ -102,6 +102,7 @@ Shell Integration:
 		// Check if this command can skip config loading (Phase 1 only)
 		// This includes subcommands AND robot flags that don't need config
 		if canSkipConfigLoading(cmd.Name()) {
+			startCommandTrace(cmd, args)
 			startCommandAudit(cmd, args)
 			return nil
 		}
@@ -140,7 +141,7 @@ Shell Integration:
 				checkpoint.SetRedactionConfig(&redactCfg)
 			}
 
-			// Wire encryption into history + event log persistence (bd-3ld77)
+			// Wire encryption into history, event log, checkpoint and saved session persistence (bd-3ld77)
 			if cfg != nil && cfg.Encryption.Enabled {
 				keyCfg := encryption.KeyConfig{
 					KeySource:   cfg.Encryption.KeySource,
@@ -169,6 +170,16 @@ Shell Integration:
 							EncryptKey:  encKey,
 							DecryptKeys: allKeys,
 						})
+						checkpoint.SetEncryptionConfig(&checkpoint.EncryptionConfig{
+							Enabled:     true,
+							EncryptKey:  encKey,
+							DecryptKeys: allKeys,
+						})
+						session.SetEncryptionConfig(&session.EncryptionConfig{
+							Enabled:     true,
+							EncryptKey:  encKey,
+							DecryptKeys: allKeys,
+						})
 					}
 				}
 			}
@@ -179,7 +190,11 @@ Shell Integration:
 				cfg.Cleanup.MaxAgeHours,
 				cfg.Cleanup.Verbose,
 			)
+
+			// Make plugin agents visible to state, context and rate-limit detection
+			registerAgentPlugins()
 		}
+		startCommandTrace(cmd, args)
 		startCommandAudit(cmd, args)
 		return nil
 	},
@@ -731,6 +746,22 @@ Shell Integration:
 			}
 			return
 		}
+		if robotTransitions != "" {
+			opts := robot.TransitionsOptions{
+				Session: robotTransitions,
+				Pane:    robotTransitionsPane,
+				State:   robotTransitionsState,
+				Since:   robotTransitionsSince,
+				Until:   robotTransitionsUntil,
+				Limit:   robotTransitionsLimit,
+				Stats:   robotTransitionsStats,
+			}
+			if err := robot.PrintTransitions(opts); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
 		if robotActivity != "" {
 			// Parse pane filter (reuse --panes flag)
 			var paneFilter []string
@@ -1672,6 +1703,15 @@ Shell Integration:
 			return
 		}
 
+		// Robot-fleet-status handler for multi-host fleet view
+		if robotFleetStatus {
+			if err := robot.PrintFleetStatus(cfg.Fleet); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		// Robot-alerts handler for alert listing (TUI parity)
 		if robotAlerts {
 			opts := robot.TUIAlertsOptions{
@@ -1957,6 +1997,7 @@ Shell Integration:
 func Execute() error {
 	err := rootCmd.Execute()
 	logCommandAuditEnd(err)
+	endCommandTrace(err)
 	_ = audit.CloseAll()
 	if err != nil {
 		// If not in JSON mode, print the error to stderr
@@ -2301,6 +2342,15 @@ var (
 	robotHistorySince string // time-based filter
 	robotHistoryStats bool   // show statistics instead of entries
 
+	// Robot-transitions flags for the agent state-transition log
+	robotTransitions      string // session name for transitions query
+	robotTransitionsPane  string // filter by pane ID or name
+	robotTransitionsState string // filter by from/to state
+	robotTransitionsSince string // start of time range
+	robotTransitionsUntil string // end of time range
+	robotTransitionsLimit int    // most recent N transitions
+	robotTransitionsStats bool   // include dwell-time aggregates
+
 	// Robot-activity flags for agent activity detection
 	robotActivity     string // session name for activity query
 	robotActivityType string // filter by agent type (claude, codex, gemini)
@@ -2357,6 +2407,9 @@ var (
 	robotDiff      string // session name for diff
 	robotDiffSince string // duration like "10m", "1h"
 
+	// Robot-fleet-status flag for the multi-host fleet view
+	robotFleetStatus bool
+
 	// Robot-alerts flags for alert listing
 	robotAlerts         bool   // list alerts
 	robotAlertsSeverity string // filter by severity
@@ -2839,6 +2892,13 @@ func init() {
 	rootCmd.Flags().IntVar(&robotHistoryLast, "history-last", 0, "Show last N entries. Optional with --robot-history. Example: --history-last=10")
 	rootCmd.Flags().StringVar(&robotHistorySince, "history-since", "", "Show entries since time (1h, 30m, 2d, or ISO8601). Optional with --robot-history")
 	rootCmd.Flags().BoolVar(&robotHistoryStats, "history-stats", false, "Show statistics instead of entries. Optional with --robot-history")
+	rootCmd.Flags().StringVar(&robotTransitions, "robot-transitions", "", "Get recorded agent state transitions for a session (JSON). Required: SESSION. Example: ntm --robot-transitions=myproject --transitions-since=1h")
+	rootCmd.Flags().StringVar(&robotTransitionsPane, "transitions-pane", "", "Filter by pane ID or name. Optional with --robot-transitions")
+	rootCmd.Flags().StringVar(&robotTransitionsState, "transitions-state", "", "Filter by from/to state. Optional with --robot-transitions. Example: --transitions-state=error")
+	rootCmd.Flags().StringVar(&robotTransitionsSince, "transitions-since", "", "Start of range (1h, 30m, 2d, or ISO8601). Optional with --robot-transitions")
+	rootCmd.Flags().StringVar(&robotTransitionsUntil, "transitions-until", "", "End of range (1h, 30m, 2d, or ISO8601). Optional with --robot-transitions")
+	rootCmd.Flags().IntVar(&robotTransitionsLimit, "transitions-limit", 0, "Return only the most recent N transitions. Optional with --robot-transitions")
+	rootCmd.Flags().BoolVar(&robotTransitionsStats, "transitions-stats", false, "Include dwell time per state per pane. Optional with --robot-transitions")
 
 	// Robot-activity flags for agent activity detection
 	rootCmd.Flags().StringVar(&robotActivity, "robot-activity", "", "Get agent activity state (idle/busy/error). Required: SESSION. Example: ntm --robot-activity=myproject")
@@ -2905,6 +2965,7 @@ func init() {
 	rootCmd.Flags().StringVar(&robotDiffSince, "diff-since", "15m", "Duration to look back (e.g., 10m, 1h). Optional with --robot-diff. Default: 15m")
 
 	// Robot-alerts flags for alert listing (TUI parity)
+	rootCmd.Flags().BoolVar(&robotFleetStatus, "robot-fleet-status", false, "Get sessions from every ntm serve host in the [fleet] config, grouped by host. Example: ntm --robot-fleet-status")
 	rootCmd.Flags().BoolVar(&robotAlerts, "robot-alerts", false, "List active alerts with filtering. TUI parity for Alerts panel. Example: ntm --robot-alerts --alerts-severity=critical")
 	rootCmd.Flags().StringVar(&robotAlertsSeverity, "alerts-severity", "", "Filter by severity: info, warning, error, critical. Optional with --robot-alerts")
 	rootCmd.Flags().StringVar(&robotAlertsType, "alerts-type", "", "Filter by alert type. Optional with --robot-alerts")
@@ -3288,7 +3349,12 @@ func init() {
 		newInterruptCmd(),
 		newRotateCmd(),
 		newQuotaCmd(),
+		newBudgetCmd(),
+		newCostCmd(),
 		newPipelineCmd(),
+		newScheduleCmd(),
+		newFleetCmd(),
+		newQueueCmd(),
 		newWaitCmd(),
 		newMailCmd(),
 		newPluginsCmd(),
@@ -3327,6 +3393,7 @@ func init() {
 		newCheckpointCmd(),
 		newRollbackCmd(),
 		newSessionPersistCmd(),
+		newRestoreCmd(),
 		newHandoffCmd(),
 		newResumeCmd(),
 
@@ -3350,6 +3417,7 @@ func init() {
 		newSafetyCmd(),
 		newPolicyCmd(),
 		newKernelCmd(),
+		newMCPCmd(),
 		newOpenAPICmd(),
 		newGuardsCmd(),
 		newApproveCmd(),
@@ -3398,6 +3466,7 @@ func init() {
 		newGitCmd(),
 		newRepoCmd(),
 		newWorktreesCmd(),
+		newMergeQueueCmd(),
 
 		// Configuration management
 		newRecipesCmd(),
@@ -3462,6 +3531,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "VersionInput",
 			Ref:  "cli.VersionInput",
+			Type: VersionInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "VersionResponse",
@@ -4134,7 +4204,7 @@ func needsConfigLoading(cmdName string) bool {
 			robotSend != "" || robotAck != "" || robotSpawn != "" ||
 			robotInterrupt != "" || robotRestartPane != "" || robotProbe != "" || robotGraph || robotMail || robotHealth != "" ||
 			robotHealthOAuth != "" || robotHealthRestartStuck != "" || robotLogs != "" || robotDiagnose != "" || robotTerse || robotMarkdown || robotSave != "" || robotRestore != "" ||
-			robotContext != "" || robotEnsemble != "" || robotEnsembleSpawn != "" || robotEnsembleSuggest != "" || robotEnsembleStop != "" || robotAlerts || robotIsWorking != "" || robotAgentHealth != "" ||
+			robotContext != "" || robotEnsemble != "" || robotEnsembleSpawn != "" || robotEnsembleSuggest != "" || robotEnsembleStop != "" || robotAlerts || robotFleetStatus || robotIsWorking != "" || robotAgentHealth != "" ||
 			robotSmartRestart != "" || robotMonitor != "" || robotEnv != "" || robotSupportBundle != "" {
 			return true
 		}
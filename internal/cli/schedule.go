@@ -0,0 +1,340 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/cron"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+func newScheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Send prompts and run pipelines on a cron schedule",
+		Long: `Manage recurring prompts and pipeline runs.
+
+Schedules use standard 5-field cron expressions (minute hour day-of-month
+month day-of-week) evaluated in local time, @every <duration>, or the
+descriptors @hourly, @daily, @weekly, @monthly and @yearly.
+
+Schedules fire while 'ntm serve' or 'ntm schedule daemon' is running. Runs
+that fell due while neither was running are skipped by default; use
+--missed once to catch up with a single run instead.
+
+Examples:
+  ntm schedule add triage --cron "0 9 * * 1-5" --session proj --pane 1 \
+    --send "Triage the new issues"
+  ntm schedule add nightly --cron "0 2 * * *" --session proj --pipeline nightly-review
+  ntm schedule add poll --cron "@every 30m" --session proj --type cc --send "status?"
+  ntm schedule list
+  ntm schedule run-now nightly
+  ntm schedule remove triage
+  ntm schedule daemon`,
+	}
+
+	cmd.AddCommand(
+		newScheduleAddCmd(),
+		newScheduleListCmd(),
+		newScheduleRemoveCmd(),
+		newScheduleRunNowCmd(),
+		newScheduleDaemonCmd(),
+	)
+	return cmd
+}
+
+func newScheduleAddCmd() *cobra.Command {
+	var (
+		cronExpr   string
+		session    string
+		message    string
+		workflow   string
+		panes      []string
+		agentTypes []string
+		all        bool
+		vars       []string
+		missed     string
+		disabled   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a schedule that sends a prompt or runs a pipeline",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (message == "") == (workflow == "") {
+				return errors.New("exactly one of --send or --pipeline is required")
+			}
+
+			sch := &state.Schedule{
+				Name:         args[0],
+				Cron:         cronExpr,
+				SessionID:    session,
+				MissedPolicy: state.MissedRunPolicy(missed),
+				Enabled:      !disabled,
+			}
+			if message != "" {
+				sch.TargetType = state.ScheduleTargetSend
+				sch.Target = state.ScheduleTarget{Message: message, Panes: panes, AgentTypes: agentTypes, All: all}
+			} else {
+				sch.TargetType = state.ScheduleTargetPipeline
+				sch.Target = state.ScheduleTarget{Workflow: workflow}
+				if wd, err := os.Getwd(); err == nil {
+					sch.Target.ProjectDir = wd
+				}
+				if len(vars) > 0 {
+					sch.Target.Vars = make(map[string]interface{}, len(vars))
+					for _, v := range vars {
+						key, value, ok := strings.Cut(v, "=")
+						if !ok {
+							return fmt.Errorf("invalid variable format: %q (expected key=value)", v)
+						}
+						sch.Target.Vars[key] = value
+					}
+				}
+			}
+			return runScheduleAdd(sch)
+		},
+	}
+
+	cmd.Flags().StringVar(&cronExpr, "cron", "", "Cron expression (e.g. \"0 9 * * 1-5\", \"@every 30m\")")
+	cmd.Flags().StringVarP(&session, "session", "s", "", "Target session")
+	cmd.Flags().StringVar(&message, "send", "", "Prompt to send when the schedule fires")
+	cmd.Flags().StringVar(&workflow, "pipeline", "", "Workflow file or name to run when the schedule fires")
+	cmd.Flags().StringSliceVar(&panes, "pane", nil, "Pane indices to send to (repeatable)")
+	cmd.Flags().StringSliceVar(&agentTypes, "type", nil, "Agent types to send to (cc, cod, gmi)")
+	cmd.Flags().BoolVar(&all, "all", false, "Send to all panes including the user pane")
+	cmd.Flags().StringArrayVar(&vars, "var", nil, "Pipeline variable in key=value format")
+	cmd.Flags().StringVar(&missed, "missed", string(state.MissedRunSkip), "Missed-run policy: skip or once")
+	cmd.Flags().BoolVar(&disabled, "disabled", false, "Create the schedule disabled")
+	_ = cmd.MarkFlagRequired("cron")
+	_ = cmd.MarkFlagRequired("session")
+	_ = cmd.RegisterFlagCompletionFunc("session", completeSessionArgs)
+
+	return cmd
+}
+
+func runScheduleAdd(sch *state.Schedule) error {
+	if err := cron.Prepare(sch, time.Now()); err != nil {
+		return err
+	}
+
+	store, err := openScheduleStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if existing, err := store.GetSchedule(sch.Name); err != nil {
+		return err
+	} else if existing != nil {
+		return fmt.Errorf("schedule %q already exists", sch.Name)
+	}
+	if err := store.CreateSchedule(sch); err != nil {
+		return err
+	}
+
+	if IsJSONOutput() {
+		return output.PrintJSON(sch)
+	}
+	fmt.Printf("Added schedule %s (%s)\n", sch.Name, sch.ID)
+	if sch.NextRunAt != nil {
+		fmt.Printf("  Next run: %s\n", sch.NextRunAt.Local().Format("Mon 2006-01-02 15:04"))
+	}
+	return nil
+}
+
+func newScheduleListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List schedules",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScheduleList()
+		},
+	}
+}
+
+func runScheduleList() error {
+	store, err := openScheduleStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	schedules, err := store.ListSchedules()
+	if err != nil {
+		return err
+	}
+	if IsJSONOutput() {
+		if schedules == nil {
+			schedules = []state.Schedule{}
+		}
+		return output.PrintJSON(schedules)
+	}
+	if len(schedules) == 0 {
+		fmt.Println("No schedules. Add one with 'ntm schedule add'.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCRON\tSESSION\tTARGET\tNEXT RUN\tLAST RUN\tRUNS")
+	for _, sch := range schedules {
+		next := "-"
+		if !sch.Enabled {
+			next = "disabled"
+		} else if sch.NextRunAt != nil {
+			next = sch.NextRunAt.Local().Format("2006-01-02 15:04")
+		}
+		last := "-"
+		if sch.LastRunAt != nil {
+			last = sch.LastRunAt.Local().Format("2006-01-02 15:04") + " " + sch.LastStatus
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			sch.Name, sch.Cron, sch.SessionID, describeScheduleTarget(sch), next, last, sch.RunCount)
+	}
+	return w.Flush()
+}
+
+func describeScheduleTarget(sch state.Schedule) string {
+	if sch.TargetType == state.ScheduleTargetPipeline {
+		return "pipeline " + sch.Target.Workflow
+	}
+	msg := sch.Target.Message
+	if len(msg) > 30 {
+		msg = msg[:27] + "..."
+	}
+	return fmt.Sprintf("send %q", msg)
+}
+
+func newScheduleRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <name>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a schedule",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			sch, err := lookupSchedule(store, args[0])
+			if err != nil {
+				return err
+			}
+			if err := store.DeleteSchedule(sch.ID); err != nil {
+				return err
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(map[string]interface{}{"success": true, "id": sch.ID, "name": sch.Name})
+			}
+			fmt.Printf("Removed schedule %s\n", sch.Name)
+			return nil
+		},
+	}
+}
+
+func newScheduleRunNowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run-now <name>",
+		Short: "Run a schedule immediately",
+		Long: `Run a schedule's send or pipeline immediately, in the foreground.
+The schedule's next run time is unchanged.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			sch, err := lookupSchedule(store, args[0])
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+
+			runErr := cron.NewRunner(store, nil).RunNow(ctx, *sch)
+			if IsJSONOutput() {
+				result := map[string]interface{}{"success": runErr == nil, "id": sch.ID, "name": sch.Name}
+				if runErr != nil {
+					result["error"] = runErr.Error()
+				}
+				return output.PrintJSON(result)
+			}
+			if runErr != nil {
+				return fmt.Errorf("schedule %s failed: %w", sch.Name, runErr)
+			}
+			fmt.Printf("Ran schedule %s\n", sch.Name)
+			return nil
+		},
+	}
+}
+
+func newScheduleDaemonCmd() *cobra.Command {
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run the scheduler in the foreground",
+		Long: `Fire schedules without running 'ntm serve'. Runs until interrupted.
+
+The daemon and 'ntm serve' can run at the same time; each slot fires once.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openScheduleStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+
+			runner := cron.NewRunner(store, nil)
+			runner.SetPollInterval(interval)
+			fmt.Printf("Scheduler running (poll every %s). Press Ctrl+C to stop.\n", interval)
+			runner.Run(ctx)
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&interval, "interval", cron.DefaultPollInterval, "How often to check for due schedules")
+	return cmd
+}
+
+func openScheduleStore() (*state.Store, error) {
+	store, err := state.Open("")
+	if err != nil {
+		return nil, fmt.Errorf("open state store: %w", err)
+	}
+	if err := store.Migrate(); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("apply migrations: %w", err)
+	}
+	return store, nil
+}
+
+func lookupSchedule(store *state.Store, idOrName string) (*state.Schedule, error) {
+	sch, err := store.GetSchedule(idOrName)
+	if err != nil {
+		return nil, err
+	}
+	if sch == nil {
+		return nil, fmt.Errorf("schedule %q not found", idOrName)
+	}
+	return sch, nil
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScheduleAddListRemove(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cmd := newScheduleCmd()
+	cmd.SetArgs([]string{"add", "nightly", "--cron", "0 2 * * *", "--session", "proj",
+		"--pipeline", "nightly-review", "--var", "branch=main", "--missed", "once"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("schedule add: %v", err)
+	}
+
+	store, err := openScheduleStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	sch, err := lookupSchedule(store, "nightly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sch.TargetType != "pipeline" || sch.Target.Vars["branch"] != "main" || sch.MissedPolicy != "once" || sch.NextRunAt == nil {
+		t.Errorf("schedule = %+v", sch)
+	}
+	if sch.Target.ProjectDir == "" {
+		t.Error("pipeline schedule should record the project directory")
+	}
+
+	for _, args := range [][]string{
+		{"add", "nightly", "--cron", "@daily", "--session", "proj", "--send", "hi"},
+		{"add", "both", "--cron", "@daily", "--session", "proj", "--send", "hi", "--pipeline", "x"},
+		{"add", "bad", "--cron", "0 25 * * *", "--session", "proj", "--send", "hi"},
+	} {
+		cmd := newScheduleCmd()
+		cmd.SetArgs(args)
+		cmd.SilenceUsage, cmd.SilenceErrors = true, true
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("schedule %s succeeded, want error", strings.Join(args, " "))
+		}
+	}
+
+	cmd = newScheduleCmd()
+	cmd.SetArgs([]string{"remove", "nightly"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("schedule remove: %v", err)
+	}
+	if _, err := lookupSchedule(store, "nightly"); err == nil {
+		t.Error("schedule not removed")
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/robot"
 	sessionPkg "github.com/Dicklesworthstone/ntm/internal/session"
 	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/status"
 	"github.com/Dicklesworthstone/ntm/internal/summary"
 	"github.com/Dicklesworthstone/ntm/internal/templates"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
@@ -118,6 +119,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "SessionInterruptInput",
 			Ref:  "cli.SessionInterruptInput",
+			Type: SessionInterruptInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "InterruptResponse",
@@ -151,6 +153,10 @@ func init() {
 			if value != nil {
 				opts = *value
 			}
+		default:
+			if err := kernel.DecodeInput(value, &opts); err != nil {
+				return nil, err
+			}
 		}
 		if strings.TrimSpace(opts.Session) == "" {
 			return nil, fmt.Errorf("session is required")
@@ -166,6 +172,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "SessionKillInput",
 			Ref:  "cli.SessionKillInput",
+			Type: SessionKillInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "KillResponse",
@@ -199,6 +206,10 @@ func init() {
 			if value != nil {
 				opts = *value
 			}
+		default:
+			if err := kernel.DecodeInput(value, &opts); err != nil {
+				return nil, err
+			}
 		}
 		if strings.TrimSpace(opts.Session) == "" {
 			return nil, fmt.Errorf("session is required")
@@ -222,6 +233,7 @@ type SendOptions struct {
 	TemplateName   string
 	Tags           []string
 	DryRun         bool
+	Queue          bool  // Queue for delivery when each pane is idle instead of sending now
 	Randomize      bool  // Randomize send order for individualized prompts
 	Seed           int64 // Deterministic seed (only used when Randomize=true)
 	PriorityOrder  bool  // Sort batch prompts by priority (P0 first)
@@ -464,6 +476,7 @@ func newSendCmd() *cobra.Command {
 	var templateVars []string
 	var tags []string
 	var dryRun bool
+	var queuePrompt bool
 	var cassCheck bool
 	var noCassCheck bool
 	var cassSimilarity float64
@@ -542,6 +555,7 @@ func newSendCmd() *cobra.Command {
 		  ntm send myproject -c a.go -c b.go "Compare these"    # Multiple files
 		  ntm send myproject -t code_review --file src/main.go  # Template with file
 		  ntm send myproject -t fix --var issue="null pointer" --file src/app.go  # Template with vars
+		  ntm send myproject --cc --queue "now add tests"       # Deliver when each agent is idle
 		  ntm send myproject --smart "fix auth bug"             # Auto-select best agent
 		  ntm send myproject --smart --route=affinity "auth"    # Use affinity strategy`,
 		Args: cobra.MinimumNArgs(1),
@@ -559,6 +573,10 @@ func newSendCmd() *cobra.Command {
 				return err
 			}
 
+			if queuePrompt && (distribute || batchFile != "") {
+				return fmt.Errorf("--queue cannot be used with --distribute or --batch")
+			}
+
 			// Handle --distribute mode: auto-distribute work from bv triage
 			if distribute {
 				if dryRun && distributeAuto {
@@ -635,6 +653,7 @@ func newSendCmd() *cobra.Command {
 				CassCheckDays:  cassCheckDays,
 				NoHooks:        noHooks,
 				DryRun:         dryRun,
+				Queue:          queuePrompt,
 				Randomize:      randomize,
 				Seed:           seed,
 			}
@@ -711,6 +730,7 @@ func newSendCmd() *cobra.Command {
 	cmd.Flags().IntVar(&cassCheckDays, "cass-check-days", 7, "Look back N days for duplicates")
 	cmd.Flags().BoolVar(&noHooks, "no-hooks", false, "Disable command hooks")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview what would be sent without sending")
+	cmd.Flags().BoolVar(&queuePrompt, "queue", false, "Queue the prompt and deliver it when each target agent is idle")
 
 	// Randomization flags
 	cmd.Flags().BoolVar(&randomize, "randomize", false, "Randomize send order for individualized prompts (reduces thundering herd)")
@@ -1042,8 +1062,9 @@ func runSendInternal(opts SendOptions) (err error) {
 	start := time.Now()
 
 	// Defer history logic
+	// Queued prompts are recorded in history when they are delivered.
 	defer func() {
-		if dryRun {
+		if dryRun || opts.Queue {
 			return
 		}
 		entry := history.NewEntry(session, intsToStrings(histTargets), prompt, history.SourceCLI)
@@ -1409,6 +1430,13 @@ func runSendInternal(opts SendOptions) (err error) {
 		return outputError(err)
 	}
 
+	// Refuse new work past an exceeded spend budget
+	if !dryRun {
+		if err := maybeBlockSendForBudget(session, selectedPanes); err != nil {
+			return outputError(err)
+		}
+	}
+
 	if dryRun {
 		entries := buildSendDryRunEntries(selectedPanes, prompt, promptSource)
 		return printSendDryRunResult(SendDryRunResult{
@@ -1425,6 +1453,13 @@ func runSendInternal(opts SendOptions) (err error) {
 		})
 	}
 
+	if opts.Queue {
+		if err := runQueueSend(session, selectedPanes, prompt, templateName, redactionSummary, redactionWarnings); err != nil {
+			return outputError(err)
+		}
+		return nil
+	}
+
 	// If specific pane requested
 	if paneIndex >= 0 {
 		p := selectedPanes[0]
@@ -1982,6 +2017,7 @@ func runKill(w io.Writer, session string, force bool, tags []string, noHooks boo
 		return err
 	}
 	auditKilled = true
+	_ = status.RemoveHookStates(session)
 
 	fmt.Printf("Killed session '%s'\n", session)
 
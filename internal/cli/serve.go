@@ -8,12 +8,18 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Dicklesworthstone/ntm/internal/agenthooks"
+	"github.com/Dicklesworthstone/ntm/internal/budget"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
 	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/notify"
 	"github.com/Dicklesworthstone/ntm/internal/serve"
 	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/transcript"
 )
 
 func newServeCmd() *cobra.Command {
@@ -65,6 +71,16 @@ Examples:
 	return cmd
 }
 
+// transcriptSyncInterval is how often ntm serve re-reads agent transcripts.
+const transcriptSyncInterval = 15 * time.Second
+
+// pricingReloadInterval is how often ntm serve checks pricing.toml for changes.
+const pricingReloadInterval = 30 * time.Second
+
+// agentHookSyncInterval is how often ntm serve opens hook sockets for new
+// sessions and closes those of ended ones.
+const agentHookSyncInterval = 10 * time.Second
+
 type serveOptions struct {
 	Host             string
 	Port             int
@@ -104,13 +120,34 @@ func runServe(opts serveOptions) error {
 	if err != nil {
 		return err
 	}
+	// Read the global config before cfg is shadowed below.
+	tmuxControlMode := cfg == nil || cfg.Tmux.ControlMode
+
+	wd, _ := os.Getwd()
+	sources := newServeMetricSources(wd, cfg)
+	defer sources.close()
+	costs := sources.costs
+	ingester := transcript.NewIngester(costs, sources.contextMonitor)
+
+	// Spend budgets are evaluated against the same exact usage.
+	budgets := &budget.Enforcer{
+		Evaluator: budget.Evaluator{Store: stateStore, Costs: costs},
+		Config:    budgetsConfig(),
+		Bus:       events.DefaultBus,
+	}
+	if cfg != nil {
+		budgets.Notifier = notify.NewWithRedaction(cfg.Notifications, cfg.Redaction.ToRedactionLibConfig())
+	}
+
 	cfg := serve.Config{
-		Host:           opts.Host,
-		Port:           opts.Port,
-		PublicBaseURL:  opts.PublicBaseURL,
-		EventBus:       events.DefaultBus,
-		StateStore:     stateStore,
-		AllowedOrigins: opts.CORSAllowOrigins,
+		Host:            opts.Host,
+		Port:            opts.Port,
+		PublicBaseURL:   opts.PublicBaseURL,
+		EventBus:        events.DefaultBus,
+		StateStore:      stateStore,
+		AllowedOrigins:  opts.CORSAllowOrigins,
+		TmuxControlMode: tmuxControlMode,
+		Metrics:         sources.config(),
 		Auth: serve.AuthConfig{
 			Mode:   mode,
 			APIKey: opts.APIKey,
@@ -136,6 +173,23 @@ func runServe(opts serveOptions) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	go ingester.Run(ctx, transcriptSyncInterval)
+	go sources.run(ctx)
+	// Edits to pricing.toml reprice all tracked usage without a restart.
+	go cost.WatchCatalog(ctx, wd, pricingReloadInterval, func(c *cost.Catalog) {
+		slog.Info("pricing catalog reloaded", "version", c.Version(), "sources", c.Sources)
+	})
+	// Agent CLI hooks report turns, tool calls and permission prompts over
+	// per-session sockets ('ntm hooks agent install').
+	go agenthooks.NewManager(agenthooks.NewRecorder(stateStore).Handle).Run(ctx, agentHookSyncInterval)
+	if budgets.Config.Enabled {
+		interval := time.Duration(budgets.Config.CheckIntervalSeconds) * time.Second
+		if interval <= 0 {
+			interval = time.Minute
+		}
+		go budgets.Run(ctx, interval)
+	}
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
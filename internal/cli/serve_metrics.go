@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	ctxmon "github.com/Dicklesworthstone/ntm/internal/context"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/quota"
+	"github.com/Dicklesworthstone/ntm/internal/scheduler"
+	"github.com/Dicklesworthstone/ntm/internal/serve"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/webhook"
+)
+
+// quotaSyncInterval is how often ntm serve starts quota polling for new
+// agent panes and stops it for closed ones.
+const quotaSyncInterval = time.Minute
+
+// serveMetricSources are the live trackers ntm serve exposes on /metrics.
+type serveMetricSources struct {
+	costs          *cost.CostTracker
+	contextMonitor *ctxmon.ContextMonitor
+	quotas         *quota.Tracker
+	webhooks       *webhook.BusBridge
+	scheduler      *scheduler.Scheduler
+
+	// pollQuotas runs the quota poller; it types the provider's usage
+	// command into agent panes, so it is tied to opt-in account rotation.
+	pollQuotas bool
+}
+
+// newServeMetricSources builds the trackers for a server started in
+// projectDir. Exact token usage from agent transcripts backs the cost
+// metrics; persisted estimates seed sessions that have none. Webhooks come
+// from the project's .ntm.yaml and receive the server's bus events.
+func newServeMetricSources(projectDir string, appCfg *config.Config) *serveMetricSources {
+	if appCfg == nil {
+		appCfg = config.Default()
+	}
+	src := &serveMetricSources{
+		costs:          cost.NewCostTracker(""),
+		contextMonitor: ctxmon.NewContextMonitor(ctxmon.DefaultMonitorConfig()),
+		quotas:         quota.NewTracker(),
+		scheduler:      scheduler.Global(),
+		pollQuotas:     appCfg.Rotation.Enabled,
+	}
+	if projectDir == "" {
+		return src
+	}
+	if err := src.costs.LoadFromDir(projectDir); err != nil {
+		slog.Warn("load cost estimates", "error", err)
+	}
+	redactCfg := appCfg.Redaction.ToRedactionLibConfig()
+	bridge, err := webhook.StartBridgeFromProjectConfig(projectDir, "", events.DefaultBus, &redactCfg)
+	if err != nil {
+		slog.Warn("start webhooks", "error", err)
+	}
+	src.webhooks = bridge
+	return src
+}
+
+// config returns the /metrics configuration reading these sources.
+func (s *serveMetricSources) config() serve.MetricsConfig {
+	return serve.MetricsConfig{
+		ContextMonitor: s.contextMonitor,
+		Costs:          s.costs,
+		Quotas:         s.quotas,
+		Webhooks:       s.webhooks.Manager(),
+		Scheduler:      s.scheduler,
+	}
+}
+
+// run keeps quota polling in step with the agent panes until ctx ends.
+func (s *serveMetricSources) run(ctx context.Context) {
+	if !s.pollQuotas {
+		return
+	}
+	polled := make(map[string]bool)
+	ticker := time.NewTicker(quotaSyncInterval)
+	defer ticker.Stop()
+	defer s.quotas.StopAllPolling()
+	for {
+		syncQuotaPolling(ctx, s.quotas, polled)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// close stops the webhook bridge.
+func (s *serveMetricSources) close() {
+	if err := s.webhooks.Close(); err != nil {
+		slog.Warn("stop webhooks", "error", err)
+	}
+}
+
+// syncQuotaPolling polls every agent pane's provider quota, forgetting
+// panes that no longer exist. polled holds the panes being polled.
+func syncQuotaPolling(ctx context.Context, tracker *quota.Tracker, polled map[string]bool) {
+	byPane, err := tmux.GetAllPanes()
+	if err != nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, panes := range byPane {
+		for _, p := range panes {
+			provider, ok := quotaProvider(p.Type)
+			if !ok {
+				continue
+			}
+			seen[p.ID] = true
+			if !polled[p.ID] {
+				tracker.StartPolling(ctx, p.ID, provider)
+				polled[p.ID] = true
+			}
+		}
+	}
+	for id := range polled {
+		if !seen[id] {
+			tracker.StopPolling(id)
+			tracker.InvalidatePane(id)
+			delete(polled, id)
+		}
+	}
+}
+
+func quotaProvider(t tmux.AgentType) (quota.Provider, bool) {
+	switch t {
+	case tmux.AgentClaude:
+		return quota.ProviderClaude, true
+	case tmux.AgentCodex:
+		return quota.ProviderCodex, true
+	case tmux.AgentGemini:
+		return quota.ProviderGemini, true
+	}
+	return "", false
+}
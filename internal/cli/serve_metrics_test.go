@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/serve"
+)
+
+func TestServeMetricSources_WiresEverySource(t *testing.T) {
+	dir := t.TempDir()
+	ntmYAML := `webhooks:
+  - name: ci
+    url: http://127.0.0.1:1/hook
+    events: [agent.error]
+`
+	if err := os.WriteFile(filepath.Join(dir, ".ntm.yaml"), []byte(ntmYAML), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	// Built the way runServe builds it.
+	sources := newServeMetricSources(dir, config.Default())
+	t.Cleanup(sources.close)
+	metricsCfg := sources.config()
+
+	if metricsCfg.ContextMonitor == nil || metricsCfg.Costs == nil {
+		t.Fatalf("context monitor and costs must be wired: %+v", metricsCfg)
+	}
+	if metricsCfg.Quotas == nil {
+		t.Error("quota tracker not wired")
+	}
+	if metricsCfg.Webhooks == nil {
+		t.Error("webhook manager from .ntm.yaml not wired")
+	}
+	if metricsCfg.Scheduler == nil {
+		t.Error("scheduler not wired")
+	}
+
+	srv := serve.New(serve.Config{Metrics: metricsCfg})
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil).WithContext(context.Background())
+	rec := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"\nntm_webhook_dead_letters 0\n",
+		"\nntm_scheduler_queue_depth 0\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing series %q\n%s", strings.TrimSpace(want), body)
+		}
+	}
+}
+
+func TestServeMetricSources_WithoutProjectWebhooks(t *testing.T) {
+	sources := newServeMetricSources(t.TempDir(), nil)
+	t.Cleanup(sources.close)
+	if got := sources.config().Webhooks; got != nil {
+		t.Errorf("Webhooks = %v, want nil without configured webhooks", got)
+	}
+	if sources.pollQuotas {
+		t.Error("quota polling must stay off unless rotation is enabled")
+	}
+}
@@ -86,6 +86,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "SessionListInput",
 			Ref:  "cli.SessionListInput",
+			Type: SessionListInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "SessionListResponse",
@@ -119,6 +120,10 @@ func init() {
 			if value != nil {
 				opts = *value
 			}
+		default:
+			if err := kernel.DecodeInput(value, &opts); err != nil {
+				return nil, err
+			}
 		}
 		return buildSessionListResponse(opts.Tags)
 	})
@@ -130,6 +135,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "SessionStatusInput",
 			Ref:  "cli.SessionStatusInput",
+			Type: SessionStatusInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "StatusResponse",
@@ -163,6 +169,10 @@ func init() {
 			if value != nil {
 				opts = *value
 			}
+		default:
+			if err := kernel.DecodeInput(value, &opts); err != nil {
+				return nil, err
+			}
 		}
 		if strings.TrimSpace(opts.Session) == "" {
 			return nil, fmt.Errorf("session is required")
@@ -189,6 +199,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "SessionAttachInput",
 			Ref:  "cli.SessionAttachInput",
+			Type: SessionAttachInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "SessionResponse",
@@ -217,6 +228,10 @@ func init() {
 			if value != nil {
 				opts = *value
 			}
+		default:
+			if err := kernel.DecodeInput(value, &opts); err != nil {
+				return nil, err
+			}
 		}
 		if strings.TrimSpace(opts.Session) == "" {
 			return nil, fmt.Errorf("session is required")
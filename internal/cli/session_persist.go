@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Dicklesworthstone/ntm/internal/handoff"
 	"github.com/Dicklesworthstone/ntm/internal/output"
 	"github.com/Dicklesworthstone/ntm/internal/session"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
@@ -299,6 +301,9 @@ func (r *SessionsShowResult) Text(w io.Writer) error {
 			model = fmt.Sprintf(" (%s)", p.Model)
 		}
 		fmt.Fprintf(w, "  [%d] %s%s%s\n", p.Index, p.Title, model, active)
+		if p.NativeSessionID != "" {
+			fmt.Fprintf(w, "      native session: %s\n", p.NativeSessionID)
+		}
 	}
 
 	return nil
@@ -370,6 +375,7 @@ func newSessionsRestoreCmd() *cobra.Command {
 	var attach bool
 	var skipGitCheck bool
 	var launchAgents bool
+	var native bool
 
 	cmd := &cobra.Command{
 		Use:   "restore <saved-name>",
@@ -379,12 +385,18 @@ func newSessionsRestoreCmd() *cobra.Command {
 Creates a new tmux session with the same panes and layout as the saved state.
 Optionally launches agents in the panes.
 
+With --native, each agent is relaunched into its own conversation using its
+CLI's resume flag (claude --resume, codex resume, gemini --resume) in the
+directory it ran in. Agents whose native session is unknown or gone start
+fresh and receive the session's latest handoff instead.
+
 Examples:
   ntm sessions restore myproject              # Restore saved session
   ntm sessions restore myproject --force      # Overwrite if session exists
   ntm sessions restore myproject --attach     # Attach after restore
   ntm sessions restore myproject --name=new   # Restore as different name
-  ntm sessions restore myproject --launch     # Launch agents in panes`,
+  ntm sessions restore myproject --launch     # Launch agents in panes
+  ntm sessions restore myproject --native     # Resume each agent's conversation`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts := session.RestoreOptions{
@@ -392,7 +404,7 @@ Examples:
 				Force:        force,
 				SkipGitCheck: skipGitCheck,
 			}
-			return runSessionsRestore(args[0], opts, attach, launchAgents)
+			return runSessionsRestore(args[0], opts, attach, launchAgents, native)
 		},
 	}
 
@@ -401,7 +413,15 @@ Examples:
 	cmd.Flags().BoolVarP(&attach, "attach", "a", false, "attach after restore")
 	cmd.Flags().BoolVar(&skipGitCheck, "skip-git-check", false, "don't warn about git branch mismatch")
 	cmd.Flags().BoolVar(&launchAgents, "launch", false, "launch agents in restored panes")
+	cmd.Flags().BoolVar(&native, "native", false, "launch agents resuming their native CLI sessions (implies --launch)")
+
+	return cmd
+}
 
+// newRestoreCmd exposes 'ntm sessions restore' as 'ntm restore'.
+func newRestoreCmd() *cobra.Command {
+	cmd := newSessionsRestoreCmd()
+	cmd.Short = "Restore a saved session (same as 'ntm sessions restore')"
 	return cmd
 }
 
@@ -412,6 +432,8 @@ type SessionsRestoreResult struct {
 	RestoredAs string                `json:"restored_as"`
 	State      *session.SessionState `json:"state,omitempty"`
 	AgentCount int                   `json:"agent_count"`
+	Resumed    int                   `json:"resumed,omitempty"`
+	Handoff    int                   `json:"handoff_injected,omitempty"`
 	Error      string                `json:"error,omitempty"`
 	GitWarning string                `json:"git_warning,omitempty"`
 }
@@ -432,6 +454,9 @@ func (r *SessionsRestoreResult) Text(w io.Writer) error {
 		fmt.Fprintf(w, "  Agents: %d Claude, %d Codex, %d Gemini\n",
 			r.State.Agents.Claude, r.State.Agents.Codex, r.State.Agents.Gemini)
 	}
+	if r.Resumed > 0 || r.Handoff > 0 {
+		fmt.Fprintf(w, "  Native sessions resumed: %d, handoff injected: %d\n", r.Resumed, r.Handoff)
+	}
 	if r.GitWarning != "" {
 		fmt.Fprintf(w, "  %sWarning:%s %s\n", colorize(t.Warning), colorize(t.Text), r.GitWarning)
 	}
@@ -442,7 +467,7 @@ func (r *SessionsRestoreResult) JSON() interface{} {
 	return r
 }
 
-func runSessionsRestore(savedName string, opts session.RestoreOptions, attach, launchAgents bool) error {
+func runSessionsRestore(savedName string, opts session.RestoreOptions, attach, launchAgents, native bool) error {
 	if err := tmux.EnsureInstalled(); err != nil {
 		return err
 	}
@@ -485,15 +510,24 @@ func runSessionsRestore(savedName string, opts session.RestoreOptions, attach, l
 
 	// Optionally launch agents
 	var launchErr error
-	agentCount := 0
-	if launchAgents {
+	agentCount, resumed, injected := 0, 0, 0
+	if launchAgents || native {
 		if cfg != nil {
 			cmds := session.AgentCommands{
 				Claude: cfg.Agents.Claude,
 				Codex:  cfg.Agents.Codex,
 				Gemini: cfg.Agents.Gemini,
 			}
-			launchErr = session.RestoreAgents(restoredName, state, cmds)
+			if native {
+				var res *session.NativeRestoreResult
+				res, launchErr = session.RestoreAgentsNative(restoredName, state, cmds)
+				if res != nil {
+					resumed = len(res.Resumed)
+					injected = injectRestoreHandoff(state, res.Fresh)
+				}
+			} else {
+				launchErr = session.RestoreAgents(restoredName, state, cmds)
+			}
 		}
 		agentCount = state.Agents.Total()
 	}
@@ -504,6 +538,8 @@ func runSessionsRestore(savedName string, opts session.RestoreOptions, attach, l
 		RestoredAs: restoredName,
 		State:      state,
 		AgentCount: agentCount,
+		Resumed:    resumed,
+		Handoff:    injected,
 		GitWarning: gitWarning,
 	}
 
@@ -522,3 +558,32 @@ func runSessionsRestore(savedName string, opts session.RestoreOptions, attach, l
 
 	return nil
 }
+
+// restoreHandoffDelay gives freshly launched agents time to start before the
+// handoff is pasted into them.
+const restoreHandoffDelay = 5 * time.Second
+
+// injectRestoreHandoff sends the saved session's latest handoff to panes
+// whose native session could not be resumed. It returns how many panes
+// received it.
+func injectRestoreHandoff(state *session.SessionState, paneIDs []string) int {
+	if len(paneIDs) == 0 {
+		return 0
+	}
+	h, _, err := handoff.NewReader(state.WorkDir).FindLatest(state.Name)
+	if err != nil || h == nil {
+		return 0
+	}
+
+	time.Sleep(restoreHandoffDelay)
+	prompt := h.ResumeContext()
+	sent := 0
+	for _, paneID := range paneIDs {
+		if err := sendPromptWithDoubleEnter(paneID, prompt); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to send handoff to pane %s: %v\n", paneID, err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
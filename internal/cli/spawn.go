@@ -1851,6 +1851,7 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 				Type:      agent.agentType,
 				Model:     agent.model,
 				Command:   agent.command,
+				Cwd:       dir,
 			})
 		}
 		if err := resilience.SaveManifest(manifest); err != nil {
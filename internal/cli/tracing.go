@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/tracing"
+)
+
+// traceShutdownTimeout bounds the final span flush when a command exits.
+const traceShutdownTimeout = 3 * time.Second
+
+// longRunningCommands run until interrupted. They get no command span; the
+// requests, pipeline runs and jobs they handle start their own traces.
+var longRunningCommands = map[string]bool{
+	"serve":     true,
+	"daemon":    true,
+	"dashboard": true,
+	"watch":     true,
+}
+
+var traceInitialized bool
+
+// startCommandTrace installs the OTLP exporter from config and environment
+// and starts the span for cmd. It must run before startCommandAudit so the
+// audit correlation ID carries the trace ID.
+func startCommandTrace(cmd *cobra.Command, args []string) {
+	if cmd == nil || traceInitialized {
+		return
+	}
+	traceInitialized = true
+
+	if err := tracing.Init(tracingConfig(cfg)); err != nil {
+		output.PrintWarningf("tracing disabled: %v", err)
+		return
+	}
+	if !tracing.Enabled() || longRunningCommands[cmd.Name()] {
+		return
+	}
+	tracing.StartCommand(cmd.CommandPath(),
+		tracing.String("ntm.command", cmd.CommandPath()),
+		tracing.Int("ntm.args.count", len(args)),
+	)
+}
+
+// endCommandTrace ends the command span and flushes queued spans.
+func endCommandTrace(err error) {
+	if !tracing.Enabled() {
+		return
+	}
+	tracing.EndCommand(err)
+	ctx, cancel := context.WithTimeout(context.Background(), traceShutdownTimeout)
+	defer cancel()
+	_ = tracing.Shutdown(ctx)
+}
+
+// tracingConfig converts the [tracing] config section, then applies the
+// standard OTEL_* environment overrides. A nil cfg uses the environment only.
+func tracingConfig(c *config.Config) tracing.Config {
+	out := tracing.Config{ServiceVersion: Version}
+	if c != nil && c.Tracing.Enabled {
+		out.Endpoint = c.Tracing.Endpoint
+		out.Protocol = c.Tracing.Protocol
+		out.Headers = c.Tracing.Headers
+		out.File = config.ExpandHome(c.Tracing.File)
+		out.ServiceName = c.Tracing.ServiceName
+		out.Timeout = time.Duration(c.Tracing.TimeoutSeconds) * time.Second
+	}
+	out = out.ApplyEnv()
+	if c != nil && !c.Tracing.Enabled {
+		// An explicit enabled = false wins over the environment.
+		out.Endpoint, out.File = "", ""
+	}
+	return out
+}
@@ -28,6 +28,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "SessionViewInput",
 			Ref:  "cli.SessionViewInput",
+			Type: SessionViewInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "SuccessResponse",
@@ -56,6 +57,10 @@ func init() {
 			if value != nil {
 				opts = *value
 			}
+		default:
+			if err := kernel.DecodeInput(value, &opts); err != nil {
+				return nil, err
+			}
 		}
 		if strings.TrimSpace(opts.Session) == "" {
 			return nil, fmt.Errorf("session is required")
@@ -97,7 +97,10 @@ func newWorktreesMergeCmd() *cobra.Command {
 		Long: `Merge changes from an agent's worktree branch back to the main branch.
 
 This will switch to the main branch and merge the agent's branch using
-a non-fast-forward merge to preserve the merge history.`,
+a non-fast-forward merge to preserve the merge history.
+
+To land several agents' branches without touching your checkout, and only
+when tests pass, use 'ntm merge-queue' instead.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			agentName := args[0]
@@ -31,6 +31,7 @@ func init() {
 		Input: &kernel.SchemaRef{
 			Name: "SessionZoomInput",
 			Ref:  "cli.SessionZoomInput",
+			Type: SessionZoomInput{},
 		},
 		Output: &kernel.SchemaRef{
 			Name: "SuccessResponse",
@@ -59,6 +60,10 @@ func init() {
 			if value != nil {
 				opts = *value
 			}
+		default:
+			if err := kernel.DecodeInput(value, &opts); err != nil {
+				return nil, err
+			}
 		}
 		if strings.TrimSpace(opts.Session) == "" {
 			return nil, fmt.Errorf("session is required")
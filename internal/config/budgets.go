@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// BudgetsConfig caps estimated real-money spend. Budgets set with
+// 'ntm budget set' take precedence over the same budget configured here.
+//
+//	[budgets]
+//	daily_usd = 50.0
+//	session_usd = 20.0
+//	warn_at = [0.5, 0.8]
+//	interrupt = false
+//
+//	[budgets.sessions]
+//	nightly = 5.0
+//
+//	[budgets.agent_types]
+//	cc = 30.0
+type BudgetsConfig struct {
+	Enabled              bool               `toml:"enabled"`                // Master toggle for warnings and enforcement
+	DailyUSD             float64            `toml:"daily_usd"`              // All spend per local day (0 = no cap)
+	SessionUSD           float64            `toml:"session_usd"`            // Default cap for each session's cumulative spend (0 = no cap)
+	Sessions             map[string]float64 `toml:"sessions"`               // Per-session caps, overriding session_usd
+	AgentTypes           map[string]float64 `toml:"agent_types"`            // Per agent type (cc, cod, gmi) per local day
+	WarnAt               []float64          `toml:"warn_at"`                // Fractions of a cap that trigger a warning
+	Block                bool               `toml:"block"`                  // Refuse sends and pipeline steps past a cap
+	Interrupt            bool               `toml:"interrupt"`              // Interrupt the panes spending past a cap
+	CheckIntervalSeconds int                `toml:"check_interval_seconds"` // How often ntm serve evaluates budgets
+}
+
+// DefaultBudgetsConfig returns the default budget settings: enforcement on,
+// but no caps until one is configured.
+func DefaultBudgetsConfig() BudgetsConfig {
+	return BudgetsConfig{
+		Enabled:              true,
+		WarnAt:               []float64{0.5, 0.8},
+		Block:                true,
+		CheckIntervalSeconds: 60,
+	}
+}
+
+// ValidateBudgetsConfig checks that caps are non-negative and warning
+// thresholds lie strictly between 0 and 1.
+func ValidateBudgetsConfig(c *BudgetsConfig) error {
+	if c.DailyUSD < 0 {
+		return fmt.Errorf("budgets.daily_usd must be >= 0")
+	}
+	if c.SessionUSD < 0 {
+		return fmt.Errorf("budgets.session_usd must be >= 0")
+	}
+	for _, name := range sortedBudgetKeys(c.Sessions) {
+		if c.Sessions[name] <= 0 {
+			return fmt.Errorf("budgets.sessions.%s must be > 0", name)
+		}
+	}
+	for _, name := range sortedBudgetKeys(c.AgentTypes) {
+		if c.AgentTypes[name] <= 0 {
+			return fmt.Errorf("budgets.agent_types.%s must be > 0", name)
+		}
+	}
+	for _, f := range c.WarnAt {
+		if f <= 0 || f >= 1 {
+			return fmt.Errorf("budgets.warn_at values must be between 0 and 1, got %v", f)
+		}
+	}
+	if c.CheckIntervalSeconds < 0 {
+		return fmt.Errorf("budgets.check_interval_seconds must be >= 0")
+	}
+	return nil
+}
+
+func sortedBudgetKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
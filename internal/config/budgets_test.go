@@ -0,0 +1,56 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestBudgetsConfigTOML(t *testing.T) {
+	data := `
+[budgets]
+daily_usd = 50.0
+interrupt = true
+
+[budgets.sessions]
+nightly = 5.0
+
+[budgets.agent_types]
+cc = 30.0
+`
+	cfg := Default()
+	if _, err := toml.Decode(data, cfg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	b := cfg.Budgets
+	if !b.Enabled || !b.Block || !b.Interrupt || b.DailyUSD != 50 || b.CheckIntervalSeconds != 60 {
+		t.Errorf("budgets = %+v", b)
+	}
+	if b.Sessions["nightly"] != 5 || b.AgentTypes["cc"] != 30 || len(b.WarnAt) != 2 {
+		t.Errorf("maps/warn_at = %v/%v/%v", b.Sessions, b.AgentTypes, b.WarnAt)
+	}
+	if err := ValidateBudgetsConfig(&b); err != nil {
+		t.Errorf("ValidateBudgetsConfig: %v", err)
+	}
+}
+
+func TestValidateBudgetsConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  BudgetsConfig
+	}{
+		{"negative daily", BudgetsConfig{DailyUSD: -1}},
+		{"negative session", BudgetsConfig{SessionUSD: -1}},
+		{"zero session cap", BudgetsConfig{Sessions: map[string]float64{"proj": 0}}},
+		{"zero agent cap", BudgetsConfig{AgentTypes: map[string]float64{"cc": 0}}},
+		{"threshold above one", BudgetsConfig{WarnAt: []float64{1.5}}},
+		{"negative interval", BudgetsConfig{CheckIntervalSeconds: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateBudgetsConfig(&tt.cfg); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
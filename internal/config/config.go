@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -95,6 +96,10 @@ type Config struct {
 	Encryption         EncryptionConfig      `toml:"encryption"`       // Encryption at rest for artifacts
 	Send               SendConfig            `toml:"send"`             // Send command defaults
 	Prompts            PromptsConfig         `toml:"prompts"`          // Per-agent-type default prompts
+	Fleet              FleetConfig           `toml:"fleet"`            // Remote ntm serve instances for ntm fleet
+	Tracing            TracingConfig         `toml:"tracing"`          // OTLP trace export
+	Budgets            BudgetsConfig         `toml:"budgets"`          // Spend budgets and enforcement
+	MergeQueue         MergeQueueConfig      `toml:"merge_queue"`      // Test-gated merge queue for agent worktrees
 
 	// Runtime-only fields (populated by project config merging)
 	ProjectDefaults map[string]int `toml:"-"`
@@ -836,6 +841,9 @@ type TmuxConfig struct {
 	DefaultPanes    int    `toml:"default_panes"`
 	PaletteKey      string `toml:"palette_key"`
 	PaneInitDelayMs int    `toml:"pane_init_delay_ms"` // Delay before sending keys to new panes
+	// ControlMode runs tmux commands and pane streaming over a persistent
+	// `tmux -C` connection instead of forking tmux per call (tmux 3.2+).
+	ControlMode bool `toml:"control_mode"`
 	// ActivityIndicators control pane border activity coloring.
 	ActivityIndicators ActivityIndicatorConfig `toml:"activity_indicators"`
 }
@@ -1714,14 +1722,42 @@ type RedactionConfig struct {
 	// DisabledCategories lists secret categories to skip during scanning.
 	// Valid categories: OPENAI_KEY, ANTHROPIC_KEY, GITHUB_TOKEN, AWS_ACCESS_KEY,
 	// AWS_SECRET_KEY, JWT, GOOGLE_API_KEY, PRIVATE_KEY, DATABASE_URL, PASSWORD,
-	// GENERIC_API_KEY, GENERIC_SECRET, BEARER_TOKEN
+	// GENERIC_API_KEY, GENERIC_SECRET, BEARER_TOKEN, HIGH_ENTROPY, plus any
+	// extra pattern or rule pack category
 	DisabledCategories []string `toml:"disabled_categories,omitempty"`
+
+	// Entropy configures detection of unrecognized high-entropy tokens.
+	Entropy RedactionEntropyConfig `toml:"entropy"`
+
+	// PII lists personal-data categories to detect: EMAIL, PHONE, IP_ADDRESS.
+	// None are detected by default.
+	PII []string `toml:"pii,omitempty"`
+
+	// RulePacks lists rule pack TOML files (globs allowed) with extra rules.
+	// Validate them with `ntm redact validate`.
+	RulePacks []string `toml:"rule_packs,omitempty"`
+}
+
+// RedactionEntropyConfig configures the Shannon-entropy detector.
+type RedactionEntropyConfig struct {
+	Enabled   bool    `toml:"enabled"`
+	Threshold float64 `toml:"threshold"`  // Minimum bits per character
+	MinLength int     `toml:"min_length"` // Minimum token length
+	// Keywords must appear shortly before a token on the same line.
+	// Empty uses the built-in credential keywords.
+	Keywords []string `toml:"keywords,omitempty"`
 }
 
 // DefaultRedactionConfig returns sensible redaction defaults.
 func DefaultRedactionConfig() RedactionConfig {
+	entropy := redaction.DefaultEntropyConfig()
 	return RedactionConfig{
 		Mode: "warn", // Safe default: detect but don't block
+		Entropy: RedactionEntropyConfig{
+			Enabled:   entropy.Enabled,
+			Threshold: entropy.Threshold,
+			MinLength: entropy.MinLength,
+		},
 	}
 }
 
@@ -1729,10 +1765,33 @@ func DefaultRedactionConfig() RedactionConfig {
 func ValidateRedactionConfig(cfg *RedactionConfig) error {
 	switch cfg.Mode {
 	case "", "off", "warn", "redact", "block":
-		return nil
 	default:
 		return fmt.Errorf("invalid redaction mode %q: must be off, warn, redact, or block", cfg.Mode)
 	}
+
+	libCfg := cfg.ToRedactionLibConfig()
+	if err := libCfg.Validate(); err != nil {
+		return fmt.Errorf("redaction: %w", err)
+	}
+	packs, err := redaction.LoadRulePacks(cfg.RulePackPaths())
+	if err != nil {
+		return fmt.Errorf("redaction.rule_packs: %w", err)
+	}
+	for _, p := range packs {
+		if errs := p.Test(); len(errs) > 0 {
+			return fmt.Errorf("redaction.rule_packs: %s: %w", p.Path, errors.Join(errs...))
+		}
+	}
+	return nil
+}
+
+// RulePackPaths returns RulePacks with ~ expanded.
+func (c *RedactionConfig) RulePackPaths() []string {
+	paths := make([]string, 0, len(c.RulePacks))
+	for _, p := range c.RulePacks {
+		paths = append(paths, ExpandHome(p))
+	}
+	return paths
 }
 
 // PrivacyConfig holds configuration for privacy mode.
@@ -1914,6 +1973,26 @@ func (c *RedactionConfig) ToRedactionLibConfig() redaction.Config {
 		}
 	}
 
+	libCfg.Entropy = redaction.EntropyConfig{
+		Enabled:   c.Entropy.Enabled,
+		Threshold: c.Entropy.Threshold,
+		MinLength: c.Entropy.MinLength,
+		Keywords:  c.Entropy.Keywords,
+	}
+	if len(libCfg.Entropy.Keywords) == 0 {
+		libCfg.Entropy.Keywords = redaction.DefaultEntropyKeywords
+	}
+
+	for _, cat := range c.PII {
+		libCfg.PII = append(libCfg.PII, redaction.Category(strings.ToUpper(cat)))
+	}
+
+	// Packs that fail to load are reported by ValidateRedactionConfig and
+	// `ntm redact validate`; scanning continues with the rest.
+	if len(c.RulePacks) > 0 {
+		libCfg.RulePacks, _ = redaction.LoadRulePacks(c.RulePackPaths())
+	}
+
 	return libCfg
 }
 
@@ -1934,6 +2013,7 @@ func Default() *Config {
 			DefaultPanes:       10,
 			PaletteKey:         "F6",
 			PaneInitDelayMs:    1000,
+			ControlMode:        true,
 			ActivityIndicators: DefaultActivityIndicatorConfig(),
 		},
 		Robot: DefaultRobotConfig(),
@@ -1970,6 +2050,10 @@ func Default() *Config {
 		Redaction:       DefaultRedactionConfig(),
 		Privacy:         DefaultPrivacyConfig(),
 		Encryption:      DefaultEncryptionConfig(),
+		Fleet:           DefaultFleetConfig(),
+		Tracing:         DefaultTracingConfig(),
+		Budgets:         DefaultBudgetsConfig(),
+		MergeQueue:      DefaultMergeQueueConfig(),
 	}
 
 	// Apply safety profile defaults (standard/safe/paranoid).
@@ -2460,6 +2544,7 @@ func Print(cfg *Config, w io.Writer) error {
 	fmt.Fprintf(w, "default_panes = %d\n", cfg.Tmux.DefaultPanes)
 	fmt.Fprintf(w, "palette_key = %q\n", cfg.Tmux.PaletteKey)
 	fmt.Fprintf(w, "pane_init_delay_ms = %d  # Delay before send-keys to new panes\n", cfg.Tmux.PaneInitDelayMs)
+	fmt.Fprintf(w, "control_mode = %t  # Use a persistent tmux -C connection (falls back to exec)\n", cfg.Tmux.ControlMode)
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w, "[robot]")
@@ -2590,6 +2675,27 @@ func Print(cfg *Config, w io.Writer) error {
 		fmt.Fprintln(w, "disabled_categories = []")
 	}
 	fmt.Fprintln(w, "# extra_patterns = { CUSTOM_TOKEN = [\"regex\"] }")
+	fmt.Fprintln(w, "# Personal data to detect: EMAIL, PHONE, IP_ADDRESS")
+	if len(cfg.Redaction.PII) > 0 {
+		fmt.Fprintf(w, "pii = %s\n", renderTOMLStringArray(cfg.Redaction.PII))
+	} else {
+		fmt.Fprintln(w, "pii = []")
+	}
+	if len(cfg.Redaction.RulePacks) > 0 {
+		fmt.Fprintf(w, "rule_packs = %s\n", renderTOMLStringArray(cfg.Redaction.RulePacks))
+	} else {
+		fmt.Fprintln(w, "# rule_packs = [\"~/.config/ntm/redaction/*.toml\"]")
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "[redaction.entropy]")
+	fmt.Fprintln(w, "# Flag random-looking tokens after credential keywords")
+	fmt.Fprintf(w, "enabled = %t\n", cfg.Redaction.Entropy.Enabled)
+	fmt.Fprintf(w, "threshold = %g\n", cfg.Redaction.Entropy.Threshold)
+	fmt.Fprintf(w, "min_length = %d\n", cfg.Redaction.Entropy.MinLength)
+	if len(cfg.Redaction.Entropy.Keywords) > 0 {
+		fmt.Fprintf(w, "keywords = %s\n", renderTOMLStringArray(cfg.Redaction.Entropy.Keywords))
+	}
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w, "[privacy]")
@@ -3124,6 +3230,8 @@ func GetValue(cfg *Config, path string) (interface{}, error) {
 			return cfg.Tmux.PaletteKey, nil
 		case "pane_init_delay_ms":
 			return cfg.Tmux.PaneInitDelayMs, nil
+		case "control_mode":
+			return cfg.Tmux.ControlMode, nil
 		}
 	case "agent_mail":
 		if len(parts) < 2 {
@@ -3410,6 +3518,7 @@ func Diff(cfg *Config) []ConfigDiff {
 	addDiff("tmux.default_panes", defaults.Tmux.DefaultPanes, cfg.Tmux.DefaultPanes)
 	addDiff("tmux.palette_key", defaults.Tmux.PaletteKey, cfg.Tmux.PaletteKey)
 	addDiff("tmux.pane_init_delay_ms", defaults.Tmux.PaneInitDelayMs, cfg.Tmux.PaneInitDelayMs)
+	addDiff("tmux.control_mode", defaults.Tmux.ControlMode, cfg.Tmux.ControlMode)
 
 	// Agent Mail
 	addDiff("agent_mail.enabled", defaults.AgentMail.Enabled, cfg.AgentMail.Enabled)
@@ -3563,6 +3672,21 @@ func Validate(cfg *Config) []error {
 		errs = append(errs, fmt.Errorf("encryption: %w", err))
 	}
 
+	// Validate tracing configuration
+	if err := ValidateTracingConfig(&cfg.Tracing); err != nil {
+		errs = append(errs, fmt.Errorf("tracing: %w", err))
+	}
+
+	// Validate budgets configuration
+	if err := ValidateBudgetsConfig(&cfg.Budgets); err != nil {
+		errs = append(errs, fmt.Errorf("budgets: %w", err))
+	}
+
+	// Validate merge queue configuration
+	if err := ValidateMergeQueueConfig(&cfg.MergeQueue); err != nil {
+		errs = append(errs, fmt.Errorf("merge_queue: %w", err))
+	}
+
 	// Validate projects_base if set
 	if cfg.ProjectsBase != "" {
 		expanded := ExpandHome(cfg.ProjectsBase)
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/redaction"
 )
 
 func TestDefault(t *testing.T) {
@@ -3306,6 +3308,53 @@ func TestRedactionConfig_ToRedactionLibConfig(t *testing.T) {
 	})
 }
 
+func TestRedactionConfig_DetectorsAndRulePacks(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.toml")
+	pack := "id = \"acme\"\n[[rules]]\nid = \"ticket\"\nregex = 'ACME-[0-9]{6}'\nexamples = [\"ACME-123456\"]\n"
+	if err := os.WriteFile(good, []byte(pack), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultRedactionConfig()
+	cfg.PII = []string{"email", "IP_ADDRESS"}
+	cfg.RulePacks = []string{filepath.Join(dir, "*.toml")}
+	if err := ValidateRedactionConfig(&cfg); err != nil {
+		t.Fatalf("ValidateRedactionConfig() = %v", err)
+	}
+
+	libCfg := cfg.ToRedactionLibConfig()
+	if !libCfg.Entropy.Enabled || libCfg.Entropy.Threshold != 4.0 || len(libCfg.Entropy.Keywords) == 0 {
+		t.Errorf("Entropy = %+v, want enabled defaults with keywords", libCfg.Entropy)
+	}
+	if len(libCfg.PII) != 2 || libCfg.PII[0] != redaction.CategoryEmail {
+		t.Errorf("PII = %v, want [EMAIL IP_ADDRESS]", libCfg.PII)
+	}
+	if len(libCfg.RulePacks) != 1 || libCfg.RulePacks[0].ID != "acme" {
+		t.Fatalf("RulePacks = %v, want acme", libCfg.RulePacks)
+	}
+
+	// A pack whose examples fail is rejected.
+	bad := filepath.Join(dir, "bad.toml")
+	if err := os.WriteFile(bad, []byte(strings.Replace(pack, "ACME-123456", "nope", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateRedactionConfig(&cfg); err == nil || !strings.Contains(err.Error(), "example 1 not detected") {
+		t.Errorf("ValidateRedactionConfig() = %v, want example failure", err)
+	}
+
+	cfg.RulePacks = nil
+	cfg.PII = []string{"SSN"}
+	if err := ValidateRedactionConfig(&cfg); err == nil {
+		t.Error("expected error for unknown PII category")
+	}
+	cfg.PII = nil
+	cfg.Entropy.Threshold = 12
+	if err := ValidateRedactionConfig(&cfg); err == nil {
+		t.Error("expected error for entropy threshold out of range")
+	}
+}
+
 func TestRedactionConfigInDefault(t *testing.T) {
 	cfg := Default()
 
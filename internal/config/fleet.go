@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FleetConfig lists the `ntm serve` instances that `ntm fleet` aggregates.
+//
+//	[fleet]
+//	poll_interval_seconds = 5
+//
+//	[[fleet.hosts]]
+//	name = "gpu-box"
+//	url = "http://gpu-box:7337"
+//	api_key_env = "NTM_GPU_BOX_KEY"
+type FleetConfig struct {
+	Hosts               []FleetHostConfig `toml:"hosts"`
+	PollIntervalSeconds int               `toml:"poll_interval_seconds"` // Default: 5
+	TimeoutSeconds      int               `toml:"timeout_seconds"`       // Per-request timeout, default: 5
+}
+
+// FleetHostConfig is one `ntm serve` instance in the fleet.
+type FleetHostConfig struct {
+	Name      string `toml:"name"`        // Used in host:session targets
+	URL       string `toml:"url"`         // Base URL, e.g. http://host:7337
+	APIKey    string `toml:"api_key"`     // Sent as X-API-Key
+	APIKeyEnv string `toml:"api_key_env"` // Environment variable holding the API key
+}
+
+// DefaultFleetConfig returns the default fleet settings (no hosts).
+func DefaultFleetConfig() FleetConfig {
+	return FleetConfig{
+		PollIntervalSeconds: 5,
+		TimeoutSeconds:      5,
+	}
+}
+
+// ResolvedAPIKey returns the host's API key, preferring api_key_env when set.
+func (h FleetHostConfig) ResolvedAPIKey() string {
+	if h.APIKeyEnv != "" {
+		if key := strings.TrimSpace(os.Getenv(h.APIKeyEnv)); key != "" {
+			return key
+		}
+	}
+	return h.APIKey
+}
+
+// ValidateFleetConfig checks that every host has a unique name and a URL.
+func ValidateFleetConfig(c *FleetConfig) error {
+	seen := make(map[string]bool, len(c.Hosts))
+	for i, h := range c.Hosts {
+		name := strings.TrimSpace(h.Name)
+		if name == "" {
+			return fmt.Errorf("fleet.hosts[%d]: name is required", i)
+		}
+		if strings.TrimSpace(h.URL) == "" {
+			return fmt.Errorf("fleet.hosts[%d] (%s): url is required", i, name)
+		}
+		if seen[name] {
+			return fmt.Errorf("fleet.hosts[%d]: duplicate host name %q", i, name)
+		}
+		seen[name] = true
+	}
+	if c.PollIntervalSeconds < 0 {
+		return fmt.Errorf("fleet.poll_interval_seconds must be >= 0")
+	}
+	if c.TimeoutSeconds < 0 {
+		return fmt.Errorf("fleet.timeout_seconds must be >= 0")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestFleetConfigTOML(t *testing.T) {
+	data := `
+[fleet]
+poll_interval_seconds = 10
+
+[[fleet.hosts]]
+name = "local"
+url = "http://127.0.0.1:7337"
+
+[[fleet.hosts]]
+name = "gpu"
+url = "http://gpu:7337"
+api_key = "inline"
+api_key_env = "NTM_TEST_FLEET_GPU_KEY"
+`
+	cfg := Default()
+	if _, err := toml.Decode(data, cfg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(cfg.Fleet.Hosts) != 2 {
+		t.Fatalf("hosts = %d, want 2", len(cfg.Fleet.Hosts))
+	}
+	if cfg.Fleet.PollIntervalSeconds != 10 || cfg.Fleet.TimeoutSeconds != 5 {
+		t.Errorf("poll/timeout = %d/%d, want 10/5", cfg.Fleet.PollIntervalSeconds, cfg.Fleet.TimeoutSeconds)
+	}
+	if err := ValidateFleetConfig(&cfg.Fleet); err != nil {
+		t.Errorf("ValidateFleetConfig: %v", err)
+	}
+
+	gpu := cfg.Fleet.Hosts[1]
+	if got := gpu.ResolvedAPIKey(); got != "inline" {
+		t.Errorf("ResolvedAPIKey without env = %q, want inline", got)
+	}
+	t.Setenv("NTM_TEST_FLEET_GPU_KEY", "from-env")
+	if got := gpu.ResolvedAPIKey(); got != "from-env" {
+		t.Errorf("ResolvedAPIKey with env = %q, want from-env", got)
+	}
+}
+
+func TestValidateFleetConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  FleetConfig
+	}{
+		{"missing name", FleetConfig{Hosts: []FleetHostConfig{{URL: "http://a"}}}},
+		{"missing url", FleetConfig{Hosts: []FleetHostConfig{{Name: "a"}}}},
+		{"duplicate", FleetConfig{Hosts: []FleetHostConfig{{Name: "a", URL: "http://a"}, {Name: "a", URL: "http://b"}}}},
+		{"negative poll", FleetConfig{PollIntervalSeconds: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFleetConfig(&tt.cfg); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
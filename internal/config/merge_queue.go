@@ -0,0 +1,38 @@
+package config
+
+import "fmt"
+
+// MergeQueueConfig configures 'ntm merge-queue run'.
+//
+//	[merge_queue]
+//	target = "main"
+//	test_command = "go test ./..."
+//	test_timeout_seconds = 1800
+//	notify = "send"
+type MergeQueueConfig struct {
+	Target             string `toml:"target"`               // Branch to fast-forward ("" = main, or master if there is no main)
+	TestCommand        string `toml:"test_command"`         // Run in the rebased scratch worktree; empty skips testing
+	TestTimeoutSeconds int    `toml:"test_timeout_seconds"` // Treat the tests as failed after this long (0 = no limit)
+	Notify             string `toml:"notify"`               // How conflicts and failures reach the agent: send, mail, none
+}
+
+// DefaultMergeQueueConfig returns the default merge queue settings.
+func DefaultMergeQueueConfig() MergeQueueConfig {
+	return MergeQueueConfig{
+		TestTimeoutSeconds: 1800,
+		Notify:             "send",
+	}
+}
+
+// ValidateMergeQueueConfig checks the notify mode and test timeout.
+func ValidateMergeQueueConfig(c *MergeQueueConfig) error {
+	switch c.Notify {
+	case "", "send", "mail", "none":
+	default:
+		return fmt.Errorf("merge_queue.notify must be send, mail or none, got %q", c.Notify)
+	}
+	if c.TestTimeoutSeconds < 0 {
+		return fmt.Errorf("merge_queue.test_timeout_seconds must be >= 0")
+	}
+	return nil
+}
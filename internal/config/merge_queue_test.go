@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestMergeQueueConfig(t *testing.T) {
+	cfg := Default()
+	if _, err := toml.Decode(`
+[merge_queue]
+target = "integration"
+test_command = "make test"
+notify = "mail"
+`, cfg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	mq := cfg.MergeQueue
+	if mq.Target != "integration" || mq.TestCommand != "make test" || mq.Notify != "mail" || mq.TestTimeoutSeconds != 1800 {
+		t.Errorf("merge_queue = %+v", mq)
+	}
+	if err := ValidateMergeQueueConfig(&mq); err != nil {
+		t.Errorf("ValidateMergeQueueConfig: %v", err)
+	}
+
+	for _, bad := range []MergeQueueConfig{{Notify: "slack"}, {TestTimeoutSeconds: -1}} {
+		if err := ValidateMergeQueueConfig(&bad); err == nil {
+			t.Errorf("ValidateMergeQueueConfig(%+v) = nil, want error", bad)
+		}
+	}
+}
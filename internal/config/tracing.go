@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TracingConfig controls OTLP trace export. The standard OTEL_EXPORTER_OTLP_*
+// environment variables and NTM_TRACE_FILE override these settings.
+//
+//	[tracing]
+//	endpoint = "http://localhost:4318"
+//	protocol = "http/protobuf"
+//	file = "~/.local/share/ntm/traces.jsonl"
+//
+//	[tracing.headers]
+//	authorization = "Bearer ..."
+type TracingConfig struct {
+	Enabled        bool              `toml:"enabled"`         // Master toggle; endpoint/file are ignored when false
+	Endpoint       string            `toml:"endpoint"`        // OTLP/HTTP collector base URL
+	Protocol       string            `toml:"protocol"`        // http/protobuf (default) or http/json
+	Headers        map[string]string `toml:"headers"`         // Extra headers sent with each export
+	File           string            `toml:"file"`            // Append OTLP/JSON batches to this file
+	ServiceName    string            `toml:"service_name"`    // service.name resource attribute (default: ntm)
+	TimeoutSeconds int               `toml:"timeout_seconds"` // Per-export timeout, default: 10
+}
+
+// DefaultTracingConfig returns the default tracing settings (enabled, but with
+// no destination, so nothing is exported until one is configured).
+func DefaultTracingConfig() TracingConfig {
+	return TracingConfig{
+		Enabled:        true,
+		Protocol:       "http/protobuf",
+		TimeoutSeconds: 10,
+	}
+}
+
+// ValidateTracingConfig checks the protocol, endpoint scheme and timeout.
+func ValidateTracingConfig(c *TracingConfig) error {
+	switch c.Protocol {
+	case "", "http/protobuf", "http/json":
+	default:
+		return fmt.Errorf("tracing.protocol must be http/protobuf or http/json, got %q", c.Protocol)
+	}
+	if c.Endpoint != "" && !strings.HasPrefix(c.Endpoint, "http://") && !strings.HasPrefix(c.Endpoint, "https://") {
+		return fmt.Errorf("tracing.endpoint must be an http(s) URL, got %q", c.Endpoint)
+	}
+	if c.TimeoutSeconds < 0 {
+		return fmt.Errorf("tracing.timeout_seconds must be >= 0")
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestTracingConfigTOML(t *testing.T) {
+	data := `
+[tracing]
+endpoint = "http://localhost:4318"
+protocol = "http/json"
+
+[tracing.headers]
+authorization = "Bearer t"
+`
+	cfg := Default()
+	if _, err := toml.Decode(data, cfg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !cfg.Tracing.Enabled || cfg.Tracing.Endpoint != "http://localhost:4318" || cfg.Tracing.Protocol != "http/json" {
+		t.Errorf("tracing = %+v", cfg.Tracing)
+	}
+	if cfg.Tracing.Headers["authorization"] != "Bearer t" || cfg.Tracing.TimeoutSeconds != 10 {
+		t.Errorf("headers/timeout = %v/%d", cfg.Tracing.Headers, cfg.Tracing.TimeoutSeconds)
+	}
+	if err := ValidateTracingConfig(&cfg.Tracing); err != nil {
+		t.Errorf("ValidateTracingConfig: %v", err)
+	}
+}
+
+func TestValidateTracingConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  TracingConfig
+	}{
+		{"grpc protocol", TracingConfig{Protocol: "grpc"}},
+		{"no scheme", TracingConfig{Endpoint: "localhost:4318"}},
+		{"negative timeout", TracingConfig{TimeoutSeconds: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTracingConfig(&tt.cfg); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
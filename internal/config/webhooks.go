@@ -352,6 +352,7 @@ func isRecognizedWebhookEvent(ev string) bool {
 		"agent.idle",
 		"agent.busy",
 		"agent.rate_limit",
+		"agent.approval",
 		"agent.completed",
 		"rotation.needed",
 		"session.created",
@@ -360,7 +361,9 @@ func isRecognizedWebhookEvent(ev string) bool {
 		"bead.assigned",
 		"bead.completed",
 		"bead.failed",
-		"health.degraded":
+		"health.degraded",
+		"budget.warning",
+		"budget.exceeded":
 		return true
 	default:
 		return false
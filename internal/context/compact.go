@@ -5,6 +5,7 @@ package context
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -121,10 +122,28 @@ func GetAgentCapabilities(agentType string) AgentCapabilities {
 			HistoryClearCommand:    "",
 		}
 	default:
-		return AgentCapabilities{}
+		pluginCapabilitiesMu.RLock()
+		defer pluginCapabilitiesMu.RUnlock()
+		return pluginCapabilities[agentType]
 	}
 }
 
+var (
+	pluginCapabilitiesMu sync.RWMutex
+	pluginCapabilities   = map[string]AgentCapabilities{}
+)
+
+// RegisterAgentCapabilities sets the compaction capabilities of a plugin
+// agent type. Built-in agent types cannot be overridden.
+func RegisterAgentCapabilities(agentType string, caps AgentCapabilities) {
+	caps.SupportsBuiltinCompact = caps.BuiltinCompactCommand != ""
+	caps.SupportsHistoryClear = caps.HistoryClearCommand != ""
+
+	pluginCapabilitiesMu.Lock()
+	defer pluginCapabilitiesMu.Unlock()
+	pluginCapabilities[strings.ToLower(agentType)] = caps
+}
+
 // CompactionPromptTemplate is the prompt for requesting a summarization compaction.
 const CompactionPromptTemplate = `[System Context Management]
 
@@ -55,9 +55,30 @@ var ContextLimits = map[string]int64{
 	"default": 128000,
 }
 
+var (
+	pluginContextLimitsMu sync.RWMutex
+	pluginContextLimits   = map[string]int64{}
+)
+
+// RegisterContextLimit sets the context window of a plugin agent type.
+// Panes of plugin agents are looked up by agent type, so GetContextLimit
+// resolves the name to this limit. Safe to call while limits are read.
+func RegisterContextLimit(agentType string, limit int64) {
+	pluginContextLimitsMu.Lock()
+	defer pluginContextLimitsMu.Unlock()
+	pluginContextLimits[strings.ToLower(agentType)] = limit
+}
+
 // GetContextLimit returns the context limit for a model.
 // Returns the default limit if the model is not found.
 func GetContextLimit(model string) int64 {
+	pluginContextLimitsMu.RLock()
+	limit, ok := pluginContextLimits[strings.ToLower(model)]
+	pluginContextLimitsMu.RUnlock()
+	if ok {
+		return limit
+	}
+
 	// Try exact match first
 	if limit, ok := ContextLimits[model]; ok {
 		return limit
@@ -102,6 +123,7 @@ func normalizeModelName(model string) string {
 type EstimationMethod string
 
 const (
+	MethodTranscript       EstimationMethod = "transcript"        // Exact usage from the agent's session log
 	MethodRobotMode        EstimationMethod = "robot_mode"        // Direct report from agent
 	MethodMessageCount     EstimationMethod = "message_count"     // Estimated from message count
 	MethodCumulativeTokens EstimationMethod = "cumulative_tokens" // Sum of input+output tokens
@@ -136,6 +158,9 @@ type ContextState struct {
 	// Internal tracking
 	cumulativeInputTokens  int64
 	cumulativeOutputTokens int64
+	transcriptTokens       int64 // Context size reported by the transcript's last turn
+	transcriptWindow       int64 // Window size reported by the agent, 0 if unknown
+	transcriptUpdated      time.Time
 }
 
 // ContextEstimator defines the interface for estimation strategies.
@@ -150,6 +175,38 @@ type ContextEstimator interface {
 	Name() string
 }
 
+// TranscriptEstimator reports the exact context size read from the agent's
+// own session log (see internal/transcript).
+type TranscriptEstimator struct{}
+
+// Name returns the estimator name.
+func (e *TranscriptEstimator) Name() string { return "transcript" }
+
+// Confidence returns the base confidence for this strategy.
+func (e *TranscriptEstimator) Confidence() float64 { return 0.98 }
+
+// Estimate returns the last transcript-reported context size.
+func (e *TranscriptEstimator) Estimate(state *ContextState) (*ContextEstimate, error) {
+	if state.transcriptTokens <= 0 {
+		return nil, nil
+	}
+
+	contextLimit := state.transcriptWindow
+	if contextLimit <= 0 {
+		contextLimit = GetContextLimit(state.Model)
+	}
+
+	return &ContextEstimate{
+		TokensUsed:   state.transcriptTokens,
+		ContextLimit: contextLimit,
+		UsagePercent: float64(state.transcriptTokens) / float64(contextLimit) * 100,
+		Confidence:   e.Confidence(),
+		Method:       MethodTranscript,
+		Model:        state.Model,
+		UpdatedAt:    state.transcriptUpdated,
+	}, nil
+}
+
 // RobotModeEstimator parses context info from robot mode output.
 type RobotModeEstimator struct{}
 
@@ -395,6 +452,7 @@ func NewContextMonitor(cfg MonitorConfig) *ContextMonitor {
 
 	return &ContextMonitor{
 		estimators: []ContextEstimator{
+			&TranscriptEstimator{},
 			&RobotModeEstimator{},
 			&CumulativeTokenEstimator{CompactionDiscount: 0.7},
 			&MessageCountEstimator{TokensPerMessage: cfg.TokensPerMessage},
@@ -472,6 +530,30 @@ func (m *ContextMonitor) RecordMessage(agentID string, inputTokens, outputTokens
 	state.LastActivity = time.Now()
 }
 
+// UpdateFromUsage records exact token usage read from the agent's transcript.
+// contextTokens is the context size as of the last turn and window the limit
+// reported by the agent (0 to use the model's known limit). Estimates then
+// come from the transcript until the agent is reset.
+func (m *ContextMonitor) UpdateFromUsage(agentID, model string, contextTokens, window, inputTokens, outputTokens int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, exists := m.states[agentID]
+	if !exists {
+		return
+	}
+
+	if model != "" {
+		state.Model = model
+	}
+	state.transcriptTokens = contextTokens
+	state.transcriptWindow = window
+	state.transcriptUpdated = time.Now()
+	state.cumulativeInputTokens = inputTokens
+	state.cumulativeOutputTokens = outputTokens
+	state.LastActivity = state.transcriptUpdated
+}
+
 // UpdateFromRobotMode updates context estimate from robot mode output.
 func (m *ContextMonitor) UpdateFromRobotMode(agentID, output string) {
 	m.mu.Lock()
@@ -619,6 +701,8 @@ func (m *ContextMonitor) ResetAgent(agentID string) {
 		state.MessageCount = 0
 		state.cumulativeInputTokens = 0
 		state.cumulativeOutputTokens = 0
+		state.transcriptTokens = 0
+		state.transcriptWindow = 0
 		state.SessionStart = time.Now()
 		state.Estimate = nil
 	}
@@ -292,6 +292,30 @@ func TestContextMonitor_GetEstimate(t *testing.T) {
 	}
 }
 
+func TestContextMonitor_UpdateFromUsage(t *testing.T) {
+	t.Parallel()
+
+	monitor := NewContextMonitor(DefaultMonitorConfig())
+	monitor.RegisterAgent("agent-1", "pane-1", "claude-opus-4")
+	for i := 0; i < 50; i++ {
+		monitor.RecordMessage("agent-1", 500, 1000)
+	}
+	monitor.UpdateFromUsage("agent-1", "gpt-5-codex", 68000, 272000, 900000, 20000)
+
+	estimate := monitor.GetEstimate("agent-1")
+	if estimate == nil || estimate.Method != MethodTranscript {
+		t.Fatalf("GetEstimate() = %+v, want transcript method", estimate)
+	}
+	if estimate.TokensUsed != 68000 || estimate.ContextLimit != 272000 || estimate.UsagePercent != 25 {
+		t.Errorf("estimate = %+v", estimate)
+	}
+
+	monitor.ResetAgent("agent-1")
+	if estimate := monitor.GetEstimate("agent-1"); estimate != nil && estimate.Method == MethodTranscript {
+		t.Errorf("transcript estimate survived reset: %+v", estimate)
+	}
+}
+
 func TestContextMonitor_AgentsAboveThreshold(t *testing.T) {
 	t.Parallel()
 
@@ -91,7 +91,7 @@ func (m *AgentMonitor) GetAgentStatusWithOutput(paneID, paneName, agentType stri
 	}
 
 	// 1. Static analysis using UnifiedDetector
-	agentStatus := m.detector.Analyze(paneID, paneName, agentType, output, lastActivity)
+	agentStatus := m.detector.Analyze(m.session, paneID, paneName, agentType, output, lastActivity)
 	
 	// Map status.AgentState to robot.AgentState
 	result.Status = mapStatusToRobotState(agentStatus.State)
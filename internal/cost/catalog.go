@@ -0,0 +1,576 @@
+package cost
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+//go:embed pricing.toml
+var defaultCatalogTOML []byte
+
+// SourceEmbedded names the catalog compiled into ntm.
+const SourceEmbedded = "embedded"
+
+const effectiveLayout = "2006-01-02"
+
+// PriceEntry is one model's prices from an effective date on. Prices are USD
+// per million tokens, as providers publish them.
+type PriceEntry struct {
+	Model          string    `json:"model"`
+	Provider       string    `json:"provider,omitempty"`
+	Effective      time.Time `json:"effective,omitempty"` // zero: always in effect
+	InputPerM      float64   `json:"input_per_m"`
+	OutputPerM     float64   `json:"output_per_m"`
+	CacheReadPerM  float64   `json:"cache_read_per_m,omitempty"`
+	CacheWritePerM float64   `json:"cache_write_per_m,omitempty"`
+	BatchDiscount  float64   `json:"batch_discount,omitempty"`
+	Source         string    `json:"source"`
+}
+
+// Pricing converts the entry to per-1K prices.
+func (e PriceEntry) Pricing() ModelPricing {
+	return ModelPricing{
+		InputPer1K:      e.InputPerM / 1000,
+		OutputPer1K:     e.OutputPerM / 1000,
+		CacheReadPer1K:  e.CacheReadPerM / 1000,
+		CacheWritePer1K: e.CacheWritePerM / 1000,
+		BatchDiscount:   e.BatchDiscount,
+	}
+}
+
+// Provider groups models for aliasing and for pricing unknown variants.
+type Provider struct {
+	Prefixes []string          `json:"prefixes,omitempty"` // model name prefixes the provider owns
+	Default  string            `json:"default,omitempty"`  // model priced for unknown names with a prefix
+	Aliases  map[string]string `json:"aliases,omitempty"`  // short name -> model
+}
+
+// Catalog is a merged set of model prices. The zero value is not usable;
+// build one with DefaultCatalog, ParseCatalog or LoadCatalog.
+type Catalog struct {
+	Fallback  string                  `json:"fallback"`
+	Providers map[string]*Provider    `json:"providers"`
+	Models    map[string][]PriceEntry `json:"models"` // per model, sorted by effective date
+	Sources   []string                `json:"sources"`
+
+	hash []byte
+}
+
+type catalogFile struct {
+	Fallback  string                  `toml:"fallback"`
+	Providers map[string]providerFile `toml:"providers"`
+	Models    []modelFile             `toml:"models"`
+}
+
+type providerFile struct {
+	Prefixes []string          `toml:"prefixes"`
+	Default  string            `toml:"default"`
+	Aliases  map[string]string `toml:"aliases"`
+}
+
+type modelFile struct {
+	Name          string        `toml:"name"`
+	Provider      string        `toml:"provider"`
+	Effective     effectiveDate `toml:"effective"`
+	Input         *float64      `toml:"input"`
+	Output        *float64      `toml:"output"`
+	CacheRead     float64       `toml:"cache_read"`
+	CacheWrite    float64       `toml:"cache_write"`
+	BatchDiscount float64       `toml:"batch_discount"`
+}
+
+// effectiveDate accepts both "2025-06-01" and the bare TOML date 2025-06-01.
+type effectiveDate struct{ time.Time }
+
+// UnmarshalTOML implements toml.Unmarshaler.
+func (d *effectiveDate) UnmarshalTOML(data interface{}) error {
+	switch v := data.(type) {
+	case time.Time:
+		d.Time = time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.UTC)
+	case string:
+		t, err := time.Parse(effectiveLayout, v)
+		if err != nil {
+			return fmt.Errorf("effective date %q: want YYYY-MM-DD", v)
+		}
+		d.Time = t
+	default:
+		return fmt.Errorf("effective date: unexpected %T", data)
+	}
+	return nil
+}
+
+// ParseCatalog parses and validates one catalog file. Every problem found is
+// reported, not just the first.
+func ParseCatalog(data []byte, source string) (*Catalog, error) {
+	var f catalogFile
+	md, err := toml.NewDecoder(bytes.NewReader(data)).Decode(&f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	var errs []error
+	for _, key := range md.Undecoded() {
+		errs = append(errs, fmt.Errorf("unknown key %q", key.String()))
+	}
+
+	c := newCatalog()
+	c.Fallback = strings.ToLower(strings.TrimSpace(f.Fallback))
+	for name, p := range f.Providers {
+		prov := &Provider{Default: strings.ToLower(p.Default), Aliases: map[string]string{}}
+		for _, prefix := range p.Prefixes {
+			prov.Prefixes = append(prov.Prefixes, strings.ToLower(prefix))
+		}
+		for alias, target := range p.Aliases {
+			prov.Aliases[strings.ToLower(alias)] = strings.ToLower(target)
+		}
+		c.Providers[strings.ToLower(name)] = prov
+	}
+
+	seen := make(map[string]bool)
+	for i, m := range f.Models {
+		name := strings.ToLower(strings.TrimSpace(m.Name))
+		where := fmt.Sprintf("models[%d]", i)
+		if name != "" {
+			where = fmt.Sprintf("model %q", name)
+		}
+		switch {
+		case name == "":
+			errs = append(errs, fmt.Errorf("%s: name is required", where))
+			continue
+		case m.Input == nil || m.Output == nil:
+			errs = append(errs, fmt.Errorf("%s: input and output prices are required", where))
+			continue
+		}
+		entry := PriceEntry{
+			Model:          name,
+			Provider:       strings.ToLower(m.Provider),
+			Effective:      m.Effective.Time,
+			InputPerM:      *m.Input,
+			OutputPerM:     *m.Output,
+			CacheReadPerM:  m.CacheRead,
+			CacheWritePerM: m.CacheWrite,
+			BatchDiscount:  m.BatchDiscount,
+			Source:         source,
+		}
+		if entry.InputPerM < 0 || entry.OutputPerM < 0 || entry.CacheReadPerM < 0 || entry.CacheWritePerM < 0 {
+			errs = append(errs, fmt.Errorf("%s: prices must not be negative", where))
+		}
+		if entry.BatchDiscount < 0 || entry.BatchDiscount >= 1 {
+			errs = append(errs, fmt.Errorf("%s: batch_discount must be in [0, 1)", where))
+		}
+		key := name + "@" + entry.effectiveString()
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("%s: duplicate entry for effective date %s", where, entry.effectiveString()))
+		}
+		seen[key] = true
+		c.Models[name] = append(c.Models[name], entry)
+	}
+	for name := range c.Models {
+		sortEntries(c.Models[name])
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	c.Sources = []string{source}
+	c.hash = hashSources(nil, source, data)
+	return c, nil
+}
+
+// DefaultCatalog returns the catalog compiled into ntm.
+func DefaultCatalog() *Catalog {
+	c, err := ParseCatalog(defaultCatalogTOML, SourceEmbedded)
+	if err != nil {
+		panic(fmt.Sprintf("embedded pricing catalog: %v", err))
+	}
+	return c
+}
+
+// UserCatalogPath returns ~/.config/ntm/pricing.toml (honoring
+// XDG_CONFIG_HOME).
+func UserCatalogPath() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "ntm", "pricing.toml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, ".config", "ntm", "pricing.toml")
+}
+
+// ProjectCatalogPath returns the project override in dir/.ntm/pricing.toml.
+func ProjectCatalogPath(dir string) string {
+	return filepath.Join(dir, ".ntm", "pricing.toml")
+}
+
+// CatalogPaths returns the override files layered over the embedded
+// catalog, in merge order.
+func CatalogPaths(projectDir string) []string {
+	paths := []string{UserCatalogPath()}
+	if projectDir != "" {
+		paths = append(paths, ProjectCatalogPath(projectDir))
+	}
+	return paths
+}
+
+// LoadCatalog merges the embedded catalog with the user and project
+// overrides. Missing files are skipped. A file that fails to parse or that
+// breaks the merged catalog is left out and reported in the error, so the
+// returned catalog is always usable.
+func LoadCatalog(projectDir string) (*Catalog, error) {
+	c := DefaultCatalog()
+	var errs []error
+	for _, path := range CatalogPaths(projectDir) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("read pricing catalog: %w", err))
+			}
+			continue
+		}
+		merged, err := c.MergeFile(data, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c = merged
+	}
+	return c, errors.Join(errs...)
+}
+
+// MergeFile parses data and layers it over c, returning the merged catalog.
+// c is not modified.
+func (c *Catalog) MergeFile(data []byte, source string) (*Catalog, error) {
+	layer, err := ParseCatalog(data, source)
+	if err != nil {
+		return nil, err
+	}
+	merged := c.Merge(layer)
+	if err := merged.Check(); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	return merged, nil
+}
+
+// Merge returns c with layer applied on top: entries with the same model and
+// effective date are replaced, provider prefixes and defaults are replaced
+// when set, and aliases are merged.
+func (c *Catalog) Merge(layer *Catalog) *Catalog {
+	out := newCatalog()
+	out.Fallback = c.Fallback
+	if layer.Fallback != "" {
+		out.Fallback = layer.Fallback
+	}
+	for name, p := range c.Providers {
+		out.Providers[name] = p.clone()
+	}
+	for name, lp := range layer.Providers {
+		p, ok := out.Providers[name]
+		if !ok {
+			p = &Provider{Aliases: map[string]string{}}
+			out.Providers[name] = p
+		}
+		if len(lp.Prefixes) > 0 {
+			p.Prefixes = append([]string(nil), lp.Prefixes...)
+		}
+		if lp.Default != "" {
+			p.Default = lp.Default
+		}
+		for alias, target := range lp.Aliases {
+			p.Aliases[alias] = target
+		}
+	}
+	for name, entries := range c.Models {
+		out.Models[name] = append([]PriceEntry(nil), entries...)
+	}
+	for name, entries := range layer.Models {
+		for _, e := range entries {
+			replaced := false
+			for i, existing := range out.Models[name] {
+				if existing.Effective.Equal(e.Effective) {
+					out.Models[name][i] = e
+					replaced = true
+				}
+			}
+			if !replaced {
+				out.Models[name] = append(out.Models[name], e)
+			}
+		}
+		sortEntries(out.Models[name])
+	}
+	out.Sources = append(append([]string(nil), c.Sources...), layer.Sources...)
+	out.hash = hashSources(c.hash, layer.Sources[0], layer.hash)
+	return out
+}
+
+// Check validates references across the merged catalog: the fallback,
+// provider defaults and alias targets must all name priced models.
+func (c *Catalog) Check() error {
+	var errs []error
+	if c.Fallback == "" {
+		errs = append(errs, errors.New("fallback model is not set"))
+	} else if _, ok := c.Models[c.Fallback]; !ok {
+		errs = append(errs, fmt.Errorf("fallback %q is not a priced model", c.Fallback))
+	}
+	for _, name := range c.providerNames() {
+		p := c.Providers[name]
+		if p.Default != "" {
+			if _, ok := c.Models[p.Default]; !ok {
+				errs = append(errs, fmt.Errorf("provider %q: default %q is not a priced model", name, p.Default))
+			}
+		}
+		aliases := make([]string, 0, len(p.Aliases))
+		for alias := range p.Aliases {
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases)
+		for _, alias := range aliases {
+			if _, ok := c.Models[p.Aliases[alias]]; !ok {
+				errs = append(errs, fmt.Errorf("provider %q: alias %q points at unpriced model %q", name, alias, p.Aliases[alias]))
+			}
+		}
+	}
+	for _, name := range c.ModelNames() {
+		for _, e := range c.Models[name] {
+			if e.Provider != "" && c.Providers[e.Provider] == nil {
+				errs = append(errs, fmt.Errorf("model %q: unknown provider %q", name, e.Provider))
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Version identifies the catalog's contents. It changes whenever any layer
+// does.
+func (c *Catalog) Version() string {
+	return hex.EncodeToString(c.hash)[:12]
+}
+
+// ModelNames returns the priced model names, sorted.
+func (c *Catalog) ModelNames() []string {
+	names := make([]string, 0, len(c.Models))
+	for name := range c.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the catalog model whose prices apply to model. Lookups try,
+// in order: the exact name, the name without a date suffix, provider aliases,
+// the longest priced prefix, the owning provider's default and the catalog
+// fallback. A "provider/model" name restricts aliases and the default to that
+// provider.
+func (c *Catalog) Resolve(model string) string {
+	provider := ""
+	if i := strings.Index(model, "/"); i > 0 {
+		if _, ok := c.Providers[strings.ToLower(model[:i])]; ok {
+			provider, model = strings.ToLower(model[:i]), model[i+1:]
+		}
+	}
+	if _, ok := c.Models[model]; ok {
+		return model
+	}
+	normalized := normalizeModelName(model)
+	if _, ok := c.Models[normalized]; ok {
+		return normalized
+	}
+
+	providers := c.providerNames()
+	if provider != "" {
+		providers = []string{provider}
+	}
+	for _, name := range providers {
+		if target, ok := c.Providers[name].Aliases[normalized]; ok {
+			return target
+		}
+	}
+
+	best := ""
+	for name := range c.Models {
+		if name != c.Fallback && strings.HasPrefix(normalized, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best != "" {
+		return best
+	}
+
+	for _, name := range providers {
+		p := c.Providers[name]
+		if p.Default == "" {
+			continue
+		}
+		if provider != "" {
+			return p.Default
+		}
+		for _, prefix := range p.Prefixes {
+			if strings.HasPrefix(normalized, prefix) {
+				return p.Default
+			}
+		}
+	}
+	return c.Fallback
+}
+
+// Entry returns the price entry for model in effect at the given time. When
+// every entry starts later, the earliest one applies.
+func (c *Catalog) Entry(model string, at time.Time) (PriceEntry, bool) {
+	entries := c.Models[c.Resolve(model)]
+	if len(entries) == 0 {
+		return PriceEntry{}, false
+	}
+	chosen := entries[0]
+	for _, e := range entries[1:] {
+		if e.Effective.After(at) {
+			break
+		}
+		chosen = e
+	}
+	return chosen, true
+}
+
+// Lookup returns the per-1K pricing for model at the given time.
+func (c *Catalog) Lookup(model string, at time.Time) ModelPricing {
+	e, ok := c.Entry(model, at)
+	if !ok {
+		return ModelPricing{}
+	}
+	return e.Pricing()
+}
+
+func (c *Catalog) providerNames() []string {
+	names := make([]string, 0, len(c.Providers))
+	for name := range c.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *Provider) clone() *Provider {
+	out := &Provider{
+		Prefixes: append([]string(nil), p.Prefixes...),
+		Default:  p.Default,
+		Aliases:  make(map[string]string, len(p.Aliases)),
+	}
+	for k, v := range p.Aliases {
+		out.Aliases[k] = v
+	}
+	return out
+}
+
+func (e PriceEntry) effectiveString() string {
+	if e.Effective.IsZero() {
+		return "always"
+	}
+	return e.Effective.Format(effectiveLayout)
+}
+
+func newCatalog() *Catalog {
+	return &Catalog{
+		Providers: make(map[string]*Provider),
+		Models:    make(map[string][]PriceEntry),
+	}
+}
+
+func sortEntries(entries []PriceEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Effective.Before(entries[j].Effective)
+	})
+}
+
+func hashSources(prev []byte, source string, data []byte) []byte {
+	h := sha256.New()
+	h.Write(prev)
+	h.Write([]byte(source))
+	h.Write([]byte{0})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// ============================================================================
+// Active catalog
+// ============================================================================
+
+var (
+	catalogMu     sync.RWMutex
+	activeCatalog *Catalog
+)
+
+// ActiveCatalog returns the catalog GetModelPricing and AgentCost use. On
+// first use it loads the embedded catalog with the user override and the
+// override of the current directory's project; files that fail to load are
+// skipped ('ntm cost pricing validate' reports why).
+func ActiveCatalog() *Catalog {
+	catalogMu.RLock()
+	c := activeCatalog
+	catalogMu.RUnlock()
+	if c != nil {
+		return c
+	}
+
+	dir, _ := os.Getwd()
+	loaded, _ := LoadCatalog(dir)
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	if activeCatalog == nil {
+		activeCatalog = loaded
+	}
+	return activeCatalog
+}
+
+// SetCatalog replaces the active catalog. Costs are derived from stored token
+// counts on every read, so every tracker reprices immediately.
+func SetCatalog(c *Catalog) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	activeCatalog = c
+}
+
+// WatchCatalog reloads the catalog for projectDir every interval and makes it
+// active when its version changes, calling onChange (if set) with the new
+// catalog. It returns when ctx is done.
+func WatchCatalog(ctx context.Context, projectDir string, interval time.Duration, onChange func(*Catalog)) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if c, changed := ReloadCatalog(projectDir); changed && onChange != nil {
+				onChange(c)
+			}
+		}
+	}
+}
+
+// ReloadCatalog loads the catalog for projectDir and activates it if it
+// differs from the active one.
+func ReloadCatalog(projectDir string) (*Catalog, bool) {
+	c, _ := LoadCatalog(projectDir)
+	if c.Version() == ActiveCatalog().Version() {
+		return c, false
+	}
+	SetCatalog(c)
+	return c, true
+}
@@ -0,0 +1,230 @@
+package cost
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultCatalogIsValid(t *testing.T) {
+	c := DefaultCatalog()
+	if err := c.Check(); err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+	if len(c.Sources) != 1 || c.Sources[0] != SourceEmbedded {
+		t.Errorf("Sources = %v", c.Sources)
+	}
+}
+
+func TestCatalogResolve(t *testing.T) {
+	c := DefaultCatalog()
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"claude-sonnet-4", "claude-sonnet-4"},
+		{"claude-opus-4-5-20251101", "claude-opus-4-5"},
+		{"claude-opus-4-1-20250805", "claude-opus-4"},
+		{"opus", "claude-opus-4"},
+		{"anthropic/sonnet", "claude-sonnet-4"},
+		{"claude-unreleased", "claude-sonnet-4"}, // provider default
+		{"gpt-7", "gpt-5"},
+		{"openai/unheard-of", "gpt-5"},
+		{"codex", "gpt-5-codex"},
+		{"unknown-model", "default"},
+	}
+	for _, tt := range tests {
+		if got := c.Resolve(tt.model); got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogMergeEffectiveDates(t *testing.T) {
+	override := `
+[providers.anthropic]
+aliases = { big = "claude-opus-4" }
+
+[[models]]
+name = "claude-opus-4"
+provider = "anthropic"
+effective = 2026-03-01
+input = 5.0
+output = 25.0
+
+[[models]]
+name = "claude-sonnet-4"
+provider = "anthropic"
+input = 2.0
+output = 10.0
+`
+	base := DefaultCatalog()
+	c, err := base.MergeFile([]byte(override), "override.toml")
+	if err != nil {
+		t.Fatalf("MergeFile: %v", err)
+	}
+	if c.Version() == base.Version() {
+		t.Error("Version() unchanged after merge")
+	}
+
+	before := time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)
+	after := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	if p := c.Lookup("big", before); p.InputPer1K != 0.015 {
+		t.Errorf("opus before effective date = %+v", p)
+	}
+	if p := c.Lookup("big", after); p.InputPer1K != 0.005 || p.OutputPer1K != 0.025 {
+		t.Errorf("opus after effective date = %+v", p)
+	}
+	// An undated entry replaces the undated default.
+	if e, _ := c.Entry("claude-sonnet-4", before); e.InputPerM != 2 || e.Source != "override.toml" {
+		t.Errorf("sonnet entry = %+v", e)
+	}
+	if len(c.Models["claude-sonnet-4"]) != 1 {
+		t.Errorf("sonnet history = %+v", c.Models["claude-sonnet-4"])
+	}
+	// The base catalog is untouched.
+	if p := base.Lookup("claude-sonnet-4", after); p.InputPer1K != 0.003 {
+		t.Errorf("base sonnet = %+v", p)
+	}
+}
+
+func TestParseCatalogErrors(t *testing.T) {
+	bad := `
+fallback = "default"
+colour = "blue"
+
+[[models]]
+name = "a"
+input = -1.0
+output = 2.0
+batch_discount = 1.5
+
+[[models]]
+name = "b"
+input = 1.0
+
+[[models]]
+name = "c"
+effective = "June"
+input = 1.0
+output = 1.0
+`
+	_, err := ParseCatalog([]byte(bad), "bad.toml")
+	if err == nil {
+		t.Fatal("ParseCatalog succeeded, want error")
+	}
+	// A malformed date aborts decoding; fix it to see the other problems.
+	if !strings.Contains(err.Error(), "YYYY-MM-DD") {
+		t.Errorf("error = %v", err)
+	}
+	_, err = ParseCatalog([]byte(strings.Replace(bad, `"June"`, `"2026-06-01"`, 1)), "bad.toml")
+	for _, want := range []string{"bad.toml", `unknown key "colour"`, "must not be negative", "batch_discount", `model "b": input and output`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want it to mention %q", err, want)
+		}
+	}
+
+	_, err = DefaultCatalog().MergeFile([]byte(`
+[providers.anthropic]
+aliases = { tiny = "claude-nano" }
+`), "aliases.toml")
+	if err == nil || !strings.Contains(err.Error(), `alias "tiny"`) {
+		t.Errorf("dangling alias error = %v", err)
+	}
+}
+
+func TestLoadCatalogLayers(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	project := t.TempDir()
+
+	write := func(path, body string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(UserCatalogPath(), "[[models]]\nname = \"gpt-4o\"\nprovider = \"openai\"\ninput = 2.5\noutput = 10.0\n")
+	write(ProjectCatalogPath(project), "[[models]]\nname = \"gpt-4o\"\nprovider = \"openai\"\ninput = 1.0\noutput = 4.0\n")
+
+	c, err := LoadCatalog(project)
+	if err != nil {
+		t.Fatalf("LoadCatalog: %v", err)
+	}
+	if p := c.Lookup("gpt-4o", time.Now()); p.InputPer1K != 0.001 {
+		t.Errorf("gpt-4o = %+v, want the project price", p)
+	}
+	if len(c.Sources) != 3 {
+		t.Errorf("Sources = %v", c.Sources)
+	}
+
+	// A broken project file is skipped and reported.
+	write(ProjectCatalogPath(project), "[[models]]\nname = \"gpt-4o\"\n")
+	c, err = LoadCatalog(project)
+	if err == nil {
+		t.Error("LoadCatalog succeeded with a broken project file")
+	}
+	if p := c.Lookup("gpt-4o", time.Now()); p.InputPer1K != 0.0025 {
+		t.Errorf("gpt-4o = %+v, want the user price", p)
+	}
+}
+
+func TestCostTrackerRepricesOnCatalogChange(t *testing.T) {
+	t.Cleanup(func() { SetCatalog(nil) })
+	SetCatalog(DefaultCatalog())
+
+	tracker := NewCostTracker("")
+	tracker.RecordTokens("s", "s__cc_1", "claude-sonnet-4", 1000, 1000)
+	if got := tracker.GetSessionCost("s"); !floatEquals(got, 0.018) {
+		t.Fatalf("cost = %v, want 0.018", got)
+	}
+
+	cheaper, err := DefaultCatalog().MergeFile([]byte(`
+[[models]]
+name = "claude-sonnet-4"
+provider = "anthropic"
+input = 1.0
+output = 5.0
+`), "cheaper.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetCatalog(cheaper)
+	if got := tracker.GetSessionCost("s"); !floatEquals(got, 0.006) {
+		t.Errorf("cost after repricing = %v, want 0.006", got)
+	}
+
+	// Usage last updated before a dated price keeps the older price.
+	dated, err := DefaultCatalog().MergeFile([]byte(`
+[[models]]
+name = "claude-sonnet-4"
+provider = "anthropic"
+effective = "2999-01-01"
+input = 1.0
+output = 5.0
+`), "future.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetCatalog(dated)
+	if got := tracker.GetSessionCost("s"); !floatEquals(got, 0.018) {
+		t.Errorf("cost with future price = %v, want 0.018", got)
+	}
+}
+
+func TestModelPricingBatch(t *testing.T) {
+	p := DefaultCatalog().Lookup("claude-sonnet-4", time.Now()).Batch()
+	if !floatEquals(p.InputPer1K, 0.0015) || !floatEquals(p.OutputPer1K, 0.0075) || !floatEquals(p.CacheReadPrice(), 0.00015) {
+		t.Errorf("Batch() = %+v", p)
+	}
+}
+
+func floatEquals(a, b float64) bool {
+	d := a - b
+	return d < 1e-12 && d > -1e-12
+}
@@ -7,7 +7,6 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -16,65 +15,79 @@ import (
 )
 
 // ModelPricing defines the cost per 1K tokens for input and output.
+// Cached input defaults to 10% (reads) and 125% (writes) of the input price
+// when no cache price is set.
 type ModelPricing struct {
-	InputPer1K  float64 `json:"input_per_1k"`
-	OutputPer1K float64 `json:"output_per_1k"`
+	InputPer1K      float64 `json:"input_per_1k"`
+	OutputPer1K     float64 `json:"output_per_1k"`
+	CacheReadPer1K  float64 `json:"cache_read_per_1k,omitempty"`
+	CacheWritePer1K float64 `json:"cache_write_per_1k,omitempty"`
+	BatchDiscount   float64 `json:"batch_discount,omitempty"` // fraction off for batch requests
 }
 
-// modelPricing contains pricing data for known models (USD per 1K tokens).
-// Updated as of May 2025.
-var modelPricing = map[string]ModelPricing{
-	// Claude models
-	"claude-opus":       {InputPer1K: 0.015, OutputPer1K: 0.075},
-	"claude-opus-4":     {InputPer1K: 0.015, OutputPer1K: 0.075},
-	"claude-opus-4-5":   {InputPer1K: 0.015, OutputPer1K: 0.075},
-	"claude-sonnet":     {InputPer1K: 0.003, OutputPer1K: 0.015},
-	"claude-sonnet-4":   {InputPer1K: 0.003, OutputPer1K: 0.015},
-	"claude-haiku":      {InputPer1K: 0.00025, OutputPer1K: 0.00125},
-	"claude-haiku-3-5":  {InputPer1K: 0.00025, OutputPer1K: 0.00125},
-	"claude-3-opus":     {InputPer1K: 0.015, OutputPer1K: 0.075},
-	"claude-3-sonnet":   {InputPer1K: 0.003, OutputPer1K: 0.015},
-	"claude-3-haiku":    {InputPer1K: 0.00025, OutputPer1K: 0.00125},
-	"claude-3-5-sonnet": {InputPer1K: 0.003, OutputPer1K: 0.015},
-	"claude-3-5-haiku":  {InputPer1K: 0.00025, OutputPer1K: 0.00125},
-
-	// OpenAI models
-	"gpt-4o":      {InputPer1K: 0.005, OutputPer1K: 0.015},
-	"gpt-4o-mini": {InputPer1K: 0.00015, OutputPer1K: 0.0006},
-	"gpt-4-turbo": {InputPer1K: 0.01, OutputPer1K: 0.03},
-	"gpt-4":       {InputPer1K: 0.03, OutputPer1K: 0.06},
-	"o1":          {InputPer1K: 0.015, OutputPer1K: 0.06},
-	"o1-mini":     {InputPer1K: 0.003, OutputPer1K: 0.012},
-	"o1-preview":  {InputPer1K: 0.015, OutputPer1K: 0.06},
-
-	// Google models
-	"gemini-pro":       {InputPer1K: 0.00025, OutputPer1K: 0.0005},
-	"gemini-pro-1.5":   {InputPer1K: 0.00025, OutputPer1K: 0.0005},
-	"gemini-ultra":     {InputPer1K: 0.00125, OutputPer1K: 0.00375},
-	"gemini-flash":     {InputPer1K: 0.000075, OutputPer1K: 0.0003},
-	"gemini-flash-1.5": {InputPer1K: 0.000075, OutputPer1K: 0.0003},
-	"gemini-2.0-flash": {InputPer1K: 0.000075, OutputPer1K: 0.0003},
-
-	// Default fallback
-	"default": {InputPer1K: 0.003, OutputPer1K: 0.015},
+// Batch returns the pricing for batch requests.
+func (p ModelPricing) Batch() ModelPricing {
+	f := 1 - p.BatchDiscount
+	return ModelPricing{
+		InputPer1K:      p.InputPer1K * f,
+		OutputPer1K:     p.OutputPer1K * f,
+		CacheReadPer1K:  p.CacheReadPrice() * f,
+		CacheWritePer1K: p.CacheWritePrice() * f,
+	}
+}
+
+// CacheReadPrice returns the cost per 1K cache-read tokens.
+func (p ModelPricing) CacheReadPrice() float64 {
+	if p.CacheReadPer1K > 0 {
+		return p.CacheReadPer1K
+	}
+	return p.InputPer1K * 0.1
+}
+
+// CacheWritePrice returns the cost per 1K cache-write tokens.
+func (p ModelPricing) CacheWritePrice() float64 {
+	if p.CacheWritePer1K > 0 {
+		return p.CacheWritePer1K
+	}
+	return p.InputPer1K * 1.25
 }
 
 var modelDateSuffixRegex = regexp.MustCompile(`-\d{8}$`)
 
+// SourceTranscript marks token counts read from the agent's own transcript
+// rather than estimated from text seen in the pane.
+const SourceTranscript = "transcript"
+
 // AgentCost tracks token usage for a single agent.
 type AgentCost struct {
-	InputTokens  int       `json:"input_tokens"`
-	OutputTokens int       `json:"output_tokens"`
-	Model        string    `json:"model"`
-	LastUpdated  time.Time `json:"last_updated"`
+	InputTokens      int       `json:"input_tokens"`
+	OutputTokens     int       `json:"output_tokens"`
+	CacheReadTokens  int       `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int       `json:"cache_write_tokens,omitempty"`
+	Model            string    `json:"model"`
+	Source           string    `json:"source,omitempty"` // "" (estimated) or SourceTranscript
+	LastUpdated      time.Time `json:"last_updated"`
 }
 
-// Cost calculates the USD cost for this agent.
+// Cost calculates the USD cost for this agent from its token counts, using
+// the active catalog's prices in effect when the agent was last updated.
+// Nothing is cached, so a catalog change reprices all recorded usage.
 func (a *AgentCost) Cost() float64 {
-	pricing := GetModelPricing(a.Model)
+	at := a.LastUpdated
+	if at.IsZero() {
+		at = time.Now()
+	}
+	pricing := ActiveCatalog().Lookup(a.Model, at)
 	inputCost := float64(a.InputTokens) / 1000 * pricing.InputPer1K
 	outputCost := float64(a.OutputTokens) / 1000 * pricing.OutputPer1K
-	return inputCost + outputCost
+	cacheCost := float64(a.CacheReadTokens)/1000*pricing.CacheReadPrice() +
+		float64(a.CacheWriteTokens)/1000*pricing.CacheWritePrice()
+	return inputCost + outputCost + cacheCost
+}
+
+// Exact reports whether the counts come from the agent's transcript.
+func (a *AgentCost) Exact() bool {
+	return a.Source == SourceTranscript
 }
 
 // SessionCost tracks costs for all agents in a session.
@@ -101,6 +114,16 @@ func (s *SessionCost) TotalTokens() (input, output int) {
 	return
 }
 
+// TotalCacheTokens returns total cache-read and cache-write tokens for this
+// session (known only for agents with transcripts).
+func (s *SessionCost) TotalCacheTokens() (read, write int) {
+	for _, agent := range s.Agents {
+		read += agent.CacheReadTokens
+		write += agent.CacheWriteTokens
+	}
+	return
+}
+
 // CostTracker manages cost tracking across multiple sessions.
 type CostTracker struct {
 	mu       sync.RWMutex
@@ -197,6 +220,9 @@ func (t *CostTracker) RecordPrompt(session, pane, model, prompt string) {
 	tokens := EstimateTokens(prompt)
 	s := t.getOrCreateSession(session)
 	a := s.getOrCreateAgent(pane, model)
+	if a.Exact() {
+		return // The transcript already counts this prompt.
+	}
 	a.InputTokens += tokens
 	a.LastUpdated = time.Now()
 	if model != "" && a.Model == "" {
@@ -212,6 +238,9 @@ func (t *CostTracker) RecordResponse(session, pane, model, response string) {
 	tokens := EstimateTokens(response)
 	s := t.getOrCreateSession(session)
 	a := s.getOrCreateAgent(pane, model)
+	if a.Exact() {
+		return // The transcript already counts this response.
+	}
 	a.OutputTokens += tokens
 	a.LastUpdated = time.Now()
 	if model != "" && a.Model == "" {
@@ -234,6 +263,26 @@ func (t *CostTracker) RecordTokens(session, pane, model string, inputTokens, out
 	}
 }
 
+// SetExactUsage replaces an agent's token counts with the cumulative totals
+// read from its transcript. Later heuristic Record calls for the agent are
+// ignored.
+func (t *CostTracker) SetExactUsage(session, pane, model string, input, output, cacheRead, cacheWrite int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.getOrCreateSession(session)
+	a := s.getOrCreateAgent(pane, model)
+	a.InputTokens = input
+	a.OutputTokens = output
+	a.CacheReadTokens = cacheRead
+	a.CacheWriteTokens = cacheWrite
+	a.Source = SourceTranscript
+	a.LastUpdated = time.Now()
+	if model != "" {
+		a.Model = model
+	}
+}
+
 // GetSessionCost returns the total USD cost for a session.
 func (t *CostTracker) GetSessionCost(session string) float64 {
 	t.mu.RLock()
@@ -305,39 +354,11 @@ func normalizeModelName(model string) string {
 	return model
 }
 
-// GetModelPricing returns the pricing for a model.
-// If the model is not found, returns default pricing.
+// GetModelPricing returns the current pricing for a model from the active
+// catalog. Unknown models get their provider's default or the catalog
+// fallback price.
 func GetModelPricing(model string) ModelPricing {
-	if pricing, ok := modelPricing[model]; ok {
-		return pricing
-	}
-
-	normalized := normalizeModelName(model)
-	if pricing, ok := modelPricing[normalized]; ok {
-		return pricing
-	}
-
-	// Prefix match for variants (longest key first).
-	keys := make([]string, 0, len(modelPricing))
-	for key := range modelPricing {
-		if key == "default" {
-			continue
-		}
-		keys = append(keys, key)
-	}
-	sort.Slice(keys, func(i, j int) bool {
-		return len(keys[i]) > len(keys[j])
-	})
-	for _, key := range keys {
-		if strings.HasPrefix(normalized, key) {
-			return modelPricing[key]
-		}
-	}
-
-	if pricing, ok := modelPricing["default"]; ok {
-		return pricing
-	}
-	return ModelPricing{}
+	return ActiveCatalog().Lookup(model, time.Now())
 }
 
 // EstimateTokens estimates the token count for text.
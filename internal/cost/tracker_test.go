@@ -213,6 +213,27 @@ func TestCostTracker_RecordTokens(t *testing.T) {
 	}
 }
 
+func TestCostTracker_SetExactUsage(t *testing.T) {
+	tracker := NewCostTracker("")
+	tracker.RecordPrompt("session1", "pane1", "claude-sonnet-4", "an estimated prompt")
+	tracker.SetExactUsage("session1", "pane1", "claude-sonnet-4", 1000, 2000, 10000, 4000)
+	tracker.RecordResponse("session1", "pane1", "claude-sonnet-4", "ignored once exact")
+
+	agent := tracker.GetSession("session1").Agents["pane1"]
+	if !agent.Exact() || agent.InputTokens != 1000 || agent.OutputTokens != 2000 {
+		t.Fatalf("agent = %+v", agent)
+	}
+	// 1K*0.003 + 2K*0.015 + 10K*0.0003 + 4K*0.00375
+	want := 0.003 + 0.030 + 0.003 + 0.015
+	if got := agent.Cost(); got < want-1e-9 || got > want+1e-9 {
+		t.Errorf("Cost() = %f, want %f", got, want)
+	}
+	read, write := tracker.GetSession("session1").TotalCacheTokens()
+	if read != 10000 || write != 4000 {
+		t.Errorf("TotalCacheTokens() = %d, %d", read, write)
+	}
+}
+
 func TestCostTracker_GetSessionCost(t *testing.T) {
 	tracker := NewCostTracker("")
 	tracker.RecordTokens("session1", "pane1", "claude-opus", 1000, 1000)
@@ -0,0 +1,260 @@
+// Package cron parses cron expressions and runs the schedules stored in the
+// state store: prompts sent to session panes and pipeline runs.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule computes the activation times of a cron expression.
+type Schedule interface {
+	// Next returns the first activation time strictly after t.
+	Next(t time.Time) time.Time
+}
+
+// Expr is a parsed standard 5-field cron expression:
+//
+//	minute hour day-of-month month day-of-week
+//
+// Each field accepts *, values, ranges (1-5), lists (1,3,5) and steps (*/15,
+// 0-30/10). Months and weekdays also accept three-letter names (jan, mon).
+// Times are evaluated in the location of the time passed to Next.
+type Expr struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// Every is an @every <duration> schedule.
+type Every struct {
+	Interval time.Duration
+}
+
+// Next returns t plus the interval, truncated to the second.
+func (e Every) Next(t time.Time) time.Time {
+	return t.Add(e.Interval).Truncate(time.Second)
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dowNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+type fieldSpec struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var fields = []fieldSpec{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day-of-month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	{name: "day-of-week", min: 0, max: 7, names: dowNames}, // 7 is Sunday too
+}
+
+// Parse parses a 5-field cron expression, @every <duration>, or one of the
+// descriptors @yearly, @monthly, @weekly, @daily and @hourly.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("empty cron expression")
+	}
+
+	if rest, ok := strings.CutPrefix(spec, "@every"); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid @every duration %q: %w", strings.TrimSpace(rest), err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("@every interval must be at least 1s, got %s", d)
+		}
+		return Every{Interval: d}, nil
+	}
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := descriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cron descriptor %q", spec)
+		}
+		spec = expanded
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields (minute hour day-of-month month day-of-week), got %d", spec, len(parts))
+	}
+
+	var masks [5]uint64
+	for i, part := range parts {
+		mask, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		masks[i] = mask
+	}
+
+	// Sunday may be written as 0 or 7.
+	if masks[4]&(1<<7) != 0 {
+		masks[4] = masks[4]&^(1<<7) | 1
+	}
+
+	return &Expr{
+		minute:  masks[0],
+		hour:    masks[1],
+		dom:     masks[2],
+		month:   masks[3],
+		dow:     masks[4],
+		domStar: isStar(parts[2]),
+		dowStar: isStar(parts[4]),
+	}, nil
+}
+
+// isStar reports whether a day field starts with * (or its ? alias), which
+// is how Vixie cron decides a day field is unrestricted: "*" and "*/2" defer
+// to the other day field, while "1-31" still counts as a restriction.
+func isStar(field string) bool {
+	return strings.HasPrefix(field, "*") || strings.HasPrefix(field, "?")
+}
+
+// Validate reports whether spec is a valid schedule expression.
+func Validate(spec string) error {
+	_, err := Parse(spec)
+	return err
+}
+
+func parseField(field string, spec fieldSpec) (uint64, error) {
+	var mask uint64
+	for _, item := range strings.Split(field, ",") {
+		m, err := parseItem(strings.ToLower(item), spec)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s field %q: %w", spec.name, field, err)
+		}
+		mask |= m
+	}
+	return mask, nil
+}
+
+func parseItem(item string, spec fieldSpec) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(item, "/")
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepPart)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid step %q", stepPart)
+		}
+		step = n
+	}
+
+	lo, hi := spec.min, spec.max
+	switch {
+	case rangePart == "*" || rangePart == "?":
+		if spec.name == "day-of-week" {
+			hi = 6
+		}
+	case strings.Contains(rangePart, "-"):
+		a, b, _ := strings.Cut(rangePart, "-")
+		var err error
+		if lo, err = parseValue(a, spec); err != nil {
+			return 0, err
+		}
+		if hi, err = parseValue(b, spec); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("range %s is backwards", rangePart)
+		}
+	default:
+		v, err := parseValue(rangePart, spec)
+		if err != nil {
+			return 0, err
+		}
+		lo = v
+		if !hasStep {
+			hi = v
+		}
+	}
+
+	var mask uint64
+	for v := lo; v <= hi; v += step {
+		mask |= 1 << uint(v)
+	}
+	return mask, nil
+}
+
+func parseValue(s string, spec fieldSpec) (int, error) {
+	if v, ok := spec.names[s]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < spec.min || v > spec.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, spec.min, spec.max)
+	}
+	return v, nil
+}
+
+// maxSearchYears bounds the search for impossible dates such as 30 February.
+const maxSearchYears = 5
+
+// Next returns the first minute strictly after t that matches the expression,
+// or the zero time if none exists within the next few years.
+func (e *Expr) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if !has(e.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !e.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !has(e.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if !has(e.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the cron rule that, when both day fields are
+// restricted, a day matching either one fires.
+func (e *Expr) dayMatches(t time.Time) bool {
+	dom := has(e.dom, t.Day())
+	dow := has(e.dow, int(t.Weekday()))
+	if e.domStar || e.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func has(mask uint64, v int) bool {
+	return mask&(1<<uint(v)) != 0
+}
@@ -0,0 +1,78 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{"", "empty"},
+		{"* * * *", "must have 5 fields"},
+		{"60 * * * *", "out of range"},
+		{"* * 0 * *", "out of range"},
+		{"*/0 * * * *", "invalid step"},
+		{"5-1 * * * *", "backwards"},
+		{"* * * foo *", "invalid value"},
+		{"@every", "invalid @every duration"},
+		{"@every 10ms", "at least 1s"},
+		{"@fortnightly", "unknown cron descriptor"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.spec)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// Friday 2026-01-02 08:30 UTC
+	from := time.Date(2026, 1, 2, 8, 30, 0, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 2, 8, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 2, 8, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * mon", time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2026, 1, 3, 2, 0, 0, 0, time.UTC)},
+		{"30 8 * * *", time.Date(2026, 1, 3, 8, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 feb *", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 13 * fri", time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)}, // dom OR dow
+		{"0 0 */2 * 1", time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)},    // */n is a star: odd day AND monday
+		{"0 0 1-31 * 1", time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)},   // a full range still restricts: dom OR dow
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"@every 30m", time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		sched, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.spec, err)
+		}
+		if got := sched.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestNextUsesLocation(t *testing.T) {
+	loc := time.FixedZone("IST", 5*3600+1800)
+	sched, err := Parse("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := sched.Next(time.Date(2026, 1, 2, 8, 59, 0, 0, loc))
+	if want := time.Date(2026, 1, 2, 9, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
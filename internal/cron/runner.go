@@ -0,0 +1,290 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/pipeline"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+// DefaultPollInterval is how often the runner checks for due schedules.
+const DefaultPollInterval = 15 * time.Second
+
+// Executor runs a schedule's target.
+type Executor func(ctx context.Context, sch state.Schedule) error
+
+// Runner fires due schedules from the state store. Each run happens in its
+// own goroutine; a schedule that is still running when its next slot comes
+// round skips that slot rather than overlapping. Slots are claimed in the
+// store, so several runners sharing a database fire each slot once.
+type Runner struct {
+	store    *state.Store
+	execute  Executor
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	running map[string]bool
+	wg      sync.WaitGroup
+}
+
+// NewRunner creates a runner. A nil executor runs targets with Execute.
+func NewRunner(store *state.Store, execute Executor) *Runner {
+	if execute == nil {
+		execute = Execute
+	}
+	return &Runner{
+		store:    store,
+		execute:  execute,
+		interval: DefaultPollInterval,
+		now:      time.Now,
+		running:  make(map[string]bool),
+	}
+}
+
+// SetPollInterval changes how often the runner checks for due schedules.
+func (r *Runner) SetPollInterval(d time.Duration) {
+	if d > 0 {
+		r.interval = d
+	}
+}
+
+// Run checks for due schedules until ctx is cancelled, then waits for
+// in-flight runs to finish.
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	defer r.wg.Wait()
+
+	for {
+		r.Tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick starts every schedule that is due now and advances it to its next
+// slot. Runs that were due longer ago than the missed-run grace period follow
+// the schedule's missed-run policy.
+func (r *Runner) Tick(ctx context.Context) {
+	now := r.now()
+	due, err := r.store.DueSchedules(now)
+	if err != nil {
+		log.Printf("schedule: %v", err)
+		return
+	}
+
+	for _, sch := range due {
+		next, err := NextRun(sch.Cron, now)
+		if err != nil {
+			log.Printf("schedule %s: %v", sch.Name, err)
+			_ = r.store.SetScheduleNextRun(sch.ID, nil)
+			continue
+		}
+		claimed, err := r.store.ClaimScheduleRun(sch.ID, *sch.NextRunAt, nextPtr(next))
+		if err != nil {
+			log.Printf("schedule %s: %v", sch.Name, err)
+			continue
+		}
+		if !claimed {
+			continue // Another scheduler took this slot
+		}
+
+		if r.missed(sch, now) && sch.MissedPolicy != state.MissedRunOnce {
+			log.Printf("schedule %s: skipping run missed at %s", sch.Name, sch.NextRunAt.Local().Format(time.RFC3339))
+			continue
+		}
+		if !r.claim(sch.ID) {
+			log.Printf("schedule %s: previous run still in progress, skipping", sch.Name)
+			continue
+		}
+
+		r.wg.Add(1)
+		go func(sch state.Schedule) {
+			defer r.wg.Done()
+			defer r.release(sch.ID)
+			r.runAndRecord(ctx, sch)
+		}(sch)
+	}
+}
+
+// RunNow runs a schedule immediately without moving its next run time.
+func (r *Runner) RunNow(ctx context.Context, sch state.Schedule) error {
+	if !r.claim(sch.ID) {
+		return fmt.Errorf("schedule %s is already running", sch.Name)
+	}
+	defer r.release(sch.ID)
+	return r.runAndRecord(ctx, sch)
+}
+
+func (r *Runner) runAndRecord(ctx context.Context, sch state.Schedule) error {
+	startedAt := r.now()
+	runErr := r.execute(ctx, sch)
+	if runErr != nil {
+		log.Printf("schedule %s: run failed: %v", sch.Name, runErr)
+	}
+	if err := r.store.RecordScheduleRun(sch.ID, startedAt, runErr); err != nil {
+		log.Printf("schedule %s: %v", sch.Name, err)
+	}
+	return runErr
+}
+
+// missed reports whether a due run is older than the grace period, meaning
+// no scheduler was running when it fell due.
+func (r *Runner) missed(sch state.Schedule, now time.Time) bool {
+	grace := 2 * r.interval
+	if grace < time.Minute {
+		grace = time.Minute
+	}
+	return sch.NextRunAt != nil && now.Sub(*sch.NextRunAt) > grace
+}
+
+func (r *Runner) claim(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running[id] {
+		return false
+	}
+	r.running[id] = true
+	return true
+}
+
+func (r *Runner) release(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.running, id)
+}
+
+// NextRun returns the first activation of spec after t, or the zero time if
+// the expression never fires again.
+func NextRun(spec string, after time.Time) (time.Time, error) {
+	sched, err := Parse(spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return sched.Next(after.Local()), nil
+}
+
+func nextPtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// Prepare validates a schedule, fills in defaults and sets its next run time.
+func Prepare(sch *state.Schedule, now time.Time) error {
+	sch.Name = strings.TrimSpace(sch.Name)
+	if sch.Name == "" {
+		return errors.New("schedule name is required")
+	}
+	if sch.SessionID == "" {
+		return errors.New("schedule session is required")
+	}
+	switch sch.TargetType {
+	case state.ScheduleTargetSend:
+		if strings.TrimSpace(sch.Target.Message) == "" {
+			return errors.New("send schedules require a message")
+		}
+	case state.ScheduleTargetPipeline:
+		if strings.TrimSpace(sch.Target.Workflow) == "" {
+			return errors.New("pipeline schedules require a workflow")
+		}
+	default:
+		return fmt.Errorf("invalid target type %q (expected send or pipeline)", sch.TargetType)
+	}
+	switch sch.MissedPolicy {
+	case "":
+		sch.MissedPolicy = state.MissedRunSkip
+	case state.MissedRunSkip, state.MissedRunOnce:
+	default:
+		return fmt.Errorf("invalid missed-run policy %q (expected skip or once)", sch.MissedPolicy)
+	}
+
+	next, err := NextRun(sch.Cron, now)
+	if err != nil {
+		return err
+	}
+	if next.IsZero() {
+		return fmt.Errorf("cron expression %q never fires", sch.Cron)
+	}
+	sch.NextRunAt = nil
+	if sch.Enabled {
+		sch.NextRunAt = &next
+	}
+	return nil
+}
+
+// Execute runs a schedule's target: a send to the session's panes or a
+// pipeline run in the schedule's project directory.
+func Execute(ctx context.Context, sch state.Schedule) error {
+	switch sch.TargetType {
+	case state.ScheduleTargetSend:
+		return executeSend(sch)
+	case state.ScheduleTargetPipeline:
+		return executePipeline(ctx, sch)
+	default:
+		return fmt.Errorf("unknown target type %q", sch.TargetType)
+	}
+}
+
+func executeSend(sch state.Schedule) error {
+	out, err := robot.GetSend(robot.SendOptions{
+		Session:     sch.SessionID,
+		Message:     sch.Target.Message,
+		Panes:       sch.Target.Panes,
+		AgentTypes:  sch.Target.AgentTypes,
+		All:         sch.Target.All,
+		RequestedBy: "schedule " + sch.Name,
+	})
+	if err != nil {
+		return err
+	}
+	if !out.Success {
+		return errors.New(out.Error)
+	}
+	if len(out.Failed) > 0 {
+		return fmt.Errorf("send failed for %d of %d panes: %s", len(out.Failed), len(out.Targets), out.Failed[0].Error)
+	}
+	return nil
+}
+
+func executePipeline(ctx context.Context, sch state.Schedule) error {
+	projectDir := sch.Target.ProjectDir
+	if projectDir == "" {
+		projectDir, _ = os.Getwd()
+	}
+
+	workflow, key, err := pipeline.ResolveWorkflowRef(sch.Target.Workflow, "", projectDir)
+	if err != nil {
+		return err
+	}
+	if result := pipeline.Validate(workflow); !result.Valid {
+		msg := "workflow validation failed"
+		if len(result.Errors) > 0 {
+			msg = result.Errors[0].Message
+		}
+		return fmt.Errorf("%s: %s", sch.Target.Workflow, msg)
+	}
+
+	cfg := pipeline.DefaultExecutorConfig(sch.SessionID)
+	cfg.ProjectDir = projectDir
+	if !strings.HasPrefix(key, pipeline.BuiltinWorkflowPrefix) {
+		cfg.WorkflowFile = key
+	}
+	cfg.RunID = pipeline.GenerateRunID()
+
+	_, err = pipeline.NewExecutor(cfg).Run(ctx, workflow, sch.Target.Vars, nil)
+	return err
+}
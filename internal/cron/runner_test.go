@@ -0,0 +1,198 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/budget"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func testRunnerStore(t *testing.T) *state.Store {
+	t.Helper()
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return store
+}
+
+func addSchedule(t *testing.T, store *state.Store, name string, policy state.MissedRunPolicy, next time.Time) {
+	t.Helper()
+	sch := &state.Schedule{
+		Name: name, Cron: "0 * * * *", TargetType: state.ScheduleTargetSend, SessionID: "proj",
+		Target: state.ScheduleTarget{Message: "status?"}, MissedPolicy: policy, Enabled: true,
+	}
+	if err := Prepare(sch, next); err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	sch.NextRunAt = &next
+	if err := store.CreateSchedule(sch); err != nil {
+		t.Fatalf("CreateSchedule: %v", err)
+	}
+}
+
+func TestRunnerTick(t *testing.T) {
+	store := testRunnerStore(t)
+	now := time.Date(2026, 1, 2, 9, 0, 10, 0, time.Local)
+
+	addSchedule(t, store, "due", state.MissedRunSkip, now.Add(-10*time.Second))
+	addSchedule(t, store, "missed-skip", state.MissedRunSkip, now.Add(-3*time.Hour))
+	addSchedule(t, store, "missed-once", state.MissedRunOnce, now.Add(-3*time.Hour))
+	addSchedule(t, store, "later", state.MissedRunSkip, now.Add(time.Hour))
+
+	var mu sync.Mutex
+	ran := map[string]int{}
+	r := NewRunner(store, func(ctx context.Context, sch state.Schedule) error {
+		mu.Lock()
+		defer mu.Unlock()
+		ran[sch.Name]++
+		if sch.Name == "missed-once" {
+			return errors.New("session not found")
+		}
+		return nil
+	})
+	r.now = func() time.Time { return now }
+	r.Tick(context.Background())
+	r.wg.Wait()
+
+	if ran["due"] != 1 || ran["missed-once"] != 1 || ran["missed-skip"] != 0 || ran["later"] != 0 {
+		t.Fatalf("ran = %v", ran)
+	}
+
+	want := time.Date(2026, 1, 2, 10, 0, 0, 0, time.Local)
+	for _, name := range []string{"due", "missed-skip", "missed-once"} {
+		sch, _ := store.GetSchedule(name)
+		if sch.NextRunAt == nil || !sch.NextRunAt.Equal(want) {
+			t.Errorf("%s next_run_at = %v, want %v", name, sch.NextRunAt, want)
+		}
+	}
+	if sch, _ := store.GetSchedule("missed-skip"); sch.RunCount != 0 {
+		t.Errorf("skipped run was recorded: %+v", sch)
+	}
+	if sch, _ := store.GetSchedule("missed-once"); sch.LastStatus != state.ScheduleStatusError || sch.LastError != "session not found" {
+		t.Errorf("failed run not recorded: %+v", sch)
+	}
+
+	// Nothing is due again until the next slot
+	r.Tick(context.Background())
+	r.wg.Wait()
+	if ran["due"] != 1 {
+		t.Errorf("due ran %d times, want 1", ran["due"])
+	}
+}
+
+func TestRunnerSkipsOverlappingRuns(t *testing.T) {
+	store := testRunnerStore(t)
+	now := time.Now()
+	addSchedule(t, store, "slow", state.MissedRunSkip, now.Add(-time.Second))
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	r := NewRunner(store, func(ctx context.Context, sch state.Schedule) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	r.Tick(context.Background())
+	<-started
+
+	sch, _ := store.GetSchedule("slow")
+	if err := r.RunNow(context.Background(), *sch); err == nil {
+		t.Error("expected RunNow to refuse while a run is in progress")
+	}
+	close(release)
+	r.wg.Wait()
+
+	if err := r.RunNow(context.Background(), *sch); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	sch, _ = store.GetSchedule("slow")
+	if sch.RunCount != 2 {
+		t.Errorf("run_count = %d, want 2", sch.RunCount)
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	now := time.Date(2026, 1, 2, 8, 0, 0, 0, time.Local)
+	sch := &state.Schedule{Name: " nightly ", Cron: "0 2 * * *", TargetType: state.ScheduleTargetPipeline,
+		SessionID: "proj", Target: state.ScheduleTarget{Workflow: "nightly-review"}, Enabled: true}
+	if err := Prepare(sch, now); err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if sch.Name != "nightly" || sch.MissedPolicy != state.MissedRunSkip {
+		t.Errorf("defaults not applied: %+v", sch)
+	}
+	if want := time.Date(2026, 1, 3, 2, 0, 0, 0, time.Local); sch.NextRunAt == nil || !sch.NextRunAt.Equal(want) {
+		t.Errorf("next_run_at = %v, want %v", sch.NextRunAt, want)
+	}
+
+	for _, bad := range []state.Schedule{
+		{Name: "x", Cron: "@daily", TargetType: state.ScheduleTargetSend, SessionID: "proj"},
+		{Name: "x", Cron: "@daily", TargetType: "shell", SessionID: "proj"},
+		{Name: "x", Cron: "0 0 30 2 *", TargetType: state.ScheduleTargetSend, SessionID: "proj", Target: state.ScheduleTarget{Message: "hi"}},
+		{Name: "x", Cron: "@daily", TargetType: state.ScheduleTargetSend, SessionID: "proj", Target: state.ScheduleTarget{Message: "hi"}, MissedPolicy: "all"},
+	} {
+		if err := Prepare(&bad, now); err == nil {
+			t.Errorf("Prepare(%+v) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestExecuteSendRespectsBudget(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	session := fmt.Sprintf("ntm_cron_test_%d", time.Now().UnixNano())
+	if err := tmux.CreateSession(session, os.TempDir()); err != nil {
+		t.Skipf("failed to create test session (tmux issue): %v", err)
+	}
+	t.Cleanup(func() { _ = tmux.KillSession(session) })
+	paneID, err := tmux.SplitWindow(session, os.TempDir())
+	if err != nil {
+		t.Skipf("split window: %v", err)
+	}
+	if err := tmux.SetPaneTitle(paneID, session+"__cc_1"); err != nil {
+		t.Skipf("set pane title: %v", err)
+	}
+
+	var gotTypes []string
+	var gotBy string
+	orig := budget.GuardSend
+	budget.GuardSend = func(_ context.Context, _ config.BudgetsConfig, _, _ string, agentTypes []string, requestedBy string) error {
+		gotTypes, gotBy = agentTypes, requestedBy
+		return &budget.BlockedError{Status: budget.Status{
+			Limit:    budget.Limit{Scope: state.BudgetScopeDaily, USD: 1},
+			SpentUSD: 2,
+		}}
+	}
+	t.Cleanup(func() { budget.GuardSend = orig })
+
+	err = Execute(context.Background(), state.Schedule{
+		Name: "nightly", TargetType: state.ScheduleTargetSend, SessionID: session,
+		Target: state.ScheduleTarget{Message: "cron-budget-marker", Panes: []string{paneID}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "budget daily exceeded") {
+		t.Fatalf("Execute error = %v, want the budget block", err)
+	}
+	if len(gotTypes) != 1 || gotTypes[0] != "cc" || gotBy != "schedule nightly" {
+		t.Errorf("budget checked for %v by %q", gotTypes, gotBy)
+	}
+	if out, _ := tmux.CapturePaneOutput(paneID, 50); strings.Contains(out, "cron-budget-marker") {
+		t.Errorf("message was sent despite the exceeded budget:\n%s", out)
+	}
+}
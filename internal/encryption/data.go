@@ -0,0 +1,49 @@
+package encryption
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// dataHeader prefixes data encrypted with EncryptData so readers can tell it
+// apart from plaintext files written before encryption was enabled.
+var dataHeader = []byte("NTMENC\n")
+
+// EncryptData encrypts a whole file's contents. The result starts with a
+// fixed header recognized by IsEncryptedData.
+func EncryptData(key, plaintext []byte) ([]byte, error) {
+	ciphertext, err := Encrypt(key, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(dataHeader)+len(ciphertext))
+	out = append(out, dataHeader...)
+	return append(out, ciphertext...), nil
+}
+
+// IsEncryptedData returns true if data was produced by EncryptData.
+func IsEncryptedData(data []byte) bool {
+	return bytes.HasPrefix(data, dataHeader)
+}
+
+// DecryptDataWithKeyring decrypts data produced by EncryptData, trying each
+// key in order. Data without the header is returned unchanged.
+func DecryptDataWithKeyring(keys [][]byte, data []byte) ([]byte, error) {
+	if !IsEncryptedData(data) {
+		return data, nil
+	}
+	if len(keys) == 0 {
+		return nil, &Error{Kind: ErrWrongKey, Err: fmt.Errorf("data is encrypted but no encryption key is configured")}
+	}
+	ciphertext := data[len(dataHeader):]
+	for _, key := range keys {
+		plaintext, err := Decrypt(key, ciphertext)
+		if err == nil {
+			return plaintext, nil
+		}
+		if !IsWrongKey(err) {
+			return nil, err
+		}
+	}
+	return nil, &Error{Kind: ErrWrongKey, Err: fmt.Errorf("no key in keyring could decrypt the data")}
+}
@@ -0,0 +1,48 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestEncryptDataRoundTrip(t *testing.T) {
+	oldKey := make([]byte, KeySize)
+	newKey := make([]byte, KeySize)
+	if _, err := rand.Read(oldKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rand.Read(newKey); err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte("\x1f\x8bbinary scrollback\n")
+
+	encrypted, err := EncryptData(oldKey, plaintext)
+	if err != nil {
+		t.Fatalf("EncryptData: %v", err)
+	}
+	if !IsEncryptedData(encrypted) || IsEncryptedData(plaintext) {
+		t.Fatal("IsEncryptedData misclassified data")
+	}
+
+	// Any key in the keyring can decrypt.
+	got, err := DecryptDataWithKeyring([][]byte{newKey, oldKey}, encrypted)
+	if err != nil {
+		t.Fatalf("DecryptDataWithKeyring: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("round-trip mismatch: got %q", got)
+	}
+
+	// Plaintext passes through untouched.
+	if got, err := DecryptDataWithKeyring(nil, plaintext); err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("plaintext = %q, %v", got, err)
+	}
+
+	if _, err := DecryptDataWithKeyring([][]byte{newKey}, encrypted); !IsWrongKey(err) {
+		t.Errorf("wrong keyring error = %v, want wrong key", err)
+	}
+	if _, err := DecryptDataWithKeyring(nil, encrypted); !IsWrongKey(err) {
+		t.Errorf("no keys error = %v, want wrong key", err)
+	}
+}
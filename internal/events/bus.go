@@ -548,6 +548,34 @@ func NewAgentErrorEvent(session, agentID, errorType, message string) AgentErrorE
 	}
 }
 
+// AgentHookEvent is emitted for each event an agent CLI reports through
+// its own hooks (turn start/stop, tool calls, permission prompts)
+type AgentHookEvent struct {
+	BaseEvent
+	PaneID    string   `json:"pane_id"`
+	AgentType string   `json:"agent_type,omitempty"`
+	Kind      string   `json:"kind"`
+	Tool      string   `json:"tool,omitempty"`
+	Command   string   `json:"command,omitempty"`
+	ExitCode  *int     `json:"exit_code,omitempty"`
+	Files     []string `json:"files,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+// NewAgentHookEvent creates a new agent hook event
+func NewAgentHookEvent(session, paneID, agentType, kind string) AgentHookEvent {
+	return AgentHookEvent{
+		BaseEvent: BaseEvent{
+			Type:      "agent_hook",
+			Timestamp: time.Now().UTC(),
+			Session:   session,
+		},
+		PaneID:    paneID,
+		AgentType: agentType,
+		Kind:      kind,
+	}
+}
+
 // ----------------------------------------------------------------
 // Alert Events
 // ----------------------------------------------------------------
@@ -10,7 +10,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Dicklesworthstone/ntm/internal/audit"
 	"github.com/Dicklesworthstone/ntm/internal/privacy"
+	"github.com/Dicklesworthstone/ntm/internal/tracing"
 	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
@@ -98,6 +100,11 @@ func (l *Logger) Log(event *Event) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
+	// Link uncorrelated events to the running command's trace
+	if event.CorrelationID == "" && tracing.CommandTraceID() != "" {
+		event.CorrelationID = audit.NewCorrelationID()
+	}
+
 	// Apply redaction if configured
 	eventToWrite := RedactEvent(event)
 
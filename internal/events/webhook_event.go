@@ -15,6 +15,7 @@ const (
 	WebhookAgentIdle      = "agent.idle"
 	WebhookAgentBusy      = "agent.busy"
 	WebhookAgentRateLimit = "agent.rate_limit"
+	WebhookAgentApproval  = "agent.approval"
 	WebhookAgentCompleted = "agent.completed"
 	WebhookRotationNeeded = "rotation.needed"
 	WebhookHealthDegraded = "health.degraded"
@@ -0,0 +1,198 @@
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// apiKeyHeader is the header `ntm serve` reads API keys from.
+const apiKeyHeader = "X-API-Key"
+
+// client talks to a single `ntm serve` instance.
+type client struct {
+	host Host
+	base *url.URL
+	http *http.Client
+}
+
+func newClient(h Host, timeout time.Duration) (*client, error) {
+	base, err := url.Parse(strings.TrimRight(h.URL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("host %s: invalid url: %w", h.Name, err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("host %s: url must be http or https, got %q", h.Name, h.URL)
+	}
+	if base.Host == "" {
+		return nil, fmt.Errorf("host %s: url %q has no host", h.Name, h.URL)
+	}
+	return &client{
+		host: h,
+		base: base,
+		http: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// apiError is the error envelope returned by `ntm serve`.
+type apiError struct {
+	Success   bool   `json:"success"`
+	Error     string `json:"error"`
+	ErrorCode string `json:"error_code"`
+}
+
+func (c *client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.base.String()+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.host.APIKey != "" {
+		req.Header.Set(apiKeyHeader, c.host.APIKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		var apiErr apiError
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s %s: %s (%d)", method, path, apiErr.Error, resp.StatusCode)
+		}
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
+
+// remoteStatus is the subset of /api/v1/robot/status the fleet uses.
+type remoteStatus struct {
+	System struct {
+		Version string `json:"version"`
+	} `json:"system"`
+	Sessions []struct {
+		Name     string  `json:"name"`
+		Attached bool    `json:"attached"`
+		Panes    int     `json:"panes"`
+		Agents   []Agent `json:"agents"`
+	} `json:"sessions"`
+}
+
+// remoteSession is an entry of /api/v1/sessions.
+type remoteSession struct {
+	Name        string    `json:"name"`
+	ProjectPath string    `json:"project_path"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (c *client) status(ctx context.Context) (*remoteStatus, error) {
+	var out remoteStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/robot/status", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *client) sessions(ctx context.Context) ([]remoteSession, error) {
+	var out struct {
+		Sessions []remoteSession `json:"sessions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/sessions", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Sessions, nil
+}
+
+// remoteSendResult is the response of POST /api/v1/sessions/{id}/agents/send.
+type remoteSendResult struct {
+	Error      string   `json:"error"`
+	Successful []string `json:"successful"`
+	Failed     []struct {
+		Pane  string `json:"pane"`
+		Error string `json:"error"`
+	} `json:"failed"`
+	Queued []json.RawMessage `json:"queued"`
+}
+
+func (c *client) send(ctx context.Context, session string, req SendRequest) (*remoteSendResult, error) {
+	var out remoteSendResult
+	path := "/api/v1/sessions/" + url.PathEscape(session) + "/agents/send"
+	if err := c.do(ctx, http.MethodPost, path, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// wsEvent is an event frame on the /api/v1/ws stream.
+type wsEvent struct {
+	Type      string `json:"type"`
+	Topic     string `json:"topic"`
+	EventType string `json:"event_type"`
+}
+
+// dialEvents opens the host's WebSocket stream and subscribes to session
+// events.
+func (c *client) dialEvents(ctx context.Context) (*websocket.Conn, error) {
+	wsURL := *c.base
+	if wsURL.Scheme == "https" {
+		wsURL.Scheme = "wss"
+	} else {
+		wsURL.Scheme = "ws"
+	}
+	wsURL.Path = strings.TrimRight(wsURL.Path, "/") + "/api/v1/ws"
+
+	header := http.Header{}
+	if c.host.APIKey != "" {
+		header.Set(apiKeyHeader, c.host.APIKey)
+	}
+	dialer := websocket.Dialer{HandshakeTimeout: c.http.Timeout}
+	conn, _, err := dialer.DialContext(ctx, wsURL.String(), header)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribe := map[string]interface{}{
+		"type": "subscribe",
+		"data": map[string]interface{}{
+			"topics": []string{"sessions:*", "global:*"},
+		},
+	}
+	if err := conn.WriteJSON(subscribe); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
@@ -0,0 +1,419 @@
+// Package fleet aggregates several `ntm serve` instances into one view.
+//
+// Each host is polled over the serve REST API (/api/v1/sessions and
+// /api/v1/robot/status) and watched over its /api/v1/ws event stream. Hosts
+// that stop answering are reported offline with their last known sessions
+// instead of failing the whole view.
+package fleet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+)
+
+// DefaultTimeout bounds each request to a host.
+const DefaultTimeout = 5 * time.Second
+
+// Host is one `ntm serve` instance in the fleet.
+type Host struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	APIKey string `json:"-"`
+}
+
+// Agent is an agent pane on a remote session.
+type Agent struct {
+	Type     string `json:"type"`
+	Variant  string `json:"variant,omitempty"`
+	Pane     string `json:"pane"`
+	PaneIdx  int    `json:"pane_idx"`
+	IsActive bool   `json:"is_active"`
+}
+
+// Session is a session on one host, merged from the host's state store and
+// its live tmux server.
+type Session struct {
+	Name        string  `json:"name"`
+	Status      string  `json:"status,omitempty"`
+	ProjectPath string  `json:"project_path,omitempty"`
+	Live        bool    `json:"live"`
+	Attached    bool    `json:"attached,omitempty"`
+	Panes       int     `json:"panes"`
+	Agents      []Agent `json:"agents"`
+}
+
+// HostStatus is the last known state of one host.
+type HostStatus struct {
+	Name      string     `json:"name"`
+	URL       string     `json:"url"`
+	Online    bool       `json:"online"`
+	Stale     bool       `json:"stale,omitempty"` // Sessions are from the last successful poll
+	Error     string     `json:"error,omitempty"`
+	Version   string     `json:"version,omitempty"`
+	LastSeen  *time.Time `json:"last_seen,omitempty"`
+	LatencyMs int64      `json:"latency_ms"`
+	Sessions  []Session  `json:"sessions"`
+}
+
+// Summary totals the fleet.
+type Summary struct {
+	Hosts        int            `json:"hosts"`
+	Online       int            `json:"online"`
+	Offline      int            `json:"offline"`
+	Sessions     int            `json:"sessions"`
+	Agents       int            `json:"agents"`
+	AgentsByType map[string]int `json:"agents_by_type"`
+}
+
+// Status is a merged snapshot of every host.
+type Status struct {
+	GeneratedAt time.Time    `json:"generated_at"`
+	Hosts       []HostStatus `json:"hosts"`
+	Summary     Summary      `json:"summary"`
+}
+
+// Fleet polls and addresses a set of hosts.
+type Fleet struct {
+	hosts   []Host
+	clients map[string]*client
+
+	mu   sync.RWMutex
+	last map[string]HostStatus
+}
+
+// New validates the hosts and returns a fleet over them. Host names must be
+// unique and must not contain ':' since targets are written host:session.
+func New(hosts []Host, timeout time.Duration) (*Fleet, error) {
+	if len(hosts) == 0 {
+		return nil, errors.New("no fleet hosts configured")
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	f := &Fleet{
+		clients: make(map[string]*client, len(hosts)),
+		last:    make(map[string]HostStatus, len(hosts)),
+	}
+	for _, h := range hosts {
+		h.Name = strings.TrimSpace(h.Name)
+		if h.Name == "" {
+			return nil, fmt.Errorf("fleet host %q has no name", h.URL)
+		}
+		if strings.ContainsAny(h.Name, ":* ") {
+			return nil, fmt.Errorf("fleet host name %q must not contain ':', '*' or spaces", h.Name)
+		}
+		if _, dup := f.clients[h.Name]; dup {
+			return nil, fmt.Errorf("duplicate fleet host %q", h.Name)
+		}
+		c, err := newClient(h, timeout)
+		if err != nil {
+			return nil, err
+		}
+		f.hosts = append(f.hosts, h)
+		f.clients[h.Name] = c
+	}
+	return f, nil
+}
+
+// NewFromConfig builds a fleet from the [fleet] config section.
+func NewFromConfig(cfg config.FleetConfig) (*Fleet, error) {
+	if err := config.ValidateFleetConfig(&cfg); err != nil {
+		return nil, err
+	}
+	hosts := make([]Host, 0, len(cfg.Hosts))
+	for _, h := range cfg.Hosts {
+		hosts = append(hosts, Host{Name: h.Name, URL: h.URL, APIKey: h.ResolvedAPIKey()})
+	}
+	return New(hosts, time.Duration(cfg.TimeoutSeconds)*time.Second)
+}
+
+// PollInterval returns the configured poll interval, defaulting to 5s.
+func PollInterval(cfg config.FleetConfig) time.Duration {
+	if cfg.PollIntervalSeconds <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(cfg.PollIntervalSeconds) * time.Second
+}
+
+// Hosts returns the configured hosts in order.
+func (f *Fleet) Hosts() []Host {
+	return append([]Host(nil), f.hosts...)
+}
+
+// Poll queries every host concurrently and returns the merged status.
+func (f *Fleet) Poll(ctx context.Context) *Status {
+	var wg sync.WaitGroup
+	for _, h := range f.hosts {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			f.PollHost(ctx, name)
+		}(h.Name)
+	}
+	wg.Wait()
+	return f.Snapshot()
+}
+
+// PollHost refreshes a single host. An unreachable host is marked offline and
+// keeps the sessions from its last successful poll.
+func (f *Fleet) PollHost(ctx context.Context, name string) HostStatus {
+	c, ok := f.clients[name]
+	if !ok {
+		return HostStatus{Name: name, Error: "unknown host"}
+	}
+
+	start := time.Now()
+	hs := HostStatus{Name: name, URL: c.host.URL, Sessions: []Session{}}
+	sessions, err := f.fetch(ctx, c, &hs)
+	hs.LatencyMs = time.Since(start).Milliseconds()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	prev, seen := f.last[name]
+	if err != nil {
+		hs.Online = false
+		hs.Error = err.Error()
+		if seen {
+			hs.Version = prev.Version
+			hs.LastSeen = prev.LastSeen
+			hs.Sessions = prev.Sessions
+			hs.Stale = prev.Online || prev.Stale
+		}
+	} else {
+		now := time.Now().UTC()
+		hs.Online = true
+		hs.LastSeen = &now
+		hs.Sessions = sessions
+	}
+	f.last[name] = hs
+	return hs
+}
+
+func (f *Fleet) fetch(ctx context.Context, c *client, hs *HostStatus) ([]Session, error) {
+	stored, err := c.sessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	live, err := c.status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hs.Version = live.System.Version
+	return mergeSessions(stored, live), nil
+}
+
+// mergeSessions joins state-store sessions with live tmux sessions by name.
+// Terminated sessions are dropped unless tmux still has them.
+func mergeSessions(stored []remoteSession, live *remoteStatus) []Session {
+	byName := make(map[string]*Session)
+	var order []string
+	for _, rs := range live.Sessions {
+		if _, ok := byName[rs.Name]; ok {
+			continue
+		}
+		agents := rs.Agents
+		if agents == nil {
+			agents = []Agent{}
+		}
+		byName[rs.Name] = &Session{
+			Name:     rs.Name,
+			Live:     true,
+			Attached: rs.Attached,
+			Panes:    rs.Panes,
+			Agents:   agents,
+		}
+		order = append(order, rs.Name)
+	}
+	for _, st := range stored {
+		if s, ok := byName[st.Name]; ok {
+			s.Status = st.Status
+			s.ProjectPath = st.ProjectPath
+			continue
+		}
+		if st.Status == "terminated" {
+			continue
+		}
+		byName[st.Name] = &Session{
+			Name:        st.Name,
+			Status:      st.Status,
+			ProjectPath: st.ProjectPath,
+			Agents:      []Agent{},
+		}
+		order = append(order, st.Name)
+	}
+
+	sort.Strings(order)
+	out := make([]Session, 0, len(order))
+	for _, name := range order {
+		out = append(out, *byName[name])
+	}
+	return out
+}
+
+// Snapshot returns the merged status from the most recent polls without
+// contacting any host. Hosts that were never polled are reported offline.
+func (f *Fleet) Snapshot() *Status {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	st := &Status{
+		GeneratedAt: time.Now().UTC(),
+		Hosts:       make([]HostStatus, 0, len(f.hosts)),
+		Summary:     Summary{Hosts: len(f.hosts), AgentsByType: map[string]int{}},
+	}
+	for _, h := range f.hosts {
+		hs, ok := f.last[h.Name]
+		if !ok {
+			hs = HostStatus{Name: h.Name, URL: h.URL, Error: "not polled yet", Sessions: []Session{}}
+		}
+		if hs.Online {
+			st.Summary.Online++
+		} else {
+			st.Summary.Offline++
+		}
+		st.Summary.Sessions += len(hs.Sessions)
+		for _, s := range hs.Sessions {
+			st.Summary.Agents += len(s.Agents)
+			for _, a := range s.Agents {
+				st.Summary.AgentsByType[a.Type]++
+			}
+		}
+		st.Hosts = append(st.Hosts, hs)
+	}
+	return st
+}
+
+// Target addresses a session on a host. Host "*" means every host that has
+// the session; Session "*" means every session on the host.
+type Target struct {
+	Host    string `json:"host"`
+	Session string `json:"session"`
+}
+
+func (t Target) String() string {
+	return t.Host + ":" + t.Session
+}
+
+// ParseTarget parses a host:session target.
+func ParseTarget(s string) (Target, error) {
+	host, session, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok || host == "" || session == "" {
+		return Target{}, fmt.Errorf("invalid target %q: expected host:session", s)
+	}
+	return Target{Host: host, Session: session}, nil
+}
+
+// Resolve expands wildcard targets against a status snapshot. Explicit
+// host:session targets are passed through unchanged, so a send to a host
+// that is offline still reports that host's error.
+func (f *Fleet) Resolve(targets []Target, st *Status) ([]Target, error) {
+	var out []Target
+	seen := make(map[Target]bool)
+	add := func(t Target) {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	for _, t := range targets {
+		if t.Host != "*" {
+			if _, ok := f.clients[t.Host]; !ok {
+				return nil, fmt.Errorf("unknown fleet host %q", t.Host)
+			}
+		}
+		if t.Host != "*" && t.Session != "*" {
+			add(t)
+			continue
+		}
+		matched := false
+		for _, hs := range st.Hosts {
+			if t.Host != "*" && hs.Name != t.Host {
+				continue
+			}
+			for _, s := range hs.Sessions {
+				if t.Session == "*" || s.Name == t.Session {
+					add(Target{Host: hs.Name, Session: s.Name})
+					matched = true
+				}
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no fleet sessions match %s", t)
+		}
+	}
+	return out, nil
+}
+
+// SendRequest mirrors the body of POST /api/v1/sessions/{id}/agents/send.
+type SendRequest struct {
+	Message    string   `json:"message"`
+	Panes      []string `json:"panes,omitempty"`
+	AgentTypes []string `json:"agent_types,omitempty"`
+	All        bool     `json:"all,omitempty"`
+	Queue      bool     `json:"queue,omitempty"`
+}
+
+// SendResult is the outcome of a send to one target.
+type SendResult struct {
+	Target     Target   `json:"target"`
+	Success    bool     `json:"success"`
+	Error      string   `json:"error,omitempty"`
+	Successful []string `json:"successful,omitempty"`
+	Failed     []string `json:"failed,omitempty"`
+	Queued     int      `json:"queued,omitempty"`
+}
+
+// Send delivers req to every target concurrently. Targets must already be
+// resolved; a failure on one host never stops the others.
+func (f *Fleet) Send(ctx context.Context, targets []Target, req SendRequest) []SendResult {
+	results := make([]SendResult, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			results[i] = f.sendOne(ctx, t, req)
+		}(i, t)
+	}
+	wg.Wait()
+	return results
+}
+
+func (f *Fleet) sendOne(ctx context.Context, t Target, req SendRequest) SendResult {
+	res := SendResult{Target: t}
+	c, ok := f.clients[t.Host]
+	if !ok {
+		res.Error = fmt.Sprintf("unknown fleet host %q", t.Host)
+		return res
+	}
+	out, err := c.send(ctx, t.Session, req)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if out.Error != "" {
+		res.Error = out.Error
+		return res
+	}
+	res.Successful = out.Successful
+	res.Queued = len(out.Queued)
+	for _, fail := range out.Failed {
+		res.Failed = append(res.Failed, fmt.Sprintf("%s: %s", fail.Pane, fail.Error))
+	}
+	switch {
+	case len(out.Failed) > 0 && len(out.Successful) == 0:
+		res.Error = "all target panes failed"
+	case len(out.Successful) == 0 && res.Queued == 0:
+		res.Error = "no panes matched the filters"
+	default:
+		res.Success = true
+	}
+	return res
+}
@@ -0,0 +1,324 @@
+package fleet_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/fleet"
+	"github.com/Dicklesworthstone/ntm/internal/serve"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+type testHost struct {
+	url   string
+	store *state.Store
+	bus   *events.EventBus
+	stop  func()
+}
+
+// startServe runs a real serve.Server on a free local port.
+func startServe(t *testing.T, apiKey string) *testHost {
+	t.Helper()
+
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	auth := serve.AuthConfig{Mode: serve.AuthModeLocal}
+	if apiKey != "" {
+		auth = serve.AuthConfig{Mode: serve.AuthModeAPIKey, APIKey: apiKey}
+	}
+	bus := events.NewEventBus(100)
+	srv := serve.New(serve.Config{
+		Host:       "127.0.0.1",
+		Port:       port,
+		EventBus:   bus,
+		StateStore: store,
+		Auth:       auth,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.Start(ctx)
+	}()
+
+	var once sync.Once
+	h := &testHost{
+		url:   fmt.Sprintf("http://127.0.0.1:%d", port),
+		store: store,
+		bus:   bus,
+		stop: func() {
+			once.Do(func() {
+				cancel()
+				<-done
+			})
+		},
+	}
+	t.Cleanup(func() {
+		h.stop()
+		store.Close()
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(h.url + "/health")
+		if err == nil {
+			resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("serve on port %d never came up: %v", port, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return h
+}
+
+func addSession(t *testing.T, h *testHost, name string, status state.SessionStatus) {
+	t.Helper()
+	err := h.store.CreateSession(&state.Session{
+		ID:          name,
+		Name:        name,
+		ProjectPath: "/tmp/" + name,
+		CreatedAt:   time.Now(),
+		Status:      status,
+	})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+}
+
+func findHost(st *fleet.Status, name string) *fleet.HostStatus {
+	for i := range st.Hosts {
+		if st.Hosts[i].Name == name {
+			return &st.Hosts[i]
+		}
+	}
+	return nil
+}
+
+func hasSession(hs *fleet.HostStatus, name string) bool {
+	for _, s := range hs.Sessions {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPollMergesHostsAndToleratesOffline(t *testing.T) {
+	a := startServe(t, "")
+	b := startServe(t, "secret-b")
+	addSession(t, a, "fleet-alpha", state.SessionActive)
+	addSession(t, a, "fleet-gone", state.SessionTerminated)
+	addSession(t, b, "fleet-beta", state.SessionActive)
+
+	f, err := fleet.New([]fleet.Host{
+		{Name: "a", URL: a.url},
+		{Name: "b", URL: b.url, APIKey: "secret-b"},
+		{Name: "down", URL: "http://127.0.0.1:1"},
+	}, 2*time.Second)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	st := f.Poll(context.Background())
+	if st.Summary.Hosts != 3 || st.Summary.Online != 2 || st.Summary.Offline != 1 {
+		t.Fatalf("summary = %+v, want 3 hosts, 2 online, 1 offline", st.Summary)
+	}
+
+	ha := findHost(st, "a")
+	if ha == nil || !ha.Online || !hasSession(ha, "fleet-alpha") {
+		t.Fatalf("host a = %+v, want online with fleet-alpha", ha)
+	}
+	if hasSession(ha, "fleet-gone") {
+		t.Error("terminated session should be dropped")
+	}
+	if hasSession(ha, "fleet-beta") {
+		t.Error("host a should not report host b's session")
+	}
+	hb := findHost(st, "b")
+	if hb == nil || !hb.Online || !hasSession(hb, "fleet-beta") {
+		t.Fatalf("host b = %+v, want online with fleet-beta", hb)
+	}
+	down := findHost(st, "down")
+	if down == nil || down.Online || down.Error == "" {
+		t.Fatalf("host down = %+v, want offline with error", down)
+	}
+
+	// Host b goes away: it is reported offline with its last known sessions.
+	b.stop()
+	st = f.Poll(context.Background())
+	hb = findHost(st, "b")
+	if hb.Online || !hb.Stale || hb.LastSeen == nil || !hasSession(hb, "fleet-beta") {
+		t.Fatalf("host b after stop = %+v, want offline, stale, with fleet-beta", hb)
+	}
+	if st.Summary.Online != 1 {
+		t.Errorf("online = %d, want 1", st.Summary.Online)
+	}
+}
+
+func TestPollRejectsWrongAPIKey(t *testing.T) {
+	h := startServe(t, "right")
+	f, err := fleet.New([]fleet.Host{{Name: "h", URL: h.url, APIKey: "wrong"}}, time.Second)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	hs := f.PollHost(context.Background(), "h")
+	if hs.Online || hs.Error == "" {
+		t.Fatalf("host = %+v, want offline with auth error", hs)
+	}
+}
+
+func TestSendFansOutPerTarget(t *testing.T) {
+	a := startServe(t, "")
+	f, err := fleet.New([]fleet.Host{
+		{Name: "a", URL: a.url},
+		{Name: "down", URL: "http://127.0.0.1:1"},
+	}, 2*time.Second)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	missing := fmt.Sprintf("ntm-fleet-missing-%d", time.Now().UnixNano())
+	results := f.Send(context.Background(), []fleet.Target{
+		{Host: "a", Session: missing},
+		{Host: "down", Session: "x"},
+	}, fleet.SendRequest{Message: "hello", All: true})
+
+	if len(results) != 2 {
+		t.Fatalf("results = %d, want 2", len(results))
+	}
+	if results[0].Success || !strings.Contains(results[0].Error, "not found") {
+		t.Errorf("result[0] = %+v, want session not found", results[0])
+	}
+	if results[1].Success || results[1].Error == "" {
+		t.Errorf("result[1] = %+v, want connection error", results[1])
+	}
+}
+
+func TestWatchRefreshesOnEvents(t *testing.T) {
+	h := startServe(t, "")
+	f, err := fleet.New([]fleet.Host{{Name: "h", URL: h.url}}, 2*time.Second)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := make(chan *fleet.Status, 16)
+	go f.Watch(ctx, time.Hour, func(st *fleet.Status) {
+		select {
+		case updates <- st:
+		default:
+		}
+	})
+
+	// Initial poll.
+	select {
+	case <-updates:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no initial update")
+	}
+
+	addSession(t, h, "fleet-watched", state.SessionActive)
+	deadline := time.After(5 * time.Second)
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case st := <-updates:
+			if hasSession(findHost(st, "h"), "fleet-watched") {
+				return
+			}
+		case <-tick.C:
+			h.bus.Publish(events.BaseEvent{Type: "session_create", Timestamp: time.Now().UTC(), Session: "fleet-watched"})
+		case <-deadline:
+			t.Fatal("watch never refreshed after a WebSocket event")
+		}
+	}
+}
+
+func TestNewValidatesHosts(t *testing.T) {
+	tests := []struct {
+		name  string
+		hosts []fleet.Host
+	}{
+		{"empty", nil},
+		{"no name", []fleet.Host{{URL: "http://localhost:7337"}}},
+		{"colon in name", []fleet.Host{{Name: "a:b", URL: "http://localhost:7337"}}},
+		{"duplicate", []fleet.Host{{Name: "a", URL: "http://x:1"}, {Name: "a", URL: "http://y:1"}}},
+		{"bad scheme", []fleet.Host{{Name: "a", URL: "ftp://x"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := fleet.New(tt.hosts, 0); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestParseAndResolveTargets(t *testing.T) {
+	if _, err := fleet.ParseTarget("nosession"); err == nil {
+		t.Error("expected error for target without session")
+	}
+	tgt, err := fleet.ParseTarget("web:proj")
+	if err != nil || tgt.Host != "web" || tgt.Session != "proj" {
+		t.Fatalf("ParseTarget = %+v, %v", tgt, err)
+	}
+
+	f, err := fleet.New([]fleet.Host{
+		{Name: "web", URL: "http://web:7337"},
+		{Name: "gpu", URL: "http://gpu:7337"},
+	}, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	st := &fleet.Status{Hosts: []fleet.HostStatus{
+		{Name: "web", Sessions: []fleet.Session{{Name: "proj"}, {Name: "api"}}},
+		{Name: "gpu", Sessions: []fleet.Session{{Name: "proj"}}},
+	}}
+
+	got, err := f.Resolve([]fleet.Target{{Host: "*", Session: "proj"}, {Host: "web", Session: "*"}}, st)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	want := []string{"web:proj", "gpu:proj", "web:api"}
+	if len(got) != len(want) {
+		t.Fatalf("Resolve = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Errorf("target[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	if _, err := f.Resolve([]fleet.Target{{Host: "nope", Session: "proj"}}, st); err == nil {
+		t.Error("expected error for unknown host")
+	}
+	if _, err := f.Resolve([]fleet.Target{{Host: "*", Session: "missing"}}, st); err == nil {
+		t.Error("expected error when wildcard matches nothing")
+	}
+}
@@ -0,0 +1,96 @@
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Watch polls every host each interval and re-polls a host as soon as its
+// /api/v1/ws stream reports a session event, calling update with each new
+// snapshot. It blocks until ctx is done. Hosts whose stream cannot be opened
+// are still covered by the interval poll, and their streams are retried with
+// backoff.
+func (f *Fleet) Watch(ctx context.Context, interval time.Duration, update func(*Status)) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	dirty := make(chan string, len(f.hosts))
+	for _, h := range f.hosts {
+		go f.watchEvents(ctx, f.clients[h.Name], dirty)
+	}
+
+	update(f.Poll(ctx))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			update(f.Poll(ctx))
+		case name := <-dirty:
+			f.PollHost(ctx, name)
+			update(f.Snapshot())
+		}
+	}
+}
+
+// watchEvents keeps a WebSocket subscription open to one host and signals
+// dirty whenever it delivers an event.
+func (f *Fleet) watchEvents(ctx context.Context, c *client, dirty chan<- string) {
+	delay := minReconnectDelay
+	for ctx.Err() == nil {
+		conn, err := c.dialEvents(ctx)
+		if err == nil {
+			delay = minReconnectDelay
+			stop := context.AfterFunc(ctx, func() { conn.Close() })
+			for {
+				_, data, err := conn.ReadMessage()
+				if err != nil {
+					break
+				}
+				if !hasEvent(data) {
+					continue
+				}
+				select {
+				case dirty <- c.host.Name:
+				default:
+					// A refresh for this host is already pending.
+				}
+			}
+			stop()
+			conn.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// hasEvent reports whether a WebSocket frame carries an event. The server
+// batches queued messages into one frame separated by newlines.
+func hasEvent(frame []byte) bool {
+	for _, line := range bytes.Split(frame, []byte{'\n'}) {
+		var ev wsEvent
+		if json.Unmarshal(line, &ev) == nil && ev.Type == "event" {
+			return true
+		}
+	}
+	return false
+}
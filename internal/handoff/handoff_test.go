@@ -1,6 +1,7 @@
 package handoff
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -659,3 +660,16 @@ func TestTruncate(t *testing.T) {
 		})
 	}
 }
+
+func TestResumeContext(t *testing.T) {
+	h := New("proj").WithGoalAndNow("Implemented auth", "Add rate limiting")
+	h.AddBlocker("Redis not configured")
+	h.Test = "go test ./..."
+
+	got := h.ResumeContext()
+	for _, want := range []string{"Implemented auth", "Add rate limiting", "Redis not configured", "go test ./...", "continue from where"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ResumeContext() missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -18,20 +18,22 @@ const (
 	SourceCLI     Source = "cli"
 	SourcePalette Source = "palette"
 	SourceReplay  Source = "replay"
+	SourceQueue   Source = "queue"
 )
 
 // HistoryEntry represents a single prompt sent via ntm send.
 type HistoryEntry struct {
-	ID         string    `json:"id"`                    // Unique ID (timestamp-random)
-	Timestamp  time.Time `json:"ts"`                    // When sent
-	Session    string    `json:"session"`               // Session name
-	Targets    []string  `json:"targets"`               // Pane indices sent to
-	Prompt     string    `json:"prompt"`                // Full prompt text
-	Source     Source    `json:"source"`                // cli, palette, replay
-	Template   string    `json:"template,omitempty"`    // Template name if used
-	Success    bool      `json:"success"`               // Whether send succeeded
-	Error      string    `json:"error,omitempty"`       // Error message if failed
-	DurationMs int       `json:"duration_ms,omitempty"` // How long the operation took
+	ID         string     `json:"id"`                    // Unique ID (timestamp-random)
+	Timestamp  time.Time  `json:"ts"`                    // When sent
+	Session    string     `json:"session"`               // Session name
+	Targets    []string   `json:"targets"`               // Pane indices sent to
+	Prompt     string     `json:"prompt"`                // Full prompt text
+	Source     Source     `json:"source"`                // cli, palette, replay, queue
+	Template   string     `json:"template,omitempty"`    // Template name if used
+	Success    bool       `json:"success"`               // Whether send succeeded
+	Error      string     `json:"error,omitempty"`       // Error message if failed
+	DurationMs int        `json:"duration_ms,omitempty"` // How long the operation took
+	QueuedAt   *time.Time `json:"queued_at,omitempty"`   // When the prompt was queued, if it waited for an idle pane
 }
 
 // NewEntry creates a new history entry with generated ID and timestamp.
@@ -0,0 +1,202 @@
+package kernel
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DecodeInput converts a loosely-typed handler input (typically a
+// map[string]any decoded from a REST or MCP request body, or raw JSON) into
+// dst, which must be a pointer to the command's input struct. A nil input
+// leaves dst untouched.
+func DecodeInput(input any, dst any) error {
+	if input == nil {
+		return nil
+	}
+
+	var data []byte
+	switch value := input.(type) {
+	case json.RawMessage:
+		data = value
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("encode input: %w", err)
+		}
+		data = encoded
+	}
+
+	if len(strings.TrimSpace(string(data))) == 0 || string(data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("decode input: %w", err)
+	}
+	return nil
+}
+
+var (
+	schemaTypesMu sync.RWMutex
+	schemaTypes   = make(map[string]reflect.Type)
+)
+
+// RegisterSchemaType associates a SchemaRef.Ref identifier with the Go type
+// it describes so JSON Schemas can be derived for tool and API surfaces.
+func RegisterSchemaType(ref string, sample any) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || sample == nil {
+		return
+	}
+	t := reflect.TypeOf(sample)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	schemaTypesMu.Lock()
+	schemaTypes[ref] = t
+	schemaTypesMu.Unlock()
+}
+
+// JSONSchema returns a JSON Schema object for a registered schema ref.
+// The boolean is false when no Go type has been registered for ref.
+func JSONSchema(ref string) (map[string]any, bool) {
+	schemaTypesMu.RLock()
+	t, ok := schemaTypes[strings.TrimSpace(ref)]
+	schemaTypesMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return schemaForType(t, 0), true
+}
+
+// SchemaOf returns a JSON Schema object for ref, derived from ref.Type when
+// the command supplied one and otherwise from the type registered for
+// ref.Ref. The boolean is false when neither is available.
+func SchemaOf(ref *SchemaRef) (map[string]any, bool) {
+	if ref == nil {
+		return nil, false
+	}
+	if ref.Type != nil {
+		return schemaForType(reflect.TypeOf(ref.Type), 0), true
+	}
+	return JSONSchema(ref.Ref)
+}
+
+// maxSchemaDepth bounds recursion for self-referential types.
+const maxSchemaDepth = 8
+
+func schemaForType(t reflect.Type, depth int) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if depth > maxSchemaDepth {
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if t.PkgPath() == "time" && t.Name() == "Duration" {
+			return map[string]any{"type": "integer", "description": "duration in nanoseconds"}
+		}
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": schemaForType(t.Elem(), depth+1)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaForType(t.Elem(), depth+1)}
+	case reflect.Struct:
+		if t.PkgPath() == "time" && t.Name() == "Time" {
+			return map[string]any{"type": "string", "format": "date-time"}
+		}
+		return structSchema(t, depth)
+	default:
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type, depth int) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitEmpty, skip := parseJSONTag(field)
+		if skip {
+			continue
+		}
+
+		// Flatten embedded structs without an explicit JSON name.
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := structSchema(ft, depth+1)
+				if props, ok := embedded["properties"].(map[string]any); ok {
+					for k, v := range props {
+						properties[k] = v
+					}
+				}
+				if req, ok := embedded["required"].([]string); ok {
+					required = append(required, req...)
+				}
+				continue
+			}
+		}
+
+		properties[name] = schemaForType(field.Type, depth+1)
+		if !omitEmpty && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+func parseJSONTag(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
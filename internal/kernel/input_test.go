@@ -0,0 +1,137 @@
+package kernel
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type decodeTestInput struct {
+	Session string   `json:"session"`
+	Pane    *int     `json:"pane,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+func TestDecodeInputFromMap(t *testing.T) {
+	var got decodeTestInput
+	err := DecodeInput(map[string]any{
+		"session": "proj",
+		"pane":    float64(2),
+		"tags":    []any{"a", "b"},
+	}, &got)
+	if err != nil {
+		t.Fatalf("DecodeInput: %v", err)
+	}
+	if got.Session != "proj" || got.Pane == nil || *got.Pane != 2 {
+		t.Fatalf("unexpected decode result: %+v", got)
+	}
+	if !reflect.DeepEqual(got.Tags, []string{"a", "b"}) {
+		t.Fatalf("tags = %v", got.Tags)
+	}
+}
+
+func TestDecodeInputFromRawJSON(t *testing.T) {
+	var got decodeTestInput
+	if err := DecodeInput(json.RawMessage(`{"session":"raw"}`), &got); err != nil {
+		t.Fatalf("DecodeInput: %v", err)
+	}
+	if got.Session != "raw" {
+		t.Fatalf("session = %q, want raw", got.Session)
+	}
+}
+
+func TestDecodeInputNilAndNull(t *testing.T) {
+	got := decodeTestInput{Session: "keep"}
+	if err := DecodeInput(nil, &got); err != nil {
+		t.Fatalf("DecodeInput(nil): %v", err)
+	}
+	if err := DecodeInput(json.RawMessage("null"), &got); err != nil {
+		t.Fatalf("DecodeInput(null): %v", err)
+	}
+	if got.Session != "keep" {
+		t.Fatalf("nil input should leave dst untouched, got %+v", got)
+	}
+}
+
+func TestDecodeInputTypeMismatch(t *testing.T) {
+	var got decodeTestInput
+	if err := DecodeInput(map[string]any{"session": 42}, &got); err == nil {
+		t.Fatal("expected error for mismatched field type")
+	}
+}
+
+func TestJSONSchemaFromRegisteredType(t *testing.T) {
+	type nested struct {
+		When time.Time `json:"when"`
+	}
+	type schemaInput struct {
+		Session  string            `json:"session"`
+		Pane     *int              `json:"pane,omitempty"`
+		Verbose  bool              `json:"verbose,omitempty"`
+		Labels   map[string]string `json:"labels,omitempty"`
+		Nested   nested            `json:"nested"`
+		internal string
+		Skipped  string `json:"-"`
+	}
+
+	RegisterSchemaType("kernel.schemaInput", &schemaInput{})
+	schema, ok := JSONSchema("kernel.schemaInput")
+	if !ok {
+		t.Fatal("expected schema to be registered")
+	}
+
+	if schema["type"] != "object" {
+		t.Fatalf("type = %v, want object", schema["type"])
+	}
+	props := schema["properties"].(map[string]any)
+	for _, name := range []string{"session", "pane", "verbose", "labels", "nested"} {
+		if _, ok := props[name]; !ok {
+			t.Errorf("missing property %q", name)
+		}
+	}
+	if _, ok := props["Skipped"]; ok {
+		t.Error("json:\"-\" field should be skipped")
+	}
+	if _, ok := props["internal"]; ok {
+		t.Error("unexported field should be skipped")
+	}
+	if got := props["pane"].(map[string]any)["type"]; got != "integer" {
+		t.Errorf("pane type = %v, want integer", got)
+	}
+	nestedProps := props["nested"].(map[string]any)["properties"].(map[string]any)
+	if got := nestedProps["when"].(map[string]any)["format"]; got != "date-time" {
+		t.Errorf("time format = %v, want date-time", got)
+	}
+
+	required, _ := schema["required"].([]string)
+	if !reflect.DeepEqual(required, []string{"nested", "session"}) {
+		t.Errorf("required = %v, want [nested session]", required)
+	}
+}
+
+func TestJSONSchemaUnknownRef(t *testing.T) {
+	if _, ok := JSONSchema("kernel.doesNotExist"); ok {
+		t.Fatal("expected unknown ref to be reported missing")
+	}
+}
+
+func TestSchemaOfUsesSchemaRefType(t *testing.T) {
+	type typedInput struct {
+		Session string `json:"session"`
+	}
+
+	schema, ok := SchemaOf(&SchemaRef{Ref: "kernel.typedInput", Type: typedInput{}})
+	if !ok {
+		t.Fatal("expected schema derived from SchemaRef.Type")
+	}
+	if _, ok := schema["properties"].(map[string]any)["session"]; !ok {
+		t.Errorf("schema = %v, want session property", schema)
+	}
+	if _, ok := SchemaOf(&SchemaRef{Ref: "kernel.doesNotExist"}); ok {
+		t.Error("ref without a type or registration should be reported missing")
+	}
+	if _, ok := SchemaOf(nil); ok {
+		t.Error("nil ref should be reported missing")
+	}
+}
@@ -108,6 +108,15 @@ func (r *Registry) Get(name string) (Command, bool) {
 	return cmd, ok
 }
 
+// HasHandler reports whether a handler is registered for the named command.
+func (r *Registry) HasHandler(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.handlers[name]
+	return ok
+}
+
 // List returns all commands in deterministic order.
 func (r *Registry) List() []Command {
 	r.mu.RLock()
@@ -198,6 +207,12 @@ func logRegisterError(cmd Command, err error) {
 
 var defaultRegistry = NewRegistry()
 
+// DefaultRegistry returns the process-wide registry populated by init-time
+// command registrations.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
 // Register adds a command to the default registry.
 func Register(cmd Command) error {
 	return defaultRegistry.Register(cmd)
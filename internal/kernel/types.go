@@ -15,6 +15,11 @@ type SchemaRef struct {
 	Name        string `json:"name,omitempty"`
 	Ref         string `json:"ref,omitempty"`
 	Description string `json:"description,omitempty"`
+
+	// Type is a sample value of the Go type the schema describes
+	// (e.g. SessionCreateInput{}). When set, SchemaOf derives the JSON
+	// Schema from it without a separate RegisterSchemaType call.
+	Type any `json:"-"`
 }
 
 // RESTBinding describes the REST endpoint mapping for a command.
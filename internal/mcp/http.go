@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// HTTPHandler returns an http.Handler implementing the MCP streamable HTTP
+// transport in its stateless JSON form: clients POST a single JSON-RPC
+// message and receive the JSON response in the body. Notifications are
+// acknowledged with 202 Accepted. Server-initiated streams (GET) are not
+// offered, so GET returns 405 as the specification allows.
+//
+// The handler performs no authentication of its own; tool calls act with
+// the server's tmux access, so callers must restrict who can reach it.
+// ntm mcp serve binds it to loopback or wraps it with serve.RequireAPIKey.
+func (s *Server) HTTPHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if ct := r.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "application/json") {
+			http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+		if err != nil {
+			http.Error(w, "read body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := s.HandleMessage(r.Context(), body)
+		if resp == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			s.logger.Error("mcp http write failed", "error", err)
+		}
+	})
+}
@@ -0,0 +1,478 @@
+// Package mcp implements a Model Context Protocol server that exposes the
+// kernel command registry as MCP tools. It speaks JSON-RPC 2.0 over stdio
+// (newline-delimited messages) and over streamable HTTP (single POST
+// endpoint returning JSON), so controller agents can drive ntm natively
+// instead of shelling out to --robot-* flags.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/approval"
+	"github.com/Dicklesworthstone/ntm/internal/kernel"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+const (
+	// ProtocolVersion is the latest MCP protocol revision this server implements.
+	ProtocolVersion = "2025-06-18"
+
+	// ServerName is reported to clients during initialization.
+	ServerName = "ntm"
+
+	// DefaultApprovalTimeout is how long a dangerous tool call waits for a
+	// human decision before it is rejected.
+	DefaultApprovalTimeout = 5 * time.Minute
+
+	// maxMessageSize caps a single JSON-RPC message read from stdio.
+	maxMessageSize = 10 << 20
+)
+
+// supportedProtocolVersions lists revisions accepted during initialization.
+var supportedProtocolVersions = []string{
+	"2025-06-18",
+	"2025-03-26",
+	"2024-11-05",
+}
+
+// JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Request is a JSON-RPC 2.0 request or notification.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// IsNotification reports whether the request carries no ID and therefore
+// expects no response.
+func (r *Request) IsNotification() bool {
+	return len(r.ID) == 0 || string(r.ID) == "null"
+}
+
+// Response is a JSON-RPC 2.0 response.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError is a JSON-RPC 2.0 error object.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// Approver gates dangerous tool calls behind a human decision.
+// *approval.Engine satisfies this interface.
+type Approver interface {
+	Request(ctx context.Context, params approval.RequestParams) (*state.Approval, error)
+	WaitForApproval(ctx context.Context, id string, timeout time.Duration) (*state.Approval, error)
+}
+
+// Config configures an MCP server.
+type Config struct {
+	// Registry supplies the commands published as tools.
+	// If nil, the default kernel registry is used.
+	Registry *kernel.Registry
+
+	// Version is reported in serverInfo during initialization.
+	Version string
+
+	// Approver routes danger-level commands through human approval.
+	// If nil, danger-level commands are refused.
+	Approver Approver
+
+	// ApprovalTimeout bounds how long a dangerous call waits for approval.
+	ApprovalTimeout time.Duration
+
+	// RequestedBy identifies this server in approval requests.
+	RequestedBy string
+
+	// Logger receives diagnostic output. Stdout is reserved for protocol
+	// traffic in stdio mode, so this must never write there.
+	Logger *slog.Logger
+}
+
+// Server dispatches MCP requests to kernel command handlers.
+type Server struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu          sync.RWMutex
+	initialized bool
+	clientInfo  ClientInfo
+}
+
+// NewServer creates an MCP server with the given configuration.
+func NewServer(cfg Config) *Server {
+	if cfg.Version == "" {
+		cfg.Version = "dev"
+	}
+	if cfg.ApprovalTimeout <= 0 {
+		cfg.ApprovalTimeout = DefaultApprovalTimeout
+	}
+	if cfg.RequestedBy == "" {
+		cfg.RequestedBy = "mcp"
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Server{cfg: cfg, logger: logger}
+}
+
+// ServeStdio reads newline-delimited JSON-RPC messages from r and writes
+// responses to w until r is exhausted or ctx is cancelled. Requests are
+// handled concurrently so a long-running tool call (e.g. one awaiting
+// approval) does not block pings or other calls.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+
+	var (
+		writeMu sync.Mutex
+		wg      sync.WaitGroup
+	)
+	enc := json.NewEncoder(w)
+	write := func(resp *Response) {
+		if resp == nil {
+			return
+		}
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := enc.Encode(resp); err != nil {
+			s.logger.Error("mcp write failed", "error", err)
+		}
+	}
+
+	lines := make(chan []byte)
+	scanErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		scanErr <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case line, ok := <-lines:
+			if !ok {
+				wg.Wait()
+				select {
+				case err := <-scanErr:
+					return err
+				default:
+					return nil
+				}
+			}
+			if len(strings.TrimSpace(string(line))) == 0 {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				write(s.HandleMessage(ctx, line))
+			}()
+		}
+	}
+}
+
+// HandleMessage processes a single raw JSON-RPC message and returns the
+// response to send, or nil for notifications.
+func (s *Server) HandleMessage(ctx context.Context, data []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errorResponse(nil, CodeParseError, "parse error: "+err.Error())
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, CodeInvalidRequest, "invalid JSON-RPC 2.0 request")
+	}
+
+	result, rpcErr := s.dispatch(ctx, &req)
+	if req.IsNotification() {
+		return nil
+	}
+	if rpcErr != nil {
+		return &Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+	return &Response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) dispatch(ctx context.Context, req *Request) (any, *RPCError) {
+	switch req.Method {
+	case "initialize":
+		return s.handleInitialize(req.Params)
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return ListToolsResult{Tools: s.Tools()}, nil
+	case "tools/call":
+		return s.handleToolCall(ctx, req.Params)
+	default:
+		return nil, &RPCError{Code: CodeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+}
+
+// ClientInfo identifies the connected MCP client.
+type ClientInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// InitializeParams is the params object of an initialize request.
+type InitializeParams struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities,omitempty"`
+	ClientInfo      ClientInfo     `json:"clientInfo"`
+}
+
+// InitializeResult is the result of an initialize request.
+type InitializeResult struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities"`
+	ServerInfo      ServerInfo     `json:"serverInfo"`
+	Instructions    string         `json:"instructions,omitempty"`
+}
+
+// ServerInfo identifies this server.
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func (s *Server) handleInitialize(raw json.RawMessage) (any, *RPCError) {
+	var params InitializeParams
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, &RPCError{Code: CodeInvalidParams, Message: "invalid initialize params: " + err.Error()}
+		}
+	}
+
+	version := ProtocolVersion
+	for _, v := range supportedProtocolVersions {
+		if v == params.ProtocolVersion {
+			version = v
+			break
+		}
+	}
+
+	s.mu.Lock()
+	s.initialized = true
+	s.clientInfo = params.ClientInfo
+	s.mu.Unlock()
+
+	s.logger.Info("mcp client initialized",
+		"client", params.ClientInfo.Name,
+		"client_version", params.ClientInfo.Version,
+		"protocol", version,
+	)
+
+	return InitializeResult{
+		ProtocolVersion: version,
+		Capabilities: map[string]any{
+			"tools": map[string]any{"listChanged": false},
+		},
+		ServerInfo: ServerInfo{Name: ServerName, Version: s.cfg.Version},
+		Instructions: "Tools mirror ntm kernel commands. Danger-level tools " +
+			"require human approval via `ntm approve` before they run.",
+	}, nil
+}
+
+// CallToolParams is the params object of a tools/call request.
+type CallToolParams struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+// CallToolResult is the result of a tools/call request.
+type CallToolResult struct {
+	Content           []ContentBlock `json:"content"`
+	StructuredContent any            `json:"structuredContent,omitempty"`
+	IsError           bool           `json:"isError,omitempty"`
+}
+
+// ContentBlock is a single MCP content item.
+type ContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+func (s *Server) handleToolCall(ctx context.Context, raw json.RawMessage) (any, *RPCError) {
+	var params CallToolParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, &RPCError{Code: CodeInvalidParams, Message: "invalid tools/call params: " + err.Error()}
+	}
+
+	cmd, ok := s.lookupTool(params.Name)
+	if !ok {
+		return nil, &RPCError{Code: CodeInvalidParams, Message: "unknown tool: " + params.Name}
+	}
+
+	if cmd.SafetyLevel == kernel.SafetyDanger {
+		if err := s.awaitApproval(ctx, cmd, params.Arguments); err != nil {
+			return toolError(err), nil
+		}
+	}
+
+	var input any
+	if params.Arguments != nil {
+		input = params.Arguments
+	}
+
+	start := time.Now()
+	result, err := s.registry().Run(ctx, cmd.Name, input)
+	s.logger.Info("mcp tool call",
+		"tool", params.Name,
+		"command", cmd.Name,
+		"duration", time.Since(start),
+		"error", err,
+	)
+	if err != nil {
+		return toolError(err), nil
+	}
+	return toolSuccess(result)
+}
+
+// ErrApprovalDenied is returned when a dangerous tool call is not approved.
+var ErrApprovalDenied = errors.New("approval not granted")
+
+func (s *Server) awaitApproval(ctx context.Context, cmd kernel.Command, args map[string]any) error {
+	if s.cfg.Approver == nil {
+		return fmt.Errorf("%s is a danger-level command and no approval engine is configured", cmd.Name)
+	}
+
+	resource := approvalResource(args)
+	appr, err := s.cfg.Approver.Request(ctx, approval.RequestParams{
+		Action:      "mcp:" + cmd.Name,
+		Resource:    resource,
+		Reason:      fmt.Sprintf("MCP client %q requested %s", s.clientName(), cmd.Name),
+		RequestedBy: s.cfg.RequestedBy,
+		ExpiresIn:   s.cfg.ApprovalTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("request approval: %w", err)
+	}
+
+	s.logger.Info("mcp tool awaiting approval",
+		"command", cmd.Name,
+		"approval_id", appr.ID,
+		"timeout", s.cfg.ApprovalTimeout,
+	)
+
+	decided, err := s.cfg.Approver.WaitForApproval(ctx, appr.ID, s.cfg.ApprovalTimeout)
+	if err != nil {
+		return fmt.Errorf("wait for approval %s: %w", appr.ID, err)
+	}
+	if decided.Status != state.ApprovalApproved {
+		reason := string(decided.Status)
+		if decided.DeniedReason != "" {
+			reason += ": " + decided.DeniedReason
+		}
+		return fmt.Errorf("%w for %s (approval %s %s)", ErrApprovalDenied, cmd.Name, appr.ID, reason)
+	}
+	return nil
+}
+
+func (s *Server) clientName() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.clientInfo.Name == "" {
+		return "unknown"
+	}
+	return s.clientInfo.Name
+}
+
+func (s *Server) registry() *kernel.Registry {
+	if s.cfg.Registry != nil {
+		return s.cfg.Registry
+	}
+	return kernel.DefaultRegistry()
+}
+
+// approvalResource derives a human-readable resource from tool arguments.
+func approvalResource(args map[string]any) string {
+	for _, key := range []string{"session", "name", "target"} {
+		if v, ok := args[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	if len(args) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func toolSuccess(result any) (any, *RPCError) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, &RPCError{Code: CodeInternalError, Message: "encode tool result: " + err.Error()}
+	}
+
+	out := CallToolResult{
+		Content: []ContentBlock{{Type: "text", Text: string(data)}},
+	}
+	// structuredContent must be a JSON object per the MCP spec.
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err == nil && obj != nil {
+		out.StructuredContent = obj
+	}
+	return out, nil
+}
+
+func toolError(err error) CallToolResult {
+	return CallToolResult{
+		Content: []ContentBlock{{Type: "text", Text: err.Error()}},
+		IsError: true,
+	}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   &RPCError{Code: code, Message: message},
+	}
+}
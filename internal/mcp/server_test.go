@@ -0,0 +1,351 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/approval"
+	"github.com/Dicklesworthstone/ntm/internal/kernel"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+type echoInput struct {
+	Session string `json:"session"`
+	Count   int    `json:"count,omitempty"`
+}
+
+func newTestRegistry(t *testing.T) *kernel.Registry {
+	t.Helper()
+	reg := kernel.NewRegistry()
+	kernel.RegisterSchemaType("mcp.echoInput", echoInput{})
+
+	register := func(cmd kernel.Command, h kernel.HandlerFunc) {
+		if err := reg.Register(cmd); err != nil {
+			t.Fatalf("register %s: %v", cmd.Name, err)
+		}
+		if h != nil {
+			if err := reg.RegisterHandler(cmd.Name, h); err != nil {
+				t.Fatalf("register handler %s: %v", cmd.Name, err)
+			}
+		}
+	}
+
+	register(kernel.Command{
+		Name:        "test.echo",
+		Description: "Echo the session",
+		Category:    "test",
+		Input:       &kernel.SchemaRef{Name: "EchoInput", Ref: "mcp.echoInput"},
+		REST:        &kernel.RESTBinding{Method: "GET", Path: "/echo"},
+		Examples:    []kernel.Example{{Name: "echo", Description: "Echo", Command: "ntm echo proj"}},
+		SafetyLevel: kernel.SafetySafe,
+		Idempotent:  true,
+	}, func(ctx context.Context, input any) (any, error) {
+		var in echoInput
+		if err := kernel.DecodeInput(input, &in); err != nil {
+			return nil, err
+		}
+		if in.Session == "" {
+			return nil, errors.New("session is required")
+		}
+		return map[string]any{"session": in.Session, "count": in.Count}, nil
+	})
+
+	register(kernel.Command{
+		Name:        "test.destroy",
+		Description: "Destroy the session",
+		Category:    "test",
+		Input:       &kernel.SchemaRef{Name: "EchoInput", Ref: "mcp.echoInput"},
+		Examples:    []kernel.Example{{Name: "destroy", Command: "ntm destroy proj"}},
+		SafetyLevel: kernel.SafetyDanger,
+	}, func(ctx context.Context, input any) (any, error) {
+		return map[string]any{"destroyed": true}, nil
+	})
+
+	register(kernel.Command{
+		Name:        "test.nohandler",
+		Description: "Metadata only",
+		Category:    "test",
+		Examples:    []kernel.Example{{Name: "none", Command: "ntm none"}},
+	}, nil)
+
+	return reg
+}
+
+func quietLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func call(t *testing.T, s *Server, method string, params any) *Response {
+	t.Helper()
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("marshal params: %v", err)
+	}
+	msg, _ := json.Marshal(Request{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: method, Params: raw})
+	resp := s.HandleMessage(context.Background(), msg)
+	if resp == nil {
+		t.Fatalf("%s: expected response", method)
+	}
+	return resp
+}
+
+func decodeResult[T any](t *testing.T, resp *Response) T {
+	t.Helper()
+	if resp.Error != nil {
+		t.Fatalf("unexpected rpc error: %v", resp.Error)
+	}
+	data, _ := json.Marshal(resp.Result)
+	var out T
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	return out
+}
+
+func TestInitializeNegotiatesVersion(t *testing.T) {
+	s := NewServer(Config{Registry: newTestRegistry(t), Version: "1.2.3", Logger: quietLogger()})
+
+	res := decodeResult[InitializeResult](t, call(t, s, "initialize", InitializeParams{
+		ProtocolVersion: "2025-03-26",
+		ClientInfo:      ClientInfo{Name: "tester"},
+	}))
+	if res.ProtocolVersion != "2025-03-26" {
+		t.Errorf("protocolVersion = %q, want 2025-03-26", res.ProtocolVersion)
+	}
+	if res.ServerInfo.Name != ServerName || res.ServerInfo.Version != "1.2.3" {
+		t.Errorf("serverInfo = %+v", res.ServerInfo)
+	}
+	if _, ok := res.Capabilities["tools"]; !ok {
+		t.Error("expected tools capability")
+	}
+
+	res = decodeResult[InitializeResult](t, call(t, s, "initialize", InitializeParams{ProtocolVersion: "1999-01-01"}))
+	if res.ProtocolVersion != ProtocolVersion {
+		t.Errorf("unsupported version should fall back to %q, got %q", ProtocolVersion, res.ProtocolVersion)
+	}
+}
+
+func TestToolsListUsesKernelMetadata(t *testing.T) {
+	s := NewServer(Config{Registry: newTestRegistry(t), Logger: quietLogger()})
+
+	res := decodeResult[ListToolsResult](t, call(t, s, "tools/list", nil))
+	if len(res.Tools) != 2 {
+		t.Fatalf("expected 2 tools (handler-less command skipped), got %d", len(res.Tools))
+	}
+
+	byName := map[string]Tool{}
+	for _, tool := range res.Tools {
+		byName[tool.Name] = tool
+	}
+
+	echo, ok := byName["test_echo"]
+	if !ok {
+		t.Fatalf("missing test_echo tool: %v", byName)
+	}
+	if echo.InputSchema["type"] != "object" {
+		t.Errorf("input schema type = %v", echo.InputSchema["type"])
+	}
+	props, _ := echo.InputSchema["properties"].(map[string]any)
+	if _, ok := props["session"]; !ok {
+		t.Errorf("expected session property, got %v", props)
+	}
+	if !echo.Annotations.ReadOnlyHint || echo.Annotations.DestructiveHint || !echo.Annotations.IdempotentHint {
+		t.Errorf("unexpected echo annotations: %+v", echo.Annotations)
+	}
+	if !strings.Contains(echo.Description, "ntm echo proj") {
+		t.Errorf("description should include examples: %q", echo.Description)
+	}
+
+	destroy := byName["test_destroy"]
+	if !destroy.Annotations.DestructiveHint || destroy.Annotations.ReadOnlyHint {
+		t.Errorf("unexpected destroy annotations: %+v", destroy.Annotations)
+	}
+	if !strings.Contains(destroy.Description, "approval") {
+		t.Errorf("danger description should mention approval: %q", destroy.Description)
+	}
+}
+
+func TestToolsCall(t *testing.T) {
+	s := NewServer(Config{Registry: newTestRegistry(t), Logger: quietLogger()})
+
+	res := decodeResult[CallToolResult](t, call(t, s, "tools/call", CallToolParams{
+		Name:      "test_echo",
+		Arguments: map[string]any{"session": "proj", "count": 3},
+	}))
+	if res.IsError {
+		t.Fatalf("unexpected tool error: %+v", res)
+	}
+	structured, _ := res.StructuredContent.(map[string]any)
+	if structured["session"] != "proj" || structured["count"] != float64(3) {
+		t.Errorf("structuredContent = %v", res.StructuredContent)
+	}
+	if len(res.Content) != 1 || !strings.Contains(res.Content[0].Text, `"proj"`) {
+		t.Errorf("content = %+v", res.Content)
+	}
+
+	// Handler errors are tool errors, not protocol errors.
+	res = decodeResult[CallToolResult](t, call(t, s, "tools/call", CallToolParams{Name: "test_echo"}))
+	if !res.IsError || !strings.Contains(res.Content[0].Text, "session is required") {
+		t.Errorf("expected tool error, got %+v", res)
+	}
+
+	resp := call(t, s, "tools/call", CallToolParams{Name: "test_nohandler"})
+	if resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Errorf("expected invalid params for handler-less tool, got %+v", resp)
+	}
+}
+
+type fakeApprover struct {
+	status    state.ApprovalStatus
+	requested []approval.RequestParams
+}
+
+func (f *fakeApprover) Request(ctx context.Context, params approval.RequestParams) (*state.Approval, error) {
+	f.requested = append(f.requested, params)
+	return &state.Approval{ID: "appr-1", Status: state.ApprovalPending}, nil
+}
+
+func (f *fakeApprover) WaitForApproval(ctx context.Context, id string, timeout time.Duration) (*state.Approval, error) {
+	return &state.Approval{ID: id, Status: f.status, DeniedReason: "nope"}, nil
+}
+
+func TestDangerToolRequiresApproval(t *testing.T) {
+	reg := newTestRegistry(t)
+	args := CallToolParams{Name: "test_destroy", Arguments: map[string]any{"session": "proj"}}
+
+	t.Run("no approver", func(t *testing.T) {
+		s := NewServer(Config{Registry: reg, Logger: quietLogger()})
+		res := decodeResult[CallToolResult](t, call(t, s, "tools/call", args))
+		if !res.IsError {
+			t.Fatal("danger tool without approver should fail")
+		}
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		approver := &fakeApprover{status: state.ApprovalDenied}
+		s := NewServer(Config{Registry: reg, Approver: approver, Logger: quietLogger()})
+		res := decodeResult[CallToolResult](t, call(t, s, "tools/call", args))
+		if !res.IsError || !strings.Contains(res.Content[0].Text, "nope") {
+			t.Fatalf("expected denial, got %+v", res)
+		}
+		if len(approver.requested) != 1 {
+			t.Fatalf("expected one approval request, got %d", len(approver.requested))
+		}
+		req := approver.requested[0]
+		if req.Action != "mcp:test.destroy" || req.Resource != "proj" {
+			t.Errorf("unexpected approval params: %+v", req)
+		}
+	})
+
+	t.Run("approved", func(t *testing.T) {
+		approver := &fakeApprover{status: state.ApprovalApproved}
+		s := NewServer(Config{Registry: reg, Approver: approver, Logger: quietLogger()})
+		res := decodeResult[CallToolResult](t, call(t, s, "tools/call", args))
+		if res.IsError {
+			t.Fatalf("approved call failed: %+v", res)
+		}
+	})
+}
+
+func TestHandleMessageErrors(t *testing.T) {
+	s := NewServer(Config{Registry: newTestRegistry(t), Logger: quietLogger()})
+
+	if resp := s.HandleMessage(context.Background(), []byte("{not json")); resp.Error == nil || resp.Error.Code != CodeParseError {
+		t.Errorf("expected parse error, got %+v", resp)
+	}
+	if resp := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"1.0","id":1,"method":"ping"}`)); resp.Error == nil || resp.Error.Code != CodeInvalidRequest {
+		t.Errorf("expected invalid request, got %+v", resp)
+	}
+	if resp := call(t, s, "resources/list", nil); resp.Error == nil || resp.Error.Code != CodeMethodNotFound {
+		t.Errorf("expected method not found, got %+v", resp)
+	}
+	if resp := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)); resp != nil {
+		t.Errorf("notifications must not produce a response, got %+v", resp)
+	}
+}
+
+func TestServeStdio(t *testing.T) {
+	s := NewServer(Config{Registry: newTestRegistry(t), Logger: quietLogger()})
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"t"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	if err := s.ServeStdio(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatalf("ServeStdio: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 responses, got %d: %q", len(lines), out.String())
+	}
+	ids := map[string]bool{}
+	for _, line := range lines {
+		var resp Response
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		if resp.Error != nil {
+			t.Errorf("unexpected error: %+v", resp.Error)
+		}
+		ids[string(resp.ID)] = true
+	}
+	if !ids["1"] || !ids["2"] {
+		t.Errorf("missing responses, got ids %v", ids)
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	s := NewServer(Config{Registry: newTestRegistry(t), Logger: quietLogger()})
+	ts := httptest.NewServer(s.HTTPHandler())
+	defer ts.Close()
+
+	body := `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"test_echo","arguments":{"session":"web"}}}`
+	resp, err := http.Post(ts.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var rpc Response
+	if err := json.NewDecoder(resp.Body).Decode(&rpc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	res := decodeResult[CallToolResult](t, &rpc)
+	if res.IsError {
+		t.Fatalf("tool error: %+v", res)
+	}
+
+	notif, err := http.Post(ts.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	if err != nil {
+		t.Fatalf("POST notification: %v", err)
+	}
+	notif.Body.Close()
+	if notif.StatusCode != http.StatusAccepted {
+		t.Errorf("notification status = %d, want 202", notif.StatusCode)
+	}
+
+	get, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	get.Body.Close()
+	if get.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", get.StatusCode)
+	}
+}
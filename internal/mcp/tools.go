@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Dicklesworthstone/ntm/internal/kernel"
+)
+
+// Tool describes an MCP tool derived from a kernel command.
+type Tool struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description"`
+	InputSchema map[string]any   `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+	Meta        map[string]any   `json:"_meta,omitempty"`
+}
+
+// ToolAnnotations carries MCP behavioural hints for a tool.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    bool   `json:"readOnlyHint"`
+	DestructiveHint bool   `json:"destructiveHint"`
+	IdempotentHint  bool   `json:"idempotentHint"`
+	OpenWorldHint   bool   `json:"openWorldHint"`
+}
+
+// ListToolsResult is the result of a tools/list request.
+type ListToolsResult struct {
+	Tools []Tool `json:"tools"`
+}
+
+// ToolName converts a kernel command name into an MCP tool name.
+// MCP tool names are restricted to [A-Za-z0-9_-], so dots become underscores
+// (matching the OpenAPI operationId convention).
+func ToolName(commandName string) string {
+	return strings.ReplaceAll(commandName, ".", "_")
+}
+
+// Tools returns the tool list for every registered kernel command that has
+// a handler.
+func (s *Server) Tools() []Tool {
+	reg := s.registry()
+	commands := reg.List()
+	tools := make([]Tool, 0, len(commands))
+	for _, cmd := range commands {
+		if !reg.HasHandler(cmd.Name) {
+			continue
+		}
+		tools = append(tools, BuildTool(cmd))
+	}
+	return tools
+}
+
+func (s *Server) lookupTool(name string) (kernel.Command, bool) {
+	reg := s.registry()
+	for _, cmd := range reg.List() {
+		if ToolName(cmd.Name) == name || cmd.Name == name {
+			if !reg.HasHandler(cmd.Name) {
+				return kernel.Command{}, false
+			}
+			return cmd, true
+		}
+	}
+	return kernel.Command{}, false
+}
+
+// BuildTool derives an MCP tool definition from kernel command metadata.
+func BuildTool(cmd kernel.Command) Tool {
+	tool := Tool{
+		Name:        ToolName(cmd.Name),
+		Title:       cmd.Description,
+		Description: toolDescription(cmd),
+		InputSchema: inputSchema(cmd),
+		Annotations: &ToolAnnotations{
+			Title:           cmd.Description,
+			ReadOnlyHint:    isReadOnly(cmd),
+			DestructiveHint: cmd.SafetyLevel == kernel.SafetyDanger,
+			IdempotentHint:  cmd.Idempotent,
+			OpenWorldHint:   false,
+		},
+		Meta: map[string]any{
+			"ntm/command":  cmd.Name,
+			"ntm/category": cmd.Category,
+			"ntm/safety":   string(safetyLevel(cmd)),
+		},
+	}
+
+	// Output schemas are advertised by reference only: MCP clients validate
+	// structuredContent strictly against outputSchema, and kernel outputs are
+	// not guaranteed to be closed objects.
+	if cmd.Output != nil && cmd.Output.Ref != "" {
+		tool.Meta["ntm/output"] = cmd.Output.Ref
+	}
+	return tool
+}
+
+func safetyLevel(cmd kernel.Command) kernel.SafetyLevel {
+	if cmd.SafetyLevel == "" {
+		return kernel.SafetySafe
+	}
+	return cmd.SafetyLevel
+}
+
+// isReadOnly treats safe commands without a mutating REST verb as read-only.
+func isReadOnly(cmd kernel.Command) bool {
+	if safetyLevel(cmd) != kernel.SafetySafe {
+		return false
+	}
+	if cmd.REST == nil {
+		return false
+	}
+	return strings.EqualFold(cmd.REST.Method, "GET")
+}
+
+func inputSchema(cmd kernel.Command) map[string]any {
+	if cmd.Input != nil {
+		if schema, ok := kernel.SchemaOf(cmd.Input); ok {
+			if cmd.Input.Description != "" {
+				schema["description"] = cmd.Input.Description
+			}
+			return schema
+		}
+	}
+	// MCP requires an object schema even for argument-less tools.
+	return map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{},
+		"additionalProperties": cmd.Input != nil,
+	}
+}
+
+func toolDescription(cmd kernel.Command) string {
+	var b strings.Builder
+	b.WriteString(cmd.Description)
+
+	level := safetyLevel(cmd)
+	fmt.Fprintf(&b, "\n\nSafety level: %s.", level)
+	if level == kernel.SafetyDanger {
+		b.WriteString(" Requires human approval (ntm approve) before execution.")
+	}
+	if cmd.Idempotent {
+		b.WriteString(" Idempotent: safe to retry.")
+	}
+	if len(cmd.EmitsEvents) > 0 {
+		fmt.Fprintf(&b, "\nEmits events: %s.", strings.Join(cmd.EmitsEvents, ", "))
+	}
+
+	if len(cmd.Examples) > 0 {
+		b.WriteString("\n\nExamples:")
+		for _, ex := range cmd.Examples {
+			line := ex.Command
+			if ex.Input != "" {
+				line = ex.Input
+			}
+			if line == "" {
+				continue
+			}
+			if ex.Description != "" {
+				fmt.Fprintf(&b, "\n- %s: %s", ex.Description, line)
+			} else {
+				fmt.Fprintf(&b, "\n- %s", line)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,219 @@
+// Package mergequeue lands agent worktree branches one at a time: each queued
+// branch is rebased onto the current target in a scratch worktree, tested,
+// and fast-forwarded only when the tests pass. Conflicts and test failures
+// are reported back to the agent that owns the branch.
+package mergequeue
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/worktrees"
+)
+
+const (
+	// LeaseMargin is added to the test timeout to get how long an entry may
+	// stay running before its runner is presumed dead and it is requeued.
+	LeaseMargin = 10 * time.Minute
+	// DefaultLease is the lease of running entries when the tests have no
+	// timeout.
+	DefaultLease = 2 * time.Hour
+)
+
+// LandFunc lands one queued branch.
+type LandFunc func(req state.MergeRequest) (*worktrees.LandResult, error)
+
+// NotifyFunc tells the owning agent that its branch did not land.
+type NotifyFunc func(req state.MergeRequest, message string) error
+
+// Runner drains a project's merge queue from the state store.
+type Runner struct {
+	store  *state.Store
+	land   LandFunc
+	notify NotifyFunc
+	now    func() time.Time
+	lease  time.Duration
+}
+
+// NewRunner creates a runner that lands branches with opts (the target of
+// each entry overrides opts.Target) and reports failures through notify. A
+// nil notify disables notifications.
+func NewRunner(store *state.Store, opts worktrees.LandOptions, notify NotifyFunc) *Runner {
+	return &Runner{
+		store: store,
+		land: func(req state.MergeRequest) (*worktrees.LandResult, error) {
+			o := opts
+			if req.Target != "" {
+				o.Target = req.Target
+			}
+			return worktrees.NewManager(req.ProjectPath, req.SessionID).Land(req.Agent, o)
+		},
+		notify: notify,
+		now:    time.Now,
+		lease:  Lease(opts.TestTimeout),
+	}
+}
+
+// Lease returns how long an entry landed with the given test timeout may
+// stay running before another runner requeues it.
+func Lease(testTimeout time.Duration) time.Duration {
+	if testTimeout <= 0 {
+		return DefaultLease
+	}
+	return testTimeout + LeaseMargin
+}
+
+// Run processes queued entries for a project in order until the queue is
+// empty and returns them with their outcomes.
+func (r *Runner) Run(projectPath string) ([]state.MergeRequest, error) {
+	var done []state.MergeRequest
+	for {
+		req, err := r.Step(projectPath)
+		if err != nil || req == nil {
+			return done, err
+		}
+		done = append(done, *req)
+	}
+}
+
+// Step processes the oldest queued entry for a project. It returns nil when
+// the queue is empty. Entries left running past their lease by a runner that
+// died are requeued first.
+func (r *Runner) Step(projectPath string) (*state.MergeRequest, error) {
+	if _, err := r.store.RequeueStaleMergeRequests(projectPath, r.now().Add(-r.lease)); err != nil {
+		return nil, err
+	}
+	for {
+		req, err := r.store.NextMergeRequest(projectPath)
+		if err != nil || req == nil {
+			return nil, err
+		}
+		claimed, err := r.store.ClaimMergeRequest(req.ID, r.now())
+		if err != nil {
+			return nil, err
+		}
+		if !claimed {
+			continue // Another runner took it
+		}
+		started := r.now().UTC()
+		req.StartedAt = &started
+		req.Status = state.MergeStatusRunning
+
+		r.process(req)
+		if err := r.store.CompleteMergeRequest(req); err != nil {
+			return req, err
+		}
+		return req, nil
+	}
+}
+
+// process lands a claimed entry and fills in its outcome.
+func (r *Runner) process(req *state.MergeRequest) {
+	result, err := r.land(*req)
+	if result != nil {
+		req.Target = result.Target
+		req.BaseCommit = result.Base
+		req.Conflicts = result.Conflicts
+		req.Output = result.Output
+	}
+	switch {
+	case err != nil:
+		req.Status = state.MergeStatusFailed
+		req.Output = err.Error()
+	case result.Outcome == worktrees.LandMerged:
+		req.Status = state.MergeStatusMerged
+		req.MergedCommit = result.Commit
+	case result.Outcome == worktrees.LandConflict:
+		req.Status = state.MergeStatusConflict
+	default:
+		req.Status = state.MergeStatusTestsFailed
+	}
+	finished := r.now().UTC()
+	req.FinishedAt = &finished
+
+	if req.Status == state.MergeStatusMerged || r.notify == nil {
+		return
+	}
+	if err := r.notify(*req, FailureMessage(*req)); err == nil {
+		req.Notified = true
+	} else if req.Output == "" {
+		req.Output = "notify: " + err.Error()
+	}
+}
+
+// FailureMessage is the prompt sent to an agent whose branch did not land.
+func FailureMessage(req state.MergeRequest) string {
+	target := req.Target
+	if target == "" {
+		target = "the target branch"
+	}
+	var sb strings.Builder
+	switch req.Status {
+	case state.MergeStatusConflict:
+		fmt.Fprintf(&sb, "Merge queue: your branch %s conflicts with %s and was not merged.", req.Branch, target)
+		if len(req.Conflicts) > 0 {
+			fmt.Fprintf(&sb, " Conflicting files: %s.", strings.Join(req.Conflicts, ", "))
+		}
+		fmt.Fprintf(&sb, " Rebase onto %s, resolve the conflicts, commit, and ask to be re-queued.", target)
+	case state.MergeStatusTestsFailed:
+		fmt.Fprintf(&sb, "Merge queue: tests failed after rebasing your branch %s onto %s, so it was not merged.", req.Branch, target)
+		fmt.Fprintf(&sb, " Fix the failures on your branch and ask to be re-queued.")
+		if out := lastLines(req.Output, 20); out != "" {
+			fmt.Fprintf(&sb, "\n\nTest output (last lines):\n%s", out)
+		}
+	default:
+		fmt.Fprintf(&sb, "Merge queue: your branch %s could not be merged into %s: %s", req.Branch, target, req.Output)
+	}
+	return sb.String()
+}
+
+// FindAgentPane returns the pane of a worktree agent (e.g. "cc_1") in session.
+func FindAgentPane(session, agent string) (*tmux.Pane, error) {
+	panes, err := tmux.GetPanes(session)
+	if err != nil {
+		return nil, err
+	}
+	title := session + "__" + agent
+	for i := range panes {
+		if panes[i].Title == title || strings.HasPrefix(panes[i].Title, title+"_") {
+			return &panes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no pane for agent %s in session %s", agent, session)
+}
+
+// NotifyPane types the message into the owning agent's pane, like 'ntm send'.
+func NotifyPane(req state.MergeRequest, message string) error {
+	pane, err := FindAgentPane(req.SessionID, req.Agent)
+	if err != nil {
+		return err
+	}
+	out, err := robot.GetSend(robot.SendOptions{
+		Session: req.SessionID,
+		Message: message,
+		Panes:   []string{pane.ID},
+	})
+	if err != nil {
+		return err
+	}
+	if !out.Success {
+		return errors.New(out.Error)
+	}
+	if len(out.Failed) > 0 {
+		return fmt.Errorf("pane %s: %s", out.Failed[0].Pane, out.Failed[0].Error)
+	}
+	return nil
+}
+
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,122 @@
+package mergequeue
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/worktrees"
+)
+
+func testMergeStore(t *testing.T) *state.Store {
+	t.Helper()
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return store
+}
+
+func TestRunProcessesQueueInOrderAndNotifiesFailures(t *testing.T) {
+	store := testMergeStore(t)
+	for _, agent := range []string{"cc_1", "cod_1", "gmi_1", "cc_2"} {
+		req := &state.MergeRequest{ProjectPath: "/repo", SessionID: "proj", Agent: agent, Branch: "ntm/proj/" + agent}
+		if err := store.EnqueueMerge(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var landed []string
+	outcomes := map[string]*worktrees.LandResult{
+		"cc_1":  {Outcome: worktrees.LandMerged, Target: "main", Base: "b1", Commit: "c1"},
+		"cod_1": {Outcome: worktrees.LandConflict, Target: "main", Base: "c1", Conflicts: []string{"a.go"}},
+		"gmi_1": {Outcome: worktrees.LandTestsFailed, Target: "main", Base: "c1", Output: "FAIL: TestX\n"},
+	}
+	notified := map[string]string{}
+
+	r := NewRunner(store, worktrees.LandOptions{}, func(req state.MergeRequest, message string) error {
+		if req.Agent == "cc_2" {
+			return errors.New("pane gone")
+		}
+		notified[req.Agent] = message
+		return nil
+	})
+	r.now = func() time.Time { return time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC) }
+	r.land = func(req state.MergeRequest) (*worktrees.LandResult, error) {
+		landed = append(landed, req.Agent)
+		if res, ok := outcomes[req.Agent]; ok {
+			return res, nil
+		}
+		return nil, errors.New("git exploded")
+	}
+
+	done, err := r.Run("/repo")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if strings.Join(landed, ",") != "cc_1,cod_1,gmi_1,cc_2" || len(done) != 4 {
+		t.Fatalf("landed = %v, done = %d", landed, len(done))
+	}
+
+	all, err := store.ListMergeRequests(state.MergeQuery{ProjectPath: "/repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []state.MergeStatus{state.MergeStatusMerged, state.MergeStatusConflict, state.MergeStatusTestsFailed, state.MergeStatusFailed}
+	for i, req := range all {
+		if req.Status != want[i] {
+			t.Errorf("%s status = %s, want %s", req.Agent, req.Status, want[i])
+		}
+	}
+	if all[0].MergedCommit != "c1" || all[0].Notified {
+		t.Errorf("merged entry = %+v", all[0])
+	}
+	if !all[1].Notified || !strings.Contains(notified["cod_1"], "a.go") {
+		t.Errorf("conflict notification = %q (%+v)", notified["cod_1"], all[1])
+	}
+	if !all[2].Notified || !strings.Contains(notified["gmi_1"], "FAIL: TestX") {
+		t.Errorf("test failure notification = %q", notified["gmi_1"])
+	}
+	if all[3].Notified || all[3].Output != "git exploded" {
+		t.Errorf("failed entry = %+v", all[3])
+	}
+
+	if req, err := r.Step("/repo"); req != nil || err != nil {
+		t.Errorf("Step on empty queue = %+v, %v", req, err)
+	}
+}
+
+func TestStepRequeuesEntriesPastTheirLease(t *testing.T) {
+	store := testMergeStore(t)
+	req := &state.MergeRequest{ProjectPath: "/repo", SessionID: "proj", Agent: "cc_1", Branch: "ntm/proj/cc_1"}
+	if err := store.EnqueueMerge(req); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	// A runner claimed it and crashed.
+	if ok, err := store.ClaimMergeRequest(req.ID, now.Add(-Lease(time.Minute)/2)); !ok || err != nil {
+		t.Fatalf("claim = %v, %v", ok, err)
+	}
+
+	r := NewRunner(store, worktrees.LandOptions{TestTimeout: time.Minute}, nil)
+	r.land = func(state.MergeRequest) (*worktrees.LandResult, error) {
+		return &worktrees.LandResult{Outcome: worktrees.LandMerged, Target: "main", Commit: "c1"}, nil
+	}
+	r.now = func() time.Time { return now }
+	if got, err := r.Step("/repo"); got != nil || err != nil {
+		t.Fatalf("Step within the lease = %+v, %v; want the entry left running", got, err)
+	}
+
+	r.now = func() time.Time { return now.Add(Lease(time.Minute)) }
+	got, err := r.Step("/repo")
+	if err != nil || got == nil || got.ID != req.ID || got.Status != state.MergeStatusMerged {
+		t.Fatalf("Step after the lease = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxSeries caps the series a single metric family may expose.
+const DefaultMaxSeries = 500
+
+// maxLabelValueLen bounds label values so a pathological pane title or
+// workflow name cannot bloat the scrape.
+const maxLabelValueLen = 128
+
+// Exposition builds a scrape body in the Prometheus text format. Families
+// render in registration order with their series sorted, so output is
+// deterministic. Each family holds at most maxSeries series; further series
+// are dropped and reported through ntm_metrics_series_dropped.
+type Exposition struct {
+	maxSeries int
+	families  []*Family
+}
+
+// Family is one metric name with a fixed label set.
+type Family struct {
+	name       string
+	help       string
+	typ        string
+	labelNames []string
+	maxSeries  int
+	series     map[string]float64
+	dropped    int
+}
+
+// NewExposition creates an empty exposition. maxSeries <= 0 uses
+// DefaultMaxSeries.
+func NewExposition(maxSeries int) *Exposition {
+	if maxSeries <= 0 {
+		maxSeries = DefaultMaxSeries
+	}
+	return &Exposition{maxSeries: maxSeries}
+}
+
+// Gauge registers a gauge family.
+func (e *Exposition) Gauge(name, help string, labelNames ...string) *Family {
+	return e.register(name, help, "gauge", labelNames)
+}
+
+// Counter registers a counter family. By convention name ends in _total.
+func (e *Exposition) Counter(name, help string, labelNames ...string) *Family {
+	return e.register(name, help, "counter", labelNames)
+}
+
+func (e *Exposition) register(name, help, typ string, labelNames []string) *Family {
+	f := &Family{
+		name:       name,
+		help:       help,
+		typ:        typ,
+		labelNames: labelNames,
+		maxSeries:  e.maxSeries,
+		series:     make(map[string]float64),
+	}
+	e.families = append(e.families, f)
+	return f
+}
+
+// Set sets the series identified by labelValues, given in the order of the
+// family's label names. Calls with the wrong number of values are ignored.
+func (f *Family) Set(value float64, labelValues ...string) {
+	f.update(labelValues, func(float64) float64 { return value })
+}
+
+// Add adds value to the series identified by labelValues.
+func (f *Family) Add(value float64, labelValues ...string) {
+	f.update(labelValues, func(old float64) float64 { return old + value })
+}
+
+func (f *Family) update(labelValues []string, fn func(float64) float64) {
+	if f == nil || len(labelValues) != len(f.labelNames) {
+		return
+	}
+	key := f.labelString(labelValues)
+	old, ok := f.series[key]
+	if !ok && len(f.series) >= f.maxSeries {
+		f.dropped++
+		return
+	}
+	f.series[key] = fn(old)
+}
+
+func (f *Family) labelString(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range f.labelNames {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// WriteTo writes the exposition in text format.
+func (e *Exposition) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	dropped := &Family{
+		name:       "ntm_metrics_series_dropped",
+		help:       "Series omitted from this scrape because a metric exceeded its cardinality limit.",
+		typ:        "gauge",
+		labelNames: []string{"metric"},
+		maxSeries:  len(e.families),
+		series:     make(map[string]float64),
+	}
+	for _, f := range e.families {
+		if f.dropped > 0 {
+			dropped.Set(float64(f.dropped), f.name)
+		}
+	}
+
+	for _, f := range append(e.families, dropped) {
+		bw.WriteString("# HELP " + f.name + " " + f.help + "\n")
+		bw.WriteString("# TYPE " + f.name + " " + f.typ + "\n")
+		keys := make([]string, 0, len(f.series))
+		for k := range f.series {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			bw.WriteString(f.name + k + " " + formatValue(f.series[k]) + "\n")
+		}
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// String returns the exposition in text format.
+func (e *Exposition) String() string {
+	var b strings.Builder
+	_, _ = e.WriteTo(&b)
+	return b.String()
+}
+
+func escapeLabelValue(v string) string {
+	if len(v) > maxLabelValueLen {
+		v = strings.ToValidUTF8(v[:maxLabelValueLen], "")
+	}
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
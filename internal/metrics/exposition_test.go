@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestExpositionFormat(t *testing.T) {
+	exp := NewExposition(0)
+	g := exp.Gauge("ntm_b", "B gauge.", "pane", "state")
+	c := exp.Counter("ntm_a_total", "A counter.")
+	g.Set(1, "cc_2", "working")
+	g.Set(0, "cc_1", "idle")
+	c.Add(2)
+	c.Add(3)
+
+	got := exp.String()
+	want := `# HELP ntm_b B gauge.
+# TYPE ntm_b gauge
+ntm_b{pane="cc_1",state="idle"} 0
+ntm_b{pane="cc_2",state="working"} 1
+# HELP ntm_a_total A counter.
+# TYPE ntm_a_total counter
+ntm_a_total 5
+# HELP ntm_metrics_series_dropped Series omitted from this scrape because a metric exceeded its cardinality limit.
+# TYPE ntm_metrics_series_dropped gauge
+`
+	if got != want {
+		t.Errorf("exposition mismatch\n got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExpositionEscapesLabelValues(t *testing.T) {
+	exp := NewExposition(0)
+	g := exp.Gauge("ntm_x", "X.", "name")
+	g.Set(1, "a\"b\\c\nd")
+	g.Set(2, strings.Repeat("é", 100))
+
+	out := exp.String()
+	if !strings.Contains(out, `ntm_x{name="a\"b\\c\nd"} 1`) {
+		t.Errorf("label not escaped:\n%s", out)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasSuffix(line, " 2") && len(line) > len(`ntm_x{name=""} 2`)+maxLabelValueLen {
+			t.Errorf("label value not truncated: %d bytes", len(line))
+		}
+	}
+}
+
+func TestExpositionCardinalityLimit(t *testing.T) {
+	exp := NewExposition(2)
+	g := exp.Gauge("ntm_y", "Y.", "pane")
+	g.Set(1, "p1")
+	g.Set(1, "p2")
+	g.Set(1, "p3")
+	g.Set(5, "p1") // existing series still update
+	g.Set(1, "p1", "extra")
+
+	out := exp.String()
+	if strings.Contains(out, `pane="p3"`) {
+		t.Errorf("series past the limit was exported:\n%s", out)
+	}
+	if !strings.Contains(out, `ntm_y{pane="p1"} 5`) {
+		t.Errorf("existing series not updated:\n%s", out)
+	}
+	if !strings.Contains(out, `ntm_metrics_series_dropped{metric="ntm_y"} 1`) {
+		t.Errorf("dropped series not reported:\n%s", out)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := map[float64]string{
+		0:           "0",
+		1.5:         "1.5",
+		math.NaN():  "NaN",
+		math.Inf(1): "+Inf",
+		1e21:        "1e+21",
+	}
+	for in, want := range tests {
+		if got := formatValue(in); got != want {
+			t.Errorf("formatValue(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
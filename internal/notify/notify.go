@@ -32,12 +32,15 @@ const (
 	EventAgentRestarted EventType = "agent.restarted"  // Agent was auto-restarted
 	EventAgentIdle      EventType = "agent.idle"       // Agent waiting for input
 	EventRateLimit      EventType = "agent.rate_limit" // Agent hit rate limit
+	EventAgentApproval  EventType = "agent.approval"   // Agent blocked on a confirmation prompt
 	EventBeadAssigned   EventType = "bead.assigned"    // Bead assigned to an agent
 	EventBeadCompleted  EventType = "bead.completed"   // Bead completed by an agent
 	EventRotationNeeded EventType = "rotation.needed"  // Account rotation recommended
 	EventSessionCreated EventType = "session.created"  // New session spawned
 	EventSessionKilled  EventType = "session.killed"   // Session terminated
 	EventHealthDegraded EventType = "health.degraded"  // Overall health dropped
+	EventBudgetWarning  EventType = "budget.warning"   // Spend crossed a budget warning threshold
+	EventBudgetExceeded EventType = "budget.exceeded"  // Spend reached a budget's hard cap
 )
 
 // Event represents a notification event
@@ -106,7 +109,7 @@ type FileBoxConfig struct {
 func DefaultConfig() Config {
 	return Config{
 		Enabled:  true,
-		Events:   []string{string(EventAgentError), string(EventAgentCrashed)},
+		Events:   []string{string(EventAgentError), string(EventAgentCrashed), string(EventAgentApproval), string(EventBudgetWarning), string(EventBudgetExceeded)},
 		Primary:  "desktop",
 		Fallback: "filebox",
 		Routing:  nil, // Use default (all enabled channels in parallel)
@@ -717,6 +720,26 @@ func NewRateLimitEvent(session, pane, agent string, waitSeconds int) Event {
 	}
 }
 
+// NewAgentApprovalEvent creates a notification event for an agent blocked on
+// a confirmation prompt
+func NewAgentApprovalEvent(session, pane, agent, kind, action string) Event {
+	message := fmt.Sprintf("Agent %s in pane %s is waiting for approval", agent, pane)
+	if strings.TrimSpace(action) != "" {
+		message = fmt.Sprintf("Agent %s in pane %s is waiting for approval: %s", agent, pane, strings.TrimSpace(action))
+	}
+	return Event{
+		Type:    EventAgentApproval,
+		Session: session,
+		Pane:    pane,
+		Agent:   agent,
+		Message: message,
+		Details: map[string]string{
+			"kind":   kind,
+			"action": action,
+		},
+	}
+}
+
 // NewBeadAssignedEvent creates a bead assigned notification event
 func NewBeadAssignedEvent(session, pane, agent, beadID, beadTitle string) Event {
 	message := fmt.Sprintf("Bead assigned: %s", beadID)
@@ -755,6 +778,27 @@ func NewBeadCompletedEvent(session, pane, agent, beadID, beadTitle string) Event
 	}
 }
 
+// NewBudgetEvent creates a budget warning or exceeded notification event.
+// Session is empty for budgets that span sessions.
+func NewBudgetEvent(session, budgetID string, spentUSD, limitUSD float64, exceeded bool) Event {
+	eventType := EventBudgetWarning
+	message := fmt.Sprintf("Budget %s at %.0f%%: $%.2f of $%.2f", budgetID, 100*spentUSD/limitUSD, spentUSD, limitUSD)
+	if exceeded {
+		eventType = EventBudgetExceeded
+		message = fmt.Sprintf("Budget %s exceeded: $%.2f of $%.2f", budgetID, spentUSD, limitUSD)
+	}
+	return Event{
+		Type:    eventType,
+		Session: session,
+		Message: message,
+		Details: map[string]string{
+			"budget":    budgetID,
+			"spent_usd": fmt.Sprintf("%.4f", spentUSD),
+			"limit_usd": fmt.Sprintf("%.4f", limitUSD),
+		},
+	}
+}
+
 // NewRotationNeededEvent creates a rotation needed notification event
 func NewRotationNeededEvent(session string, paneIndex int, agent, command string) Event {
 	return Event{
@@ -218,6 +218,14 @@ func TestHelperFunctions(t *testing.T) {
 		t.Errorf("NewRateLimitEvent details = %v", evt.Details)
 	}
 
+	evt = NewAgentApprovalEvent("sess", "p1", "cc", "command", "rm -rf build")
+	if evt.Type != EventAgentApproval {
+		t.Errorf("NewAgentApprovalEvent type = %v", evt.Type)
+	}
+	if evt.Details["action"] != "rm -rf build" || !strings.Contains(evt.Message, "rm -rf build") {
+		t.Errorf("NewAgentApprovalEvent = %+v", evt)
+	}
+
 	evt = NewAgentCrashedEvent("sess", "p1", "cc")
 	if evt.Type != EventAgentCrashed {
 		t.Errorf("NewAgentCrashedEvent type = %v", evt.Type)
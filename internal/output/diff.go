@@ -49,6 +49,49 @@ func ComputeDiff(pane1, content1, pane2, content2 string) *DiffResult {
 	}
 }
 
+// LineDelta is a line-level comparison of two outputs.
+type LineDelta struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// DiffLines compares two outputs line by line and returns the lines only in
+// content2 (Added) and only in content1 (Removed), each in order. For a
+// growing log such as pane scrollback, Added is the new output.
+func DiffLines(content1, content2 string) LineDelta {
+	dmp := diffmatchpatch.New()
+	// Terminate the last line so "a\nb" and "a\nb\nc" share line "b".
+	chars1, chars2, lines := dmp.DiffLinesToChars(terminateLine(content1), terminateLine(content2))
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(chars1, chars2, false), lines)
+
+	var delta LineDelta
+	for _, d := range diffs {
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			delta.Added = append(delta.Added, splitLines(d.Text)...)
+		case diffmatchpatch.DiffDelete:
+			delta.Removed = append(delta.Removed, splitLines(d.Text)...)
+		}
+	}
+	return delta
+}
+
+func terminateLine(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
+
+// splitLines splits s into lines without a trailing empty line.
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
 // countLines counts the number of lines in a string.
 // Empty strings return 0, trailing newlines don't count as extra lines.
 func countLines(s string) int {
@@ -107,6 +107,22 @@ func TestCountLines(t *testing.T) {
 	}
 }
 
+func TestDiffLines(t *testing.T) {
+	before := "$ make\nbuilding\nok"
+	after := "building\nok\n$ make test\nPASS\n"
+
+	delta := DiffLines(before, after)
+	if strings.Join(delta.Added, "|") != "$ make test|PASS" {
+		t.Errorf("Added = %q", delta.Added)
+	}
+	if strings.Join(delta.Removed, "|") != "$ make" {
+		t.Errorf("Removed = %q", delta.Removed)
+	}
+	if d := DiffLines("same\n", "same"); len(d.Added)+len(d.Removed) != 0 {
+		t.Errorf("identical content delta = %+v", d)
+	}
+}
+
 // ============ Error factory tests ============
 
 func TestNoSessionsError(t *testing.T) {
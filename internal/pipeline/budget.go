@@ -0,0 +1,31 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Dicklesworthstone/ntm/internal/budget"
+)
+
+// checkBudget refuses a prompt step, sequential or in a parallel or matrix
+// group, when a spend budget covering the session or the step's agent type
+// is exceeded without an approved override. Failures of the check itself are
+// reported as warnings and do not fail the step.
+func (e *Executor) checkBudget(ctx context.Context, step *Step, agentType string) error {
+	dir, _ := e.stateProjectDir()
+	requestedBy := "pipeline"
+	if e.state != nil {
+		requestedBy = "pipeline " + e.state.RunID
+	}
+
+	err := budget.GuardSend(ctx, budget.LoadConfig(), dir, e.config.Session, []string{agentType}, requestedBy)
+	var blocked *budget.BlockedError
+	if errors.As(err, &blocked) {
+		return err
+	}
+	if err != nil {
+		e.emitProgress("step_warning", step.ID, fmt.Sprintf("budget check failed: %v", err), e.calculateProgress())
+	}
+	return nil
+}
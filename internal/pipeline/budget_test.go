@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/budget"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func TestExecuteParallel_BudgetBlocksPromptSteps(t *testing.T) {
+	if !tmuxAvailable() {
+		t.Skip("tmux not available")
+	}
+	session := createTestSession(t)
+	paneID, err := tmux.SplitWindow(session, os.TempDir())
+	if err != nil {
+		t.Skipf("split window: %v", err)
+	}
+	if err := tmux.SetPaneTitle(paneID, session+"__cc_1"); err != nil {
+		t.Skipf("set pane title: %v", err)
+	}
+	panes, err := tmux.GetPanes(session)
+	if err != nil {
+		t.Skipf("get panes: %v", err)
+	}
+	paneIndex := -1
+	for _, p := range panes {
+		if p.ID == paneID {
+			paneIndex = p.Index
+		}
+	}
+	if paneIndex <= 0 {
+		t.Skipf("split pane has index %d", paneIndex)
+	}
+
+	var mu sync.Mutex
+	var checked []string
+	orig := budget.GuardSend
+	budget.GuardSend = func(_ context.Context, _ config.BudgetsConfig, _, _ string, agentTypes []string, requestedBy string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		checked = append(checked, agentTypes...)
+		return &budget.BlockedError{Status: budget.Status{
+			Limit:    budget.Limit{Scope: state.BudgetScopeDaily, USD: 1},
+			SpentUSD: 2,
+		}}
+	}
+	t.Cleanup(func() { budget.GuardSend = orig })
+
+	cfg := DefaultExecutorConfig(session)
+	cfg.ProjectDir = t.TempDir()
+	e := NewExecutor(cfg)
+	workflow := &Workflow{SchemaVersion: SchemaVersion, Name: "budget-workflow", Settings: DefaultWorkflowSettings()}
+	step := &Step{ID: "group", Parallel: []Step{
+		{ID: "review", Prompt: "budget-marker-prompt", Pane: paneIndex, Wait: WaitNone},
+	}}
+	workflow.Steps = []Step{*step}
+	e.graph = NewDependencyGraph(workflow)
+	e.state = &ExecutionState{
+		RunID:      "budget-run",
+		WorkflowID: workflow.Name,
+		Status:     StatusRunning,
+		StartedAt:  time.Now(),
+		Steps:      make(map[string]StepResult),
+		Variables:  make(map[string]interface{}),
+	}
+
+	e.executeParallel(context.Background(), step, workflow)
+
+	r := e.state.Steps["review"]
+	if r.Status != StatusFailed || r.Error == nil || r.Error.Type != "budget" {
+		t.Fatalf("review = %+v, want a budget failure", r)
+	}
+	if len(checked) != 1 || checked[0] != "cc" {
+		t.Errorf("budget checked for %v, want [cc]", checked)
+	}
+	if out, _ := tmux.CapturePaneOutput(paneID, 50); strings.Contains(out, "budget-marker-prompt") {
+		t.Errorf("prompt was sent despite the exceeded budget:\n%s", out)
+	}
+}
@@ -13,11 +13,19 @@ type DependencyGraph struct {
 	inDegree map[string]int      // step ID -> number of dependencies
 	executed map[string]bool     // step ID -> has been executed
 	failed   map[string]bool     // step ID -> has failed (for CONTINUE mode)
+	children map[string][]string // step ID -> parallel/loop sub-step IDs it runs itself
+	nested   map[string]bool     // step ID -> is a parallel/loop sub-step
+
+	// Sub-workflow resolution context; uses: references are only checked
+	// once SetWorkflowSource has been called.
+	source     string
+	projectDir string
+	checkUses  bool
 }
 
 // DependencyError represents an error in the dependency graph
 type DependencyError struct {
-	Type    string   `json:"type"`  // cycle, missing_dep, unreachable
+	Type    string   `json:"type"`  // cycle, missing_dep, unreachable, uses_cycle, missing_workflow
 	Steps   []string `json:"steps"` // affected step IDs
 	Message string   `json:"message"`
 }
@@ -43,14 +51,20 @@ func NewDependencyGraph(workflow *Workflow) *DependencyGraph {
 		inDegree: make(map[string]int),
 		executed: make(map[string]bool),
 		failed:   make(map[string]bool),
+		children: make(map[string][]string),
+		nested:   make(map[string]bool),
 	}
 
 	// Add all steps including parallel sub-steps
-	var addSteps func(steps []Step)
-	addSteps = func(steps []Step) {
+	var addSteps func(steps []Step, parent string)
+	addSteps = func(steps []Step, parent string) {
 		for i := range steps {
 			step := &steps[i]
 			g.steps[step.ID] = step
+			if parent != "" {
+				g.nested[step.ID] = true
+				g.children[parent] = append(g.children[parent], step.ID)
+			}
 			g.edges[step.ID] = step.DependsOn
 			g.inDegree[step.ID] = len(step.DependsOn)
 
@@ -61,20 +75,28 @@ func NewDependencyGraph(workflow *Workflow) *DependencyGraph {
 
 			// Handle parallel sub-steps
 			if len(step.Parallel) > 0 {
-				addSteps(step.Parallel)
+				addSteps(step.Parallel, step.ID)
 			}
 
 			// Handle loop sub-steps
 			if step.Loop != nil {
-				addSteps(step.Loop.Steps)
+				addSteps(step.Loop.Steps, step.ID)
 			}
 		}
 	}
 
-	addSteps(workflow.Steps)
+	addSteps(workflow.Steps, "")
 	return g
 }
 
+// SetWorkflowSource records the file the workflow was loaded from and the
+// project directory, so Validate can resolve and cycle-check uses: references.
+func (g *DependencyGraph) SetWorkflowSource(source, projectDir string) {
+	g.source = source
+	g.projectDir = projectDir
+	g.checkUses = true
+}
+
 // Validate checks the dependency graph for errors
 func (g *DependencyGraph) Validate() []DependencyError {
 	var errors []DependencyError
@@ -115,6 +137,11 @@ func (g *DependencyGraph) Validate() []DependencyError {
 		}
 	}
 
+	// Check sub-workflow references for missing files and cycles
+	if g.checkUses {
+		errors = append(errors, g.validateUses()...)
+	}
+
 	return errors
 }
 
@@ -268,7 +295,8 @@ func (g *DependencyGraph) Resolve() ExecutionPlan {
 func (g *DependencyGraph) GetReadySteps() []string {
 	var ready []string
 	for id := range g.steps {
-		if g.executed[id] {
+		// Sub-steps are run by their enclosing parallel or loop step
+		if g.executed[id] || g.nested[id] {
 			continue
 		}
 
@@ -294,6 +322,9 @@ func (g *DependencyGraph) MarkExecuted(id string) error {
 		return fmt.Errorf("step %q not found", id)
 	}
 	g.executed[id] = true
+	for _, child := range g.children[id] {
+		_ = g.MarkExecuted(child)
+	}
 	return nil
 }
 
@@ -286,6 +286,38 @@ func TestDependencyGraph_GetReadySteps(t *testing.T) {
 	}
 }
 
+func TestDependencyGraph_GetReadySteps_Nested(t *testing.T) {
+	t.Parallel()
+
+	w := &Workflow{
+		Steps: []Step{
+			{ID: "fan", Parallel: []Step{{ID: "p1", Prompt: "x"}, {ID: "p2", Prompt: "y"}}},
+			{ID: "retry", Loop: &LoopConfig{Times: 2, Steps: []Step{{ID: "l1", Prompt: "z"}}}},
+			{ID: "after", Prompt: "done", DependsOn: []string{"p1"}},
+		},
+	}
+
+	g := NewDependencyGraph(w)
+
+	// Sub-steps are run by their parent, never scheduled on their own
+	ready := g.GetReadySteps()
+	if len(ready) != 2 || ready[0] != "fan" || ready[1] != "retry" {
+		t.Errorf("expected [fan retry] ready, got %v", ready)
+	}
+
+	if err := g.MarkExecuted("fan"); err != nil {
+		t.Fatal(err)
+	}
+	if !g.IsExecuted("p1") || !g.IsExecuted("p2") {
+		t.Error("executing a parallel step should mark its sub-steps executed")
+	}
+
+	ready = g.GetReadySteps()
+	if len(ready) != 2 || ready[0] != "after" || ready[1] != "retry" {
+		t.Errorf("expected [after retry] ready, got %v", ready)
+	}
+}
+
 func TestDependencyGraph_MarkExecuted_NotFound(t *testing.T) {
 	t.Parallel()
 
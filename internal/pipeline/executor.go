@@ -15,9 +15,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Dicklesworthstone/ntm/internal/approval"
 	"github.com/Dicklesworthstone/ntm/internal/robot"
 	"github.com/Dicklesworthstone/ntm/internal/status"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tracing"
 	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
@@ -52,12 +54,13 @@ func DefaultExecutorConfig(session string) ExecutorConfig {
 
 // Executor runs workflows with full orchestration support
 type Executor struct {
-	config   ExecutorConfig
-	detector status.Detector
-	router   *robot.Router
-	scorer   *robot.AgentScorer
-	notifier *Notifier
-	loopExec *LoopExecutor
+	config    ExecutorConfig
+	detector  status.Detector
+	router    *robot.Router
+	scorer    *robot.AgentScorer
+	notifier  *Notifier
+	loopExec  *LoopExecutor
+	approvals *approval.Engine
 
 	// Runtime state (reset per execution)
 	state    *ExecutionState
@@ -66,6 +69,13 @@ type Executor struct {
 	graph    *DependencyGraph
 	progress chan<- ProgressEvent
 	cancelFn context.CancelFunc
+
+	// Gates currently waiting for approval; the run stays paused until all
+	// of them are decided (protected by stateMu)
+	openGates int
+
+	// Gate approval requests carried over from a resumed run (step ID -> approval ID)
+	resumeApprovals map[string]string
 }
 
 // NewExecutor creates a new workflow executor
@@ -81,6 +91,8 @@ func NewExecutor(config ExecutorConfig) *Executor {
 		detector: status.NewDetector(),
 		router:   robot.NewRouter(),
 		scorer:   robot.NewAgentScorer(robot.DefaultRoutingConfig()),
+
+		resumeApprovals: make(map[string]string),
 	}
 	e.loopExec = NewLoopExecutor(e)
 	return e
@@ -113,6 +125,8 @@ func (e *Executor) Run(ctx context.Context, workflow *Workflow, vars map[string]
 	if runID == "" {
 		runID = generateRunID()
 	}
+	ctx, endSpan := e.startRunSpan(ctx, "pipeline.run", runID, workflow)
+	defer endSpan()
 	e.state = &ExecutionState{
 		RunID:        runID,
 		WorkflowID:   workflow.Name,
@@ -141,6 +155,7 @@ func (e *Executor) Run(ctx context.Context, workflow *Workflow, vars map[string]
 
 	// Build dependency graph
 	e.graph = NewDependencyGraph(workflow)
+	e.graph.SetWorkflowSource(e.config.WorkflowFile, e.config.ProjectDir)
 	if errors := e.graph.Validate(); len(errors) > 0 {
 		e.state.Status = StatusFailed
 		for _, err := range errors {
@@ -160,6 +175,9 @@ func (e *Executor) Run(ctx context.Context, workflow *Workflow, vars map[string]
 
 	// Execute steps in dependency order
 	err := e.executeWorkflow(ctx, workflow)
+	if err == nil {
+		err = e.resolveOutputs(workflow)
+	}
 
 	// Finalize state
 	e.state.FinishedAt = time.Now()
@@ -243,11 +261,14 @@ func (e *Executor) Resume(ctx context.Context, workflow *Workflow, prior *Execut
 	e.state.UpdatedAt = time.Now()
 	e.state.FinishedAt = time.Time{}
 	e.state.CurrentStep = ""
+	ctx, endSpan := e.startRunSpan(ctx, "pipeline.resume", e.state.RunID, workflow)
+	defer endSpan()
 
 	e.progress = progress
 
 	// Build dependency graph
 	e.graph = NewDependencyGraph(workflow)
+	e.graph.SetWorkflowSource(e.config.WorkflowFile, e.config.ProjectDir)
 	if errors := e.graph.Validate(); len(errors) > 0 {
 		e.state.Status = StatusFailed
 		for _, err := range errors {
@@ -270,6 +291,9 @@ func (e *Executor) Resume(ctx context.Context, workflow *Workflow, prior *Execut
 
 	// Execute steps in dependency order
 	err := e.executeWorkflow(ctx, workflow)
+	if err == nil {
+		err = e.resolveOutputs(workflow)
+	}
 
 	// Finalize state
 	e.state.FinishedAt = time.Now()
@@ -407,8 +431,18 @@ func (e *Executor) executeWorkflow(ctx context.Context, workflow *Workflow) erro
 	return nil
 }
 
-// executeStep runs a single step with retry logic
+// executeStep runs a single step with retry logic inside a pipeline.step span.
 func (e *Executor) executeStep(ctx context.Context, step *Step, workflow *Workflow) StepResult {
+	ctx, span := tracing.Start(ctx, "pipeline.step", tracing.WithAttributes(
+		tracing.String("ntm.pipeline.run_id", e.runID()),
+		tracing.String("ntm.pipeline.step", step.ID),
+	))
+	result := e.runStep(ctx, step, workflow)
+	endStepSpan(span, result)
+	return result
+}
+
+func (e *Executor) runStep(ctx context.Context, step *Step, workflow *Workflow) StepResult {
 	result := StepResult{
 		StepID:    step.ID,
 		Status:    StatusPending,
@@ -448,6 +482,11 @@ func (e *Executor) executeStep(ctx context.Context, step *Step, workflow *Workfl
 		}
 	}
 
+	// Handle matrix fan-out steps
+	if step.Matrix != nil {
+		return e.executeMatrix(ctx, step, workflow)
+	}
+
 	// Handle parallel steps
 	if len(step.Parallel) > 0 {
 		return e.executeParallel(ctx, step, workflow)
@@ -458,6 +497,19 @@ func (e *Executor) executeStep(ctx context.Context, step *Step, workflow *Workfl
 		return e.executeLoop(ctx, step, workflow)
 	}
 
+	return e.executeWithRetry(ctx, step, workflow)
+}
+
+// executeWithRetry runs a single (non-parallel, non-loop) step, retrying
+// according to its on_error settings.
+func (e *Executor) executeWithRetry(ctx context.Context, step *Step, workflow *Workflow) StepResult {
+	result := StepResult{
+		StepID:    step.ID,
+		Status:    StatusPending,
+		StartedAt: time.Now(),
+		Attempts:  0,
+	}
+
 	// Calculate retry parameters
 	maxAttempts := 1
 	if step.OnError == ErrorActionRetry {
@@ -493,8 +545,18 @@ func (e *Executor) executeStep(ctx context.Context, step *Step, workflow *Workfl
 			return result
 		}
 
-		// Step failed
+		if stepResult.Status == StatusCancelled {
+			result = stepResult
+			result.Attempts = attempt
+			result.FinishedAt = time.Now()
+			return result
+		}
+
+		// Step failed; keep the command result so conditions can inspect it
 		result.Error = stepResult.Error
+		result.Output = stepResult.Output
+		result.Stderr = stepResult.Stderr
+		result.ExitCode = stepResult.ExitCode
 
 		if attempt < maxAttempts {
 			// Wait before retry
@@ -525,6 +587,16 @@ func (e *Executor) executeStep(ctx context.Context, step *Step, workflow *Workfl
 
 // executeStepOnce executes a step once without retry logic
 func (e *Executor) executeStepOnce(ctx context.Context, step *Step, workflow *Workflow) StepResult {
+	if step.Run != nil {
+		return e.executeRunStep(ctx, step)
+	}
+	if step.Gate != nil {
+		return e.executeGateStep(ctx, step)
+	}
+	if step.Uses != "" {
+		return e.executeSubWorkflowStep(ctx, step)
+	}
+
 	result := StepResult{
 		StepID:    step.ID,
 		Status:    StatusRunning,
@@ -568,6 +640,10 @@ func (e *Executor) executeStepOnce(ctx context.Context, step *Step, workflow *Wo
 		return result
 	}
 
+	if err := e.checkBudget(ctx, step, agentType); err != nil {
+		return stepFailure(result, "budget", err.Error())
+	}
+
 	// Capture state before sending
 	beforeOutput, _ := tmux.CapturePaneOutput(paneID, 2000)
 
@@ -615,7 +691,16 @@ func (e *Executor) executeStepOnce(ctx context.Context, step *Step, workflow *Wo
 
 	case WaitCompletion, WaitIdle:
 		// Wait for agent to return to idle
-		if err := e.waitForIdle(ctx, paneID, timeout); err != nil {
+		turn := tracing.StartTurn(ctx, tracing.TurnInfo{
+			Session:   e.config.Session,
+			Pane:      paneID,
+			AgentType: agentType,
+			Prompt:    prompt,
+		})
+		err := e.waitForIdle(ctx, paneID, timeout)
+		turn.SetError(err)
+		turn.End()
+		if err != nil {
 			if ctx.Err() == context.Canceled {
 				result.Status = StatusCancelled
 			} else {
@@ -919,6 +1004,23 @@ func (e *Executor) executeParallelStep(ctx context.Context, step *Step, workflow
 		}
 	}
 
+	// Run, gate and sub-workflow steps execute locally and don't need an agent
+	if step.Run != nil || step.Gate != nil || step.Uses != "" {
+		result = e.executeWithRetry(ctx, step, workflow)
+		if result.Status == StatusCompleted {
+			e.varMu.Lock()
+			if step.OutputVar != "" {
+				e.state.Variables[step.OutputVar] = result.Output
+				if result.ParsedData != nil {
+					e.state.Variables[step.OutputVar+"_parsed"] = result.ParsedData
+				}
+			}
+			StoreStepOutput(e.state, step.ID, result.Output, result.ParsedData)
+			e.varMu.Unlock()
+		}
+		return result
+	}
+
 	// Select pane with coordination to avoid reusing agents
 	// We select once and reuse for retries to avoid "self-exclusion" issues
 	paneID, agentType, err := e.selectAndMarkPane(step, usedPanes, panesMu)
@@ -996,6 +1098,11 @@ func (e *Executor) executeParallelStep(ctx context.Context, step *Step, workflow
 			return result
 		}
 
+		if err := e.checkBudget(ctx, step, agentType); err != nil {
+			result = stepFailure(result, "budget", err.Error())
+			goto HANDLE_RESULT
+		}
+
 		// Capture state before sending
 		beforeOutput, _ = tmux.CapturePaneOutput(paneID, 2000)
 
@@ -1163,6 +1270,9 @@ func (e *Executor) selectAndMarkPane(step *Step, usedPanes map[string]bool, pane
 		}
 		agents = filtered
 	}
+	if agents, err = filterByModel(agents, step.Model); err != nil {
+		return "", "", err
+	}
 
 	// Begin atomic selection and marking
 	panesMu.Lock()
@@ -1257,6 +1367,9 @@ func (e *Executor) selectPane(step *Step) (paneID string, agentType string, err
 		}
 		agents = filtered
 	}
+	if agents, err = filterByModel(agents, step.Model); err != nil {
+		return "", "", err
+	}
 
 	// Filter out excluded agents
 	available := make([]robot.ScoredAgent, 0, len(agents))
@@ -1295,6 +1408,24 @@ func (e *Executor) selectPane(step *Step) (paneID string, agentType string, err
 	return result.Selected.PaneID, result.Selected.AgentType, nil
 }
 
+// filterByModel keeps agents whose pane runs the given model variant (as
+// shown in the pane title). An empty model keeps all agents.
+func filterByModel(agents []robot.ScoredAgent, model string) ([]robot.ScoredAgent, error) {
+	if model == "" {
+		return agents, nil
+	}
+	filtered := make([]robot.ScoredAgent, 0, len(agents))
+	for _, a := range agents {
+		if strings.EqualFold(a.Variant, model) {
+			filtered = append(filtered, a)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("no agents running model %q", model)
+	}
+	return filtered, nil
+}
+
 // resolvePrompt gets the prompt content from prompt or prompt_file
 func (e *Executor) resolvePrompt(step *Step) (string, error) {
 	if step.Prompt != "" {
@@ -1418,6 +1549,10 @@ func (e *Executor) calculateProgress() float64 {
 		}
 	}
 	e.stateMu.RUnlock()
+	// Matrix cells are recorded in state but are not graph steps
+	if completed > total {
+		completed = total
+	}
 	return float64(completed) / float64(total)
 }
 
@@ -1510,7 +1645,10 @@ func (e *Executor) applyResumeState() {
 		return
 	}
 
-	for stepID := range rerun {
+	for stepID, result := range rerun {
+		if result.ApprovalID != "" {
+			e.resumeApprovals[stepID] = result.ApprovalID
+		}
 		delete(e.state.Steps, stepID)
 		e.clearStepVariables(stepID)
 	}
@@ -1518,7 +1656,7 @@ func (e *Executor) applyResumeState() {
 
 func shouldRerunStep(result StepResult) bool {
 	switch result.Status {
-	case StatusFailed, StatusCancelled, StatusRunning, StatusPending:
+	case StatusFailed, StatusCancelled, StatusRunning, StatusPending, StatusPaused:
 		return true
 	case StatusSkipped:
 		if strings.HasPrefix(result.SkipReason, "dependency failed") {
@@ -1536,8 +1674,9 @@ func (e *Executor) clearStepVariables(stepID string) {
 		return
 	}
 
-	delete(e.state.Variables, "steps."+stepID+".output")
-	delete(e.state.Variables, "steps."+stepID+".data")
+	for _, field := range stepResultFields {
+		delete(e.state.Variables, "steps."+stepID+"."+field)
+	}
 
 	if step, ok := e.graph.GetStep(stepID); ok && step.OutputVar != "" {
 		delete(e.state.Variables, step.OutputVar)
@@ -1578,16 +1717,12 @@ func (e *Executor) persistState() {
 		return
 	}
 
-	projectDir := e.config.ProjectDir
-	if projectDir == "" {
-		cwd, err := os.Getwd()
-		if err != nil {
-			if e.config.Verbose {
-				log.Printf("pipeline: unable to resolve project dir for state persistence: %v", err)
-			}
-			return
+	projectDir, err := e.stateProjectDir()
+	if err != nil {
+		if e.config.Verbose {
+			log.Printf("pipeline: unable to resolve project dir for state persistence: %v", err)
 		}
-		projectDir = cwd
+		return
 	}
 
 	snapshot := e.snapshotState()
@@ -1600,6 +1735,14 @@ func (e *Executor) persistState() {
 	}
 }
 
+// stateProjectDir returns the project directory run state is persisted under.
+func (e *Executor) stateProjectDir() (string, error) {
+	if e.config.ProjectDir != "" {
+		return e.config.ProjectDir, nil
+	}
+	return os.Getwd()
+}
+
 // GetState returns the current execution state (for monitoring)
 func (e *Executor) GetState() *ExecutionState {
 	return e.state
@@ -1670,6 +1813,19 @@ func (vc *VariableContext) GetVariable(ref string) (interface{}, bool) {
 					return string(result.Status), true
 				case "pane":
 					return result.PaneUsed, true
+				case "exit_code":
+					if result.ExitCode != nil {
+						return *result.ExitCode, true
+					}
+				case "data", "outputs":
+					if result.ParsedData == nil {
+						return nil, false
+					}
+					if len(parts) == 3 {
+						return result.ParsedData, true
+					}
+					val, err := navigateNested(result.ParsedData, parts[3:])
+					return val, err == nil
 				}
 			}
 		}
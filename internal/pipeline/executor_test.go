@@ -2414,21 +2414,24 @@ func TestClearStepVariables(t *testing.T) {
 
 	e.state = &ExecutionState{
 		Variables: map[string]interface{}{
-			"steps.step1.output": "output data",
-			"steps.step1.data":   "parsed data",
-			"myresult":           "result",
-			"myresult_parsed":    "parsed result",
-			"unrelated":          "keep this",
+			"steps.step1.output":    "output data",
+			"steps.step1.data":      "parsed data",
+			"steps.step1.stdout":    "output data",
+			"steps.step1.stderr":    "warning",
+			"steps.step1.status":    "completed",
+			"steps.step1.exit_code": 1,
+			"myresult":              "result",
+			"myresult_parsed":       "parsed result",
+			"unrelated":             "keep this",
 		},
 	}
 
 	e.clearStepVariables("step1")
 
-	if _, ok := e.state.Variables["steps.step1.output"]; ok {
-		t.Error("steps.step1.output should be cleared")
-	}
-	if _, ok := e.state.Variables["steps.step1.data"]; ok {
-		t.Error("steps.step1.data should be cleared")
+	for _, field := range []string{"output", "data", "stdout", "stderr", "status", "exit_code"} {
+		if _, ok := e.state.Variables["steps.step1."+field]; ok {
+			t.Errorf("steps.step1.%s should be cleared", field)
+		}
 	}
 	if _, ok := e.state.Variables["myresult"]; ok {
 		t.Error("myresult should be cleared")
@@ -0,0 +1,257 @@
+// Package pipeline provides workflow execution for AI agent orchestration.
+// gate.go implements human approval checkpoints (gate:) backed by the approval engine.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Dicklesworthstone/ntm/internal/approval"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+// GateApprovalAction is the approval action used for pipeline gate requests.
+const GateApprovalAction = "pipeline_gate"
+
+// gateSummaryOutputChars caps how much of each step's output goes into the
+// approval request summary.
+const gateSummaryOutputChars = 600
+
+// SetApprovalEngine sets the approval engine used by gate steps. When unset,
+// gates open the default state store so 'ntm approve' can decide them.
+func (e *Executor) SetApprovalEngine(engine *approval.Engine) {
+	e.approvals = engine
+}
+
+// gateEngine returns the configured approval engine or opens the default one.
+func (e *Executor) gateEngine() (*approval.Engine, func(), error) {
+	if e.approvals != nil {
+		return e.approvals, func() {}, nil
+	}
+	store, err := state.Open("")
+	if err != nil {
+		return nil, nil, fmt.Errorf("open state store: %w", err)
+	}
+	if err := store.Migrate(); err != nil {
+		store.Close()
+		return nil, nil, fmt.Errorf("apply migrations: %w", err)
+	}
+	return approval.New(store, nil, nil, approval.DefaultConfig()), func() { store.Close() }, nil
+}
+
+// executeGateStep pauses the run until a human approves or denies the gate.
+func (e *Executor) executeGateStep(ctx context.Context, step *Step) StepResult {
+	result := StepResult{
+		StepID:    step.ID,
+		Status:    StatusRunning,
+		StartedAt: time.Now(),
+	}
+
+	gate := step.Gate
+	name := gate.Name
+	if name == "" {
+		name = step.ID
+	}
+
+	if e.config.DryRun {
+		result.Status = StatusCompleted
+		result.Output = GateApproved
+		result.FinishedAt = time.Now()
+		e.emitProgress("gate_waiting", step.ID,
+			fmt.Sprintf("[DRY RUN] Would wait for approval at gate %s", name), e.calculateProgress())
+		return result
+	}
+
+	engine, closeEngine, err := e.gateEngine()
+	if err != nil {
+		return stepFailure(result, "gate", fmt.Sprintf("approval engine unavailable: %v", err))
+	}
+	defer closeEngine()
+
+	timeout := step.Timeout.Duration
+
+	// A resumed run keeps waiting on the request it created before.
+	var appr *state.Approval
+	if id := e.resumeApprovals[step.ID]; id != "" {
+		if prior, err := engine.Check(ctx, id); err == nil {
+			appr = prior
+		}
+	}
+	if appr == nil {
+		appr, err = engine.Request(ctx, approval.RequestParams{
+			Action:        GateApprovalAction,
+			Resource:      fmt.Sprintf("%s/%s", e.state.WorkflowID, name),
+			Reason:        e.renderGateSummary(step, name),
+			RequestedBy:   "pipeline:" + e.state.RunID,
+			CorrelationID: fmt.Sprintf("pipeline:%s:%s", e.state.RunID, step.ID),
+			RequiresSLB:   gate.RequireSLB,
+			ExpiresIn:     timeout,
+		})
+		if err != nil {
+			return stepFailure(result, "gate", fmt.Sprintf("failed to request approval: %v", err))
+		}
+	}
+	result.ApprovalID = appr.ID
+
+	// Record the pause so a restarted run resumes waiting on the same request.
+	e.stateMu.Lock()
+	e.openGates++
+	e.state.Status = StatusPaused
+	e.state.Steps[step.ID] = StepResult{
+		StepID:     step.ID,
+		Status:     StatusPaused,
+		StartedAt:  result.StartedAt,
+		ApprovalID: appr.ID,
+	}
+	e.stateMu.Unlock()
+	e.persistState()
+	e.emitProgress("gate_waiting", step.ID,
+		fmt.Sprintf("Waiting for approval at gate %s: ntm approve %s (deny: ntm approve deny %s)", name, appr.ID, appr.ID),
+		e.calculateProgress())
+
+	wait := time.Until(appr.ExpiresAt)
+	if wait < 0 {
+		wait = 0
+	}
+	decided, err := engine.WaitForApproval(ctx, appr.ID, wait)
+
+	// Parallel gates share the run status; only the last one to be decided
+	// resumes it.
+	e.stateMu.Lock()
+	e.openGates--
+	if e.openGates == 0 {
+		e.state.Status = StatusRunning
+	}
+	e.stateMu.Unlock()
+
+	if ctx.Err() != nil {
+		result.Status = StatusCancelled
+		result.SkipReason = "cancelled while waiting for approval"
+		result.FinishedAt = time.Now()
+		return result
+	}
+	if err != nil {
+		return stepFailure(result, "gate", fmt.Sprintf("failed waiting for approval: %v", err))
+	}
+
+	decision := GateDenied
+	reason := decided.DeniedReason
+	switch decided.Status {
+	case state.ApprovalApproved:
+		decision = GateApproved
+	case state.ApprovalDenied:
+	default:
+		// Still pending or expired: the gate timed out
+		reason = "timed out waiting for approval"
+		if gate.OnTimeout == GateOnTimeoutApprove {
+			decision = GateApproved
+		}
+	}
+
+	result.Output = decision
+	result.ParsedData = map[string]interface{}{
+		"decision":    decision,
+		"approval_id": decided.ID,
+		"approved_by": decided.ApprovedBy,
+		"reason":      reason,
+	}
+	result.FinishedAt = time.Now()
+
+	if decision == GateDenied && gate.OnDeny != GateOnDenyContinue {
+		msg := fmt.Sprintf("gate %s denied", name)
+		if reason != "" {
+			msg += ": " + reason
+		}
+		result.Status = StatusFailed
+		result.Error = &StepError{
+			Type:      "gate_denied",
+			Message:   msg,
+			Timestamp: time.Now(),
+		}
+		return result
+	}
+
+	e.emitProgress("gate_decided", step.ID, fmt.Sprintf("Gate %s %s", name, decision), e.calculateProgress())
+	result.Status = StatusCompleted
+	return result
+}
+
+// renderGateSummary builds the approval request text: the gate's message
+// followed by the status and output tail of the steps that ran before it.
+func (e *Executor) renderGateSummary(step *Step, name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Workflow %s (run %s) is waiting at gate %q.\n", e.state.WorkflowID, e.state.RunID, name)
+	if msg := strings.TrimSpace(e.substituteVariables(step.Gate.Message)); msg != "" {
+		b.WriteString("\n")
+		b.WriteString(msg)
+		b.WriteString("\n")
+	}
+
+	e.stateMu.Lock()
+	var results []StepResult
+	if len(step.Gate.Summary) > 0 {
+		for _, id := range step.Gate.Summary {
+			if r, ok := e.state.Steps[id]; ok {
+				results = append(results, r)
+			}
+		}
+	} else {
+		for id, r := range e.state.Steps {
+			if id != step.ID && r.Status != StatusPaused && r.Status != StatusRunning && r.Status != StatusPending {
+				results = append(results, r)
+			}
+		}
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].FinishedAt.Before(results[j].FinishedAt)
+		})
+	}
+	e.stateMu.Unlock()
+
+	if len(results) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("\nPrior steps:\n")
+	for _, r := range results {
+		line := fmt.Sprintf("- %s: %s", r.StepID, r.Status)
+		if r.ExitCode != nil {
+			line += fmt.Sprintf(" (exit %d)", *r.ExitCode)
+		}
+		if !r.FinishedAt.IsZero() && !r.StartedAt.IsZero() {
+			line += fmt.Sprintf(" in %s", r.FinishedAt.Sub(r.StartedAt).Round(time.Second))
+		}
+		b.WriteString(line + "\n")
+		if out := strings.TrimSpace(r.Output); out != "" {
+			if len(out) > gateSummaryOutputChars {
+				cut := len(out) - gateSummaryOutputChars
+				for cut < len(out) && !utf8.RuneStart(out[cut]) {
+					cut++
+				}
+				out = "..." + out[cut:]
+			}
+			for _, l := range strings.Split(out, "\n") {
+				b.WriteString("    " + l + "\n")
+			}
+		}
+		if r.Error != nil {
+			fmt.Fprintf(&b, "    error: %s\n", r.Error.Message)
+		}
+	}
+	return b.String()
+}
+
+// stepFailure marks result as failed with the given error type and message.
+func stepFailure(result StepResult, errType, msg string) StepResult {
+	result.Status = StatusFailed
+	result.Error = &StepError{
+		Type:      errType,
+		Message:   msg,
+		Timestamp: time.Now(),
+	}
+	result.FinishedAt = time.Now()
+	return result
+}
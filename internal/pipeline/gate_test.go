@@ -0,0 +1,258 @@
+//go:build unix
+
+package pipeline
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/approval"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+func newGateTestExecutor(t *testing.T) (*Executor, *approval.Engine) {
+	t.Helper()
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("state.Open: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	engine := approval.New(store, nil, nil, approval.DefaultConfig())
+
+	e := newRunTestExecutor(t)
+	e.SetApprovalEngine(engine)
+	return e, engine
+}
+
+// decideWhenPending approves or denies the first pending gate request.
+func decideWhenPending(t *testing.T, engine *approval.Engine, approve bool, check func(state.Approval)) {
+	t.Helper()
+	go func() {
+		ctx := context.Background()
+		for i := 0; i < 200; i++ {
+			pending, _ := engine.ListPending(ctx)
+			if len(pending) > 0 {
+				if check != nil {
+					check(pending[0])
+				}
+				if approve {
+					_ = engine.Approve(ctx, pending[0].ID, "reviewer")
+				} else {
+					_ = engine.Deny(ctx, pending[0].ID, "reviewer", "not on a Friday")
+				}
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+}
+
+func TestGateStep_ApproveBranches(t *testing.T) {
+	e, engine := newGateTestExecutor(t)
+
+	summary := make(chan string, 1)
+	decideWhenPending(t, engine, true, func(a state.Approval) { summary <- a.Reason })
+
+	state, err := runWorkflow(t, e, `
+schema_version: "2.0"
+name: ship
+steps:
+  - id: tests
+    run: echo "ok  ./..."
+  - id: review
+    depends_on: [tests]
+    gate:
+      name: merge-to-main
+      message: Merge ${workflow} to main?
+  - id: merge
+    depends_on: [review]
+    when: ${steps.review.output} == "approved"
+    run: echo merging
+  - id: abandon
+    depends_on: [review]
+    when: ${steps.review.output} == "denied"
+    run: echo abandoning
+`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	gate := state.Steps["review"]
+	if gate.Status != StatusCompleted || gate.Output != GateApproved || gate.ApprovalID == "" {
+		t.Errorf("review = %+v, want approved", gate)
+	}
+	if data, _ := gate.ParsedData.(map[string]interface{}); data["approved_by"] != "reviewer" {
+		t.Errorf("ParsedData = %v", gate.ParsedData)
+	}
+	if state.Steps["merge"].Status != StatusCompleted || state.Steps["abandon"].Status != StatusSkipped {
+		t.Errorf("merge=%s abandon=%s", state.Steps["merge"].Status, state.Steps["abandon"].Status)
+	}
+
+	got := <-summary
+	for _, want := range []string{`gate "merge-to-main"`, "Merge ship to main?", "- tests: completed (exit 0)", "ok  ./..."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestGateStep_DenyFails(t *testing.T) {
+	e, engine := newGateTestExecutor(t)
+	decideWhenPending(t, engine, false, nil)
+
+	state, err := runWorkflow(t, e, `
+schema_version: "2.0"
+name: ship
+steps:
+  - id: review
+    gate: merge-to-main
+  - id: merge
+    depends_on: [review]
+    run: echo merging
+`)
+	if err == nil {
+		t.Fatal("expected denied gate to fail the workflow")
+	}
+	gate := state.Steps["review"]
+	if gate.Status != StatusFailed || gate.Error == nil || gate.Error.Type != "gate_denied" {
+		t.Fatalf("review = %+v, want gate_denied failure", gate)
+	}
+	if !strings.Contains(gate.Error.Message, "not on a Friday") {
+		t.Errorf("error message %q should include the deny reason", gate.Error.Message)
+	}
+	if _, ran := state.Steps["merge"]; ran {
+		t.Error("merge should not run after a denied gate")
+	}
+}
+
+func TestGateStep_TimeoutDefaultsToDeny(t *testing.T) {
+	e, _ := newGateTestExecutor(t)
+
+	state, err := runWorkflow(t, e, `
+schema_version: "2.0"
+name: ship
+steps:
+  - id: review
+    timeout: 100ms
+    gate:
+      on_deny: continue
+`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	gate := state.Steps["review"]
+	if gate.Status != StatusCompleted || gate.Output != GateDenied {
+		t.Fatalf("review = %+v, want completed with denied", gate)
+	}
+	if data, _ := gate.ParsedData.(map[string]interface{}); !strings.Contains(data["reason"].(string), "timed out") {
+		t.Errorf("reason = %v", data["reason"])
+	}
+}
+
+func TestGateStep_ResumeReusesRequest(t *testing.T) {
+	e, engine := newGateTestExecutor(t)
+	ctx := context.Background()
+
+	appr, err := engine.Request(ctx, approval.RequestParams{Action: GateApprovalAction, Resource: "ship/review"})
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if err := engine.Approve(ctx, appr.ID, "reviewer"); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+
+	w, err := ParseString(`
+schema_version: "2.0"
+name: ship
+steps:
+  - id: review
+    gate: {}
+`, "yaml")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	prior := &ExecutionState{
+		RunID:      "run-resume",
+		WorkflowID: "ship",
+		Status:     StatusPaused,
+		Steps: map[string]StepResult{
+			"review": {StepID: "review", Status: StatusPaused, ApprovalID: appr.ID},
+		},
+	}
+
+	state, err := e.Resume(ctx, w, prior, nil)
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if gate := state.Steps["review"]; gate.Output != GateApproved || gate.ApprovalID != appr.ID {
+		t.Errorf("review = %+v, want approved via %s", gate, appr.ID)
+	}
+	if pending, _ := engine.ListPending(ctx); len(pending) != 0 {
+		t.Errorf("resume created a new request: %+v", pending)
+	}
+}
+
+func TestGateStep_ParallelGatesKeepRunPausedUntilAllDecided(t *testing.T) {
+	e, engine := newGateTestExecutor(t)
+	e.state = &ExecutionState{
+		RunID:      "run-gates",
+		WorkflowID: "ship",
+		Status:     StatusRunning,
+		Steps:      make(map[string]StepResult),
+		Variables:  make(map[string]interface{}),
+	}
+
+	ctx := context.Background()
+	done := make(map[string]chan StepResult)
+	for _, id := range []string{"security", "product"} {
+		ch := make(chan StepResult, 1)
+		done[id] = ch
+		step := &Step{ID: id, Gate: &GateConfig{}}
+		go func() { ch <- e.executeGateStep(ctx, step) }()
+	}
+
+	var pending []state.Approval
+	for i := 0; i < 200 && len(pending) < 2; i++ {
+		pending, _ = engine.ListPending(ctx)
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("pending approvals = %d, want 2", len(pending))
+	}
+	byStep := make(map[string]string)
+	for _, a := range pending {
+		byStep[a.CorrelationID[strings.LastIndex(a.CorrelationID, ":")+1:]] = a.ID
+	}
+
+	runStatus := func() ExecutionStatus {
+		e.stateMu.RLock()
+		defer e.stateMu.RUnlock()
+		return e.state.Status
+	}
+
+	if err := engine.Approve(ctx, byStep["security"], "reviewer"); err != nil {
+		t.Fatal(err)
+	}
+	if r := <-done["security"]; r.Status != StatusCompleted {
+		t.Fatalf("security gate = %+v", r)
+	}
+	if got := runStatus(); got != StatusPaused {
+		t.Errorf("status with one gate still open = %s, want paused", got)
+	}
+
+	if err := engine.Approve(ctx, byStep["product"], "reviewer"); err != nil {
+		t.Fatal(err)
+	}
+	if r := <-done["product"]; r.Status != StatusCompleted {
+		t.Fatalf("product gate = %+v", r)
+	}
+	if got := runStatus(); got != StatusRunning {
+		t.Errorf("status after both gates = %s, want running", got)
+	}
+}
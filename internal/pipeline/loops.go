@@ -495,6 +495,18 @@ func (le *LoopExecutor) executeIteration(ctx context.Context, step *Step, loop *
 		le.executor.state.Steps[iteratedStep.ID] = result
 		le.executor.stateMu.Unlock()
 
+		// Expose the latest iteration's result under the step's declared ID
+		// so loop conditions can use ${steps.<id>.exit_code} and friends.
+		le.executor.varMu.Lock()
+		StoreStepResult(le.executor.state, nestedStep.ID, result)
+		if nestedStep.OutputVar != "" && result.Status == StatusCompleted {
+			le.executor.state.Variables[nestedStep.OutputVar] = result.Output
+			if result.ParsedData != nil {
+				le.executor.state.Variables[nestedStep.OutputVar+"_parsed"] = result.ParsedData
+			}
+		}
+		le.executor.varMu.Unlock()
+
 		// Handle step failure based on error action
 		if result.Status == StatusFailed {
 			onError := nestedStep.OnError
@@ -0,0 +1,279 @@
+// Package pipeline provides workflow execution for AI agent orchestration.
+// matrix.go implements matrix fan-out steps that compare agents on one task.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/tokens"
+)
+
+// MatrixResult is the structured comparison produced by a matrix step.
+type MatrixResult struct {
+	Dimensions []string     `json:"dimensions"`      // Matrix keys in cell order (agent, model, vars...)
+	Cells      []MatrixCell `json:"cells"`           // One entry per combination
+	Diffs      []MatrixDiff `json:"diffs,omitempty"` // Pairwise output diffs (matrix.diff)
+}
+
+// MatrixCell is the outcome of one matrix combination.
+type MatrixCell struct {
+	StepID       string                 `json:"step_id"`
+	Values       map[string]interface{} `json:"values"` // Dimension -> value for this cell
+	Status       ExecutionStatus        `json:"status"`
+	AgentType    string                 `json:"agent_type,omitempty"`
+	PaneUsed     string                 `json:"pane_used,omitempty"`
+	Output       string                 `json:"output,omitempty"`
+	ParsedData   interface{}            `json:"parsed_data,omitempty"`
+	DurationMs   int64                  `json:"duration_ms"`
+	PromptTokens int                    `json:"prompt_tokens"` // Estimated
+	OutputTokens int                    `json:"output_tokens"` // Estimated
+	Error        string                 `json:"error,omitempty"`
+}
+
+// MatrixDiff compares the outputs of two cells.
+type MatrixDiff struct {
+	A          string  `json:"a"`
+	B          string  `json:"b"`
+	Similarity float64 `json:"similarity"` // 0.0 - 1.0
+	Diff       string  `json:"diff,omitempty"`
+}
+
+// matrixDimensions returns the matrix keys in expansion order: agent, model,
+// then vars sorted by name.
+func matrixDimensions(m *MatrixConfig) []string {
+	var dims []string
+	if len(m.Agent) > 0 {
+		dims = append(dims, "agent")
+	}
+	if len(m.Model) > 0 {
+		dims = append(dims, "model")
+	}
+	names := make([]string, 0, len(m.Vars))
+	for name := range m.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append(dims, names...)
+}
+
+// matrixValues returns the values listed for a dimension.
+func matrixValues(m *MatrixConfig, dim string) []interface{} {
+	var values []interface{}
+	switch dim {
+	case "agent":
+		for _, v := range m.Agent {
+			values = append(values, v)
+		}
+	case "model":
+		for _, v := range m.Model {
+			values = append(values, v)
+		}
+	default:
+		values = m.Vars[dim]
+	}
+	return values
+}
+
+// matrixCellCount returns the number of combinations the matrix expands to.
+func matrixCellCount(m *MatrixConfig) int {
+	dims := matrixDimensions(m)
+	if len(dims) == 0 {
+		return 0
+	}
+	count := 1
+	for _, dim := range dims {
+		count *= len(matrixValues(m, dim))
+	}
+	return count
+}
+
+// expandMatrix builds one agent step per matrix combination. Cells are named
+// <step>_<n> and get ${matrix.*} references in the prompt substituted.
+func expandMatrix(step *Step, prompt string) ([]Step, []map[string]interface{}) {
+	m := step.Matrix
+	dims := matrixDimensions(m)
+
+	combos := []map[string]interface{}{{}}
+	for _, dim := range dims {
+		var next []map[string]interface{}
+		for _, combo := range combos {
+			for _, v := range matrixValues(m, dim) {
+				cell := make(map[string]interface{}, len(combo)+1)
+				for k, cv := range combo {
+					cell[k] = cv
+				}
+				cell[dim] = v
+				next = append(next, cell)
+			}
+		}
+		combos = next
+	}
+
+	cells := make([]Step, len(combos))
+	for i, values := range combos {
+		cell := *step
+		cell.ID = fmt.Sprintf("%s_%d", step.ID, i+1)
+		cell.Name = ""
+		cell.Matrix = nil
+		cell.DependsOn = nil
+		cell.When = ""
+		cell.OutputVar = ""
+		cell.PromptFile = ""
+		cell.Prompt = substituteMatrix(prompt, values)
+		if agent, ok := values["agent"]; ok {
+			cell.Agent = formatValue(agent)
+		}
+		if model, ok := values["model"]; ok {
+			cell.Model = formatValue(model)
+		}
+		cells[i] = cell
+	}
+	return cells, combos
+}
+
+// substituteMatrix replaces ${matrix.<key>} references, leaving all other
+// references for regular substitution when the cell runs.
+func substituteMatrix(s string, values map[string]interface{}) string {
+	return varPattern.ReplaceAllStringFunc(s, func(match string) string {
+		key, ok := strings.CutPrefix(strings.TrimSpace(match[2:len(match)-1]), "matrix.")
+		if !ok {
+			return match
+		}
+		if v, ok := values[key]; ok {
+			return formatValue(v)
+		}
+		return match
+	})
+}
+
+// executeMatrix fans a prompt out across the matrix cells, runs them as a
+// parallel group and compares their results.
+func (e *Executor) executeMatrix(ctx context.Context, step *Step, workflow *Workflow) StepResult {
+	prompt, err := e.resolvePrompt(step)
+	if err != nil {
+		return stepFailure(StepResult{StepID: step.ID, StartedAt: time.Now()}, "prompt",
+			fmt.Sprintf("failed to resolve prompt: %v", err))
+	}
+
+	cells, values := expandMatrix(step, prompt)
+	group := *step
+	group.Matrix = nil
+	group.Prompt = ""
+	group.PromptFile = ""
+	group.Parallel = cells
+
+	e.emitProgress("matrix_start", step.ID,
+		fmt.Sprintf("Comparing %d matrix cells (%s)", len(cells), strings.Join(matrixDimensions(step.Matrix), " × ")),
+		e.calculateProgress())
+
+	result := e.executeParallel(ctx, &group, workflow)
+
+	comparison := &MatrixResult{Dimensions: matrixDimensions(step.Matrix)}
+	e.stateMu.RLock()
+	for i, cell := range cells {
+		r := e.state.Steps[cell.ID]
+		mc := MatrixCell{
+			StepID:       cell.ID,
+			Values:       values[i],
+			Status:       r.Status,
+			AgentType:    r.AgentType,
+			PaneUsed:     r.PaneUsed,
+			Output:       r.Output,
+			ParsedData:   r.ParsedData,
+			PromptTokens: tokens.EstimateTokens(e.substituteVariables(cell.Prompt)),
+			OutputTokens: tokens.EstimateTokens(r.Output),
+		}
+		if !r.StartedAt.IsZero() && !r.FinishedAt.IsZero() {
+			mc.DurationMs = r.FinishedAt.Sub(r.StartedAt).Milliseconds()
+		}
+		if r.Error != nil {
+			mc.Error = r.Error.Message
+		}
+		comparison.Cells = append(comparison.Cells, mc)
+	}
+	e.stateMu.RUnlock()
+
+	if step.Matrix.Diff {
+		for i := 0; i < len(comparison.Cells); i++ {
+			for j := i + 1; j < len(comparison.Cells); j++ {
+				a, b := comparison.Cells[i], comparison.Cells[j]
+				d := output.ComputeDiff(a.StepID, a.Output, b.StepID, b.Output)
+				comparison.Diffs = append(comparison.Diffs, MatrixDiff{
+					A:          a.StepID,
+					B:          b.StepID,
+					Similarity: d.Similarity,
+					Diff:       d.UnifiedDiff,
+				})
+			}
+		}
+	}
+
+	result.Matrix = comparison
+	result.ParsedData = comparison.data()
+	if result.Status == StatusCompleted {
+		result.Output = comparison.Summary()
+	}
+	return result
+}
+
+// data returns the comparison as generic maps so cells are reachable from
+// variable references such as ${steps.compare.data.cells.0.output}.
+func (m *MatrixResult) data() map[string]interface{} {
+	cells := make([]interface{}, len(m.Cells))
+	for i, c := range m.Cells {
+		cells[i] = map[string]interface{}{
+			"step_id":       c.StepID,
+			"values":        c.Values,
+			"status":        string(c.Status),
+			"agent_type":    c.AgentType,
+			"output":        c.Output,
+			"parsed_data":   c.ParsedData,
+			"duration_ms":   c.DurationMs,
+			"prompt_tokens": c.PromptTokens,
+			"output_tokens": c.OutputTokens,
+			"error":         c.Error,
+		}
+	}
+	data := map[string]interface{}{"cells": cells}
+	if len(m.Diffs) > 0 {
+		diffs := make([]interface{}, len(m.Diffs))
+		for i, d := range m.Diffs {
+			diffs[i] = map[string]interface{}{
+				"a":          d.A,
+				"b":          d.B,
+				"similarity": d.Similarity,
+				"diff":       d.Diff,
+			}
+		}
+		data["diffs"] = diffs
+	}
+	return data
+}
+
+// Summary renders the comparison as a plain-text table.
+func (m *MatrixResult) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Matrix comparison (%d cells)\n", len(m.Cells))
+	for _, c := range m.Cells {
+		labels := make([]string, 0, len(m.Dimensions))
+		for _, dim := range m.Dimensions {
+			labels = append(labels, fmt.Sprintf("%s=%s", dim, formatValue(c.Values[dim])))
+		}
+		fmt.Fprintf(&b, "- %s [%s] %s in %s, ~%d→%d tokens",
+			c.StepID, strings.Join(labels, " "), c.Status,
+			(time.Duration(c.DurationMs) * time.Millisecond).Round(time.Second), c.PromptTokens, c.OutputTokens)
+		if c.Error != "" {
+			fmt.Fprintf(&b, ": %s", c.Error)
+		}
+		b.WriteString("\n")
+	}
+	for _, d := range m.Diffs {
+		fmt.Fprintf(&b, "- %s vs %s: %.0f%% similar\n", d.A, d.B, d.Similarity*100)
+	}
+	return b.String()
+}
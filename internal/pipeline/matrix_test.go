@@ -0,0 +1,158 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func TestExpandMatrix(t *testing.T) {
+	step := &Step{
+		ID:          "compare",
+		Prompt:      "${matrix.style}: fix ${vars.bug} (${matrix.agent})",
+		OutputVar:   "result",
+		OutputParse: OutputParse{Type: "first_line"},
+		DependsOn:   []string{"setup"},
+		Matrix: &MatrixConfig{
+			Agent: []string{"claude", "codex", "gemini"},
+			Vars:  map[string][]interface{}{"style": {"terse", "thorough"}},
+		},
+	}
+
+	cells, values := expandMatrix(step, step.Prompt)
+	if len(cells) != 6 || matrixCellCount(step.Matrix) != 6 {
+		t.Fatalf("got %d cells, want 6", len(cells))
+	}
+
+	first := cells[0]
+	if first.ID != "compare_1" || first.Agent != "claude" || first.Prompt != "terse: fix ${vars.bug} (claude)" {
+		t.Errorf("first cell = %+v", first)
+	}
+	if first.Matrix != nil || first.OutputVar != "" || len(first.DependsOn) != 0 {
+		t.Errorf("cell kept group-only fields: %+v", first)
+	}
+	if first.OutputParse.Type != "first_line" {
+		t.Errorf("cell lost output_parse")
+	}
+	if last := cells[5]; last.Agent != "gemini" || values[5]["style"] != "thorough" {
+		t.Errorf("last cell = %+v values %v", last, values[5])
+	}
+}
+
+func TestValidate_Matrix(t *testing.T) {
+	tests := []struct {
+		name    string
+		step    string
+		wantErr string
+	}{
+		{"valid", "prompt: hi\n    matrix:\n      agent: [claude, codex]\n      model: [opus, sonnet]", ""},
+		{"needs prompt", "run: echo hi\n    matrix:\n      agent: [claude]", "matrix requires a prompt"},
+		{"agent conflict", "prompt: hi\n    agent: claude\n    matrix:\n      agent: [codex]", "matrix.agent cannot be combined"},
+		{"empty", "prompt: hi\n    matrix: {diff: true}", "needs at least one"},
+		{"empty var", "prompt: hi\n    matrix:\n      vars:\n        style: []", "has no values"},
+		{"reserved var", "prompt: hi\n    matrix:\n      vars:\n        agent: [a]", "invalid matrix variable name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := ParseString("schema_version: \"2.0\"\nname: m\nsteps:\n  - id: compare\n    "+tt.step+"\n", "yaml")
+			if err != nil {
+				t.Fatalf("ParseString: %v", err)
+			}
+			res := Validate(w)
+			if tt.wantErr == "" {
+				if !res.Valid {
+					t.Errorf("unexpected errors: %+v", res.Errors)
+				}
+				return
+			}
+			found := false
+			for _, e := range res.Errors {
+				if strings.Contains(e.Message, tt.wantErr) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("errors %+v do not mention %q", res.Errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatrixStep_DryRunComparison(t *testing.T) {
+	cfg := DefaultExecutorConfig("matrix-test")
+	cfg.ProjectDir = t.TempDir()
+	cfg.DryRun = true
+	e := NewExecutor(cfg)
+
+	w, err := ParseString(`
+schema_version: "2.0"
+name: compare
+steps:
+  - id: compare
+    prompt: "Implement the parser, ${matrix.style}"
+    matrix:
+      agent: [claude, codex]
+      vars:
+        style: [briefly, with tests]
+      diff: true
+  - id: pick
+    depends_on: [compare]
+    prompt: "Pick the best of: ${steps.compare.data.cells.1.output}"
+`, "yaml")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	state, err := e.Run(context.Background(), w, nil, nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	res := state.Steps["compare"]
+	if res.Status != StatusCompleted || res.Matrix == nil {
+		t.Fatalf("compare = %+v", res)
+	}
+	m := res.Matrix
+	if len(m.Cells) != 4 || len(m.Diffs) != 6 {
+		t.Fatalf("cells=%d diffs=%d, want 4 and 6", len(m.Cells), len(m.Diffs))
+	}
+	if strings.Join(m.Dimensions, ",") != "agent,style" {
+		t.Errorf("dimensions = %v", m.Dimensions)
+	}
+	cell := m.Cells[1]
+	if cell.Values["agent"] != "claude" || cell.Values["style"] != "with tests" {
+		t.Errorf("cell values = %v", cell.Values)
+	}
+	if !strings.Contains(cell.Output, "Implement the parser, with tests") || cell.PromptTokens == 0 || cell.OutputTokens == 0 {
+		t.Errorf("cell = %+v", cell)
+	}
+	if m.Diffs[0].Similarity <= 0 || m.Diffs[0].Similarity >= 1 {
+		t.Errorf("similarity = %v", m.Diffs[0].Similarity)
+	}
+	if !strings.Contains(res.Output, "Matrix comparison (4 cells)") {
+		t.Errorf("summary = %q", res.Output)
+	}
+	if !strings.Contains(state.Steps["pick"].Output, "with tests") {
+		t.Errorf("pick output = %q", state.Steps["pick"].Output)
+	}
+}
+
+func TestFilterByModel(t *testing.T) {
+	agents := []robot.ScoredAgent{
+		{PaneID: "%1", AgentType: "claude", Variant: "opus"},
+		{PaneID: "%2", AgentType: "claude", Variant: "sonnet"},
+		{PaneID: "%3", AgentType: "codex"},
+	}
+
+	got, err := filterByModel(agents, "Opus")
+	if err != nil || len(got) != 1 || got[0].PaneID != "%1" {
+		t.Errorf("filterByModel(opus) = %+v, %v", got, err)
+	}
+	if got, err := filterByModel(agents, ""); err != nil || len(got) != 3 {
+		t.Errorf("filterByModel(\"\") = %+v, %v", got, err)
+	}
+	if _, err := filterByModel(agents, "haiku"); err == nil {
+		t.Error("expected an error when no pane runs the model")
+	}
+}
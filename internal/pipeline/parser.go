@@ -1,6 +1,7 @@
 package pipeline
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -151,6 +152,27 @@ func Validate(w *Workflow) ValidationResult {
 		validateStep(&step, fmt.Sprintf("steps[%d]", i), stepIDs, &result)
 	}
 
+	// Validate declared outputs
+	for name, out := range w.Outputs {
+		field := "outputs." + name
+		if strings.TrimSpace(out.Value) == "" {
+			result.addError(ParseError{
+				Field:   field + ".value",
+				Message: fmt.Sprintf("output %s has no value", name),
+				Hint:    "Set value to a reference such as ${steps.step_id.output}",
+			})
+		}
+		switch out.Type {
+		case "", VarTypeString, VarTypeNumber, VarTypeBoolean, VarTypeArray:
+		default:
+			result.addError(ParseError{
+				Field:   field + ".type",
+				Message: fmt.Sprintf("invalid output type: %s", out.Type),
+				Hint:    "Valid types: string, number, boolean, array",
+			})
+		}
+	}
+
 	// Check for dependency cycles
 	if cycles := detectCycles(w.Steps); len(cycles) > 0 {
 		for _, cycle := range cycles {
@@ -219,11 +241,76 @@ func validateStep(step *Step, stepField string, stepIDs map[string]bool, result
 		})
 	}
 
-	if !hasPrompt && !hasParallel && step.Loop == nil {
+	hasRun := step.Run != nil
+	if hasRun && (hasPrompt || hasParallel || step.Loop != nil) {
 		result.addError(ParseError{
 			Field:   stepField,
-			Message: "step must have prompt, prompt_file, parallel, or loop",
-			Hint:    "Add a prompt, parallel steps, or loop for this step",
+			Message: "step cannot combine run with prompt, parallel, or loop",
+			Hint:    "Use a separate step for the shell command",
+		})
+	}
+	if hasRun && strings.TrimSpace(step.Run.Command) == "" {
+		result.addError(ParseError{
+			Field:   stepField + ".run.command",
+			Message: "run command is required",
+			Hint:    "Specify the shell command to execute",
+		})
+	}
+
+	hasGate := step.Gate != nil
+	if hasGate {
+		if hasPrompt || hasParallel || hasRun || step.Loop != nil {
+			result.addError(ParseError{
+				Field:   stepField,
+				Message: "step cannot combine gate with prompt, run, parallel, or loop",
+				Hint:    "Put the approval gate in its own step",
+			})
+		}
+		switch step.Gate.OnDeny {
+		case "", GateOnDenyFail, GateOnDenyContinue:
+		default:
+			result.addError(ParseError{
+				Field:   stepField + ".gate.on_deny",
+				Message: fmt.Sprintf("invalid on_deny value: %s", step.Gate.OnDeny),
+				Hint:    "Valid values: fail, continue",
+			})
+		}
+		switch step.Gate.OnTimeout {
+		case "", GateOnTimeoutDeny, GateOnTimeoutApprove:
+		default:
+			result.addError(ParseError{
+				Field:   stepField + ".gate.on_timeout",
+				Message: fmt.Sprintf("invalid on_timeout value: %s", step.Gate.OnTimeout),
+				Hint:    "Valid values: deny, approve",
+			})
+		}
+	}
+
+	hasUses := strings.TrimSpace(step.Uses) != ""
+	if hasUses && (hasPrompt || hasParallel || hasRun || hasGate || step.Loop != nil) {
+		result.addError(ParseError{
+			Field:   stepField,
+			Message: "step cannot combine uses with prompt, run, gate, parallel, or loop",
+			Hint:    "Invoke the sub-workflow from its own step",
+		})
+	}
+	if len(step.With) > 0 && !hasUses {
+		result.addError(ParseError{
+			Field:   stepField + ".with",
+			Message: "with requires uses",
+			Hint:    "with passes input vars to the workflow named by uses",
+		})
+	}
+
+	if step.Matrix != nil {
+		validateMatrix(step, stepField, hasPrompt && !hasParallel && !hasRun && !hasGate && !hasUses && step.Loop == nil, result)
+	}
+
+	if !hasPrompt && !hasParallel && !hasRun && !hasGate && !hasUses && step.Loop == nil && step.LoopControl == LoopControlNone {
+		result.addError(ParseError{
+			Field:   stepField,
+			Message: "step must have prompt, prompt_file, run, gate, uses, parallel, or loop",
+			Hint:    "Add a prompt, shell command, approval gate, sub-workflow, parallel steps, or loop for this step",
 		})
 	}
 
@@ -305,11 +392,11 @@ func validateStep(step *Step, stepField string, stepIDs map[string]bool, result
 
 	// Validate loop configuration
 	if step.Loop != nil {
-		if step.Loop.Items == "" {
+		if step.Loop.Items == "" && step.Loop.While == "" && step.Loop.Times <= 0 {
 			result.addError(ParseError{
 				Field:   stepField + ".loop.items",
-				Message: "loop items is required",
-				Hint:    "Specify the variable to iterate over",
+				Message: "loop requires items, while, or times",
+				Hint:    "Specify the variable to iterate over, a while condition, or a repeat count",
 			})
 		}
 		if step.Loop.MaxIterations < 0 {
@@ -393,6 +480,75 @@ func detectCycles(steps []Step) [][]string {
 	return cycles
 }
 
+// validateMatrix checks a matrix step's dimensions and agent selection.
+func validateMatrix(step *Step, stepField string, promptOnly bool, result *ValidationResult) {
+	m := step.Matrix
+	field := stepField + ".matrix"
+
+	if !promptOnly {
+		result.addError(ParseError{
+			Field:   field,
+			Message: "matrix requires a prompt and cannot be combined with run, gate, uses, parallel, or loop",
+			Hint:    "Use matrix on a single prompt step",
+		})
+	}
+	if len(m.Agent) > 0 && (step.Agent != "" || step.Pane > 0) {
+		result.addError(ParseError{
+			Field:   field + ".agent",
+			Message: "matrix.agent cannot be combined with agent or pane",
+			Hint:    "List the agent types only in matrix.agent",
+		})
+	}
+	if len(m.Model) > 0 && step.Model != "" {
+		result.addError(ParseError{
+			Field:   field + ".model",
+			Message: "matrix.model cannot be combined with model",
+			Hint:    "List the models only in matrix.model",
+		})
+	}
+	for _, agent := range m.Agent {
+		if !IsValidAgentType(agent) {
+			result.addWarning(ParseError{
+				Field:   field + ".agent",
+				Message: fmt.Sprintf("unknown agent type: %s", agent),
+				Hint:    "Valid types: claude, codex, gemini (and aliases)",
+			})
+		}
+	}
+	for name, values := range m.Vars {
+		if name == "agent" || name == "model" || !isValidID(name) {
+			result.addError(ParseError{
+				Field:   field + ".vars." + name,
+				Message: fmt.Sprintf("invalid matrix variable name: %s", name),
+				Hint:    "Use alphanumeric names other than agent and model",
+			})
+		}
+		if len(values) == 0 {
+			result.addError(ParseError{
+				Field:   field + ".vars." + name,
+				Message: fmt.Sprintf("matrix variable %s has no values", name),
+				Hint:    "List at least one value",
+			})
+		}
+	}
+
+	cells := matrixCellCount(m)
+	switch {
+	case cells == 0 && len(m.Vars) == 0:
+		result.addError(ParseError{
+			Field:   field,
+			Message: "matrix needs at least one of agent, model, or vars",
+			Hint:    "For example: matrix: {agent: [claude, codex]}",
+		})
+	case cells > MaxMatrixCells:
+		result.addError(ParseError{
+			Field:   field,
+			Message: fmt.Sprintf("matrix expands to %d cells (max %d)", cells, MaxMatrixCells),
+			Hint:    "Compare fewer agents, models, or variants per step",
+		})
+	}
+}
+
 // validateVariableRefs checks that variable references are valid
 func validateVariableRefs(w *Workflow, result *ValidationResult) {
 	// Uses package-level varPattern from variables.go
@@ -423,7 +579,7 @@ func validateVariableRefs(w *Workflow, result *ValidationResult) {
 						Hint:    "Use ${steps.step_id.output}",
 					})
 				}
-			case "env", "session", "timestamp", "run_id", "workflow", "loop":
+			case "env", "session", "timestamp", "run_id", "workflow", "loop", "matrix":
 				// Valid built-in references
 			default:
 				result.addWarning(ParseError{
@@ -446,6 +602,17 @@ func validateVariableRefs(w *Workflow, result *ValidationResult) {
 			if step.When != "" {
 				checkString(step.When, stepField+".when")
 			}
+			if step.Run != nil {
+				checkString(step.Run.Command, stepField+".run.command")
+			}
+			if step.Gate != nil {
+				checkString(step.Gate.Message, stepField+".gate.message")
+			}
+			for name, value := range step.With {
+				if str, ok := value.(string); ok {
+					checkString(str, stepField+".with."+name)
+				}
+			}
 			// Check parallel sub-steps
 			if len(step.Parallel) > 0 {
 				checkSteps(step.Parallel, stepField+".parallel")
@@ -458,6 +625,10 @@ func validateVariableRefs(w *Workflow, result *ValidationResult) {
 	}
 
 	checkSteps(w.Steps, "steps")
+
+	for name, out := range w.Outputs {
+		checkString(out.Value, "outputs."+name+".value")
+	}
 }
 
 // Helper validation functions
@@ -522,3 +693,18 @@ func LoadAndValidate(path string) (*Workflow, ValidationResult, error) {
 	result := Validate(workflow)
 	return workflow, result, nil
 }
+
+// decodeTOMLTable decodes an already-parsed TOML table into v. It is used by
+// types whose UnmarshalText shorthand would otherwise shadow the table form.
+func decodeTOMLTable(data interface{}, v interface{}) error {
+	table, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected string or table, got %T", data)
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(table); err != nil {
+		return err
+	}
+	_, err := toml.Decode(buf.String(), v)
+	return err
+}
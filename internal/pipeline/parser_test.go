@@ -604,6 +604,20 @@ func TestValidate_LoopWithMissingItems(t *testing.T) {
 	}
 }
 
+func TestValidate_WhileAndTimesLoopsNeedNoItems(t *testing.T) {
+	t.Parallel()
+
+	for _, loop := range []*LoopConfig{
+		{While: "${vars.retry}", Steps: []Step{{ID: "inner", Prompt: "test"}, {ID: "stop", LoopControl: LoopControlBreak}}},
+		{Times: 3, Steps: []Step{{ID: "inner", Prompt: "test"}}},
+	} {
+		w := &Workflow{SchemaVersion: "2.0", Name: "test", Steps: []Step{{ID: "s1", Loop: loop}}}
+		if result := Validate(w); !result.Valid {
+			t.Errorf("loop %+v: expected valid, got %v", loop, result.Errors)
+		}
+	}
+}
+
 func TestValidate_LoopNegativeMaxIterations(t *testing.T) {
 	t.Parallel()
 
@@ -1437,3 +1451,84 @@ func TestValidate_StepWithInvalidPromptFile(t *testing.T) {
 		t.Errorf("expected warning about invalid prompt_file path, got %v", result.Warnings)
 	}
 }
+
+func TestParseString_RunStep(t *testing.T) {
+	t.Parallel()
+
+	content := `
+schema_version = "2.0"
+name = "run-test"
+
+[[steps]]
+id = "build"
+run = "go build ./..."
+
+[[steps]]
+id = "tests"
+depends_on = ["build"]
+
+[steps.run]
+command = "go test ./..."
+dir = "sub"
+allow_failure = true
+
+[steps.run.env]
+GOFLAGS = "-count=1"
+`
+	w, err := ParseString(content, "toml")
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	if w.Steps[0].Run == nil || w.Steps[0].Run.Command != "go build ./..." {
+		t.Errorf("string form: got %+v", w.Steps[0].Run)
+	}
+	run := w.Steps[1].Run
+	if run == nil || run.Command != "go test ./..." || run.Dir != "sub" || !run.AllowFailure || run.Env["GOFLAGS"] != "-count=1" {
+		t.Errorf("table form: got %+v", run)
+	}
+
+	if result := Validate(w); !result.Valid {
+		t.Errorf("expected valid workflow, got %v", result.Errors)
+	}
+}
+
+func TestValidate_RunAndGateSteps(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		step    Step
+		wantErr string
+	}{
+		{"empty command", Step{ID: "s1", Run: &RunConfig{}}, "run command is required"},
+		{"run with prompt", Step{ID: "s1", Prompt: "hi", Run: &RunConfig{Command: "true"}}, "cannot combine run"},
+		{"while loop", Step{ID: "s1", Loop: &LoopConfig{While: "true", Steps: []Step{{ID: "s2", Run: &RunConfig{Command: "true"}}}}}, ""},
+		{"gate", Step{ID: "s1", Gate: &GateConfig{Name: "ship", OnDeny: "continue"}}, ""},
+		{"gate with run", Step{ID: "s1", Gate: &GateConfig{}, Run: &RunConfig{Command: "true"}}, "cannot combine gate"},
+		{"gate bad on_timeout", Step{ID: "s1", Gate: &GateConfig{OnTimeout: "maybe"}}, "invalid on_timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := Validate(&Workflow{SchemaVersion: "2.0", Name: "test", Steps: []Step{tt.step}})
+			if tt.wantErr == "" {
+				if !result.Valid {
+					t.Errorf("expected valid, got %v", result.Errors)
+				}
+				return
+			}
+			found := false
+			for _, e := range result.Errors {
+				if strings.Contains(e.Message, tt.wantErr) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, result.Errors)
+			}
+		})
+	}
+}
@@ -2,11 +2,14 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/Dicklesworthstone/ntm/internal/budget"
 	"github.com/Dicklesworthstone/ntm/internal/status"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 	"github.com/Dicklesworthstone/ntm/internal/util"
@@ -61,7 +64,16 @@ func Execute(ctx context.Context, p Pipeline) error {
 			}
 		}
 
-		// 3. Send prompt
+		// 3. Send prompt, unless a spend budget refuses new work
+		wd, _ := os.Getwd()
+		err = budget.GuardSend(ctx, budget.LoadConfig(), wd, p.Session, []string{stage.AgentType}, "ntm pipeline")
+		var blocked *budget.BlockedError
+		if errors.As(err, &blocked) {
+			return fmt.Errorf("stage %d: %w", i+1, err)
+		}
+		if err != nil {
+			log.Printf("  Warning: budget check failed: %v", err)
+		}
 		if err := tmux.PasteKeys(paneID, prompt, true); err != nil {
 			return fmt.Errorf("stage %d sending prompt: %w", i+1, err)
 		}
@@ -639,6 +639,19 @@ func GetAllPipelines() []*PipelineExecution {
 	return result
 }
 
+// SnapshotPipelines returns copies of all tracked pipelines, safe to read
+// while the runs continue (exported for metrics)
+func SnapshotPipelines() []PipelineExecution {
+	pipelineMu.RLock()
+	defer pipelineMu.RUnlock()
+
+	result := make([]PipelineExecution, 0, len(pipelineRegistry))
+	for _, exec := range pipelineRegistry {
+		result = append(result, *exec)
+	}
+	return result
+}
+
 // CancelPipeline cancels a running pipeline by run ID (exported for REST API)
 func CancelPipeline(runID string) {
 	exec := getPipeline(runID)
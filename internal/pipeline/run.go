@@ -0,0 +1,281 @@
+// Package pipeline provides workflow execution for AI agent orchestration.
+// run.go implements shell command (run:) steps executed directly by the executor.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxRunOutputBytes caps how much of each run step stream is kept. When a
+// command writes more, the earliest output is dropped so the tail (where
+// test failures and build errors usually are) is preserved.
+const MaxRunOutputBytes = 1 << 20
+
+// runWaitDelay bounds how long a timed-out command may take to exit after
+// it has been signalled before its process group is killed.
+const runWaitDelay = 5 * time.Second
+
+// executeRunStep runs a step's shell command and captures its output and exit code.
+func (e *Executor) executeRunStep(ctx context.Context, step *Step) StepResult {
+	result := StepResult{
+		StepID:    step.ID,
+		Status:    StatusRunning,
+		StartedAt: time.Now(),
+	}
+
+	run := step.Run
+	display := e.substituteVariables(run.Command)
+	if strings.TrimSpace(display) == "" {
+		result.Status = StatusFailed
+		result.Error = &StepError{
+			Type:      "run",
+			Message:   "run step has no command",
+			Timestamp: time.Now(),
+		}
+		result.FinishedAt = time.Now()
+		return result
+	}
+
+	dir := e.resolveRunDir(e.substituteVariables(run.Dir))
+
+	if e.config.DryRun {
+		result.Status = StatusCompleted
+		result.Output = "[DRY RUN] Would run: " + truncatePrompt(display, 100)
+		result.FinishedAt = time.Now()
+		return result
+	}
+
+	timeout := e.config.DefaultTimeout
+	if step.Timeout.Duration > 0 {
+		timeout = step.Timeout.Duration
+	}
+	runCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	shell, shellArgs := runShell(run.Shell)
+	command, varEnv := e.substituteCommand(run.Command, shell)
+	cmd := exec.CommandContext(runCtx, shell, append(shellArgs, command)...)
+	cmd.Dir = dir
+	cmd.Env = append(e.runEnv(step), varEnv...)
+	setRunProcAttr(cmd)
+	cmd.WaitDelay = runWaitDelay
+
+	stdout := &tailBuffer{max: MaxRunOutputBytes}
+	stderr := stdout
+	if !run.MergeStderr {
+		stderr = &tailBuffer{max: MaxRunOutputBytes}
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	result.FinishedAt = time.Now()
+	result.Output = stdout.String()
+	if !run.MergeStderr {
+		result.Stderr = stderr.String()
+	}
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case ctx.Err() != nil:
+			result.Status = StatusCancelled
+			return result
+		case runCtx.Err() == context.DeadlineExceeded:
+			code := -1
+			result.ExitCode = &code
+			result.Status = StatusFailed
+			result.Error = &StepError{
+				Type:      "timeout",
+				Message:   fmt.Sprintf("command timed out after %s", timeout),
+				Details:   runErrorDetails(result),
+				Timestamp: time.Now(),
+			}
+			return result
+		case errors.As(err, &exitErr):
+			code := exitErr.ExitCode()
+			result.ExitCode = &code
+		default:
+			result.Status = StatusFailed
+			result.Error = &StepError{
+				Type:      "run",
+				Message:   fmt.Sprintf("failed to run command: %v", err),
+				Timestamp: time.Now(),
+			}
+			return result
+		}
+	} else {
+		code := 0
+		result.ExitCode = &code
+	}
+
+	if *result.ExitCode != 0 && !run.AllowFailure {
+		result.Status = StatusFailed
+		result.Error = &StepError{
+			Type:      "exit_code",
+			Message:   fmt.Sprintf("command exited with status %d", *result.ExitCode),
+			Details:   runErrorDetails(result),
+			Timestamp: time.Now(),
+		}
+		return result
+	}
+
+	if step.OutputVar != "" && step.OutputParse.Type != "" && step.OutputParse.Type != "none" {
+		parsed, err := e.parseOutput(result.Output, step.OutputParse)
+		if err != nil {
+			e.varMu.Lock()
+			e.state.Errors = append(e.state.Errors, ExecutionError{
+				StepID:    step.ID,
+				Type:      "parse",
+				Message:   fmt.Sprintf("failed to parse output: %v", err),
+				Timestamp: time.Now(),
+				Fatal:     false,
+			})
+			e.varMu.Unlock()
+		} else {
+			result.ParsedData = parsed
+		}
+	}
+
+	result.Status = StatusCompleted
+	return result
+}
+
+// substituteCommand substitutes variables into a run step's command without
+// letting the shell parse their values. Each reference becomes an expansion
+// of an NTM_VAR_* environment variable, returned in env, that holds the
+// value; webhook payloads and agent output can then never inject commands.
+func (e *Executor) substituteCommand(command, shell string) (string, []string) {
+	values := make(map[string]string)
+	var env []string
+
+	e.varMu.RLock()
+	defer e.varMu.RUnlock()
+	sub := NewSubstitutor(e.state, e.config.Session, e.state.WorkflowID)
+	result, _ := sub.SubstituteRefs(command, func(varPath, value string) string {
+		base := commandVarName(varPath)
+		name := base
+		for i := 2; ; i++ {
+			existing, ok := values[name]
+			if !ok {
+				values[name] = value
+				env = append(env, name+"="+value)
+				break
+			}
+			if existing == value {
+				break
+			}
+			name = fmt.Sprintf("%s_%d", base, i)
+		}
+		return shellVarRef(shell, name)
+	})
+	return result, env
+}
+
+// commandVarName names the environment variable that carries a variable
+// reference into a run command: NTM_VAR_<name> for ${vars.<name>}, and the
+// path with every other character replaced by '_' otherwise, so
+// ${steps.tests.output} becomes NTM_VAR_steps_tests_output.
+func commandVarName(varPath string) string {
+	path := strings.TrimPrefix(strings.TrimSpace(varPath), "vars.")
+	return "NTM_VAR_" + strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, path)
+}
+
+// resolveRunDir resolves a run step's working directory against the project dir.
+func (e *Executor) resolveRunDir(dir string) string {
+	if dir == "" {
+		return e.config.ProjectDir
+	}
+	if filepath.IsAbs(dir) || e.config.ProjectDir == "" {
+		return dir
+	}
+	return filepath.Join(e.config.ProjectDir, dir)
+}
+
+// runEnv builds the environment for a run step: the ntm process environment,
+// workflow context variables, then the step's own env entries. Values are
+// passed to the process as-is, never through the shell.
+func (e *Executor) runEnv(step *Step) []string {
+	env := os.Environ()
+	env = append(env,
+		"NTM_SESSION="+e.config.Session,
+		"NTM_RUN_ID="+e.state.RunID,
+		"NTM_WORKFLOW="+e.state.WorkflowID,
+		"NTM_STEP_ID="+step.ID,
+	)
+
+	keys := make([]string, 0, len(step.Run.Env))
+	for k := range step.Run.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+e.substituteVariables(step.Run.Env[k]))
+	}
+	return env
+}
+
+// runErrorDetails returns the most useful output for a failed command.
+func runErrorDetails(result StepResult) string {
+	if strings.TrimSpace(result.Stderr) != "" {
+		return lastLines(result.Stderr, 50)
+	}
+	return lastLines(result.Output, 50)
+}
+
+// lastLines returns at most n trailing lines of s.
+func lastLines(s string, n int) string {
+	s = strings.TrimRight(s, "\n")
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tailBuffer is an io.Writer that keeps at most max bytes, discarding the
+// oldest data first. Safe for concurrent writes (stdout and stderr may share one).
+type tailBuffer struct {
+	mu        sync.Mutex
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.truncated {
+		return "[output truncated]\n" + string(b.buf)
+	}
+	return string(b.buf)
+}
@@ -0,0 +1,278 @@
+//go:build unix
+
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newRunTestExecutor(t *testing.T) *Executor {
+	t.Helper()
+	cfg := DefaultExecutorConfig("run-test")
+	cfg.ProjectDir = t.TempDir()
+	return NewExecutor(cfg)
+}
+
+func runWorkflow(t *testing.T, e *Executor, content string) (*ExecutionState, error) {
+	t.Helper()
+	w, err := ParseString(content, "yaml")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	if res := Validate(w); !res.Valid {
+		t.Fatalf("Validate: %+v", res.Errors)
+	}
+	return e.Run(context.Background(), w, nil, nil)
+}
+
+func TestRunStep_ExitCodeAndOutput(t *testing.T) {
+	e := newRunTestExecutor(t)
+	state, err := runWorkflow(t, e, `
+schema_version: "2.0"
+name: run-basic
+steps:
+  - id: hello
+    run: echo hello; echo oops >&2
+    output_var: greeting
+`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	res := state.Steps["hello"]
+	if res.Status != StatusCompleted {
+		t.Fatalf("status = %s, want completed (%+v)", res.Status, res.Error)
+	}
+	if res.ExitCode == nil || *res.ExitCode != 0 {
+		t.Errorf("ExitCode = %v, want 0", res.ExitCode)
+	}
+	if strings.TrimSpace(res.Output) != "hello" {
+		t.Errorf("Output = %q, want hello", res.Output)
+	}
+	if strings.TrimSpace(res.Stderr) != "oops" {
+		t.Errorf("Stderr = %q, want oops", res.Stderr)
+	}
+	if got := state.Variables["greeting"]; strings.TrimSpace(got.(string)) != "hello" {
+		t.Errorf("greeting = %q", got)
+	}
+}
+
+func TestRunStep_FailureAndAllowFailure(t *testing.T) {
+	e := newRunTestExecutor(t)
+	state, err := runWorkflow(t, e, `
+schema_version: "2.0"
+name: run-fail
+steps:
+  - id: tests
+    run:
+      command: 'echo "FAIL: TestFoo" >&2; exit 3'
+      allow_failure: true
+  - id: fix
+    depends_on: [tests]
+    when: ${steps.tests.exit_code} != 0
+    run: echo fixing
+  - id: celebrate
+    depends_on: [tests]
+    when: ${steps.tests.exit_code} == 0
+    run: echo green
+  - id: strict
+    depends_on: [fix]
+    run: exit 2
+`)
+	if err == nil {
+		t.Fatal("expected workflow to fail on strict step")
+	}
+
+	if res := state.Steps["tests"]; res.Status != StatusCompleted || res.ExitCode == nil || *res.ExitCode != 3 {
+		t.Errorf("tests = %+v, want completed with exit code 3", res)
+	}
+	if res := state.Steps["fix"]; res.Status != StatusCompleted {
+		t.Errorf("fix status = %s, want completed", res.Status)
+	}
+	if res := state.Steps["celebrate"]; res.Status != StatusSkipped {
+		t.Errorf("celebrate status = %s, want skipped", res.Status)
+	}
+
+	strict := state.Steps["strict"]
+	if strict.Status != StatusFailed || strict.Error == nil || strict.Error.Type != "exit_code" {
+		t.Fatalf("strict = %+v, want exit_code failure", strict)
+	}
+	if strict.ExitCode == nil || *strict.ExitCode != 2 {
+		t.Errorf("strict ExitCode = %v, want 2", strict.ExitCode)
+	}
+}
+
+func TestRunStep_Timeout(t *testing.T) {
+	e := newRunTestExecutor(t)
+	start := time.Now()
+	state, err := runWorkflow(t, e, `
+schema_version: "2.0"
+name: run-timeout
+steps:
+  - id: slow
+    run: sleep 30
+    timeout: 200ms
+`)
+	if err == nil {
+		t.Fatal("expected timeout failure")
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Errorf("timed-out command was not killed promptly (%s)", time.Since(start))
+	}
+	res := state.Steps["slow"]
+	if res.Error == nil || res.Error.Type != "timeout" {
+		t.Errorf("error = %+v, want timeout", res.Error)
+	}
+	if res.ExitCode == nil || *res.ExitCode != -1 {
+		t.Errorf("ExitCode = %v, want -1", res.ExitCode)
+	}
+}
+
+func TestRunStep_DirEnvAndParse(t *testing.T) {
+	e := newRunTestExecutor(t)
+	sub := filepath.Join(e.config.ProjectDir, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := runWorkflow(t, e, `
+schema_version: "2.0"
+name: run-env
+vars:
+  target:
+    default: ./pkg/...
+steps:
+  - id: info
+    run:
+      command: 'printf "{\"dir\": \"%s\", \"target\": \"%s\", \"run\": \"%s\"}" "$(basename "$PWD")" "$TARGET" "$NTM_RUN_ID"'
+      dir: sub
+      env:
+        TARGET: ${vars.target}
+    output_var: info
+    output_parse: json
+  - id: echo
+    depends_on: [info]
+    run: echo ${steps.info.data.target}
+`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	data, ok := state.Steps["info"].ParsedData.(map[string]interface{})
+	if !ok {
+		t.Fatalf("ParsedData = %#v, want map", state.Steps["info"].ParsedData)
+	}
+	if data["dir"] != "sub" || data["target"] != "./pkg/..." || data["run"] != state.RunID {
+		t.Errorf("parsed = %v", data)
+	}
+	if got := strings.TrimSpace(state.Steps["echo"].Output); got != "./pkg/..." {
+		t.Errorf("echo output = %q", got)
+	}
+}
+
+func TestRunStep_VariablesAreNotParsedByTheShell(t *testing.T) {
+	e := newRunTestExecutor(t)
+	state, err := runWorkflow(t, e, `
+schema_version: "2.0"
+name: run-inject
+vars:
+  msg:
+    default: "hi; touch pwned"
+  sub:
+    default: "$(touch pwned-sub) 'quoted'"
+steps:
+  - id: plain
+    run: echo ${vars.msg}
+  - id: quoted
+    run: echo "${vars.msg}" "${vars.sub}" "${vars.msg}"
+  - id: env
+    run:
+      command: echo "$MSG"
+      env:
+        MSG: ${vars.sub}
+`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, name := range []string{"pwned", "pwned-sub"} {
+		if _, err := os.Stat(filepath.Join(e.config.ProjectDir, name)); err == nil {
+			t.Errorf("variable value was executed: %s exists", name)
+		}
+	}
+	want := map[string]string{
+		"plain":  "hi; touch pwned",
+		"quoted": "hi; touch pwned $(touch pwned-sub) 'quoted' hi; touch pwned",
+		"env":    "$(touch pwned-sub) 'quoted'",
+	}
+	for id, w := range want {
+		if got := strings.TrimSpace(state.Steps[id].Output); got != w {
+			t.Errorf("%s output = %q, want %q", id, got, w)
+		}
+	}
+}
+
+func TestRunStep_RetestLoop(t *testing.T) {
+	e := newRunTestExecutor(t)
+	state, err := runWorkflow(t, e, `
+schema_version: "2.0"
+name: run-loop
+steps:
+  - id: fix_loop
+    loop:
+      times: 5
+      steps:
+        - id: retest
+          run:
+            command: 'n=$(cat count 2>/dev/null || echo 0); n=$((n+1)); echo $n > count; [ $n -ge 3 ]'
+            allow_failure: true
+        - id: done
+          loop_control: break
+          when: ${steps.retest.exit_code} == 0
+`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(e.config.ProjectDir, "count"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "3" {
+		t.Errorf("loop ran %s times, want 3", got)
+	}
+	if got := state.Variables["steps.retest.exit_code"]; got != 0 {
+		t.Errorf("steps.retest.exit_code = %v, want 0", got)
+	}
+}
+
+func TestRunStep_DryRun(t *testing.T) {
+	e := newRunTestExecutor(t)
+	e.config.DryRun = true
+	state, err := runWorkflow(t, e, `
+schema_version: "2.0"
+name: run-dry
+steps:
+  - id: nuke
+    run: touch should-not-exist
+`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.HasPrefix(state.Steps["nuke"].Output, "[DRY RUN]") {
+		t.Errorf("Output = %q", state.Steps["nuke"].Output)
+	}
+	if _, err := os.Stat(filepath.Join(e.config.ProjectDir, "should-not-exist")); err == nil {
+		t.Error("dry run executed the command")
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{max: 8}
+	b.Write([]byte("hello "))
+	b.Write([]byte("world"))
+	if got := b.String(); got != "[output truncated]\nlo world" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package pipeline
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// runShell returns the shell and arguments used to run a command string.
+func runShell(shell string) (string, []string) {
+	if shell == "" {
+		shell = "sh"
+	}
+	return shell, []string{"-c"}
+}
+
+// shellVarRef returns the shell's expansion of environment variable name.
+func shellVarRef(_, name string) string {
+	return "${" + name + "}"
+}
+
+// setRunProcAttr runs the command in its own process group and kills the
+// whole group on cancellation, so test runners and their children do not
+// outlive a timed-out step.
+func setRunProcAttr(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		if cmd.Process == nil {
+			return nil
+		}
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build windows
+
+package pipeline
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// runShell returns the shell and arguments used to run a command string.
+func runShell(shell string) (string, []string) {
+	if shell == "" {
+		// Delayed expansion lets shellVarRef read values without cmd parsing them
+		return "cmd", []string{"/V:ON", "/C"}
+	}
+	if strings.Contains(strings.ToLower(shell), "powershell") || strings.Contains(strings.ToLower(shell), "pwsh") {
+		return shell, []string{"-NoProfile", "-Command"}
+	}
+	return shell, []string{"-c"}
+}
+
+// shellVarRef returns the shell's expansion of environment variable name.
+func shellVarRef(shell, name string) string {
+	lower := strings.ToLower(shell)
+	switch {
+	case strings.TrimSuffix(filepath.Base(lower), ".exe") == "cmd":
+		return "!" + name + "!"
+	case strings.Contains(lower, "powershell") || strings.Contains(lower, "pwsh"):
+		return "${env:" + name + "}"
+	default:
+		return "${" + name + "}"
+	}
+}
+
+// setRunProcAttr sets platform-specific process attributes for run steps.
+// On Windows, the default exec.CommandContext kill behavior is used.
+func setRunProcAttr(cmd *exec.Cmd) {}
@@ -3,6 +3,8 @@ package pipeline
 import (
 	"strings"
 	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/status"
 )
 
 // SchemaVersion is the current workflow schema version
@@ -22,10 +24,21 @@ type Workflow struct {
 	// Global settings
 	Settings WorkflowSettings `yaml:"settings,omitempty" toml:"settings,omitempty" json:"settings,omitempty"`
 
+	// Values exposed to parent workflows that invoke this one with 'uses:'
+	Outputs map[string]OutputDef `yaml:"outputs,omitempty" toml:"outputs,omitempty" json:"outputs,omitempty"`
+
 	// Step definitions
 	Steps []Step `yaml:"steps" toml:"steps" json:"steps"`
 }
 
+// OutputDef declares a workflow output. Value is evaluated against the
+// workflow's final state (e.g. ${steps.tests.exit_code}) and converted to Type.
+type OutputDef struct {
+	Description string  `yaml:"description,omitempty" toml:"description,omitempty" json:"description,omitempty"`
+	Value       string  `yaml:"value" toml:"value" json:"value"`
+	Type        VarType `yaml:"type,omitempty" toml:"type,omitempty" json:"type,omitempty"` // string (default), number, boolean, array
+}
+
 // VarDef defines a workflow variable with optional default and type info
 type VarDef struct {
 	Description string      `yaml:"description,omitempty" toml:"description,omitempty" json:"description,omitempty"`
@@ -92,11 +105,22 @@ type Step struct {
 	Agent string          `yaml:"agent,omitempty" toml:"agent,omitempty" json:"agent,omitempty"` // Agent type: claude, codex, gemini
 	Pane  int             `yaml:"pane,omitempty" toml:"pane,omitempty" json:"pane,omitempty"`    // Specific pane index
 	Route RoutingStrategy `yaml:"route,omitempty" toml:"route,omitempty" json:"route,omitempty"` // Routing strategy
+	Model string          `yaml:"model,omitempty" toml:"model,omitempty" json:"model,omitempty"` // Only panes running this model variant
 
 	// Prompt (choose one)
 	Prompt     string `yaml:"prompt,omitempty" toml:"prompt,omitempty" json:"prompt,omitempty"`
 	PromptFile string `yaml:"prompt_file,omitempty" toml:"prompt_file,omitempty" json:"prompt_file,omitempty"`
 
+	// Shell command executed directly by the executor (mutually exclusive with Prompt)
+	Run *RunConfig `yaml:"run,omitempty" toml:"run,omitempty" json:"run,omitempty"`
+
+	// Human approval checkpoint (mutually exclusive with Prompt and Run)
+	Gate *GateConfig `yaml:"gate,omitempty" toml:"gate,omitempty" json:"gate,omitempty"`
+
+	// Sub-workflow invocation (mutually exclusive with Prompt, Run and Gate)
+	Uses string                 `yaml:"uses,omitempty" toml:"uses,omitempty" json:"uses,omitempty"` // Workflow name or path
+	With map[string]interface{} `yaml:"with,omitempty" toml:"with,omitempty" json:"with,omitempty"` // Input vars for the sub-workflow
+
 	// Wait configuration
 	Wait    WaitCondition `yaml:"wait,omitempty" toml:"wait,omitempty" json:"wait,omitempty"` // completion, idle, time, none
 	Timeout Duration      `yaml:"timeout,omitempty" toml:"timeout,omitempty" json:"timeout,omitempty"`
@@ -120,6 +144,9 @@ type Step struct {
 	// Parallel execution (mutually exclusive with Prompt)
 	Parallel []Step `yaml:"parallel,omitempty" toml:"parallel,omitempty" json:"parallel,omitempty"`
 
+	// Matrix fan-out: run the prompt once per agent/model/vars combination
+	Matrix *MatrixConfig `yaml:"matrix,omitempty" toml:"matrix,omitempty" json:"matrix,omitempty"`
+
 	// Loop execution
 	Loop *LoopConfig `yaml:"loop,omitempty" toml:"loop,omitempty" json:"loop,omitempty"`
 
@@ -158,6 +185,92 @@ func (o *OutputParse) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// RunConfig defines a shell command step. It may be written as a plain
+// string (run: go test ./...) or as a table with the fields below.
+type RunConfig struct {
+	Command      string            `yaml:"command" toml:"command" json:"command"`
+	Shell        string            `yaml:"shell,omitempty" toml:"shell,omitempty" json:"shell,omitempty"`                         // Default: sh (cmd on Windows)
+	Dir          string            `yaml:"dir,omitempty" toml:"dir,omitempty" json:"dir,omitempty"`                               // Working directory (relative to project dir)
+	Env          map[string]string `yaml:"env,omitempty" toml:"env,omitempty" json:"env,omitempty"`                               // Extra environment variables
+	AllowFailure bool              `yaml:"allow_failure,omitempty" toml:"allow_failure,omitempty" json:"allow_failure,omitempty"` // Non-zero exit still completes the step
+	MergeStderr  bool              `yaml:"merge_stderr,omitempty" toml:"merge_stderr,omitempty" json:"merge_stderr,omitempty"`    // Capture stderr into the step output
+}
+
+// UnmarshalText allows RunConfig to be specified as a simple command string
+func (r *RunConfig) UnmarshalText(text []byte) error {
+	r.Command = string(text)
+	return nil
+}
+
+// UnmarshalTOML accepts both the string and table forms. The TOML decoder
+// prefers UnmarshalText for any value, so tables are decoded explicitly.
+func (r *RunConfig) UnmarshalTOML(data interface{}) error {
+	if cmd, ok := data.(string); ok {
+		r.Command = cmd
+		return nil
+	}
+	return decodeTOMLTable(data, (*runConfigFields)(r))
+}
+
+// runConfigFields has RunConfig's fields without its unmarshal methods.
+type runConfigFields RunConfig
+
+// GateConfig defines a human approval checkpoint. The run pauses until the
+// request is approved or denied (e.g. via 'ntm approve'), or the step's
+// timeout elapses.
+type GateConfig struct {
+	Name       string   `yaml:"name,omitempty" toml:"name,omitempty" json:"name,omitempty"`                      // Checkpoint name shown to approvers (default: step ID)
+	Message    string   `yaml:"message,omitempty" toml:"message,omitempty" json:"message,omitempty"`             // Context for the approver, supports variables
+	Summary    []string `yaml:"summary,omitempty" toml:"summary,omitempty" json:"summary,omitempty"`             // Step IDs to summarize (default: all finished steps)
+	OnDeny     string   `yaml:"on_deny,omitempty" toml:"on_deny,omitempty" json:"on_deny,omitempty"`             // fail (default) or continue
+	OnTimeout  string   `yaml:"on_timeout,omitempty" toml:"on_timeout,omitempty" json:"on_timeout,omitempty"`    // deny (default) or approve
+	RequireSLB bool     `yaml:"require_slb,omitempty" toml:"require_slb,omitempty" json:"require_slb,omitempty"` // Two-person rule
+}
+
+// Gate decision and policy values
+const (
+	GateApproved = "approved"
+	GateDenied   = "denied"
+
+	GateOnDenyFail     = "fail"
+	GateOnDenyContinue = "continue"
+
+	GateOnTimeoutDeny    = "deny"
+	GateOnTimeoutApprove = "approve"
+)
+
+// UnmarshalText allows GateConfig to be specified as just the checkpoint name
+func (g *GateConfig) UnmarshalText(text []byte) error {
+	g.Name = string(text)
+	return nil
+}
+
+// UnmarshalTOML accepts both the string and table forms.
+func (g *GateConfig) UnmarshalTOML(data interface{}) error {
+	if name, ok := data.(string); ok {
+		g.Name = name
+		return nil
+	}
+	return decodeTOMLTable(data, (*gateConfigFields)(g))
+}
+
+// gateConfigFields has GateConfig's fields without its unmarshal methods.
+type gateConfigFields GateConfig
+
+// MatrixConfig expands a prompt step into one cell per combination of the
+// listed agent types, model variants and variable values. Vars values are
+// available to the prompt as ${matrix.<name>} (also ${matrix.agent} and
+// ${matrix.model}).
+type MatrixConfig struct {
+	Agent []string                 `yaml:"agent,omitempty" toml:"agent,omitempty" json:"agent,omitempty"` // Agent types to compare
+	Model []string                 `yaml:"model,omitempty" toml:"model,omitempty" json:"model,omitempty"` // Model variants to compare
+	Vars  map[string][]interface{} `yaml:"vars,omitempty" toml:"vars,omitempty" json:"vars,omitempty"`    // Prompt variants to compare
+	Diff  bool                     `yaml:"diff,omitempty" toml:"diff,omitempty" json:"diff,omitempty"`    // Include pairwise output diffs
+}
+
+// MaxMatrixCells caps how many cells a matrix step may expand to.
+const MaxMatrixCells = 32
+
 // LoopConfig defines loop iteration settings for for-each, while, and times loops
 type LoopConfig struct {
 	// For-each loop: iterate over array
@@ -229,11 +342,16 @@ type StepResult struct {
 	Error      *StepError      `json:"error,omitempty"`
 	SkipReason string          `json:"skip_reason,omitempty"` // If skipped due to 'when' condition
 	Attempts   int             `json:"attempts,omitempty"`    // Number of retry attempts
+	ExitCode   *int            `json:"exit_code,omitempty"`   // Exit status of run steps
+	Stderr     string          `json:"stderr,omitempty"`      // Standard error of run steps
+	ApprovalID string          `json:"approval_id,omitempty"` // Approval request of gate steps
+	SubRunID   string          `json:"sub_run_id,omitempty"`  // Child run of sub-workflow steps
+	Matrix     *MatrixResult   `json:"matrix,omitempty"`      // Cell comparison of matrix steps
 }
 
 // StepError contains detailed error information for a failed step
 type StepError struct {
-	Type       string    `json:"type"` // timeout, agent_error, crash, validation, routing, send, capture
+	Type       string    `json:"type"` // timeout, agent_error, crash, validation, routing, send, capture, run, exit_code, gate, gate_denied, uses
 	Message    string    `json:"message"`
 	Details    string    `json:"details,omitempty"`     // Full error output
 	PaneOutput string    `json:"pane_output,omitempty"` // Last N lines from pane for debugging
@@ -254,7 +372,8 @@ type ExecutionState struct {
 	FinishedAt   time.Time              `json:"finished_at,omitempty"`
 	CurrentStep  string                 `json:"current_step,omitempty"`
 	Steps        map[string]StepResult  `json:"steps"`
-	Variables    map[string]interface{} `json:"variables"` // Runtime variables including step outputs
+	Variables    map[string]interface{} `json:"variables"`         // Runtime variables including step outputs
+	Outputs      map[string]interface{} `json:"outputs,omitempty"` // Declared workflow outputs, set on success
 	Errors       []ExecutionError       `json:"errors,omitempty"`
 }
 
@@ -269,11 +388,12 @@ type ExecutionError struct {
 
 // ProgressEvent is emitted during workflow execution for monitoring
 type ProgressEvent struct {
-	Type      string    `json:"type"` // step_start, step_complete, step_error, parallel_start, workflow_complete
+	Type      string    `json:"type"` // step_start, step_complete, step_error, parallel_start, gate_waiting, workflow_complete
 	StepID    string    `json:"step_id,omitempty"`
 	Message   string    `json:"message"`
 	Progress  float64   `json:"progress"` // 0.0 - 1.0
 	Timestamp time.Time `json:"timestamp"`
+	Depth     int       `json:"depth,omitempty"` // Sub-workflow nesting level (0 = top-level run)
 }
 
 // ParallelGroupResult contains results from a parallel execution group
@@ -321,9 +441,12 @@ func NormalizeAgentType(t string) string {
 	return lower
 }
 
-// IsValidAgentType checks if the given agent type is recognized.
+// IsValidAgentType checks if the given agent type is recognized, including
+// registered plugin agent types.
 // Case-insensitive: "Claude", "CLAUDE", "claude" are all valid.
 func IsValidAgentType(t string) bool {
-	_, ok := AgentTypeAliases[strings.ToLower(t)]
-	return ok
+	if _, ok := AgentTypeAliases[strings.ToLower(t)]; ok {
+		return true
+	}
+	return status.IsKnownAgentType(t)
 }
@@ -0,0 +1,444 @@
+// Package pipeline provides workflow execution for AI agent orchestration.
+// subworkflow.go implements reusable sub-workflow steps (uses:).
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/workflow"
+)
+
+// BuiltinWorkflowPrefix selects a built-in pipeline in a uses: reference.
+const BuiltinWorkflowPrefix = "builtin:"
+
+// workflowExtensions are tried, in order, when resolving a bare workflow name.
+var workflowExtensions = []string{".yaml", ".yml", ".toml"}
+
+// ResolveWorkflowRef loads the workflow named by a uses: reference and returns
+// it with a stable key (absolute path or builtin:<name>) for cycle detection.
+//
+// Resolution order:
+//   - builtin:<name> always loads the built-in pipeline
+//   - paths (containing a separator or extension) are relative to baseDir,
+//     then projectDir
+//   - bare names are looked up in <projectDir>/.ntm/workflows, then the
+//     built-in pipelines
+func ResolveWorkflowRef(ref, baseDir, projectDir string) (*Workflow, string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, "", fmt.Errorf("empty workflow reference")
+	}
+
+	if name, ok := strings.CutPrefix(ref, BuiltinWorkflowPrefix); ok {
+		return loadBuiltinWorkflow(name)
+	}
+
+	if filepath.Ext(ref) != "" || strings.ContainsAny(ref, `/\`) {
+		var candidates []string
+		if filepath.IsAbs(ref) {
+			candidates = []string{ref}
+		} else {
+			if baseDir != "" {
+				candidates = append(candidates, filepath.Join(baseDir, ref))
+			}
+			candidates = append(candidates, filepath.Join(projectDir, ref))
+		}
+		for _, path := range candidates {
+			if _, err := os.Stat(path); err == nil {
+				return loadWorkflowFile(path)
+			}
+		}
+		return nil, "", fmt.Errorf("workflow file not found: %s", ref)
+	}
+
+	dir := filepath.Join(projectDir, ".ntm", "workflows")
+	for _, ext := range workflowExtensions {
+		path := filepath.Join(dir, ref+ext)
+		if _, err := os.Stat(path); err == nil {
+			return loadWorkflowFile(path)
+		}
+	}
+	if _, ok := workflow.BuiltinPipeline(ref); ok {
+		return loadBuiltinWorkflow(ref)
+	}
+	return nil, "", fmt.Errorf("workflow %q not found in %s or built-ins (%s)",
+		ref, dir, strings.Join(workflow.BuiltinPipelineNames(), ", "))
+}
+
+func loadWorkflowFile(path string) (*Workflow, string, error) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	w, err := ParseFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return w, path, nil
+}
+
+func loadBuiltinWorkflow(name string) (*Workflow, string, error) {
+	data, ok := workflow.BuiltinPipeline(name)
+	if !ok {
+		return nil, "", fmt.Errorf("unknown built-in workflow %q (available: %s)",
+			name, strings.Join(workflow.BuiltinPipelineNames(), ", "))
+	}
+	w, err := ParseString(string(data), "yaml")
+	if err != nil {
+		return nil, "", fmt.Errorf("built-in workflow %s: %w", name, err)
+	}
+	return w, BuiltinWorkflowPrefix + name, nil
+}
+
+// workflowDir returns the directory relative uses: paths resolve against for
+// the workflow identified by key. Built-ins have none.
+func workflowDir(key string) string {
+	if key == "" || strings.HasPrefix(key, BuiltinWorkflowPrefix) {
+		return ""
+	}
+	return filepath.Dir(key)
+}
+
+// usesRef is a uses: reference found in a workflow's steps.
+type usesRef struct {
+	stepID string
+	ref    string
+}
+
+// collectUses returns the uses: references of steps, including parallel and
+// loop sub-steps.
+func collectUses(steps []Step) []usesRef {
+	var refs []usesRef
+	for _, step := range steps {
+		if step.Uses != "" {
+			refs = append(refs, usesRef{stepID: step.ID, ref: step.Uses})
+		}
+		refs = append(refs, collectUses(step.Parallel)...)
+		if step.Loop != nil {
+			refs = append(refs, collectUses(step.Loop.Steps)...)
+		}
+	}
+	return refs
+}
+
+// validateUses resolves every uses: reference reachable from the graph and
+// reports missing workflows and workflows that (transitively) invoke themselves.
+func (g *DependencyGraph) validateUses() []DependencyError {
+	var errs []DependencyError
+
+	rootKey := ""
+	if g.source != "" {
+		rootKey = g.source
+		if abs, err := filepath.Abs(g.source); err == nil {
+			rootKey = abs
+		}
+	}
+
+	ids := make([]string, 0, len(g.steps))
+	for id, step := range g.steps {
+		if step.Uses != "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	rootRefs := make([]usesRef, 0, len(ids))
+	for _, id := range ids {
+		rootRefs = append(rootRefs, usesRef{stepID: id, ref: g.steps[id].Uses})
+	}
+
+	done := make(map[string]bool)
+	var walk func(baseDir string, refs []usesRef, chain []string)
+	walk = func(baseDir string, refs []usesRef, chain []string) {
+		for _, r := range refs {
+			child, key, err := ResolveWorkflowRef(r.ref, baseDir, g.projectDir)
+			if err != nil {
+				errs = append(errs, DependencyError{
+					Type:    "missing_workflow",
+					Steps:   []string{r.stepID},
+					Message: fmt.Sprintf("step %q uses %q: %v", r.stepID, r.ref, err),
+				})
+				continue
+			}
+			if containsString(chain, key) {
+				names := make([]string, 0, len(chain)+1)
+				for _, k := range append(chain, key) {
+					if k != "" {
+						names = append(names, workflowDisplayName(k))
+					}
+				}
+				errs = append(errs, DependencyError{
+					Type:    "uses_cycle",
+					Steps:   []string{r.stepID},
+					Message: fmt.Sprintf("sub-workflow cycle via step %q: %s", r.stepID, strings.Join(names, " -> ")),
+				})
+				continue
+			}
+			if done[key] {
+				continue
+			}
+			walk(workflowDir(key), collectUses(child.Steps), append(chain, key))
+			done[key] = true
+		}
+	}
+	walk(workflowDir(rootKey), rootRefs, []string{rootKey})
+
+	return errs
+}
+
+func workflowDisplayName(key string) string {
+	if strings.HasPrefix(key, BuiltinWorkflowPrefix) {
+		return key
+	}
+	return filepath.Base(key)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// executeSubWorkflowStep runs the workflow named by step.Uses as a child run.
+// The child persists its own state under <parent-run>-<step>, so resuming the
+// parent resumes (or reuses) the child instead of starting it over.
+func (e *Executor) executeSubWorkflowStep(ctx context.Context, step *Step) StepResult {
+	result := StepResult{
+		StepID:    step.ID,
+		Status:    StatusRunning,
+		StartedAt: time.Now(),
+	}
+
+	child, key, err := ResolveWorkflowRef(step.Uses, workflowDir(e.config.WorkflowFile), e.config.ProjectDir)
+	if err != nil {
+		return stepFailure(result, "uses", fmt.Sprintf("failed to load sub-workflow: %v", err))
+	}
+	if res := Validate(child); !res.Valid {
+		return stepFailure(result, "uses", fmt.Sprintf("sub-workflow %s is invalid: %s", key, res.Errors[0].Message))
+	}
+
+	inputs, err := e.subWorkflowInputs(step, child)
+	if err != nil {
+		return stepFailure(result, "uses", err.Error())
+	}
+
+	cfg := e.config
+	cfg.RunID = e.state.RunID + "-" + step.ID
+	cfg.WorkflowFile = key
+	if step.Timeout.Duration > 0 {
+		cfg.GlobalTimeout = step.Timeout.Duration
+	}
+	sub := NewExecutor(cfg)
+	sub.approvals = e.approvals
+	result.SubRunID = cfg.RunID
+
+	progress := make(chan ProgressEvent, 100)
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		for event := range progress {
+			e.forwardProgress(step.ID, event)
+		}
+	}()
+
+	var childState *ExecutionState
+	prior, loadErr := e.loadChildState(cfg.RunID, child.Name)
+	switch {
+	case loadErr == nil && prior.Status == StatusCompleted:
+		childState = prior
+	case loadErr == nil:
+		childState, err = sub.Resume(ctx, child, prior, progress)
+	default:
+		childState, err = sub.Run(ctx, child, inputs, progress)
+	}
+	close(progress)
+	<-forwarded
+
+	if childState != nil && len(childState.Outputs) > 0 {
+		outputs := make(map[string]interface{}, len(childState.Outputs))
+		for name, value := range childState.Outputs {
+			outputs[name] = value
+		}
+		result.ParsedData = outputs
+		if data, jsonErr := json.Marshal(outputs); jsonErr == nil {
+			result.Output = string(data)
+		}
+	}
+	result.FinishedAt = time.Now()
+
+	if ctx.Err() != nil {
+		result.Status = StatusCancelled
+		result.SkipReason = "cancelled during sub-workflow"
+		return result
+	}
+	if err != nil {
+		return stepFailure(result, "uses", fmt.Sprintf("sub-workflow %s failed: %v", child.Name, err))
+	}
+
+	result.Status = StatusCompleted
+	return result
+}
+
+// subWorkflowInputs builds the child's initial variables from step.With,
+// substituting parent variables into string values.
+func (e *Executor) subWorkflowInputs(step *Step, child *Workflow) (map[string]interface{}, error) {
+	inputs := make(map[string]interface{}, len(step.With))
+
+	e.varMu.RLock()
+	sub := NewSubstitutor(e.state, e.config.Session, e.state.WorkflowID)
+	for name, value := range step.With {
+		if s, ok := value.(string); ok {
+			resolved, err := sub.Resolve(s)
+			if err != nil {
+				e.varMu.RUnlock()
+				return nil, fmt.Errorf("with.%s: %v", name, err)
+			}
+			value = resolved
+		}
+		inputs[name] = value
+	}
+	e.varMu.RUnlock()
+
+	for name, def := range child.Vars {
+		if _, ok := inputs[name]; !ok && def.Required && def.Default == nil {
+			return nil, fmt.Errorf("sub-workflow %s requires input %q", child.Name, name)
+		}
+	}
+	return inputs, nil
+}
+
+// loadChildState returns the persisted state of a previous child run, if any.
+func (e *Executor) loadChildState(runID, workflowName string) (*ExecutionState, error) {
+	projectDir, err := e.stateProjectDir()
+	if err != nil {
+		return nil, err
+	}
+	prior, err := LoadState(projectDir, runID)
+	if err != nil {
+		return nil, err
+	}
+	if prior.WorkflowID != workflowName {
+		return nil, fmt.Errorf("run %s belongs to workflow %s", runID, prior.WorkflowID)
+	}
+	return prior, nil
+}
+
+// forwardProgress re-emits a child run's progress event, prefixing the step
+// ID with the invoking step and increasing its nesting depth.
+func (e *Executor) forwardProgress(stepID string, event ProgressEvent) {
+	if e.progress == nil {
+		return
+	}
+	if event.StepID != "" {
+		event.StepID = stepID + "/" + event.StepID
+	} else {
+		event.StepID = stepID
+	}
+	event.Depth++
+
+	select {
+	case e.progress <- event:
+	default:
+		// Don't block if channel is full
+	}
+}
+
+// resolveOutputs evaluates the workflow's declared outputs against the final
+// state and records them on the execution state.
+func (e *Executor) resolveOutputs(workflow *Workflow) error {
+	if len(workflow.Outputs) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(workflow.Outputs))
+	for name := range workflow.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	e.varMu.RLock()
+	sub := NewSubstitutor(e.state, e.config.Session, e.state.WorkflowID)
+	outputs := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		def := workflow.Outputs[name]
+		value, err := sub.Resolve(def.Value)
+		if err == nil {
+			value, err = convertOutput(value, def.Type)
+		}
+		if err != nil {
+			e.varMu.RUnlock()
+			return fmt.Errorf("output %s: %w", name, err)
+		}
+		outputs[name] = value
+	}
+	e.varMu.RUnlock()
+
+	e.state.Outputs = outputs
+	return nil
+}
+
+// convertOutput coerces an output value to its declared type.
+func convertOutput(value interface{}, typ VarType) (interface{}, error) {
+	switch typ {
+	case "", VarTypeString:
+		return formatValue(value), nil
+	case VarTypeNumber:
+		switch v := value.(type) {
+		case int, int64, float64:
+			return v, nil
+		}
+		s := strings.TrimSpace(formatValue(value))
+		if n, err := strconv.Atoi(s); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", s)
+		}
+		return f, nil
+	case VarTypeBoolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		s := strings.TrimSpace(formatValue(value))
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", s)
+		}
+		return b, nil
+	case VarTypeArray:
+		switch v := value.(type) {
+		case []interface{}:
+			return v, nil
+		case []string:
+			items := make([]interface{}, len(v))
+			for i, s := range v {
+				items[i] = s
+			}
+			return items, nil
+		}
+		s := strings.TrimSpace(formatValue(value))
+		var items []interface{}
+		if err := json.Unmarshal([]byte(s), &items); err == nil {
+			return items, nil
+		}
+		items = []interface{}{}
+		for _, line := range strings.Split(s, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				items = append(items, line)
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unknown output type %q", typ)
+	}
+}
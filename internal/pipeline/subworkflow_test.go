@@ -0,0 +1,266 @@
+//go:build unix
+
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeWorkflow writes a workflow file under <projectDir>/.ntm/workflows.
+func writeWorkflow(t *testing.T, projectDir, name, content string) string {
+	t.Helper()
+	dir := filepath.Join(projectDir, ".ntm", "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const countChild = `
+schema_version: "2.0"
+name: count
+vars:
+  word:
+    required: true
+  times:
+    default: 1
+outputs:
+  upper:
+    value: ${steps.shout.output}
+  words:
+    value: ${steps.shout.output}
+    type: array
+  exit_code:
+    value: ${steps.shout.exit_code}
+    type: number
+steps:
+  - id: shout
+    run: 'echo $((${vars.times} + $(cat runs 2>/dev/null || echo 0))) > runs; echo ${vars.word} | tr a-z A-Z'
+`
+
+func TestSubWorkflow_InputsAndOutputs(t *testing.T) {
+	e := newRunTestExecutor(t)
+	writeWorkflow(t, e.config.ProjectDir, "count.yaml", countChild)
+
+	events := make(chan ProgressEvent, 100)
+	w, err := ParseString(`
+schema_version: "2.0"
+name: parent
+vars:
+  greeting:
+    default: hello
+steps:
+  - id: child
+    uses: count
+    with:
+      word: ${vars.greeting}
+      times: 2
+  - id: after
+    depends_on: [child]
+    when: ${steps.child.outputs.exit_code} == 0
+    run: echo ${steps.child.outputs.upper}
+`, "yaml")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	state, err := e.Run(context.Background(), w, nil, events)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	res := state.Steps["child"]
+	if res.Status != StatusCompleted || res.SubRunID != state.RunID+"-child" {
+		t.Fatalf("child = %+v", res)
+	}
+	data, _ := res.ParsedData.(map[string]interface{})
+	if data["upper"] != "HELLO\n" || data["exit_code"] != 0 {
+		t.Errorf("outputs = %#v", data)
+	}
+	if words, _ := data["words"].([]interface{}); len(words) != 1 || words[0] != "HELLO" {
+		t.Errorf("words = %#v", data["words"])
+	}
+	if got := strings.TrimSpace(state.Steps["after"].Output); got != "HELLO" {
+		t.Errorf("after output = %q", got)
+	}
+
+	runs, _ := os.ReadFile(filepath.Join(e.config.ProjectDir, "runs"))
+	if strings.TrimSpace(string(runs)) != "2" {
+		t.Errorf("with.times not passed as input: runs = %q", runs)
+	}
+
+	child, err := LoadState(e.config.ProjectDir, res.SubRunID)
+	if err != nil {
+		t.Fatalf("child state not persisted: %v", err)
+	}
+	if child.Status != StatusCompleted || child.Outputs["upper"] != "HELLO\n" {
+		t.Errorf("child state = %+v", child)
+	}
+
+	close(events)
+	var nested bool
+	for ev := range events {
+		if ev.StepID == "child/shout" && ev.Depth == 1 {
+			nested = true
+		}
+	}
+	if !nested {
+		t.Error("expected forwarded progress events for child/shout at depth 1")
+	}
+}
+
+func TestSubWorkflow_MissingRequiredInput(t *testing.T) {
+	e := newRunTestExecutor(t)
+	writeWorkflow(t, e.config.ProjectDir, "count.yaml", countChild)
+
+	state, err := runWorkflow(t, e, `
+schema_version: "2.0"
+name: parent
+steps:
+  - id: child
+    uses: count
+`)
+	if err == nil {
+		t.Fatal("expected missing input to fail")
+	}
+	if res := state.Steps["child"]; res.Error == nil || !strings.Contains(res.Error.Message, `requires input "word"`) {
+		t.Errorf("child = %+v", res)
+	}
+}
+
+func TestSubWorkflow_CycleDetection(t *testing.T) {
+	projectDir := t.TempDir()
+	a := writeWorkflow(t, projectDir, "a.yaml", `
+schema_version: "2.0"
+name: a
+steps:
+  - id: call_b
+    uses: b
+`)
+	writeWorkflow(t, projectDir, "b.yaml", `
+schema_version: "2.0"
+name: b
+steps:
+  - id: call_a
+    uses: ./a.yaml
+`)
+
+	w, err := ParseFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewDependencyGraph(w)
+	g.SetWorkflowSource(a, projectDir)
+	errs := g.Validate()
+	if len(errs) != 1 || errs[0].Type != "uses_cycle" {
+		t.Fatalf("errors = %+v, want one uses_cycle", errs)
+	}
+	if !strings.Contains(errs[0].Message, "a.yaml -> b.yaml -> a.yaml") {
+		t.Errorf("message = %q", errs[0].Message)
+	}
+
+	// Without a source the graph doesn't resolve uses: references
+	if errs := NewDependencyGraph(w).Validate(); len(errs) != 0 {
+		t.Errorf("unexpected errors without source: %+v", errs)
+	}
+
+	g = NewDependencyGraph(&Workflow{Steps: []Step{{ID: "x", Uses: "nope"}}})
+	g.SetWorkflowSource("", projectDir)
+	if errs := g.Validate(); len(errs) != 1 || errs[0].Type != "missing_workflow" {
+		t.Errorf("errors = %+v, want missing_workflow", errs)
+	}
+}
+
+func TestSubWorkflow_Builtin(t *testing.T) {
+	w, key, err := ResolveWorkflowRef("test-fix", "", t.TempDir())
+	if err != nil {
+		t.Fatalf("ResolveWorkflowRef: %v", err)
+	}
+	if key != "builtin:test-fix" {
+		t.Errorf("key = %q", key)
+	}
+	if res := Validate(w); !res.Valid {
+		t.Errorf("builtin test-fix is invalid: %+v", res.Errors)
+	}
+
+	e := newRunTestExecutor(t)
+	state, err := runWorkflow(t, e, `
+schema_version: "2.0"
+name: parent
+steps:
+  - id: tests
+    uses: builtin:test-fix
+    with:
+      command: echo all green
+  - id: report
+    depends_on: [tests]
+    run: echo exit=${steps.tests.outputs.exit_code}
+`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := strings.TrimSpace(state.Steps["report"].Output); got != "exit=0" {
+		t.Errorf("report = %q", got)
+	}
+}
+
+func TestSubWorkflow_ResumeAcrossBoundary(t *testing.T) {
+	e := newRunTestExecutor(t)
+	projectDir := e.config.ProjectDir
+	writeWorkflow(t, projectDir, "flaky.yaml", `
+schema_version: "2.0"
+name: flaky
+outputs:
+  result:
+    value: ${steps.second.output}
+steps:
+  - id: first
+    run: echo x >> first.log
+  - id: second
+    depends_on: [first]
+    run: 'test -f ok && echo done'
+`)
+	parent := `
+schema_version: "2.0"
+name: parent
+steps:
+  - id: child
+    uses: flaky
+`
+	state, err := runWorkflow(t, e, parent)
+	if err == nil {
+		t.Fatal("expected the child to fail first time round")
+	}
+	if state.Steps["child"].Error.Type != "uses" {
+		t.Fatalf("child = %+v", state.Steps["child"])
+	}
+
+	if err := os.WriteFile(filepath.Join(projectDir, "ok"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prior, err := LoadState(projectDir, state.RunID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, _ := ParseString(parent, "yaml")
+	state, err = NewExecutor(e.config).Resume(context.Background(), w, prior, nil)
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if data, _ := state.Steps["child"].ParsedData.(map[string]interface{}); data["result"] != "done\n" {
+		t.Errorf("outputs = %#v", state.Steps["child"].ParsedData)
+	}
+
+	// The child resumed rather than restarting: its first step ran once
+	log, _ := os.ReadFile(filepath.Join(projectDir, "first.log"))
+	if strings.Count(string(log), "x") != 1 {
+		t.Errorf("first step ran %d times, want 1", strings.Count(string(log), "x"))
+	}
+}
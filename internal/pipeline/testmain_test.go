@@ -0,0 +1,27 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// Executors without a ProjectDir persist run state under the working
+	// directory, so run the tests from a scratch directory instead of the
+	// package source tree.
+	dir, err := os.MkdirTemp("", "ntm-pipeline-test-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.Chdir(dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/Dicklesworthstone/ntm/internal/tracing"
+)
+
+// startRunSpan starts the span covering a whole run. The returned func ends
+// it with the run's final status, so it must be deferred after e.state is
+// assigned.
+func (e *Executor) startRunSpan(ctx context.Context, name, runID string, workflow *Workflow) (context.Context, func()) {
+	ctx, span := tracing.Start(ctx, name, tracing.WithAttributes(
+		tracing.String("ntm.pipeline.run_id", runID),
+		tracing.String("ntm.pipeline.workflow", workflow.Name),
+		tracing.String("ntm.session", e.config.Session),
+		tracing.Int("ntm.pipeline.steps", len(workflow.Steps)),
+	))
+	if span == nil {
+		return ctx, func() {}
+	}
+	return ctx, func() {
+		if e.state == nil {
+			span.End()
+			return
+		}
+		span.SetAttributes(tracing.String("ntm.pipeline.status", string(e.state.Status)))
+		switch e.state.Status {
+		case StatusCompleted:
+			span.SetStatus(tracing.StatusOK, "")
+		case StatusFailed:
+			msg := "pipeline failed"
+			if n := len(e.state.Errors); n > 0 {
+				msg = e.state.Errors[n-1].Message
+			}
+			span.SetStatus(tracing.StatusError, msg)
+		}
+		span.End()
+	}
+}
+
+// endStepSpan records a step's outcome on its span and ends it.
+func endStepSpan(span *tracing.Span, result StepResult) {
+	if span == nil {
+		return
+	}
+	span.SetAttributes(
+		tracing.String("ntm.pipeline.step.status", string(result.Status)),
+		tracing.Int("ntm.pipeline.step.attempts", result.Attempts),
+	)
+	if result.PaneUsed != "" {
+		span.SetAttributes(tracing.String("ntm.pane", result.PaneUsed))
+	}
+	if result.AgentType != "" {
+		span.SetAttributes(tracing.String("ntm.agent.type", result.AgentType))
+	}
+	if result.Status == StatusFailed && result.Error != nil {
+		span.SetStatus(tracing.StatusError, result.Error.Message)
+	}
+	span.End()
+}
+
+func (e *Executor) runID() string {
+	if e.state == nil {
+		return e.config.RunID
+	}
+	return e.state.RunID
+}
@@ -54,6 +54,13 @@ const escapePlaceholder = "\x00ESC_VAR\x00"
 // Substitute replaces all ${...} variable references in the template string.
 // Returns the substituted string and any errors encountered.
 func (s *Substitutor) Substitute(template string) (string, error) {
+	return s.SubstituteRefs(template, func(_, value string) string { return value })
+}
+
+// SubstituteRefs is Substitute with each resolved reference replaced by
+// ref(varPath, value) rather than the value itself. Run steps use it to hand
+// values to the shell through the environment.
+func (s *Substitutor) SubstituteRefs(template string, ref func(varPath, value string) string) (string, error) {
 	// First, replace escaped \${...} with placeholder to preserve them
 	escaped := escapedPattern.ReplaceAllString(template, escapePlaceholder)
 
@@ -70,7 +77,7 @@ func (s *Substitutor) Substitute(template string) (string, error) {
 		value, err := s.resolveVar(varPath)
 		if err != nil {
 			if hasDefault {
-				return defaultVal
+				return ref(varPath, defaultVal)
 			}
 			if firstErr == nil {
 				firstErr = &SubstitutionError{VarRef: varPath, Message: err.Error()}
@@ -78,7 +85,7 @@ func (s *Substitutor) Substitute(template string) (string, error) {
 			return match // Leave unsubstituted if resolution fails
 		}
 
-		return formatValue(value)
+		return ref(varPath, formatValue(value))
 	})
 
 	// Restore escaped ${...} sequences
@@ -87,6 +94,25 @@ func (s *Substitutor) Substitute(template string) (string, error) {
 	return result, firstErr
 }
 
+// Resolve evaluates a template that may carry a typed value. A template that
+// is exactly one ${...} reference yields the referenced value unchanged (a
+// number, list or map); anything else is substituted as a string.
+func (s *Substitutor) Resolve(template string) (interface{}, error) {
+	trimmed := strings.TrimSpace(template)
+	if loc := varPattern.FindStringIndex(trimmed); loc != nil && loc[0] == 0 && loc[1] == len(trimmed) {
+		varPath, defaultVal, hasDefault := parseDefault(trimmed[2 : len(trimmed)-1])
+		value, err := s.resolveVar(varPath)
+		if err != nil {
+			if hasDefault {
+				return defaultVal, nil
+			}
+			return nil, &SubstitutionError{VarRef: varPath, Message: err.Error()}
+		}
+		return value, nil
+	}
+	return s.Substitute(template)
+}
+
 // SubstituteStrict is like Substitute but returns an error if any variable is undefined.
 func (s *Substitutor) SubstituteStrict(template string) (string, error) {
 	result, err := s.Substitute(template)
@@ -134,6 +160,8 @@ func parseDefault(expr string) (varPath, defaultVal string, hasDefault bool) {
 //   - vars.name, vars.name.nested.field
 //   - steps.id.output, steps.id.data.field
 //   - steps.id.pane, steps.id.duration, steps.id.status, steps.id.agent
+//   - steps.id.exit_code, steps.id.stdout, steps.id.stderr (run steps)
+//   - steps.id.outputs.name (sub-workflow steps)
 //   - env.NAME
 //   - session, timestamp, run_id, workflow
 //   - loop.item, loop.index, loop.count, loop.first, loop.last
@@ -205,6 +233,10 @@ func (s *Substitutor) resolveSteps(parts []string) (interface{}, error) {
 
 	stepID := parts[0]
 	field := parts[1]
+	if field == "outputs" {
+		// Sub-workflow outputs are stored as the step's parsed data
+		field = "data"
+	}
 
 	// First, check Variables for flat key lookup (backward compatible)
 	key := "steps." + stepID + "." + field
@@ -254,6 +286,15 @@ func (s *Substitutor) resolveSteps(parts []string) (interface{}, error) {
 		return string(result.Status), nil
 	case "agent":
 		return result.AgentType, nil
+	case "exit_code":
+		if result.ExitCode == nil {
+			return nil, fmt.Errorf("step %s has no exit code", stepID)
+		}
+		return *result.ExitCode, nil
+	case "stdout":
+		return result.Output, nil
+	case "stderr":
+		return result.Stderr, nil
 	default:
 		return nil, fmt.Errorf("unknown step field: %s", field)
 	}
@@ -636,6 +677,35 @@ func StoreStepOutput(state *ExecutionState, stepID string, output string, parsed
 	}
 }
 
+// stepResultFields are the flat steps.<id>.* keys StoreStepOutput and
+// StoreStepResult may write.
+var stepResultFields = []string{"output", "stdout", "stderr", "status", "data", "exit_code"}
+
+// StoreStepResult stores a finished step's output, status and command result
+// under flat steps.<id>.* keys. Loop bodies use it to expose nested steps by
+// their declared ID, since their results are recorded under per-iteration IDs.
+func StoreStepResult(state *ExecutionState, stepID string, result StepResult) {
+	if state.Variables == nil {
+		state.Variables = make(map[string]interface{})
+	}
+
+	prefix := "steps." + stepID + "."
+	state.Variables[prefix+"output"] = result.Output
+	state.Variables[prefix+"stdout"] = result.Output
+	state.Variables[prefix+"stderr"] = result.Stderr
+	state.Variables[prefix+"status"] = string(result.Status)
+	if result.ParsedData != nil {
+		state.Variables[prefix+"data"] = result.ParsedData
+	} else {
+		delete(state.Variables, prefix+"data")
+	}
+	if result.ExitCode != nil {
+		state.Variables[prefix+"exit_code"] = *result.ExitCode
+	} else {
+		delete(state.Variables, prefix+"exit_code")
+	}
+}
+
 // ValidateVarRefs validates that all variable references in a string are valid.
 // Returns a list of invalid references.
 func ValidateVarRefs(template string, availableVars []string) []string {
@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
@@ -15,14 +16,78 @@ var pluginNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 
 // AgentPlugin defines a custom agent type loaded from config
 type AgentPlugin struct {
-	Name        string            `toml:"name"`
-	Alias       string            `toml:"alias"`
-	Command     string            `toml:"command"`
-	Description string            `toml:"description"`
-	Env         map[string]string `toml:"env"`
-	Defaults    struct {
+	Name           string            `toml:"name"`
+	Alias          string            `toml:"alias"`
+	Command        string            `toml:"command"`
+	RestartCommand string            `toml:"restart_command"` // Relaunch command; defaults to Command
+	Description    string            `toml:"description"`
+	Env            map[string]string `toml:"env"`
+	Defaults       struct {
 		Tags []string `toml:"tags"`
 	} `toml:"defaults"`
+	Detection DetectionConfig `toml:"detection"`
+	Context   ContextConfig   `toml:"context"`
+}
+
+// DetectionConfig declares how to read a plugin agent's state from its pane
+// output. All entries except RateLimit are regular expressions.
+//
+//	[agent.detection]
+//	prompt = ['^llama>\s*$']
+//	thinking = ['(?i)generating']
+//	error = ['(?i)^llama error:']
+//	completion = ['(?i)^answer complete']
+//	rate_limit = ["daily token budget exhausted"]
+type DetectionConfig struct {
+	Prompt     []string `toml:"prompt"`     // Input prompt line shown when idle
+	Idle       []string `toml:"idle"`       // Other idle indicators (status lines, banners)
+	Thinking   []string `toml:"thinking"`   // Working/processing indicators
+	Error      []string `toml:"error"`      // Error output
+	Completion []string `toml:"completion"` // Task finished signals
+	RateLimit  []string `toml:"rate_limit"` // Rate-limit phrases, matched case-insensitively
+}
+
+// HasPatterns reports whether any detection pattern or phrase is declared.
+func (d DetectionConfig) HasPatterns() bool {
+	return len(d.Prompt)+len(d.Idle)+len(d.Thinking)+len(d.Error)+len(d.Completion)+len(d.RateLimit) > 0
+}
+
+// ContextConfig declares a plugin agent's context window and the commands
+// used to compact or clear it.
+//
+//	[agent.context]
+//	window = 32768
+//	compact_command = "/compact"
+//	clear_command = "/reset"
+type ContextConfig struct {
+	Window         int    `toml:"window"` // Context window in tokens
+	CompactCommand string `toml:"compact_command"`
+	ClearCommand   string `toml:"clear_command"`
+}
+
+// Validate checks that every detection pattern compiles.
+func (p AgentPlugin) Validate() error {
+	groups := []struct {
+		key      string
+		patterns []string
+	}{
+		{"prompt", p.Detection.Prompt},
+		{"idle", p.Detection.Idle},
+		{"thinking", p.Detection.Thinking},
+		{"error", p.Detection.Error},
+		{"completion", p.Detection.Completion},
+	}
+	for _, g := range groups {
+		for _, pattern := range g.patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("detection.%s pattern %q: %w", g.key, pattern, err)
+			}
+		}
+	}
+	if p.Context.Window < 0 {
+		return fmt.Errorf("context.window must not be negative")
+	}
+	return nil
 }
 
 type agentConfigFile struct {
@@ -65,6 +130,11 @@ func LoadAgentPlugins(dir string) ([]AgentPlugin, error) {
 				continue
 			}
 
+			if err := cfg.Agent.Validate(); err != nil {
+				log.Printf("plugins: plugin %s: %v, skipping", cfg.Agent.Name, err)
+				continue
+			}
+
 			plugins = append(plugins, cfg.Agent)
 		}
 	}
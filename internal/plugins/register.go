@@ -0,0 +1,127 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	ctxmon "github.com/Dicklesworthstone/ntm/internal/context"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/tokens"
+)
+
+// Priorities for plugin patterns in the robot pattern library. They sit above
+// the built-in patterns of the same category so a plugin's own definitions win.
+const (
+	pluginIdlePriority       = 150
+	pluginThinkingPriority   = 150
+	pluginCompletionPriority = 150
+	pluginErrorPriority      = 210
+	pluginRateLimitPriority  = 220
+)
+
+var (
+	registeredMu sync.Mutex
+	registered   = map[string]bool{}
+)
+
+// Register installs the plugin's state detection patterns, context window and
+// compaction commands into the shared registries, so its panes get the same
+// idle/error/rate-limit detection, context tracking and compaction as the
+// built-in agents. Registering the same plugin twice is a no-op.
+func (p AgentPlugin) Register() error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	if registered[p.Name] {
+		return nil
+	}
+
+	agentType := p.Name
+	robotAgent := strings.ToLower(p.Name)
+	status.RegisterAgentType(agentType)
+	robot.RegisterAgentType(robotAgent)
+
+	var errs []error
+	addRobot := func(kind string, patterns []string, state robot.AgentState, category robot.PatternCategory, priority int) {
+		for i, pattern := range patterns {
+			err := robot.DefaultLibrary.AddPattern(robot.Pattern{
+				Name:        fmt.Sprintf("%s_%s_%d", robotAgent, kind, i+1),
+				RegexStr:    pattern,
+				Agent:       robotAgent,
+				State:       state,
+				Category:    category,
+				Priority:    priority,
+				Description: fmt.Sprintf("%s plugin %s pattern", p.Name, kind),
+			})
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, pattern := range p.Detection.Prompt {
+		if err := status.AddPromptPattern(agentType, pattern, p.Name+" plugin prompt"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	addRobot("prompt", p.Detection.Prompt, robot.StateWaiting, robot.CategoryIdle, pluginIdlePriority)
+	addRobot("idle", p.Detection.Idle, robot.StateWaiting, robot.CategoryIdle, pluginIdlePriority)
+	addRobot("thinking", p.Detection.Thinking, robot.StateThinking, robot.CategoryThinking, pluginThinkingPriority)
+	addRobot("error", p.Detection.Error, robot.StateError, robot.CategoryError, pluginErrorPriority)
+	addRobot("completion", p.Detection.Completion, robot.StateWaiting, robot.CategoryCompletion, pluginCompletionPriority)
+
+	for i, phrase := range p.Detection.RateLimit {
+		if err := ratelimit.AddRateLimitPhrase(phrase, p.Name+" plugin rate limit"); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		err := robot.DefaultLibrary.AddPattern(robot.Pattern{
+			Name:        fmt.Sprintf("%s_rate_limit_%d", robotAgent, i+1),
+			RegexStr:    `(?i)` + regexp.QuoteMeta(phrase),
+			Agent:       robotAgent,
+			State:       robot.StateError,
+			Category:    robot.CategoryError,
+			Priority:    pluginRateLimitPriority,
+			Description: p.Name + " plugin rate limit",
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Pane model lookups fall back to the agent type for plugins, so the
+	// window is keyed by plugin name.
+	if p.Context.Window > 0 {
+		ctxmon.RegisterContextLimit(agentType, int64(p.Context.Window))
+		tokens.RegisterContextLimit(agentType, p.Context.Window)
+	}
+	if p.Context.CompactCommand != "" || p.Context.ClearCommand != "" {
+		ctxmon.RegisterAgentCapabilities(agentType, ctxmon.AgentCapabilities{
+			BuiltinCompactCommand: p.Context.CompactCommand,
+			HistoryClearCommand:   p.Context.ClearCommand,
+		})
+	}
+
+	registered[p.Name] = true
+	return errors.Join(errs...)
+}
+
+// RegisterAgentPlugins registers every plugin, returning the combined errors
+// of any that failed.
+func RegisterAgentPlugins(plugins []AgentPlugin) error {
+	var errs []error
+	for _, p := range plugins {
+		if err := p.Register(); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", p.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
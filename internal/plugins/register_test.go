@@ -0,0 +1,200 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	ctxmon "github.com/Dicklesworthstone/ntm/internal/context"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/tokens"
+)
+
+func TestLoadAgentPlugins_ParsesDetectionAndContext(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	content := `[agent]
+name = "llama"
+command = "llama-cli"
+restart_command = "llama-cli --resume"
+
+[agent.detection]
+prompt = ['^llama>\s*$']
+error = ['^FATAL:']
+rate_limit = ["slow down, friend"]
+
+[agent.context]
+window = 32768
+compact_command = "/compact"
+clear_command = "/reset"
+`
+	if err := os.WriteFile(filepath.Join(dir, "llama.toml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, err := LoadAgentPlugins(dir)
+	if err != nil {
+		t.Fatalf("LoadAgentPlugins failed: %v", err)
+	}
+	if len(plugins) != 1 {
+		t.Fatalf("expected 1 plugin, got %d", len(plugins))
+	}
+	p := plugins[0]
+	if p.RestartCommand != "llama-cli --resume" {
+		t.Errorf("RestartCommand = %q", p.RestartCommand)
+	}
+	if len(p.Detection.Prompt) != 1 || len(p.Detection.Error) != 1 || len(p.Detection.RateLimit) != 1 {
+		t.Errorf("Detection = %+v", p.Detection)
+	}
+	if !p.Detection.HasPatterns() {
+		t.Error("HasPatterns() = false, want true")
+	}
+	if p.Context.Window != 32768 || p.Context.CompactCommand != "/compact" || p.Context.ClearCommand != "/reset" {
+		t.Errorf("Context = %+v", p.Context)
+	}
+}
+
+func TestLoadAgentPlugins_SkipsInvalidDetectionRegex(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	content := `[agent]
+name = "broken"
+command = "broken-cli"
+
+[agent.detection]
+idle = ["([unclosed"]
+`
+	if err := os.WriteFile(filepath.Join(dir, "broken.toml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, err := LoadAgentPlugins(dir)
+	if err != nil {
+		t.Fatalf("LoadAgentPlugins failed: %v", err)
+	}
+	if len(plugins) != 0 {
+		t.Errorf("expected invalid detection regex to be skipped, got %d plugins", len(plugins))
+	}
+}
+
+func TestAgentPluginRegister(t *testing.T) {
+	p := AgentPlugin{
+		Name:    "regtestbot",
+		Command: "regtestbot",
+		Detection: DetectionConfig{
+			Prompt:    []string{`^bot>\s*$`},
+			Thinking:  []string{`pondering\.\.\.`},
+			Error:     []string{`^BOT ERROR`},
+			RateLimit: []string{"the bot is resting"},
+		},
+		Context: ContextConfig{
+			Window:         48000,
+			CompactCommand: "/squash",
+		},
+	}
+	if err := p.Register(); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	// Registering twice must not duplicate patterns.
+	before := robot.DefaultLibrary.PatternCount()
+	if err := p.Register(); err != nil {
+		t.Fatalf("second Register: %v", err)
+	}
+	if after := robot.DefaultLibrary.PatternCount(); after != before {
+		t.Errorf("second Register added patterns: %d -> %d", before, after)
+	}
+
+	if !status.IsKnownAgentType("regtestbot") {
+		t.Error("status does not know the plugin agent type")
+	}
+	if !status.DetectIdleFromOutput("working on it\nbot> ", "regtestbot") {
+		t.Error("plugin prompt not detected as idle")
+	}
+	if got := robot.DetectAgentType("proj__regtestbot_1"); got != "regtestbot" {
+		t.Errorf("DetectAgentType = %q, want regtestbot", got)
+	}
+	if !robot.DefaultLibrary.HasThinkingIndicator("pondering...", "regtestbot") {
+		t.Error("plugin thinking pattern not matched")
+	}
+	if !robot.DefaultLibrary.HasError("BOT ERROR: out of cheese", "regtestbot") {
+		t.Error("plugin error pattern not matched")
+	}
+	if !ratelimit.DetectRateLimit("Sorry, The Bot Is Resting for a while").RateLimited {
+		t.Error("plugin rate limit phrase not detected")
+	}
+
+	caps := ctxmon.GetAgentCapabilities("regtestbot")
+	if !caps.SupportsBuiltinCompact || caps.BuiltinCompactCommand != "/squash" {
+		t.Errorf("capabilities = %+v", caps)
+	}
+	if caps.SupportsHistoryClear {
+		t.Error("SupportsHistoryClear = true without a clear command")
+	}
+	if got := ctxmon.GetContextLimit("regtestbot"); got != 48000 {
+		t.Errorf("context limit = %d, want 48000", got)
+	}
+	if got := tokens.GetContextLimit("regtestbot"); got != 48000 {
+		t.Errorf("tokens context limit = %d, want 48000", got)
+	}
+}
+
+func TestRegisterAgentPlugins_ConcurrentWithContextLookups(t *testing.T) {
+	plugins := make([]AgentPlugin, 8)
+	for i := range plugins {
+		plugins[i] = AgentPlugin{
+			Name:    fmt.Sprintf("racebot%d", i),
+			Command: "racebot",
+			Context: ContextConfig{Window: 1000 * (i + 1)},
+		}
+	}
+
+	// Monitors read context limits while plugins load; with -race this
+	// catches unsynchronized writes to the shared limits.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = ctxmon.GetContextLimit("racebot3")
+				_ = tokens.GetContextLimit("racebot3")
+			}
+		}
+	}()
+	err := RegisterAgentPlugins(plugins)
+	close(done)
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("RegisterAgentPlugins: %v", err)
+	}
+	if got := ctxmon.GetContextLimit("RaceBot3"); got != 4000 {
+		t.Errorf("context limit = %d, want 4000", got)
+	}
+	if got, ok := tokens.PluginContextLimit("racebot3"); !ok || got != 4000 {
+		t.Errorf("tokens plugin limit = %d, %v", got, ok)
+	}
+}
+
+func TestAgentPluginRegister_InvalidPattern(t *testing.T) {
+	p := AgentPlugin{
+		Name:      "badregbot",
+		Command:   "badregbot",
+		Detection: DetectionConfig{Error: []string{"(["}},
+	}
+	if err := p.Register(); err == nil {
+		t.Fatal("expected error for invalid pattern")
+	}
+	if status.IsKnownAgentType("badregbot") {
+		t.Error("invalid plugin was registered")
+	}
+}
@@ -45,6 +45,7 @@ type Policy struct {
 	ApprovalRequired []Rule           `yaml:"approval_required"`
 	Allowed          []Rule           `yaml:"allowed"`
 	Automation       AutomationConfig `yaml:"automation"`
+	ApprovalPrompts  []PromptRule     `yaml:"approval_prompts,omitempty"`
 }
 
 // Match represents a matched policy rule.
@@ -168,6 +169,14 @@ func (p *Policy) compile() error {
 		p.Allowed[i].regex = re
 	}
 
+	for i := range p.ApprovalPrompts {
+		re, err := regexp.Compile(p.ApprovalPrompts[i].Match)
+		if err != nil {
+			return fmt.Errorf("invalid approval_prompts pattern %q: %w", p.ApprovalPrompts[i].Match, err)
+		}
+		p.ApprovalPrompts[i].regex = re
+	}
+
 	return nil
 }
 
@@ -277,6 +286,16 @@ func (p *Policy) Validate() error {
 		return fmt.Errorf("invalid force_release value: %q (must be never, approval, or auto)", p.Automation.ForceRelease)
 	}
 
+	// Validate approval prompt actions
+	for _, rule := range p.ApprovalPrompts {
+		switch rule.Action {
+		case PromptApprove, PromptDeny, PromptEscalate, PromptNotify:
+			// Valid values
+		default:
+			return fmt.Errorf("invalid approval_prompts action %q for %q (must be approve, deny, escalate, or notify)", rule.Action, rule.Match)
+		}
+	}
+
 	// Compile patterns to validate them
 	return p.compile()
 }
@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PromptAction is how ntm responds when an agent stops on a confirmation
+// prompt (tool permission menu, trust-folder dialog, y/n question).
+type PromptAction string
+
+const (
+	PromptApprove  PromptAction = "approve"  // answer the prompt affirmatively
+	PromptDeny     PromptAction = "deny"     // reject the prompt
+	PromptEscalate PromptAction = "escalate" // request approval via the approval engine
+	PromptNotify   PromptAction = "notify"   // leave the prompt for a human, notify only
+)
+
+// PromptRule decides the response to agent confirmation prompts.
+type PromptRule struct {
+	Match  string       `yaml:"match"`           // Regex against the requested action text
+	Agent  string       `yaml:"agent,omitempty"` // Agent type filter (cc, cod, gmi); empty matches all
+	Kind   string       `yaml:"kind,omitempty"`  // Prompt kind filter (command, edit, trust, confirm)
+	Action PromptAction `yaml:"action"`
+	Reason string       `yaml:"reason,omitempty"`
+	regex  *regexp.Regexp
+}
+
+// PromptDecision is the outcome of evaluating a confirmation prompt.
+type PromptDecision struct {
+	Action PromptAction
+	Rule   string // Pattern that decided the outcome (empty for the default)
+	Reason string
+	SLB    bool // Escalations that require SLB two-person approval
+}
+
+// promptAgentAliases maps long agent names to the short pane types.
+var promptAgentAliases = map[string]string{
+	"claude": "cc",
+	"codex":  "cod",
+	"gemini": "gmi",
+}
+
+func normalizePromptAgent(agent string) string {
+	agent = strings.ToLower(strings.TrimSpace(agent))
+	if short, ok := promptAgentAliases[agent]; ok {
+		return short
+	}
+	return agent
+}
+
+// CheckPrompt decides how to answer a confirmation prompt shown by an agent.
+//
+// Command prompts are first checked against the command rules so the
+// existing safety policy still applies: a blocked command is denied and an
+// approval_required command is escalated, whatever approval_prompts say.
+// Then the first matching approval_prompts rule wins. Prompts no rule covers
+// are left for a human (PromptNotify).
+func (p *Policy) CheckPrompt(agentType, kind, action string) *PromptDecision {
+	action = strings.TrimSpace(action)
+
+	if kind == "command" && action != "" {
+		if m := p.Check(action); m != nil {
+			switch m.Action {
+			case ActionBlock:
+				return &PromptDecision{Action: PromptDeny, Rule: m.Pattern, Reason: m.Reason}
+			case ActionApprove:
+				return &PromptDecision{Action: PromptEscalate, Rule: m.Pattern, Reason: m.Reason, SLB: m.SLB}
+			}
+		}
+	}
+
+	agent := normalizePromptAgent(agentType)
+	for _, rule := range p.ApprovalPrompts {
+		if rule.regex == nil {
+			continue
+		}
+		if rule.Agent != "" && normalizePromptAgent(rule.Agent) != agent {
+			continue
+		}
+		if rule.Kind != "" && !strings.EqualFold(rule.Kind, kind) {
+			continue
+		}
+		if rule.regex.MatchString(action) {
+			return &PromptDecision{Action: rule.Action, Rule: rule.Match, Reason: rule.Reason}
+		}
+	}
+
+	return &PromptDecision{Action: PromptNotify, Reason: "no approval_prompts rule matched"}
+}
@@ -0,0 +1,96 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckPrompt(t *testing.T) {
+	p := DefaultPolicy()
+	p.ApprovalPrompts = []PromptRule{
+		{Match: `^go (test|build|vet)\b`, Kind: "command", Action: PromptApprove, Reason: "read-only go tooling"},
+		{Match: `.*`, Agent: "cod", Kind: "edit", Action: PromptApprove},
+		{Match: `.*`, Kind: "trust", Action: PromptDeny, Reason: "never trust new folders"},
+		{Match: `curl`, Action: PromptEscalate},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		agent  string
+		kind   string
+		action string
+		want   PromptAction
+	}{
+		{"approve go test", "cc", "command", "go test ./...", PromptApprove},
+		{"blocked command denied", "cc", "command", "git reset --hard HEAD", PromptDeny},
+		{"approval_required escalates", "cc", "command", "rm -rf build", PromptEscalate},
+		{"agent filter long name", "codex", "edit", "src/main.rs", PromptApprove},
+		{"agent filter mismatch", "cc", "edit", "src/main.rs", PromptNotify},
+		{"trust denied", "gmi", "trust", "", PromptDeny},
+		{"any kind escalate", "cc", "command", "curl https://example.com", PromptEscalate},
+		{"default notify", "cc", "command", "make deploy", PromptNotify},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.CheckPrompt(tt.agent, tt.kind, tt.action)
+			if got == nil {
+				t.Fatal("CheckPrompt returned nil")
+			}
+			if got.Action != tt.want {
+				t.Errorf("CheckPrompt(%q, %q, %q) = %q (rule %q), want %q", tt.agent, tt.kind, tt.action, got.Action, got.Rule, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPrompt_SLBEscalation(t *testing.T) {
+	p := DefaultPolicy()
+	got := p.CheckPrompt("cc", "command", "force_release reservation")
+	if got.Action != PromptEscalate || !got.SLB {
+		t.Errorf("expected SLB escalation, got %+v", got)
+	}
+}
+
+func TestValidate_InvalidPromptRules(t *testing.T) {
+	p := &Policy{ApprovalPrompts: []PromptRule{{Match: `.*`, Action: "maybe"}}}
+	if err := p.Validate(); err == nil {
+		t.Error("expected error for invalid prompt action")
+	}
+
+	p = &Policy{ApprovalPrompts: []PromptRule{{Match: `(`, Action: PromptApprove}}}
+	if err := p.Validate(); err == nil {
+		t.Error("expected error for invalid prompt regex")
+	}
+}
+
+func TestLoad_ApprovalPrompts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yaml")
+	content := `version: 1
+approval_prompts:
+  - match: "^npm (test|run lint)"
+    agent: claude
+    kind: command
+    action: approve
+    reason: safe scripts
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(p.ApprovalPrompts) != 1 {
+		t.Fatalf("expected 1 prompt rule, got %d", len(p.ApprovalPrompts))
+	}
+	if got := p.CheckPrompt("cc", "command", "npm test"); got.Action != PromptApprove {
+		t.Errorf("expected approve, got %+v", got)
+	}
+}
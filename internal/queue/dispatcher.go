@@ -0,0 +1,255 @@
+// Package queue delivers prompts that were queued for a pane once the agent
+// in that pane goes idle, so prompts never land mid-generation.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/history"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tracing"
+)
+
+const (
+	// DefaultPollInterval is how often Run checks queued panes.
+	DefaultPollInterval = 3 * time.Second
+
+	// DefaultSettle is the minimum gap between two deliveries to the same
+	// pane, giving the agent time to pick up a prompt and leave the idle
+	// state before the next one is considered.
+	DefaultSettle = 15 * time.Second
+)
+
+// Sources recorded on queued prompts.
+const (
+	SourceCLI = "cli"
+	SourceAPI = "api"
+)
+
+// DetectFunc returns the current status of every pane in a session.
+type DetectFunc func(ctx context.Context, session string) ([]status.AgentStatus, error)
+
+// SendFunc delivers a prompt to one pane.
+type SendFunc func(session, paneID, prompt string) error
+
+// Dispatcher drains per-pane prompt queues from the state store.
+type Dispatcher struct {
+	store    *state.Store
+	detect   DetectFunc
+	send     SendFunc
+	settle   time.Duration
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewDispatcher creates a dispatcher that detects idle panes with the unified
+// status detector and delivers prompts through the robot send path.
+func NewDispatcher(store *state.Store) *Dispatcher {
+	return &Dispatcher{
+		store:    store,
+		detect:   status.NewDetector().DetectAllContext,
+		send:     sendPrompt,
+		settle:   DefaultSettle,
+		interval: DefaultPollInterval,
+		now:      time.Now,
+	}
+}
+
+// Run dispatches queued prompts until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.Tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick detects pane states for every session with queued prompts (or with
+// delivered prompts whose agent turn is still being traced) and delivers to
+// the idle ones.
+func (d *Dispatcher) Tick(ctx context.Context) {
+	sessions, err := d.store.QueuedSessions()
+	if err != nil {
+		log.Printf("queue: %v", err)
+		return
+	}
+	for _, session := range tracing.OpenTurnSessions() {
+		if !slices.Contains(sessions, session) {
+			sessions = append(sessions, session)
+		}
+	}
+	for _, session := range sessions {
+		statuses, err := d.detect(ctx, session)
+		if err != nil {
+			continue // Session not running; prompts wait for it
+		}
+		if _, err := d.Dispatch(session, statuses); err != nil {
+			log.Printf("queue %s: %v", session, err)
+		}
+	}
+}
+
+// Dispatch delivers the next queued prompt to each idle pane in statuses and
+// returns the prompts it delivered or failed to deliver. Callers that already
+// poll pane status (the dashboard) use this directly.
+func (d *Dispatcher) Dispatch(session string, statuses []status.AgentStatus) ([]state.QueuedPrompt, error) {
+	d.closeTurns(session, statuses)
+
+	depths, err := d.store.QueueDepths(session)
+	if err != nil || len(depths) == 0 {
+		return nil, err
+	}
+
+	var handled []state.QueuedPrompt
+	for _, st := range statuses {
+		if depths[st.PaneID] == 0 || st.State != status.StateIdle {
+			continue
+		}
+		last, err := d.store.LastQueueDelivery(session, st.PaneID)
+		if err != nil {
+			return handled, err
+		}
+		if !last.IsZero() && d.now().Sub(last) < d.settle {
+			continue
+		}
+
+		p, err := d.store.NextQueuedPrompt(session, st.PaneID)
+		if err != nil {
+			return handled, err
+		}
+		if p == nil {
+			continue
+		}
+		claimed, err := d.store.ClaimQueuedPrompt(p.ID)
+		if err != nil {
+			return handled, err
+		}
+		if !claimed {
+			continue // Another dispatcher is delivering it
+		}
+
+		handled = append(handled, d.deliver(*p, st.AgentType))
+	}
+	return handled, nil
+}
+
+// deliver sends a claimed prompt and records the outcome in the queue and
+// the prompt history.
+func (d *Dispatcher) deliver(p state.QueuedPrompt, agentType string) state.QueuedPrompt {
+	start := d.now()
+	sendErr := d.send(p.SessionID, p.PaneID, p.Prompt)
+	deliveredAt := d.now()
+	if sendErr == nil {
+		tracing.OpenTurn(context.Background(), tracing.TurnInfo{
+			Session:   p.SessionID,
+			Pane:      p.PaneID,
+			AgentType: agentType,
+			Prompt:    p.Prompt,
+		})
+	}
+
+	if err := d.store.CompleteQueuedPrompt(p.ID, deliveredAt, sendErr); err != nil {
+		log.Printf("queue %s: %v", p.SessionID, err)
+	}
+
+	entry := history.NewEntry(p.SessionID, []string{strconv.Itoa(p.PaneIndex)}, p.Prompt, history.SourceQueue)
+	entry.Timestamp = deliveredAt.UTC()
+	entry.Template = p.Template
+	entry.DurationMs = int(deliveredAt.Sub(start) / time.Millisecond)
+	queuedAt := p.EnqueuedAt
+	entry.QueuedAt = &queuedAt
+	if sendErr != nil {
+		entry.SetError(sendErr)
+	} else {
+		entry.SetSuccess()
+	}
+	_ = history.Append(entry)
+
+	p.DeliveredAt = &deliveredAt
+	p.Status = state.QueueStatusDelivered
+	if sendErr != nil {
+		p.Status = state.QueueStatusFailed
+		p.Error = sendErr.Error()
+	}
+	return p
+}
+
+// closeTurns ends the traced turn of each pane that is idle again. A pane
+// still inside the settle window may not have picked up its prompt yet, so
+// its turn stays open.
+func (d *Dispatcher) closeTurns(session string, statuses []status.AgentStatus) {
+	if !tracing.Enabled() {
+		return
+	}
+	for _, st := range statuses {
+		if st.State != status.StateIdle {
+			continue
+		}
+		last, err := d.store.LastQueueDelivery(session, st.PaneID)
+		if err != nil || (!last.IsZero() && d.now().Sub(last) < d.settle) {
+			continue
+		}
+		tracing.CloseTurn(session, st.PaneID)
+	}
+}
+
+// Enqueue appends prompt to the queue of each pane.
+func Enqueue(store *state.Store, session string, panes []tmux.Pane, prompt, source, template string) ([]state.QueuedPrompt, error) {
+	if len(panes) == 0 {
+		return nil, errors.New("no target panes to queue for")
+	}
+	queued := make([]state.QueuedPrompt, 0, len(panes))
+	for _, pane := range panes {
+		p := state.QueuedPrompt{
+			SessionID: session,
+			PaneID:    pane.ID,
+			PaneIndex: pane.Index,
+			PaneName:  pane.Title,
+			Prompt:    prompt,
+			Source:    source,
+			Template:  template,
+		}
+		if err := store.EnqueuePrompt(&p); err != nil {
+			return queued, err
+		}
+		queued = append(queued, p)
+	}
+	return queued, nil
+}
+
+func sendPrompt(session, paneID, prompt string) error {
+	out, err := robot.GetSend(robot.SendOptions{
+		Session:     session,
+		Message:     prompt,
+		Panes:       []string{paneID},
+		RequestedBy: "prompt queue",
+	})
+	if err != nil {
+		return err
+	}
+	if !out.Success {
+		return errors.New(out.Error)
+	}
+	if len(out.Failed) > 0 {
+		return fmt.Errorf("pane %s: %s", out.Failed[0].Pane, out.Failed[0].Error)
+	}
+	if len(out.Successful) == 0 {
+		return fmt.Errorf("pane %s not found in session %s", paneID, session)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package queue
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/history"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func testQueueStore(t *testing.T) *state.Store {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return store
+}
+
+type sent struct{ paneID, prompt string }
+
+func testDispatcher(store *state.Store, now *time.Time, sends *[]sent, sendErr error) *Dispatcher {
+	d := NewDispatcher(store)
+	d.now = func() time.Time { return *now }
+	d.send = func(session, paneID, prompt string) error {
+		*sends = append(*sends, sent{paneID, prompt})
+		return sendErr
+	}
+	return d
+}
+
+func TestDispatchDeliversOnlyToIdlePanes(t *testing.T) {
+	store := testQueueStore(t)
+	panes := []tmux.Pane{{ID: "%1", Index: 1, Title: "proj__cc_1"}, {ID: "%2", Index: 2, Title: "proj__cod_1"}}
+	if _, err := Enqueue(store, "proj", panes, "first", SourceCLI, ""); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, err := Enqueue(store, "proj", panes[:1], "second", SourceCLI, "review"); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	now := time.Now()
+	var sends []sent
+	d := testDispatcher(store, &now, &sends, nil)
+
+	statuses := []status.AgentStatus{
+		{PaneID: "%1", State: status.StateIdle},
+		{PaneID: "%2", State: status.StateWorking},
+	}
+	handled, err := d.Dispatch("proj", statuses)
+	if err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if len(handled) != 1 || len(sends) != 1 || sends[0] != (sent{"%1", "first"}) {
+		t.Fatalf("handled=%+v sends=%+v, want only first to %%1", handled, sends)
+	}
+	if handled[0].Status != state.QueueStatusDelivered || handled[0].DeliveredAt == nil {
+		t.Errorf("handled[0] = %+v, want delivered", handled[0])
+	}
+
+	// Still idle, but within the settle window: nothing more is sent.
+	now = now.Add(5 * time.Second)
+	if handled, _ := d.Dispatch("proj", statuses); len(handled) != 0 {
+		t.Fatalf("delivered %d prompts inside settle window", len(handled))
+	}
+
+	now = now.Add(DefaultSettle)
+	statuses[1].State = status.StateIdle
+	if _, err := d.Dispatch("proj", statuses); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if len(sends) != 3 || sends[1] != (sent{"%1", "second"}) || sends[2] != (sent{"%2", "first"}) {
+		t.Fatalf("sends = %+v", sends)
+	}
+
+	depths, err := store.QueueDepths("proj")
+	if err != nil {
+		t.Fatalf("QueueDepths: %v", err)
+	}
+	if len(depths) != 0 {
+		t.Errorf("depths = %v, want empty", depths)
+	}
+
+	entries, err := history.ReadAll()
+	if err != nil {
+		t.Fatalf("history.ReadAll: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("history has %d entries, want 3", len(entries))
+	}
+	for _, e := range entries {
+		if e.Source != history.SourceQueue || e.QueuedAt == nil || !e.Success {
+			t.Errorf("history entry = %+v, want successful queue entry with queued_at", e)
+		}
+	}
+	if entries[1].Template != "review" || entries[1].Targets[0] != "1" {
+		t.Errorf("entries[1] = %+v, want template review to pane 1", entries[1])
+	}
+}
+
+func TestDispatchRecordsFailure(t *testing.T) {
+	store := testQueueStore(t)
+	if _, err := Enqueue(store, "proj", []tmux.Pane{{ID: "%1", Index: 1}}, "hello", SourceAPI, ""); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	now := time.Now()
+	var sends []sent
+	d := testDispatcher(store, &now, &sends, errors.New("pane gone"))
+
+	handled, err := d.Dispatch("proj", []status.AgentStatus{{PaneID: "%1", State: status.StateIdle}})
+	if err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if len(handled) != 1 || handled[0].Status != state.QueueStatusFailed || handled[0].Error != "pane gone" {
+		t.Fatalf("handled = %+v, want one failed prompt", handled)
+	}
+
+	prompts, err := store.ListQueuedPrompts(state.QueueQuery{SessionID: "proj"})
+	if err != nil {
+		t.Fatalf("ListQueuedPrompts: %v", err)
+	}
+	if len(prompts) != 1 || prompts[0].Status != state.QueueStatusFailed {
+		t.Errorf("prompts = %+v, want one failed", prompts)
+	}
+}
+
+func TestEnqueueRequiresPanes(t *testing.T) {
+	store := testQueueStore(t)
+	if _, err := Enqueue(store, "proj", nil, "hello", SourceCLI, ""); err == nil {
+		t.Fatal("expected error for no panes")
+	}
+}
@@ -528,3 +528,14 @@ func DetectRateLimit(output string) RateLimitDetection {
 
     return detection
 }
+
+// AddRateLimitPhrase registers a phrase (matched case-insensitively) that
+// signals a rate limit, for agents whose CLIs word it in their own way. The
+// phrase is added to the status error patterns, so both DetectRateLimit and
+// pane status detection report it as a rate limit.
+func AddRateLimitPhrase(phrase string, description string) error {
+	if phrase == "" {
+		return fmt.Errorf("empty rate limit phrase")
+	}
+	return status.AddErrorPattern(status.ErrorRateLimit, `(?i)`+regexp.QuoteMeta(phrase), description)
+}
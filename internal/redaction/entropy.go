@@ -0,0 +1,133 @@
+package redaction
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// entropyPriority ranks entropy findings below every pattern, so a known
+// category always wins an overlap.
+const entropyPriority = 20
+
+// keywordWindow is how many bytes before a token are searched for a
+// context keyword.
+const keywordWindow = 40
+
+// tokenPattern matches candidate tokens for the entropy detector. '=' is
+// only allowed as base64 padding so "NAME=value" splits into two tokens.
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+/_-]+={0,2}`)
+
+// ShannonEntropy returns the Shannon entropy of s in bits per character.
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	n := float64(len(s))
+	var h float64
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// scanEntropy finds tokens that look random enough to be credentials.
+// Tokens need both letters and digits, so identifiers and numbers are
+// ignored, and must follow one of cfg.Keywords on the same line. Hex
+// strings such as commit SHAs, digests and UUIDs are never flagged.
+func scanEntropy(input string, cfg EntropyConfig) []match {
+	minLen := cfg.MinLength
+	if minLen <= 0 {
+		minLen = DefaultEntropyConfig().MinLength
+	}
+	keywords := make([]string, 0, len(cfg.Keywords))
+	for _, k := range cfg.Keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			keywords = append(keywords, k)
+		}
+	}
+
+	var lowered string
+	if len(keywords) > 0 {
+		lowered = strings.ToLower(input)
+	}
+
+	var out []match
+	for _, loc := range tokenPattern.FindAllStringIndex(input, -1) {
+		start, end := loc[0], loc[1]
+		token := input[start:end]
+		if len(token) < minLen || !hasLetterAndDigit(token) || isHexOrUUID(token) {
+			continue
+		}
+		if ShannonEntropy(token) < cfg.Threshold {
+			continue
+		}
+		if len(keywords) > 0 && !keywordBefore(lowered, start, keywords) {
+			continue
+		}
+		out = append(out, match{
+			category: CategoryHighEntropy,
+			match:    token,
+			start:    start,
+			end:      end,
+			priority: entropyPriority,
+		})
+	}
+	return out
+}
+
+// keywordBefore reports whether a keyword occurs on the same line within
+// keywordWindow bytes before pos.
+func keywordBefore(lowered string, pos int, keywords []string) bool {
+	from := pos - keywordWindow
+	if from < 0 {
+		from = 0
+	}
+	context := lowered[from:pos]
+	if i := strings.LastIndexByte(context, '\n'); i >= 0 {
+		context = context[i+1:]
+	}
+	for _, k := range keywords {
+		if strings.Contains(context, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHexOrUUID reports whether s consists only of hex digits and dashes,
+// like commit SHAs, checksums and UUIDs.
+func isHexOrUUID(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func hasLetterAndDigit(s string) bool {
+	letter, digit := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digit = true
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			letter = true
+		}
+		if letter && digit {
+			return true
+		}
+	}
+	return false
+}
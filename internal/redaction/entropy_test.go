@@ -0,0 +1,175 @@
+package redaction
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// randomToken is a credential-shaped string no built-in pattern knows.
+const randomToken = "q7Vn2Lk9Pw4Rt8Yb3Mz6Hc1J"
+
+func TestShannonEntropy(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"", 0},
+		{"aaaa", 0},
+		{"ab", 1},
+		{"abcd", 2},
+		{"0123456789abcdef", 4},
+	}
+	for _, tt := range tests {
+		if got := ShannonEntropy(tt.in); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ShannonEntropy(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEntropy_DetectsTokenAfterKeyword(t *testing.T) {
+	resetPatternsForTest(t)
+
+	input := "svc_cred " + randomToken + "\n"
+	out, findings := Redact(input, DefaultConfig())
+	if len(findings) != 1 || findings[0].Category != CategoryHighEntropy {
+		t.Fatalf("findings = %+v, want one HIGH_ENTROPY", findings)
+	}
+	if strings.Contains(out, randomToken) {
+		t.Errorf("token not redacted: %q", out)
+	}
+}
+
+func TestEntropy_RequiresKeywordOnSameLine(t *testing.T) {
+	resetPatternsForTest(t)
+
+	cfg := DefaultConfig()
+	for _, input := range []string{
+		"commit " + randomToken,
+		"svc_cred\n" + randomToken,
+	} {
+		if got := Scan(input, cfg); len(got) != 0 {
+			t.Errorf("Scan(%q) = %+v, want no findings", input, got)
+		}
+	}
+
+	// Without keywords any high-entropy token is flagged.
+	cfg.Entropy.Keywords = nil
+	if got := Scan("commit "+randomToken, cfg); len(got) != 1 {
+		t.Errorf("keywordless scan findings = %d, want 1", len(got))
+	}
+}
+
+func TestEntropy_IgnoresLowEntropyAndShortTokens(t *testing.T) {
+	resetPatternsForTest(t)
+
+	cfg := DefaultConfig()
+	for _, input := range []string{
+		"auth aaaaaaaaaa1111111111aaaaaaaaaa",     // low entropy
+		"auth q7Vn2Lk9Pw4R",                       // too short
+		"auth abcdefghijklmnopqrstuvwxyzABCDEFGH", // no digits: identifier
+	} {
+		if got := Scan(input, cfg); len(got) != 0 {
+			t.Errorf("Scan(%q) = %+v, want no findings", input, got)
+		}
+	}
+}
+
+func TestEntropy_IgnoresHashesAndUUIDs(t *testing.T) {
+	resetPatternsForTest(t)
+
+	cfg := DefaultConfig()
+	for _, input := range []string{
+		"session 550e8400-e29b-41d4-a716-446655440000",                 // UUID
+		"access commit 9fceb02d0ae598e95dc970b74767f19372d61af8",       // git SHA-1
+		"auth sha256 e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934c", // digest
+		"token id 3F2504E0-4F89-11D3-9A0C-0305E82C3301",                // upper-case UUID
+	} {
+		if got := Scan(input, cfg); len(got) != 0 {
+			t.Errorf("Scan(%q) = %+v, want no findings", input, got)
+		}
+	}
+}
+
+func TestCompileExtraPatternsCaches(t *testing.T) {
+	extra := map[Category][]string{"INTERNAL": {`acme_[0-9]{6}`, `(`}}
+	first := compileExtraPatterns(extra)
+	second := compileExtraPatterns(extra)
+	if len(first) != 1 || len(second) != 1 {
+		t.Fatalf("compiled %d and %d patterns, want 1 (invalid skipped)", len(first), len(second))
+	}
+	if first[0].regex != second[0].regex {
+		t.Error("extra pattern recompiled on the second scan")
+	}
+}
+
+func TestEntropy_DisabledAndProviderPatternsWin(t *testing.T) {
+	resetPatternsForTest(t)
+
+	cfg := DefaultConfig()
+	cfg.DisabledCategories = []Category{CategoryHighEntropy}
+	if got := Scan("svc_cred "+randomToken, cfg); len(got) != 0 {
+		t.Errorf("disabled category still reported: %+v", got)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Entropy.Enabled = false
+	if got := Scan("svc_cred "+randomToken, cfg); len(got) != 0 {
+		t.Errorf("disabled detector still reported: %+v", got)
+	}
+
+	// A GitHub token is also high-entropy; the provider category must win.
+	gh := "gh" + "p_" + "a1B2c3D4e5F6g7H8i9J0k1L2m3N4o5P6q7R8"
+	got := Scan("token "+gh, DefaultConfig())
+	if len(got) != 1 || got[0].Category != CategoryGitHubToken {
+		t.Errorf("findings = %+v, want one GITHUB_TOKEN", got)
+	}
+}
+
+func TestConfig_ValidateEntropyAndPII(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("default config invalid: %v", err)
+	}
+
+	bad := DefaultConfig()
+	bad.Entropy.Threshold = 9
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for threshold > 8")
+	}
+
+	bad = DefaultConfig()
+	bad.Entropy.MinLength = 4
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for min_length < 8")
+	}
+
+	bad = DefaultConfig()
+	bad.PII = []Category{"SSN"}
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for unknown PII category")
+	}
+}
+
+func TestExtraPatterns_AreDetected(t *testing.T) {
+	resetPatternsForTest(t)
+
+	cfg := Config{
+		Mode: ModeRedact,
+		ExtraPatterns: map[Category][]string{
+			"ACME_TICKET": {`ACME-[0-9]{6}`, `(`}, // invalid patterns are skipped
+		},
+	}
+	out, findings := Redact("see ACME-123456 for details", cfg)
+	if len(findings) != 1 || findings[0].Category != "ACME_TICKET" {
+		t.Fatalf("findings = %+v, want one ACME_TICKET", findings)
+	}
+	if strings.Contains(out, "ACME-123456") {
+		t.Errorf("extra pattern not redacted: %q", out)
+	}
+
+	cfg.DisabledCategories = []Category{"ACME_TICKET"}
+	if got := Scan("see ACME-123456", cfg); len(got) != 0 {
+		t.Errorf("disabled extra category still reported: %+v", got)
+	}
+}
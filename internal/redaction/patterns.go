@@ -127,6 +127,38 @@ func compileAllowlist(allowlist []string) []*regexp.Regexp {
 	return compiled
 }
 
+// extraPatternPriority ranks user-supplied patterns alongside rule pack rules.
+const extraPatternPriority = defaultRulePriority
+
+var (
+	extraPatternMu    sync.Mutex
+	extraPatternCache = make(map[string]*regexp.Regexp) // nil for invalid patterns
+)
+
+// compileExtraPatterns compiles Config.ExtraPatterns, skipping invalid ones
+// like compileAllowlist does. Each pattern is compiled once and cached, since
+// the same config is scanned on every send.
+func compileExtraPatterns(extra map[Category][]string) []pattern {
+	extraPatternMu.Lock()
+	defer extraPatternMu.Unlock()
+
+	var compiled []pattern
+	for cat, pats := range extra {
+		for _, pat := range pats {
+			re, ok := extraPatternCache[pat]
+			if !ok {
+				re, _ = regexp.Compile(pat)
+				extraPatternCache[pat] = re
+			}
+			if re == nil {
+				continue
+			}
+			compiled = append(compiled, pattern{category: cat, regex: re, priority: extraPatternPriority})
+		}
+	}
+	return compiled
+}
+
 // isAllowlisted checks if a match should be ignored.
 func isAllowlisted(match string, allowlist []*regexp.Regexp) bool {
 	for _, re := range allowlist {
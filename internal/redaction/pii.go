@@ -0,0 +1,81 @@
+package redaction
+
+import (
+	"net"
+	"regexp"
+)
+
+// piiPriority ranks PII below provider patterns and credential URLs, so an
+// email inside a database URL is reported as DATABASE_URL.
+const piiPriority = 60
+
+// piiDetector finds one PII category. valid, if set, rejects regex matches
+// that are not real instances (e.g. invalid or loopback IPs).
+type piiDetector struct {
+	category Category
+	regex    *regexp.Regexp
+	valid    func(string) bool
+}
+
+var piiDetectors = []piiDetector{
+	{
+		category: CategoryEmail,
+		regex:    regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+	},
+	{
+		// Requires separators so bare 10-digit numbers (timestamps, IDs)
+		// are not flagged: 555-123-4567, (555) 123-4567, +1 555 123 4567.
+		category: CategoryPhone,
+		regex:    regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{3}\)|\b\d{3})[ .-]?\d{3}[ .-]\d{4}\b`),
+	},
+	{
+		category: CategoryIPAddress,
+		regex:    regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`),
+		valid:    isIdentifyingIP,
+	},
+	{
+		category: CategoryIPAddress,
+		regex:    regexp.MustCompile(`(?:[0-9A-Fa-f]{0,4}:){2,7}[0-9A-Fa-f]{0,4}`),
+		valid:    isIdentifyingIP,
+	},
+}
+
+// isIdentifyingIP reports whether s is an IP address that can identify a
+// host; loopback and unspecified addresses are not.
+func isIdentifyingIP(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && !ip.IsLoopback() && !ip.IsUnspecified()
+}
+
+// scanPII finds matches for the enabled PII categories.
+func scanPII(input string, enabled, disabled []Category) []match {
+	var out []match
+	for _, d := range piiDetectors {
+		if !containsCategory(enabled, d.category) || isCategoryDisabled(d.category, disabled) {
+			continue
+		}
+		for _, loc := range d.regex.FindAllStringIndex(input, -1) {
+			s := input[loc[0]:loc[1]]
+			if d.valid != nil && !d.valid(s) {
+				continue
+			}
+			out = append(out, match{
+				category: d.category,
+				match:    s,
+				start:    loc[0],
+				end:      loc[1],
+				priority: piiPriority,
+			})
+		}
+	}
+	return out
+}
+
+func containsCategory(list []Category, cat Category) bool {
+	for _, c := range list {
+		if c == cat {
+			return true
+		}
+	}
+	return false
+}
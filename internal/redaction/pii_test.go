@@ -0,0 +1,79 @@
+package redaction
+
+import "testing"
+
+func TestPII_OffByDefault(t *testing.T) {
+	resetPatternsForTest(t)
+
+	input := "mail jane.doe@example.com or call 555-123-4567 from 203.0.113.7"
+	if got := Scan(input, DefaultConfig()); len(got) != 0 {
+		t.Errorf("findings = %+v, want none without PII enabled", got)
+	}
+}
+
+func TestPII_Categories(t *testing.T) {
+	resetPatternsForTest(t)
+
+	tests := []struct {
+		name  string
+		input string
+		cat   Category
+		want  []string
+	}{
+		{"email", "contact jane.doe+ntm@mail.example.co.uk today", CategoryEmail, []string{"jane.doe+ntm@mail.example.co.uk"}},
+		{"phone dashed", "call 555-123-4567", CategoryPhone, []string{"555-123-4567"}},
+		{"phone parens", "call (555) 123-4567", CategoryPhone, []string{"(555) 123-4567"}},
+		{"phone intl", "call +1 555 123 4567", CategoryPhone, []string{"+1 555 123 4567"}},
+		{"phone bare digits", "ts 1712345678901", CategoryPhone, nil},
+		{"ipv4", "from 203.0.113.7:8080", CategoryIPAddress, []string{"203.0.113.7"}},
+		{"ipv4 loopback", "listening on 127.0.0.1", CategoryIPAddress, nil},
+		{"ipv4 invalid", "version 1.2.3.400", CategoryIPAddress, nil},
+		{"ipv6", "peer 2001:db8::8a2e:370:7334 up", CategoryIPAddress, []string{"2001:db8::8a2e:370:7334"}},
+		{"ipv6 loopback", "bound ::1", CategoryIPAddress, nil},
+		{"clock", "at 12:30:45", CategoryIPAddress, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Mode: ModeWarn, PII: []Category{tt.cat}}
+			got := Scan(tt.input, cfg)
+			if len(got) != len(tt.want) {
+				t.Fatalf("findings = %+v, want %v", got, tt.want)
+			}
+			for i, f := range got {
+				if f.Category != tt.cat || f.Match != tt.want[i] {
+					t.Errorf("finding %d = %s %q, want %s %q", i, f.Category, f.Match, tt.cat, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPII_EnabledIndependently(t *testing.T) {
+	resetPatternsForTest(t)
+
+	input := "jane@example.com 555-123-4567 203.0.113.7"
+	cfg := Config{Mode: ModeWarn, PII: []Category{CategoryEmail}}
+	got := Scan(input, cfg)
+	if len(got) != 1 || got[0].Category != CategoryEmail {
+		t.Fatalf("findings = %+v, want only EMAIL", got)
+	}
+
+	cfg.PII = PIICategories
+	cfg.DisabledCategories = []Category{CategoryPhone}
+	got = Scan(input, cfg)
+	if len(got) != 2 {
+		t.Fatalf("findings = %+v, want EMAIL and IP_ADDRESS", got)
+	}
+}
+
+func TestPII_DatabaseURLWinsOverEmail(t *testing.T) {
+	resetPatternsForTest(t)
+
+	url := "postgres://" + "admin:s3cretpass@db.example.com:5432/app"
+	cfg := Config{Mode: ModeWarn, PII: PIICategories}
+	got := Scan(url, cfg)
+	if len(got) != 1 || got[0].Category != CategoryDatabaseURL {
+		t.Errorf("findings = %+v, want one DATABASE_URL", got)
+	}
+}
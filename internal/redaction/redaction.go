@@ -30,7 +30,7 @@ func ScanAndRedact(input string, cfg Config) Result {
 	allowlist := compileAllowlist(cfg.Allowlist)
 
 	// Scan for all matches.
-	matches := scan(input, allowlist, cfg.DisabledCategories)
+	matches := scan(input, cfg, allowlist)
 
 	// No findings: return input unchanged.
 	if len(matches) == 0 {
@@ -73,9 +73,14 @@ type match struct {
 	priority int
 }
 
-// scan finds all sensitive content in input.
-func scan(input string, allowlist []*regexp.Regexp, disabled []Category) []match {
+// scan finds all sensitive content in input: built-in and extra patterns,
+// enabled PII categories, high-entropy tokens and rule pack rules.
+func scan(input string, cfg Config, allowlist []*regexp.Regexp) []match {
+	disabled := cfg.DisabledCategories
 	patterns := getPatterns()
+	if len(cfg.ExtraPatterns) > 0 {
+		patterns = append(patterns[:len(patterns):len(patterns)], compileExtraPatterns(cfg.ExtraPatterns)...)
+	}
 	var allMatches []match
 
 	for _, p := range patterns {
@@ -98,6 +103,16 @@ func scan(input string, allowlist []*regexp.Regexp, disabled []Category) []match
 		}
 	}
 
+	if len(cfg.PII) > 0 {
+		allMatches = append(allMatches, scanPII(input, cfg.PII, disabled)...)
+	}
+	if cfg.Entropy.Enabled && !isCategoryDisabled(CategoryHighEntropy, disabled) {
+		allMatches = append(allMatches, scanEntropy(input, cfg.Entropy)...)
+	}
+	if len(cfg.RulePacks) > 0 {
+		allMatches = append(allMatches, scanRulePacks(input, cfg.RulePacks, disabled)...)
+	}
+
 	// Remove overlapping matches, keeping higher priority ones.
 	// This must happen BEFORE allowlist checking so that higher-priority
 	// matches can be allowlisted even when lower-priority patterns match
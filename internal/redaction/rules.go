@@ -0,0 +1,389 @@
+package redaction
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// defaultRulePriority ranks rule pack findings above the generic patterns
+// and below provider-specific keys.
+const defaultRulePriority = 70
+
+// RulePack is a set of detection rules loaded from a TOML file:
+//
+//	id = "acme"
+//	description = "ACME internal credentials"
+//	allowlist = ['acme_test_0+']          # applies to every rule in the pack
+//
+//	[[rules]]
+//	id = "acme-service-token"
+//	category = "ACME_SERVICE_TOKEN"       # default: id upper-cased
+//	regex = '(?i)acme_svc\s*[=:]\s*([a-z0-9]{32})'
+//	secret_group = 1                      # redact only this group (default: whole match)
+//	keywords = ["acme_svc"]               # skip the regex unless one appears
+//	entropy = 3.0                         # minimum entropy of the secret (0 = off)
+//	priority = 70
+//	allowlist = ['0{32}']
+//	examples = ["acme_svc=4f9c2a7e1b3d5f8a0c6e9b2d4f7a1c3e"]
+//	negative_examples = ["acme_svc=00000000000000000000000000000000"]
+type RulePack struct {
+	ID          string   `toml:"id" json:"id"`
+	Description string   `toml:"description" json:"description,omitempty"`
+	Allowlist   []string `toml:"allowlist" json:"allowlist,omitempty"`
+	Rules       []Rule   `toml:"rules" json:"rules"`
+
+	// Path is the file the pack was loaded from.
+	Path string `toml:"-" json:"path,omitempty"`
+
+	compiled []*compiledRule
+}
+
+// Rule is a single detector in a RulePack.
+type Rule struct {
+	ID               string   `toml:"id" json:"id"`
+	Description      string   `toml:"description" json:"description,omitempty"`
+	Category         Category `toml:"category" json:"category"`
+	Regex            string   `toml:"regex" json:"regex"`
+	SecretGroup      int      `toml:"secret_group" json:"secret_group,omitempty"`
+	Keywords         []string `toml:"keywords" json:"keywords,omitempty"`
+	Entropy          float64  `toml:"entropy" json:"entropy,omitempty"`
+	Priority         int      `toml:"priority" json:"priority"`
+	Allowlist        []string `toml:"allowlist" json:"allowlist,omitempty"`
+	Examples         []string `toml:"examples" json:"examples,omitempty"`
+	NegativeExamples []string `toml:"negative_examples" json:"negative_examples,omitempty"`
+}
+
+type compiledRule struct {
+	rule      *Rule
+	regex     *regexp.Regexp
+	keywords  []string // lower-cased
+	allowlist []*regexp.Regexp
+}
+
+// ParseRulePack parses and compiles a rule pack. path is only used in
+// error messages and may be empty.
+func ParseRulePack(data []byte, path string) (*RulePack, error) {
+	var pack RulePack
+	md, err := toml.Decode(string(data), &pack)
+	if err != nil {
+		return nil, fmt.Errorf("parse rule pack %s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("parse rule pack %s: unknown key %q", path, undecoded[0].String())
+	}
+	pack.Path = path
+	if err := pack.compile(); err != nil {
+		return nil, fmt.Errorf("rule pack %s: %w", pack.name(), err)
+	}
+	return &pack, nil
+}
+
+// LoadRulePack reads and compiles the rule pack at path.
+func LoadRulePack(path string) (*RulePack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rule pack: %w", err)
+	}
+	return ParseRulePack(data, path)
+}
+
+func (p *RulePack) name() string {
+	if p.ID != "" {
+		return p.ID
+	}
+	return p.Path
+}
+
+// compile validates the pack, fills in rule defaults and compiles regexes.
+func (p *RulePack) compile() error {
+	if p.ID == "" {
+		return errors.New("missing id")
+	}
+	if len(p.Rules) == 0 {
+		return errors.New("no rules")
+	}
+	packAllow, err := compileStrict(p.Allowlist)
+	if err != nil {
+		return fmt.Errorf("allowlist: %w", err)
+	}
+
+	seen := make(map[string]bool, len(p.Rules))
+	p.compiled = make([]*compiledRule, 0, len(p.Rules))
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.ID == "" {
+			return fmt.Errorf("rule %d: missing id", i+1)
+		}
+		if seen[r.ID] {
+			return fmt.Errorf("rule %s: duplicate id", r.ID)
+		}
+		seen[r.ID] = true
+		if r.Category == "" {
+			r.Category = Category(strings.ToUpper(strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(r.ID)))
+		}
+		if r.Priority == 0 {
+			r.Priority = defaultRulePriority
+		}
+		if r.Entropy < 0 || r.Entropy > 8 {
+			return fmt.Errorf("rule %s: entropy must be between 0 and 8", r.ID)
+		}
+		if r.Regex == "" {
+			return fmt.Errorf("rule %s: missing regex", r.ID)
+		}
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return fmt.Errorf("rule %s: regex: %w", r.ID, err)
+		}
+		if r.SecretGroup < 0 || r.SecretGroup > re.NumSubexp() {
+			return fmt.Errorf("rule %s: secret_group %d out of range (regex has %d groups)", r.ID, r.SecretGroup, re.NumSubexp())
+		}
+		allow, err := compileStrict(r.Allowlist)
+		if err != nil {
+			return fmt.Errorf("rule %s: allowlist: %w", r.ID, err)
+		}
+		keywords := make([]string, 0, len(r.Keywords))
+		for _, k := range r.Keywords {
+			if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+				keywords = append(keywords, k)
+			}
+		}
+		p.compiled = append(p.compiled, &compiledRule{
+			rule:      r,
+			regex:     re,
+			keywords:  keywords,
+			allowlist: append(allow, packAllow...),
+		})
+	}
+	return nil
+}
+
+func compileStrict(patterns []string) ([]*regexp.Regexp, error) {
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pat := range patterns {
+		re, err := regexp.Compile(pat)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// find returns the rule's matches in input. lowered is input in lower case,
+// or "" if the rule has no keywords.
+func (c *compiledRule) find(input, lowered string) []match {
+	if len(c.keywords) > 0 {
+		found := false
+		for _, k := range c.keywords {
+			if strings.Contains(lowered, k) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+
+	var out []match
+	for _, loc := range c.regex.FindAllStringSubmatchIndex(input, -1) {
+		start, end := loc[2*c.rule.SecretGroup], loc[2*c.rule.SecretGroup+1]
+		if start < 0 || start == end {
+			continue
+		}
+		s := input[start:end]
+		if c.rule.Entropy > 0 && ShannonEntropy(s) < c.rule.Entropy {
+			continue
+		}
+		if isAllowlisted(s, c.allowlist) {
+			continue
+		}
+		out = append(out, match{
+			category: c.rule.Category,
+			match:    s,
+			start:    start,
+			end:      end,
+			priority: c.rule.Priority,
+		})
+	}
+	return out
+}
+
+// scanRulePacks finds matches for every rule in packs.
+func scanRulePacks(input string, packs []*RulePack, disabled []Category) []match {
+	var lowered string
+	var out []match
+	for _, p := range packs {
+		for _, c := range p.compiled {
+			if isCategoryDisabled(c.rule.Category, disabled) {
+				continue
+			}
+			if len(c.keywords) > 0 && lowered == "" {
+				lowered = strings.ToLower(input)
+			}
+			out = append(out, c.find(input, lowered)...)
+		}
+	}
+	return out
+}
+
+// Test checks every rule against its examples: each example must be
+// detected by the rule and no negative example may be. It returns one error
+// per failing example. Examples are referred to by 1-based index so the
+// errors never echo secret-shaped strings.
+func (p *RulePack) Test() []error {
+	var errs []error
+	for _, c := range p.compiled {
+		for i, ex := range c.rule.Examples {
+			if len(c.find(ex, strings.ToLower(ex))) == 0 {
+				errs = append(errs, fmt.Errorf("rule %s: example %d not detected", c.rule.ID, i+1))
+			}
+		}
+		for i, ex := range c.rule.NegativeExamples {
+			if len(c.find(ex, strings.ToLower(ex))) > 0 {
+				errs = append(errs, fmt.Errorf("rule %s: negative example %d detected", c.rule.ID, i+1))
+			}
+		}
+	}
+	return errs
+}
+
+// RuleBenchmark is the cost and yield of one rule over a fixture set.
+type RuleBenchmark struct {
+	Pack     string        `json:"pack"`
+	Rule     string        `json:"rule"`
+	Category Category      `json:"category"`
+	Matches  int           `json:"matches"`  // per pass over the fixtures
+	Duration time.Duration `json:"duration"` // total over all iterations
+}
+
+// BenchmarkResult reports how rule packs perform against fixtures.
+type BenchmarkResult struct {
+	Iterations int             `json:"iterations"`
+	Bytes      int             `json:"bytes"` // fixture bytes per pass
+	Rules      []RuleBenchmark `json:"rules"`
+	// ScanDuration is the total time of full scans with cfg plus the packs,
+	// i.e. what callers of ScanAndRedact will see.
+	ScanDuration time.Duration `json:"scan_duration"`
+	Findings     int           `json:"findings"` // full-scan findings per pass
+}
+
+// MBPerSecond returns full-scan throughput in megabytes per second.
+func (r BenchmarkResult) MBPerSecond() float64 {
+	if r.ScanDuration <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) * float64(r.Iterations) / 1e6 / r.ScanDuration.Seconds()
+}
+
+// BenchmarkRulePacks times every rule in packs, and a full scan with cfg
+// plus the packs, over fixtures for the given number of iterations.
+func BenchmarkRulePacks(cfg Config, packs []*RulePack, fixtures []string, iterations int) BenchmarkResult {
+	if iterations < 1 {
+		iterations = 1
+	}
+	res := BenchmarkResult{Iterations: iterations}
+	lowered := make([]string, len(fixtures))
+	for i, f := range fixtures {
+		res.Bytes += len(f)
+		lowered[i] = strings.ToLower(f)
+	}
+
+	for _, p := range packs {
+		for _, c := range p.compiled {
+			rb := RuleBenchmark{Pack: p.ID, Rule: c.rule.ID, Category: c.rule.Category}
+			start := time.Now()
+			for it := 0; it < iterations; it++ {
+				for i, f := range fixtures {
+					n := len(c.find(f, lowered[i]))
+					if it == 0 {
+						rb.Matches += n
+					}
+				}
+			}
+			rb.Duration = time.Since(start)
+			res.Rules = append(res.Rules, rb)
+		}
+	}
+
+	cfg.Mode = ModeWarn
+	cfg.RulePacks = append(cfg.RulePacks[:len(cfg.RulePacks):len(cfg.RulePacks)], packs...)
+	allowlist := compileAllowlist(cfg.Allowlist)
+	start := time.Now()
+	for it := 0; it < iterations; it++ {
+		for _, f := range fixtures {
+			n := len(scan(f, cfg, allowlist))
+			if it == 0 {
+				res.Findings += n
+			}
+		}
+	}
+	res.ScanDuration = time.Since(start)
+	return res
+}
+
+// ruleCacheEntry is a parsed pack and the file state it was parsed from.
+type ruleCacheEntry struct {
+	modTime time.Time
+	size    int64
+	pack    *RulePack
+	err     error
+}
+
+var (
+	ruleCacheMu sync.Mutex
+	ruleCache   = make(map[string]ruleCacheEntry)
+)
+
+// LoadRulePacks loads the rule packs named by paths, which may be glob
+// patterns. Files are re-parsed only when they change, so callers can load
+// on every use. Packs that fail to load are skipped and their errors joined.
+func LoadRulePacks(paths []string) ([]*RulePack, error) {
+	var packs []*RulePack
+	var errs []error
+	for _, pattern := range paths {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule pack pattern %q: %w", pattern, err))
+			continue
+		}
+		if len(files) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			files = []string{pattern} // Report the missing file below
+		}
+		sort.Strings(files)
+		for _, f := range files {
+			pack, err := loadRulePackCached(f)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			packs = append(packs, pack)
+		}
+	}
+	return packs, errors.Join(errs...)
+}
+
+func loadRulePackCached(path string) (*RulePack, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rule pack: %w", err)
+	}
+
+	ruleCacheMu.Lock()
+	defer ruleCacheMu.Unlock()
+	if e, ok := ruleCache[path]; ok && e.modTime.Equal(info.ModTime()) && e.size == info.Size() {
+		return e.pack, e.err
+	}
+	pack, err := LoadRulePack(path)
+	ruleCache[path] = ruleCacheEntry{modTime: info.ModTime(), size: info.Size(), pack: pack, err: err}
+	return pack, err
+}
@@ -0,0 +1,172 @@
+package redaction
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadTestPack(t *testing.T) *RulePack {
+	t.Helper()
+	pack, err := LoadRulePack(filepath.Join("testdata", "rules", "acme.toml"))
+	if err != nil {
+		t.Fatalf("LoadRulePack: %v", err)
+	}
+	return pack
+}
+
+func TestLoadRulePack_Defaults(t *testing.T) {
+	pack := loadTestPack(t)
+
+	if pack.ID != "acme" || len(pack.Rules) != 2 {
+		t.Fatalf("pack = %s with %d rules, want acme with 2", pack.ID, len(pack.Rules))
+	}
+	svc := pack.Rules[0]
+	if svc.Category != "ACME_SERVICE_TOKEN" {
+		t.Errorf("default category = %q, want ACME_SERVICE_TOKEN", svc.Category)
+	}
+	if svc.Priority != defaultRulePriority {
+		t.Errorf("default priority = %d, want %d", svc.Priority, defaultRulePriority)
+	}
+	if pack.Rules[1].Category != "ACME_DEPLOY" || pack.Rules[1].Priority != 90 {
+		t.Errorf("explicit rule = %+v", pack.Rules[1])
+	}
+}
+
+func TestRulePack_TestExamples(t *testing.T) {
+	pack := loadTestPack(t)
+	if errs := pack.Test(); len(errs) != 0 {
+		t.Fatalf("Test() = %v, want no errors", errs)
+	}
+
+	pack.Rules[0].Examples = append(pack.Rules[0].Examples, "nothing to see")
+	pack.Rules[1].NegativeExamples = append(pack.Rules[1].NegativeExamples, "acme_live_Z9y8X7w6V5u4T3s2R1q0P9o8")
+	errs := pack.Test()
+	if len(errs) != 2 {
+		t.Fatalf("Test() = %v, want 2 errors", errs)
+	}
+	for _, e := range errs {
+		if strings.Contains(e.Error(), "acme_live_") {
+			t.Errorf("error echoes example text: %v", e)
+		}
+	}
+}
+
+func TestParseRulePack_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		want string
+	}{
+		{"missing id", "[[rules]]\nid = \"r\"\nregex = 'x'", "missing id"},
+		{"no rules", "id = \"p\"", "no rules"},
+		{"unknown key", "id = \"p\"\nfoo = 1\n[[rules]]\nid = \"r\"\nregex = 'x'", "unknown key"},
+		{"bad regex", "id = \"p\"\n[[rules]]\nid = \"r\"\nregex = '('", "rule r: regex"},
+		{"missing regex", "id = \"p\"\n[[rules]]\nid = \"r\"", "missing regex"},
+		{"duplicate", "id = \"p\"\n[[rules]]\nid = \"r\"\nregex = 'x'\n[[rules]]\nid = \"r\"\nregex = 'y'", "duplicate id"},
+		{"secret group", "id = \"p\"\n[[rules]]\nid = \"r\"\nregex = '(x)'\nsecret_group = 2", "secret_group 2 out of range"},
+		{"entropy", "id = \"p\"\n[[rules]]\nid = \"r\"\nregex = 'x'\nentropy = 9.0", "entropy must be"},
+		{"allowlist", "id = \"p\"\n[[rules]]\nid = \"r\"\nregex = 'x'\nallowlist = ['[']", "rule r: allowlist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRulePack([]byte(tt.toml), "test.toml")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseRulePack() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRulePacks_ScanAndRedact(t *testing.T) {
+	resetPatternsForTest(t)
+
+	cfg := Config{Mode: ModeRedact, RulePacks: []*RulePack{loadTestPack(t)}}
+	secret := "4f9c2a7e1b3d5f8a0c6e9b2d4f7a1c3e"
+	input := "export ACME_SVC=" + secret + "\nacme_test_Q3m9Vx2Lk7Pw4Rt8Yb1Nz6Hc\n"
+
+	out, findings := Redact(input, cfg)
+	if len(findings) != 1 {
+		t.Fatalf("findings = %+v, want 1", findings)
+	}
+	f := findings[0]
+	if f.Category != "ACME_SERVICE_TOKEN" || f.Match != secret {
+		t.Errorf("finding = %s %q, want the secret group only", f.Category, f.Match)
+	}
+	if !strings.Contains(out, "export ACME_SVC=[REDACTED:ACME_SERVICE_TOKEN:") {
+		t.Errorf("output = %q", out)
+	}
+
+	cfg.DisabledCategories = []Category{"ACME_SERVICE_TOKEN"}
+	if got := Scan(input, cfg); len(got) != 0 {
+		t.Errorf("disabled rule still reported: %+v", got)
+	}
+}
+
+func TestLoadRulePacks_GlobsAndCache(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile(filepath.Join("testdata", "rules", "acme.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "acme.toml")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	packs, err := LoadRulePacks([]string{filepath.Join(dir, "*.toml"), filepath.Join(dir, "none-*.toml")})
+	if err != nil || len(packs) != 1 {
+		t.Fatalf("LoadRulePacks = %d packs, %v", len(packs), err)
+	}
+	again, _ := LoadRulePacks([]string{path})
+	if len(again) != 1 || again[0] != packs[0] {
+		t.Error("unchanged pack was re-parsed")
+	}
+
+	// A changed file is re-parsed; a broken one reports an error.
+	if err := os.WriteFile(path, []byte("id = \"broken\""), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRulePacks([]string{path}); err == nil || !strings.Contains(err.Error(), "no rules") {
+		t.Errorf("LoadRulePacks after edit: err = %v", err)
+	}
+
+	if _, err := LoadRulePacks([]string{filepath.Join(dir, "missing.toml")}); err == nil {
+		t.Error("expected error for missing literal path")
+	}
+}
+
+func TestBenchmarkRulePacks(t *testing.T) {
+	resetPatternsForTest(t)
+
+	pack := loadTestPack(t)
+	fixtures := []string{
+		"ACME_SVC=4f9c2a7e1b3d5f8a0c6e9b2d4f7a1c3e",
+		"deploy acme_live_Q3m9Vx2Lk7Pw4Rt8Yb1Nz6Hc and acme_live_Z9y8X7w6V5u4T3s2R1q0P9o8",
+		"plain log line",
+	}
+	res := BenchmarkRulePacks(DefaultConfig(), []*RulePack{pack}, fixtures, 3)
+
+	if res.Iterations != 3 || len(res.Rules) != 2 {
+		t.Fatalf("result = %+v", res)
+	}
+	if res.Rules[0].Matches != 1 || res.Rules[1].Matches != 2 {
+		t.Errorf("rule matches = %d, %d; want 1, 2", res.Rules[0].Matches, res.Rules[1].Matches)
+	}
+	if res.Findings != 3 {
+		t.Errorf("full-scan findings = %d, want 3", res.Findings)
+	}
+	wantBytes := 0
+	for _, f := range fixtures {
+		wantBytes += len(f)
+	}
+	if res.Bytes != wantBytes {
+		t.Errorf("bytes = %d, want %d", res.Bytes, wantBytes)
+	}
+}
@@ -33,8 +33,22 @@ const (
 	CategoryGenericAPIKey Category = "GENERIC_API_KEY"
 	CategoryGenericSecret Category = "GENERIC_SECRET"
 	CategoryBearerToken   Category = "BEARER_TOKEN"
+
+	// CategoryHighEntropy marks unrecognized tokens with high Shannon
+	// entropy next to a credential keyword (see EntropyConfig).
+	CategoryHighEntropy Category = "HIGH_ENTROPY"
+)
+
+// PII categories. They are only detected when listed in Config.PII.
+const (
+	CategoryEmail     Category = "EMAIL"
+	CategoryPhone     Category = "PHONE"
+	CategoryIPAddress Category = "IP_ADDRESS"
 )
 
+// PIICategories lists the personal-data categories that can be enabled.
+var PIICategories = []Category{CategoryEmail, CategoryPhone, CategoryIPAddress}
+
 // Finding represents a single detected secret.
 type Finding struct {
 	// Category is the type of secret detected.
@@ -77,12 +91,50 @@ type Config struct {
 	ExtraPatterns map[Category][]string `json:"extra_patterns,omitempty"`
 	// DisabledCategories lists categories to skip during scanning.
 	DisabledCategories []Category `json:"disabled_categories,omitempty"`
+	// Entropy configures the Shannon-entropy detector.
+	Entropy EntropyConfig `json:"entropy"`
+	// PII lists the personal-data categories to detect (see PIICategories).
+	PII []Category `json:"pii,omitempty"`
+	// RulePacks holds additional detection rules loaded from rule pack files.
+	RulePacks []*RulePack `json:"-"`
+}
+
+// EntropyConfig configures detection of unrecognized high-entropy tokens,
+// such as internal service credentials, that no provider pattern knows.
+type EntropyConfig struct {
+	// Enabled turns the detector on.
+	Enabled bool `json:"enabled"`
+	// Threshold is the minimum Shannon entropy in bits per character.
+	Threshold float64 `json:"threshold"`
+	// MinLength is the minimum token length considered.
+	MinLength int `json:"min_length"`
+	// Keywords must appear shortly before a token on the same line for it
+	// to be flagged (case-insensitive). Empty flags any high-entropy token.
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// DefaultEntropyKeywords are the context keywords used by DefaultEntropyConfig.
+var DefaultEntropyKeywords = []string{
+	"key", "token", "secret", "passw", "pwd", "credential", "cred",
+	"auth", "bearer", "session", "signature", "private", "access",
+}
+
+// DefaultEntropyConfig returns the entropy detector defaults: tokens of at
+// least 20 characters with 4 bits per character after a credential keyword.
+func DefaultEntropyConfig() EntropyConfig {
+	return EntropyConfig{
+		Enabled:   true,
+		Threshold: 4.0,
+		MinLength: 20,
+		Keywords:  DefaultEntropyKeywords,
+	}
 }
 
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() Config {
 	return Config{
-		Mode: ModeWarn,
+		Mode:    ModeWarn,
+		Entropy: DefaultEntropyConfig(),
 	}
 }
 
@@ -94,9 +146,27 @@ func (c *Config) Validate() error {
 	default:
 		return &ConfigError{Field: "mode", Message: "invalid mode: " + string(c.Mode)}
 	}
+	if c.Entropy.Enabled {
+		if c.Entropy.Threshold <= 0 || c.Entropy.Threshold > 8 {
+			return &ConfigError{Field: "entropy.threshold", Message: "must be between 0 and 8 bits per character"}
+		}
+		if c.Entropy.MinLength < 8 {
+			return &ConfigError{Field: "entropy.min_length", Message: "must be at least 8"}
+		}
+	}
+	for _, cat := range c.PII {
+		if !IsPIICategory(cat) {
+			return &ConfigError{Field: "pii", Message: "unknown PII category: " + string(cat)}
+		}
+	}
 	return nil
 }
 
+// IsPIICategory reports whether cat is one of PIICategories.
+func IsPIICategory(cat Category) bool {
+	return containsCategory(PIICategories, cat)
+}
+
 // ConfigError represents a configuration error.
 type ConfigError struct {
 	Field   string
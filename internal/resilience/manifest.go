@@ -22,6 +22,32 @@ type AgentConfig struct {
 	Type      string `json:"type"`
 	Model     string `json:"model"`
 	Command   string `json:"command"`
+	// Cwd is the directory the agent runs in; native sessions are resumed there.
+	Cwd string `json:"cwd,omitempty"`
+	// NativeSessionID is the agent CLI's own session/conversation ID, if known.
+	NativeSessionID string `json:"native_session_id,omitempty"`
+}
+
+// SetNativeSession records the native session ID (and, if known, the working
+// directory) of the agent in paneID. It reports whether the manifest changed.
+func (m *SpawnManifest) SetNativeSession(paneID, sessionID, cwd string) bool {
+	for i := range m.Agents {
+		a := &m.Agents[i]
+		if a.PaneID != paneID {
+			continue
+		}
+		changed := false
+		if sessionID != "" && a.NativeSessionID != sessionID {
+			a.NativeSessionID = sessionID
+			changed = true
+		}
+		if cwd != "" && a.Cwd != cwd {
+			a.Cwd = cwd
+			changed = true
+		}
+		return changed
+	}
+	return false
 }
 
 // ManifestDir returns the directory for storing session manifests
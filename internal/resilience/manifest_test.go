@@ -152,3 +152,23 @@ func TestSaveManifest_EmptyAgents(t *testing.T) {
 		t.Errorf("Agents count = %d, want 0", len(loaded.Agents))
 	}
 }
+
+func TestSpawnManifest_SetNativeSession(t *testing.T) {
+	m := &SpawnManifest{Agents: []AgentConfig{{PaneID: "%1", Type: "cc"}, {PaneID: "%2", Type: "cod"}}}
+
+	if !m.SetNativeSession("%2", "abc", "/work") {
+		t.Fatal("expected first update to change the manifest")
+	}
+	if m.SetNativeSession("%2", "abc", "") {
+		t.Error("expected repeated update to be a no-op")
+	}
+	if m.SetNativeSession("%9", "abc", "/work") {
+		t.Error("expected unknown pane to be ignored")
+	}
+	if a := m.Agents[1]; a.NativeSessionID != "abc" || a.Cwd != "/work" {
+		t.Errorf("agent = %+v", a)
+	}
+	if m.Agents[0].NativeSessionID != "" {
+		t.Error("other agent modified")
+	}
+}
@@ -10,11 +10,13 @@ import (
 
 	"github.com/Dicklesworthstone/ntm/internal/config"
 	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/handoff"
 	"github.com/Dicklesworthstone/ntm/internal/health"
 	"github.com/Dicklesworthstone/ntm/internal/notify"
 	"github.com/Dicklesworthstone/ntm/internal/process"
 	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/transcript"
 )
 
 // Overridable hooks for tests.
@@ -27,8 +29,15 @@ var (
 	checkSessionFn   = health.CheckSession
 	displayMessageFn = tmux.DisplayMessage
 	isChildAliveFn   = process.IsChildAlive
+	nativeExistsFn   = nativeSessionExists
+	findHandoffFn    = findLatestHandoff
+	sendPromptFn     = sendPrompt
 )
 
+// handoffInjectDelay gives a relaunched agent time to start before the
+// handoff is pasted into it.
+var handoffInjectDelay = 10 * time.Second
+
 // AgentState tracks the state of an individual agent for restart purposes
 type AgentState struct {
 	PaneID            string
@@ -37,6 +46,8 @@ type AgentState struct {
 	AgentType         string // cc, cod, gmi
 	Model             string // Model variant (opus, sonnet, etc.)
 	Command           string // Original launch command
+	Cwd               string // Working directory; defaults to the project dir
+	NativeSessionID   string // Agent CLI's own session ID, resumed on restart
 	RestartCount      int
 	LastCrash         time.Time
 	LastRestart       time.Time // When agent was last restarted
@@ -109,6 +120,25 @@ func (m *Monitor) RegisterAgent(paneID string, paneIndex int, shellPID int, agen
 	}
 }
 
+// SetNativeSession records the agent CLI's own session ID and working
+// directory for paneID so a restart resumes the conversation. Empty values
+// leave the current ones unchanged.
+func (m *Monitor) SetNativeSession(paneID, sessionID, cwd string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	agent, ok := m.agents[paneID]
+	if !ok {
+		return
+	}
+	if sessionID != "" {
+		agent.NativeSessionID = sessionID
+	}
+	if cwd != "" {
+		agent.Cwd = cwd
+	}
+}
+
 // ScanAndRegisterAgents discovers agents from existing tmux panes
 func (m *Monitor) ScanAndRegisterAgents() error {
 	panes, err := tmux.GetPanes(m.session)
@@ -660,6 +690,7 @@ func (m *Monitor) restartAgent(ctx context.Context, agent *AgentState) {
 	buildFunc := buildPaneCmdFn
 	sendFunc := sendKeysFn
 	isChildAliveFunc := isChildAliveFn
+	nativeExistsFunc := nativeExistsFn
 	hooksMu.RUnlock()
 
 	select {
@@ -680,11 +711,35 @@ func (m *Monitor) restartAgent(ctx context.Context, agent *AgentState) {
 	currentAgent.RestartCount++
 	// Copy fields while holding lock to avoid race
 	agentCommand := currentAgent.Command
+	agentType := currentAgent.AgentType
+	nativeID := currentAgent.NativeSessionID
+	workDir := currentAgent.Cwd
 	shellPID := currentAgent.ShellPID
 	m.mu.Unlock()
 
+	if workDir == "" {
+		workDir = m.projectDir
+	}
+
+	// Resume the agent's own conversation when its CLI still has it;
+	// otherwise start fresh and hand over the latest handoff instead.
+	launchCmd := agentCommand
+	resumed, nativeLost := false, false
+	if nativeID != "" {
+		if nativeExistsFunc(agentType, workDir, nativeID) {
+			if cmd, err := transcript.ResumeCommand(agentType, agentCommand, nativeID); err != nil {
+				log.Printf("[resilience] Cannot resume native session for %s: %v", agent.PaneID, err)
+			} else {
+				launchCmd, resumed = cmd, true
+			}
+		} else {
+			log.Printf("[resilience] Native session %s for %s is gone; falling back to handoff", nativeID, agent.PaneID)
+			nativeLost = true
+		}
+	}
+
 	// Re-run the agent command in the pane
-	paneCmd, err := buildFunc(m.projectDir, agentCommand)
+	paneCmd, err := buildFunc(workDir, launchCmd)
 	if err != nil {
 		log.Printf("[resilience] Refusing to restart agent %s: %v", agent.PaneID, err)
 		return
@@ -710,8 +765,8 @@ func (m *Monitor) restartAgent(ctx context.Context, agent *AgentState) {
 		return
 	}
 
-	log.Printf("[resilience] Agent %s restarted (attempt %d/%d)",
-		agent.PaneID, currentAgent.RestartCount, m.cfg.Resilience.MaxRestarts)
+	log.Printf("[resilience] Agent %s restarted (attempt %d/%d, resumed native session: %t)",
+		agent.PaneID, currentAgent.RestartCount, m.cfg.Resilience.MaxRestarts, resumed)
 
 	events.DefaultEmitter().Emit(events.NewWebhookEvent(
 		events.WebhookAgentRestarted,
@@ -725,9 +780,14 @@ func (m *Monitor) restartAgent(ctx context.Context, agent *AgentState) {
 			"restart_count": fmt.Sprintf("%d", currentAgent.RestartCount),
 			"max_restarts":  fmt.Sprintf("%d", m.cfg.Resilience.MaxRestarts),
 			"auto_restart":  fmt.Sprintf("%t", m.autoRestart),
+			"resumed":       fmt.Sprintf("%t", resumed),
 		},
 	))
 
+	if nativeLost {
+		m.injectHandoff(ctx, agent.PaneID, agentType)
+	}
+
 	// Send restart notification
 	if m.notifier != nil {
 		event := notify.Event{
@@ -752,6 +812,56 @@ func (m *Monitor) restartAgent(ctx context.Context, agent *AgentState) {
 	if a, ok := m.agents[agent.PaneID]; ok {
 		a.Healthy = true
 		a.LastRestart = time.Now()
+		if nativeLost {
+			a.NativeSessionID = ""
+		}
 	}
 	m.mu.Unlock()
 }
+
+// injectHandoff sends the session's latest handoff to a relaunched agent
+// whose native session could not be resumed.
+func (m *Monitor) injectHandoff(ctx context.Context, paneID, agentType string) {
+	hooksMu.RLock()
+	findFunc := findHandoffFn
+	sendFunc := sendPromptFn
+	hooksMu.RUnlock()
+
+	h, err := findFunc(m.projectDir, m.session)
+	if err != nil || h == nil {
+		log.Printf("[resilience] No handoff to inject into %s", paneID)
+		return
+	}
+
+	select {
+	case <-time.After(handoffInjectDelay):
+	case <-ctx.Done():
+		return
+	}
+	if err := sendFunc(paneID, h.ResumeContext(), agentType); err != nil {
+		log.Printf("[resilience] Failed to inject handoff into %s: %v", paneID, err)
+		return
+	}
+	log.Printf("[resilience] Injected handoff into %s", paneID)
+}
+
+// nativeSessionExists reports whether the agent CLI can still resume
+// sessionID from its transcript directory.
+func nativeSessionExists(agentType, cwd, sessionID string) bool {
+	format, ok := transcript.FormatForAgent(agentType)
+	return ok && transcript.DefaultDirs().SessionExists(format, cwd, sessionID)
+}
+
+func findLatestHandoff(projectDir, session string) (*handoff.Handoff, error) {
+	h, _, err := handoff.NewReader(projectDir).FindLatest(session)
+	return h, err
+}
+
+// sendPrompt pastes a prompt into an agent pane and submits it.
+func sendPrompt(paneID, prompt, agentType string) error {
+	if err := tmux.SendKeysForAgent(paneID, prompt, false, tmux.AgentType(agentType)); err != nil {
+		return err
+	}
+	time.Sleep(500 * time.Millisecond)
+	return tmux.SendKeys(paneID, "", true)
+}
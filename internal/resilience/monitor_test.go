@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/handoff"
 	"github.com/Dicklesworthstone/ntm/internal/health"
 )
 
@@ -21,6 +22,9 @@ func saveHooks() func() {
 	origSleep := sleepFn
 	origCheckSession := checkSessionFn
 	origDisplayMessage := displayMessageFn
+	origNativeExists := nativeExistsFn
+	origFindHandoff := findHandoffFn
+	origSendPrompt := sendPromptFn
 	hooksMu.Unlock()
 
 	return func() {
@@ -30,6 +34,9 @@ func saveHooks() func() {
 		sleepFn = origSleep
 		checkSessionFn = origCheckSession
 		displayMessageFn = origDisplayMessage
+		nativeExistsFn = origNativeExists
+		findHandoffFn = origFindHandoff
+		sendPromptFn = origSendPrompt
 		hooksMu.Unlock()
 	}
 }
@@ -96,6 +103,104 @@ func TestRestartAgentUsesBuiltPaneCommandAndSendKeys(t *testing.T) {
 	}
 }
 
+func TestRestartAgentResumesNativeSession(t *testing.T) {
+	restore := saveHooks()
+	defer restore()
+
+	var mu sync.Mutex
+	var dir, capturedCmd string
+	setHooksLocked(func() {
+		nativeExistsFn = func(agentType, cwd, sessionID string) bool {
+			return sessionID == "sess-123"
+		}
+		buildPaneCmdFn = func(projectDir, agentCmd string) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			dir = projectDir
+			return agentCmd, nil
+		}
+		sendKeysFn = func(paneID, cmd string, enter bool) error {
+			mu.Lock()
+			defer mu.Unlock()
+			capturedCmd = cmd
+			return nil
+		}
+		findHandoffFn = func(projectDir, session string) (*handoff.Handoff, error) {
+			t.Error("handoff looked up for a resumable agent")
+			return nil, nil
+		}
+	})
+
+	cfg := config.Default()
+	cfg.Resilience.RestartDelaySeconds = 0
+	m := NewMonitor("sess", "/tmp/project", cfg, true)
+	m.RegisterAgent("pane-1", 1, 0, "cod", "", "codex --search")
+	m.SetNativeSession("pane-1", "sess-123", "/tmp/project/sub")
+	m.agents["pane-1"].Healthy = false
+
+	m.restartAgent(context.Background(), m.agents["pane-1"])
+
+	mu.Lock()
+	defer mu.Unlock()
+	if capturedCmd != "codex --search resume sess-123" {
+		t.Errorf("restart command = %q", capturedCmd)
+	}
+	if dir != "/tmp/project/sub" {
+		t.Errorf("restart dir = %q, want the agent's cwd", dir)
+	}
+}
+
+func TestRestartAgentFallsBackToHandoff(t *testing.T) {
+	restore := saveHooks()
+	defer restore()
+	origDelay := handoffInjectDelay
+	handoffInjectDelay = 0
+	defer func() { handoffInjectDelay = origDelay }()
+
+	var mu sync.Mutex
+	var capturedCmd, prompt string
+	setHooksLocked(func() {
+		nativeExistsFn = func(agentType, cwd, sessionID string) bool { return false }
+		buildPaneCmdFn = func(projectDir, agentCmd string) (string, error) { return agentCmd, nil }
+		sendKeysFn = func(paneID, cmd string, enter bool) error {
+			mu.Lock()
+			defer mu.Unlock()
+			capturedCmd = cmd
+			return nil
+		}
+		findHandoffFn = func(projectDir, session string) (*handoff.Handoff, error) {
+			return handoff.New(session).WithGoalAndNow("ship the parser", "fix the failing test"), nil
+		}
+		sendPromptFn = func(paneID, text, agentType string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			prompt = text
+			return nil
+		}
+	})
+
+	cfg := config.Default()
+	cfg.Resilience.RestartDelaySeconds = 0
+	m := NewMonitor("sess", "/tmp/project", cfg, true)
+	m.RegisterAgent("pane-1", 1, 0, "cc", "", "claude")
+	m.SetNativeSession("pane-1", "gone-456", "")
+	m.agents["pane-1"].Healthy = false
+
+	m.restartAgent(context.Background(), m.agents["pane-1"])
+
+	mu.Lock()
+	defer mu.Unlock()
+	if capturedCmd != "claude" {
+		t.Errorf("restart command = %q, want the original command", capturedCmd)
+	}
+	if !strings.Contains(prompt, "fix the failing test") {
+		t.Errorf("handoff prompt = %q", prompt)
+	}
+	if id := m.agents["pane-1"].NativeSessionID; id != "" {
+		t.Errorf("native session ID = %q, want cleared", id)
+	}
+}
+
 func TestRegisterAgent(t *testing.T) {
 	cfg := config.Default()
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
@@ -304,12 +409,14 @@ func TestCheckHealthDetectsCrash(t *testing.T) {
 	})
 
 	cfg := config.Default()
+	cfg.Notifications.FileBox.Path = t.TempDir()
 	cfg.Resilience.AutoRestart = true
 	cfg.Resilience.MaxRestarts = 3
 	cfg.Resilience.RestartDelaySeconds = 0
 	cfg.Resilience.CrashThreshold = 1 // Single text-based failure triggers crash (no PID available)
 
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
+	t.Cleanup(m.wg.Wait) // notifications write to the temp dir asynchronously
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
 
 	m.checkHealth(context.Background())
@@ -349,11 +456,13 @@ func TestCheckHealthDetectsPaneMissing(t *testing.T) {
 	})
 
 	cfg := config.Default()
+	cfg.Notifications.FileBox.Path = t.TempDir()
 	cfg.Resilience.AutoRestart = true
 	cfg.Resilience.MaxRestarts = 3
 	cfg.Resilience.RestartDelaySeconds = 0
 
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
+	t.Cleanup(m.wg.Wait) // notifications write to the temp dir asynchronously
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
 
 	m.checkHealth(context.Background())
@@ -590,8 +699,10 @@ func TestHandleCrashMaxRestartsExceeded(t *testing.T) {
 	})
 
 	cfg := config.Default()
+	cfg.Notifications.FileBox.Path = t.TempDir()
 	cfg.Resilience.MaxRestarts = 3
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
+	t.Cleanup(m.wg.Wait) // notifications write to the temp dir asynchronously
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
 
 	// Set restart count at max
@@ -623,9 +734,11 @@ func TestHandleCrashSuggestsManualRespawnWhenAutoRestartDisabled(t *testing.T) {
 	})
 
 	cfg := config.Default()
+	cfg.Notifications.FileBox.Path = t.TempDir()
 	cfg.Resilience.AutoRestart = false
 
 	m := NewMonitor("test-session", "/tmp/project", cfg, false)
+	t.Cleanup(m.wg.Wait) // notifications write to the temp dir asynchronously
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
 
 	m.handleCrash(context.Background(), m.agents["pane-1"], "test crash")
@@ -439,6 +439,9 @@ type AgentActivity struct {
 	LastOutput       time.Time         `json:"last_output,omitempty"`
 	StateHistory     []StateTransition `json:"state_history,omitempty"`
 
+	// PendingApproval describes the confirmation prompt when State is AWAITING_APPROVAL.
+	PendingApproval *status.ApprovalPrompt `json:"pending_approval,omitempty"`
+
 	// Hysteresis tracking - prevents rapid state flapping
 	PendingState AgentState `json:"pending_state,omitempty"`
 	PendingSince time.Time  `json:"pending_since,omitempty"`
@@ -544,6 +547,19 @@ func (sc *StateClassifier) classifyInternal(sample *VelocitySample) (*AgentActiv
 	// Detect patterns in content
 	var detectedPatterns []string
 	matches := sc.patternLibrary.Match(content, sc.agentType)
+
+	// Confirmation prompts are detected on the pane tail only (the library
+	// matches the whole capture, where answered prompts linger in scrollback).
+	prompt := status.DetectApprovalPrompt(content, sc.agentType)
+	if prompt != nil {
+		matches = append([]PatternMatch{{
+			Pattern:  "approval:" + prompt.Pattern,
+			State:    StateAwaitingApproval,
+			Category: CategoryApproval,
+			Priority: 300,
+		}}, matches...)
+	}
+
 	for _, m := range matches {
 		detectedPatterns = append(detectedPatterns, m.Pattern)
 	}
@@ -568,6 +584,9 @@ func (sc *StateClassifier) classifyInternal(sample *VelocitySample) (*AgentActiv
 		StateHistory:     sc.getHistoryCopy(),
 		LastOutput:       sc.velocityTracker.LastOutputTime(),
 	}
+	if finalState == StateAwaitingApproval {
+		activity.PendingApproval = prompt
+	}
 
 	return activity, nil
 }
@@ -575,6 +594,14 @@ func (sc *StateClassifier) classifyInternal(sample *VelocitySample) (*AgentActiv
 // classifyState determines state based on velocity and patterns.
 // Returns state, confidence, and trigger description.
 func (sc *StateClassifier) classifyState(velocity float64, matches []PatternMatch) (AgentState, float64, string) {
+	// A pending confirmation prompt blocks the agent regardless of velocity
+	// or error text further up the pane.
+	for _, m := range matches {
+		if m.Category == CategoryApproval {
+			return StateAwaitingApproval, 0.95, m.Pattern
+		}
+	}
+
 	// Error patterns take priority
 	for _, m := range matches {
 		if m.Category == CategoryError {
@@ -627,16 +654,17 @@ func (sc *StateClassifier) classifyState(velocity float64, matches []PatternMatc
 func (sc *StateClassifier) applyHysteresis(proposed AgentState, confidence float64, trigger string) AgentState {
 	now := time.Now()
 
-	// ERROR state transitions immediately (safety)
-	if proposed == StateError {
-		if sc.currentState != StateError {
-			sc.recordTransition(sc.currentState, StateError, confidence, trigger)
-			sc.currentState = StateError
+	// ERROR and AWAITING_APPROVAL transition immediately: both mean the agent
+	// is stuck until something outside it intervenes.
+	if proposed == StateError || proposed == StateAwaitingApproval {
+		if sc.currentState != proposed {
+			sc.recordTransition(sc.currentState, proposed, confidence, trigger)
+			sc.currentState = proposed
 			sc.stateSince = now
 		}
 		sc.pendingState = ""
 		sc.pendingSince = time.Time{}
-		return StateError
+		return proposed
 	}
 
 	// First classification - transition immediately to establish baseline
@@ -555,6 +555,16 @@ func TestStateClassifier_classifyState(t *testing.T) {
 			wantState:   StateError,
 			wantMinConf: 0.95,
 		},
+		{
+			name:     "approval_beats_error",
+			velocity: 12.0,
+			matches: []PatternMatch{
+				{Pattern: "rate_limit", Category: CategoryError},
+				{Pattern: "approval:claude_proceed", Category: CategoryApproval},
+			},
+			wantState:   StateAwaitingApproval,
+			wantMinConf: 0.95,
+		},
 		{
 			name:        "idle_prompt_low_velocity",
 			velocity:    0.5,
@@ -606,6 +616,22 @@ func TestStateClassifier_classifyState(t *testing.T) {
 	}
 }
 
+func TestStateClassifier_ClassifyWithOutput_AwaitingApproval(t *testing.T) {
+	sc := NewStateClassifier("%1", &ClassifierConfig{AgentType: "codex"})
+
+	output := "Would you like to run the following command?\n\n  $ make release\n\n  1. Yes, proceed\n  2. No\n"
+	activity, err := sc.ClassifyWithOutput(output)
+	if err != nil {
+		t.Fatalf("ClassifyWithOutput: %v", err)
+	}
+	if activity.State != StateAwaitingApproval {
+		t.Fatalf("State = %s, want %s", activity.State, StateAwaitingApproval)
+	}
+	if activity.PendingApproval == nil || activity.PendingApproval.Action != "make release" {
+		t.Errorf("PendingApproval = %+v", activity.PendingApproval)
+	}
+}
+
 func TestStateClassifier_applyHysteresis_ErrorImmediate(t *testing.T) {
 	sc := NewStateClassifier("test", nil)
 
@@ -100,6 +100,14 @@ func buildCommandRegistry() []RobotCommandInfo {
 			},
 			Examples: []string{"ntm --robot-status"},
 		},
+		{
+			Name:        "fleet-status",
+			Flag:        "--robot-fleet-status",
+			Category:    "state",
+			Description: "Get sessions and agents from every ntm serve host in the [fleet] config, grouped by host. Offline hosts are reported with their last known sessions.",
+			Parameters:  []RobotParameter{},
+			Examples:    []string{"ntm --robot-fleet-status"},
+		},
 		{
 			Name:        "context",
 			Flag:        "--robot-context",
@@ -259,6 +267,22 @@ func buildCommandRegistry() []RobotCommandInfo {
 			},
 			Examples: []string{"ntm --robot-activity=myproject --activity-type=claude"},
 		},
+		{
+			Name:        "transitions",
+			Flag:        "--robot-transitions",
+			Category:    "state",
+			Description: "Get the recorded agent state-transition log with optional dwell-time aggregates.",
+			Parameters: []RobotParameter{
+				{Name: "session", Flag: "--robot-transitions", Type: "string", Required: true, Description: "Session name"},
+				{Name: "transitions-pane", Flag: "--transitions-pane", Type: "string", Required: false, Description: "Filter by pane ID or name"},
+				{Name: "transitions-state", Flag: "--transitions-state", Type: "string", Required: false, Description: "Filter by from/to state"},
+				{Name: "transitions-since", Flag: "--transitions-since", Type: "string", Required: false, Description: "Start of range (1h, 30m, or ISO8601)"},
+				{Name: "transitions-until", Flag: "--transitions-until", Type: "string", Required: false, Description: "End of range (1h, 30m, or ISO8601)"},
+				{Name: "transitions-limit", Flag: "--transitions-limit", Type: "int", Required: false, Default: "0", Description: "Return only the most recent N transitions"},
+				{Name: "transitions-stats", Flag: "--transitions-stats", Type: "bool", Required: false, Description: "Include per-pane dwell time per state"},
+			},
+			Examples: []string{"ntm --robot-transitions=myproject --transitions-since=1h --transitions-stats"},
+		},
 		{
 			Name:        "dashboard",
 			Flag:        "--robot-dashboard",
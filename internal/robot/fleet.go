@@ -0,0 +1,52 @@
+package robot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/fleet"
+)
+
+// FleetStatusOutput is the structured output for --robot-fleet-status.
+type FleetStatusOutput struct {
+	RobotResponse
+	fleet.Status
+}
+
+// GetFleetStatus polls every `ntm serve` host in the [fleet] config and
+// returns the merged view. Offline hosts are reported, not treated as errors.
+// This function returns the data struct directly, enabling CLI/REST parity.
+func GetFleetStatus(cfg config.FleetConfig) (*FleetStatusOutput, error) {
+	output := &FleetStatusOutput{
+		RobotResponse: NewRobotResponse(true),
+		Status:        fleet.Status{Hosts: []fleet.HostStatus{}},
+	}
+
+	if len(cfg.Hosts) == 0 {
+		output.RobotResponse = NewErrorResponse(
+			fmt.Errorf("no fleet hosts configured"),
+			ErrCodeInvalidFlag,
+			"Add [[fleet.hosts]] entries with name and url to config.toml",
+		)
+		return output, nil
+	}
+
+	f, err := fleet.NewFromConfig(cfg)
+	if err != nil {
+		output.RobotResponse = NewErrorResponse(err, ErrCodeInvalidFlag, "Check the [fleet] section of config.toml")
+		return output, nil
+	}
+
+	output.Status = *f.Poll(context.Background())
+	return output, nil
+}
+
+// PrintFleetStatus outputs the merged fleet status as JSON.
+func PrintFleetStatus(cfg config.FleetConfig) error {
+	output, err := GetFleetStatus(cfg)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(output)
+}
@@ -0,0 +1,43 @@
+package robot
+
+import (
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+)
+
+func TestGetFleetStatusNoHosts(t *testing.T) {
+	out, err := GetFleetStatus(config.DefaultFleetConfig())
+	if err != nil {
+		t.Fatalf("GetFleetStatus: %v", err)
+	}
+	if out.Success {
+		t.Error("expected success=false without hosts")
+	}
+	if out.ErrorCode != ErrCodeInvalidFlag {
+		t.Errorf("error_code = %q, want %q", out.ErrorCode, ErrCodeInvalidFlag)
+	}
+	if out.Hosts == nil {
+		t.Error("hosts should be an empty array, not null")
+	}
+}
+
+func TestGetFleetStatusReportsOfflineHost(t *testing.T) {
+	cfg := config.DefaultFleetConfig()
+	cfg.TimeoutSeconds = 1
+	cfg.Hosts = []config.FleetHostConfig{{Name: "down", URL: "http://127.0.0.1:1"}}
+
+	out, err := GetFleetStatus(cfg)
+	if err != nil {
+		t.Fatalf("GetFleetStatus: %v", err)
+	}
+	if !out.Success {
+		t.Fatalf("offline hosts should not fail the fleet status: %+v", out.RobotResponse)
+	}
+	if len(out.Hosts) != 1 || out.Hosts[0].Online || out.Hosts[0].Error == "" {
+		t.Errorf("hosts = %+v, want one offline host with an error", out.Hosts)
+	}
+	if out.Summary.Offline != 1 {
+		t.Errorf("summary.offline = %d, want 1", out.Summary.Offline)
+	}
+}
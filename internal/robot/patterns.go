@@ -5,6 +5,7 @@ package robot
 import (
 	"regexp"
 	"sort"
+	"strings"
 	"sync"
 )
 
@@ -27,6 +28,10 @@ const (
 	// StateStalled indicates no output when activity was expected.
 	StateStalled AgentState = "STALLED"
 
+	// StateAwaitingApproval indicates agent is blocked on a confirmation prompt
+	// (tool permission menu, trust-folder dialog, y/n question).
+	StateAwaitingApproval AgentState = "AWAITING_APPROVAL"
+
 	// StateUnknown indicates insufficient signals to classify state.
 	StateUnknown AgentState = "UNKNOWN"
 )
@@ -39,6 +44,7 @@ const (
 	CategoryError      PatternCategory = "error"      // Error conditions
 	CategoryThinking   PatternCategory = "thinking"   // Processing indicators
 	CategoryCompletion PatternCategory = "completion" // Task completion signals
+	CategoryApproval   PatternCategory = "approval"   // Confirmation prompts blocking the agent
 )
 
 // Pattern represents a single pattern for detecting agent states.
@@ -381,3 +387,40 @@ func HasIdlePattern(content string, agentType string) bool {
 func HasThinkingPattern(content string, agentType string) bool {
 	return DefaultLibrary.HasThinkingIndicator(content, agentType)
 }
+
+// customAgentTypes holds plugin agent types registered with RegisterAgentType.
+var (
+	customAgentTypesMu sync.RWMutex
+	customAgentTypes   []string
+)
+
+// RegisterAgentType makes pane-title detection recognize a plugin agent type,
+// so its panes are classified with patterns registered for that agent name
+// instead of being treated as unknown.
+func RegisterAgentType(agentType string) {
+	agentType = strings.ToLower(strings.TrimSpace(agentType))
+	if agentType == "" {
+		return
+	}
+	customAgentTypesMu.Lock()
+	defer customAgentTypesMu.Unlock()
+	for _, t := range customAgentTypes {
+		if t == agentType {
+			return
+		}
+	}
+	customAgentTypes = append(customAgentTypes, agentType)
+}
+
+// customAgentTypeFromTitle returns the registered plugin type named in a
+// lowercased pane title, or "".
+func customAgentTypeFromTitle(titleLower string) string {
+	customAgentTypesMu.RLock()
+	defer customAgentTypesMu.RUnlock()
+	for _, t := range customAgentTypes {
+		if containsShortForm(titleLower, t) {
+			return t
+		}
+	}
+	return ""
+}
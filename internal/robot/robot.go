@@ -5,6 +5,7 @@ package robot
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/Dicklesworthstone/ntm/internal/agentmail"
 	"github.com/Dicklesworthstone/ntm/internal/alerts"
+	"github.com/Dicklesworthstone/ntm/internal/budget"
 	"github.com/Dicklesworthstone/ntm/internal/bv"
 	"github.com/Dicklesworthstone/ntm/internal/cass"
 	"github.com/Dicklesworthstone/ntm/internal/config"
@@ -1538,6 +1540,10 @@ type Agent struct {
 	ContextLimit         int       `json:"context_limit,omitempty"`           // Model context limit
 	ContextPercent       float64   `json:"context_percent,omitempty"`         // Usage percentage (0-100+)
 	ContextModel         string    `json:"context_model,omitempty"`           // Model name for context limit lookup
+
+	// Confirmation prompt the agent is blocked on (tool permission, trust dialog)
+	AwaitingApproval bool                   `json:"awaiting_approval,omitempty"`
+	PendingApproval  *status.ApprovalPrompt `json:"pending_approval,omitempty"`
 }
 
 // SystemInfo contains system and runtime information
@@ -1658,6 +1664,7 @@ type StatusAlert struct {
 	PaneIdx      int     `json:"pane_idx,omitempty"`
 	UsagePercent float64 `json:"usage_percent,omitempty"`
 	ContextModel string  `json:"context_model,omitempty"`
+	Action       string  `json:"action,omitempty"` // Requested action for awaiting_approval alerts
 	Severity     string  `json:"severity,omitempty"`
 }
 
@@ -1702,6 +1709,7 @@ type StatusSummary struct {
 	CursorCount   int `json:"cursor_count"`
 	WindsurfCount int `json:"windsurf_count"`
 	AiderCount    int `json:"aider_count"`
+	AwaitingCount int `json:"awaiting_approval_count"`
 }
 
 // ProgressSummary provides bead completion metrics for status and dashboard (bd-1qct).
@@ -1985,6 +1993,21 @@ func GetStatusWithOptions(opts PaginationOptions) (*StatusOutput, error) {
 					})
 				}
 
+				if agent.AwaitingApproval {
+					alert := StatusAlert{
+						Type:     "awaiting_approval",
+						Session:  sess.Name,
+						Pane:     pane.ID,
+						PaneIdx:  pane.Index,
+						Severity: "warning",
+					}
+					if agent.PendingApproval != nil {
+						alert.Action = agent.PendingApproval.Action
+					}
+					output.Alerts = append(output.Alerts, alert)
+					output.Summary.AwaitingCount++
+				}
+
 				info.Agents = append(info.Agents, agent)
 
 				// Update summary counts
@@ -2932,6 +2955,11 @@ func detectAgentType(title string) string {
 	// Try to detect from pane title
 	titleLower := strings.ToLower(title)
 
+	// Plugin agent types use their own name in the title (session__<name>_1)
+	if custom := customAgentTypeFromTitle(titleLower); custom != "" {
+		return custom
+	}
+
 	// Check canonical forms
 	switch {
 	case strings.Contains(titleLower, "claude"):
@@ -3228,12 +3256,16 @@ func generateTailHints(panes map[string]PaneOutput) *TailAgentHints {
 	}
 }
 
-// determineState analyzes output to determine if agent is active, idle, or in error state.
+// determineState analyzes output to determine if agent is active, idle, awaiting
+// approval, or in error state.
 // It delegates to the status package for consistent detection logic.
 func determineState(output, agentType string) string {
 	// Normalize agent type for status package (expects "cc", "cod", etc.)
 	shortType := translateAgentTypeForStatus(agentType)
 
+	if status.DetectApprovalPrompt(output, shortType) != nil {
+		return "awaiting_approval"
+	}
 	if status.DetectErrorInOutput(output) != status.ErrorNone {
 		return "error"
 	}
@@ -4089,6 +4121,9 @@ type SendOptions struct {
 	DryRun     bool     // If true, show what would be sent without actually sending
 	Enter      *bool    // If set, override Enter behavior after paste
 
+	// RequestedBy names the sender on budget override requests (default "ntm send")
+	RequestedBy string
+
 	// CASS injection options
 	WithCASS     bool          // Enable CASS context injection
 	CASSConfig   *CASSConfig   // CASS query configuration (optional)
@@ -4425,6 +4460,21 @@ func GetSend(opts SendOptions) (*SendOutput, error) {
 		return &output, nil
 	}
 
+	// Refuse new work past an exceeded spend budget
+	requestedBy := opts.RequestedBy
+	if requestedBy == "" {
+		requestedBy = "ntm send"
+	}
+	wd, _ := os.Getwd()
+	if err := budget.GuardSend(context.Background(), budget.LoadConfig(), wd, opts.Session, budget.PaneAgentTypes(targetPanes), requestedBy); err != nil {
+		var blocked *budget.BlockedError
+		if errors.As(err, &blocked) {
+			output.RobotResponse = NewErrorResponse(err, ErrCodeBudgetExceeded, "Approve the pending override with 'ntm approve', or raise the limit with 'ntm budget set'")
+			return &output, nil
+		}
+		output.Warnings = append(output.Warnings, fmt.Sprintf("Warning: budget check failed: %v", err))
+	}
+
 	sendEnter := true
 	if opts.Enter != nil {
 		sendEnter = *opts.Enter
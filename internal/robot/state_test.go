@@ -168,6 +168,13 @@ func TestDetermineState(t *testing.T) {
 			expectedState: "active",
 			description:   "Empty output for non-user agent should default to active state",
 		},
+		{
+			name:          "awaiting_approval_claude",
+			output:        "Bash command\n  rm -rf dist\nDo you want to proceed?\n❯ 1. Yes\n  2. No",
+			agentType:     "claude",
+			expectedState: "awaiting_approval",
+			description:   "Claude permission dialog should be detected as awaiting approval",
+		},
 		{
 			name:          "edge_ansi_codes",
 			output:        "\033[32mGreen text\033[0m\nSome output",
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/process"
+	"github.com/Dicklesworthstone/ntm/internal/status"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 	"github.com/Dicklesworthstone/ntm/internal/tokens"
 )
@@ -80,6 +81,12 @@ func enrichAgentStatus(agent *Agent, sessionName, modelName string) {
 		agent.RateLimitDetected = detected
 		agent.RateLimitMatch = match
 
+		// Confirmation prompt blocking the agent
+		if prompt := status.DetectApprovalPrompt(content, agent.Type); prompt != nil {
+			agent.AwaitingApproval = true
+			agent.PendingApproval = prompt
+		}
+
 		// Output activity
 		lastOutputTS, linesDelta := updateActivity(agent.Pane, content)
 		agent.LastOutputTS = lastOutputTS
@@ -0,0 +1,140 @@
+package robot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+// TransitionsOptions configures state transition queries
+type TransitionsOptions struct {
+	Session string // tmux session name
+	Pane    string // filter by pane ID or name
+	State   string // filter by from/to state
+	Since   string // start of range (e.g., "1h", "30m", "2025-12-15")
+	Until   string // end of range, same formats as Since
+	Limit   int    // most recent N transitions
+	Stats   bool   // include dwell-time aggregates
+
+	// Store overrides the default state store (for tests).
+	Store *state.Store
+}
+
+// TransitionsOutput is the structured output for --robot-transitions
+type TransitionsOutput struct {
+	RobotResponse
+	Session     string                       `json:"session"`
+	GeneratedAt time.Time                    `json:"generated_at"`
+	Since       *time.Time                   `json:"since,omitempty"`
+	Until       *time.Time                   `json:"until,omitempty"`
+	Transitions []state.AgentStateTransition `json:"transitions"`
+	Count       int                          `json:"count"`
+	Stats       *state.TransitionStats       `json:"stats,omitempty"`
+}
+
+// GetTransitions returns recorded agent state transitions as structured output.
+// This function returns the data struct directly, enabling CLI/REST parity.
+func GetTransitions(opts TransitionsOptions) (*TransitionsOutput, error) {
+	output := &TransitionsOutput{
+		RobotResponse: NewRobotResponse(true),
+		Session:       opts.Session,
+		GeneratedAt:   time.Now().UTC(),
+		Transitions:   []state.AgentStateTransition{},
+	}
+
+	if opts.Session == "" {
+		output.RobotResponse = NewErrorResponse(
+			fmt.Errorf("session name is required"),
+			ErrCodeInvalidFlag,
+			"Provide session name: ntm --robot-transitions=myproject",
+		)
+		return output, nil
+	}
+
+	q := state.TransitionQuery{
+		SessionID: opts.Session,
+		Pane:      opts.Pane,
+		State:     opts.State,
+		Limit:     opts.Limit,
+	}
+	if opts.Since != "" {
+		since, err := parseSinceTime(opts.Since)
+		if err != nil {
+			output.RobotResponse = NewErrorResponse(
+				fmt.Errorf("invalid --transitions-since value: %w", err),
+				ErrCodeInvalidFlag,
+				"Use duration (1h, 30m, 2d) or ISO8601 date",
+			)
+			return output, nil
+		}
+		q.Since = since
+		output.Since = &since
+	}
+	if opts.Until != "" {
+		until, err := parseSinceTime(opts.Until)
+		if err != nil {
+			output.RobotResponse = NewErrorResponse(
+				fmt.Errorf("invalid --transitions-until value: %w", err),
+				ErrCodeInvalidFlag,
+				"Use duration (1h, 30m, 2d) or ISO8601 date",
+			)
+			return output, nil
+		}
+		q.Until = until
+		output.Until = &until
+	}
+
+	store := opts.Store
+	if store == nil {
+		var err error
+		store, err = state.Open("")
+		if err != nil {
+			output.RobotResponse = NewErrorResponse(
+				fmt.Errorf("failed to open state store: %w", err),
+				ErrCodeInternalError,
+				"Transitions are recorded by the dashboard, ntm serve and ntm activity --watch",
+			)
+			return output, nil
+		}
+		defer store.Close()
+		if err := store.Migrate(); err != nil {
+			output.RobotResponse = NewErrorResponse(
+				fmt.Errorf("failed to migrate state store: %w", err),
+				ErrCodeInternalError,
+				"",
+			)
+			return output, nil
+		}
+	}
+
+	transitions, err := store.ListStateTransitions(q)
+	if err != nil {
+		output.RobotResponse = NewErrorResponse(err, ErrCodeInternalError, "")
+		return output, nil
+	}
+	if transitions != nil {
+		output.Transitions = transitions
+	}
+	output.Count = len(output.Transitions)
+
+	if opts.Stats {
+		stats, err := store.StateTransitionStats(q)
+		if err != nil {
+			output.RobotResponse = NewErrorResponse(err, ErrCodeInternalError, "")
+			return output, nil
+		}
+		output.Stats = stats
+	}
+
+	return output, nil
+}
+
+// PrintTransitions outputs state transitions as JSON.
+func PrintTransitions(opts TransitionsOptions) error {
+	output, err := GetTransitions(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(output)
+}
@@ -0,0 +1,61 @@
+package robot
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+func TestGetTransitions(t *testing.T) {
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("state.Open: %v", err)
+	}
+	defer store.Close()
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	now := time.Now().UTC()
+	for _, tr := range []state.AgentStateTransition{
+		{SessionID: "proj", PaneID: "%1", ToState: "idle", TransitionAt: now.Add(-2 * time.Hour)},
+		{SessionID: "proj", PaneID: "%1", FromState: "idle", ToState: "working", TransitionAt: now.Add(-20 * time.Minute)},
+		{SessionID: "proj", PaneID: "%2", ToState: "error", TransitionAt: now.Add(-10 * time.Minute)},
+	} {
+		tr := tr
+		if err := store.RecordStateTransition(&tr); err != nil {
+			t.Fatalf("RecordStateTransition: %v", err)
+		}
+	}
+
+	out, err := GetTransitions(TransitionsOptions{Session: "proj", Since: "1h", Stats: true, Store: store})
+	if err != nil {
+		t.Fatalf("GetTransitions: %v", err)
+	}
+	if !out.Success {
+		t.Fatalf("unexpected error response: %+v", out.RobotResponse)
+	}
+	if out.Count != 2 {
+		t.Errorf("Count = %d, want 2", out.Count)
+	}
+	if out.Stats == nil || len(out.Stats.Dwell) == 0 {
+		t.Errorf("expected dwell stats, got %+v", out.Stats)
+	}
+
+	out, _ = GetTransitions(TransitionsOptions{Session: "proj", Pane: "%2", Store: store})
+	if out.Count != 1 || out.Transitions[0].ToState != "error" {
+		t.Errorf("pane filter: got %+v", out.Transitions)
+	}
+
+	out, _ = GetTransitions(TransitionsOptions{Session: "proj", Since: "yesterday-ish", Store: store})
+	if out.Success || out.ErrorCode != ErrCodeInvalidFlag {
+		t.Errorf("invalid since should fail with %s, got %+v", ErrCodeInvalidFlag, out.RobotResponse)
+	}
+
+	out, _ = GetTransitions(TransitionsOptions{})
+	if out.Success {
+		t.Error("missing session should fail")
+	}
+}
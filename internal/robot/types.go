@@ -130,6 +130,9 @@ const (
 
 	// ErrCodePromptSendFailed indicates failed to send prompt.
 	ErrCodePromptSendFailed = "PROMPT_SEND_FAILED"
+
+	// ErrCodeBudgetExceeded indicates a spend budget refused the send.
+	ErrCodeBudgetExceeded = "BUDGET_EXCEEDED"
 )
 
 // ResponseMeta provides optional metadata about response generation.
@@ -9,6 +9,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tracing"
 )
 
 // Scheduler is the global spawn scheduler that serializes and paces
@@ -706,7 +708,18 @@ func (s *Scheduler) executeJob(workerID int, job *SpawnJob) {
 	executor := s.executor
 	s.mu.RUnlock()
 
-	err := executor(job.Context(), job)
+	ctx, span := tracing.Start(job.Context(), "scheduler.spawn", tracing.WithAttributes(
+		tracing.String("ntm.job.id", job.ID),
+		tracing.String("ntm.job.type", string(job.Type)),
+		tracing.String("ntm.session", job.SessionName),
+		tracing.String("ntm.agent.type", job.AgentType),
+		tracing.Int("ntm.pane_index", job.PaneIndex),
+		tracing.Int("ntm.job.retry", job.RetryCount),
+		tracing.Int64("ntm.job.queue_wait_ms", time.Since(job.CreatedAt).Milliseconds()),
+	))
+	err := executor(ctx, job)
+	span.SetError(err)
+	span.End()
 
 	s.mu.Lock()
 	delete(s.running, job.ID)
@@ -10,7 +10,6 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -165,6 +164,8 @@ func (s *Server) registerCheckpointRoutes(r chi.Router) {
 			r.With(s.RequirePermission(PermWriteSessions)).Post("/restore", s.handleRestoreCheckpoint)
 			// Verify checkpoint integrity
 			r.With(s.RequirePermission(PermReadSessions)).Get("/verify", s.handleVerifyCheckpoint)
+			// Compare with another checkpoint
+			r.With(s.RequirePermission(PermReadSessions)).Get("/diff/{otherId}", s.handleDiffCheckpoints)
 			// Export checkpoint to archive
 			r.With(s.RequirePermission(PermReadSessions)).Get("/export", s.handleExportCheckpoint)
 			r.With(s.RequirePermission(PermReadSessions)).Post("/export", s.handleExportCheckpoint)
@@ -402,6 +403,68 @@ func (s *Server) handleVerifyCheckpoint(w http.ResponseWriter, r *http.Request)
 	}, reqID)
 }
 
+// handleDiffCheckpoints reports what changed between two checkpoints. Both
+// accept the same references as handleGetCheckpoint. With format=text or
+// format=markdown the rendering is included alongside the structured diff;
+// lines caps the output lines it shows per section (default 20, 0 = all).
+func (s *Server) handleDiffCheckpoints(w http.ResponseWriter, r *http.Request) {
+	sessionName := chi.URLParam(r, "sessionName")
+	fromRef := chi.URLParam(r, "checkpointId")
+	toRef := chi.URLParam(r, "otherId")
+	reqID := requestIDFromContext(r.Context())
+
+	format := r.URL.Query().Get("format")
+	maxLines := 20
+	if v := r.URL.Query().Get("lines"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest,
+				"lines must be a non-negative integer", nil, reqID)
+			return
+		}
+		maxLines = n
+	}
+
+	storage := checkpoint.NewStorage()
+	capturer := checkpoint.NewCapturerWithStorage(storage)
+	from, err := capturer.ParseCheckpointRef(sessionName, fromRef)
+	if err != nil {
+		writeErrorResponse(w, http.StatusNotFound, ErrCodeNotFound,
+			fmt.Sprintf("checkpoint not found: %s", fromRef), nil, reqID)
+		return
+	}
+	to, err := capturer.ParseCheckpointRef(sessionName, toRef)
+	if err != nil {
+		writeErrorResponse(w, http.StatusNotFound, ErrCodeNotFound,
+			fmt.Sprintf("checkpoint not found: %s", toRef), nil, reqID)
+		return
+	}
+
+	diff, err := checkpoint.DiffCheckpoints(storage, from, to)
+	if err != nil {
+		log.Printf("REST: checkpoint diff failed session=%s from=%s to=%s error=%v request_id=%s",
+			sessionName, from.ID, to.ID, err, reqID)
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError,
+			"failed to compare checkpoints", nil, reqID)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"diff":  diff,
+		"empty": diff.Empty(),
+	}
+	if format != "" && format != checkpoint.DiffFormatJSON {
+		rendered, err := diff.Render(format, maxLines)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+			return
+		}
+		resp["format"] = format
+		resp["rendered"] = rendered
+	}
+	writeSuccessResponse(w, http.StatusOK, resp, reqID)
+}
+
 // handleExportCheckpoint exports a checkpoint to an archive.
 func (s *Server) handleExportCheckpoint(w http.ResponseWriter, r *http.Request) {
 	sessionName := chi.URLParam(r, "sessionName")
@@ -698,8 +761,11 @@ func (s *Server) handleRollback(w http.ResponseWriter, r *http.Request) {
 
 		// Apply git patch if available
 		if cp.HasGitPatch() {
-			patchPath := filepath.Join(storage.CheckpointDir(sessionName, cp.ID), cp.Git.PatchFile)
-			if err := gitApplyPatch(workDir, patchPath); err != nil {
+			patch, err := storage.ReadGitPatch(cp)
+			if err == nil && patch != "" {
+				err = gitApplyPatch(workDir, patch)
+			}
+			if err != nil {
 				resp.Warnings = append(resp.Warnings, fmt.Sprintf("patch apply failed: %v", err))
 			}
 		}
@@ -816,10 +882,14 @@ func gitCheckout(workDir, ref string) error {
 	return err
 }
 
-// gitApplyPatch applies a git patch file.
-func gitApplyPatch(workDir, patchPath string) error {
-	_, err := runGit(workDir, "apply", patchPath)
-	return err
+// gitApplyPatch applies a git patch.
+func gitApplyPatch(workDir, patch string) error {
+	cmd := exec.Command("git", "-C", workDir, "apply", "-")
+	cmd.Stdin = strings.NewReader(patch)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git apply: %w", err)
+	}
+	return nil
 }
 
 // runGit runs a git command and returns the output.
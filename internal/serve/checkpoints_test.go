@@ -1,9 +1,16 @@
 package serve
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/Dicklesworthstone/ntm/internal/checkpoint"
 )
 
@@ -237,3 +244,58 @@ func TestCheckpointToResponse(t *testing.T) {
 		}
 	})
 }
+
+func TestHandleDiffCheckpoints(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	storage := checkpoint.NewStorage()
+	for i, branch := range []string{"main", "feature"} {
+		cp := &checkpoint.Checkpoint{
+			Version:     checkpoint.CurrentVersion,
+			ID:          []string{"20260501-100000-a", "20260501-110000-b"}[i],
+			SessionName: "proj",
+			CreatedAt:   time.Date(2026, 5, 1, 10+i, 0, 0, 0, time.UTC),
+			Git:         checkpoint.GitState{Branch: branch, Commit: "abc"},
+		}
+		if err := storage.Save(cp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := New(Config{})
+
+	get := func(from, to, query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions/proj/checkpoints/"+from+"/diff/"+to+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("sessionName", "proj")
+		rctx.URLParams.Add("checkpointId", from)
+		rctx.URLParams.Add("otherId", to)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		srv.handleDiffCheckpoints(rec, req)
+		return rec
+	}
+
+	rec := get("~2", "last", "?format=markdown")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Diff     checkpoint.Diff `json:"diff"`
+		Rendered string          `json:"rendered"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Diff.Git == nil || resp.Diff.Git.ToBranch != "feature" {
+		t.Errorf("diff = %+v", resp.Diff)
+	}
+	if !strings.Contains(resp.Rendered, "branch: main → feature") {
+		t.Errorf("rendered = %q", resp.Rendered)
+	}
+
+	if rec := get("last", "nope", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("missing checkpoint status = %d", rec.Code)
+	}
+	if rec := get("~2", "last", "?format=html"); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad format status = %d", rec.Code)
+	}
+}
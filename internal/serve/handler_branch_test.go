@@ -29,6 +29,7 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/redaction"
 	"github.com/Dicklesworthstone/ntm/internal/robot"
 	"github.com/Dicklesworthstone/ntm/internal/scanner"
+	"github.com/Dicklesworthstone/ntm/internal/state"
 	"github.com/Dicklesworthstone/ntm/internal/tools"
 	"github.com/go-chi/chi/v5"
 	_ "github.com/mattn/go-sqlite3"
@@ -7120,6 +7121,30 @@ func TestHandleAgentSendV1_ValidSession_Branch(t *testing.T) {
 	}
 }
 
+func TestHandleAgentSendV1_QueueUnknownSession_Branch(t *testing.T) {
+	t.Parallel()
+	srv, store := setupTestServer(t)
+
+	body := `{"message":"hello","panes":["1"],"queue":true}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sessions/no-such-session/agents/send", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("sessionId", "no-such-session")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+
+	srv.handleAgentSendV1(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	prompts, err := store.ListQueuedPrompts(state.QueueQuery{SessionID: "no-such-session"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prompts) != 0 {
+		t.Errorf("queued %d prompts for a missing session", len(prompts))
+	}
+}
+
 func TestHandleAgentContextV1_WithLinesParam(t *testing.T) {
 	t.Parallel()
 	srv, _ := setupTestServer(t)
@@ -376,6 +376,16 @@ func TestHandleRobotStatusV1(t *testing.T) {
 	if rec.Code != http.StatusOK {
 		t.Fatalf("status = %d, want 200", rec.Code)
 	}
+
+	var resp map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	for _, key := range []string{"system", "sessions", "summary"} {
+		if _, ok := resp[key]; !ok {
+			t.Errorf("response missing %q", key)
+		}
+	}
 }
 
 func TestHandleRobotHealthV1(t *testing.T) {
@@ -0,0 +1,340 @@
+package serve
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	ctxmon "github.com/Dicklesworthstone/ntm/internal/context"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/metrics"
+	"github.com/Dicklesworthstone/ntm/internal/pipeline"
+	"github.com/Dicklesworthstone/ntm/internal/quota"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/scheduler"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/webhook"
+)
+
+// MetricsConfig wires live trackers into GET /metrics. A nil cost or rate
+// limit tracker falls back to the state the CLI persists under the project's
+// .ntm directory; other nil sources leave their metrics without series.
+type MetricsConfig struct {
+	ContextMonitor *ctxmon.ContextMonitor
+	Costs          *cost.CostTracker
+	RateLimits     *ratelimit.RateLimitTracker
+	Quotas         *quota.Tracker
+	Webhooks       *webhook.WebhookManager
+	Scheduler      *scheduler.Scheduler
+	// MaxSeries caps the series per metric (default metrics.DefaultMaxSeries).
+	MaxSeries int
+}
+
+// paneStatusFunc reports the agent status of every pane, keyed by session.
+type paneStatusFunc func(ctx context.Context) (map[string][]status.AgentStatus, error)
+
+// metricsScrapeTimeout bounds pane status detection during a scrape.
+const metricsScrapeTimeout = 8 * time.Second
+
+// agentStates is the fixed state label set of ntm_agent_state.
+var agentStates = []status.AgentState{
+	status.StateIdle,
+	status.StateWorking,
+	status.StateAwaitingApproval,
+	status.StateError,
+	status.StateUnknown,
+}
+
+// handleMetrics serves GET /metrics in the Prometheus text format.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), metricsScrapeTimeout)
+	defer cancel()
+
+	exp := metrics.NewExposition(s.metrics.MaxSeries)
+	s.collectAgentMetrics(ctx, exp)
+	s.collectCostMetrics(exp)
+	s.collectRateLimitMetrics(exp)
+	s.collectQuotaMetrics(exp)
+	collectPipelineMetrics(exp)
+	s.collectQueueMetrics(exp)
+	s.collectWebhookMetrics(exp)
+	s.collectSchedulerMetrics(exp)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := exp.WriteTo(w); err != nil {
+		log.Printf("metrics: write: %v", err)
+	}
+}
+
+func (s *Server) collectAgentMetrics(ctx context.Context, exp *metrics.Exposition) {
+	stateGauge := exp.Gauge("ntm_agent_state",
+		"1 for the pane's current agent state, 0 for the others.",
+		"session", "pane", "agent_type", "state")
+	contextGauge := exp.Gauge("ntm_agent_context_usage_percent",
+		"Estimated context window usage of the agent (0-100).",
+		"session", "pane", "agent_type")
+
+	byPane, err := s.paneStatuses(ctx)
+	if err != nil {
+		log.Printf("metrics: pane status: %v", err)
+	}
+	for session, statuses := range byPane {
+		for _, st := range statuses {
+			pane := metricsPaneLabel(st)
+			for _, state := range agentStates {
+				v := 0.0
+				if st.State == state {
+					v = 1
+				}
+				stateGauge.Set(v, session, pane, st.AgentType, string(state))
+			}
+			// The context monitor, when wired, is authoritative below.
+			if s.metrics.ContextMonitor == nil && st.ContextUsage > 0 {
+				contextGauge.Set(st.ContextUsage, session, pane, st.AgentType)
+			}
+		}
+	}
+
+	if m := s.metrics.ContextMonitor; m != nil {
+		for agentID, est := range m.GetAllEstimates() {
+			st := m.GetState(agentID)
+			if st == nil || est == nil {
+				continue
+			}
+			// Agents are keyed by pane title, matching ntm_agent_state.
+			contextGauge.Set(est.UsagePercent, st.SessionName, agentID, st.AgentType)
+		}
+	}
+}
+
+func metricsPaneLabel(st status.AgentStatus) string {
+	if st.PaneName != "" {
+		return st.PaneName
+	}
+	return st.PaneID
+}
+
+func (s *Server) collectCostMetrics(exp *metrics.Exposition) {
+	spend := exp.Gauge("ntm_cost_estimated_usd",
+		"Estimated API spend of the session in USD.",
+		"session")
+	tokens := exp.Gauge("ntm_tokens_estimated",
+		"Tokens exchanged by the session's agents, exact where a transcript was found.",
+		"session", "direction")
+
+	tracker := s.metrics.Costs
+	if tracker == nil {
+		dir := s.triggerProjectDir()
+		if dir == "" {
+			return
+		}
+		tracker = cost.NewCostTracker("")
+		if err := tracker.LoadFromDir(dir); err != nil {
+			log.Printf("metrics: costs: %v", err)
+			return
+		}
+	}
+	for _, session := range tracker.GetAllSessions() {
+		sc := tracker.GetSession(session)
+		if sc == nil {
+			continue
+		}
+		in, out := sc.TotalTokens()
+		cacheRead, cacheWrite := sc.TotalCacheTokens()
+		spend.Set(sc.TotalCost(), session)
+		tokens.Set(float64(in), session, "input")
+		tokens.Set(float64(out), session, "output")
+		tokens.Set(float64(cacheRead), session, "cache_read")
+		tokens.Set(float64(cacheWrite), session, "cache_write")
+	}
+}
+
+func (s *Server) collectRateLimitMetrics(exp *metrics.Exposition) {
+	cooldown := exp.Gauge("ntm_ratelimit_cooldown_seconds",
+		"Seconds until the provider's rate-limit cooldown ends (0 when none).",
+		"provider")
+	delay := exp.Gauge("ntm_ratelimit_delay_seconds",
+		"Learned delay between spawns/sends for the provider.",
+		"provider")
+	hits := exp.Counter("ntm_ratelimit_hits_total",
+		"Rate limits recorded for the provider.",
+		"provider")
+
+	tracker := s.metrics.RateLimits
+	if tracker == nil {
+		dir := s.triggerProjectDir()
+		if dir == "" {
+			return
+		}
+		tracker = ratelimit.NewRateLimitTracker(dir)
+		if err := tracker.LoadFromDir(dir); err != nil {
+			log.Printf("metrics: rate limits: %v", err)
+			return
+		}
+	}
+	for _, provider := range tracker.GetAllProviders() {
+		st := tracker.GetProviderState(provider)
+		if st == nil {
+			continue
+		}
+		cooldown.Set(tracker.CooldownRemaining(provider).Seconds(), provider)
+		delay.Set(st.CurrentDelay.Seconds(), provider)
+		hits.Set(float64(st.TotalRateLimits), provider)
+	}
+}
+
+func (s *Server) collectQuotaMetrics(exp *metrics.Exposition) {
+	usage := exp.Gauge("ntm_quota_usage_percent",
+		"Provider quota used by the pane's account (0-100) per quota window.",
+		"pane", "provider", "window")
+	limited := exp.Gauge("ntm_quota_limited",
+		"1 when the pane's account is currently rate limited by its provider.",
+		"pane", "provider")
+
+	if s.metrics.Quotas == nil {
+		return
+	}
+	for pane, q := range s.metrics.Quotas.GetAllQuotas() {
+		if q == nil || q.Error != "" {
+			continue
+		}
+		provider := string(q.Provider)
+		usage.Set(q.SessionUsage, pane, provider, "session")
+		usage.Set(q.PeriodUsage, pane, provider, "period")
+		usage.Set(q.WeeklyUsage, pane, provider, "weekly")
+		if q.Provider == quota.ProviderClaude {
+			usage.Set(q.SonnetUsage, pane, provider, "sonnet")
+		}
+		v := 0.0
+		if q.IsLimited {
+			v = 1
+		}
+		limited.Set(v, pane, provider)
+	}
+}
+
+func collectPipelineMetrics(exp *metrics.Exposition) {
+	runs := exp.Gauge("ntm_pipeline_runs",
+		"Pipeline runs tracked by this server, by workflow and status.",
+		"workflow", "status")
+	duration := exp.Gauge("ntm_pipeline_run_duration_seconds_sum",
+		"Summed duration of tracked runs; divide by ntm_pipeline_runs for the mean. Running runs count up to now.",
+		"workflow", "status")
+
+	now := time.Now()
+	for _, p := range pipeline.SnapshotPipelines() {
+		st := strings.ToLower(p.Status)
+		if st == "" {
+			st = "running"
+		}
+		end := now
+		if p.FinishedAt != nil {
+			end = *p.FinishedAt
+		}
+		runs.Add(1, p.WorkflowID, st)
+		duration.Add(end.Sub(p.StartedAt).Seconds(), p.WorkflowID, st)
+	}
+}
+
+func (s *Server) collectQueueMetrics(exp *metrics.Exposition) {
+	depth := exp.Gauge("ntm_prompt_queue_depth",
+		"Prompts waiting in the pane's queue for the agent to go idle.",
+		"session", "pane")
+	due := exp.Gauge("ntm_schedules_due",
+		"Scheduled prompts and pipelines whose next run time has passed.")
+
+	if s.stateStore == nil {
+		return
+	}
+	if sessions, err := s.stateStore.QueuedSessions(); err == nil {
+		for _, session := range sessions {
+			depths, err := s.stateStore.QueueDepths(session)
+			if err != nil {
+				continue
+			}
+			for pane, n := range depths {
+				depth.Set(float64(n), session, pane)
+			}
+		}
+	}
+	if schedules, err := s.stateStore.DueSchedules(time.Now()); err == nil {
+		due.Set(float64(len(schedules)))
+	}
+}
+
+func (s *Server) collectWebhookMetrics(exp *metrics.Exposition) {
+	deadLetters := exp.Gauge("ntm_webhook_dead_letters",
+		"Outbound webhook deliveries that exhausted their retries.")
+	queued := exp.Gauge("ntm_webhook_queue_length",
+		"Outbound webhook deliveries waiting to be sent or retried.",
+		"queue")
+	deliveries := exp.Counter("ntm_webhook_deliveries_total",
+		"Outbound webhook delivery attempts by outcome.",
+		"outcome")
+
+	m := s.metrics.Webhooks
+	if m == nil {
+		return
+	}
+	st := m.Stats()
+	deadLetters.Set(float64(st.DeadLetterCount))
+	queued.Set(float64(st.QueueLength), "pending")
+	queued.Set(float64(st.RetryQueueLen), "retry")
+	deliveries.Set(float64(st.Deliveries), "attempted")
+	deliveries.Set(float64(st.Failures), "failed")
+	deliveries.Set(float64(st.DroppedEvents), "dropped")
+}
+
+func (s *Server) collectSchedulerMetrics(exp *metrics.Exposition) {
+	depth := exp.Gauge("ntm_scheduler_queue_depth",
+		"Spawn jobs waiting in the scheduler queue.")
+	running := exp.Gauge("ntm_scheduler_running_jobs",
+		"Spawn jobs currently executing.")
+	jobs := exp.Counter("ntm_scheduler_jobs_total",
+		"Spawn jobs by outcome since the scheduler started.",
+		"outcome")
+	paused := exp.Gauge("ntm_scheduler_paused",
+		"1 when the spawn scheduler is paused.")
+
+	sch := s.metrics.Scheduler
+	if sch == nil {
+		return
+	}
+	st := sch.Stats()
+	depth.Set(float64(st.CurrentQueueSize))
+	running.Set(float64(st.CurrentRunning))
+	jobs.Set(float64(st.TotalSubmitted), "submitted")
+	jobs.Set(float64(st.TotalCompleted), "completed")
+	jobs.Set(float64(st.TotalFailed), "failed")
+	jobs.Set(float64(st.TotalRetried), "retried")
+	v := 0.0
+	if st.IsPaused {
+		v = 1
+	}
+	paused.Set(v)
+}
+
+// detectPaneStatuses detects agent state in every tmux session.
+func detectPaneStatuses(ctx context.Context) (map[string][]status.AgentStatus, error) {
+	sessions, err := tmux.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	detector := status.NewDetector()
+	out := make(map[string][]status.AgentStatus, len(sessions))
+	for _, sess := range sessions {
+		if ctx.Err() != nil {
+			return out, ctx.Err()
+		}
+		statuses, err := detector.DetectAllContext(ctx, sess.Name)
+		if err != nil {
+			continue
+		}
+		out[sess.Name] = statuses
+	}
+	return out, nil
+}
@@ -0,0 +1,82 @@
+package serve
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+)
+
+func TestHandleMetrics(t *testing.T) {
+	srv, store := setupTestServer(t)
+	srv.paneStatuses = func(context.Context) (map[string][]status.AgentStatus, error) {
+		return map[string][]status.AgentStatus{
+			"proj": {
+				{PaneID: "%1", PaneName: "proj__cc_1", AgentType: "cc", State: status.StateWorking, ContextUsage: 42},
+				{PaneID: "%2", AgentType: "cod", State: status.StateIdle},
+			},
+		}, nil
+	}
+	costs := cost.NewCostTracker("")
+	costs.RecordTokens("proj", "proj__cc_1", "claude-sonnet-4", 1000, 500)
+	limits := ratelimit.NewRateLimitTracker(t.TempDir())
+	limits.RecordRateLimit("anthropic", "spawn")
+	srv.metrics.Costs = costs
+	srv.metrics.RateLimits = limits
+
+	if err := store.EnqueuePrompt(&state.QueuedPrompt{SessionID: "proj", PaneID: "%1", PaneIndex: 1, Prompt: "hello"}); err != nil {
+		t.Fatalf("EnqueuePrompt: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`ntm_agent_state{session="proj",pane="proj__cc_1",agent_type="cc",state="working"} 1`,
+		`ntm_agent_state{session="proj",pane="proj__cc_1",agent_type="cc",state="idle"} 0`,
+		`ntm_agent_state{session="proj",pane="%2",agent_type="cod",state="idle"} 1`,
+		`ntm_agent_context_usage_percent{session="proj",pane="proj__cc_1",agent_type="cc"} 42`,
+		`ntm_tokens_estimated{session="proj",direction="input"} 1000`,
+		`ntm_ratelimit_hits_total{provider="anthropic"} 1`,
+		`ntm_prompt_queue_depth{session="proj",pane="%1"} 1`,
+		"# TYPE ntm_pipeline_runs gauge",
+		"# TYPE ntm_webhook_dead_letters gauge",
+		"# TYPE ntm_scheduler_queue_depth gauge",
+		"ntm_schedules_due 0",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %s\n%s", want, body)
+		}
+	}
+	if !strings.Contains(body, `ntm_cost_estimated_usd{session="proj"} `) {
+		t.Errorf("metrics missing session spend\n%s", body)
+	}
+}
+
+func TestHandleMetricsRequiresAuth(t *testing.T) {
+	srv := New(Config{Auth: AuthConfig{Mode: AuthModeAPIKey, APIKey: "secret"}})
+	srv.paneStatuses = func(context.Context) (map[string][]status.AgentStatus, error) {
+		return nil, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
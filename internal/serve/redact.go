@@ -52,8 +52,9 @@ func (s *Server) redactionMiddleware(next http.Handler) http.Handler {
 		}
 		categories := make(map[string]int)
 
-		// Handle request body redaction for JSON content
-		if r.Body != nil && r.ContentLength > 0 {
+		// Handle request body redaction for JSON content. Trigger deliveries
+		// are left intact because their signature covers the raw body.
+		if r.Body != nil && r.ContentLength > 0 && !isTriggerDelivery(r) {
 			contentType := r.Header.Get("Content-Type")
 			if isJSONContent(contentType) {
 				body, err := io.ReadAll(r.Body)
@@ -0,0 +1,180 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Dicklesworthstone/ntm/internal/cron"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+// Schedule-specific error codes
+const (
+	ErrCodeScheduleNotFound = "SCHEDULE_NOT_FOUND"
+	ErrCodeInvalidSchedule  = "INVALID_SCHEDULE"
+)
+
+// ScheduleRequest is the request body for POST /api/v1/schedules.
+type ScheduleRequest struct {
+	Name         string                   `json:"name"`
+	Cron         string                   `json:"cron"`
+	TargetType   state.ScheduleTargetType `json:"target_type"`
+	Session      string                   `json:"session"`
+	Target       state.ScheduleTarget     `json:"target"`
+	MissedPolicy state.MissedRunPolicy    `json:"missed_policy,omitempty"`
+	Enabled      *bool                    `json:"enabled,omitempty"` // Defaults to true
+}
+
+// registerScheduleRoutes registers the cron schedule endpoints.
+func (s *Server) registerScheduleRoutes(r chi.Router) {
+	r.Route("/schedules", func(r chi.Router) {
+		r.With(s.RequirePermission(PermReadPipelines)).Get("/", s.handleListSchedules)
+		r.With(s.RequirePermission(PermWritePipelines)).Post("/", s.handleCreateSchedule)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.With(s.RequirePermission(PermReadPipelines)).Get("/", s.handleGetSchedule)
+			r.With(s.RequirePermission(PermWritePipelines)).Delete("/", s.handleDeleteSchedule)
+			r.With(s.RequirePermission(PermWritePipelines)).Post("/run", s.handleRunSchedule)
+		})
+	})
+}
+
+// handleListSchedules handles GET /api/v1/schedules
+func (s *Server) handleListSchedules(w http.ResponseWriter, r *http.Request) {
+	reqID := requestIDFromContext(r.Context())
+	if s.stateStore == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeServiceUnavail, "state store not available", nil, reqID)
+		return
+	}
+
+	schedules, err := s.stateStore.ListSchedules()
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+	if schedules == nil {
+		schedules = []state.Schedule{}
+	}
+
+	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"schedules": schedules,
+		"count":     len(schedules),
+	}, reqID)
+}
+
+// handleCreateSchedule handles POST /api/v1/schedules
+func (s *Server) handleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	reqID := requestIDFromContext(r.Context())
+	if s.stateStore == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeServiceUnavail, "state store not available", nil, reqID)
+		return
+	}
+
+	var req ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid request body", nil, reqID)
+		return
+	}
+
+	sch := &state.Schedule{
+		Name:         req.Name,
+		Cron:         req.Cron,
+		TargetType:   req.TargetType,
+		SessionID:    req.Session,
+		Target:       req.Target,
+		MissedPolicy: req.MissedPolicy,
+		Enabled:      req.Enabled == nil || *req.Enabled,
+	}
+	if err := cron.Prepare(sch, time.Now()); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeInvalidSchedule, err.Error(), nil, reqID)
+		return
+	}
+	if existing, _ := s.stateStore.GetSchedule(sch.Name); existing != nil {
+		writeErrorResponse(w, http.StatusConflict, ErrCodeConflict, "schedule already exists: "+sch.Name, nil, reqID)
+		return
+	}
+	if err := s.stateStore.CreateSchedule(sch); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+
+	slog.Info("schedule created", "request_id", reqID, "name", sch.Name, "cron", sch.Cron, "target_type", sch.TargetType)
+	writeSuccessResponse(w, http.StatusCreated, map[string]interface{}{"schedule": sch}, reqID)
+}
+
+// handleGetSchedule handles GET /api/v1/schedules/{id}
+func (s *Server) handleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	reqID := requestIDFromContext(r.Context())
+	sch, ok := s.lookupSchedule(w, r, reqID)
+	if !ok {
+		return
+	}
+	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{"schedule": sch}, reqID)
+}
+
+// handleDeleteSchedule handles DELETE /api/v1/schedules/{id}
+func (s *Server) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	reqID := requestIDFromContext(r.Context())
+	sch, ok := s.lookupSchedule(w, r, reqID)
+	if !ok {
+		return
+	}
+	if err := s.stateStore.DeleteSchedule(sch.ID); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+
+	slog.Info("schedule deleted", "request_id", reqID, "name", sch.Name)
+	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{"id": sch.ID, "deleted": true}, reqID)
+}
+
+// handleRunSchedule handles POST /api/v1/schedules/{id}/run. The run happens
+// in the background; its outcome is recorded on the schedule.
+func (s *Server) handleRunSchedule(w http.ResponseWriter, r *http.Request) {
+	reqID := requestIDFromContext(r.Context())
+	sch, ok := s.lookupSchedule(w, r, reqID)
+	if !ok {
+		return
+	}
+	if s.scheduler == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeServiceUnavail, "scheduler not running", nil, reqID)
+		return
+	}
+
+	go func(sch state.Schedule) {
+		_ = s.scheduler.RunNow(context.Background(), sch)
+	}(*sch)
+
+	slog.Info("schedule run requested", "request_id", reqID, "name", sch.Name)
+	writeSuccessResponse(w, http.StatusAccepted, map[string]interface{}{
+		"id":     sch.ID,
+		"name":   sch.Name,
+		"status": "started",
+	}, reqID)
+}
+
+// lookupSchedule resolves the {id} URL parameter (ID or name), writing an
+// error response when it can't.
+func (s *Server) lookupSchedule(w http.ResponseWriter, r *http.Request, reqID string) (*state.Schedule, bool) {
+	if s.stateStore == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeServiceUnavail, "state store not available", nil, reqID)
+		return nil, false
+	}
+	id := strings.TrimSpace(chi.URLParam(r, "id"))
+	sch, err := s.stateStore.GetSchedule(id)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return nil, false
+	}
+	if sch == nil {
+		writeErrorResponse(w, http.StatusNotFound, ErrCodeScheduleNotFound, "schedule not found: "+id, nil, reqID)
+		return nil, false
+	}
+	return sch, true
+}
@@ -0,0 +1,63 @@
+package serve
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestScheduleRoutes(t *testing.T) {
+	srv, store := setupTestServer(t)
+
+	do := func(method, path, body string) (int, map[string]interface{}) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		srv.router.ServeHTTP(rec, req)
+		var resp map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode %s %s: %v (%s)", method, path, err, rec.Body.String())
+		}
+		return rec.Code, resp
+	}
+
+	code, resp := do(http.MethodPost, "/api/v1/schedules", `{
+		"name": "triage", "cron": "0 9 * * 1-5", "target_type": "send", "session": "proj",
+		"target": {"message": "triage the new issues", "panes": ["1"]}
+	}`)
+	if code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body = %v", code, resp)
+	}
+	created, _ := resp["schedule"].(map[string]interface{})
+	if created["next_run_at"] == nil || created["missed_policy"] != "skip" || created["enabled"] != true {
+		t.Errorf("created schedule = %v", created)
+	}
+
+	if code, _ = do(http.MethodPost, "/api/v1/schedules", `{"name": "triage", "cron": "@daily", "target_type": "send", "session": "proj", "target": {"message": "x"}}`); code != http.StatusConflict {
+		t.Errorf("duplicate: status = %d, want 409", code)
+	}
+	if code, resp = do(http.MethodPost, "/api/v1/schedules", `{"name": "bad", "cron": "61 * * * *", "target_type": "send", "session": "proj", "target": {"message": "x"}}`); code != http.StatusBadRequest || resp["error_code"] != ErrCodeInvalidSchedule {
+		t.Errorf("invalid cron: status = %d, body = %v", code, resp)
+	}
+
+	code, resp = do(http.MethodGet, "/api/v1/schedules", "")
+	if code != http.StatusOK || resp["count"] != float64(1) {
+		t.Fatalf("list: status = %d, body = %v", code, resp)
+	}
+	if code, resp = do(http.MethodGet, "/api/v1/schedules/triage", ""); code != http.StatusOK {
+		t.Errorf("get by name: status = %d, body = %v", code, resp)
+	}
+
+	code, _ = do(http.MethodDelete, "/api/v1/schedules/"+created["id"].(string), "")
+	if code != http.StatusOK {
+		t.Errorf("delete: status = %d", code)
+	}
+	if sch, _ := store.GetSchedule("triage"); sch != nil {
+		t.Error("schedule not deleted")
+	}
+	if code, _ = do(http.MethodPost, "/api/v1/schedules/triage/run", ""); code != http.StatusNotFound {
+		t.Errorf("run missing: status = %d, want 404", code)
+	}
+}
@@ -32,14 +32,20 @@ import (
 
 	"github.com/Dicklesworthstone/ntm/internal/agentmail"
 	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/cron"
 	"github.com/Dicklesworthstone/ntm/internal/ensemble"
 	"github.com/Dicklesworthstone/ntm/internal/events"
 	"github.com/Dicklesworthstone/ntm/internal/kernel"
 	"github.com/Dicklesworthstone/ntm/internal/metrics"
+	"github.com/Dicklesworthstone/ntm/internal/queue"
 	"github.com/Dicklesworthstone/ntm/internal/redaction"
 	"github.com/Dicklesworthstone/ntm/internal/robot"
 	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/status"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tracing"
+	"github.com/Dicklesworthstone/ntm/internal/transitions"
+	"github.com/Dicklesworthstone/ntm/internal/trigger"
 	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
 	"github.com/gorilla/websocket"
@@ -52,6 +58,9 @@ type Server struct {
 	publicBaseURL string
 	eventBus      *events.EventBus
 	stateStore    *state.Store
+	observer      *transitions.Observer
+	scheduler     *cron.Runner
+	dispatcher    *queue.Dispatcher
 	server        *http.Server
 	auth          AuthConfig
 
@@ -84,6 +93,14 @@ type Server struct {
 
 	// Redaction configuration for REST API
 	redactionCfg *RedactionConfig
+
+	// Inbound webhook triggers
+	triggerLimiter *trigger.Limiter
+	runTrigger     triggerAction
+
+	// Prometheus scrape sources
+	metrics      MetricsConfig
+	paneStatuses paneStatusFunc
 }
 
 // AuthMode configures authentication for the server.
@@ -131,6 +148,11 @@ type Config struct {
 	Auth          AuthConfig
 	// AllowedOrigins controls CORS origin allowlist. Empty means default localhost only.
 	AllowedOrigins []string
+	// TmuxControlMode streams pane output over tmux control-mode connections
+	// instead of pipe-pane.
+	TmuxControlMode bool
+	// Metrics supplies the trackers exported by GET /metrics.
+	Metrics MetricsConfig
 }
 
 const (
@@ -767,10 +789,20 @@ func New(cfg Config) *Server {
 		idempotencyStore:   NewIdempotencyStore(24 * time.Hour),
 		jobStore:           NewJobStore(),
 		wsHub:              NewWSHub(),
+		triggerLimiter:     trigger.NewLimiter(),
+		metrics:            cfg.Metrics,
+		paneStatuses:       detectPaneStatuses,
+	}
+	s.runTrigger = s.executeTrigger
+	if cfg.StateStore != nil {
+		s.observer = transitions.NewObserver(cfg.StateStore)
+		s.scheduler = cron.NewRunner(cfg.StateStore, nil)
+		s.dispatcher = queue.NewDispatcher(cfg.StateStore)
 	}
 
 	// Initialize pane output streaming
 	streamCfg := tmux.DefaultPaneStreamerConfig()
+	streamCfg.ControlMode = cfg.TmuxControlMode
 	s.streamManager = tmux.NewStreamManager(tmux.DefaultClient, func(event tmux.StreamEvent) {
 		// Publish pane output to WebSocket subscribers
 		// Topic format: panes:session:pane_idx
@@ -793,6 +825,7 @@ func (s *Server) buildRouter() chi.Router {
 	// Base middleware stack
 	r.Use(chimw.RealIP)
 	r.Use(s.requestIDMiddlewareFunc)
+	r.Use(s.tracingMiddlewareFunc)
 	r.Use(s.recovererMiddleware)
 	r.Use(s.loggingMiddlewareFunc)
 	r.Use(s.corsMiddlewareFunc)
@@ -803,6 +836,9 @@ func (s *Server) buildRouter() chi.Router {
 	// Health check (no versioning)
 	r.Get("/health", s.handleHealth)
 
+	// Prometheus scrape endpoint (no versioning)
+	r.Get("/metrics", s.handleMetrics)
+
 	// SSE event stream (no versioning)
 	r.Get("/events", s.handleEventStream)
 
@@ -881,6 +917,7 @@ func (s *Server) buildRouter() chi.Router {
 			r.With(s.RequirePermission(PermWriteAgents)).Post("/wait", s.handleAgentWaitV1)
 			r.With(s.RequirePermission(PermReadAgents)).Get("/route", s.handleAgentRouteV1)
 			r.With(s.RequirePermission(PermReadAgents)).Get("/activity", s.handleAgentActivityV1)
+			r.With(s.RequirePermission(PermReadAgents)).Get("/transitions", s.handleAgentTransitionsV1)
 			r.With(s.RequirePermission(PermReadAgents)).Get("/health", s.handleAgentHealthV1)
 			r.With(s.RequirePermission(PermReadAgents)).Get("/context", s.handleAgentContextV1)
 			r.With(s.RequirePermission(PermWriteAgents)).Post("/restart", s.handleAgentRestartV1)
@@ -898,6 +935,12 @@ func (s *Server) buildRouter() chi.Router {
 		// Pipeline API
 		s.registerPipelineRoutes(r)
 
+		// Cron schedules for sends and pipeline runs
+		s.registerScheduleRoutes(r)
+
+		// Inbound webhook triggers
+		s.registerTriggerRoutes(r)
+
 		// Mail and Reservations API
 		s.registerMailRoutes(r)
 
@@ -999,8 +1042,24 @@ func (s *Server) Start(ctx context.Context) error {
 	defer s.wsHub.Stop()
 
 	// Cleanup pane streaming on shutdown
+	defer tmux.DefaultClient.CloseControlMode()
 	defer s.streamManager.StopAll()
 
+	// Fire cron schedules while serving
+	if s.scheduler != nil {
+		go s.scheduler.Run(ctx)
+	}
+
+	// Deliver queued prompts as panes go idle
+	if s.dispatcher != nil {
+		go s.dispatcher.Run(ctx)
+	}
+
+	// Keep the state-transition log current and pruned
+	if s.observer != nil {
+		go s.observer.Run(ctx)
+	}
+
 	// Subscribe to events for SSE and WebSocket broadcasting
 	if s.eventBus != nil {
 		unsubscribe := s.eventBus.SubscribeAll(func(e events.BusEvent) {
@@ -1137,6 +1196,41 @@ func (s *Server) requestIDMiddlewareFunc(next http.Handler) http.Handler {
 	})
 }
 
+// tracingMiddlewareFunc records a server span per request, continuing the
+// caller's trace when a W3C traceparent header is present.
+func (s *Server) tracingMiddlewareFunc(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !tracing.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := tracing.ContextWithTraceparent(r.Context(), r.Header.Get("traceparent"))
+		ctx, span := tracing.Start(ctx, "HTTP "+r.Method, tracing.WithKind(tracing.KindServer), tracing.WithAttributes(
+			tracing.String("http.request.method", r.Method),
+			tracing.String("url.path", r.URL.Path),
+			tracing.String("ntm.request_id", requestIDFromContext(r.Context())),
+		))
+		defer span.End()
+
+		ww := chimw.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(tracing.Int("http.response.status_code", status))
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				span.SetAttributes(tracing.String("http.route", pattern))
+			}
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(tracing.StatusError, http.StatusText(status))
+		}
+	})
+}
+
 // recovererMiddleware catches panics and returns a proper JSON error response.
 func (s *Server) recovererMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1196,6 +1290,13 @@ func (s *Server) authMiddlewareFunc(next http.Handler) http.Handler {
 			return
 		}
 
+		// Webhook deliveries carry an HMAC signature instead of credentials;
+		// the trigger handler verifies it.
+		if isTriggerDelivery(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		if err := s.authenticateRequest(r); err != nil {
 			reqID := requestIDFromContext(r.Context())
 			log.Printf("auth failed mode=%s path=%s remote=%s request_id=%s err=%v", s.auth.Mode, r.URL.Path, r.RemoteAddr, reqID, err)
@@ -1354,19 +1455,45 @@ func (s *Server) authenticateRequest(r *http.Request) error {
 }
 
 func (s *Server) authenticateAPIKey(r *http.Request) error {
-	if s.auth.APIKey == "" {
+	return checkAPIKey(r, s.auth.APIKey)
+}
+
+func checkAPIKey(r *http.Request, want string) error {
+	if want == "" {
 		return errors.New("api key not configured")
 	}
 	key := extractAPIKey(r)
 	if key == "" {
 		return errors.New("missing api key")
 	}
-	if subtle.ConstantTimeCompare([]byte(key), []byte(s.auth.APIKey)) != 1 {
+	if subtle.ConstantTimeCompare([]byte(key), []byte(want)) != 1 {
 		return errors.New("invalid api key")
 	}
 	return nil
 }
 
+// RequireAPIKey wraps next so requests must present key in the X-API-Key
+// header or as an Authorization bearer token, the same credentials the
+// api_key auth mode accepts. Other requests get 401 Unauthorized.
+func RequireAPIKey(key string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := checkAPIKey(r, key); err != nil {
+			log.Printf("auth failed mode=%s path=%s remote=%s err=%v", AuthModeAPIKey, r.URL.Path, r.RemoteAddr, err)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IsLoopbackHost reports whether host (optionally with a port) names a
+// loopback address. An empty host counts as loopback, matching the server's
+// 127.0.0.1 default.
+func IsLoopbackHost(host string) bool {
+	return isLoopbackHost(host)
+}
+
 func (s *Server) authenticateOIDC(r *http.Request) error {
 	token := extractBearerToken(r)
 	if token == "" {
@@ -2390,18 +2517,48 @@ func (s *Server) handleSessionAgentsV1(w http.ResponseWriter, r *http.Request, s
 		return
 	}
 
-	// Ensure agents is never null
-	if agents == nil {
-		agents = []state.Agent{}
+	// Overlay live pane state so agents blocked on a confirmation prompt are
+	// reported as awaiting approval rather than their last persisted status.
+	live := s.livePaneStatuses(r.Context(), sessionID)
+	views := make([]sessionAgentView, 0, len(agents))
+	for _, a := range agents {
+		view := sessionAgentView{Agent: a}
+		if st, ok := live[a.TmuxPaneID]; ok && st.State == status.StateAwaitingApproval {
+			view.Status = state.AgentAwaitingApproval
+			view.PendingApproval = st.PendingApproval
+		}
+		views = append(views, view)
 	}
 
 	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
 		"session_id": sessionID,
-		"agents":     agents,
-		"count":      len(agents),
+		"agents":     views,
+		"count":      len(views),
 	}, reqID)
 }
 
+// sessionAgentView is a persisted agent overlaid with live pane state.
+type sessionAgentView struct {
+	state.Agent
+	PendingApproval *status.ApprovalPrompt `json:"pending_approval,omitempty"`
+}
+
+// livePaneStatuses detects the current state of every pane in a session,
+// keyed by pane ID, and records any state changes in the transition log.
+// Best-effort: returns nil when tmux is unavailable.
+func (s *Server) livePaneStatuses(ctx context.Context, session string) map[string]status.AgentStatus {
+	statuses, err := status.NewDetector().DetectAllContext(ctx, session)
+	if err != nil {
+		return nil
+	}
+	s.observer.Record(session, statuses)
+	byPane := make(map[string]status.AgentStatus, len(statuses))
+	for _, st := range statuses {
+		byPane[st.PaneID] = st
+	}
+	return byPane
+}
+
 // handleSessionEventsV1 handles GET /api/v1/sessions/{id}/events.
 func (s *Server) handleSessionEventsV1(w http.ResponseWriter, r *http.Request, sessionID string) {
 	reqID := requestIDFromContext(r.Context())
@@ -2435,9 +2592,20 @@ func (s *Server) handleSessionEventsV1(w http.ResponseWriter, r *http.Request, s
 // handleRobotStatusV1 handles GET /api/v1/robot/status.
 func (s *Server) handleRobotStatusV1(w http.ResponseWriter, r *http.Request) {
 	reqID := requestIDFromContext(r.Context())
-	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
-		"note": "full robot status requires robot package integration",
-	}, reqID)
+
+	result, err := robot.GetStatus()
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+
+	data, err := toJSONMap(result)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, "failed to serialize response", nil, reqID)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, data, reqID)
 }
 
 // handleRobotHealthV1 handles GET /api/v1/robot/health.
@@ -2992,6 +3160,8 @@ func (s *Server) handleListAgentsV1(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	live := s.livePaneStatuses(r.Context(), sessionID)
+
 	// Filter to only include recognized agent panes (not user/unknown)
 	agents := make([]map[string]interface{}, 0, len(panes))
 	for _, p := range panes {
@@ -2999,7 +3169,7 @@ func (s *Server) handleListAgentsV1(w http.ResponseWriter, r *http.Request) {
 		if agentType == "" || agentType == "unknown" || agentType == "user" {
 			continue
 		}
-		agents = append(agents, map[string]interface{}{
+		agent := map[string]interface{}{
 			"pane_index": p.Index,
 			"pane_id":    p.ID,
 			"agent_type": agentType,
@@ -3007,7 +3177,14 @@ func (s *Server) handleListAgentsV1(w http.ResponseWriter, r *http.Request) {
 			"variant":    p.Variant,
 			"tags":       p.Tags,
 			"active":     p.Active,
-		})
+		}
+		if st, ok := live[p.ID]; ok {
+			agent["state"] = st.State
+			if st.PendingApproval != nil {
+				agent["pending_approval"] = st.PendingApproval
+			}
+		}
+		agents = append(agents, agent)
 	}
 
 	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
@@ -3078,6 +3255,7 @@ type AgentSendRequest struct {
 	AgentTypes []string `json:"agent_types,omitempty"`
 	Message    string   `json:"message"`
 	All        bool     `json:"all,omitempty"`
+	Queue      bool     `json:"queue,omitempty"` // Deliver when each target pane is idle
 }
 
 // handleAgentSendV1 handles POST /api/v1/sessions/{sessionId}/agents/send.
@@ -3102,11 +3280,17 @@ func (s *Server) handleAgentSendV1(w http.ResponseWriter, r *http.Request) {
 	}
 
 	opts := robot.SendOptions{
-		Session:    sessionID,
-		Message:    req.Message,
-		Panes:      req.Panes,
-		AgentTypes: req.AgentTypes,
-		All:        req.All,
+		Session:     sessionID,
+		Message:     req.Message,
+		Panes:       req.Panes,
+		AgentTypes:  req.AgentTypes,
+		All:         req.All,
+		RequestedBy: "ntm serve",
+	}
+
+	if req.Queue {
+		s.queueAgentSend(w, opts, reqID)
+		return
 	}
 
 	result, err := robot.GetSend(opts)
@@ -3124,6 +3308,55 @@ func (s *Server) handleAgentSendV1(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, http.StatusOK, data, reqID)
 }
 
+// queueAgentSend resolves the send targets and appends the message to each
+// target pane's prompt queue instead of sending it now.
+func (s *Server) queueAgentSend(w http.ResponseWriter, opts robot.SendOptions, reqID string) {
+	if s.stateStore == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeServiceUnavail, "state store not available", nil, reqID)
+		return
+	}
+
+	opts.DryRun = true
+	preview, err := robot.GetSend(opts)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+	if !preview.Success {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, preview.Error, nil, reqID)
+		return
+	}
+
+	panes, err := tmux.GetPanes(opts.Session)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+	wanted := make(map[string]bool, len(preview.WouldSendTo))
+	for _, idx := range preview.WouldSendTo {
+		wanted[idx] = true
+	}
+	var targets []tmux.Pane
+	for _, p := range panes {
+		if wanted[strconv.Itoa(p.Index)] {
+			targets = append(targets, p)
+		}
+	}
+
+	queued, err := queue.Enqueue(s.stateStore, opts.Session, targets, opts.Message, queue.SourceAPI, "")
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+
+	slog.Info("prompt queued", "request_id", reqID, "session", opts.Session, "panes", len(queued))
+	writeSuccessResponse(w, http.StatusAccepted, map[string]interface{}{
+		"session": opts.Session,
+		"queued":  queued,
+		"count":   len(queued),
+	}, reqID)
+}
+
 // AgentInterruptRequest is the request body for POST /sessions/{id}/agents/interrupt.
 type AgentInterruptRequest struct {
 	Panes   []string `json:"panes,omitempty"`
@@ -3307,6 +3540,60 @@ func (s *Server) handleAgentActivityV1(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, http.StatusOK, data, reqID)
 }
 
+// handleAgentTransitionsV1 handles GET /api/v1/sessions/{sessionId}/agents/transitions.
+// Query params: pane, state, since, until (durations or RFC3339), limit, stats.
+func (s *Server) handleAgentTransitionsV1(w http.ResponseWriter, r *http.Request) {
+	reqID := requestIDFromContext(r.Context())
+	sessionID := chi.URLParam(r, "sessionId")
+
+	if sessionID == "" {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, "session ID required", nil, reqID)
+		return
+	}
+	if s.stateStore == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeServiceUnavail, "state store not available", nil, reqID)
+		return
+	}
+
+	q := r.URL.Query()
+	opts := robot.TransitionsOptions{
+		Session: sessionID,
+		Pane:    q.Get("pane"),
+		State:   q.Get("state"),
+		Since:   q.Get("since"),
+		Until:   q.Get("until"),
+		Stats:   q.Get("stats") == "true" || q.Get("stats") == "1",
+		Store:   s.stateStore,
+	}
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			opts.Limit = parsed
+		}
+	}
+
+	result, err := robot.GetTransitions(opts)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+	if !result.Success {
+		status := http.StatusInternalServerError
+		if result.ErrorCode == robot.ErrCodeInvalidFlag {
+			status = http.StatusBadRequest
+		}
+		writeErrorResponse(w, status, ErrCodeBadRequest, result.Error, nil, reqID)
+		return
+	}
+
+	data, err := toJSONMap(result)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, "failed to serialize response", nil, reqID)
+		return
+	}
+
+	writeSuccessResponse(w, http.StatusOK, data, reqID)
+}
+
 // handleAgentHealthV1 handles GET /api/v1/sessions/{sessionId}/agents/health.
 func (s *Server) handleAgentHealthV1(w http.ResponseWriter, r *http.Request) {
 	reqID := requestIDFromContext(r.Context())
@@ -23,6 +23,7 @@ import (
 
 	"github.com/Dicklesworthstone/ntm/internal/events"
 	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tracing"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -713,6 +714,40 @@ func TestLoggingMiddleware(t *testing.T) {
 	}
 }
 
+func TestTracingMiddlewareContinuesTraceparent(t *testing.T) {
+	srv, _ := setupTestServer(t)
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	tracing.SetProvider(tracing.NewProvider(tracing.Config{}, tracing.NewFileExporter(path)))
+	t.Cleanup(func() { tracing.SetProvider(nil) })
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if err := tracing.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read traces: %v", err)
+	}
+	for _, want := range []string{
+		`"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"`,
+		`"parentSpanId":"00f067aa0ba902b7"`,
+		`"name":"HTTP GET"`,
+		`"key":"http.route","value":{"stringValue":"/health"}`,
+		`"key":"http.response.status_code","value":{"intValue":"200"}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("trace output missing %s:\n%s", want, data)
+		}
+	}
+}
+
 func TestAuthMiddlewareAPIKey(t *testing.T) {
 	srv := New(Config{
 		Auth: AuthConfig{
@@ -3191,3 +3226,54 @@ func TestLoggingMiddleware_WithoutRequestID(t *testing.T) {
 		t.Error("next handler should be called")
 	}
 }
+
+func TestHandleAgentTransitionsV1(t *testing.T) {
+	srv, store := setupTestServer(t)
+
+	now := time.Now().UTC()
+	for _, tr := range []state.AgentStateTransition{
+		{SessionID: "proj", PaneID: "%1", ToState: "idle", TransitionAt: now.Add(-3 * time.Hour)},
+		{SessionID: "proj", PaneID: "%1", FromState: "idle", ToState: "working", TransitionAt: now.Add(-30 * time.Minute)},
+		{SessionID: "proj", PaneID: "%2", ToState: "error", TransitionAt: now.Add(-5 * time.Minute)},
+	} {
+		tr := tr
+		if err := store.RecordStateTransition(&tr); err != nil {
+			t.Fatalf("RecordStateTransition: %v", err)
+		}
+	}
+
+	get := func(query string) (int, map[string]interface{}) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions/proj/agents/transitions"+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("sessionId", "proj")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		srv.handleAgentTransitionsV1(rec, req)
+		var body map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return rec.Code, body
+	}
+
+	code, body := get("?since=1h&pane=%251&stats=true")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", code, body)
+	}
+	if body["count"] != float64(1) {
+		t.Errorf("count = %v, want 1", body["count"])
+	}
+	if body["stats"] == nil {
+		t.Error("expected stats in response")
+	}
+
+	code, body = get("")
+	if code != http.StatusOK || body["count"] != float64(3) {
+		t.Errorf("unfiltered: status = %d, count = %v", code, body["count"])
+	}
+
+	if code, _ = get("?since=whenever"); code != http.StatusBadRequest {
+		t.Errorf("invalid since: status = %d, want 400", code)
+	}
+}
@@ -0,0 +1,411 @@
+package serve
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/pipeline"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tracing"
+	"github.com/Dicklesworthstone/ntm/internal/trigger"
+)
+
+// Trigger-specific error codes
+const (
+	ErrCodeTriggerNotFound  = "TRIGGER_NOT_FOUND"
+	ErrCodeInvalidSignature = "INVALID_SIGNATURE"
+	ErrCodeRateLimited      = "RATE_LIMITED"
+	ErrCodeTriggerFailed    = "TRIGGER_FAILED"
+)
+
+// maxTriggerBody caps inbound webhook payloads.
+const maxTriggerBody = 1 << 20
+
+// triggerAction runs a trigger's pipeline or send and returns the pipeline
+// run ID, if any.
+type triggerAction func(ctx context.Context, t *trigger.Trigger, vars map[string]interface{}, projectDir string) (string, error)
+
+// TriggerInfo describes a configured trigger. Secrets are never included.
+type TriggerInfo struct {
+	Name            string            `json:"name"`
+	Description     string            `json:"description,omitempty"`
+	Action          string            `json:"action"`
+	Session         string            `json:"session"`
+	Workflow        string            `json:"workflow,omitempty"`
+	SignatureHeader string            `json:"signature_header"`
+	DeliveryHeader  string            `json:"delivery_header,omitempty"`
+	DeliveryPath    string            `json:"delivery_path,omitempty"`
+	RateLimit       int               `json:"rate_limit_per_minute"`
+	Match           map[string]string `json:"match,omitempty"`
+	Vars            map[string]string `json:"vars,omitempty"`
+}
+
+// registerTriggerRoutes registers the inbound webhook trigger endpoints.
+// Deliveries are authenticated by their HMAC signature rather than the
+// server's auth mode, so webhook senders need no API credentials.
+func (s *Server) registerTriggerRoutes(r chi.Router) {
+	r.Route("/triggers", func(r chi.Router) {
+		r.With(s.RequirePermission(PermReadPipelines)).Get("/", s.handleListTriggers)
+		r.Post("/{name}", s.handleTriggerDelivery)
+		r.With(s.RequirePermission(PermReadPipelines)).Get("/{name}/deliveries", s.handleTriggerDeliveries)
+	})
+}
+
+// isTriggerDelivery reports whether r is a webhook delivery to a trigger.
+func isTriggerDelivery(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+	name, ok := strings.CutPrefix(r.URL.Path, "/api/v1/triggers/")
+	return ok && name != "" && !strings.Contains(name, "/")
+}
+
+// triggerProjectDir is the directory whose .ntm/triggers.toml is served.
+func (s *Server) triggerProjectDir() string {
+	s.mu.Lock()
+	dir := s.projectDir
+	s.mu.Unlock()
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	return dir
+}
+
+// handleListTriggers handles GET /api/v1/triggers
+func (s *Server) handleListTriggers(w http.ResponseWriter, r *http.Request) {
+	reqID := requestIDFromContext(r.Context())
+	cfg, err := trigger.Load(s.triggerProjectDir())
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+
+	triggers := make([]TriggerInfo, 0, len(cfg.Triggers))
+	for i := range cfg.Triggers {
+		t := &cfg.Triggers[i]
+		info := TriggerInfo{
+			Name:            t.Name,
+			Description:     t.Description,
+			Action:          t.Action(),
+			Session:         t.Session,
+			SignatureHeader: t.ResolvedSignatureHeader(),
+			DeliveryPath:    t.DeliveryPath,
+			RateLimit:       t.ResolvedRateLimit(),
+			Match:           t.Match,
+			Vars:            t.Vars,
+		}
+		if t.DeliveryPath == "" {
+			info.DeliveryHeader = t.ResolvedDeliveryHeader()
+		}
+		if t.Pipeline != nil {
+			info.Workflow = t.Pipeline.Workflow
+		}
+		triggers = append(triggers, info)
+	}
+
+	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"triggers": triggers,
+		"count":    len(triggers),
+	}, reqID)
+}
+
+// handleTriggerDeliveries handles GET /api/v1/triggers/{name}/deliveries
+func (s *Server) handleTriggerDeliveries(w http.ResponseWriter, r *http.Request) {
+	reqID := requestIDFromContext(r.Context())
+	if s.stateStore == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeServiceUnavail, "state store not available", nil, reqID)
+		return
+	}
+
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, "limit must be a positive integer", nil, reqID)
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := s.stateStore.ListTriggerDeliveries(chi.URLParam(r, "name"), limit)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []state.TriggerDelivery{}
+	}
+
+	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	}, reqID)
+}
+
+// handleTriggerDelivery handles POST /api/v1/triggers/{name}
+func (s *Server) handleTriggerDelivery(w http.ResponseWriter, r *http.Request) {
+	reqID := requestIDFromContext(r.Context())
+	name := chi.URLParam(r, "name")
+	if s.stateStore == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeServiceUnavail, "state store not available", nil, reqID)
+		return
+	}
+
+	projectDir := s.triggerProjectDir()
+	cfg, err := trigger.Load(projectDir)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+	t := cfg.Get(name)
+	if t == nil {
+		writeErrorResponse(w, http.StatusNotFound, ErrCodeTriggerNotFound, "trigger not found: "+name, nil, reqID)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTriggerBody))
+	if err != nil {
+		writeErrorResponse(w, http.StatusRequestEntityTooLarge, ErrCodeBadRequest, "payload too large or unreadable", nil, reqID)
+		return
+	}
+
+	if err := trigger.VerifySignature(t.ResolvedSecret(), r.Header.Get(t.ResolvedSignatureHeader()), body); err != nil {
+		slog.Warn("trigger signature rejected", "trigger", name, "remote", r.RemoteAddr, "request_id", reqID, "error", err)
+		s.auditTrigger(r, t, "", "rejected", "", err)
+		if errors.Is(err, trigger.ErrNoSecret) {
+			writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeServiceUnavail,
+				fmt.Sprintf("trigger secret is not set (%s)", t.SecretEnv), nil, reqID)
+			return
+		}
+		writeErrorResponse(w, http.StatusUnauthorized, ErrCodeInvalidSignature, err.Error(), nil, reqID)
+		return
+	}
+
+	payload, err := trigger.ParsePayload(body)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
+	}
+	matched, err := payload.Matches(t.Match)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
+	}
+	if !matched {
+		writeSuccessResponse(w, http.StatusAccepted, map[string]interface{}{
+			"trigger": name,
+			"status":  "ignored",
+		}, reqID)
+		return
+	}
+
+	deliveryID, err := t.DeliveryID(r.Header, payload)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
+	}
+	if deliveryID != "" {
+		existing, err := s.stateStore.GetTriggerDelivery(name, deliveryID)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+			return
+		}
+		// A failed delivery may be retried; anything else has run or is running.
+		if existing != nil && existing.Status != state.DeliveryStatusFailed {
+			writeTriggerDuplicate(w, existing, reqID)
+			return
+		}
+	}
+
+	if ok, retryAfter := s.triggerLimiter.Allow(name, t.ResolvedRateLimit()); !ok {
+		secs := int(retryAfter.Round(time.Second) / time.Second)
+		if secs < 1 {
+			secs = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+		writeErrorResponse(w, http.StatusTooManyRequests, ErrCodeRateLimited,
+			fmt.Sprintf("trigger %s is limited to %d deliveries per minute", name, t.ResolvedRateLimit()), nil, reqID)
+		return
+	}
+
+	vars, err := payload.Vars(t.Vars)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
+	}
+	if deliveryID == "" {
+		deliveryID = newDeliveryID()
+	}
+
+	delivery := &state.TriggerDelivery{
+		Trigger:    name,
+		DeliveryID: deliveryID,
+		Action:     t.Action(),
+		SessionID:  t.Session,
+		Vars:       vars,
+	}
+	inserted, err := s.stateStore.RecordTriggerDelivery(delivery)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+	if !inserted {
+		// A concurrent retry recorded or restarted it first.
+		existing, _ := s.stateStore.GetTriggerDelivery(name, deliveryID)
+		if existing == nil {
+			existing = delivery
+		}
+		writeTriggerDuplicate(w, existing, reqID)
+		return
+	}
+
+	runID, actionErr := s.runTrigger(r.Context(), t, vars, projectDir)
+	if err := s.stateStore.FinishTriggerDelivery(delivery.ID, runID, actionErr); err != nil {
+		slog.Error("trigger delivery not recorded", "trigger", name, "delivery_id", deliveryID, "error", err)
+	}
+
+	status := string(state.DeliveryStatusStarted)
+	if actionErr != nil {
+		status = string(state.DeliveryStatusFailed)
+	}
+	s.auditTrigger(r, t, deliveryID, status, runID, actionErr)
+	slog.Info("trigger delivery", "trigger", name, "delivery_id", deliveryID, "action", t.Action(),
+		"status", status, "run_id", runID, "request_id", reqID)
+
+	if actionErr != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeTriggerFailed, actionErr.Error(), map[string]interface{}{
+			"trigger":     name,
+			"delivery_id": deliveryID,
+		}, reqID)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"trigger":     name,
+		"delivery_id": deliveryID,
+		"action":      t.Action(),
+		"session":     t.Session,
+		"status":      status,
+	}
+	if runID != "" {
+		resp["run_id"] = runID
+	}
+	writeSuccessResponse(w, http.StatusAccepted, resp, reqID)
+}
+
+func writeTriggerDuplicate(w http.ResponseWriter, d *state.TriggerDelivery, reqID string) {
+	resp := map[string]interface{}{
+		"trigger":     d.Trigger,
+		"delivery_id": d.DeliveryID,
+		"status":      "duplicate",
+		"original":    d.Status,
+	}
+	if d.RunID != "" {
+		resp["run_id"] = d.RunID
+	}
+	writeSuccessResponse(w, http.StatusOK, resp, reqID)
+}
+
+// executeTrigger is the default triggerAction. Pipelines start in the
+// background and report progress over the pipeline WebSocket topics; sends
+// complete before the delivery is acknowledged.
+func (s *Server) executeTrigger(ctx context.Context, t *trigger.Trigger, vars map[string]interface{}, projectDir string) (string, error) {
+	if t.Send != nil {
+		message, err := t.Send.Render(t.Session, vars)
+		if err != nil {
+			return "", err
+		}
+		out, err := robot.GetSend(robot.SendOptions{
+			Session:     t.Session,
+			Message:     message,
+			Panes:       t.Send.Panes,
+			AgentTypes:  t.Send.AgentTypes,
+			All:         t.Send.All,
+			RequestedBy: "trigger " + t.Name,
+		})
+		if err != nil {
+			return "", err
+		}
+		if !out.Success {
+			return "", errors.New(out.Error)
+		}
+		if len(out.Failed) > 0 {
+			return "", fmt.Errorf("send failed for %d of %d panes: %s", len(out.Failed), len(out.Targets), out.Failed[0].Error)
+		}
+		return "", nil
+	}
+
+	workflow, key, err := pipeline.ResolveWorkflowRef(t.Pipeline.Workflow, "", projectDir)
+	if err != nil {
+		return "", err
+	}
+
+	var out pipeline.PipelineRunOutput
+	if strings.HasPrefix(key, pipeline.BuiltinWorkflowPrefix) {
+		if result := pipeline.Validate(workflow); !result.Valid {
+			msg := "workflow validation failed"
+			if len(result.Errors) > 0 {
+				msg = result.Errors[0].Message
+			}
+			return "", fmt.Errorf("%s: %s", t.Pipeline.Workflow, msg)
+		}
+		out = s.execPipelineInline(ctx, workflow, t.Session, vars, true)
+	} else {
+		out = s.runPipelineWithResult(ctx, pipeline.PipelineRunOptions{
+			WorkflowFile: key,
+			Session:      t.Session,
+			Variables:    vars,
+			Background:   true,
+		})
+	}
+	if !out.Success {
+		return "", errors.New(out.Error)
+	}
+	return out.RunID, nil
+}
+
+// auditTrigger writes a trigger delivery to the session's audit log.
+func (s *Server) auditTrigger(r *http.Request, t *trigger.Trigger, deliveryID, status, runID string, err error) {
+	payload := map[string]interface{}{
+		"trigger":     t.Name,
+		"action":      t.Action(),
+		"delivery_id": deliveryID,
+		"status":      status,
+	}
+	if runID != "" {
+		payload["run_id"] = runID
+	}
+	if err != nil {
+		payload["error"] = err.Error()
+	}
+	metadata := map[string]interface{}{
+		"remote_addr": r.RemoteAddr,
+		"request_id":  requestIDFromContext(r.Context()),
+	}
+	if traceID := tracing.TraceIDFromContext(r.Context()); traceID != "" {
+		metadata["trace_id"] = traceID
+	}
+	_ = audit.LogEvent(t.Session, audit.EventTypeCommand, audit.ActorSystem, "trigger:"+t.Name, payload, metadata)
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("gen-%d", time.Now().UnixNano())
+	}
+	return "gen-" + hex.EncodeToString(b)
+}
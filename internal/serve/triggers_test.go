@@ -0,0 +1,238 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/trigger"
+)
+
+const testTriggers = `
+[[trigger]]
+name = "ci"
+secret = "s3cret"
+delivery_header = "X-GitHub-Delivery"
+session = "proj"
+rate_limit = 2
+
+[trigger.match]
+"$.action" = "completed"
+
+[trigger.vars]
+branch = "$.workflow_run.head_branch"
+run = "$.workflow_run.id"
+
+[trigger.pipeline]
+workflow = "ci-review"
+
+[[trigger]]
+name = "alerts"
+secret_env = "NTM_TEST_ALERTS_SECRET_UNSET"
+session = "proj"
+
+[trigger.send]
+message = "alert: ${vars.title}"
+`
+
+type triggerCall struct {
+	name string
+	vars map[string]interface{}
+}
+
+func setupTriggerServer(t *testing.T) (*Server, *state.Store, *[]triggerCall) {
+	t.Helper()
+	srv, store := setupTestServer(t)
+	srv.projectDir = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(srv.projectDir, ".ntm"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(trigger.Path(srv.projectDir), []byte(testTriggers), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls []triggerCall
+	srv.runTrigger = func(_ context.Context, tr *trigger.Trigger, vars map[string]interface{}, _ string) (string, error) {
+		calls = append(calls, triggerCall{name: tr.Name, vars: vars})
+		if tr.Name == "alerts" {
+			return "", errors.New("session not found")
+		}
+		return "run-1", nil
+	}
+	return srv, store, &calls
+}
+
+func deliver(t *testing.T, srv *Server, name, body, signature string, headers map[string]string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/triggers/"+name, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if signature != "" {
+		req.Header.Set(trigger.DefaultSignatureHeader, signature)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v (%s)", err, rec.Body.String())
+	}
+	return rec.Code, resp
+}
+
+func TestTriggerDeliveryStartsPipeline(t *testing.T) {
+	srv, store, calls := setupTriggerServer(t)
+	body := `{"action": "completed", "workflow_run": {"id": 42, "head_branch": "main"}}`
+	sig := trigger.Sign("s3cret", []byte(body))
+	hdr := map[string]string{"X-GitHub-Delivery": "d-1"}
+
+	code, resp := deliver(t, srv, "ci", body, sig, hdr)
+	if code != http.StatusAccepted || resp["status"] != "started" || resp["run_id"] != "run-1" {
+		t.Fatalf("deliver: status = %d, body = %v", code, resp)
+	}
+	if len(*calls) != 1 {
+		t.Fatalf("calls = %d, want 1", len(*calls))
+	}
+	vars := (*calls)[0].vars
+	if vars["branch"] != "main" || vars["run"] != int64(42) {
+		t.Errorf("vars = %#v", vars)
+	}
+
+	d, err := store.GetTriggerDelivery("ci", "d-1")
+	if err != nil || d == nil || d.Status != state.DeliveryStatusStarted || d.RunID != "run-1" {
+		t.Fatalf("delivery = %+v, %v", d, err)
+	}
+
+	// A redelivery with the same ID is acknowledged but not run again.
+	code, resp = deliver(t, srv, "ci", body, sig, hdr)
+	if code != http.StatusOK || resp["status"] != "duplicate" || resp["run_id"] != "run-1" {
+		t.Errorf("redeliver: status = %d, body = %v", code, resp)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("duplicate delivery ran the action")
+	}
+}
+
+func TestTriggerDeliveryRetriesFailedDelivery(t *testing.T) {
+	srv, store, _ := setupTriggerServer(t)
+	runs := 0
+	srv.runTrigger = func(context.Context, *trigger.Trigger, map[string]interface{}, string) (string, error) {
+		runs++
+		if runs == 1 {
+			return "", errors.New("pipeline busy")
+		}
+		return "run-2", nil
+	}
+	body := `{"action": "completed", "workflow_run": {"id": 7, "head_branch": "main"}}`
+	sig := trigger.Sign("s3cret", []byte(body))
+	hdr := map[string]string{"X-GitHub-Delivery": "d-retry"}
+
+	if code, resp := deliver(t, srv, "ci", body, sig, hdr); code != http.StatusInternalServerError {
+		t.Fatalf("first delivery: status = %d, body = %v", code, resp)
+	}
+
+	// The sender's retry of a failed delivery runs the action again.
+	code, resp := deliver(t, srv, "ci", body, sig, hdr)
+	if code != http.StatusAccepted || resp["status"] != "started" || resp["run_id"] != "run-2" {
+		t.Fatalf("retry: status = %d, body = %v", code, resp)
+	}
+	d, err := store.GetTriggerDelivery("ci", "d-retry")
+	if err != nil || d == nil || d.Status != state.DeliveryStatusStarted || d.Error != "" {
+		t.Fatalf("delivery = %+v, %v", d, err)
+	}
+
+	// Once it has succeeded, further retries are duplicates.
+	if code, resp := deliver(t, srv, "ci", body, sig, hdr); code != http.StatusOK || resp["status"] != "duplicate" {
+		t.Errorf("after success: status = %d, body = %v", code, resp)
+	}
+	if runs != 2 {
+		t.Errorf("runs = %d, want 2", runs)
+	}
+}
+
+func TestTriggerDeliveryRejections(t *testing.T) {
+	srv, _, calls := setupTriggerServer(t)
+	body := `{"action": "completed", "workflow_run": {"head_branch": "main"}}`
+
+	if code, resp := deliver(t, srv, "missing", body, trigger.Sign("s3cret", []byte(body)), nil); code != http.StatusNotFound || resp["error_code"] != ErrCodeTriggerNotFound {
+		t.Errorf("unknown trigger: status = %d, body = %v", code, resp)
+	}
+	if code, resp := deliver(t, srv, "ci", body, "", nil); code != http.StatusUnauthorized || resp["error_code"] != ErrCodeInvalidSignature {
+		t.Errorf("unsigned: status = %d, body = %v", code, resp)
+	}
+	if code, _ := deliver(t, srv, "ci", body, trigger.Sign("wrong", []byte(body)), nil); code != http.StatusUnauthorized {
+		t.Errorf("wrong secret: status = %d", code)
+	}
+	if code, _ := deliver(t, srv, "alerts", `{}`, trigger.Sign("", []byte(`{}`)), nil); code != http.StatusServiceUnavailable {
+		t.Errorf("unset secret env: status = %d", code)
+	}
+
+	other := `{"action": "requested"}`
+	code, resp := deliver(t, srv, "ci", other, trigger.Sign("s3cret", []byte(other)), nil)
+	if code != http.StatusAccepted || resp["status"] != "ignored" {
+		t.Errorf("unmatched: status = %d, body = %v", code, resp)
+	}
+
+	sig := trigger.Sign("s3cret", []byte(body))
+	for i := 0; i < 2; i++ {
+		if code, resp := deliver(t, srv, "ci", body, sig, nil); code != http.StatusAccepted {
+			t.Fatalf("delivery %d: status = %d, body = %v", i, code, resp)
+		}
+	}
+	code, resp = deliver(t, srv, "ci", body, sig, nil)
+	if code != http.StatusTooManyRequests || resp["error_code"] != ErrCodeRateLimited {
+		t.Errorf("over limit: status = %d, body = %v", code, resp)
+	}
+	if len(*calls) != 2 {
+		t.Errorf("calls = %d, want 2", len(*calls))
+	}
+}
+
+func TestTriggerDeliveryBypassesAPIKeyAndRecordsFailures(t *testing.T) {
+	srv, store, _ := setupTriggerServer(t)
+	srv.auth = AuthConfig{Mode: AuthModeAPIKey, APIKey: "k"}
+	t.Setenv("NTM_TEST_ALERTS_SECRET_UNSET", "env-secret")
+
+	body := `{"title": "disk full"}`
+	code, resp := deliver(t, srv, "alerts", body, trigger.Sign("env-secret", []byte(body)), map[string]string{"X-Delivery-ID": "a-1"})
+	if code != http.StatusInternalServerError || resp["error_code"] != ErrCodeTriggerFailed {
+		t.Fatalf("failing action: status = %d, body = %v", code, resp)
+	}
+	d, _ := store.GetTriggerDelivery("alerts", "a-1")
+	if d == nil || d.Status != state.DeliveryStatusFailed || d.Error != "session not found" {
+		t.Errorf("delivery = %+v", d)
+	}
+
+	// Listing still requires the API key.
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/triggers", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("list without key: status = %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/triggers", nil)
+	req.Header.Set("X-API-Key", "k")
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "s3cret") {
+		t.Errorf("list: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/triggers/alerts/deliveries", nil)
+	req.Header.Set("X-API-Key", "k")
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	var list map[string]interface{}
+	_ = json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || list["count"] != float64(1) {
+		t.Errorf("deliveries: status = %d, body = %v", rec.Code, list)
+	}
+}
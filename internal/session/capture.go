@@ -8,8 +8,15 @@ import (
 
 	"github.com/Dicklesworthstone/ntm/internal/audit"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/transcript"
 )
 
+// nativeSessionsFn matches agent panes to the transcripts their CLIs write.
+// Overridable for tests.
+var nativeSessionsFn = func(session string, panes []transcript.Pane) []transcript.Result {
+	return transcript.NewIngester(nil, nil).Sync(session, panes)
+}
+
 // Capture captures the current state of a tmux session.
 func Capture(sessionName string) (state *SessionState, err error) {
 	correlationID := audit.NewCorrelationID()
@@ -58,6 +65,9 @@ func Capture(sessionName string) (state *SessionState, err error) {
 	// Detect working directory from first pane or session
 	cwd := detectWorkDir(sessionName, panes)
 
+	// Record native agent sessions so they can be resumed
+	captureNativeSessions(sessionName, panes, paneStates, cwd)
+
 	// Get git info if in a repo
 	gitBranch, gitRemote, gitCommit := getGitInfo(cwd)
 
@@ -142,6 +152,43 @@ func mapPaneStates(panes []tmux.Pane) []PaneState {
 	return states
 }
 
+// captureNativeSessions fills in each agent pane's native session ID and
+// working directory from its CLI's transcript, when one can be matched.
+func captureNativeSessions(sessionName string, panes []tmux.Pane, states []PaneState, workDir string) {
+	var agentPanes []transcript.Pane
+	for _, p := range panes {
+		if _, ok := transcript.FormatForAgent(string(p.Type)); !ok {
+			continue
+		}
+		agentPanes = append(agentPanes, transcript.Pane{
+			ID:        p.ID,
+			Name:      p.Title,
+			AgentType: string(p.Type),
+			PID:       p.PID,
+			Dir:       workDir,
+		})
+	}
+	if len(agentPanes) == 0 {
+		return
+	}
+
+	snapshots := make(map[string]transcript.Snapshot)
+	for _, r := range nativeSessionsFn(sessionName, agentPanes) {
+		snapshots[r.PaneID] = r.Snapshot
+	}
+	for i := range states {
+		snap, ok := snapshots[states[i].PaneID]
+		if !ok || snap.SessionID == "" {
+			continue
+		}
+		states[i].NativeSessionID = snap.SessionID
+		states[i].Cwd = snap.Cwd
+		if states[i].Cwd == "" {
+			states[i].Cwd = workDir
+		}
+	}
+}
+
 // detectWorkDir attempts to detect the working directory for the session.
 func detectWorkDir(sessionName string, panes []tmux.Pane) string {
 	// Try to get the pane's current path via tmux
@@ -0,0 +1,128 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Dicklesworthstone/ntm/internal/encryption"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+var (
+	// encryptionEnabled indicates whether saved sessions are encrypted.
+	encryptionEnabled bool
+	// encryptKey is the active AES-256 key for writing saved sessions.
+	encryptKey []byte
+	// decryptKeys holds all keyring keys for decryption (includes encryptKey).
+	decryptKeys [][]byte
+	encryptMu   sync.RWMutex
+)
+
+// EncryptionConfig holds resolved encryption keys for saved sessions.
+type EncryptionConfig struct {
+	Enabled     bool
+	EncryptKey  []byte   // Active key for writing files
+	DecryptKeys [][]byte // All keys for reading (keyring)
+}
+
+// SetEncryptionConfig sets the global encryption config for saved session
+// files. Pass nil to disable encryption.
+func SetEncryptionConfig(cfg *EncryptionConfig) {
+	encryptMu.Lock()
+	defer encryptMu.Unlock()
+	if cfg != nil && cfg.Enabled && len(cfg.EncryptKey) > 0 {
+		encryptionEnabled = true
+		encryptKey = make([]byte, len(cfg.EncryptKey))
+		copy(encryptKey, cfg.EncryptKey)
+		decryptKeys = make([][]byte, len(cfg.DecryptKeys))
+		for i, k := range cfg.DecryptKeys {
+			decryptKeys[i] = make([]byte, len(k))
+			copy(decryptKeys[i], k)
+		}
+	} else {
+		encryptionEnabled = false
+		encryptKey = nil
+		decryptKeys = nil
+	}
+}
+
+// GetEncryptionEnabled returns whether encryption is currently enabled.
+func GetEncryptionEnabled() bool {
+	encryptMu.RLock()
+	defer encryptMu.RUnlock()
+	return encryptionEnabled
+}
+
+// encryptFile encrypts serialized session state if encryption is enabled.
+func encryptFile(data []byte) ([]byte, error) {
+	encryptMu.RLock()
+	enabled := encryptionEnabled
+	key := encryptKey
+	encryptMu.RUnlock()
+
+	if !enabled || key == nil {
+		return data, nil
+	}
+	return encryption.EncryptData(key, data)
+}
+
+// decryptFile decrypts a saved session file if needed. Plaintext files are
+// returned as-is for backward compatibility.
+func decryptFile(data []byte) ([]byte, error) {
+	encryptMu.RLock()
+	keys := decryptKeys
+	encryptMu.RUnlock()
+
+	return encryption.DecryptDataWithKeyring(keys, data)
+}
+
+// Rekey re-encrypts every saved session with the active key and returns
+// the number of files rewritten.
+func Rekey() (int, error) {
+	if !GetEncryptionEnabled() {
+		return 0, fmt.Errorf("encryption is not enabled")
+	}
+
+	unlock, err := acquireLock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	dir := StorageDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+
+	rewritten := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExtension) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return rewritten, fmt.Errorf("reading %s: %w", entry.Name(), err)
+		}
+		data, err := decryptFile(raw)
+		if err != nil {
+			return rewritten, fmt.Errorf("decrypting %s: %w", entry.Name(), err)
+		}
+		sealed, err := encryptFile(data)
+		if err != nil {
+			return rewritten, err
+		}
+		if err := util.AtomicWriteFile(path, sealed, 0600); err != nil {
+			return rewritten, fmt.Errorf("writing %s: %w", entry.Name(), err)
+		}
+		rewritten++
+	}
+	return rewritten, nil
+}
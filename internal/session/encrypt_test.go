@@ -0,0 +1,51 @@
+package session
+
+import (
+	"crypto/rand"
+	"os"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/encryption"
+)
+
+func TestEncryptedSessionRekey(t *testing.T) {
+	_, cleanup := setupTestStorage(t)
+	defer cleanup()
+	t.Cleanup(func() { SetEncryptionConfig(nil) })
+
+	oldKey := make([]byte, encryption.KeySize)
+	newKey := make([]byte, encryption.KeySize)
+	rand.Read(oldKey)
+	rand.Read(newKey)
+
+	SetEncryptionConfig(&EncryptionConfig{Enabled: true, EncryptKey: oldKey, DecryptKeys: [][]byte{oldKey}})
+	path, err := Save(createTestState("secret"), SaveOptions{Overwrite: true})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !encryption.IsEncryptedData(raw) {
+		t.Fatal("saved session is not encrypted on disk")
+	}
+	if state, err := Load("secret"); err != nil || state.GitBranch != "main" {
+		t.Fatalf("Load() = %+v, %v", state, err)
+	}
+
+	SetEncryptionConfig(&EncryptionConfig{Enabled: true, EncryptKey: newKey, DecryptKeys: [][]byte{newKey, oldKey}})
+	if n, err := Rekey(); err != nil || n != 1 {
+		t.Fatalf("Rekey() = %d, %v", n, err)
+	}
+
+	SetEncryptionConfig(&EncryptionConfig{Enabled: true, EncryptKey: newKey, DecryptKeys: [][]byte{newKey}})
+	if _, err := Load("secret"); err != nil {
+		t.Errorf("Load() after rekey error = %v", err)
+	}
+
+	SetEncryptionConfig(nil)
+	if _, err := Load("secret"); err == nil {
+		t.Error("Load() without a key succeeded on an encrypted session")
+	}
+}
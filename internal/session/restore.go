@@ -12,6 +12,7 @@ import (
 
 	"github.com/Dicklesworthstone/ntm/internal/audit"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/transcript"
 )
 
 // Restore recreates a session from saved state.
@@ -176,7 +177,34 @@ func Restore(state *SessionState, opts RestoreOptions) (err error) {
 
 // RestoreAgents launches the agents in the restored session.
 // This is separated from Restore to allow for customization.
-func RestoreAgents(sessionName string, state *SessionState, cmds AgentCommands) (err error) {
+func RestoreAgents(sessionName string, state *SessionState, cmds AgentCommands) error {
+	_, err := restoreAgents(sessionName, state, cmds, false)
+	return err
+}
+
+// NativeRestoreResult reports how RestoreAgentsNative launched agent panes.
+type NativeRestoreResult struct {
+	Resumed []string // Pane IDs relaunched into their native session
+	Fresh   []string // Pane IDs started fresh because no native session remains
+}
+
+// nativeExistsFn reports whether an agent CLI can still resume a native
+// session. Overridable for tests.
+var nativeExistsFn = func(format transcript.Format, cwd, sessionID string) bool {
+	return transcript.DefaultDirs().SessionExists(format, cwd, sessionID)
+}
+
+// RestoreAgentsNative launches the agents like RestoreAgents, but resumes
+// each agent's own conversation with its CLI's resume flag, in the pane's
+// original working directory. Agents whose native session is unknown or no
+// longer on disk start fresh and are listed in Fresh so the caller can hand
+// them context another way.
+func RestoreAgentsNative(sessionName string, state *SessionState, cmds AgentCommands) (*NativeRestoreResult, error) {
+	return restoreAgents(sessionName, state, cmds, true)
+}
+
+func restoreAgents(sessionName string, state *SessionState, cmds AgentCommands, native bool) (result *NativeRestoreResult, err error) {
+	result = &NativeRestoreResult{}
 	correlationID := audit.NewCorrelationID()
 	auditStart := time.Now()
 	attempted := 0
@@ -189,6 +217,7 @@ func RestoreAgents(sessionName string, state *SessionState, cmds AgentCommands)
 		"phase":          "start",
 		"session":        sessionName,
 		"agents_planned": planned,
+		"native":         native,
 		"correlation_id": correlationID,
 	}, nil)
 	defer func() {
@@ -198,6 +227,7 @@ func RestoreAgents(sessionName string, state *SessionState, cmds AgentCommands)
 			"agents_planned":   planned,
 			"agents_attempted": attempted,
 			"agents_launched":  launched,
+			"agents_resumed":   len(result.Resumed),
 			"success":          err == nil,
 			"duration_ms":      time.Since(auditStart).Milliseconds(),
 			"correlation_id":   correlationID,
@@ -210,7 +240,7 @@ func RestoreAgents(sessionName string, state *SessionState, cmds AgentCommands)
 
 	panes, err := tmux.GetPanes(sessionName)
 	if err != nil {
-		return fmt.Errorf("getting panes: %w", err)
+		return result, fmt.Errorf("getting panes: %w", err)
 	}
 
 	for i, paneState := range state.Panes {
@@ -231,6 +261,14 @@ func RestoreAgents(sessionName string, state *SessionState, cmds AgentCommands)
 
 		attempted++
 
+		workDir := state.WorkDir
+		resumed := false
+		if native {
+			if cmd, dir, ok := nativeResumeCommand(paneState, agentCmd, workDir); ok {
+				agentCmd, workDir, resumed = cmd, dir, true
+			}
+		}
+
 		// Launch agent
 		safeAgentCmd, err := tmux.SanitizePaneCommand(agentCmd)
 		if err != nil {
@@ -244,7 +282,7 @@ func RestoreAgents(sessionName string, state *SessionState, cmds AgentCommands)
 			continue
 		}
 
-		cmd, err := tmux.BuildPaneCommand(state.WorkDir, safeAgentCmd)
+		cmd, err := tmux.BuildPaneCommand(workDir, safeAgentCmd)
 		if err != nil {
 			_ = audit.LogEvent(sessionName, audit.EventTypeError, audit.ActorSystem, "agent.restore", map[string]interface{}{
 				"agent_type":     paneState.AgentType,
@@ -268,15 +306,48 @@ func RestoreAgents(sessionName string, state *SessionState, cmds AgentCommands)
 			continue
 		}
 		launched++
+		if resumed {
+			result.Resumed = append(result.Resumed, panes[i].ID)
+		} else if native {
+			result.Fresh = append(result.Fresh, panes[i].ID)
+		}
 		_ = audit.LogEvent(sessionName, audit.EventTypeSpawn, audit.ActorSystem, "agent.restore", map[string]interface{}{
 			"agent_type":     paneState.AgentType,
 			"pane_index":     paneState.Index,
 			"pane_title":     paneState.Title,
+			"resumed":        resumed,
 			"correlation_id": correlationID,
 		}, nil)
 	}
 
-	return nil
+	return result, nil
+}
+
+// nativeResumeCommand returns the command and directory that resume the
+// pane's native session, or ok=false if it cannot be resumed.
+func nativeResumeCommand(p PaneState, agentCmd, workDir string) (cmd, dir string, ok bool) {
+	if p.NativeSessionID == "" {
+		return "", "", false
+	}
+	format, known := transcript.FormatForAgent(p.AgentType)
+	if !known {
+		return "", "", false
+	}
+	dir = p.Cwd
+	if dir == "" {
+		dir = workDir
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", "", false
+	}
+	if !nativeExistsFn(format, dir, p.NativeSessionID) {
+		return "", "", false
+	}
+	cmd, err := transcript.ResumeCommand(p.AgentType, agentCmd, p.NativeSessionID)
+	if err != nil {
+		return "", "", false
+	}
+	return cmd, dir, true
 }
 
 // getAgentCommand returns the command for an agent type.
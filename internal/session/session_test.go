@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/transcript"
 )
 
 // --- AgentConfig Tests ---
@@ -823,3 +824,63 @@ func TestMapPaneStates(t *testing.T) {
 		}
 	})
 }
+
+// --- Native Session Tests ---
+
+func TestCaptureNativeSessions(t *testing.T) {
+	orig := nativeSessionsFn
+	defer func() { nativeSessionsFn = orig }()
+
+	var asked []transcript.Pane
+	nativeSessionsFn = func(session string, panes []transcript.Pane) []transcript.Result {
+		asked = panes
+		return []transcript.Result{
+			{PaneID: "%2", Snapshot: transcript.Snapshot{SessionID: "claude-sess", Cwd: "/work/sub"}},
+			{PaneID: "%3", Snapshot: transcript.Snapshot{SessionID: "gemini-sess"}},
+		}
+	}
+
+	panes := []tmux.Pane{
+		{ID: "%1", Type: tmux.AgentUser},
+		{ID: "%2", Type: tmux.AgentClaude},
+		{ID: "%3", Type: tmux.AgentGemini},
+	}
+	states := mapPaneStates(panes)
+	captureNativeSessions("proj", panes, states, "/work")
+
+	if len(asked) != 2 {
+		t.Errorf("asked about %d panes, want only the 2 agent panes", len(asked))
+	}
+	if states[0].NativeSessionID != "" {
+		t.Errorf("user pane got native session %q", states[0].NativeSessionID)
+	}
+	if states[1].NativeSessionID != "claude-sess" || states[1].Cwd != "/work/sub" {
+		t.Errorf("claude pane = %+v", states[1])
+	}
+	if states[2].NativeSessionID != "gemini-sess" || states[2].Cwd != "/work" {
+		t.Errorf("gemini pane = %+v, want cwd defaulting to the work dir", states[2])
+	}
+}
+
+func TestNativeResumeCommand(t *testing.T) {
+	orig := nativeExistsFn
+	defer func() { nativeExistsFn = orig }()
+	nativeExistsFn = func(format transcript.Format, cwd, sessionID string) bool {
+		return sessionID == "live"
+	}
+	dir := t.TempDir()
+
+	cmd, gotDir, ok := nativeResumeCommand(PaneState{AgentType: "cc", NativeSessionID: "live", Cwd: dir}, "claude", "/elsewhere")
+	if !ok || cmd != "claude --resume live" || gotDir != dir {
+		t.Errorf("live session = %q, %q, %v", cmd, gotDir, ok)
+	}
+	if _, _, ok := nativeResumeCommand(PaneState{AgentType: "cc", NativeSessionID: "gone", Cwd: dir}, "claude", dir); ok {
+		t.Error("expected missing native session to fall back")
+	}
+	if _, _, ok := nativeResumeCommand(PaneState{AgentType: "cc"}, "claude", dir); ok {
+		t.Error("expected pane without native session to fall back")
+	}
+	if _, _, ok := nativeResumeCommand(PaneState{AgentType: "cc", NativeSessionID: "live", Cwd: filepath.Join(dir, "missing")}, "claude", dir); ok {
+		t.Error("expected missing cwd to fall back")
+	}
+}
@@ -65,6 +65,11 @@ type PaneState struct {
 	Width       int    `json:"width,omitempty"`   // Pane width
 	Height      int    `json:"height,omitempty"`  // Pane height
 	PaneID      string `json:"pane_id,omitempty"` // Original pane ID
+	// Cwd is the agent's working directory, where its native session lives.
+	Cwd string `json:"cwd,omitempty"`
+	// NativeSessionID is the agent CLI's own session ID (Claude Code, Codex,
+	// Gemini CLI), used by 'ntm restore --native' to resume the conversation.
+	NativeSessionID string `json:"native_session_id,omitempty"`
 }
 
 // ConfigSnapshot captures relevant config at save time.
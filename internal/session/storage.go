@@ -113,6 +113,12 @@ func Save(state *SessionState, opts SaveOptions) (path string, err error) {
 		return "", fmt.Errorf("failed to serialize session state: %w", err)
 	}
 
+	// Encrypt if enabled
+	data, err = encryptFile(data)
+	if err != nil {
+		return "", fmt.Errorf("encrypting session state: %w", err)
+	}
+
 	// Atomic write using utility function
 	if err := util.AtomicWriteFile(path, data, 0600); err != nil {
 		return "", fmt.Errorf("writing session file: %w", err)
@@ -164,6 +170,11 @@ func Load(name string) (state *SessionState, err error) {
 		return nil, fmt.Errorf("failed to read session file: %w", err)
 	}
 
+	data, err = decryptFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt session file: %w", err)
+	}
+
 	var parsed SessionState
 	if err := json.Unmarshal(data, &parsed); err != nil {
 		return nil, fmt.Errorf("failed to parse session file: %w", err)
@@ -25,6 +25,7 @@ var CommandClassification = map[string]CommandRequirement{
 	"init":       RequirePhase1Only,
 	"completion": RequirePhase1Only,
 	"upgrade":    RequirePhase1Only,
+	"emit":       RequirePhase1Only, // hooks agent emit: runs inline with every agent tool call
 
 	// Config-only commands
 	"config":   RequireConfig,
@@ -33,6 +34,7 @@ var CommandClassification = map[string]CommandRequirement{
 	"personas": RequireConfig,
 	"template": RequireConfig,
 	"scrub":    RequireConfig,
+	"fleet":    RequireConfig,
 
 	// Full startup commands
 	"spawn":           RequireFullStartup,
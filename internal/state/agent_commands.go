@@ -0,0 +1,158 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ========================
+// Agent Commands
+// ========================
+
+// AgentCommand is one tool call reported by an agent CLI hook: a shell
+// command with its exit code, or a file edit.
+type AgentCommand struct {
+	ID         int64      `json:"id"`
+	SessionID  string     `json:"session"`
+	PaneID     string     `json:"pane_id"`
+	AgentType  string     `json:"agent_type,omitempty"`
+	Tool       string     `json:"tool"`
+	Command    string     `json:"command,omitempty"`
+	ExitCode   *int       `json:"exit_code,omitempty"`
+	Files      []string   `json:"files,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time  `json:"finished_at"`
+}
+
+// Duration returns how long the call ran, or 0 if its start was not seen.
+func (c AgentCommand) Duration() time.Duration {
+	if c.StartedAt == nil {
+		return 0
+	}
+	return c.FinishedAt.Sub(*c.StartedAt)
+}
+
+// AgentCommandQuery filters agent command queries. Zero values match everything.
+type AgentCommandQuery struct {
+	SessionID string
+	PaneID    string
+	Since     time.Time
+	Limit     int // Most recent N commands (0 = no limit)
+}
+
+const agentCommandColumns = `id, session_id, pane_id, COALESCE(agent_type, ''), tool, COALESCE(command, ''),
+	exit_code, COALESCE(files, ''), started_at, finished_at`
+
+// RecordAgentCommand persists a tool call reported by an agent hook.
+func (s *Store) RecordAgentCommand(c *AgentCommand) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c.FinishedAt.IsZero() {
+		c.FinishedAt = time.Now().UTC()
+	}
+	var exitCode, startedAt interface{}
+	if c.ExitCode != nil {
+		exitCode = *c.ExitCode
+	}
+	if c.StartedAt != nil {
+		startedAt = c.StartedAt.UTC()
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO agent_commands (session_id, pane_id, agent_type, tool, command, exit_code, files, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.SessionID, c.PaneID, nullString(c.AgentType), c.Tool, nullString(c.Command), exitCode,
+		nullString(strings.Join(c.Files, "\n")), startedAt, c.FinishedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("record agent command: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get agent command id: %w", err)
+	}
+	c.ID = id
+	return nil
+}
+
+// ListAgentCommands returns commands matching q in chronological order.
+// With a Limit, the most recent Limit commands are returned.
+func (s *Store) ListAgentCommands(q AgentCommandQuery) ([]AgentCommand, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if q.SessionID != "" {
+		conds = append(conds, "session_id = ?")
+		args = append(args, q.SessionID)
+	}
+	if q.PaneID != "" {
+		conds = append(conds, "pane_id = ?")
+		args = append(args, q.PaneID)
+	}
+	if !q.Since.IsZero() {
+		conds = append(conds, "finished_at >= ?")
+		args = append(args, q.Since.UTC())
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	query := `SELECT ` + agentCommandColumns + ` FROM agent_commands` + where + ` ORDER BY finished_at DESC, id DESC`
+	if q.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, q.Limit)
+	}
+
+	// #nosec G202 -- where clause is internally generated, values are bound
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list agent commands: %w", err)
+	}
+	defer rows.Close()
+
+	commands, err := scanAgentCommands(rows)
+	if err != nil {
+		return nil, err
+	}
+	// Reverse into chronological order.
+	for i, j := 0, len(commands)-1; i < j; i, j = i+1, j-1 {
+		commands[i], commands[j] = commands[j], commands[i]
+	}
+	return commands, nil
+}
+
+func scanAgentCommands(rows *sql.Rows) ([]AgentCommand, error) {
+	var commands []AgentCommand
+	for rows.Next() {
+		var (
+			c         AgentCommand
+			exitCode  sql.NullInt64
+			files     string
+			startedAt sql.NullTime
+		)
+		if err := rows.Scan(&c.ID, &c.SessionID, &c.PaneID, &c.AgentType, &c.Tool, &c.Command,
+			&exitCode, &files, &startedAt, &c.FinishedAt); err != nil {
+			return nil, fmt.Errorf("scan agent command: %w", err)
+		}
+		if exitCode.Valid {
+			code := int(exitCode.Int64)
+			c.ExitCode = &code
+		}
+		if files != "" {
+			c.Files = strings.Split(files, "\n")
+		}
+		if startedAt.Valid {
+			t := startedAt.Time
+			c.StartedAt = &t
+		}
+		commands = append(commands, c)
+	}
+	return commands, rows.Err()
+}
@@ -0,0 +1,50 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgentCommands(t *testing.T) {
+	store := testStore(t)
+
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	started := base.Add(-2 * time.Second)
+	zero, one := 0, 1
+	commands := []*AgentCommand{
+		{SessionID: "proj", PaneID: "%1", AgentType: "cc", Tool: "Bash", Command: "go test ./...", ExitCode: &one, StartedAt: &started, FinishedAt: base},
+		{SessionID: "proj", PaneID: "%1", AgentType: "cc", Tool: "Edit", Files: []string{"a.go", "b.go"}, FinishedAt: base.Add(time.Minute)},
+		{SessionID: "proj", PaneID: "%2", AgentType: "gmi", Tool: "run_shell_command", Command: "ls", ExitCode: &zero, FinishedAt: base.Add(2 * time.Minute)},
+		{SessionID: "other", PaneID: "%9", Tool: "Bash", Command: "true", FinishedAt: base},
+	}
+	for _, c := range commands {
+		if err := store.RecordAgentCommand(c); err != nil {
+			t.Fatalf("RecordAgentCommand: %v", err)
+		}
+	}
+
+	got, err := store.ListAgentCommands(AgentCommandQuery{SessionID: "proj"})
+	if err != nil || len(got) != 3 {
+		t.Fatalf("ListAgentCommands = %+v, %v", got, err)
+	}
+	first := got[0]
+	if first.Command != "go test ./..." || first.ExitCode == nil || *first.ExitCode != 1 || first.Duration() != 2*time.Second {
+		t.Errorf("first command = %+v", first)
+	}
+	if len(got[1].Files) != 2 || got[1].ExitCode != nil || got[1].StartedAt != nil {
+		t.Errorf("edit command = %+v", got[1])
+	}
+
+	latest, err := store.ListAgentCommands(AgentCommandQuery{SessionID: "proj", Limit: 2})
+	if err != nil || len(latest) != 2 || latest[1].Command != "ls" {
+		t.Errorf("latest = %+v, %v", latest, err)
+	}
+	pane, err := store.ListAgentCommands(AgentCommandQuery{PaneID: "%2"})
+	if err != nil || len(pane) != 1 || pane[0].AgentType != "gmi" {
+		t.Errorf("pane = %+v, %v", pane, err)
+	}
+	since, err := store.ListAgentCommands(AgentCommandQuery{SessionID: "proj", Since: base.Add(30 * time.Second)})
+	if err != nil || len(since) != 2 {
+		t.Errorf("since = %+v, %v", since, err)
+	}
+}
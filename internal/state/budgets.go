@@ -0,0 +1,200 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ========================
+// Spend Budgets
+// ========================
+
+// BudgetScope is what a spend budget caps.
+type BudgetScope string
+
+const (
+	BudgetScopeSession   BudgetScope = "session"    // Cumulative spend of one session (or every session)
+	BudgetScopeAgentType BudgetScope = "agent_type" // Spend of one agent type per local day
+	BudgetScopeDaily     BudgetScope = "daily"      // All spend per local day
+)
+
+// Valid reports whether the scope is known.
+func (s BudgetScope) Valid() bool {
+	switch s {
+	case BudgetScopeSession, BudgetScopeAgentType, BudgetScopeDaily:
+		return true
+	}
+	return false
+}
+
+// Budget is a spend cap set with 'ntm budget set'.
+type Budget struct {
+	Scope     BudgetScope `json:"scope"`
+	Target    string      `json:"target,omitempty"`
+	LimitUSD  float64     `json:"limit_usd"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// BudgetSpend is one agent's spend on one local day.
+type BudgetSpend struct {
+	Day       string    `json:"day"`
+	SessionID string    `json:"session"`
+	Agent     string    `json:"agent"`
+	AgentType string    `json:"agent_type,omitempty"`
+	SpentUSD  float64   `json:"spent_usd"`
+	LastUSD   float64   `json:"last_usd"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetBudget creates or replaces the budget for a scope and target.
+func (s *Store) SetBudget(b *Budget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b.UpdatedAt.IsZero() {
+		b.UpdatedAt = time.Now().UTC()
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO budgets (scope, target, limit_usd, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (scope, target) DO UPDATE SET limit_usd = excluded.limit_usd, updated_at = excluded.updated_at`,
+		b.Scope, b.Target, b.LimitUSD, b.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("set budget: %w", err)
+	}
+	return nil
+}
+
+// DeleteBudget removes a budget. It reports false if there was none.
+func (s *Store) DeleteBudget(scope BudgetScope, target string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM budgets WHERE scope = ? AND target = ?`, scope, target)
+	if err != nil {
+		return false, fmt.Errorf("delete budget: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// ListBudgets returns every stored budget ordered by scope and target.
+func (s *Store) ListBudgets() ([]Budget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT scope, target, limit_usd, updated_at FROM budgets ORDER BY scope, target`)
+	if err != nil {
+		return nil, fmt.Errorf("list budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var budgets []Budget
+	for rows.Next() {
+		var b Budget
+		if err := rows.Scan(&b.Scope, &b.Target, &b.LimitUSD, &b.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan budget: %w", err)
+		}
+		budgets = append(budgets, b)
+	}
+	return budgets, rows.Err()
+}
+
+// RecordBudgetSpend records an agent's cumulative tracked cost on a local
+// day and returns the increase attributed to that day. A cumulative cost
+// below the previous observation means the tracker restarted, so the whole
+// amount counts as new spend. When pricing (the catalog version that priced
+// the cost) differs from the previous observation, the cumulative cost was
+// recomputed at new prices: it becomes the new baseline and nothing is
+// attributed, so a repricing is never mistaken for a restart.
+func (s *Store) RecordBudgetSpend(day, session, agent, agentType, pricing string, cumulativeUSD float64) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var last sql.NullFloat64
+	var lastPricing sql.NullString
+	err = tx.QueryRow(`
+		SELECT last_usd, pricing FROM budget_spend WHERE session_id = ? AND agent = ?
+		ORDER BY day DESC LIMIT 1`, session, agent).Scan(&last, &lastPricing)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("read budget spend: %w", err)
+	}
+	delta := cumulativeUSD
+	switch {
+	case !last.Valid:
+	case lastPricing.String != pricing:
+		delta = 0
+	case cumulativeUSD >= last.Float64:
+		delta = cumulativeUSD - last.Float64
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO budget_spend (day, session_id, agent, agent_type, spent_usd, last_usd, pricing, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (day, session_id, agent) DO UPDATE SET
+			spent_usd = spent_usd + excluded.spent_usd,
+			last_usd = excluded.last_usd,
+			pricing = excluded.pricing,
+			agent_type = CASE WHEN excluded.agent_type = '' THEN agent_type ELSE excluded.agent_type END,
+			updated_at = excluded.updated_at`,
+		day, session, agent, agentType, delta, cumulativeUSD, pricing, time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("record budget spend: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit budget spend: %w", err)
+	}
+	return delta, nil
+}
+
+// LastBudgetSpend returns the most recent cumulative cost recorded for an
+// agent and the pricing it was recorded at. ok is false if the agent has
+// never been recorded.
+func (s *Store) LastBudgetSpend(session, agent string) (lastUSD float64, pricing string, ok bool, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var p sql.NullString
+	err = s.db.QueryRow(`
+		SELECT last_usd, pricing FROM budget_spend WHERE session_id = ? AND agent = ?
+		ORDER BY day DESC LIMIT 1`, session, agent).Scan(&lastUSD, &p)
+	if err == sql.ErrNoRows {
+		return 0, "", false, nil
+	}
+	if err != nil {
+		return 0, "", false, fmt.Errorf("read budget spend: %w", err)
+	}
+	return lastUSD, p.String, true, nil
+}
+
+// ListBudgetSpend returns every agent's spend on a local day.
+func (s *Store) ListBudgetSpend(day string) ([]BudgetSpend, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`
+		SELECT day, session_id, agent, agent_type, spent_usd, last_usd, updated_at
+		FROM budget_spend WHERE day = ? ORDER BY session_id, agent`, day)
+	if err != nil {
+		return nil, fmt.Errorf("list budget spend: %w", err)
+	}
+	defer rows.Close()
+
+	var spend []BudgetSpend
+	for rows.Next() {
+		var b BudgetSpend
+		if err := rows.Scan(&b.Day, &b.SessionID, &b.Agent, &b.AgentType, &b.SpentUSD, &b.LastUSD, &b.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan budget spend: %w", err)
+		}
+		spend = append(spend, b)
+	}
+	return spend, rows.Err()
+}
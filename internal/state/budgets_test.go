@@ -0,0 +1,105 @@
+package state
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBudgetsCRUD(t *testing.T) {
+	store := testStore(t)
+
+	if err := store.SetBudget(&Budget{Scope: BudgetScopeDaily, LimitUSD: 50}); err != nil {
+		t.Fatalf("SetBudget: %v", err)
+	}
+	if err := store.SetBudget(&Budget{Scope: BudgetScopeSession, Target: "proj", LimitUSD: 10}); err != nil {
+		t.Fatalf("SetBudget: %v", err)
+	}
+	// Setting again replaces the limit.
+	if err := store.SetBudget(&Budget{Scope: BudgetScopeSession, Target: "proj", LimitUSD: 20}); err != nil {
+		t.Fatalf("SetBudget: %v", err)
+	}
+
+	budgets, err := store.ListBudgets()
+	if err != nil || len(budgets) != 2 {
+		t.Fatalf("ListBudgets = %+v, %v", budgets, err)
+	}
+	if budgets[1].Scope != BudgetScopeSession || budgets[1].Target != "proj" || budgets[1].LimitUSD != 20 {
+		t.Errorf("unexpected session budget: %+v", budgets[1])
+	}
+
+	if ok, err := store.DeleteBudget(BudgetScopeSession, "proj"); err != nil || !ok {
+		t.Fatalf("DeleteBudget = %v, %v", ok, err)
+	}
+	if ok, err := store.DeleteBudget(BudgetScopeSession, "proj"); err != nil || ok {
+		t.Fatalf("second DeleteBudget = %v, %v, want false", ok, err)
+	}
+}
+
+func TestRecordBudgetSpend(t *testing.T) {
+	store := testStore(t)
+
+	steps := []struct {
+		day        string
+		cumulative float64
+		wantDelta  float64
+		pricing    string
+	}{
+		{"2026-01-01", 1.0, 1.0, ""},
+		{"2026-01-01", 2.5, 1.5, ""},
+		{"2026-01-01", 2.5, 0, ""},
+		// The next day only counts spend since the last observation.
+		{"2026-01-02", 3.0, 0.5, ""},
+		// A tracker restart counts the new cumulative cost in full.
+		{"2026-01-02", 0.25, 0.25, ""},
+		// A new pricing catalog rebases without counting anything.
+		{"2026-01-02", 0.2, 0, "v2"},
+		{"2026-01-02", 0.3, 0.1, "v2"},
+	}
+	for i, step := range steps {
+		delta, err := store.RecordBudgetSpend(step.day, "proj", "proj__cc_1", "cc", step.pricing, step.cumulative)
+		if err != nil {
+			t.Fatalf("step %d: RecordBudgetSpend: %v", i, err)
+		}
+		if math.Abs(delta-step.wantDelta) > 1e-9 {
+			t.Errorf("step %d: delta = %v, want %v", i, delta, step.wantDelta)
+		}
+	}
+	if _, err := store.RecordBudgetSpend("2026-01-02", "proj", "proj__cod_1", "cod", "", 4); err != nil {
+		t.Fatalf("RecordBudgetSpend: %v", err)
+	}
+
+	day1, err := store.ListBudgetSpend("2026-01-01")
+	if err != nil || len(day1) != 1 || math.Abs(day1[0].SpentUSD-2.5) > 1e-9 {
+		t.Fatalf("ListBudgetSpend(day1) = %+v, %v", day1, err)
+	}
+	day2, err := store.ListBudgetSpend("2026-01-02")
+	if err != nil || len(day2) != 2 {
+		t.Fatalf("ListBudgetSpend(day2) = %+v, %v", day2, err)
+	}
+	if math.Abs(day2[0].SpentUSD-0.85) > 1e-9 || day2[0].AgentType != "cc" || day2[0].LastUSD != 0.3 {
+		t.Errorf("unexpected cc spend: %+v", day2[0])
+	}
+}
+
+func TestFindLatestApproval(t *testing.T) {
+	store := testStore(t)
+
+	if got, err := store.FindLatestApproval("budget_override", "daily@2026-01-01"); err != nil || got != nil {
+		t.Fatalf("FindLatestApproval(empty) = %v, %v", got, err)
+	}
+	base := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	for i, id := range []string{"appr-old", "appr-new"} {
+		err := store.CreateApproval(&Approval{
+			ID: id, Action: "budget_override", Resource: "daily@2026-01-01", RequestedBy: "test",
+			CreatedAt: base.Add(time.Duration(i) * time.Minute), ExpiresAt: base.Add(time.Hour), Status: ApprovalPending,
+		})
+		if err != nil {
+			t.Fatalf("CreateApproval: %v", err)
+		}
+	}
+	got, err := store.FindLatestApproval("budget_override", "daily@2026-01-01")
+	if err != nil || got == nil || got.ID != "appr-new" {
+		t.Fatalf("FindLatestApproval = %+v, %v, want appr-new", got, err)
+	}
+}
@@ -0,0 +1,256 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ========================
+// Merge Queue
+// ========================
+
+// MergeStatus is the state of a merge queue entry.
+type MergeStatus string
+
+const (
+	MergeStatusQueued      MergeStatus = "queued"       // Waiting for 'ntm merge-queue run'
+	MergeStatusRunning     MergeStatus = "running"      // Claimed by a runner
+	MergeStatusMerged      MergeStatus = "merged"       // Target fast-forwarded to the rebased branch
+	MergeStatusConflict    MergeStatus = "conflict"     // Rebase onto the target conflicted
+	MergeStatusTestsFailed MergeStatus = "tests_failed" // Rebased cleanly but the test command failed
+	MergeStatusFailed      MergeStatus = "failed"       // Git or infrastructure error
+)
+
+// Done reports whether the entry has finished processing.
+func (s MergeStatus) Done() bool {
+	return s != MergeStatusQueued && s != MergeStatusRunning
+}
+
+// MergeRequest is an agent branch in the merge queue.
+type MergeRequest struct {
+	ID           int64       `json:"id"`
+	ProjectPath  string      `json:"project_path"`
+	SessionID    string      `json:"session"`
+	Agent        string      `json:"agent"`
+	Branch       string      `json:"branch"`
+	Target       string      `json:"target,omitempty"`
+	Status       MergeStatus `json:"status"`
+	BaseCommit   string      `json:"base_commit,omitempty"`
+	MergedCommit string      `json:"merged_commit,omitempty"`
+	Conflicts    []string    `json:"conflicts,omitempty"`
+	Output       string      `json:"output,omitempty"`
+	Notified     bool        `json:"notified"`
+	EnqueuedAt   time.Time   `json:"enqueued_at"`
+	StartedAt    *time.Time  `json:"started_at,omitempty"`
+	FinishedAt   *time.Time  `json:"finished_at,omitempty"`
+}
+
+// MergeQuery filters merge queue queries. Zero values match everything.
+type MergeQuery struct {
+	ProjectPath string
+	SessionID   string
+	Statuses    []MergeStatus // Any of these statuses
+}
+
+const mergeColumns = `id, project_path, session_id, agent, branch, target, status, COALESCE(base_commit, ''),
+	COALESCE(merged_commit, ''), COALESCE(conflicts, ''), COALESCE(output, ''), notified, enqueued_at, started_at, finished_at`
+
+// EnqueueMerge appends a branch to the merge queue. A branch that is already
+// queued or running cannot be queued again.
+func (s *Store) EnqueueMerge(r *MergeRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var existing int64
+	var status MergeStatus
+	err := s.db.QueryRow(`SELECT id, status FROM merge_queue WHERE project_path = ? AND branch = ? AND status IN (?, ?)`,
+		r.ProjectPath, r.Branch, MergeStatusQueued, MergeStatusRunning).Scan(&existing, &status)
+	if err == nil {
+		return fmt.Errorf("branch %s is already in the merge queue (#%d, %s; 'ntm merge-queue remove %d' drops it)",
+			r.Branch, existing, status, existing)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("enqueue merge: %w", err)
+	}
+
+	if r.EnqueuedAt.IsZero() {
+		r.EnqueuedAt = time.Now().UTC()
+	}
+	r.Status = MergeStatusQueued
+
+	result, err := s.db.Exec(`
+		INSERT INTO merge_queue (project_path, session_id, agent, branch, target, status, enqueued_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		r.ProjectPath, r.SessionID, r.Agent, r.Branch, r.Target, r.Status, r.EnqueuedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("enqueue merge: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get merge request id: %w", err)
+	}
+	r.ID = id
+	return nil
+}
+
+// ListMergeRequests returns entries matching q in queue order.
+func (s *Store) ListMergeRequests(q MergeQuery) ([]MergeRequest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if q.ProjectPath != "" {
+		conds = append(conds, "project_path = ?")
+		args = append(args, q.ProjectPath)
+	}
+	if q.SessionID != "" {
+		conds = append(conds, "session_id = ?")
+		args = append(args, q.SessionID)
+	}
+	if len(q.Statuses) > 0 {
+		conds = append(conds, "status IN (?"+strings.Repeat(", ?", len(q.Statuses)-1)+")")
+		for _, st := range q.Statuses {
+			args = append(args, st)
+		}
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	// #nosec G202 -- where clause is internally generated, values are bound
+	rows, err := s.db.Query(`SELECT `+mergeColumns+` FROM merge_queue`+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list merge requests: %w", err)
+	}
+	defer rows.Close()
+	return scanMergeRequests(rows)
+}
+
+// NextMergeRequest returns the oldest queued entry for a project, or nil.
+func (s *Store) NextMergeRequest(projectPath string) (*MergeRequest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT `+mergeColumns+` FROM merge_queue
+		WHERE project_path = ? AND status = ? ORDER BY id LIMIT 1`, projectPath, MergeStatusQueued)
+	if err != nil {
+		return nil, fmt.Errorf("next merge request: %w", err)
+	}
+	defer rows.Close()
+
+	requests, err := scanMergeRequests(rows)
+	if err != nil || len(requests) == 0 {
+		return nil, err
+	}
+	return &requests[0], nil
+}
+
+// ClaimMergeRequest marks a queued entry as running, reporting false if
+// another runner claimed it first.
+func (s *Store) ClaimMergeRequest(id int64, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`UPDATE merge_queue SET status = ?, started_at = ? WHERE id = ? AND status = ?`,
+		MergeStatusRunning, at.UTC(), id, MergeStatusQueued)
+	if err != nil {
+		return false, fmt.Errorf("claim merge request: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+// RequeueStaleMergeRequests returns a project's running entries that were
+// claimed before startedBefore to the queue, so entries whose runner died
+// are picked up again. It returns how many were requeued.
+func (s *Store) RequeueStaleMergeRequests(projectPath string, startedBefore time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`UPDATE merge_queue SET status = ?, started_at = NULL
+		WHERE project_path = ? AND status = ? AND started_at < ?`,
+		MergeStatusQueued, projectPath, MergeStatusRunning, startedBefore.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("requeue stale merge requests: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// RemoveMergeRequest deletes an entry from the merge queue, whatever its
+// status.
+func (s *Store) RemoveMergeRequest(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM merge_queue WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("remove merge request: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("merge request not found: %d", id)
+	}
+	return nil
+}
+
+// CompleteMergeRequest records the outcome of a claimed entry: its status,
+// commits, conflicts, output, notification flag and finish time.
+func (s *Store) CompleteMergeRequest(r *MergeRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.FinishedAt == nil {
+		now := time.Now().UTC()
+		r.FinishedAt = &now
+	}
+	result, err := s.db.Exec(`
+		UPDATE merge_queue SET target = ?, status = ?, base_commit = ?, merged_commit = ?, conflicts = ?,
+			output = ?, notified = ?, finished_at = ?
+		WHERE id = ?`,
+		r.Target, r.Status, nullString(r.BaseCommit), nullString(r.MergedCommit), nullString(strings.Join(r.Conflicts, "\n")),
+		nullString(r.Output), r.Notified, r.FinishedAt.UTC(), r.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("complete merge request: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("merge request not found: %d", r.ID)
+	}
+	return nil
+}
+
+func scanMergeRequests(rows *sql.Rows) ([]MergeRequest, error) {
+	var requests []MergeRequest
+	for rows.Next() {
+		var (
+			r                     MergeRequest
+			conflicts             string
+			startedAt, finishedAt sql.NullTime
+		)
+		if err := rows.Scan(&r.ID, &r.ProjectPath, &r.SessionID, &r.Agent, &r.Branch, &r.Target, &r.Status,
+			&r.BaseCommit, &r.MergedCommit, &conflicts, &r.Output, &r.Notified, &r.EnqueuedAt, &startedAt, &finishedAt); err != nil {
+			return nil, fmt.Errorf("scan merge request: %w", err)
+		}
+		if conflicts != "" {
+			r.Conflicts = strings.Split(conflicts, "\n")
+		}
+		if startedAt.Valid {
+			t := startedAt.Time
+			r.StartedAt = &t
+		}
+		if finishedAt.Valid {
+			t := finishedAt.Time
+			r.FinishedAt = &t
+		}
+		requests = append(requests, r)
+	}
+	return requests, rows.Err()
+}
@@ -0,0 +1,125 @@
+package state
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeQueueLifecycle(t *testing.T) {
+	store := testStore(t)
+
+	first := &MergeRequest{ProjectPath: "/repo", SessionID: "proj", Agent: "cc_1", Branch: "ntm/proj/cc_1"}
+	if err := store.EnqueueMerge(first); err != nil {
+		t.Fatalf("EnqueueMerge: %v", err)
+	}
+	second := &MergeRequest{ProjectPath: "/repo", SessionID: "proj", Agent: "cod_1", Branch: "ntm/proj/cod_1", Target: "integration"}
+	if err := store.EnqueueMerge(second); err != nil {
+		t.Fatalf("EnqueueMerge: %v", err)
+	}
+	if first.ID == 0 || first.Status != MergeStatusQueued {
+		t.Fatalf("unexpected entry: %+v", first)
+	}
+
+	dup := &MergeRequest{ProjectPath: "/repo", SessionID: "proj", Agent: "cc_1", Branch: "ntm/proj/cc_1"}
+	if err := store.EnqueueMerge(dup); err == nil || !strings.Contains(err.Error(), "already in the merge queue") {
+		t.Errorf("duplicate EnqueueMerge = %v", err)
+	}
+
+	next, err := store.NextMergeRequest("/repo")
+	if err != nil || next == nil || next.ID != first.ID {
+		t.Fatalf("NextMergeRequest = %+v, %v", next, err)
+	}
+	if other, _ := store.NextMergeRequest("/elsewhere"); other != nil {
+		t.Errorf("NextMergeRequest for another project = %+v", other)
+	}
+
+	started := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if ok, err := store.ClaimMergeRequest(next.ID, started); err != nil || !ok {
+		t.Fatalf("ClaimMergeRequest = %v, %v", ok, err)
+	}
+	if ok, _ := store.ClaimMergeRequest(next.ID, started); ok {
+		t.Error("entry claimed twice")
+	}
+
+	next.Target = "main"
+	next.Status = MergeStatusConflict
+	next.BaseCommit = "abc123"
+	next.Conflicts = []string{"a.go", "b.go"}
+	next.Notified = true
+	if err := store.CompleteMergeRequest(next); err != nil {
+		t.Fatalf("CompleteMergeRequest: %v", err)
+	}
+
+	pending, err := store.ListMergeRequests(MergeQuery{ProjectPath: "/repo", Statuses: []MergeStatus{MergeStatusQueued, MergeStatusRunning}})
+	if err != nil || len(pending) != 1 || pending[0].ID != second.ID {
+		t.Fatalf("pending = %+v, %v", pending, err)
+	}
+
+	all, err := store.ListMergeRequests(MergeQuery{SessionID: "proj"})
+	if err != nil || len(all) != 2 {
+		t.Fatalf("all = %+v, %v", all, err)
+	}
+	done := all[0]
+	if done.Status != MergeStatusConflict || done.Target != "main" || done.BaseCommit != "abc123" || !done.Notified {
+		t.Errorf("completed entry = %+v", done)
+	}
+	if len(done.Conflicts) != 2 || done.Conflicts[1] != "b.go" {
+		t.Errorf("conflicts = %v", done.Conflicts)
+	}
+	if done.StartedAt == nil || !done.StartedAt.Equal(started) || done.FinishedAt == nil {
+		t.Errorf("times = %v, %v", done.StartedAt, done.FinishedAt)
+	}
+	if !done.Status.Done() || all[1].Status.Done() {
+		t.Error("Done() misclassified statuses")
+	}
+
+	// A finished branch can be queued again.
+	if err := store.EnqueueMerge(dup); err != nil {
+		t.Errorf("re-enqueue after completion: %v", err)
+	}
+}
+
+func TestMergeQueueStaleEntriesAndRemoval(t *testing.T) {
+	store := testStore(t)
+
+	stale := &MergeRequest{ProjectPath: "/repo", SessionID: "proj", Agent: "cc_1", Branch: "ntm/proj/cc_1"}
+	fresh := &MergeRequest{ProjectPath: "/repo", SessionID: "proj", Agent: "cc_2", Branch: "ntm/proj/cc_2"}
+	for _, r := range []*MergeRequest{stale, fresh} {
+		if err := store.EnqueueMerge(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if ok, err := store.ClaimMergeRequest(stale.ID, now.Add(-3*time.Hour)); !ok || err != nil {
+		t.Fatalf("claim stale = %v, %v", ok, err)
+	}
+	if ok, err := store.ClaimMergeRequest(fresh.ID, now.Add(-time.Minute)); !ok || err != nil {
+		t.Fatalf("claim fresh = %v, %v", ok, err)
+	}
+
+	// The runner that claimed the stale entry died; only it returns to the queue.
+	n, err := store.RequeueStaleMergeRequests("/repo", now.Add(-time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("RequeueStaleMergeRequests = %d, %v", n, err)
+	}
+	next, err := store.NextMergeRequest("/repo")
+	if err != nil || next == nil || next.ID != stale.ID || next.StartedAt != nil {
+		t.Fatalf("NextMergeRequest = %+v, %v", next, err)
+	}
+
+	// A running entry blocks re-queueing its branch until it is removed.
+	again := &MergeRequest{ProjectPath: "/repo", SessionID: "proj", Agent: "cc_2", Branch: "ntm/proj/cc_2"}
+	if err := store.EnqueueMerge(again); err == nil || !strings.Contains(err.Error(), "merge-queue remove") {
+		t.Errorf("EnqueueMerge of running branch = %v", err)
+	}
+	if err := store.RemoveMergeRequest(fresh.ID); err != nil {
+		t.Fatalf("RemoveMergeRequest: %v", err)
+	}
+	if err := store.RemoveMergeRequest(fresh.ID); err == nil {
+		t.Error("removing a missing entry succeeded")
+	}
+	if err := store.EnqueueMerge(again); err != nil {
+		t.Errorf("EnqueueMerge after removal: %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+-- NTM State Store: Agent State Transitions
+-- Version: 007
+-- Description: Persistent log of agent pane state changes (idle, working,
+-- error, awaiting_approval, ...) as observed by the state classifiers
+
+-- session_id is the tmux session name; it deliberately has no foreign key so
+-- transitions are recorded for sessions that were never registered in the store.
+CREATE TABLE IF NOT EXISTS agent_state_transitions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL,
+    pane_id TEXT NOT NULL,     -- tmux pane ID (e.g. "%3")
+    pane_name TEXT,            -- pane title (e.g. "myproject__cc_2")
+    agent_type TEXT,           -- cc, cod, gmi, ...
+    from_state TEXT,           -- NULL for the first observation of a pane
+    to_state TEXT NOT NULL,
+    source TEXT,               -- classifier that observed it (status, robot)
+    trigger TEXT,              -- pattern or signal that caused the transition
+    confidence REAL,
+    transition_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_state_transitions_session_at
+    ON agent_state_transitions(session_id, transition_at);
+CREATE INDEX IF NOT EXISTS idx_state_transitions_pane_at
+    ON agent_state_transitions(session_id, pane_id, transition_at);
+CREATE INDEX IF NOT EXISTS idx_state_transitions_at
+    ON agent_state_transitions(transition_at);
@@ -0,0 +1,23 @@
+-- NTM State Store: Schedules
+-- Version: 008
+-- Description: Cron-style schedules that send prompts or run pipelines
+
+CREATE TABLE IF NOT EXISTS schedules (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    cron TEXT NOT NULL,              -- 5-field cron expression, @every <duration>, or @daily etc.
+    target_type TEXT NOT NULL CHECK (target_type IN ('send', 'pipeline')),
+    session_id TEXT NOT NULL,        -- tmux session name; no foreign key, sessions come and go
+    target TEXT NOT NULL,            -- JSON target options (message, panes, workflow file, vars)
+    missed_policy TEXT NOT NULL DEFAULT 'skip' CHECK (missed_policy IN ('skip', 'once')),
+    enabled INTEGER NOT NULL DEFAULT 1,
+    next_run_at TIMESTAMP,
+    last_run_at TIMESTAMP,
+    last_status TEXT,                -- success, error
+    last_error TEXT,
+    run_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedules_due ON schedules(enabled, next_run_at);
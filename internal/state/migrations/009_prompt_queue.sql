@@ -0,0 +1,23 @@
+-- NTM State Store: Prompt Queue
+-- Version: 009
+-- Description: Durable per-pane FIFO of prompts delivered when the agent is idle
+
+CREATE TABLE IF NOT EXISTS prompt_queue (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL,        -- tmux session name; no foreign key, like transitions
+    pane_id TEXT NOT NULL,           -- tmux pane ID (e.g. "%3")
+    pane_index INTEGER NOT NULL,
+    pane_name TEXT,
+    prompt TEXT NOT NULL,
+    source TEXT,                     -- cli, api
+    template TEXT,
+    position INTEGER NOT NULL,       -- order within the pane's queue
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivering', 'delivered', 'failed')),
+    error TEXT,
+    enqueued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_prompt_queue_pane
+    ON prompt_queue(session_id, pane_id, status, position);
+CREATE INDEX IF NOT EXISTS idx_prompt_queue_status ON prompt_queue(status);
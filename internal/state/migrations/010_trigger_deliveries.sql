@@ -0,0 +1,21 @@
+-- NTM State Store: Trigger Deliveries
+-- Version: 010
+-- Description: Inbound webhook deliveries, used to dedupe retries and as trigger history
+
+CREATE TABLE IF NOT EXISTS trigger_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    trigger_name TEXT NOT NULL,
+    delivery_id TEXT NOT NULL,       -- sender's delivery ID, or a generated one
+    action TEXT NOT NULL,            -- pipeline, send
+    session_id TEXT,
+    status TEXT NOT NULL DEFAULT 'received' CHECK (status IN ('received', 'started', 'failed')),
+    run_id TEXT,                     -- pipeline run ID
+    vars TEXT,                       -- JSON object of mapped payload variables
+    error TEXT,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    UNIQUE (trigger_name, delivery_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trigger_deliveries_received
+    ON trigger_deliveries(trigger_name, received_at);
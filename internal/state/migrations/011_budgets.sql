@@ -0,0 +1,25 @@
+-- NTM State Store: Spend Budgets
+-- Version: 011
+-- Description: Budgets set with 'ntm budget set' and the per-day spend ledger they are evaluated against
+
+CREATE TABLE IF NOT EXISTS budgets (
+    scope TEXT NOT NULL CHECK (scope IN ('session', 'agent_type', 'daily')),
+    target TEXT NOT NULL DEFAULT '',     -- session name or agent type; '' for daily and the all-sessions default
+    limit_usd REAL NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, target)
+);
+
+CREATE TABLE IF NOT EXISTS budget_spend (
+    day TEXT NOT NULL,                   -- local date, YYYY-MM-DD
+    session_id TEXT NOT NULL,
+    agent TEXT NOT NULL,                 -- pane title
+    agent_type TEXT NOT NULL DEFAULT '',
+    spent_usd REAL NOT NULL DEFAULT 0,   -- spend attributed to this day
+    last_usd REAL NOT NULL DEFAULT 0,    -- cumulative tracker cost at the last observation
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (day, session_id, agent)
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_spend_agent
+    ON budget_spend(session_id, agent, day);
@@ -0,0 +1,5 @@
+-- NTM State Store: Budget Pricing Version
+-- Version: 012
+-- Description: Records which pricing catalog priced each budget spend observation
+
+ALTER TABLE budget_spend ADD COLUMN pricing TEXT NOT NULL DEFAULT '';
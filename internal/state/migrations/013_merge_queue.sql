@@ -0,0 +1,24 @@
+-- NTM State Store: Merge Queue
+-- Version: 013
+-- Description: Agent worktree branches waiting to be rebased, tested and fast-forwarded onto a target branch
+
+CREATE TABLE IF NOT EXISTS merge_queue (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_path TEXT NOT NULL,      -- repository the worktrees belong to
+    session_id TEXT NOT NULL,        -- tmux session name; no foreign key, like prompt_queue
+    agent TEXT NOT NULL,             -- worktree agent name (e.g. "cc_1")
+    branch TEXT NOT NULL,
+    target TEXT NOT NULL,            -- branch to fast-forward; '' resolves to main or master when run
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'merged', 'conflict', 'tests_failed', 'failed')),
+    base_commit TEXT,                -- target commit the branch was rebased onto
+    merged_commit TEXT,              -- new target head after a successful merge
+    conflicts TEXT,                  -- newline-separated conflicting paths
+    output TEXT,                     -- tail of the test output or the error
+    notified INTEGER NOT NULL DEFAULT 0,
+    enqueued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_merge_queue_project
+    ON merge_queue(project_path, status, id);
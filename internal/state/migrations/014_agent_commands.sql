@@ -0,0 +1,19 @@
+-- NTM State Store: Agent Commands
+-- Version: 014
+-- Description: Tool calls reported by agent CLI hooks (shell commands with exit codes, file edits)
+
+CREATE TABLE IF NOT EXISTS agent_commands (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL,        -- tmux session name; no foreign key, like prompt_queue
+    pane_id TEXT NOT NULL,           -- tmux pane ID from $TMUX_PANE (e.g. "%3")
+    agent_type TEXT,                 -- cc, cod, gmi
+    tool TEXT NOT NULL,              -- tool name as reported by the agent (e.g. "Bash", "Edit")
+    command TEXT,                    -- shell command for shell tools
+    exit_code INTEGER,               -- NULL when the agent did not report one
+    files TEXT,                      -- newline-separated paths touched by edit tools
+    started_at TIMESTAMP,            -- when the matching pre-tool hook fired, if seen
+    finished_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_commands_session
+    ON agent_commands(session_id, finished_at);
@@ -0,0 +1,369 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ========================
+// Prompt Queue
+// ========================
+
+// QueueStatus is the delivery status of a queued prompt.
+type QueueStatus string
+
+const (
+	QueueStatusPending    QueueStatus = "pending"    // Waiting for the pane to go idle
+	QueueStatusDelivering QueueStatus = "delivering" // Claimed by a dispatcher
+	QueueStatusDelivered  QueueStatus = "delivered"
+	QueueStatusFailed     QueueStatus = "failed"
+)
+
+// QueuedPrompt is a prompt waiting in a pane's FIFO.
+type QueuedPrompt struct {
+	ID          int64       `json:"id"`
+	SessionID   string      `json:"session"`
+	PaneID      string      `json:"pane_id"`
+	PaneIndex   int         `json:"pane_index"`
+	PaneName    string      `json:"pane_name,omitempty"`
+	Prompt      string      `json:"prompt"`
+	Source      string      `json:"source,omitempty"`
+	Template    string      `json:"template,omitempty"`
+	Position    int         `json:"position"`
+	Status      QueueStatus `json:"status"`
+	Error       string      `json:"error,omitempty"`
+	EnqueuedAt  time.Time   `json:"enqueued_at"`
+	DeliveredAt *time.Time  `json:"delivered_at,omitempty"`
+}
+
+// QueueQuery filters prompt queue queries. Zero values match everything.
+type QueueQuery struct {
+	SessionID string
+	Pane      string        // Matches pane ID or pane index
+	Statuses  []QueueStatus // Any of these statuses
+}
+
+const queueColumns = `id, session_id, pane_id, pane_index, COALESCE(pane_name, ''), prompt, COALESCE(source, ''),
+	COALESCE(template, ''), position, status, COALESCE(error, ''), enqueued_at, delivered_at`
+
+// EnqueuePrompt appends a prompt to the end of its pane's queue.
+func (s *Store) EnqueuePrompt(p *QueuedPrompt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.EnqueuedAt.IsZero() {
+		p.EnqueuedAt = time.Now().UTC()
+	}
+	p.Status = QueueStatusPending
+
+	var last int
+	if err := s.db.QueryRow(`SELECT COALESCE(MAX(position), 0) FROM prompt_queue WHERE session_id = ? AND pane_id = ?`,
+		p.SessionID, p.PaneID).Scan(&last); err != nil {
+		return fmt.Errorf("enqueue prompt: %w", err)
+	}
+	p.Position = last + 1
+
+	result, err := s.db.Exec(`
+		INSERT INTO prompt_queue (session_id, pane_id, pane_index, pane_name, prompt, source, template, position, status, enqueued_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.SessionID, p.PaneID, p.PaneIndex, nullString(p.PaneName), p.Prompt, nullString(p.Source), nullString(p.Template),
+		p.Position, p.Status, p.EnqueuedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("enqueue prompt: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get queued prompt id: %w", err)
+	}
+	p.ID = id
+	return nil
+}
+
+// ListQueuedPrompts returns prompts matching q, grouped by pane in queue order.
+func (s *Store) ListQueuedPrompts(q QueueQuery) ([]QueuedPrompt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if q.SessionID != "" {
+		conds = append(conds, "session_id = ?")
+		args = append(args, q.SessionID)
+	}
+	if q.Pane != "" {
+		if idx, err := strconv.Atoi(q.Pane); err == nil {
+			conds = append(conds, "(pane_id = ? OR pane_index = ?)")
+			args = append(args, q.Pane, idx)
+		} else {
+			conds = append(conds, "pane_id = ?")
+			args = append(args, q.Pane)
+		}
+	}
+	if len(q.Statuses) > 0 {
+		conds = append(conds, "status IN (?"+strings.Repeat(", ?", len(q.Statuses)-1)+")")
+		for _, st := range q.Statuses {
+			args = append(args, st)
+		}
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	// #nosec G202 -- where clause is internally generated, values are bound
+	rows, err := s.db.Query(`SELECT `+queueColumns+` FROM prompt_queue`+where+
+		` ORDER BY session_id, pane_index, pane_id, position`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list queued prompts: %w", err)
+	}
+	defer rows.Close()
+	return scanQueuedPrompts(rows)
+}
+
+// NextQueuedPrompt returns the head of a pane's pending queue, or nil.
+func (s *Store) NextQueuedPrompt(sessionID, paneID string) (*QueuedPrompt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT `+queueColumns+` FROM prompt_queue
+		WHERE session_id = ? AND pane_id = ? AND status = ?
+		ORDER BY position LIMIT 1`, sessionID, paneID, QueueStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("next queued prompt: %w", err)
+	}
+	defer rows.Close()
+
+	prompts, err := scanQueuedPrompts(rows)
+	if err != nil || len(prompts) == 0 {
+		return nil, err
+	}
+	return &prompts[0], nil
+}
+
+// QueuedSessions returns the sessions that have pending prompts.
+func (s *Store) QueuedSessions() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT DISTINCT session_id FROM prompt_queue WHERE status = ? ORDER BY session_id`, QueueStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("list queued sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []string
+	for rows.Next() {
+		var session string
+		if err := rows.Scan(&session); err != nil {
+			return nil, fmt.Errorf("scan queued session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// QueueDepths returns the number of pending prompts per pane ID for a session.
+func (s *Store) QueueDepths(sessionID string) (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT pane_id, COUNT(*) FROM prompt_queue
+		WHERE session_id = ? AND status = ? GROUP BY pane_id`, sessionID, QueueStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("queue depths: %w", err)
+	}
+	defer rows.Close()
+
+	depths := make(map[string]int)
+	for rows.Next() {
+		var (
+			paneID string
+			n      int
+		)
+		if err := rows.Scan(&paneID, &n); err != nil {
+			return nil, fmt.Errorf("scan queue depth: %w", err)
+		}
+		depths[paneID] = n
+	}
+	return depths, rows.Err()
+}
+
+// LastQueueDelivery returns when a prompt was last delivered to a pane from
+// the queue, or the zero time.
+func (s *Store) LastQueueDelivery(sessionID, paneID string) (time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var last sql.NullTime
+	rows, err := s.db.Query(`SELECT delivered_at FROM prompt_queue
+		WHERE session_id = ? AND pane_id = ? AND delivered_at IS NOT NULL
+		ORDER BY delivered_at DESC LIMIT 1`, sessionID, paneID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("last queue delivery: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(&last); err != nil {
+			return time.Time{}, fmt.Errorf("scan last queue delivery: %w", err)
+		}
+	}
+	return last.Time, rows.Err()
+}
+
+// ClaimQueuedPrompt marks a pending prompt as being delivered, reporting false
+// if another dispatcher claimed it first.
+func (s *Store) ClaimQueuedPrompt(id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`UPDATE prompt_queue SET status = ? WHERE id = ? AND status = ?`,
+		QueueStatusDelivering, id, QueueStatusPending)
+	if err != nil {
+		return false, fmt.Errorf("claim queued prompt: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+// CompleteQueuedPrompt records the outcome of delivering a claimed prompt.
+func (s *Store) CompleteQueuedPrompt(id int64, at time.Time, deliveryErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, errMsg := QueueStatusDelivered, ""
+	if deliveryErr != nil {
+		status, errMsg = QueueStatusFailed, deliveryErr.Error()
+	}
+
+	result, err := s.db.Exec(`UPDATE prompt_queue SET status = ?, error = ?, delivered_at = ? WHERE id = ?`,
+		status, nullString(errMsg), at.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("complete queued prompt: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("queued prompt not found: %d", id)
+	}
+	return nil
+}
+
+// ClearQueuedPrompts removes pending prompts for a session, optionally
+// limited to one pane (ID or index). It returns the number removed.
+func (s *Store) ClearQueuedPrompts(sessionID, pane string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := `DELETE FROM prompt_queue WHERE session_id = ? AND status = ?`
+	args := []interface{}{sessionID, QueueStatusPending}
+	if pane != "" {
+		if idx, err := strconv.Atoi(pane); err == nil {
+			query += ` AND (pane_id = ? OR pane_index = ?)`
+			args = append(args, pane, idx)
+		} else {
+			query += ` AND pane_id = ?`
+			args = append(args, pane)
+		}
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("clear queued prompts: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ReorderQueuedPrompt moves a pending prompt to a 1-based position within its
+// pane's pending queue. Positions past the end move it to the back.
+func (s *Store) ReorderQueuedPrompt(id int64, position int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if position < 1 {
+		return fmt.Errorf("invalid queue position %d (positions start at 1)", position)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin reorder: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		sessionID, paneID string
+		status            QueueStatus
+	)
+	err = tx.QueryRow(`SELECT session_id, pane_id, status FROM prompt_queue WHERE id = ?`, id).Scan(&sessionID, &paneID, &status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("queued prompt not found: %d", id)
+	}
+	if err != nil {
+		return fmt.Errorf("reorder queued prompt: %w", err)
+	}
+	if status != QueueStatusPending {
+		return fmt.Errorf("queued prompt %d is %s, only pending prompts can be reordered", id, status)
+	}
+
+	rows, err := tx.Query(`SELECT id FROM prompt_queue WHERE session_id = ? AND pane_id = ? AND status = ? AND id != ?
+		ORDER BY position`, sessionID, paneID, QueueStatusPending, id)
+	if err != nil {
+		return fmt.Errorf("reorder queued prompt: %w", err)
+	}
+	var order []int64
+	for rows.Next() {
+		var other int64
+		if err := rows.Scan(&other); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan queued prompt: %w", err)
+		}
+		order = append(order, other)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reorder queued prompt: %w", err)
+	}
+
+	if position > len(order)+1 {
+		position = len(order) + 1
+	}
+	order = append(order[:position-1], append([]int64{id}, order[position-1:]...)...)
+
+	// Renumber after any delivered entries so positions keep increasing.
+	var base int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(position), 0) FROM prompt_queue WHERE session_id = ? AND pane_id = ? AND status != ?`,
+		sessionID, paneID, QueueStatusPending).Scan(&base); err != nil {
+		return fmt.Errorf("reorder queued prompt: %w", err)
+	}
+	for i, qid := range order {
+		if _, err := tx.Exec(`UPDATE prompt_queue SET position = ? WHERE id = ?`, base+i+1, qid); err != nil {
+			return fmt.Errorf("reorder queued prompt: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func scanQueuedPrompts(rows *sql.Rows) ([]QueuedPrompt, error) {
+	var prompts []QueuedPrompt
+	for rows.Next() {
+		var (
+			p           QueuedPrompt
+			deliveredAt sql.NullTime
+		)
+		if err := rows.Scan(&p.ID, &p.SessionID, &p.PaneID, &p.PaneIndex, &p.PaneName, &p.Prompt, &p.Source,
+			&p.Template, &p.Position, &p.Status, &p.Error, &p.EnqueuedAt, &deliveredAt); err != nil {
+			return nil, fmt.Errorf("scan queued prompt: %w", err)
+		}
+		if deliveredAt.Valid {
+			t := deliveredAt.Time
+			p.DeliveredAt = &t
+		}
+		prompts = append(prompts, p)
+	}
+	return prompts, rows.Err()
+}
@@ -0,0 +1,119 @@
+package state
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func enqueue(t *testing.T, store *Store, pane string, index int, prompt string) *QueuedPrompt {
+	t.Helper()
+	p := &QueuedPrompt{SessionID: "proj", PaneID: pane, PaneIndex: index, Prompt: prompt, Source: "cli"}
+	if err := store.EnqueuePrompt(p); err != nil {
+		t.Fatalf("EnqueuePrompt: %v", err)
+	}
+	return p
+}
+
+func queuedPrompts(t *testing.T, store *Store, pane string) []string {
+	t.Helper()
+	prompts, err := store.ListQueuedPrompts(QueueQuery{SessionID: "proj", Pane: pane, Statuses: []QueueStatus{QueueStatusPending}})
+	if err != nil {
+		t.Fatalf("ListQueuedPrompts: %v", err)
+	}
+	var out []string
+	for _, p := range prompts {
+		out = append(out, p.Prompt)
+	}
+	return out
+}
+
+func TestPromptQueueFIFO(t *testing.T) {
+	store := testStore(t)
+
+	a := enqueue(t, store, "%1", 1, "first")
+	enqueue(t, store, "%1", 1, "second")
+	enqueue(t, store, "%2", 2, "other pane")
+	if a.ID == 0 || a.Position != 1 || a.Status != QueueStatusPending {
+		t.Fatalf("unexpected enqueued prompt: %+v", a)
+	}
+
+	head, err := store.NextQueuedPrompt("proj", "%1")
+	if err != nil || head == nil || head.Prompt != "first" {
+		t.Fatalf("NextQueuedPrompt = %+v, %v", head, err)
+	}
+
+	depths, _ := store.QueueDepths("proj")
+	if depths["%1"] != 2 || depths["%2"] != 1 {
+		t.Errorf("depths = %v", depths)
+	}
+	if sessions, _ := store.QueuedSessions(); len(sessions) != 1 || sessions[0] != "proj" {
+		t.Errorf("sessions = %v", sessions)
+	}
+
+	if ok, err := store.ClaimQueuedPrompt(head.ID); err != nil || !ok {
+		t.Fatalf("ClaimQueuedPrompt = %v, %v", ok, err)
+	}
+	if ok, _ := store.ClaimQueuedPrompt(head.ID); ok {
+		t.Error("prompt claimed twice")
+	}
+	delivered := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	if err := store.CompleteQueuedPrompt(head.ID, delivered, nil); err != nil {
+		t.Fatalf("CompleteQueuedPrompt: %v", err)
+	}
+	if last, _ := store.LastQueueDelivery("proj", "%1"); !last.Equal(delivered) {
+		t.Errorf("LastQueueDelivery = %v, want %v", last, delivered)
+	}
+
+	next, _ := store.NextQueuedPrompt("proj", "%1")
+	if next == nil || next.Prompt != "second" {
+		t.Fatalf("next = %+v, want second", next)
+	}
+	_, _ = store.ClaimQueuedPrompt(next.ID)
+	if err := store.CompleteQueuedPrompt(next.ID, delivered, errors.New("pane gone")); err != nil {
+		t.Fatal(err)
+	}
+
+	all, _ := store.ListQueuedPrompts(QueueQuery{SessionID: "proj", Pane: "1"})
+	if len(all) != 2 || all[0].Status != QueueStatusDelivered || all[1].Status != QueueStatusFailed || all[1].Error != "pane gone" {
+		t.Errorf("history = %+v", all)
+	}
+	if all[0].DeliveredAt == nil {
+		t.Error("delivered_at not recorded")
+	}
+}
+
+func TestPromptQueueReorderAndClear(t *testing.T) {
+	store := testStore(t)
+	a := enqueue(t, store, "%1", 1, "a")
+	enqueue(t, store, "%1", 1, "b")
+	c := enqueue(t, store, "%1", 1, "c")
+	enqueue(t, store, "%2", 2, "x")
+
+	if err := store.ReorderQueuedPrompt(c.ID, 1); err != nil {
+		t.Fatalf("ReorderQueuedPrompt: %v", err)
+	}
+	if got := queuedPrompts(t, store, "%1"); len(got) != 3 || got[0] != "c" || got[1] != "a" || got[2] != "b" {
+		t.Errorf("after move to front = %v", got)
+	}
+	if err := store.ReorderQueuedPrompt(c.ID, 99); err != nil {
+		t.Fatalf("ReorderQueuedPrompt: %v", err)
+	}
+	if got := queuedPrompts(t, store, "%1"); got[2] != "c" {
+		t.Errorf("after move to back = %v", got)
+	}
+	if err := store.ReorderQueuedPrompt(a.ID, 0); err == nil {
+		t.Error("expected position 0 to be rejected")
+	}
+	if err := store.ReorderQueuedPrompt(12345, 1); err == nil {
+		t.Error("expected missing prompt to fail")
+	}
+
+	n, err := store.ClearQueuedPrompts("proj", "1")
+	if err != nil || n != 3 {
+		t.Fatalf("ClearQueuedPrompts = %d, %v", n, err)
+	}
+	if got := queuedPrompts(t, store, ""); len(got) != 1 || got[0] != "x" {
+		t.Errorf("remaining = %v", got)
+	}
+}
@@ -0,0 +1,305 @@
+package state
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ========================
+// Schedules
+// ========================
+
+// ScheduleTargetType identifies what a schedule does when it fires.
+type ScheduleTargetType string
+
+const (
+	ScheduleTargetSend     ScheduleTargetType = "send"     // Send a prompt to session panes
+	ScheduleTargetPipeline ScheduleTargetType = "pipeline" // Run a pipeline workflow
+)
+
+// MissedRunPolicy decides what happens to runs that were due while no
+// scheduler was running.
+type MissedRunPolicy string
+
+const (
+	MissedRunSkip MissedRunPolicy = "skip" // Drop missed runs, wait for the next slot
+	MissedRunOnce MissedRunPolicy = "once" // Run once to catch up, then resume the schedule
+)
+
+// Schedule run outcomes recorded in LastStatus.
+const (
+	ScheduleStatusSuccess = "success"
+	ScheduleStatusError   = "error"
+)
+
+// ScheduleTarget holds the options for the schedule's target. Send targets use
+// the message and pane filters; pipeline targets use the workflow and vars.
+type ScheduleTarget struct {
+	Message    string   `json:"message,omitempty"`
+	Panes      []string `json:"panes,omitempty"`       // Pane indices
+	AgentTypes []string `json:"agent_types,omitempty"` // Agent type filter (cc, cod, ...)
+	All        bool     `json:"all,omitempty"`         // Include the user pane
+
+	Workflow   string                 `json:"workflow,omitempty"`    // Workflow file or name
+	Vars       map[string]interface{} `json:"vars,omitempty"`        // Pipeline variables
+	ProjectDir string                 `json:"project_dir,omitempty"` // Pipeline working directory
+}
+
+// Schedule is a cron-style recurring send or pipeline run.
+type Schedule struct {
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	Cron         string             `json:"cron"`
+	TargetType   ScheduleTargetType `json:"target_type"`
+	SessionID    string             `json:"session"`
+	Target       ScheduleTarget     `json:"target"`
+	MissedPolicy MissedRunPolicy    `json:"missed_policy"`
+	Enabled      bool               `json:"enabled"`
+	NextRunAt    *time.Time         `json:"next_run_at,omitempty"`
+	LastRunAt    *time.Time         `json:"last_run_at,omitempty"`
+	LastStatus   string             `json:"last_status,omitempty"`
+	LastError    string             `json:"last_error,omitempty"`
+	RunCount     int                `json:"run_count"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+const scheduleColumns = `id, name, cron, target_type, session_id, target, missed_policy, enabled,
+	next_run_at, last_run_at, COALESCE(last_status, ''), COALESCE(last_error, ''), run_count, created_at, updated_at`
+
+// CreateSchedule persists a new schedule. An ID is generated when empty.
+func (s *Store) CreateSchedule(sch *Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sch.ID == "" {
+		sch.ID = newScheduleID()
+	}
+	if sch.MissedPolicy == "" {
+		sch.MissedPolicy = MissedRunSkip
+	}
+	now := time.Now().UTC()
+	if sch.CreatedAt.IsZero() {
+		sch.CreatedAt = now
+	}
+	sch.UpdatedAt = now
+
+	target, err := json.Marshal(sch.Target)
+	if err != nil {
+		return fmt.Errorf("encode schedule target: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO schedules (id, name, cron, target_type, session_id, target, missed_policy, enabled, next_run_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sch.ID, sch.Name, sch.Cron, sch.TargetType, sch.SessionID, string(target), sch.MissedPolicy, sch.Enabled,
+		utcTimePtr(sch.NextRunAt), sch.CreatedAt, sch.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create schedule: %w", err)
+	}
+	return nil
+}
+
+// GetSchedule retrieves a schedule by ID or name, or nil if none matches.
+func (s *Store) GetSchedule(idOrName string) (*Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT `+scheduleColumns+` FROM schedules WHERE id = ? OR name = ?
+		ORDER BY id = ? DESC LIMIT 1`, idOrName, idOrName, idOrName)
+	if err != nil {
+		return nil, fmt.Errorf("get schedule: %w", err)
+	}
+	defer rows.Close()
+
+	schedules, err := scanSchedules(rows)
+	if err != nil || len(schedules) == 0 {
+		return nil, err
+	}
+	return &schedules[0], nil
+}
+
+// ListSchedules returns all schedules ordered by name.
+func (s *Store) ListSchedules() ([]Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT ` + scheduleColumns + ` FROM schedules ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list schedules: %w", err)
+	}
+	defer rows.Close()
+	return scanSchedules(rows)
+}
+
+// DueSchedules returns enabled schedules whose next run is at or before now,
+// oldest first.
+func (s *Store) DueSchedules(now time.Time) ([]Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT `+scheduleColumns+` FROM schedules
+		WHERE enabled = 1 AND next_run_at IS NOT NULL AND next_run_at <= ?
+		ORDER BY next_run_at, name`, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list due schedules: %w", err)
+	}
+	defer rows.Close()
+	return scanSchedules(rows)
+}
+
+// UpdateSchedule updates a schedule's definition and next run time.
+func (s *Store) UpdateSchedule(sch *Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, err := json.Marshal(sch.Target)
+	if err != nil {
+		return fmt.Errorf("encode schedule target: %w", err)
+	}
+	sch.UpdatedAt = time.Now().UTC()
+
+	result, err := s.db.Exec(`
+		UPDATE schedules SET name = ?, cron = ?, target_type = ?, session_id = ?, target = ?, missed_policy = ?,
+			enabled = ?, next_run_at = ?, updated_at = ?
+		WHERE id = ?`,
+		sch.Name, sch.Cron, sch.TargetType, sch.SessionID, string(target), sch.MissedPolicy,
+		sch.Enabled, utcTimePtr(sch.NextRunAt), sch.UpdatedAt, sch.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update schedule: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("schedule not found: %s", sch.ID)
+	}
+	return nil
+}
+
+// RecordScheduleRun records the outcome of a run.
+func (s *Store) RecordScheduleRun(id string, ranAt time.Time, runErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, errMsg := ScheduleStatusSuccess, ""
+	if runErr != nil {
+		status, errMsg = ScheduleStatusError, runErr.Error()
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE schedules SET last_run_at = ?, last_status = ?, last_error = ?, run_count = run_count + 1, updated_at = ?
+		WHERE id = ?`,
+		ranAt.UTC(), status, nullString(errMsg), time.Now().UTC(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("record schedule run: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("schedule not found: %s", id)
+	}
+	return nil
+}
+
+// SetScheduleNextRun moves a schedule to its next run time. A nil time leaves
+// the schedule without a pending run.
+func (s *Store) SetScheduleNextRun(id string, next *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`UPDATE schedules SET next_run_at = ?, updated_at = ? WHERE id = ?`,
+		utcTimePtr(next), time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("set schedule next run: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("schedule not found: %s", id)
+	}
+	return nil
+}
+
+// ClaimScheduleRun moves a due schedule from due to next, reporting false if
+// another scheduler already moved it. This keeps 'ntm serve' and a standalone
+// 'ntm schedule daemon' from both firing the same slot.
+func (s *Store) ClaimScheduleRun(id string, due time.Time, next *time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`UPDATE schedules SET next_run_at = ?, updated_at = ? WHERE id = ? AND next_run_at = ?`,
+		utcTimePtr(next), time.Now().UTC(), id, due.UTC())
+	if err != nil {
+		return false, fmt.Errorf("claim schedule run: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+// DeleteSchedule removes a schedule by ID.
+func (s *Store) DeleteSchedule(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM schedules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete schedule: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("schedule not found: %s", id)
+	}
+	return nil
+}
+
+func scanSchedules(rows *sql.Rows) ([]Schedule, error) {
+	var schedules []Schedule
+	for rows.Next() {
+		var (
+			sch                  Schedule
+			target               string
+			nextRunAt, lastRunAt sql.NullTime
+		)
+		if err := rows.Scan(&sch.ID, &sch.Name, &sch.Cron, &sch.TargetType, &sch.SessionID, &target, &sch.MissedPolicy,
+			&sch.Enabled, &nextRunAt, &lastRunAt, &sch.LastStatus, &sch.LastError, &sch.RunCount, &sch.CreatedAt, &sch.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan schedule: %w", err)
+		}
+		if err := json.Unmarshal([]byte(target), &sch.Target); err != nil {
+			return nil, fmt.Errorf("decode schedule %s target: %w", sch.Name, err)
+		}
+		if nextRunAt.Valid {
+			t := nextRunAt.Time
+			sch.NextRunAt = &t
+		}
+		if lastRunAt.Valid {
+			t := lastRunAt.Time
+			sch.LastRunAt = &t
+		}
+		schedules = append(schedules, sch)
+	}
+	return schedules, rows.Err()
+}
+
+func utcTimePtr(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func newScheduleID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("sch-%d", time.Now().UnixNano())
+	}
+	return "sch-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,117 @@
+package state
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSchedulesCRUD(t *testing.T) {
+	store := testStore(t)
+	next := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	sch := &Schedule{
+		Name:       "triage",
+		Cron:       "0 9 * * 1-5",
+		TargetType: ScheduleTargetSend,
+		SessionID:  "proj",
+		Target:     ScheduleTarget{Message: "triage the inbox", Panes: []string{"1"}},
+		Enabled:    true,
+		NextRunAt:  &next,
+	}
+	if err := store.CreateSchedule(sch); err != nil {
+		t.Fatalf("CreateSchedule: %v", err)
+	}
+	if sch.ID == "" || sch.MissedPolicy != MissedRunSkip {
+		t.Fatalf("defaults not applied: %+v", sch)
+	}
+	if err := store.CreateSchedule(&Schedule{Name: "triage", Cron: "@daily", TargetType: ScheduleTargetSend, SessionID: "x"}); err == nil {
+		t.Error("expected duplicate name to fail")
+	}
+
+	byName, err := store.GetSchedule("triage")
+	if err != nil || byName == nil {
+		t.Fatalf("GetSchedule(name) = %v, %v", byName, err)
+	}
+	if byName.ID != sch.ID || byName.Target.Message != "triage the inbox" || len(byName.Target.Panes) != 1 {
+		t.Errorf("unexpected schedule: %+v", byName)
+	}
+	if byName.NextRunAt == nil || !byName.NextRunAt.Equal(next) {
+		t.Errorf("next_run_at = %v, want %v", byName.NextRunAt, next)
+	}
+	if missing, err := store.GetSchedule("nope"); err != nil || missing != nil {
+		t.Errorf("GetSchedule(missing) = %v, %v", missing, err)
+	}
+
+	byName.Enabled = false
+	if err := store.UpdateSchedule(byName); err != nil {
+		t.Fatalf("UpdateSchedule: %v", err)
+	}
+	if due, _ := store.DueSchedules(next.Add(time.Hour)); len(due) != 0 {
+		t.Errorf("disabled schedule reported due: %+v", due)
+	}
+
+	list, err := store.ListSchedules()
+	if err != nil || len(list) != 1 || list[0].Enabled {
+		t.Fatalf("ListSchedules = %+v, %v", list, err)
+	}
+
+	if err := store.DeleteSchedule(sch.ID); err != nil {
+		t.Fatalf("DeleteSchedule: %v", err)
+	}
+	if err := store.DeleteSchedule(sch.ID); err == nil {
+		t.Error("expected deleting a missing schedule to fail")
+	}
+}
+
+func TestSchedulesDueAndRecordRun(t *testing.T) {
+	store := testStore(t)
+	base := time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC)
+
+	for i, name := range []string{"early", "late"} {
+		at := base.Add(time.Duration(i) * time.Hour)
+		if err := store.CreateSchedule(&Schedule{
+			Name: name, Cron: "@every 1h", TargetType: ScheduleTargetPipeline, SessionID: "proj",
+			Target: ScheduleTarget{Workflow: "nightly-review"}, Enabled: true, NextRunAt: &at,
+		}); err != nil {
+			t.Fatalf("CreateSchedule: %v", err)
+		}
+	}
+
+	due, err := store.DueSchedules(base.Add(30 * time.Minute))
+	if err != nil {
+		t.Fatalf("DueSchedules: %v", err)
+	}
+	if len(due) != 1 || due[0].Name != "early" {
+		t.Fatalf("due = %+v, want early only", due)
+	}
+
+	next := base.Add(2 * time.Hour)
+	if ok, err := store.ClaimScheduleRun(due[0].ID, *due[0].NextRunAt, &next); err != nil || !ok {
+		t.Fatalf("ClaimScheduleRun = %v, %v", ok, err)
+	}
+	if ok, _ := store.ClaimScheduleRun(due[0].ID, *due[0].NextRunAt, &next); ok {
+		t.Error("second claim of the same slot succeeded")
+	}
+	if err := store.RecordScheduleRun(due[0].ID, base, errors.New("session not found")); err != nil {
+		t.Fatalf("RecordScheduleRun: %v", err)
+	}
+	got, _ := store.GetSchedule("early")
+	if got.RunCount != 1 || got.LastStatus != ScheduleStatusError || got.LastError != "session not found" {
+		t.Errorf("run not recorded: %+v", got)
+	}
+	if got.LastRunAt == nil || !got.NextRunAt.Equal(next) {
+		t.Errorf("times not updated: last=%v next=%v", got.LastRunAt, got.NextRunAt)
+	}
+
+	if err := store.RecordScheduleRun(got.ID, next, nil); err != nil {
+		t.Fatalf("RecordScheduleRun: %v", err)
+	}
+	if err := store.SetScheduleNextRun(got.ID, nil); err != nil {
+		t.Fatalf("SetScheduleNextRun: %v", err)
+	}
+	got, _ = store.GetSchedule(got.ID)
+	if got.LastStatus != ScheduleStatusSuccess || got.LastError != "" || got.NextRunAt != nil {
+		t.Errorf("success not recorded: %+v", got)
+	}
+}
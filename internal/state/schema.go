@@ -35,6 +35,8 @@ const (
 	AgentWorking AgentStatus = "working"
 	AgentError   AgentStatus = "error"
 	AgentCrashed AgentStatus = "crashed"
+	// AgentAwaitingApproval means the agent is blocked on a confirmation prompt.
+	AgentAwaitingApproval AgentStatus = "awaiting_approval"
 )
 
 // AgentType represents the type of AI agent.
@@ -220,7 +220,7 @@ func TestMigrate(t *testing.T) {
 	t.Logf("Existing tables: %v", existingTables)
 
 	// Verify tables exist by trying to query them
-	tables := []string{"sessions", "agents", "tasks", "reservations", "approvals", "context_packs", "tool_health", "event_log", "ensemble_sessions", "mode_assignments", "_migrations"}
+	tables := []string{"sessions", "agents", "tasks", "reservations", "approvals", "context_packs", "tool_health", "event_log", "ensemble_sessions", "mode_assignments", "agent_state_transitions", "_migrations"}
 	for _, table := range tables {
 		r, err := store.db.Query("SELECT 1 FROM " + table + " LIMIT 1")
 		if err != nil {
@@ -0,0 +1,427 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ========================
+// Agent State Transitions
+// ========================
+
+// Transition sources identify which classifier observed a transition.
+const (
+	TransitionSourceStatus = "status" // status.UnifiedDetector
+	TransitionSourceRobot  = "robot"  // robot.StateClassifier
+	TransitionSourceHook   = "hook"   // agenthooks.Recorder (agent CLI hooks)
+)
+
+// AgentStateTransition is a single recorded change of an agent pane's state.
+type AgentStateTransition struct {
+	ID           int64     `json:"id"`
+	SessionID    string    `json:"session"`
+	PaneID       string    `json:"pane_id"`
+	PaneName     string    `json:"pane_name,omitempty"`
+	AgentType    string    `json:"agent_type,omitempty"`
+	FromState    string    `json:"from_state,omitempty"` // Empty for the first observation
+	ToState      string    `json:"to_state"`
+	Source       string    `json:"source,omitempty"`
+	Trigger      string    `json:"trigger,omitempty"`
+	Confidence   float64   `json:"confidence,omitempty"`
+	TransitionAt time.Time `json:"transition_at"`
+}
+
+// TransitionQuery filters state transition queries. Zero values match everything.
+type TransitionQuery struct {
+	SessionID string
+	Pane      string // Matches pane ID or pane name
+	State     string // Matches from_state or to_state
+	Source    string
+	Since     time.Time
+	Until     time.Time
+	Limit     int // Most recent N transitions (0 = no limit)
+}
+
+// StateDwell aggregates time spent in one state by one pane.
+type StateDwell struct {
+	SessionID    string  `json:"session"`
+	PaneID       string  `json:"pane_id"`
+	PaneName     string  `json:"pane_name,omitempty"`
+	Source       string  `json:"source,omitempty"`
+	State        string  `json:"state"`
+	Entries      int     `json:"entries"`
+	TotalSeconds float64 `json:"total_seconds"`
+	AvgSeconds   float64 `json:"avg_seconds"`
+	MaxSeconds   float64 `json:"max_seconds"`
+}
+
+// TransitionStats summarizes state transitions over a time range.
+type TransitionStats struct {
+	Since            time.Time      `json:"since"`
+	Until            time.Time      `json:"until"`
+	TotalTransitions int            `json:"total_transitions"`
+	ByPair           map[string]int `json:"by_pair"` // "error->idle" -> count
+	Dwell            []StateDwell   `json:"dwell"`
+}
+
+const transitionColumns = `id, session_id, pane_id, COALESCE(pane_name, ''), COALESCE(agent_type, ''),
+	COALESCE(from_state, ''), to_state, COALESCE(source, ''), COALESCE(trigger, ''),
+	COALESCE(confidence, 0), transition_at`
+
+// RecordStateTransition persists a state transition.
+func (s *Store) RecordStateTransition(t *AgentStateTransition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.TransitionAt.IsZero() {
+		t.TransitionAt = time.Now().UTC()
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO agent_state_transitions (session_id, pane_id, pane_name, agent_type, from_state, to_state, source, trigger, confidence, transition_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.SessionID, t.PaneID, nullString(t.PaneName), nullString(t.AgentType), nullString(t.FromState), t.ToState,
+		nullString(t.Source), nullString(t.Trigger), t.Confidence, t.TransitionAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("record state transition: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get state transition id: %w", err)
+	}
+	t.ID = id
+	return nil
+}
+
+// RecordStateChange persists t unless the pane's last transition from the
+// same source already ended in t.ToState. FromState is set to that last
+// state. The comparison and the insert share a transaction, so processes
+// recording the same panes never write duplicate or stale transitions.
+// Returns true when a transition was written.
+func (s *Store) RecordStateChange(t *AgentStateTransition) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.TransitionAt.IsZero() {
+		t.TransitionAt = time.Now().UTC()
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var last sql.NullString
+	err = tx.QueryRow(`SELECT to_state FROM agent_state_transitions
+		WHERE session_id = ? AND pane_id = ? AND COALESCE(source, '') = ?
+		ORDER BY transition_at DESC, id DESC LIMIT 1`, t.SessionID, t.PaneID, t.Source).Scan(&last)
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("get last state transition: %w", err)
+	}
+	if last.Valid && last.String == t.ToState {
+		return false, nil
+	}
+	t.FromState = last.String
+
+	result, err := tx.Exec(`
+		INSERT INTO agent_state_transitions (session_id, pane_id, pane_name, agent_type, from_state, to_state, source, trigger, confidence, transition_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.SessionID, t.PaneID, nullString(t.PaneName), nullString(t.AgentType), nullString(t.FromState), t.ToState,
+		nullString(t.Source), nullString(t.Trigger), t.Confidence, t.TransitionAt.UTC(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("record state transition: %w", err)
+	}
+	if t.ID, err = result.LastInsertId(); err != nil {
+		return false, fmt.Errorf("get state transition id: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit state transition: %w", err)
+	}
+	return true, nil
+}
+
+// ListStateTransitions returns transitions matching q in chronological order.
+// With a Limit, the most recent Limit transitions are returned.
+func (s *Store) ListStateTransitions(q TransitionQuery) ([]AgentStateTransition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	where, args := transitionWhere(q)
+	query := `SELECT ` + transitionColumns + ` FROM agent_state_transitions` + where + ` ORDER BY transition_at DESC, id DESC`
+	if q.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, q.Limit)
+	}
+
+	// #nosec G202 -- where clause is internally generated, values are bound
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list state transitions: %w", err)
+	}
+	defer rows.Close()
+
+	transitions, err := scanTransitions(rows)
+	if err != nil {
+		return nil, err
+	}
+	// Reverse into chronological order.
+	for i, j := 0, len(transitions)-1; i < j; i, j = i+1, j-1 {
+		transitions[i], transitions[j] = transitions[j], transitions[i]
+	}
+	return transitions, nil
+}
+
+// LastStateTransition returns the most recent transition recorded for a pane
+// by source, or nil.
+func (s *Store) LastStateTransition(sessionID, paneID, source string) (*AgentStateTransition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT `+transitionColumns+` FROM agent_state_transitions
+		WHERE session_id = ? AND pane_id = ? AND COALESCE(source, '') = ?
+		ORDER BY transition_at DESC, id DESC LIMIT 1`, sessionID, paneID, source)
+	if err != nil {
+		return nil, fmt.Errorf("get last state transition: %w", err)
+	}
+	defer rows.Close()
+
+	transitions, err := scanTransitions(rows)
+	if err != nil || len(transitions) == 0 {
+		return nil, err
+	}
+	return &transitions[0], nil
+}
+
+// StateTransitionStats computes transition counts and per-pane dwell times
+// for the window [q.Since, q.Until]. The state a pane was in when the window
+// opened is taken from its last transition before Since, and the final state
+// is counted up to Until (or now). Dwell is tracked separately per source
+// since classifiers use different state names. Both are computed in SQL from
+// the transitions in the window. Limit is ignored.
+func (s *Store) StateTransitionStats(q TransitionQuery) (*TransitionStats, error) {
+	until := q.Until
+	if until.IsZero() {
+		until = time.Now().UTC()
+	}
+
+	stats := &TransitionStats{
+		Since:  q.Since,
+		Until:  until,
+		ByPair: make(map[string]int),
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counted := q
+	counted.Until = until
+	where, args := transitionWhere(counted)
+	// #nosec G202 -- where clause is internally generated, values are bound
+	rows, err := s.db.Query(`SELECT COALESCE(from_state, ''), to_state, COUNT(*)
+		FROM agent_state_transitions`+where+` GROUP BY 1, 2`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("count state transitions: %w", err)
+	}
+	for rows.Next() {
+		var from, to string
+		var n int
+		if err := rows.Scan(&from, &to, &n); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan state transition counts: %w", err)
+		}
+		stats.TotalTransitions += n
+		if from != "" {
+			stats.ByPair[from+"->"+to] += n
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	// The window's transitions, plus each pane's last transition before it.
+	scope := q
+	scope.Until = until
+	scope.State = ""
+	scope.Limit = 0
+	windowWhere, windowArgs := transitionWhere(scope)
+	base := `SELECT session_id, pane_id, COALESCE(pane_name, '') AS pane_name, COALESCE(source, '') AS source,
+			to_state, transition_at, id
+		FROM agent_state_transitions` + windowWhere
+	baseArgs := windowArgs
+	var since interface{} = ""
+	if !q.Since.IsZero() {
+		since = q.Since.UTC()
+		scope.Since = time.Time{}
+		scope.Until = time.Time{}
+		beforeWhere, beforeArgs := transitionWhere(scope)
+		beforeWhere = andWhere(beforeWhere, "transition_at < ?")
+		base += `
+		UNION ALL
+		SELECT t.session_id, t.pane_id, COALESCE(t.pane_name, ''), COALESCE(t.source, ''), t.to_state, t.transition_at, t.id
+		FROM agent_state_transitions t
+		JOIN (SELECT session_id, pane_id, COALESCE(source, '') AS source, MAX(transition_at) AS at
+			FROM agent_state_transitions` + beforeWhere + `
+			GROUP BY session_id, pane_id, COALESCE(source, '')) o
+		ON t.session_id = o.session_id AND t.pane_id = o.pane_id AND COALESCE(t.source, '') = o.source
+			AND t.transition_at = o.at`
+		baseArgs = append(append(baseArgs, beforeArgs...), since)
+	}
+
+	stateFilter := ""
+	dwellArgs := append(baseArgs, until.UTC(), since)
+	if q.State != "" {
+		stateFilter = " AND to_state = ?"
+		dwellArgs = append(dwellArgs, q.State)
+	}
+	// #nosec G202 -- where clauses are internally generated, values are bound
+	rows, err = s.db.Query(`
+		WITH base AS (`+base+`),
+		spans AS (
+			SELECT session_id, pane_id, pane_name, source, to_state, transition_at, id,
+				unixepoch(COALESCE(LEAD(transition_at) OVER pane, ?), 'subsec')
+					- unixepoch(MAX(transition_at, ?), 'subsec') AS secs
+			FROM base
+			WINDOW pane AS (PARTITION BY session_id, pane_id, source ORDER BY transition_at, id)
+		),
+		dwell AS (
+			SELECT *, LAST_VALUE(pane_name) OVER (
+				PARTITION BY session_id, pane_id, source, to_state ORDER BY transition_at, id
+				ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) AS latest_name
+			FROM spans
+			WHERE secs > 0`+stateFilter+`
+		)
+		SELECT session_id, pane_id, MAX(latest_name), source, to_state, COUNT(*), SUM(secs), MAX(secs)
+		FROM dwell
+		GROUP BY session_id, pane_id, source, to_state
+		ORDER BY session_id, pane_id, source, to_state`, dwellArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("compute state dwell: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d StateDwell
+		if err := rows.Scan(&d.SessionID, &d.PaneID, &d.PaneName, &d.Source, &d.State,
+			&d.Entries, &d.TotalSeconds, &d.MaxSeconds); err != nil {
+			return nil, fmt.Errorf("scan state dwell: %w", err)
+		}
+		d.AvgSeconds = d.TotalSeconds / float64(d.Entries)
+		stats.Dwell = append(stats.Dwell, d)
+	}
+	return stats, rows.Err()
+}
+
+// PruneStateTransitions deletes transitions recorded before cutoff.
+func (s *Store) PruneStateTransitions(cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM agent_state_transitions WHERE transition_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("prune state transitions: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func transitionWhere(q TransitionQuery) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	if q.SessionID != "" {
+		clauses = append(clauses, "session_id = ?")
+		args = append(args, q.SessionID)
+	}
+	if q.Pane != "" {
+		clauses = append(clauses, "(pane_id = ? OR pane_name = ?)")
+		args = append(args, q.Pane, q.Pane)
+	}
+	if q.State != "" {
+		clauses = append(clauses, "(from_state = ? OR to_state = ?)")
+		args = append(args, q.State, q.State)
+	}
+	if q.Source != "" {
+		clauses = append(clauses, "source = ?")
+		args = append(args, q.Source)
+	}
+	if !q.Since.IsZero() {
+		clauses = append(clauses, "transition_at >= ?")
+		args = append(args, q.Since.UTC())
+	}
+	if !q.Until.IsZero() {
+		clauses = append(clauses, "transition_at <= ?")
+		args = append(args, q.Until.UTC())
+	}
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(clauses, " AND "), args
+}
+
+// andWhere adds clause to a where clause from transitionWhere.
+func andWhere(where, clause string) string {
+	if where == "" {
+		return " WHERE " + clause
+	}
+	return where + " AND " + clause
+}
+
+func scanTransitions(rows *sql.Rows) ([]AgentStateTransition, error) {
+	var transitions []AgentStateTransition
+	for rows.Next() {
+		var t AgentStateTransition
+		if err := rows.Scan(&t.ID, &t.SessionID, &t.PaneID, &t.PaneName, &t.AgentType,
+			&t.FromState, &t.ToState, &t.Source, &t.Trigger, &t.Confidence, &t.TransitionAt); err != nil {
+			return nil, fmt.Errorf("scan state transition: %w", err)
+		}
+		transitions = append(transitions, t)
+	}
+	return transitions, rows.Err()
+}
+
+// StateObservation is one classifier reading of a pane's state.
+type StateObservation struct {
+	SessionID  string
+	PaneID     string
+	PaneName   string
+	AgentType  string
+	State      string
+	Source     string
+	Trigger    string
+	Confidence float64
+	At         time.Time
+}
+
+// TransitionRecorder turns a stream of state observations into persisted
+// transitions, writing only when a pane's state differs from the last one
+// recorded by the same source in any process. Safe for concurrent use.
+type TransitionRecorder struct {
+	store *Store
+}
+
+// NewTransitionRecorder creates a recorder backed by store.
+func NewTransitionRecorder(store *Store) *TransitionRecorder {
+	return &TransitionRecorder{store: store}
+}
+
+// Observe records obs if it changes the pane's state. Returns true when a
+// transition was written.
+func (r *TransitionRecorder) Observe(obs StateObservation) (bool, error) {
+	if r == nil || r.store == nil || obs.State == "" || obs.PaneID == "" {
+		return false, nil
+	}
+	return r.store.RecordStateChange(&AgentStateTransition{
+		SessionID:    obs.SessionID,
+		PaneID:       obs.PaneID,
+		PaneName:     obs.PaneName,
+		AgentType:    obs.AgentType,
+		ToState:      obs.State,
+		Source:       obs.Source,
+		Trigger:      obs.Trigger,
+		Confidence:   obs.Confidence,
+		TransitionAt: obs.At,
+	})
+}
@@ -0,0 +1,227 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStateTransitionsRecordAndList(t *testing.T) {
+	store := testStore(t)
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	for i, tr := range []AgentStateTransition{
+		{SessionID: "proj", PaneID: "%1", PaneName: "proj__cc_1", ToState: "idle"},
+		{SessionID: "proj", PaneID: "%1", PaneName: "proj__cc_1", FromState: "idle", ToState: "working", Trigger: "spinner", Confidence: 0.9},
+		{SessionID: "proj", PaneID: "%2", PaneName: "proj__cod_1", ToState: "working"},
+		{SessionID: "other", PaneID: "%1", ToState: "error"},
+	} {
+		tr.TransitionAt = base.Add(time.Duration(i) * time.Minute)
+		if err := store.RecordStateTransition(&tr); err != nil {
+			t.Fatalf("RecordStateTransition: %v", err)
+		}
+		if tr.ID == 0 {
+			t.Fatal("expected ID to be set")
+		}
+	}
+
+	all, err := store.ListStateTransitions(TransitionQuery{SessionID: "proj"})
+	if err != nil {
+		t.Fatalf("ListStateTransitions: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("got %d transitions, want 3", len(all))
+	}
+	if !all[0].TransitionAt.Before(all[2].TransitionAt) {
+		t.Error("transitions should be in chronological order")
+	}
+	if all[1].Trigger != "spinner" || all[1].Confidence != 0.9 || all[1].FromState != "idle" {
+		t.Errorf("unexpected transition fields: %+v", all[1])
+	}
+
+	byName, err := store.ListStateTransitions(TransitionQuery{SessionID: "proj", Pane: "proj__cc_1"})
+	if err != nil {
+		t.Fatalf("ListStateTransitions by pane: %v", err)
+	}
+	if len(byName) != 2 {
+		t.Errorf("pane filter: got %d, want 2", len(byName))
+	}
+
+	ranged, err := store.ListStateTransitions(TransitionQuery{Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)})
+	if err != nil {
+		t.Fatalf("ListStateTransitions by range: %v", err)
+	}
+	if len(ranged) != 2 {
+		t.Errorf("range filter: got %d, want 2", len(ranged))
+	}
+
+	limited, err := store.ListStateTransitions(TransitionQuery{Limit: 1})
+	if err != nil {
+		t.Fatalf("ListStateTransitions with limit: %v", err)
+	}
+	if len(limited) != 1 || limited[0].SessionID != "other" {
+		t.Errorf("limit should return the most recent transition, got %+v", limited)
+	}
+
+	last, err := store.LastStateTransition("proj", "%1", "")
+	if err != nil {
+		t.Fatalf("LastStateTransition: %v", err)
+	}
+	if last == nil || last.ToState != "working" {
+		t.Errorf("LastStateTransition = %+v, want working", last)
+	}
+	if none, _ := store.LastStateTransition("proj", "%9", ""); none != nil {
+		t.Errorf("LastStateTransition for unknown pane = %+v, want nil", none)
+	}
+
+	pruned, err := store.PruneStateTransitions(base.Add(2 * time.Minute))
+	if err != nil {
+		t.Fatalf("PruneStateTransitions: %v", err)
+	}
+	if pruned != 2 {
+		t.Errorf("pruned %d, want 2", pruned)
+	}
+}
+
+func TestStateTransitionStats(t *testing.T) {
+	store := testStore(t)
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	// %1: idle@0 -> working@10m -> idle@40m -> working@50m
+	for _, tr := range []struct {
+		from, to string
+		at       time.Duration
+	}{
+		{"", "idle", 0},
+		{"idle", "working", 10 * time.Minute},
+		{"working", "idle", 40 * time.Minute},
+		{"idle", "working", 50 * time.Minute},
+	} {
+		if err := store.RecordStateTransition(&AgentStateTransition{
+			SessionID: "proj", PaneID: "%1", FromState: tr.from, ToState: tr.to, TransitionAt: base.Add(tr.at),
+		}); err != nil {
+			t.Fatalf("RecordStateTransition: %v", err)
+		}
+	}
+
+	// Window [5m, 60m]: idle 5m + 10m, working 30m + 10m.
+	stats, err := store.StateTransitionStats(TransitionQuery{
+		SessionID: "proj",
+		Since:     base.Add(5 * time.Minute),
+		Until:     base.Add(60 * time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("StateTransitionStats: %v", err)
+	}
+	if stats.TotalTransitions != 3 {
+		t.Errorf("TotalTransitions = %d, want 3", stats.TotalTransitions)
+	}
+	if stats.ByPair["idle->working"] != 2 || stats.ByPair["working->idle"] != 1 {
+		t.Errorf("ByPair = %v", stats.ByPair)
+	}
+
+	want := map[string]StateDwell{
+		"idle":    {Entries: 2, TotalSeconds: 900, AvgSeconds: 450, MaxSeconds: 600},
+		"working": {Entries: 2, TotalSeconds: 2400, AvgSeconds: 1200, MaxSeconds: 1800},
+	}
+	if len(stats.Dwell) != len(want) {
+		t.Fatalf("Dwell = %+v, want %d entries", stats.Dwell, len(want))
+	}
+	for _, d := range stats.Dwell {
+		w := want[d.State]
+		if d.Entries != w.Entries || d.TotalSeconds != w.TotalSeconds || d.AvgSeconds != w.AvgSeconds || d.MaxSeconds != w.MaxSeconds {
+			t.Errorf("dwell[%s] = %+v, want %+v", d.State, d, w)
+		}
+	}
+}
+
+func TestStateTransitionStatsStateFilterAndOpeningState(t *testing.T) {
+	store := testStore(t)
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	for _, tr := range []AgentStateTransition{
+		{SessionID: "proj", PaneID: "%1", ToState: "idle", TransitionAt: base},
+		{SessionID: "proj", PaneID: "%1", FromState: "idle", ToState: "working", TransitionAt: base.Add(20 * time.Minute)},
+		// %2 changes state only before the window
+		{SessionID: "proj", PaneID: "%2", PaneName: "proj__cod_1", ToState: "error", TransitionAt: base.Add(5 * time.Minute)},
+	} {
+		tr := tr
+		if err := store.RecordStateTransition(&tr); err != nil {
+			t.Fatalf("RecordStateTransition: %v", err)
+		}
+	}
+
+	stats, err := store.StateTransitionStats(TransitionQuery{
+		SessionID: "proj",
+		State:     "error",
+		Since:     base.Add(10 * time.Minute),
+		Until:     base.Add(30 * time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("StateTransitionStats: %v", err)
+	}
+	if stats.TotalTransitions != 0 || len(stats.ByPair) != 0 {
+		t.Errorf("counts = %d %v, want none in the window", stats.TotalTransitions, stats.ByPair)
+	}
+	if len(stats.Dwell) != 1 {
+		t.Fatalf("Dwell = %+v, want only %%2's error state", stats.Dwell)
+	}
+	if d := stats.Dwell[0]; d.PaneID != "%2" || d.PaneName != "proj__cod_1" || d.Entries != 1 || d.TotalSeconds != 1200 {
+		t.Errorf("dwell = %+v", d)
+	}
+}
+
+func TestTransitionRecorder(t *testing.T) {
+	store := testStore(t)
+	rec := NewTransitionRecorder(store)
+
+	observe := func(state string) bool {
+		t.Helper()
+		wrote, err := rec.Observe(StateObservation{SessionID: "proj", PaneID: "%1", State: state, Source: TransitionSourceStatus})
+		if err != nil {
+			t.Fatalf("Observe: %v", err)
+		}
+		return wrote
+	}
+
+	if !observe("idle") {
+		t.Error("first observation should be recorded")
+	}
+	if observe("idle") {
+		t.Error("unchanged state should not be recorded")
+	}
+	if !observe("working") {
+		t.Error("state change should be recorded")
+	}
+
+	// A fresh recorder seeds from the store and does not duplicate.
+	rec = NewTransitionRecorder(store)
+	if observe("working") {
+		t.Error("restarted recorder should not re-record the current state")
+	}
+
+	// Recorders in other processes see each other's writes: a stale reading
+	// neither duplicates nor reverts the current state.
+	other := NewTransitionRecorder(store)
+	if wrote, _ := other.Observe(StateObservation{SessionID: "proj", PaneID: "%1", State: "idle", Source: TransitionSourceStatus}); !wrote {
+		t.Error("change from another recorder should be recorded")
+	}
+	if !observe("working") {
+		t.Error("change back should be recorded, not taken from a stale cache")
+	}
+	if observe("working") {
+		t.Error("unchanged state should not be recorded")
+	}
+
+	// Other sources are tracked independently.
+	if wrote, _ := rec.Observe(StateObservation{SessionID: "proj", PaneID: "%1", State: "GENERATING", Source: TransitionSourceRobot}); !wrote {
+		t.Error("first observation from another source should be recorded")
+	}
+
+	all, err := store.ListStateTransitions(TransitionQuery{Source: TransitionSourceStatus})
+	if err != nil {
+		t.Fatalf("ListStateTransitions: %v", err)
+	}
+	if len(all) != 4 || all[1].FromState != "idle" || all[1].ToState != "working" ||
+		all[2].FromState != "working" || all[2].ToState != "idle" || all[3].FromState != "idle" {
+		t.Errorf("transitions = %+v", all)
+	}
+}
@@ -0,0 +1,191 @@
+package state
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ========================
+// Trigger Deliveries
+// ========================
+
+// DeliveryStatus is the outcome of an inbound trigger delivery.
+type DeliveryStatus string
+
+const (
+	DeliveryStatusReceived DeliveryStatus = "received" // Accepted, action not yet started
+	DeliveryStatusStarted  DeliveryStatus = "started"  // Pipeline started or prompt sent
+	DeliveryStatusFailed   DeliveryStatus = "failed"
+)
+
+// TriggerDelivery records one accepted webhook delivery for a named trigger.
+type TriggerDelivery struct {
+	ID         int64                  `json:"id"`
+	Trigger    string                 `json:"trigger"`
+	DeliveryID string                 `json:"delivery_id"`
+	Action     string                 `json:"action"`
+	SessionID  string                 `json:"session,omitempty"`
+	Status     DeliveryStatus         `json:"status"`
+	RunID      string                 `json:"run_id,omitempty"`
+	Vars       map[string]interface{} `json:"vars,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	ReceivedAt time.Time              `json:"received_at"`
+	FinishedAt *time.Time             `json:"finished_at,omitempty"`
+}
+
+const deliveryColumns = `id, trigger_name, delivery_id, action, COALESCE(session_id, ''), status, COALESCE(run_id, ''),
+	COALESCE(vars, ''), COALESCE(error, ''), received_at, finished_at`
+
+// RecordTriggerDelivery stores a new delivery. A failed delivery with the
+// same ID is reset to received and reused, so a redelivery runs it again.
+// It reports false, without error, when the trigger already has a delivery
+// with the same ID that is in flight or succeeded.
+func (s *Store) RecordTriggerDelivery(d *TriggerDelivery) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if d.ReceivedAt.IsZero() {
+		d.ReceivedAt = time.Now().UTC()
+	}
+	if d.Status == "" {
+		d.Status = DeliveryStatusReceived
+	}
+	vars := ""
+	if len(d.Vars) > 0 {
+		data, err := json.Marshal(d.Vars)
+		if err != nil {
+			return false, fmt.Errorf("encode delivery vars: %w", err)
+		}
+		vars = string(data)
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO trigger_deliveries (trigger_name, delivery_id, action, session_id, status, vars, received_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (trigger_name, delivery_id) DO NOTHING`,
+		d.Trigger, d.DeliveryID, d.Action, nullString(d.SessionID), d.Status, nullString(vars), d.ReceivedAt.UTC(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("record trigger delivery: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		id, err := result.LastInsertId()
+		if err != nil {
+			return false, fmt.Errorf("get trigger delivery id: %w", err)
+		}
+		d.ID = id
+		return true, nil
+	}
+
+	result, err = s.db.Exec(`
+		UPDATE trigger_deliveries SET action = ?, session_id = ?, status = ?, run_id = NULL, vars = ?,
+			error = NULL, received_at = ?, finished_at = NULL
+		WHERE trigger_name = ? AND delivery_id = ? AND status = ?`,
+		d.Action, nullString(d.SessionID), d.Status, nullString(vars), d.ReceivedAt.UTC(),
+		d.Trigger, d.DeliveryID, DeliveryStatusFailed,
+	)
+	if err != nil {
+		return false, fmt.Errorf("retry trigger delivery: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := s.db.QueryRow(`SELECT id FROM trigger_deliveries WHERE trigger_name = ? AND delivery_id = ?`,
+		d.Trigger, d.DeliveryID).Scan(&d.ID); err != nil {
+		return false, fmt.Errorf("get trigger delivery id: %w", err)
+	}
+	return true, nil
+}
+
+// GetTriggerDelivery returns a trigger's delivery by delivery ID, or nil if
+// there is none.
+func (s *Store) GetTriggerDelivery(trigger, deliveryID string) (*TriggerDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT `+deliveryColumns+` FROM trigger_deliveries
+		WHERE trigger_name = ? AND delivery_id = ?`, trigger, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("get trigger delivery: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries, err := scanTriggerDeliveries(rows)
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+	return &deliveries[0], nil
+}
+
+// FinishTriggerDelivery records the outcome of a delivery's action.
+func (s *Store) FinishTriggerDelivery(id int64, runID string, actionErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, errMsg := DeliveryStatusStarted, ""
+	if actionErr != nil {
+		status, errMsg = DeliveryStatusFailed, actionErr.Error()
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE trigger_deliveries SET status = ?, run_id = ?, error = ?, finished_at = ?
+		WHERE id = ?`,
+		status, nullString(runID), nullString(errMsg), time.Now().UTC(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("finish trigger delivery: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("trigger delivery not found: %d", id)
+	}
+	return nil
+}
+
+// ListTriggerDeliveries returns a trigger's most recent deliveries, newest
+// first. An empty trigger name lists every trigger's deliveries.
+func (s *Store) ListTriggerDeliveries(trigger string, limit int) ([]TriggerDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := s.db.Query(`SELECT `+deliveryColumns+` FROM trigger_deliveries
+		WHERE ? = '' OR trigger_name = ?
+		ORDER BY received_at DESC, id DESC LIMIT ?`, trigger, trigger, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list trigger deliveries: %w", err)
+	}
+	defer rows.Close()
+	return scanTriggerDeliveries(rows)
+}
+
+func scanTriggerDeliveries(rows *sql.Rows) ([]TriggerDelivery, error) {
+	var deliveries []TriggerDelivery
+	for rows.Next() {
+		var (
+			d          TriggerDelivery
+			vars       string
+			finishedAt sql.NullTime
+		)
+		if err := rows.Scan(&d.ID, &d.Trigger, &d.DeliveryID, &d.Action, &d.SessionID, &d.Status, &d.RunID,
+			&vars, &d.Error, &d.ReceivedAt, &finishedAt); err != nil {
+			return nil, fmt.Errorf("scan trigger delivery: %w", err)
+		}
+		if vars != "" {
+			if err := json.Unmarshal([]byte(vars), &d.Vars); err != nil {
+				return nil, fmt.Errorf("decode delivery vars: %w", err)
+			}
+		}
+		if finishedAt.Valid {
+			t := finishedAt.Time
+			d.FinishedAt = &t
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,82 @@
+package state
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTriggerDeliveriesDedupe(t *testing.T) {
+	store := testStore(t)
+
+	d := &TriggerDelivery{
+		Trigger:    "github-push",
+		DeliveryID: "abc-123",
+		Action:     "pipeline",
+		SessionID:  "proj",
+		Vars:       map[string]interface{}{"branch": "main"},
+	}
+	ok, err := store.RecordTriggerDelivery(d)
+	if err != nil || !ok {
+		t.Fatalf("RecordTriggerDelivery = %v, %v", ok, err)
+	}
+	if d.ID == 0 || d.Status != DeliveryStatusReceived {
+		t.Fatalf("defaults not applied: %+v", d)
+	}
+
+	ok, err = store.RecordTriggerDelivery(&TriggerDelivery{Trigger: "github-push", DeliveryID: "abc-123", Action: "pipeline"})
+	if err != nil || ok {
+		t.Fatalf("duplicate RecordTriggerDelivery = %v, %v, want false", ok, err)
+	}
+	// The same delivery ID on another trigger is a different delivery.
+	ok, err = store.RecordTriggerDelivery(&TriggerDelivery{Trigger: "alerts", DeliveryID: "abc-123", Action: "send"})
+	if err != nil || !ok {
+		t.Fatalf("other trigger RecordTriggerDelivery = %v, %v", ok, err)
+	}
+
+	if err := store.FinishTriggerDelivery(d.ID, "run-1", nil); err != nil {
+		t.Fatalf("FinishTriggerDelivery: %v", err)
+	}
+	got, err := store.GetTriggerDelivery("github-push", "abc-123")
+	if err != nil || got == nil {
+		t.Fatalf("GetTriggerDelivery = %v, %v", got, err)
+	}
+	if got.Status != DeliveryStatusStarted || got.RunID != "run-1" || got.FinishedAt == nil || got.Vars["branch"] != "main" {
+		t.Errorf("unexpected delivery: %+v", got)
+	}
+
+	if err := store.FinishTriggerDelivery(999, "", errors.New("boom")); err == nil {
+		t.Error("expected error for unknown delivery")
+	}
+
+	list, err := store.ListTriggerDeliveries("github-push", 0)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListTriggerDeliveries(github-push) = %d, %v", len(list), err)
+	}
+	all, err := store.ListTriggerDeliveries("", 10)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("ListTriggerDeliveries(all) = %d, %v", len(all), err)
+	}
+	if missing, err := store.GetTriggerDelivery("github-push", "nope"); err != nil || missing != nil {
+		t.Errorf("GetTriggerDelivery(missing) = %v, %v", missing, err)
+	}
+
+	// A failed delivery is reused by its retry; one in flight is not.
+	failed := &TriggerDelivery{Trigger: "alerts", DeliveryID: "f-1", Action: "send"}
+	if ok, err := store.RecordTriggerDelivery(failed); !ok || err != nil {
+		t.Fatalf("RecordTriggerDelivery(f-1) = %v, %v", ok, err)
+	}
+	if err := store.FinishTriggerDelivery(failed.ID, "", errors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+	retry := &TriggerDelivery{Trigger: "alerts", DeliveryID: "f-1", Action: "send"}
+	if ok, err := store.RecordTriggerDelivery(retry); !ok || err != nil || retry.ID != failed.ID {
+		t.Fatalf("retry of failed delivery = %v, %v (id %d, want %d)", ok, err, retry.ID, failed.ID)
+	}
+	got, err = store.GetTriggerDelivery("alerts", "f-1")
+	if err != nil || got.Status != DeliveryStatusReceived || got.Error != "" || got.FinishedAt != nil {
+		t.Errorf("retried delivery = %+v, %v", got, err)
+	}
+	if ok, err := store.RecordTriggerDelivery(&TriggerDelivery{Trigger: "alerts", DeliveryID: "f-1", Action: "send"}); ok || err != nil {
+		t.Errorf("retry of in-flight delivery = %v, %v, want false", ok, err)
+	}
+}
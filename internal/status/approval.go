@@ -0,0 +1,306 @@
+package status
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ApprovalKind classifies an interactive confirmation prompt shown by an agent.
+type ApprovalKind string
+
+const (
+	// ApprovalKindCommand is a request to run a shell command or tool.
+	ApprovalKindCommand ApprovalKind = "command"
+	// ApprovalKindEdit is a request to create, modify or delete a file.
+	ApprovalKindEdit ApprovalKind = "edit"
+	// ApprovalKindTrust is a workspace/folder trust dialog.
+	ApprovalKindTrust ApprovalKind = "trust"
+	// ApprovalKindConfirm is a generic yes/no confirmation.
+	ApprovalKindConfirm ApprovalKind = "confirm"
+)
+
+// ApprovalPrompt describes a confirmation prompt an agent is blocked on.
+type ApprovalPrompt struct {
+	// AgentType is the agent type the prompt was detected for (cc, cod, gmi, ...).
+	AgentType string `json:"agent_type,omitempty"`
+	// Kind classifies the prompt.
+	Kind ApprovalKind `json:"kind"`
+	// Question is the prompt line itself (e.g. "Do you want to proceed?").
+	Question string `json:"question"`
+	// Action is the requested action text (command, file path, tool call).
+	Action string `json:"action,omitempty"`
+	// Tool is the tool header shown above the prompt, when present (e.g. "Bash command").
+	Tool string `json:"tool,omitempty"`
+	// Options lists the choices offered below the prompt.
+	Options []string `json:"options,omitempty"`
+	// Pattern is the name of the pattern that matched.
+	Pattern string `json:"pattern"`
+	// ApproveKeys are the tmux keys that accept the prompt once.
+	ApproveKeys []string `json:"approve_keys,omitempty"`
+	// DenyKeys are the tmux keys that reject the prompt.
+	DenyKeys []string `json:"deny_keys,omitempty"`
+}
+
+// ApprovalPattern defines how to recognise one agent's confirmation prompt.
+type ApprovalPattern struct {
+	// Name uniquely identifies the pattern (reported in ApprovalPrompt.Pattern).
+	Name string
+	// AgentType restricts the pattern to an agent type; empty applies to all.
+	AgentType string
+	// Kind classifies prompts matched by this pattern.
+	Kind ApprovalKind
+	// Regex matches the question line. A named group "action" captures the
+	// requested action directly from the question when the agent inlines it.
+	Regex *regexp.Regexp
+	// LastLineOnly requires the match on the last non-empty line. Used for
+	// terse generic prompts like "[y/N]" that are noisy elsewhere in scrollback.
+	LastLineOnly bool
+	// ApproveKeys/DenyKeys are the keystrokes that answer the prompt.
+	ApproveKeys []string
+	DenyKeys    []string
+	// Description explains what this pattern matches (for debugging).
+	Description string
+}
+
+// approvalWindowLines bounds how far from the end of the output a prompt may
+// appear. Confirmation dialogs are drawn at the bottom of the pane; anything
+// further up is stale scrollback from an already-answered prompt.
+const approvalWindowLines = 14
+
+// approvalContextLines bounds how far above the question the action is searched.
+const approvalContextLines = 16
+
+var errInvalidApprovalPattern = errors.New("approval pattern requires a regex")
+
+var approvalPatternsMu sync.RWMutex
+
+// approvalPatterns contains the built-in confirmation prompt patterns.
+var approvalPatterns = []ApprovalPattern{
+	// Claude Code permission dialogs
+	{Name: "claude_edit_file", AgentType: "cc", Kind: ApprovalKindEdit, Regex: regexp.MustCompile(`(?i)do you want to (?P<action>(?:make this edit to|create|overwrite|delete)\s+[^?]+)\?`), ApproveKeys: []string{"1"}, DenyKeys: []string{"Escape"}, Description: "Claude Code file edit permission"},
+	{Name: "claude_trust_folder", AgentType: "cc", Kind: ApprovalKindTrust, Regex: regexp.MustCompile(`(?i)do you trust the files in this folder\?`), ApproveKeys: []string{"1"}, DenyKeys: []string{"Escape"}, Description: "Claude Code folder trust dialog"},
+	{Name: "claude_allow_tool", AgentType: "cc", Kind: ApprovalKindCommand, Regex: regexp.MustCompile(`(?i)do you want to allow (?:claude to )?(?P<action>[^?]+)\?`), ApproveKeys: []string{"1"}, DenyKeys: []string{"Escape"}, Description: "Claude Code tool permission"},
+	{Name: "claude_proceed", AgentType: "cc", Kind: ApprovalKindCommand, Regex: regexp.MustCompile(`(?i)do you want to proceed\?`), ApproveKeys: []string{"1"}, DenyKeys: []string{"Escape"}, Description: "Claude Code command permission"},
+
+	// Codex CLI approval overlays
+	{Name: "codex_run_command", AgentType: "cod", Kind: ApprovalKindCommand, Regex: regexp.MustCompile(`(?i)would you like to run the following command\?`), ApproveKeys: []string{"y"}, DenyKeys: []string{"Escape"}, Description: "Codex command approval"},
+	{Name: "codex_allow_command", AgentType: "cod", Kind: ApprovalKindCommand, Regex: regexp.MustCompile(`(?i)allow command\?`), ApproveKeys: []string{"y"}, DenyKeys: []string{"n"}, Description: "Codex legacy command approval"},
+	{Name: "codex_apply_edits", AgentType: "cod", Kind: ApprovalKindEdit, Regex: regexp.MustCompile(`(?i)would you like to (?:make|apply) the following edits\?`), ApproveKeys: []string{"y"}, DenyKeys: []string{"Escape"}, Description: "Codex patch approval"},
+	{Name: "codex_trust_folder", AgentType: "cod", Kind: ApprovalKindTrust, Regex: regexp.MustCompile(`(?i)(?:allow codex to work in this folder|do you trust the contents of this directory)`), ApproveKeys: []string{"Enter"}, DenyKeys: []string{"Escape"}, Description: "Codex folder trust dialog"},
+
+	// Gemini CLI confirmation dialogs
+	{Name: "gemini_allow_execution", AgentType: "gmi", Kind: ApprovalKindCommand, Regex: regexp.MustCompile(`(?i)allow execution of:?\s*(?P<action>.+?)\?\s*$`), ApproveKeys: []string{"1"}, DenyKeys: []string{"Escape"}, Description: "Gemini shell execution confirmation"},
+	{Name: "gemini_apply_change", AgentType: "gmi", Kind: ApprovalKindEdit, Regex: regexp.MustCompile(`(?i)apply this change\?`), ApproveKeys: []string{"1"}, DenyKeys: []string{"Escape"}, Description: "Gemini edit confirmation"},
+	{Name: "gemini_trust_folder", AgentType: "gmi", Kind: ApprovalKindTrust, Regex: regexp.MustCompile(`(?i)do you trust this folder\?`), ApproveKeys: []string{"1"}, DenyKeys: []string{"Escape"}, Description: "Gemini folder trust dialog"},
+	{Name: "gemini_allow_tool", AgentType: "gmi", Kind: ApprovalKindCommand, Regex: regexp.MustCompile(`(?i)allow (?:execution of )?(?:mcp )?tool\s+"?(?P<action>[^"?]+)"?`), ApproveKeys: []string{"1"}, DenyKeys: []string{"Escape"}, Description: "Gemini tool confirmation"},
+
+	// Generic confirmations (any agent)
+	{Name: "generic_proceed", Kind: ApprovalKindConfirm, Regex: regexp.MustCompile(`(?i)do you want to (?:proceed|continue)\?`), ApproveKeys: []string{"Enter"}, DenyKeys: []string{"Escape"}, Description: "Generic proceed confirmation"},
+	{Name: "generic_yes_no", Kind: ApprovalKindConfirm, Regex: regexp.MustCompile(`(?i)(?:\(y/n\)|\[y/n\])\s*[:?]?\s*$`), LastLineOnly: true, ApproveKeys: []string{"y", "Enter"}, DenyKeys: []string{"n", "Enter"}, Description: "Generic y/n confirmation"},
+}
+
+// approvalAgentAliases maps long agent names (as used by the robot pattern
+// library) to the short pane types used here.
+var approvalAgentAliases = map[string]string{
+	"claude": "cc",
+	"codex":  "cod",
+	"gemini": "gmi",
+}
+
+var (
+	// approvalBoxChars are box-drawing and selection glyphs agents draw around dialogs.
+	approvalBoxChars = "│┃|╭╮╰╯─━┌┐└┘├┤▌▐❯›>●○◉◯•*"
+
+	approvalOptionRegex  = regexp.MustCompile(`^(?:\d+[.)]\s+)(.+)$`)
+	approvalCommandRegex = regexp.MustCompile(`^\$\s+(.+)$`)
+	approvalToolRegex    = regexp.MustCompile(`(?i)^(bash command|bash|shell|run shell command|edit file|edit|create file|write|read file|read|fetch|web ?fetch|web ?search|mcp tool|tool use)\b\s*(?:\((.+)\))?\s*$`)
+)
+
+// AddApprovalPattern registers an additional confirmation prompt pattern.
+// Patterns added later are checked after the built-ins.
+func AddApprovalPattern(p ApprovalPattern) error {
+	if p.Regex == nil {
+		return errInvalidApprovalPattern
+	}
+	if p.Kind == "" {
+		p.Kind = ApprovalKindConfirm
+	}
+
+	approvalPatternsMu.Lock()
+	defer approvalPatternsMu.Unlock()
+	approvalPatterns = append(approvalPatterns, p)
+	return nil
+}
+
+// AddApprovalPatternString compiles and registers a confirmation prompt pattern.
+func AddApprovalPatternString(name, agentType string, kind ApprovalKind, pattern string, approveKeys, denyKeys []string) error {
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	return AddApprovalPattern(ApprovalPattern{
+		Name:        name,
+		AgentType:   agentType,
+		Kind:        kind,
+		Regex:       regex,
+		ApproveKeys: approveKeys,
+		DenyKeys:    denyKeys,
+	})
+}
+
+// ApprovalPatterns returns a copy of the registered confirmation prompt patterns.
+func ApprovalPatterns() []ApprovalPattern {
+	approvalPatternsMu.RLock()
+	defer approvalPatternsMu.RUnlock()
+	out := make([]ApprovalPattern, len(approvalPatterns))
+	copy(out, approvalPatterns)
+	return out
+}
+
+// DetectApprovalPrompt reports whether the tail of output shows an agent
+// blocked on an interactive confirmation prompt, and if so describes it.
+// Returns nil when no prompt is pending.
+func DetectApprovalPrompt(output string, agentType string) *ApprovalPrompt {
+	if alias, ok := approvalAgentAliases[agentType]; ok {
+		agentType = alias
+	}
+
+	lines := approvalTail(StripANSI(output))
+	if len(lines) == 0 {
+		return nil
+	}
+
+	approvalPatternsMu.RLock()
+	defer approvalPatternsMu.RUnlock()
+
+	for _, p := range approvalPatterns {
+		if p.AgentType != "" && p.AgentType != agentType {
+			continue
+		}
+
+		start := 0
+		if p.LastLineOnly {
+			start = len(lines) - 1
+		}
+		for i := len(lines) - 1; i >= start; i-- {
+			line := trimDialogLine(lines[i])
+			match := p.Regex.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			return buildApprovalPrompt(p, agentType, lines, i, line, match)
+		}
+	}
+	return nil
+}
+
+// approvalTail returns the last approvalWindowLines non-empty lines.
+func approvalTail(clean string) []string {
+	raw := strings.Split(clean, "\n")
+	var tail []string
+	for i := len(raw) - 1; i >= 0 && len(tail) < approvalWindowLines+approvalContextLines; i-- {
+		if strings.TrimSpace(raw[i]) == "" {
+			continue
+		}
+		tail = append(tail, raw[i])
+	}
+	// Reverse into display order.
+	for i, j := 0, len(tail)-1; i < j; i, j = i+1, j-1 {
+		tail[i], tail[j] = tail[j], tail[i]
+	}
+	if len(tail) == 0 {
+		return nil
+	}
+	return tail
+}
+
+func buildApprovalPrompt(p ApprovalPattern, agentType string, lines []string, idx int, question string, match []string) *ApprovalPrompt {
+	// Only prompts within the bottom window count; older matches are stale.
+	if len(lines)-idx > approvalWindowLines {
+		return nil
+	}
+
+	prompt := &ApprovalPrompt{
+		AgentType:   agentType,
+		Kind:        p.Kind,
+		Question:    question,
+		Pattern:     p.Name,
+		ApproveKeys: append([]string(nil), p.ApproveKeys...),
+		DenyKeys:    append([]string(nil), p.DenyKeys...),
+	}
+
+	// Options and inline commands below the question.
+	for _, line := range lines[idx+1:] {
+		trimmed := trimDialogLine(line)
+		if m := approvalOptionRegex.FindStringSubmatch(trimmed); m != nil {
+			prompt.Options = append(prompt.Options, strings.TrimSpace(m[1]))
+			continue
+		}
+		if prompt.Action == "" {
+			if m := approvalCommandRegex.FindStringSubmatch(trimmed); m != nil {
+				prompt.Action = strings.TrimSpace(m[1])
+			}
+		}
+	}
+
+	// 1. Action captured inline by the question pattern.
+	for i, name := range p.Regex.SubexpNames() {
+		if name == "action" && i < len(match) && strings.TrimSpace(match[i]) != "" {
+			prompt.Action = cleanApprovalAction(match[i])
+		}
+	}
+
+	// 2. Tool header / "$ command" lines above the question.
+	lo := idx - approvalContextLines
+	if lo < 0 {
+		lo = 0
+	}
+	above := lines[lo:idx]
+	for i := len(above) - 1; i >= 0; i-- {
+		trimmed := trimDialogLine(above[i])
+		if prompt.Action == "" {
+			if m := approvalCommandRegex.FindStringSubmatch(trimmed); m != nil {
+				prompt.Action = strings.TrimSpace(m[1])
+			}
+		}
+		if m := approvalToolRegex.FindStringSubmatch(trimmed); m != nil {
+			prompt.Tool = strings.TrimSpace(m[1])
+			if prompt.Action == "" && len(m) > 2 && m[2] != "" {
+				prompt.Action = cleanApprovalAction(m[2])
+			}
+			if prompt.Action == "" {
+				for _, next := range above[i+1:] {
+					if text := trimDialogLine(next); text != "" {
+						prompt.Action = text
+						break
+					}
+				}
+			}
+			break
+		}
+	}
+
+	if prompt.Action == "" && prompt.Kind != ApprovalKindTrust {
+		prompt.Action = question
+	}
+	return prompt
+}
+
+// trimDialogLine strips box-drawing borders and selection markers.
+func trimDialogLine(line string) string {
+	for {
+		trimmed := strings.Trim(strings.TrimSpace(line), approvalBoxChars)
+		if trimmed == line {
+			return trimmed
+		}
+		line = trimmed
+	}
+}
+
+func cleanApprovalAction(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.Trim(s, "'\"`[]")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,209 @@
+package status
+
+import (
+	"testing"
+	"time"
+)
+
+const claudeBashPrompt = `● I'll run the test suite now.
+
+╭──────────────────────────────────────────────────────────────╮
+│ Bash command                                                 │
+│                                                              │
+│   go test ./internal/...                                     │
+│   Run the unit tests                                         │
+│                                                              │
+│ Do you want to proceed?                                      │
+│ ❯ 1. Yes                                                     │
+│   2. Yes, and don't ask again for go test commands           │
+│   3. No, and tell Claude what to do differently (esc)        │
+╰──────────────────────────────────────────────────────────────╯
+`
+
+const claudeEditPrompt = `╭──────────────────────────────────────────╮
+│ Edit file                                │
+│ Do you want to make this edit to main.go? │
+│ ❯ 1. Yes                                 │
+│   2. No, and tell Claude what to do       │
+╰──────────────────────────────────────────╯
+`
+
+const codexCommandPrompt = `Would you like to run the following command?
+
+  $ rm -rf build/
+
+▌ 1. Yes, proceed
+▌ 2. No, and tell Codex what to do differently
+`
+
+const geminiExecPrompt = `╭────────────────────────────────────────╮
+│ ?  Shell npm install (Install deps)    │
+│                                        │
+│ Allow execution of: 'npm install'?     │
+│                                        │
+│ ● 1. Yes, allow once                   │
+│   2. Yes, allow always                 │
+│   3. No (esc)                          │
+╰────────────────────────────────────────╯
+`
+
+func TestDetectApprovalPrompt(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		agentType   string
+		wantPattern string
+		wantKind    ApprovalKind
+		wantAction  string
+		wantOptions int
+	}{
+		{
+			name:        "claude bash command",
+			output:      claudeBashPrompt,
+			agentType:   "cc",
+			wantPattern: "claude_proceed",
+			wantKind:    ApprovalKindCommand,
+			wantAction:  "go test ./internal/...",
+			wantOptions: 3,
+		},
+		{
+			name:        "claude edit",
+			output:      claudeEditPrompt,
+			agentType:   "cc",
+			wantPattern: "claude_edit_file",
+			wantKind:    ApprovalKindEdit,
+			wantAction:  "make this edit to main.go",
+			wantOptions: 2,
+		},
+		{
+			name:        "claude trust folder",
+			output:      "Do you trust the files in this folder?\n\n/home/me/project\n\n❯ 1. Yes, proceed\n  2. No, exit\n",
+			agentType:   "claude",
+			wantPattern: "claude_trust_folder",
+			wantKind:    ApprovalKindTrust,
+			wantOptions: 2,
+		},
+		{
+			name:        "codex command",
+			output:      codexCommandPrompt,
+			agentType:   "cod",
+			wantPattern: "codex_run_command",
+			wantKind:    ApprovalKindCommand,
+			wantAction:  "rm -rf build/",
+			wantOptions: 2,
+		},
+		{
+			name:        "gemini execution",
+			output:      geminiExecPrompt,
+			agentType:   "gmi",
+			wantPattern: "gemini_allow_execution",
+			wantKind:    ApprovalKindCommand,
+			wantAction:  "npm install",
+			wantOptions: 3,
+		},
+		{
+			name:        "generic y/n on last line",
+			output:      "Overwrite existing config? (y/n)",
+			agentType:   "user",
+			wantPattern: "generic_yes_no",
+			wantKind:    ApprovalKindConfirm,
+			wantAction:  "Overwrite existing config? (y/n)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectApprovalPrompt(tt.output, tt.agentType)
+			if got == nil {
+				t.Fatalf("DetectApprovalPrompt() = nil, want pattern %q", tt.wantPattern)
+			}
+			if got.Pattern != tt.wantPattern {
+				t.Errorf("Pattern = %q, want %q", got.Pattern, tt.wantPattern)
+			}
+			if got.Kind != tt.wantKind {
+				t.Errorf("Kind = %q, want %q", got.Kind, tt.wantKind)
+			}
+			if got.Action != tt.wantAction {
+				t.Errorf("Action = %q, want %q", got.Action, tt.wantAction)
+			}
+			if len(got.Options) != tt.wantOptions {
+				t.Errorf("Options = %v, want %d entries", got.Options, tt.wantOptions)
+			}
+			if len(got.ApproveKeys) == 0 || len(got.DenyKeys) == 0 {
+				t.Errorf("expected approve/deny keys, got %v / %v", got.ApproveKeys, got.DenyKeys)
+			}
+		})
+	}
+}
+
+func TestDetectApprovalPrompt_NoPrompt(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		agentType string
+	}{
+		{"empty", "", "cc"},
+		{"idle claude", "Done! All tests pass.\n\n> ", "cc"},
+		{"wrong agent", codexCommandPrompt, "cc"},
+		{"y/n in scrollback", "Overwrite? (y/n) y\nwrote config\n$ ", "user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectApprovalPrompt(tt.output, tt.agentType); got != nil {
+				t.Errorf("DetectApprovalPrompt() = %+v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestDetectApprovalPrompt_StaleScrollback(t *testing.T) {
+	output := claudeBashPrompt
+	for i := 0; i < 40; i++ {
+		output += "output line\n"
+	}
+	if got := DetectApprovalPrompt(output, "cc"); got != nil {
+		t.Errorf("answered prompt in scrollback detected as pending: %+v", got)
+	}
+}
+
+func TestAddApprovalPatternString(t *testing.T) {
+	saved := ApprovalPatterns()
+	t.Cleanup(func() {
+		approvalPatternsMu.Lock()
+		approvalPatterns = saved
+		approvalPatternsMu.Unlock()
+	})
+
+	if err := AddApprovalPatternString("custom", "aider", ApprovalKindEdit, `(?i)apply edits to (?P<action>\S+)\?`, []string{"y"}, []string{"n"}); err != nil {
+		t.Fatalf("AddApprovalPatternString: %v", err)
+	}
+	got := DetectApprovalPrompt("Apply edits to main.py?", "aider")
+	if got == nil || got.Pattern != "custom" || got.Action != "main.py" {
+		t.Fatalf("custom pattern not applied: %+v", got)
+	}
+
+	if err := AddApprovalPatternString("bad", "", "", `(`, nil, nil); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+	if err := AddApprovalPattern(ApprovalPattern{Name: "nil"}); err == nil {
+		t.Error("expected error for nil regex")
+	}
+}
+
+func TestDetermineState_AwaitingApproval(t *testing.T) {
+	d := NewDetector()
+	// Even with recent activity and error text in scrollback, a pending
+	// prompt wins.
+	output := "Error: connection refused\n" + claudeBashPrompt
+	st := d.Analyze("proj", "%1", "proj__cc_1", "cc", output, time.Now())
+	if st.State != StateAwaitingApproval {
+		t.Fatalf("State = %q, want %q", st.State, StateAwaitingApproval)
+	}
+	if st.PendingApproval == nil || st.PendingApproval.Action != "go test ./internal/..." {
+		t.Errorf("PendingApproval = %+v", st.PendingApproval)
+	}
+	if st.IsHealthy() {
+		t.Error("awaiting approval should not count as healthy")
+	}
+}
@@ -2,6 +2,7 @@ package status
 
 import (
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -156,6 +157,9 @@ func IsError(e ErrorType) bool {
 }
 
 // AddErrorPattern allows adding custom error patterns at runtime.
+// The pattern is placed after the existing patterns of the same type, so it
+// keeps that type's priority (a custom rate-limit pattern still outranks the
+// generic error patterns).
 // It is thread-safe and can be called concurrently with detection functions.
 func AddErrorPattern(errorType ErrorType, pattern string, description string) error {
 	regex, err := regexp.Compile(pattern)
@@ -166,11 +170,19 @@ func AddErrorPattern(errorType ErrorType, pattern string, description string) er
 	errorPatternsMu.Lock()
 	defer errorPatternsMu.Unlock()
 
-	errorPatterns = append(errorPatterns, ErrorPattern{
+	p := ErrorPattern{
 		Type:        errorType,
 		Regex:       regex,
 		Description: description,
-	})
+	}
+	at := len(errorPatterns)
+	for i := len(errorPatterns) - 1; i >= 0; i-- {
+		if errorPatterns[i].Type == errorType {
+			at = i + 1
+			break
+		}
+	}
+	errorPatterns = slices.Insert(errorPatterns, at, p)
 
 	return nil
 }
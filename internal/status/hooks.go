@@ -0,0 +1,197 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// TriggerAgentHook is the trigger prefix for states reported by agent CLI
+// hooks (e.g. "agent_hook:turn_start").
+const TriggerAgentHook = "agent_hook"
+
+// hookStateMaxAge bounds how long a hook-reported state is trusted without a
+// newer hook event. Agents can die mid-turn without firing their stop hook.
+const hookStateMaxAge = 30 * time.Minute
+
+// hookIdleGrace is how much pane output may follow a hook-reported idle or
+// approval state before scraping is trusted again. Some agents (Codex) only
+// report turn ends, so a new turn shows up as output first.
+const hookIdleGrace = 3 * time.Second
+
+// hookQuietLimit is how long a pane that shows an idle prompt must have been
+// quiet before scraping overrides a hook-reported working state. Interrupted
+// turns (Esc in Claude Code) never fire a stop hook.
+const hookQuietLimit = 30 * time.Second
+
+// HookState is the agent state last reported by the agent CLI's own hooks
+// for a pane (see internal/agenthooks). It is exact where scraping guesses,
+// so detection prefers it while it is fresh.
+type HookState struct {
+	Session string     `json:"session"`
+	PaneID  string     `json:"pane_id"`
+	Agent   string     `json:"agent,omitempty"`
+	State   AgentState `json:"state"`
+	Event   string     `json:"event"` // Hook event kind that set State (e.g. "turn_start")
+	Tool    string     `json:"tool,omitempty"`
+	Message string     `json:"message,omitempty"`
+	At      time.Time  `json:"at"`
+}
+
+// HookStateDir is where hook states are kept, one directory per session
+// holding one file per pane. Tests may replace it.
+var HookStateDir = func() string {
+	dir, err := util.NTMDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "hooks", "state")
+}
+
+func hookSessionDir(session string) string {
+	dir := HookStateDir()
+	if dir == "" || session == "" {
+		return ""
+	}
+	return filepath.Join(dir, url.PathEscape(session))
+}
+
+func hookStatePath(session, paneID string) string {
+	dir := hookSessionDir(session)
+	if dir == "" || paneID == "" {
+		return ""
+	}
+	return filepath.Join(dir, strings.TrimPrefix(paneID, "%")+".json")
+}
+
+// WriteHookState records the state a hook reported for a pane. Pane IDs are
+// reused after a tmux server restart, so states are keyed by session and
+// pane, and removed once the pane or session is gone.
+func WriteHookState(hs HookState) error {
+	path := hookStatePath(hs.Session, hs.PaneID)
+	if path == "" {
+		return fmt.Errorf("no hook state path for pane %q in session %q", hs.PaneID, hs.Session)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating hook state dir: %w", err)
+	}
+	data, err := json.Marshal(hs)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("writing hook state: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadHookState returns the last hook-reported state for a pane.
+func ReadHookState(session, paneID string) (*HookState, bool) {
+	path := hookStatePath(session, paneID)
+	if path == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var hs HookState
+	if err := json.Unmarshal(data, &hs); err != nil || hs.State == "" {
+		return nil, false
+	}
+	return &hs, true
+}
+
+// RemoveHookStates deletes the hook states of every pane in a session.
+func RemoveHookStates(session string) error {
+	dir := hookSessionDir(session)
+	if dir == "" {
+		return nil
+	}
+	return os.RemoveAll(dir)
+}
+
+// PruneHookStates deletes the hook states of sessions that are not in live,
+// including states left behind by a previous tmux server.
+func PruneHookStates(live []string) error {
+	dir := HookStateDir()
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	keep := make(map[string]bool, len(live))
+	for _, session := range live {
+		keep[url.PathEscape(session)] = true
+	}
+	for _, e := range entries {
+		if !keep[e.Name()] {
+			_ = os.RemoveAll(filepath.Join(dir, e.Name()))
+		}
+	}
+	return nil
+}
+
+// pruneHookPanes deletes the hook states of panes that are no longer part
+// of session.
+func pruneHookPanes(session string, panes []tmux.PaneActivity) {
+	dir := hookSessionDir(session)
+	if dir == "" {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	live := make(map[string]bool, len(panes))
+	for _, p := range panes {
+		live[strings.TrimPrefix(p.Pane.ID, "%")+".json"] = true
+	}
+	for _, e := range entries {
+		// In-flight writes (.tmp) are left alone.
+		if strings.HasSuffix(e.Name(), ".json") && !live[e.Name()] {
+			_ = os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
+// applyHookState overrides a scraped reading with the pane's hook-reported
+// state when that state is fresh. Scraped errors and visible approval
+// prompts are kept, since hooks report neither reliably.
+func applyHookState(session, paneID string, r stateReading, lastActivity time.Time) stateReading {
+	hs, ok := ReadHookState(session, paneID)
+	if !ok || time.Since(hs.At) > hookStateMaxAge {
+		return r
+	}
+	if r.state == StateError || r.state == StateAwaitingApproval {
+		return r
+	}
+
+	switch hs.State {
+	case StateWorking:
+		quiet := time.Since(lastActivity) >= hookQuietLimit
+		if r.trigger == TriggerIdlePrompt && quiet && lastActivity.After(hs.At) {
+			return r
+		}
+	case StateIdle, StateAwaitingApproval:
+		if lastActivity.After(hs.At.Add(hookIdleGrace)) {
+			return r
+		}
+	default:
+		return r
+	}
+	return stateReading{hs.State, ErrorNone, TriggerAgentHook + ":" + hs.Event, 0.99}
+}
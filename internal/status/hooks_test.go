@@ -0,0 +1,130 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func useHookStateDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	orig := HookStateDir
+	HookStateDir = func() string { return dir }
+	t.Cleanup(func() { HookStateDir = orig })
+}
+
+func TestHookStateRoundTrip(t *testing.T) {
+	useHookStateDir(t)
+
+	if _, ok := ReadHookState("proj", "%7"); ok {
+		t.Fatal("ReadHookState found a state before any was written")
+	}
+	at := time.Now().UTC().Truncate(time.Second)
+	if err := WriteHookState(HookState{Session: "proj", PaneID: "%7", State: StateWorking, Event: "tool_start", Tool: "Bash", At: at}); err != nil {
+		t.Fatal(err)
+	}
+	hs, ok := ReadHookState("proj", "%7")
+	if !ok || hs.State != StateWorking || hs.Tool != "Bash" || !hs.At.Equal(at) {
+		t.Errorf("ReadHookState = %+v, %v", hs, ok)
+	}
+	if _, ok := ReadHookState("other", "%7"); ok {
+		t.Error("a pane ID in another session read this session's hook state")
+	}
+}
+
+func TestHookStatesRemovedWithPaneOrSession(t *testing.T) {
+	useHookStateDir(t)
+	at := time.Now()
+	for _, hs := range []HookState{
+		{Session: "proj", PaneID: "%1", State: StateWorking, At: at},
+		{Session: "proj", PaneID: "%2", State: StateIdle, At: at},
+		{Session: "gone/x", PaneID: "%1", State: StateIdle, At: at},
+		{Session: "killed", PaneID: "%3", State: StateIdle, At: at},
+	} {
+		if err := WriteHookState(hs); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pruneHookPanes("proj", []tmux.PaneActivity{{Pane: tmux.Pane{ID: "%2"}}})
+	if _, ok := ReadHookState("proj", "%1"); ok {
+		t.Error("state of a closed pane survived")
+	}
+	if _, ok := ReadHookState("proj", "%2"); !ok {
+		t.Error("state of a live pane was removed")
+	}
+
+	if err := RemoveHookStates("killed"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ReadHookState("killed", "%3"); ok {
+		t.Error("state of a killed session survived")
+	}
+
+	if err := PruneHookStates([]string{"proj"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ReadHookState("gone/x", "%1"); ok {
+		t.Error("state of an ended session survived")
+	}
+	if _, ok := ReadHookState("proj", "%2"); !ok {
+		t.Error("state of a live session was pruned")
+	}
+}
+
+func TestApplyHookState(t *testing.T) {
+	useHookStateDir(t)
+	now := time.Now()
+
+	scrapedIdle := stateReading{StateIdle, ErrorNone, TriggerIdlePrompt, 0.95}
+	scrapedWorking := stateReading{StateWorking, ErrorNone, TriggerActivity, 0.8}
+	scrapedError := stateReading{StateError, ErrorRateLimit, TriggerError + ":rate_limit", 0.9}
+
+	tests := []struct {
+		name         string
+		hook         *HookState
+		scraped      stateReading
+		lastActivity time.Time
+		want         AgentState
+		fromHook     bool
+	}{
+		{"no hook state", nil, scrapedIdle, now.Add(-time.Minute), StateIdle, false},
+		{"working mid tool call", &HookState{State: StateWorking, Event: "tool_start", At: now.Add(-10 * time.Second)}, scrapedIdle, now.Add(-10 * time.Second), StateWorking, true},
+		{"turn ended", &HookState{State: StateIdle, Event: "turn_end", At: now.Add(-time.Second)}, scrapedWorking, now.Add(-time.Second), StateIdle, true},
+		{"permission prompt", &HookState{State: StateAwaitingApproval, Event: "permission", At: now.Add(-time.Second)}, scrapedWorking, now.Add(-time.Second), StateAwaitingApproval, true},
+		{"stale hook state", &HookState{State: StateWorking, Event: "tool_start", At: now.Add(-time.Hour)}, scrapedIdle, now.Add(-time.Minute), StateIdle, false},
+		{"scraped error wins", &HookState{State: StateWorking, Event: "turn_start", At: now}, scrapedError, now, StateError, false},
+		{"output after idle hook", &HookState{State: StateIdle, Event: "turn_end", At: now.Add(-time.Minute)}, scrapedWorking, now, StateWorking, false},
+		{"interrupted turn", &HookState{State: StateWorking, Event: "turn_start", At: now.Add(-5 * time.Minute)}, scrapedIdle, now.Add(-4 * time.Minute), StateIdle, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pane := "%1"
+			if tt.hook != nil {
+				tt.hook.Session = "proj"
+				tt.hook.PaneID = pane
+				if err := WriteHookState(*tt.hook); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				pane = "%none"
+			}
+			got := applyHookState("proj", pane, tt.scraped, tt.lastActivity)
+			if got.state != tt.want {
+				t.Errorf("state = %s, want %s (%+v)", got.state, tt.want, got)
+			}
+			if fromHook := got.trigger == TriggerAgentHook+":"+stringOrEmpty(tt.hook); fromHook != tt.fromHook {
+				t.Errorf("trigger = %q, fromHook want %v", got.trigger, tt.fromHook)
+			}
+		})
+	}
+}
+
+func stringOrEmpty(hs *HookState) string {
+	if hs == nil {
+		return ""
+	}
+	return hs.Event
+}
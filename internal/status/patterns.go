@@ -121,7 +121,8 @@ func IsPromptLine(line string, agentType string) bool {
 }
 
 // knownAgentTypes are agent types that have their own specific prompt patterns.
-// Generic shell prompt detection should not apply to these.
+// Generic shell prompt detection should not apply to these. Guarded by
+// promptPatternsMu; plugins add their types with RegisterAgentType.
 var knownAgentTypes = map[string]bool{
 	"cc":       true, // Claude Code uses "claude>" or ">" prompts
 	"cod":      true, // Codex uses "codex>" prompt
@@ -203,3 +204,19 @@ func AddPromptPattern(agentType string, pattern string, description string) erro
 
 	return nil
 }
+
+// RegisterAgentType marks a custom (plugin) agent type as a real AI agent, so
+// shell prompts in its pane are not mistaken for idle and undetermined output
+// defaults to idle, as for the built-in agents. Register its prompt patterns
+// with AddPromptPattern.
+func RegisterAgentType(agentType string) {
+	promptPatternsMu.Lock()
+	defer promptPatternsMu.Unlock()
+	knownAgentTypes[agentType] = true
+}
+
+// IsKnownAgentType reports whether agentType is a built-in agent type or one
+// added with RegisterAgentType.
+func IsKnownAgentType(agentType string) bool {
+	return isKnownAgentType(agentType)
+}
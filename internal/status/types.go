@@ -15,6 +15,9 @@ const (
 	StateWorking AgentState = "working"
 	// StateError indicates the agent encountered a problem
 	StateError AgentState = "error"
+	// StateAwaitingApproval indicates the agent is blocked on an interactive
+	// confirmation prompt (tool permission, trust dialog, y/n question)
+	StateAwaitingApproval AgentState = "awaiting_approval"
 	// StateUnknown indicates the state cannot be determined
 	StateUnknown AgentState = "unknown"
 )
@@ -28,6 +31,8 @@ func (s AgentState) Icon() string {
 		return "\U0001f7e2" // green circle
 	case StateError:
 		return "\U0001f534" // red circle
+	case StateAwaitingApproval:
+		return "\U0001f7e3" // purple circle
 	default:
 		return "\u26ab" // black circle
 	}
@@ -99,11 +104,18 @@ type AgentStatus struct {
 	ContextUsage float64 `json:"context_usage,omitempty"`
 	// TokensUsed is the estimated token count
 	TokensUsed int64 `json:"tokens_used,omitempty"`
+	// PendingApproval describes the prompt if State == StateAwaitingApproval
+	PendingApproval *ApprovalPrompt `json:"pending_approval,omitempty"`
+	// Trigger names the detection rule that produced State (e.g. "idle_prompt")
+	Trigger string `json:"trigger,omitempty"`
+	// Confidence is how reliable the triggering rule is (0-1)
+	Confidence float64 `json:"confidence,omitempty"`
 	// UpdatedAt is when this status was computed
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// IsHealthy returns true if the agent is in a healthy state (idle or working)
+// IsHealthy returns true if the agent is in a healthy state (idle or working).
+// An agent awaiting approval is not: it makes no progress until answered.
 func (s *AgentStatus) IsHealthy() bool {
 	return s.State == StateIdle || s.State == StateWorking
 }
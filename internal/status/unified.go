@@ -36,7 +36,8 @@ func (d *UnifiedDetector) Config() DetectorConfig {
 
 // Analyze determines status from provided output and metadata without calling tmux.
 // This allows reusing output captured for other purposes (e.g. live view).
-func (d *UnifiedDetector) Analyze(paneID, paneName, agentType string, output string, lastActivity time.Time) AgentStatus {
+// The session scopes the pane's hook-reported state.
+func (d *UnifiedDetector) Analyze(session, paneID, paneName, agentType string, output string, lastActivity time.Time) AgentStatus {
 	status := AgentStatus{
 		PaneID:     paneID,
 		PaneName:   paneName,
@@ -47,9 +48,14 @@ func (d *UnifiedDetector) Analyze(paneID, paneName, agentType string, output str
 		LastOutput: truncateOutput(output, d.config.OutputPreviewLength),
 	}
 
-	state, errType := d.determineState(output, agentType, lastActivity)
-	status.State = state
-	status.ErrorType = errType
+	reading := applyHookState(session, paneID, d.classify(output, agentType, lastActivity), lastActivity)
+	status.State = reading.state
+	status.ErrorType = reading.errType
+	status.Trigger = reading.trigger
+	status.Confidence = reading.confidence
+	if reading.state == StateAwaitingApproval {
+		status.PendingApproval = DetectApprovalPrompt(output, agentType)
+	}
 
 	// Extract metrics using agent parser
 	if isKnownAgentType(agentType) {
@@ -67,9 +73,37 @@ func (d *UnifiedDetector) Analyze(paneID, paneName, agentType string, output str
 	return status
 }
 
+// stateReading is the outcome of one state classification, including which
+// rule fired and how confident that rule is.
+type stateReading struct {
+	state      AgentState
+	errType    ErrorType
+	trigger    string
+	confidence float64
+}
+
+// Triggers reported in AgentStatus.Trigger.
+const (
+	TriggerApprovalPrompt = "approval_prompt"
+	TriggerIdlePrompt     = "idle_prompt"
+	TriggerError          = "error_pattern"
+	TriggerActivity       = "recent_activity"
+	TriggerEmptyPane      = "empty_pane"
+	TriggerIdleHeuristic  = "idle_heuristic"
+	TriggerAgentDefault   = "agent_default"
+	TriggerUndetermined   = "undetermined"
+)
+
 // determineState calculates state based on output and activity
 func (d *UnifiedDetector) determineState(output, agentType string, lastActivity time.Time) (AgentState, ErrorType) {
+	r := d.classify(output, agentType, lastActivity)
+	return r.state, r.errType
+}
+
+// classify implements determineState and also reports the triggering rule.
+func (d *UnifiedDetector) classify(output, agentType string, lastActivity time.Time) stateReading {
 	// Detection priority:
+	// 0. Check for a pending confirmation prompt (agent blocked on a human)
 	// 1. Check for idle prompt when velocity is low (agent waiting for input)
 	// 2. Check for errors (but only if not clearly at a prompt)
 	// 3. Check activity recency (working vs unknown)
@@ -80,6 +114,13 @@ func (d *UnifiedDetector) determineState(output, agentType string, lastActivity
 	// in the scrollback. Error patterns from earlier in the session are not relevant
 	// when the agent has clearly recovered and is now waiting for input.
 
+	// A confirmation dialog is drawn at the bottom of the pane and blocks the
+	// agent until answered, so it outranks both the idle prompt and any
+	// error text in scrollback.
+	if prompt := DetectApprovalPrompt(output, agentType); prompt != nil {
+		return stateReading{StateAwaitingApproval, ErrorNone, TriggerApprovalPrompt + ":" + prompt.Pattern, 0.95}
+	}
+
 	threshold := time.Duration(d.config.ActivityThreshold) * time.Second
 	isLowVelocity := time.Since(lastActivity) >= threshold
 
@@ -87,28 +128,28 @@ func (d *UnifiedDetector) determineState(output, agentType string, lastActivity
 	isAtPrompt := DetectIdleFromOutput(output, agentType)
 	if isAtPrompt && isLowVelocity {
 		// Agent is at prompt and not actively outputting - clearly idle
-		return StateIdle, ErrorNone
+		return stateReading{StateIdle, ErrorNone, TriggerIdlePrompt, 0.95}
 	}
 
 	// Check for errors (only relevant if not clearly at a prompt waiting for input)
 	if errType := DetectErrorInOutput(output); errType != ErrorNone {
-		return StateError, errType
+		return stateReading{StateError, errType, TriggerError + ":" + string(errType), 0.9}
 	}
 
 	// Check if at prompt (for cases with recent activity - might still be processing)
 	if isAtPrompt {
-		return StateIdle, ErrorNone
+		return stateReading{StateIdle, ErrorNone, TriggerIdlePrompt, 0.8}
 	}
 	// Heuristic: for user panes with empty output, treat as idle
 	if agentType == "" || agentType == "user" {
 		if strings.TrimSpace(output) == "" {
-			return StateIdle, ErrorNone
+			return stateReading{StateIdle, ErrorNone, TriggerEmptyPane, 0.7}
 		}
 	}
 
 	// Check recent activity (reuse isLowVelocity computed earlier)
 	if !isLowVelocity {
-		return StateWorking, ErrorNone
+		return stateReading{StateWorking, ErrorNone, TriggerActivity, 0.8}
 	}
 
 	// Heuristic: if no recent activity and output suggests agent is waiting,
@@ -117,7 +158,7 @@ func (d *UnifiedDetector) determineState(output, agentType string, lastActivity
 	// - The last line is short (typical of prompts)
 	// - The output ends without indication of ongoing work
 	if looksLikeIdle(output) {
-		return StateIdle, ErrorNone
+		return stateReading{StateIdle, ErrorNone, TriggerIdleHeuristic, 0.6}
 	}
 
 	// For known AI agent types (cc, cod, gmi), default to idle when state
@@ -126,25 +167,20 @@ func (d *UnifiedDetector) determineState(output, agentType string, lastActivity
 	// state provides little value and causes confusion in the dashboard.
 	// Only truly indeterminate user/shell panes should show "unknown".
 	if isKnownAgentType(agentType) {
-		return StateIdle, ErrorNone
+		return stateReading{StateIdle, ErrorNone, TriggerAgentDefault, 0.5}
 	}
 
 	// Default to unknown only for user/shell panes when we truly can't determine state
-	return StateUnknown, ErrorNone
+	return stateReading{StateUnknown, ErrorNone, TriggerUndetermined, 0.3}
 }
 
 // isKnownAgentType returns true for AI agent types that have predictable
-// working/idle behavior (cc=Claude Code, cod=Codex, gmi=Gemini).
+// working/idle behavior (cc=Claude Code, cod=Codex, gmi=Gemini, and any
+// plugin types added with RegisterAgentType).
 func isKnownAgentType(agentType string) bool {
-	switch agentType {
-	case string(agent.AgentTypeClaudeCode),
-		string(agent.AgentTypeCodex),
-		string(agent.AgentTypeGemini),
-		"cursor", "windsurf", "aider":
-		return true
-	default:
-		return false
-	}
+	promptPatternsMu.RLock()
+	defer promptPatternsMu.RUnlock()
+	return knownAgentTypes[agentType]
 }
 
 // looksLikeIdle applies heuristics to detect likely idle state when
@@ -254,9 +290,15 @@ func (d *UnifiedDetector) Detect(paneID string) (AgentStatus, error) {
 	}
 
 	// Use shared logic
-	state, errType := d.determineState(output, status.AgentType, status.LastActive)
-	status.State = state
-	status.ErrorType = errType
+	session, _ := tmux.DefaultClient.Run("display-message", "-p", "-t", paneID, "#{session_name}")
+	reading := applyHookState(session, paneID, d.classify(output, status.AgentType, status.LastActive), status.LastActive)
+	status.State = reading.state
+	status.ErrorType = reading.errType
+	status.Trigger = reading.trigger
+	status.Confidence = reading.confidence
+	if reading.state == StateAwaitingApproval {
+		status.PendingApproval = DetectApprovalPrompt(output, status.AgentType)
+	}
 
 	// Extract metrics using agent parser
 	if isKnownAgentType(status.AgentType) {
@@ -291,6 +333,7 @@ func (d *UnifiedDetector) DetectAllContext(ctx context.Context, session string)
 	if err != nil {
 		return nil, err
 	}
+	pruneHookPanes(session, panes)
 
 	// Capture outputs in parallel
 	type captureResult struct {
@@ -348,9 +391,14 @@ func (d *UnifiedDetector) DetectAllContext(ctx context.Context, session string)
 		status.LastOutput = truncateOutput(output, d.config.OutputPreviewLength)
 
 		// Use shared logic
-		state, errType := d.determineState(output, status.AgentType, status.LastActive)
-		status.State = state
-		status.ErrorType = errType
+		reading := applyHookState(session, status.PaneID, d.classify(output, status.AgentType, status.LastActive), status.LastActive)
+		status.State = reading.state
+		status.ErrorType = reading.errType
+		status.Trigger = reading.trigger
+		status.Confidence = reading.confidence
+		if reading.state == StateAwaitingApproval {
+			status.PendingApproval = DetectApprovalPrompt(output, status.AgentType)
+		}
 
 		// Extract metrics using agent parser
 		if isKnownAgentType(status.AgentType) {
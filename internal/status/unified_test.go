@@ -287,6 +287,7 @@ func TestAgentStatusIsHealthy(t *testing.T) {
 		{StateIdle, true},
 		{StateWorking, true},
 		{StateError, false},
+		{StateAwaitingApproval, false},
 		{StateUnknown, false},
 	}
 
@@ -446,6 +447,27 @@ func TestAddErrorPattern(t *testing.T) {
 	}
 }
 
+// TestAddErrorPatternKeepsTypePriority checks that a custom rate-limit pattern
+// still wins over generic error patterns matching the same output.
+func TestAddErrorPatternKeepsTypePriority(t *testing.T) {
+	if err := AddErrorPattern(ErrorRateLimit, `(?i)bucket drained`, "Custom rate limit"); err != nil {
+		t.Fatalf("AddErrorPattern failed: %v", err)
+	}
+	if got := DetectErrorInOutput("error: bucket drained, retry later"); got != ErrorRateLimit {
+		t.Errorf("DetectErrorInOutput = %s, want %s", got, ErrorRateLimit)
+	}
+}
+
+func TestRegisterAgentType(t *testing.T) {
+	if IsKnownAgentType("statustestbot") {
+		t.Fatal("statustestbot known before registration")
+	}
+	RegisterAgentType("statustestbot")
+	if !IsKnownAgentType("statustestbot") {
+		t.Error("statustestbot not known after registration")
+	}
+}
+
 // TestDefaultConfig tests the default configuration values
 func TestDefaultConfig(t *testing.T) {
 	config := DefaultConfig()
@@ -911,6 +933,34 @@ func TestDetermineState(t *testing.T) {
 	}
 }
 
+func TestAnalyzeReportsTrigger(t *testing.T) {
+	d := NewDetector()
+
+	tests := []struct {
+		name         string
+		output       string
+		lastActivity time.Time
+		wantTrigger  string
+	}{
+		{"idle prompt", "Task done\nclaude>", time.Now().Add(-10 * time.Second), TriggerIdlePrompt},
+		{"error", "Error: rate limit exceeded", time.Now(), TriggerError + ":" + string(ErrorRateLimit)},
+		{"activity", "Processing request...", time.Now(), TriggerActivity},
+		{"agent default", "Still processing the very long task that has been running for a while now", time.Now().Add(-60 * time.Second), TriggerAgentDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := d.Analyze("proj", "%1", "proj__cc_1", "cc", tt.output, tt.lastActivity)
+			if st.Trigger != tt.wantTrigger {
+				t.Errorf("Trigger = %q, want %q", st.Trigger, tt.wantTrigger)
+			}
+			if st.Confidence <= 0 || st.Confidence > 1 {
+				t.Errorf("Confidence = %v, want (0, 1]", st.Confidence)
+			}
+		})
+	}
+}
+
 // TestIsKnownAgentType tests the agent type classification
 func TestIsKnownAgentType(t *testing.T) {
 	tests := []struct {
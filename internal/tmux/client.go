@@ -3,6 +3,7 @@ package tmux
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -14,11 +15,13 @@ import (
 // Client handles tmux operations, optionally on a remote host
 type Client struct {
 	Remote string // "user@host" or empty for local
+
+	control *controlPool // control-mode connections, see EnableControlMode
 }
 
 // NewClient creates a new tmux client
 func NewClient(remote string) *Client {
-	return &Client{Remote: remote}
+	return &Client{Remote: remote, control: newControlPool()}
 }
 
 // DefaultClient is the default local client
@@ -86,6 +89,20 @@ func (c *Client) RunContext(ctx context.Context, args ...string) (string, error)
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	defer c.afterCommand(args)
+
+	// Prefer a live control-mode connection; fall back to exec if it has gone.
+	if cc := c.controlFor(args); cc != nil {
+		output, err := cc.Run(ctx, args...)
+		if !errors.Is(err, ErrControlClosed) {
+			return output, err
+		}
+	}
+	return c.runExec(ctx, args...)
+}
+
+// runExec runs a tmux command in a new process (or over ssh).
+func (c *Client) runExec(ctx context.Context, args ...string) (string, error) {
 	if c.Remote == "" {
 		return runLocalContext(ctx, args...)
 	}
@@ -0,0 +1,741 @@
+package tmux
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Control mode support.
+//
+// A ControlConn keeps one `tmux -C` client attached to a session and
+// multiplexes commands over its stdin/stdout instead of forking a tmux
+// process per call. tmux answers each command with a %begin/%end (or %error)
+// block, in order, and interleaves asynchronous notifications such as
+// %output and %window-add between blocks. Notifications drive pane output
+// streaming, activity timestamps and pane list invalidation.
+//
+// tmux only reports %output for panes in the session a control client is
+// attached to, so a Client keeps one connection per session. Any live
+// connection can run commands for the whole server.
+
+// ErrControlClosed is returned when a control-mode connection has exited.
+// Client.RunContext falls back to exec when it sees it.
+var ErrControlClosed = errors.New("tmux control connection closed")
+
+const (
+	// controlDialTimeout bounds the attach handshake.
+	controlDialTimeout = 5 * time.Second
+	// controlPaneCacheTTL is a safety net for pane list changes tmux does
+	// not notify about (format subscriptions need tmux 3.2+).
+	controlPaneCacheTTL = 10 * time.Second
+	// controlSubscription asks tmux to notify when any pane's title or
+	// running command changes, which the pane list cache depends on.
+	controlSubscription = "ntm-panes:%*:#{pane_title}|#{pane_current_command}|#{pane_pid}"
+)
+
+// ControlNotification is an asynchronous %-prefixed line from tmux.
+type ControlNotification struct {
+	Name   string   // Notification name without the %, e.g. "output", "window-add"
+	PaneID string   // Pane ID for %output and %pane-mode-changed
+	Args   []string // Remaining space-separated fields
+	Data   []byte   // Decoded pane output for %output
+}
+
+// paneListNotifications invalidate the cached pane list.
+var paneListNotifications = map[string]bool{
+	"window-add":             true,
+	"window-close":           true,
+	"window-renamed":         true,
+	"unlinked-window-add":    true,
+	"unlinked-window-close":  true,
+	"layout-change":          true,
+	"window-pane-changed":    true,
+	"pane-mode-changed":      true,
+	"session-changed":        true,
+	"session-window-changed": true,
+	"sessions-changed":       true,
+	"subscription-changed":   true,
+}
+
+type controlReply struct {
+	output string
+	err    error
+}
+
+// ControlConn is a persistent tmux control-mode client attached to a session.
+type ControlConn struct {
+	session string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+
+	writeMu sync.Mutex // keeps pending order in step with write order
+
+	mu          sync.Mutex
+	pending     []chan controlReply
+	handlers    map[int]func(ControlNotification)
+	nextHandler int
+	lastOutput  map[string]time.Time
+	panes       []Pane
+	panesAt     time.Time
+	panesGen    uint64
+	closed      bool
+
+	done chan struct{}
+}
+
+// DialControl starts a control-mode client attached to session, locally or
+// over ssh when remote is set. The client ignores its own size so it never
+// resizes the session's windows (requires tmux 3.2+).
+func DialControl(ctx context.Context, remote, session string) (*ControlConn, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	args := []string{"-C", "attach-session", "-f", "ignore-size", "-t", session}
+
+	var cmd *exec.Cmd
+	if remote == "" {
+		cmd = exec.Command(BinaryPath(), args...)
+	} else {
+		remoteCmd := buildRemoteShellCommand("tmux", args...)
+		cmd = exec.Command("ssh", "-T", "--", remote, fmt.Sprintf("/bin/sh -c %s", ShellQuote(remoteCmd)))
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("control stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("control stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start tmux control mode: %w", err)
+	}
+
+	cc := &ControlConn{
+		session:    session,
+		cmd:        cmd,
+		stdin:      stdin,
+		handlers:   make(map[int]func(ControlNotification)),
+		lastOutput: make(map[string]time.Time),
+		done:       make(chan struct{}),
+	}
+
+	// The attach command itself is answered with the first block.
+	attached := make(chan controlReply, 1)
+	cc.pending = append(cc.pending, attached)
+	go cc.readLoop(stdout)
+
+	dialCtx, cancel := context.WithTimeout(ctx, controlDialTimeout)
+	defer cancel()
+	select {
+	case reply := <-attached:
+		if reply.err != nil {
+			cc.Close()
+			return nil, fmt.Errorf("attach control client to %s: %w", session, reply.err)
+		}
+	case <-dialCtx.Done():
+		cc.Close()
+		return nil, fmt.Errorf("attach control client to %s: %w", session, dialCtx.Err())
+	}
+
+	// Best effort: older tmux without subscriptions relies on the cache TTL.
+	_, _ = cc.Run(ctx, "refresh-client", "-B", controlSubscription)
+	return cc, nil
+}
+
+// Session returns the session the connection is attached to.
+func (cc *ControlConn) Session() string {
+	return cc.session
+}
+
+// Done is closed when the connection exits (e.g. its session was killed).
+func (cc *ControlConn) Done() <-chan struct{} {
+	return cc.done
+}
+
+// Alive reports whether the connection can still run commands.
+func (cc *ControlConn) Alive() bool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return !cc.closed
+}
+
+// Run sends a tmux command over the connection and waits for its reply.
+func (cc *ControlConn) Run(ctx context.Context, args ...string) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	line, err := controlCommandLine(args)
+	if err != nil {
+		return "", err
+	}
+
+	reply := make(chan controlReply, 1)
+	cc.writeMu.Lock()
+	cc.mu.Lock()
+	if cc.closed {
+		cc.mu.Unlock()
+		cc.writeMu.Unlock()
+		return "", ErrControlClosed
+	}
+	cc.pending = append(cc.pending, reply)
+	cc.mu.Unlock()
+	_, err = io.WriteString(cc.stdin, line+"\n")
+	cc.writeMu.Unlock()
+	if err != nil {
+		cc.shutdown()
+		return "", ErrControlClosed
+	}
+
+	select {
+	case r := <-reply:
+		if r.err != nil && !errors.Is(r.err, ErrControlClosed) {
+			return "", fmt.Errorf("tmux %s: %w", strings.Join(args, " "), r.err)
+		}
+		return strings.TrimSpace(r.output), r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Subscribe registers fn for every notification and returns a function that
+// removes it. fn runs on the connection's reader goroutine and must not block.
+func (cc *ControlConn) Subscribe(fn func(ControlNotification)) (unsubscribe func()) {
+	cc.mu.Lock()
+	id := cc.nextHandler
+	cc.nextHandler++
+	cc.handlers[id] = fn
+	cc.mu.Unlock()
+	return func() {
+		cc.mu.Lock()
+		delete(cc.handlers, id)
+		cc.mu.Unlock()
+	}
+}
+
+// LastOutput returns when the pane last produced output, if it has since the
+// connection was established.
+func (cc *ControlConn) LastOutput(paneID string) (time.Time, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	t, ok := cc.lastOutput[paneID]
+	return t, ok
+}
+
+// Close detaches the control client.
+func (cc *ControlConn) Close() error {
+	cc.writeMu.Lock()
+	// An empty line detaches a control client.
+	_, _ = io.WriteString(cc.stdin, "\n")
+	_ = cc.stdin.Close()
+	cc.writeMu.Unlock()
+
+	select {
+	case <-cc.done:
+	case <-time.After(time.Second):
+		if cc.cmd.Process != nil {
+			_ = cc.cmd.Process.Kill()
+		}
+		<-cc.done
+	}
+	return nil
+}
+
+// shutdown marks the connection closed and fails every pending command.
+func (cc *ControlConn) shutdown() {
+	cc.mu.Lock()
+	if cc.closed {
+		cc.mu.Unlock()
+		return
+	}
+	cc.closed = true
+	pending := cc.pending
+	cc.pending = nil
+	cc.mu.Unlock()
+
+	for _, ch := range pending {
+		ch <- controlReply{err: ErrControlClosed}
+	}
+}
+
+func (cc *ControlConn) readLoop(stdout io.Reader) {
+	defer func() {
+		cc.shutdown()
+		_ = cc.cmd.Wait()
+		close(cc.done)
+	}()
+
+	reader := bufio.NewReader(stdout)
+	var (
+		inBlock bool
+		blockID string
+		body    []string
+	)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if inBlock {
+			if kind, id, ok := parseControlBlockEnd(line); ok && id == blockID {
+				inBlock = false
+				reply := controlReply{output: strings.Join(body, "\n")}
+				if kind == "error" {
+					reply = controlReply{err: errors.New(strings.TrimSpace(reply.output))}
+				}
+				cc.deliver(reply)
+				continue
+			}
+			body = append(body, line)
+			continue
+		}
+
+		if id, ok := parseControlBlockBegin(line); ok {
+			inBlock, blockID, body = true, id, nil
+			continue
+		}
+		if strings.HasPrefix(line, "%exit") {
+			cc.shutdown()
+			continue
+		}
+		if n, ok := parseControlNotification(line); ok {
+			cc.dispatch(n)
+		}
+	}
+}
+
+func (cc *ControlConn) deliver(reply controlReply) {
+	cc.mu.Lock()
+	if len(cc.pending) == 0 {
+		cc.mu.Unlock()
+		return
+	}
+	ch := cc.pending[0]
+	cc.pending = cc.pending[1:]
+	cc.mu.Unlock()
+	ch <- reply
+}
+
+func (cc *ControlConn) dispatch(n ControlNotification) {
+	cc.mu.Lock()
+	if n.Name == "output" {
+		cc.lastOutput[n.PaneID] = time.Now()
+	}
+	if paneListNotifications[n.Name] {
+		cc.invalidatePanesLocked()
+	}
+	handlers := make([]func(ControlNotification), 0, len(cc.handlers))
+	for _, fn := range cc.handlers {
+		handlers = append(handlers, fn)
+	}
+	cc.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(n)
+	}
+}
+
+// cachedPanes returns a copy of the cached pane list and the cache generation
+// to pass back to storePanes after a refresh.
+func (cc *ControlConn) cachedPanes() ([]Pane, uint64, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.panes == nil || time.Since(cc.panesAt) > controlPaneCacheTTL {
+		return nil, cc.panesGen, false
+	}
+	return clonePanes(cc.panes), cc.panesGen, true
+}
+
+// storePanes caches panes unless the list was invalidated since gen.
+func (cc *ControlConn) storePanes(gen uint64, panes []Pane) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if gen != cc.panesGen || cc.closed {
+		return
+	}
+	cc.panes = clonePanes(panes)
+	cc.panesAt = time.Now()
+}
+
+func clonePanes(panes []Pane) []Pane {
+	out := make([]Pane, len(panes))
+	copy(out, panes)
+	for i := range out {
+		out[i].Tags = slices.Clone(out[i].Tags)
+	}
+	return out
+}
+
+func (cc *ControlConn) invalidatePanes() {
+	cc.mu.Lock()
+	cc.invalidatePanesLocked()
+	cc.mu.Unlock()
+}
+
+func (cc *ControlConn) invalidatePanesLocked() {
+	cc.panesGen++
+	cc.panes = nil
+}
+
+// parseControlBlockBegin parses "%begin <time> <number> <flags>".
+func parseControlBlockBegin(line string) (string, bool) {
+	if !strings.HasPrefix(line, "%begin ") {
+		return "", false
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return "", false
+	}
+	return fields[1] + " " + fields[2], true
+}
+
+// parseControlBlockEnd parses "%end|%error <time> <number> <flags>". Only a
+// line whose time and number match the %begin ends the block, so command
+// output that happens to start with %end is kept.
+func parseControlBlockEnd(line string) (kind, id string, ok bool) {
+	switch {
+	case strings.HasPrefix(line, "%end "):
+		kind = "end"
+	case strings.HasPrefix(line, "%error "):
+		kind = "error"
+	default:
+		return "", "", false
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return "", "", false
+	}
+	return kind, fields[1] + " " + fields[2], true
+}
+
+// parseControlNotification parses an asynchronous notification line.
+func parseControlNotification(line string) (ControlNotification, bool) {
+	if !strings.HasPrefix(line, "%") {
+		return ControlNotification{}, false
+	}
+	name, rest, _ := strings.Cut(line[1:], " ")
+	n := ControlNotification{Name: name}
+	switch name {
+	case "output":
+		paneID, data, _ := strings.Cut(rest, " ")
+		n.PaneID = paneID
+		n.Data = unescapeControlOutput(data)
+	case "pane-mode-changed":
+		n.PaneID = strings.TrimSpace(rest)
+	default:
+		if rest != "" {
+			n.Args = strings.Fields(rest)
+		}
+	}
+	return n, true
+}
+
+// unescapeControlOutput decodes %output data, where tmux writes bytes below
+// 0x20 and backslashes as three-digit octal escapes.
+func unescapeControlOutput(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+3 < len(s) && isOctal(s[i+1]) && isOctal(s[i+2]) && isOctal(s[i+3]) {
+			out = append(out, (s[i+1]-'0')<<6|(s[i+2]-'0')<<3|(s[i+3]-'0'))
+			i += 3
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func isOctal(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+// controlCommandLine quotes args for the tmux command parser. Single quotes
+// disable all expansion there, as in the shell.
+func controlCommandLine(args []string) (string, error) {
+	if len(args) == 0 {
+		return "", errors.New("empty tmux command")
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsAny(arg, "\n\r") {
+			return "", fmt.Errorf("tmux control mode cannot send multi-line argument for %s", args[0])
+		}
+		parts[i] = ShellQuote(arg)
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// controlCommands are the commands routed over a control connection. They
+// either name their target explicitly or list server-wide state; commands that
+// act on "the current client" (attach, switch, display without -t) would act
+// on the control client instead and always use exec.
+var controlCommands = map[string]bool{
+	"capture-pane":    true,
+	"send-keys":       true,
+	"paste-buffer":    true,
+	"delete-buffer":   true,
+	"list-panes":      true,
+	"list-windows":    true,
+	"list-sessions":   true,
+	"display-message": true,
+	"select-pane":     true,
+	"resize-pane":     true,
+	"has-session":     true,
+	"set-option":      true,
+	"show-options":    true,
+}
+
+// paneListStableCommands never change the pane list. Command changes caused
+// by send-keys are reported through the format subscription.
+var paneListStableCommands = map[string]bool{
+	"capture-pane":    true,
+	"list-panes":      true,
+	"list-windows":    true,
+	"list-sessions":   true,
+	"display-message": true,
+	"has-session":     true,
+	"show-options":    true,
+	"send-keys":       true,
+	"paste-buffer":    true,
+	"delete-buffer":   true,
+}
+
+// controlRoutable reports whether args can run over a control connection.
+func controlRoutable(args []string) bool {
+	if len(args) == 0 || !controlCommands[args[0]] {
+		return false
+	}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "\n\r") {
+			return false
+		}
+	}
+	switch args[0] {
+	case "list-sessions":
+		return true
+	case "list-panes":
+		if containsArg(args[1:], "-a") {
+			return true
+		}
+	}
+	return containsArg(args[1:], "-t")
+}
+
+func containsArg(args []string, flag string) bool {
+	for _, a := range args {
+		if a == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// controlPool holds a Client's control connections, keyed by session.
+// Connections enabled with EnableControlMode are pinned until
+// CloseControlMode; the others live while AcquireControl holds them.
+type controlPool struct {
+	mu     sync.Mutex
+	conns  map[string]*ControlConn
+	refs   map[*ControlConn]int
+	pinned map[*ControlConn]bool
+}
+
+func newControlPool() *controlPool {
+	return &controlPool{
+		conns:  make(map[string]*ControlConn),
+		refs:   make(map[*ControlConn]int),
+		pinned: make(map[*ControlConn]bool),
+	}
+}
+
+func (p *controlPool) get(session string) *ControlConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cc := p.conns[session]
+	if cc != nil && !cc.Alive() {
+		delete(p.conns, session)
+		return nil
+	}
+	return cc
+}
+
+// any returns a live connection for running server-wide commands.
+func (p *controlPool) any() *ControlConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for session, cc := range p.conns {
+		if cc.Alive() {
+			return cc
+		}
+		delete(p.conns, session)
+	}
+	return nil
+}
+
+func (p *controlPool) all() []*ControlConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	conns := make([]*ControlConn, 0, len(p.conns))
+	for _, cc := range p.conns {
+		conns = append(conns, cc)
+	}
+	return conns
+}
+
+// EnableControlMode attaches a control-mode connection to session (or returns
+// the existing one). While any connection is alive, targeted commands run
+// over it instead of forking tmux, pane lists for the session are cached until
+// tmux reports a change, and pane activity comes from %output notifications.
+// The connection stays attached until CloseControlMode.
+func (c *Client) EnableControlMode(ctx context.Context, session string) (*ControlConn, error) {
+	cc, err := c.attachControl(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	c.control.mu.Lock()
+	c.control.pinned[cc] = true
+	c.control.mu.Unlock()
+	return cc, nil
+}
+
+// AcquireControl is EnableControlMode for a single user such as a pane
+// streamer. The returned release detaches the connection once every user
+// has released it, unless EnableControlMode pinned it.
+func (c *Client) AcquireControl(ctx context.Context, session string) (*ControlConn, func(), error) {
+	cc, err := c.attachControl(ctx, session)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.control.mu.Lock()
+	c.control.refs[cc]++
+	c.control.mu.Unlock()
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			c.control.mu.Lock()
+			c.control.refs[cc]--
+			detach := c.control.refs[cc] <= 0 && !c.control.pinned[cc]
+			if c.control.refs[cc] <= 0 {
+				delete(c.control.refs, cc)
+			}
+			if detach && c.control.conns[session] == cc {
+				delete(c.control.conns, session)
+			}
+			c.control.mu.Unlock()
+			if detach {
+				_ = cc.Close()
+			}
+		})
+	}
+	return cc, release, nil
+}
+
+func (c *Client) attachControl(ctx context.Context, session string) (*ControlConn, error) {
+	if c.control == nil {
+		return nil, errors.New("tmux client has no control pool")
+	}
+	if cc := c.control.get(session); cc != nil {
+		return cc, nil
+	}
+	cc, err := DialControl(ctx, c.Remote, session)
+	if err != nil {
+		return nil, err
+	}
+
+	c.control.mu.Lock()
+	if existing := c.control.conns[session]; existing != nil && existing.Alive() {
+		c.control.mu.Unlock()
+		_ = cc.Close()
+		return existing, nil
+	}
+	c.control.conns[session] = cc
+	c.control.mu.Unlock()
+
+	go func() {
+		<-cc.Done()
+		c.control.mu.Lock()
+		if c.control.conns[session] == cc {
+			delete(c.control.conns, session)
+		}
+		delete(c.control.pinned, cc)
+		c.control.mu.Unlock()
+	}()
+	return cc, nil
+}
+
+// ControlConn returns the live control connection for session, or nil.
+func (c *Client) ControlConn(session string) *ControlConn {
+	if c.control == nil {
+		return nil
+	}
+	return c.control.get(session)
+}
+
+// CloseControlMode detaches every control connection; commands go back to exec.
+func (c *Client) CloseControlMode() {
+	if c.control == nil {
+		return
+	}
+	c.control.mu.Lock()
+	conns := c.control.conns
+	c.control.conns = make(map[string]*ControlConn)
+	c.control.pinned = make(map[*ControlConn]bool)
+	c.control.mu.Unlock()
+	for _, cc := range conns {
+		_ = cc.Close()
+	}
+}
+
+// controlFor returns a connection able to run args, or nil to use exec.
+func (c *Client) controlFor(args []string) *ControlConn {
+	if c.control == nil || !controlRoutable(args) {
+		return nil
+	}
+	return c.control.any()
+}
+
+// afterCommand drops cached pane lists after commands that may change them.
+func (c *Client) afterCommand(args []string) {
+	if c.control == nil || len(args) == 0 || paneListStableCommands[args[0]] {
+		return
+	}
+	for _, cc := range c.control.all() {
+		cc.invalidatePanes()
+	}
+}
+
+// controlLastOutput returns the last %output time seen for paneID.
+func (c *Client) controlLastOutput(paneID string) (time.Time, bool) {
+	if c.control == nil {
+		return time.Time{}, false
+	}
+	for _, cc := range c.control.all() {
+		if t, ok := cc.LastOutput(paneID); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// EnableControlMode attaches a control-mode connection to session (default client).
+func EnableControlMode(ctx context.Context, session string) (*ControlConn, error) {
+	return DefaultClient.EnableControlMode(ctx, session)
+}
+
+// CloseControlMode detaches every control connection (default client).
+func CloseControlMode() {
+	DefaultClient.CloseControlMode()
+}
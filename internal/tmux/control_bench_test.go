@@ -0,0 +1,135 @@
+//go:build integration
+
+package tmux
+
+import (
+	"context"
+	"testing"
+)
+
+// =============================================================================
+// Control Mode vs Exec Benchmarks
+//
+// Compare forking tmux per call with multiplexing over one control-mode
+// connection. Run with:
+//   go test -tags=integration -run '^$' -bench Control ./internal/tmux/...
+// =============================================================================
+
+// benchSession creates a session with a few panes and returns exec and
+// control-mode clients for it.
+func benchSession(b *testing.B) (name string, execClient, controlClient *Client) {
+	b.Helper()
+	if !IsInstalled() {
+		b.Skip("tmux not installed")
+	}
+
+	name = uniqueSessionName("bench")
+	execClient = NewClient("")
+	if err := execClient.CreateSession(name, b.TempDir()); err != nil {
+		b.Fatalf("CreateSession: %v", err)
+	}
+	b.Cleanup(func() { _ = execClient.KillSession(name) })
+	for i := 0; i < 3; i++ {
+		if _, err := execClient.SplitWindow(name, b.TempDir()); err != nil {
+			b.Fatalf("SplitWindow: %v", err)
+		}
+		_ = execClient.ApplyTiledLayout(name)
+	}
+
+	controlClient = NewClient("")
+	if _, err := controlClient.EnableControlMode(context.Background(), name); err != nil {
+		b.Skipf("control mode unavailable: %v", err)
+	}
+	b.Cleanup(controlClient.CloseControlMode)
+	return name, execClient, controlClient
+}
+
+func benchCapture(b *testing.B, client *Client, target string) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.CapturePaneOutput(target, LinesStatusDetection); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCapturePane_Exec(b *testing.B) {
+	name, execClient, _ := benchSession(b)
+	b.ResetTimer()
+	benchCapture(b, execClient, name+":0")
+}
+
+func BenchmarkCapturePane_Control(b *testing.B) {
+	name, _, controlClient := benchSession(b)
+	b.ResetTimer()
+	benchCapture(b, controlClient, name+":0")
+}
+
+func benchGetPanes(b *testing.B, client *Client, session string) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		panes, err := client.GetPanes(session)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(panes) == 0 {
+			b.Fatal("no panes")
+		}
+	}
+}
+
+func BenchmarkGetPanes_Exec(b *testing.B) {
+	name, execClient, _ := benchSession(b)
+	b.ResetTimer()
+	benchGetPanes(b, execClient, name)
+}
+
+// BenchmarkGetPanes_Control measures the cached, notification-invalidated path.
+func BenchmarkGetPanes_Control(b *testing.B) {
+	name, _, controlClient := benchSession(b)
+	b.ResetTimer()
+	benchGetPanes(b, controlClient, name)
+}
+
+// BenchmarkListPanes_Control measures an uncached list-panes round trip.
+func BenchmarkListPanes_Control(b *testing.B) {
+	name, _, controlClient := benchSession(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := controlClient.Run("list-panes", "-s", "-t", name, "-F", "#{pane_id}"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCapturePane_ParallelPanes_* model a dashboard refresh capturing
+// every pane concurrently.
+func benchParallelCapture(b *testing.B, client *Client, session string) {
+	panes, err := client.GetPanes(session)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, err := client.CapturePaneOutput(panes[i%len(panes)].ID, LinesStatusDetection); err != nil {
+				b.Error(err)
+				return
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkCapturePane_ParallelPanes_Exec(b *testing.B) {
+	name, execClient, _ := benchSession(b)
+	benchParallelCapture(b, execClient, name)
+}
+
+func BenchmarkCapturePane_ParallelPanes_Control(b *testing.B) {
+	name, _, controlClient := benchSession(b)
+	benchParallelCapture(b, controlClient, name)
+}
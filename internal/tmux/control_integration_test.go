@@ -0,0 +1,203 @@
+//go:build integration
+
+package tmux
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// Control Mode Integration Tests
+//
+// Run with: go test -tags=integration ./internal/tmux/...
+// =============================================================================
+
+func TestIntegration_ControlMode(t *testing.T) {
+	skipIfNoTmux(t)
+
+	name := uniqueSessionName("control")
+	t.Cleanup(func() { cleanupSession(t, name) })
+	if err := CreateSession(name, t.TempDir()); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	client := NewClient("")
+	ctx := context.Background()
+	cc, err := client.EnableControlMode(ctx, name)
+	if err != nil {
+		t.Skipf("control mode unavailable: %v", err)
+	}
+	defer client.CloseControlMode()
+
+	if again, err := client.EnableControlMode(ctx, name); err != nil || again != cc {
+		t.Errorf("second EnableControlMode = %p, %v; want existing connection", again, err)
+	}
+
+	// The control client doesn't make the session look attached.
+	if client.IsAttached(name) {
+		t.Error("IsAttached = true with only a control client")
+	}
+	sessions, err := client.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	for _, s := range sessions {
+		if s.Name == name && s.Attached {
+			t.Error("ListSessions reports the session attached with only a control client")
+		}
+	}
+
+	// Commands with quoting survive the control-mode parser.
+	out, err := cc.Run(ctx, "display-message", "-p", "-t", name, "it's #{session_name}")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if out != "it's "+name {
+		t.Errorf("display-message = %q", out)
+	}
+	if _, err := cc.Run(ctx, "has-session", "-t", name+"_missing"); err == nil {
+		t.Error("expected error for missing session")
+	}
+
+	// Pane lists are cached until tmux reports a change.
+	panes, err := client.GetPanes(name)
+	if err != nil || len(panes) != 1 {
+		t.Fatalf("GetPanes = %v, %v", panes, err)
+	}
+	if _, _, ok := cc.cachedPanes(); !ok {
+		t.Error("pane list not cached after GetPanes")
+	}
+	if _, err := client.SplitWindow(name, t.TempDir()); err != nil {
+		t.Fatalf("SplitWindow: %v", err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		panes, err = client.GetPanes(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(panes) == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if len(panes) != 2 {
+		t.Errorf("GetPanes after split = %d panes, want 2", len(panes))
+	}
+
+	// %output is pushed to subscribers and recorded as activity.
+	var mu sync.Mutex
+	var seen strings.Builder
+	unsubscribe := cc.Subscribe(func(n ControlNotification) {
+		if n.Name == "output" && n.PaneID == panes[0].ID {
+			mu.Lock()
+			seen.Write(n.Data)
+			mu.Unlock()
+		}
+	})
+	defer unsubscribe()
+	if err := client.SendKeys(panes[0].ID, "echo control-$((40+2))", true); err != nil {
+		t.Fatalf("SendKeys: %v", err)
+	}
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		got := seen.String()
+		mu.Unlock()
+		if strings.Contains(got, "control-42") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no %%output for pane, got %q", got)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if _, ok := cc.LastOutput(panes[0].ID); !ok {
+		t.Error("LastOutput not recorded")
+	}
+	if _, err := client.GetPaneActivity(panes[0].ID); err != nil {
+		t.Errorf("GetPaneActivity: %v", err)
+	}
+
+	// Killing the session ends the connection; commands fall back to exec.
+	if err := client.KillSession(name); err != nil {
+		t.Fatalf("KillSession: %v", err)
+	}
+	select {
+	case <-cc.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("control connection did not exit with its session")
+	}
+	if client.ControlConn(name) != nil {
+		t.Error("dead connection still returned")
+	}
+	if client.SessionExists(name) {
+		t.Error("session still reported after kill")
+	}
+}
+
+func TestIntegration_PaneStreamer_ControlMode(t *testing.T) {
+	skipIfNoTmux(t)
+
+	name := uniqueSessionName("control_stream")
+	t.Cleanup(func() { cleanupSession(t, name) })
+	if err := CreateSession(name, t.TempDir()); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	client := NewClient("")
+	defer client.CloseControlMode()
+
+	var mu sync.Mutex
+	var lines []string
+	cfg := DefaultPaneStreamerConfig()
+	cfg.FIFODir = t.TempDir()
+	ps := NewPaneStreamer(client, name+":0", func(event StreamEvent) {
+		mu.Lock()
+		lines = append(lines, event.Lines...)
+		mu.Unlock()
+	}, cfg)
+	if err := ps.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer ps.Stop()
+	if !ps.UsingControlMode() {
+		t.Skip("control mode unavailable; streamer fell back")
+	}
+
+	if err := client.SendKeys(name+":0", "echo streamed-$((6*7))", true); err != nil {
+		t.Fatalf("SendKeys: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		joined := strings.Join(lines, "\n")
+		mu.Unlock()
+		if strings.Contains(joined, "streamed-42") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("streamed output missing, got %q", joined)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// Stopping the only streamer detaches the connection it attached.
+	cc := client.ControlConn(name)
+	ps.Stop()
+	if cc == nil {
+		t.Fatal("no control connection while streaming")
+	}
+	select {
+	case <-cc.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("control connection still attached after Stop")
+	}
+	if client.ControlConn(name) != nil {
+		t.Error("ControlConn returned a connection after Stop")
+	}
+}
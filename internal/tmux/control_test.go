@@ -0,0 +1,100 @@
+package tmux
+
+import (
+	"testing"
+)
+
+func TestUnescapeControlOutput(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`hello`, "hello"},
+		{`line\015\012`, "line\r\n"},
+		{`back\134slash`, `back\slash`},
+		{`esc\033[0m`, "esc\x1b[0m"},
+		{`trailing\01`, `trailing\01`},
+		{`not\9octal`, `not\9octal`},
+	}
+	for _, tt := range tests {
+		if got := string(unescapeControlOutput(tt.in)); got != tt.want {
+			t.Errorf("unescapeControlOutput(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseControlNotification(t *testing.T) {
+	n, ok := parseControlNotification(`%output %12 hi\015\012`)
+	if !ok || n.Name != "output" || n.PaneID != "%12" || string(n.Data) != "hi\r\n" {
+		t.Errorf("output notification = %+v, %v", n, ok)
+	}
+
+	n, ok = parseControlNotification("%window-add @3")
+	if !ok || n.Name != "window-add" || len(n.Args) != 1 || n.Args[0] != "@3" {
+		t.Errorf("window-add notification = %+v, %v", n, ok)
+	}
+
+	n, ok = parseControlNotification("%pane-mode-changed %4")
+	if !ok || n.PaneID != "%4" {
+		t.Errorf("pane-mode-changed notification = %+v, %v", n, ok)
+	}
+
+	if _, ok := parseControlNotification("plain text"); ok {
+		t.Error("plain text parsed as notification")
+	}
+}
+
+func TestParseControlBlocks(t *testing.T) {
+	id, ok := parseControlBlockBegin("%begin 1700000000 42 1")
+	if !ok || id != "1700000000 42" {
+		t.Fatalf("begin = %q, %v", id, ok)
+	}
+	kind, endID, ok := parseControlBlockEnd("%end 1700000000 42 1")
+	if !ok || kind != "end" || endID != id {
+		t.Errorf("end = %q %q %v", kind, endID, ok)
+	}
+	kind, _, ok = parseControlBlockEnd("%error 1700000000 42 1")
+	if !ok || kind != "error" {
+		t.Errorf("error = %q %v", kind, ok)
+	}
+	if _, _, ok := parseControlBlockEnd("%endless output"); ok {
+		t.Error("output line parsed as block end")
+	}
+}
+
+func TestControlRoutable(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"capture-pane", "-p", "-t", "%1"}, true},
+		{[]string{"list-sessions", "-F", "#{session_name}"}, true},
+		{[]string{"list-panes", "-a", "-F", "#{pane_id}"}, true},
+		{[]string{"send-keys", "-t", "%1", "-l", "echo hi"}, true},
+		{[]string{"display-message", "-p", "#{session_name}"}, false}, // current client
+		{[]string{"send-keys", "-t", "%1", "-l", "two\nlines"}, false},
+		{[]string{"attach-session", "-t", "proj"}, false},
+		{[]string{"kill-session", "-t", "proj"}, false},
+		{[]string{"-V"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := controlRoutable(tt.args); got != tt.want {
+			t.Errorf("controlRoutable(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestControlCommandLine(t *testing.T) {
+	got, err := controlCommandLine([]string{"send-keys", "-t", "%1", "-l", "it's; $HOME #x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `'send-keys' '-t' '%1' '-l' 'it'\''s; $HOME #x'`
+	if got != want {
+		t.Errorf("controlCommandLine = %s, want %s", got, want)
+	}
+	if _, err := controlCommandLine([]string{"send-keys", "a\nb"}); err == nil {
+		t.Error("expected error for multi-line argument")
+	}
+}
@@ -1,4 +1,5 @@
-// Package tmux provides pane output streaming using pipe-pane with polling fallback.
+// Package tmux provides pane output streaming using control mode or pipe-pane,
+// with polling fallback.
 package tmux
 
 import (
@@ -21,7 +22,7 @@ type StreamEvent struct {
 	Lines     []string  // Output lines
 	Seq       int64     // Sequence number
 	Timestamp time.Time // When the event was captured
-	IsFull    bool      // True if this is a full capture (polling), false if incremental (pipe)
+	IsFull    bool      // True if this is a full capture (polling), false if incremental (control/pipe)
 }
 
 // StreamCallback is called when new output is available.
@@ -43,6 +44,10 @@ type PaneStreamerConfig struct {
 
 	// FallbackPollLines is the number of lines to capture in polling mode (default: 50)
 	FallbackPollLines int
+
+	// ControlMode streams %output from a tmux control-mode connection
+	// before trying pipe-pane (default: true)
+	ControlMode bool
 }
 
 // DefaultPaneStreamerConfig returns sensible defaults.
@@ -53,6 +58,7 @@ func DefaultPaneStreamerConfig() PaneStreamerConfig {
 		FlushInterval:        50 * time.Millisecond,
 		FallbackPollInterval: 500 * time.Millisecond,
 		FallbackPollLines:    LinesHealthCheck,
+		ControlMode:          true,
 	}
 }
 
@@ -70,6 +76,9 @@ type PaneStreamer struct {
 	fifoPath    string
 	seq         int64
 	useFallback atomic.Bool
+	useControl  atomic.Bool
+	unsubscribe func() // detaches the control-mode output handler
+	release     func() // releases the control-mode connection
 
 	mu       sync.Mutex
 	running  bool
@@ -117,6 +126,7 @@ func (ps *PaneStreamer) Start(ctx context.Context) (err error) {
 	ps.ctx = ctx
 	ps.stopCh = make(chan struct{})
 	ps.useFallback.Store(false)
+	ps.useControl.Store(false)
 	ps.lastHash = ""
 	ps.fifoPath = ""
 	ps.mu.Unlock()
@@ -138,12 +148,21 @@ func (ps *PaneStreamer) Start(ctx context.Context) (err error) {
 		}
 	}()
 
+	// Control mode needs no FIFO and no per-pane tmux process
+	if ps.config.ControlMode {
+		controlErr := ps.startControlStreaming(ctx)
+		if controlErr == nil {
+			return nil
+		}
+		log.Printf("control-mode: unavailable for %s, using pipe-pane: %v", ps.target, controlErr)
+	}
+
 	// Ensure FIFO directory exists
 	if err = os.MkdirAll(ps.config.FIFODir, 0755); err != nil {
 		return fmt.Errorf("create fifo dir: %w", err)
 	}
 
-	// Try pipe-pane first
+	// Try pipe-pane next
 	if err := ps.startPipePaneStreaming(); err != nil {
 		log.Printf("pipe-pane: failed for %s, falling back to polling: %v", ps.target, err)
 		ps.useFallback.Store(true)
@@ -169,6 +188,11 @@ func (ps *PaneStreamer) Stop() {
 		close(stopCh)
 	}
 
+	if ps.unsubscribe != nil {
+		ps.unsubscribe()
+		ps.unsubscribe = nil
+	}
+
 	// Stop pipe-pane
 	if ps.fifoPath != "" {
 		_ = ps.client.RunSilent("pipe-pane", "-t", ps.target)
@@ -177,6 +201,13 @@ func (ps *PaneStreamer) Stop() {
 
 	ps.wg.Wait()
 
+	// Only after the reader has exited, so it doesn't take the detach for a
+	// lost connection
+	if ps.release != nil {
+		ps.release()
+		ps.release = nil
+	}
+
 	ps.mu.Lock()
 	ps.ctx = nil
 	ps.stopCh = nil
@@ -193,6 +224,11 @@ func (ps *PaneStreamer) UsingFallback() bool {
 	return ps.useFallback.Load()
 }
 
+// UsingControlMode returns true if output arrives over a control-mode connection.
+func (ps *PaneStreamer) UsingControlMode() bool {
+	return ps.useControl.Load()
+}
+
 // nextSeq returns the next sequence number.
 func (ps *PaneStreamer) nextSeq() int64 {
 	return atomic.AddInt64(&ps.seq, 1)
@@ -320,6 +356,122 @@ func (ps *PaneStreamer) runFIFOReader() {
 	}
 }
 
+// startControlStreaming subscribes to %output for the pane on its session's
+// control-mode connection, attaching one if needed. Stop releases it.
+func (ps *PaneStreamer) startControlStreaming(ctx context.Context) error {
+	info, err := ps.client.Run("display-message", "-p", "-t", ps.target, "#{pane_id}"+FieldSeparator+"#{session_name}")
+	if err != nil {
+		return err
+	}
+	paneID, session, ok := strings.Cut(info, FieldSeparator)
+	if !ok || paneID == "" || session == "" {
+		return fmt.Errorf("unexpected pane info %q", info)
+	}
+
+	cc, release, err := ps.client.AcquireControl(ctx, session)
+	if err != nil {
+		return err
+	}
+	ps.release = release
+
+	var (
+		bufMu sync.Mutex
+		buf   []byte
+	)
+	ready := make(chan struct{}, 1)
+	ps.unsubscribe = cc.Subscribe(func(n ControlNotification) {
+		if n.Name != "output" || n.PaneID != paneID {
+			return
+		}
+		bufMu.Lock()
+		buf = append(buf, n.Data...)
+		bufMu.Unlock()
+		select {
+		case ready <- struct{}{}:
+		default:
+		}
+	})
+	take := func() []byte {
+		bufMu.Lock()
+		defer bufMu.Unlock()
+		data := buf
+		buf = nil
+		return data
+	}
+
+	ps.useControl.Store(true)
+	log.Printf("control-mode: streaming %s (%s) via session %s", ps.target, paneID, session)
+
+	ps.wg.Add(1)
+	go ps.runControlReader(cc, ready, take)
+	return nil
+}
+
+// runControlReader turns %output data into line events, like runFIFOReader.
+func (ps *PaneStreamer) runControlReader(cc *ControlConn, ready <-chan struct{}, take func() []byte) {
+	defer ps.wg.Done()
+
+	ctx := ps.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	stopCh := ps.stopCh
+
+	var lineBuf []string
+	var partial string
+	flushTicker := time.NewTicker(ps.config.FlushInterval)
+	defer flushTicker.Stop()
+
+	flushLines := func() {
+		if len(lineBuf) == 0 {
+			return
+		}
+		ps.callback(StreamEvent{
+			Target:    ps.target,
+			Lines:     lineBuf,
+			Seq:       ps.nextSeq(),
+			Timestamp: time.Now(),
+			IsFull:    false,
+		})
+		lineBuf = nil
+	}
+
+	for {
+		select {
+		case <-stopCh:
+			flushLines()
+			return
+		case <-ctx.Done():
+			flushLines()
+			return
+		case <-cc.Done():
+			// The connection went away (session killed or tmux restarted).
+			flushLines()
+			log.Printf("control-mode: connection for %s closed, switching to fallback", ps.target)
+			ps.useControl.Store(false)
+			ps.useFallback.Store(true)
+			ps.wg.Add(1)
+			go ps.runPollingLoop()
+			return
+		case <-flushTicker.C:
+			flushLines()
+		case <-ready:
+			partial += string(take())
+			for {
+				line, rest, found := strings.Cut(partial, "\n")
+				if !found {
+					break
+				}
+				lineBuf = append(lineBuf, strings.TrimSuffix(line, "\r"))
+				partial = rest
+				if len(lineBuf) >= ps.config.MaxLinesPerEvent {
+					flushLines()
+				}
+			}
+		}
+	}
+}
+
 // runPollingLoop polls capture-pane as a fallback.
 func (ps *PaneStreamer) runPollingLoop() {
 	defer ps.wg.Done()
@@ -480,19 +632,24 @@ func (sm *StreamManager) Stats() map[string]interface{} {
 	defer sm.mu.RUnlock()
 
 	active := len(sm.streamers)
+	controlCount := 0
 	pipePaneCount := 0
 	fallbackCount := 0
 
 	for _, s := range sm.streamers {
-		if s.UsingFallback() {
+		switch {
+		case s.UsingFallback():
 			fallbackCount++
-		} else {
+		case s.UsingControlMode():
+			controlCount++
+		default:
 			pipePaneCount++
 		}
 	}
 
 	return map[string]interface{}{
 		"active_streams":    active,
+		"control_count":     controlCount,
 		"pipe_pane_count":   pipePaneCount,
 		"fallback_count":    fallbackCount,
 		"fifo_dir":          sm.config.FIFODir,
@@ -108,6 +108,13 @@ func tagsFromTitle(title string) []string {
 	return tags
 }
 
+// AgentTypeFromTitle returns the agent type encoded in an NTM pane title,
+// or AgentUser if the title is not NTM-formatted.
+func AgentTypeFromTitle(title string) AgentType {
+	agentType, _, _, _ := parseAgentFromTitle(title)
+	return agentType
+}
+
 // parseTags parses a comma-separated tag string into a slice.
 // Returns nil for empty input.
 func parseTags(tagStr string) []string {
@@ -227,6 +234,7 @@ func (c *Client) ListSessions() ([]Session, error) {
 	}
 
 	var sessions []Session
+	var clients map[string]int
 	for _, line := range strings.Split(output, "\n") {
 		parts := strings.Split(line, sep)
 		if len(parts) < 4 {
@@ -234,7 +242,13 @@ func (c *Client) ListSessions() ([]Session, error) {
 		}
 
 		windows, _ := strconv.Atoi(parts[1])
-		attached := parts[2] == "1"
+		attached := false
+		if n, _ := strconv.Atoi(parts[2]); n > 0 {
+			if clients == nil {
+				clients = c.attachedClients()
+			}
+			attached = clients[parts[0]] > 0
+		}
 
 		sessions = append(sessions, Session{
 			Name:     parts[0],
@@ -247,6 +261,34 @@ func (c *Client) ListSessions() ([]Session, error) {
 	return sessions, nil
 }
 
+// attachedClients counts the clients attached to each session, leaving out
+// control-mode clients such as the ones ntm serve and the dashboard attach
+// for streaming: those do not mean anyone is looking at the session.
+func (c *Client) attachedClients() map[string]int {
+	sep := FieldSeparator
+	output, err := c.Run("list-clients", "-F", "#{client_session}"+sep+"#{client_control_mode}")
+	counts := make(map[string]int)
+	if err != nil {
+		return counts
+	}
+	for _, line := range strings.Split(output, "\n") {
+		session, control, ok := strings.Cut(line, sep)
+		if ok && session != "" && control != "1" {
+			counts[session]++
+		}
+	}
+	return counts
+}
+
+// sessionAttached reports whether session has an attached client other than
+// a control-mode one, given its #{session_attached} count.
+func (c *Client) sessionAttached(session, count string) bool {
+	if n, _ := strconv.Atoi(count); n <= 0 {
+		return false
+	}
+	return c.attachedClients()[session] > 0
+}
+
 // ListSessions returns all tmux sessions (default client)
 func ListSessions() ([]Session, error) {
 	return DefaultClient.ListSessions()
@@ -272,7 +314,7 @@ func (c *Client) GetSession(name string) (*Session, error) {
 	}
 
 	windows, _ := strconv.Atoi(parts[1])
-	attached := parts[2] == "1"
+	attached := c.sessionAttached(name, parts[2])
 
 	session := &Session{
 		Name:     name,
@@ -312,6 +354,18 @@ func (c *Client) GetPanes(session string) ([]Pane, error) {
 
 // GetPanesContext returns all panes in a session with cancellation support.
 func (c *Client) GetPanesContext(ctx context.Context, session string) ([]Pane, error) {
+	// With a control connection on the session, the list only changes when
+	// tmux says so.
+	cc := c.ControlConn(session)
+	var cacheGen uint64
+	if cc != nil {
+		cached, gen, ok := cc.cachedPanes()
+		if ok {
+			return cached, nil
+		}
+		cacheGen = gen
+	}
+
 	sep := FieldSeparator
 	format := fmt.Sprintf("#{pane_id}%[1]s#{pane_index}%[1]s#{pane_title}%[1]s#{pane_current_command}%[1]s#{pane_width}%[1]s#{pane_height}%[1]s#{pane_active}%[1]s#{pane_pid}%[1]s#{window_index}", sep)
 	output, err := c.RunContext(ctx, "list-panes", "-s", "-t", session, "-F", format)
@@ -379,6 +433,9 @@ func (c *Client) GetPanesContext(ctx context.Context, session string) ([]Pane, e
 		panes = append(panes, pane)
 	}
 
+	if cc != nil {
+		cc.storePanes(cacheGen, panes)
+	}
 	return panes, nil
 }
 
@@ -1021,6 +1078,21 @@ func SendEOF(target string) error {
 	return DefaultClient.SendEOF(target)
 }
 
+// SendKeyNames sends tmux key names (e.g. "Escape", "Enter", "y") to a pane.
+// Unlike SendKeys, the keys are not sent literally, so names are interpreted.
+func (c *Client) SendKeyNames(target string, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := append([]string{"send-keys", "-t", target}, keys...)
+	return c.RunSilent(args...)
+}
+
+// SendKeyNames sends tmux key names to a pane (default client)
+func SendKeyNames(target string, keys ...string) error {
+	return DefaultClient.SendKeyNames(target, keys...)
+}
+
 // DisplayMessage shows a message in the tmux status line
 func (c *Client) DisplayMessage(session, msg string, durationMs int) error {
 	return c.RunSilent("display-message", "-t", session, "-d", fmt.Sprintf("%d", durationMs), msg)
@@ -1235,6 +1307,9 @@ func ValidateSessionName(name string) error {
 
 // GetPaneActivity returns the last activity time for a pane
 func (c *Client) GetPaneActivity(paneID string) (time.Time, error) {
+	if last, ok := c.controlLastOutput(paneID); ok {
+		return last, nil
+	}
 	output, err := c.Run("display-message", "-p", "-t", paneID, "#{pane_last_activity}")
 	if err != nil {
 		return time.Time{}, err
@@ -1306,6 +1381,10 @@ func (c *Client) GetPanesWithActivityContext(ctx context.Context, session string
 			// Unparseable timestamps should not produce huge idle durations.
 			lastActivity = now
 		}
+		// %output timestamps are finer-grained than tmux's one-second activity.
+		if last, ok := c.controlLastOutput(parts[0]); ok && last.After(lastActivity) {
+			lastActivity = last
+		}
 
 		pane := Pane{
 			ID:          parts[0],
@@ -1403,7 +1482,7 @@ func (c *Client) IsAttached(session string) bool {
 	if len(parts) < 2 {
 		return false
 	}
-	return parts[1] == "1"
+	return c.sessionAttached(session, parts[1])
 }
 
 // IsAttached checks if a session is currently attached (default client)
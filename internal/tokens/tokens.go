@@ -8,6 +8,7 @@ package tokens
 
 import (
 	"strings"
+	"sync"
 	"unicode"
 )
 
@@ -130,9 +131,34 @@ var ContextLimits = map[string]int{
 // DefaultContextLimit is used when a model isn't recognized
 const DefaultContextLimit = 128000
 
+var (
+	pluginLimitsMu sync.RWMutex
+	pluginLimits   = map[string]int{}
+)
+
+// RegisterContextLimit sets the context limit of a plugin agent type,
+// looked up by name like a model. Safe to call while limits are read.
+func RegisterContextLimit(agentType string, limit int) {
+	pluginLimitsMu.Lock()
+	defer pluginLimitsMu.Unlock()
+	pluginLimits[strings.ToLower(strings.TrimSpace(agentType))] = limit
+}
+
+// PluginContextLimit returns the context limit registered for a plugin
+// agent type, if any.
+func PluginContextLimit(agentType string) (int, bool) {
+	pluginLimitsMu.RLock()
+	defer pluginLimitsMu.RUnlock()
+	limit, ok := pluginLimits[strings.ToLower(strings.TrimSpace(agentType))]
+	return limit, ok
+}
+
 // GetContextLimit returns the context limit for a given model identifier.
 // Returns DefaultContextLimit if the model is not recognized.
 func GetContextLimit(model string) int {
+	if limit, ok := PluginContextLimit(model); ok {
+		return limit
+	}
 	normalized := normalizeModel(model)
 	if limit, ok := ContextLimits[normalized]; ok {
 		return limit
@@ -0,0 +1,387 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const scopeName = "github.com/Dicklesworthstone/ntm"
+
+// OTLPExporter posts spans to an OTLP/HTTP collector.
+type OTLPExporter struct {
+	url      string
+	protocol string
+	headers  map[string]string
+	client   *http.Client
+}
+
+// NewOTLPExporter creates an exporter for endpoint using protocol
+// (http/protobuf when empty).
+func NewOTLPExporter(endpoint, protocol string, headers map[string]string, timeout time.Duration) (*OTLPExporter, error) {
+	u, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: want http(s)://host[:port]", endpoint)
+	}
+	if !strings.HasSuffix(u.Path, "/v1/traces") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/v1/traces"
+	}
+	if protocol == "" {
+		protocol = ProtocolProtobuf
+	}
+	if err := (Config{Protocol: protocol}).Validate(); err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &OTLPExporter{
+		url:      u.String(),
+		protocol: protocol,
+		headers:  headers,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Export implements Exporter.
+func (e *OTLPExporter) Export(ctx context.Context, res Resource, spans []SpanData) error {
+	var (
+		body        []byte
+		contentType string
+		err         error
+	)
+	if e.protocol == ProtocolJSON {
+		body, err = MarshalJSON(res, spans)
+		contentType = "application/json"
+	} else {
+		body = MarshalProto(res, spans)
+		contentType = "application/x-protobuf"
+	}
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("otlp export: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("otlp export: %s returned %s", e.url, resp.Status)
+	}
+	return nil
+}
+
+// FileExporter appends each batch to a file as one OTLP/JSON
+// ExportTraceServiceRequest per line, the layout the OpenTelemetry
+// Collector's file exporter writes and its otlpjsonfile receiver reads.
+type FileExporter struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileExporter creates a file exporter writing to path.
+func NewFileExporter(path string) *FileExporter {
+	return &FileExporter{path: path}
+}
+
+// Export implements Exporter.
+func (e *FileExporter) Export(_ context.Context, res Resource, spans []SpanData) error {
+	line, err := MarshalJSON(res, spans)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if dir := filepath.Dir(e.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("trace file: %w", err)
+		}
+	}
+	f, err := os.OpenFile(e.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("trace file: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("trace file: %w", err)
+	}
+	return f.Close()
+}
+
+// OTLP/JSON encoding. IDs are hex, 64-bit integers are decimal strings and
+// enums are numbers, per the OTLP JSON mapping.
+
+type jsonRequest struct {
+	ResourceSpans []jsonResourceSpans `json:"resourceSpans"`
+}
+
+type jsonResourceSpans struct {
+	Resource   jsonResource     `json:"resource"`
+	ScopeSpans []jsonScopeSpans `json:"scopeSpans"`
+}
+
+type jsonResource struct {
+	Attributes []jsonKeyValue `json:"attributes"`
+}
+
+type jsonScopeSpans struct {
+	Scope jsonScope  `json:"scope"`
+	Spans []jsonSpan `json:"spans"`
+}
+
+type jsonScope struct {
+	Name string `json:"name"`
+}
+
+type jsonSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []jsonKeyValue `json:"attributes,omitempty"`
+	Events            []jsonEvent    `json:"events,omitempty"`
+	Status            jsonStatus     `json:"status"`
+}
+
+type jsonEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []jsonKeyValue `json:"attributes,omitempty"`
+}
+
+type jsonStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type jsonKeyValue struct {
+	Key   string       `json:"key"`
+	Value jsonAnyValue `json:"value"`
+}
+
+type jsonAnyValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *jsonArrayValue `json:"arrayValue,omitempty"`
+}
+
+type jsonArrayValue struct {
+	Values []jsonAnyValue `json:"values"`
+}
+
+// MarshalJSON encodes spans as an OTLP/JSON ExportTraceServiceRequest.
+func MarshalJSON(res Resource, spans []SpanData) ([]byte, error) {
+	out := make([]jsonSpan, 0, len(spans))
+	for _, s := range spans {
+		js := jsonSpan{
+			TraceID:           s.TraceID.String(),
+			SpanID:            s.SpanID.String(),
+			Name:              s.Name,
+			Kind:              int(s.Kind),
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        jsonAttributes(s.Attributes),
+			Status:            jsonStatus{Code: int(s.StatusCode), Message: s.StatusMessage},
+		}
+		if s.ParentSpanID.IsValid() {
+			js.ParentSpanID = s.ParentSpanID.String()
+		}
+		for _, ev := range s.Events {
+			js.Events = append(js.Events, jsonEvent{
+				TimeUnixNano: strconv.FormatInt(ev.Time.UnixNano(), 10),
+				Name:         ev.Name,
+				Attributes:   jsonAttributes(ev.Attributes),
+			})
+		}
+		out = append(out, js)
+	}
+	return json.Marshal(jsonRequest{ResourceSpans: []jsonResourceSpans{{
+		Resource:   jsonResource{Attributes: jsonAttributes(res.Attributes)},
+		ScopeSpans: []jsonScopeSpans{{Scope: jsonScope{Name: scopeName}, Spans: out}},
+	}}})
+}
+
+func jsonAttributes(attrs []Attribute) []jsonKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]jsonKeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		out = append(out, jsonKeyValue{Key: a.Key, Value: jsonValue(a.Value)})
+	}
+	return out
+}
+
+func jsonValue(v interface{}) jsonAnyValue {
+	switch v := v.(type) {
+	case string:
+		return jsonAnyValue{StringValue: &v}
+	case bool:
+		return jsonAnyValue{BoolValue: &v}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return jsonAnyValue{IntValue: &s}
+	case float64:
+		return jsonAnyValue{DoubleValue: &v}
+	case []string:
+		arr := &jsonArrayValue{Values: make([]jsonAnyValue, 0, len(v))}
+		for _, e := range v {
+			arr.Values = append(arr.Values, jsonValue(e))
+		}
+		return jsonAnyValue{ArrayValue: arr}
+	default:
+		s := fmt.Sprint(v)
+		return jsonAnyValue{StringValue: &s}
+	}
+}
+
+// OTLP protobuf encoding, written against the opentelemetry-proto field
+// numbers so the exporter needs no generated code.
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// MarshalProto encodes spans as a protobuf ExportTraceServiceRequest.
+func MarshalProto(res Resource, spans []SpanData) []byte {
+	var scope []byte
+	scope = appendStringField(scope, 1, scopeName) // InstrumentationScope.name
+
+	var scopeSpans []byte
+	scopeSpans = appendBytesField(scopeSpans, 1, scope) // ScopeSpans.scope
+	for _, s := range spans {
+		scopeSpans = appendBytesField(scopeSpans, 2, protoSpan(s)) // ScopeSpans.spans
+	}
+
+	var resource []byte
+	for _, a := range res.Attributes {
+		resource = appendBytesField(resource, 1, protoKeyValue(a)) // Resource.attributes
+	}
+
+	var resourceSpans []byte
+	resourceSpans = appendBytesField(resourceSpans, 1, resource)   // ResourceSpans.resource
+	resourceSpans = appendBytesField(resourceSpans, 2, scopeSpans) // ResourceSpans.scope_spans
+
+	return appendBytesField(nil, 1, resourceSpans) // ExportTraceServiceRequest.resource_spans
+}
+
+func protoSpan(s SpanData) []byte {
+	var b []byte
+	b = appendBytesField(b, 1, s.TraceID[:]) // trace_id
+	b = appendBytesField(b, 2, s.SpanID[:])  // span_id
+	if s.ParentSpanID.IsValid() {
+		b = appendBytesField(b, 4, s.ParentSpanID[:]) // parent_span_id
+	}
+	b = appendStringField(b, 5, s.Name)                      // name
+	b = appendVarintField(b, 6, uint64(s.Kind))              // kind
+	b = appendFixed64Field(b, 7, uint64(s.Start.UnixNano())) // start_time_unix_nano
+	b = appendFixed64Field(b, 8, uint64(s.End.UnixNano()))   // end_time_unix_nano
+	for _, a := range s.Attributes {
+		b = appendBytesField(b, 9, protoKeyValue(a)) // attributes
+	}
+	for _, ev := range s.Events {
+		var e []byte
+		e = appendFixed64Field(e, 1, uint64(ev.Time.UnixNano())) // time_unix_nano
+		e = appendStringField(e, 2, ev.Name)                     // name
+		for _, a := range ev.Attributes {
+			e = appendBytesField(e, 3, protoKeyValue(a)) // attributes
+		}
+		b = appendBytesField(b, 11, e) // events
+	}
+	var st []byte
+	if s.StatusMessage != "" {
+		st = appendStringField(st, 2, s.StatusMessage) // Status.message
+	}
+	if s.StatusCode != StatusUnset {
+		st = appendVarintField(st, 3, uint64(s.StatusCode)) // Status.code
+	}
+	return appendBytesField(b, 15, st) // status
+}
+
+func protoKeyValue(a Attribute) []byte {
+	var b []byte
+	b = appendStringField(b, 1, a.Key)
+	return appendBytesField(b, 2, protoAnyValue(a.Value))
+}
+
+func protoAnyValue(v interface{}) []byte {
+	var b []byte
+	switch v := v.(type) {
+	case string:
+		b = appendStringField(b, 1, v)
+	case bool:
+		n := uint64(0)
+		if v {
+			n = 1
+		}
+		b = appendVarintField(b, 2, n)
+	case int64:
+		b = appendVarintField(b, 3, uint64(v))
+	case float64:
+		b = appendFixed64Field(b, 4, math.Float64bits(v))
+	case []string:
+		var arr []byte
+		for _, e := range v {
+			arr = appendBytesField(arr, 1, protoAnyValue(e)) // ArrayValue.values
+		}
+		b = appendBytesField(b, 5, arr)
+	default:
+		b = appendStringField(b, 1, fmt.Sprint(v))
+	}
+	return b
+}
+
+func appendTag(b []byte, field int, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wire))
+}
+
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	b = appendTag(b, field, wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+func appendFixed64Field(b []byte, field int, v uint64) []byte {
+	b = appendTag(b, field, wireFixed64)
+	return binary.LittleEndian.AppendUint64(b, v)
+}
+
+func appendBytesField(b []byte, field int, v []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendStringField(b []byte, field int, v string) []byte {
+	return appendBytesField(b, field, []byte(v))
+}
@@ -0,0 +1,303 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Export protocols.
+const (
+	ProtocolProtobuf = "http/protobuf"
+	ProtocolJSON     = "http/json"
+)
+
+const (
+	defaultBatchSize     = 256
+	defaultQueueSize     = 4096
+	defaultFlushInterval = 5 * time.Second
+)
+
+// Config selects where spans go. Endpoint and File may both be set.
+type Config struct {
+	// Endpoint is the OTLP/HTTP collector base URL, e.g.
+	// http://localhost:4318. /v1/traces is appended unless present.
+	Endpoint string
+	// Protocol is http/protobuf (default) or http/json.
+	Protocol string
+	// Headers are sent with every export request.
+	Headers map[string]string
+	// File appends each export batch as one OTLP/JSON line.
+	File string
+	// ServiceName is the service.name resource attribute (default "ntm").
+	ServiceName string
+	// ServiceVersion is the service.version resource attribute.
+	ServiceVersion string
+	// Timeout bounds each export request.
+	Timeout time.Duration
+}
+
+// Enabled reports whether the config names any destination.
+func (c Config) Enabled() bool {
+	return c.Endpoint != "" || c.File != ""
+}
+
+// ApplyEnv overlays the standard OTEL_* variables and NTM_TRACE_FILE on c.
+// OTEL_SDK_DISABLED=true clears every destination.
+func (c Config) ApplyEnv() Config {
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); v != "" {
+		c.Endpoint = v
+	} else if v := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); v != "" {
+		c.Endpoint = v
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"); v != "" {
+		c.Protocol = v
+	} else if v := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); v != "" {
+		c.Protocol = v
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"); v != "" {
+		headers := make(map[string]string, len(c.Headers))
+		for k, hv := range c.Headers {
+			headers[k] = hv
+		}
+		for _, pair := range strings.Split(v, ",") {
+			k, hv, ok := strings.Cut(pair, "=")
+			if ok && strings.TrimSpace(k) != "" {
+				headers[strings.TrimSpace(k)] = strings.TrimSpace(hv)
+			}
+		}
+		c.Headers = headers
+	}
+	if v := os.Getenv("OTEL_SERVICE_NAME"); v != "" {
+		c.ServiceName = v
+	}
+	if v := os.Getenv("NTM_TRACE_FILE"); v != "" {
+		c.File = v
+	}
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		c.Endpoint, c.File = "", ""
+	}
+	return c
+}
+
+// Validate checks the protocol name.
+func (c Config) Validate() error {
+	switch c.Protocol {
+	case "", ProtocolProtobuf, ProtocolJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported OTLP protocol %q (want %s or %s)", c.Protocol, ProtocolProtobuf, ProtocolJSON)
+	}
+}
+
+// Exporter sends finished spans somewhere.
+type Exporter interface {
+	Export(ctx context.Context, res Resource, spans []SpanData) error
+}
+
+// Resource describes the process that produced the spans.
+type Resource struct {
+	Attributes []Attribute
+}
+
+// Provider batches finished spans and hands them to its exporters.
+type Provider struct {
+	resource  Resource
+	exporters []Exporter
+	timeout   time.Duration
+
+	mu      sync.Mutex
+	queue   []SpanData
+	dropped int
+
+	flushCh chan struct{}
+	stopCh  chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+var global atomic.Pointer[Provider]
+
+// Init installs a provider for cfg, replacing and shutting down any earlier
+// one. A config with no destination installs nothing.
+func Init(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if !cfg.Enabled() {
+		return nil
+	}
+	var exporters []Exporter
+	if cfg.Endpoint != "" {
+		exp, err := NewOTLPExporter(cfg.Endpoint, cfg.Protocol, cfg.Headers, cfg.Timeout)
+		if err != nil {
+			return err
+		}
+		exporters = append(exporters, exp)
+	}
+	if cfg.File != "" {
+		exporters = append(exporters, NewFileExporter(cfg.File))
+	}
+	SetProvider(NewProvider(cfg, exporters...))
+	return nil
+}
+
+// NewProvider creates a provider that exports to exporters in the background.
+func NewProvider(cfg Config, exporters ...Exporter) *Provider {
+	name := cfg.ServiceName
+	if name == "" {
+		name = "ntm"
+	}
+	attrs := []Attribute{String("service.name", name)}
+	if cfg.ServiceVersion != "" {
+		attrs = append(attrs, String("service.version", cfg.ServiceVersion))
+	}
+	if host, err := os.Hostname(); err == nil {
+		attrs = append(attrs, String("host.name", host))
+	}
+	attrs = append(attrs, Int("process.pid", os.Getpid()))
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	p := &Provider{
+		resource:  Resource{Attributes: attrs},
+		exporters: exporters,
+		timeout:   timeout,
+		flushCh:   make(chan struct{}, 1),
+		stopCh:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go p.loop()
+	return p
+}
+
+// SetProvider installs p as the global provider, shutting down the previous
+// one. A nil p disables tracing.
+func SetProvider(p *Provider) {
+	if old := global.Swap(p); old != nil && old != p {
+		ctx, cancel := context.WithTimeout(context.Background(), old.timeout)
+		defer cancel()
+		_ = old.Shutdown(ctx)
+	}
+}
+
+// Enabled reports whether a provider is installed.
+func Enabled() bool {
+	return global.Load() != nil
+}
+
+// Shutdown ends any open agent turns, flushes queued spans and removes the
+// global provider.
+func Shutdown(ctx context.Context) error {
+	closeAllTurns()
+	p := global.Swap(nil)
+	if p == nil {
+		return nil
+	}
+	return p.Shutdown(ctx)
+}
+
+// Shutdown stops the background exporter after a final flush.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	var err error
+	p.once.Do(func() {
+		close(p.stopCh)
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		}
+		err = p.flush(ctx)
+	})
+	return err
+}
+
+// ForceFlush exports every queued span now.
+func (p *Provider) ForceFlush(ctx context.Context) error {
+	return p.flush(ctx)
+}
+
+// ForceFlush flushes the global provider, if any.
+func ForceFlush(ctx context.Context) error {
+	if p := global.Load(); p != nil {
+		return p.flush(ctx)
+	}
+	return nil
+}
+
+func (p *Provider) enqueue(span SpanData) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	if len(p.queue) >= defaultQueueSize {
+		p.dropped++
+		p.mu.Unlock()
+		return
+	}
+	p.queue = append(p.queue, span)
+	full := len(p.queue) >= defaultBatchSize
+	p.mu.Unlock()
+
+	if full {
+		select {
+		case p.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (p *Provider) loop() {
+	defer close(p.done)
+	ticker := time.NewTicker(defaultFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+		case <-p.flushCh:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		if err := p.flush(ctx); err != nil {
+			slog.Debug("trace export failed", "error", err)
+		}
+		cancel()
+	}
+}
+
+func (p *Provider) flush(ctx context.Context) error {
+	p.mu.Lock()
+	spans := p.queue
+	p.queue = nil
+	dropped := p.dropped
+	p.dropped = 0
+	p.mu.Unlock()
+
+	if dropped > 0 {
+		slog.Debug("trace queue full, spans dropped", "count", dropped)
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for start := 0; start < len(spans); start += defaultBatchSize {
+		batch := spans[start:min(start+defaultBatchSize, len(spans))]
+		for _, exp := range p.exporters {
+			if err := exp.Export(ctx, p.resource, batch); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
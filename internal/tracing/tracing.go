@@ -0,0 +1,361 @@
+// Package tracing records spans for ntm operations and exports them over
+// OTLP. Spans cover CLI commands, serve requests, pipeline runs and steps,
+// scheduler spawn jobs and agent turns. When no provider is installed, Start
+// returns a nil *Span whose methods do nothing, so instrumented code pays
+// almost nothing with tracing off.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// String returns the lowercase hex form used by OTLP/JSON and traceparent.
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// IsValid reports whether the ID is non-zero.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// String returns the lowercase hex form.
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// IsValid reports whether the ID is non-zero.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// SpanKind is the OTLP span kind.
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// StatusCode is the OTLP span status code.
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// Attribute is a span attribute. Values are string, bool, int64, float64 or
+// []string.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attribute { return Attribute{Key: key, Value: int64(value)} }
+
+// Int64 returns an integer attribute.
+func Int64(key string, value int64) Attribute { return Attribute{Key: key, Value: value} }
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// Float64 returns a floating point attribute.
+func Float64(key string, value float64) Attribute { return Attribute{Key: key, Value: value} }
+
+// Strings returns a string array attribute.
+func Strings(key string, value []string) Attribute { return Attribute{Key: key, Value: value} }
+
+// Event is a timestamped annotation on a span.
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes []Attribute
+}
+
+// SpanData is a finished span as handed to exporters.
+type SpanData struct {
+	TraceID       TraceID
+	SpanID        SpanID
+	ParentSpanID  SpanID
+	Name          string
+	Kind          SpanKind
+	Start         time.Time
+	End           time.Time
+	Attributes    []Attribute
+	Events        []Event
+	StatusCode    StatusCode
+	StatusMessage string
+}
+
+// Span is an in-progress operation. A nil *Span is valid and ignores every
+// call.
+type Span struct {
+	provider *Provider
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// TraceID returns the span's trace ID in hex, or "" for a nil span.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.data.TraceID.String()
+}
+
+// SpanID returns the span's ID in hex, or "" for a nil span.
+func (s *Span) SpanID() string {
+	if s == nil {
+		return ""
+	}
+	return s.data.SpanID.String()
+}
+
+// SetAttributes adds or replaces attributes.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	for _, a := range attrs {
+		replaced := false
+		for i := range s.data.Attributes {
+			if s.data.Attributes[i].Key == a.Key {
+				s.data.Attributes[i] = a
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.data.Attributes = append(s.data.Attributes, a)
+		}
+	}
+}
+
+// AddEvent records a named event at the current time.
+func (s *Span) AddEvent(name string, attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.data.Events = append(s.data.Events, Event{Name: name, Time: time.Now(), Attributes: attrs})
+}
+
+// SetStatus sets the span status.
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.data.StatusCode = code
+	s.data.StatusMessage = message
+}
+
+// SetError marks the span failed with err's message. A nil err is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.AddEvent("exception", String("exception.message", err.Error()))
+	s.SetStatus(StatusError, err.Error())
+}
+
+// End finishes the span and queues it for export. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	s.provider.enqueue(data)
+}
+
+// spanContext identifies a parent span, local or remote.
+type spanContext struct {
+	traceID TraceID
+	spanID  SpanID
+}
+
+type ctxKey struct{}
+
+type remoteKey struct{}
+
+// ContextWithSpan returns ctx carrying span as the current span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxKey{}, span)
+}
+
+// SpanFromContext returns the current span in ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(ctxKey{}).(*Span)
+	return span
+}
+
+// TraceIDFromContext returns the hex trace ID of the span in ctx, or "".
+func TraceIDFromContext(ctx context.Context) string {
+	return SpanFromContext(ctx).TraceID()
+}
+
+// StartOption configures a new span.
+type StartOption func(*SpanData)
+
+// WithKind sets the span kind (default KindInternal).
+func WithKind(kind SpanKind) StartOption {
+	return func(d *SpanData) { d.Kind = kind }
+}
+
+// WithAttributes sets initial attributes.
+func WithAttributes(attrs ...Attribute) StartOption {
+	return func(d *SpanData) { d.Attributes = append(d.Attributes, attrs...) }
+}
+
+// Start begins a span named name. Its parent is the span in ctx, then a
+// remote parent extracted into ctx, then the running CLI command's span. It
+// returns ctx carrying the new span; with no provider installed it returns
+// ctx unchanged and a nil span.
+func Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	p := global.Load()
+	if p == nil {
+		return ctx, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	data := SpanData{
+		Name:   name,
+		Kind:   KindInternal,
+		Start:  time.Now(),
+		SpanID: newSpanID(),
+	}
+	switch {
+	case SpanFromContext(ctx) != nil:
+		parent := SpanFromContext(ctx)
+		data.TraceID = parent.data.TraceID
+		data.ParentSpanID = parent.data.SpanID
+	case ctx.Value(remoteKey{}) != nil:
+		remote := ctx.Value(remoteKey{}).(spanContext)
+		data.TraceID = remote.traceID
+		data.ParentSpanID = remote.spanID
+	default:
+		if cmd := commandSpan.Load(); cmd != nil {
+			data.TraceID = cmd.data.TraceID
+			data.ParentSpanID = cmd.data.SpanID
+		} else {
+			data.TraceID = newTraceID()
+		}
+	}
+	for _, opt := range opts {
+		opt(&data)
+	}
+
+	span := &Span{provider: p, data: data}
+	return ContextWithSpan(ctx, span), span
+}
+
+// commandSpan is the span of the CLI command this process is running, the
+// fallback parent for spans started without one in their context.
+var commandSpan atomic.Pointer[Span]
+
+// StartCommand starts the span for a CLI command and makes it the process's
+// command span.
+func StartCommand(name string, attrs ...Attribute) *Span {
+	_, span := Start(context.Background(), name, WithAttributes(attrs...))
+	if span != nil {
+		commandSpan.Store(span)
+	}
+	return span
+}
+
+// CommandTraceID returns the hex trace ID of the running command, or "".
+func CommandTraceID() string {
+	return commandSpan.Load().TraceID()
+}
+
+// EndCommand ends the command span, recording err.
+func EndCommand(err error) {
+	span := commandSpan.Swap(nil)
+	span.SetError(err)
+	span.End()
+}
+
+// Traceparent returns the W3C traceparent header value for the span in ctx,
+// or "" when there is none.
+func Traceparent(ctx context.Context) string {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", span.data.TraceID, span.data.SpanID)
+}
+
+// ContextWithTraceparent returns ctx with the remote parent described by a
+// W3C traceparent header value. Malformed values are ignored.
+func ContextWithTraceparent(ctx context.Context, header string) context.Context {
+	var sc spanContext
+	// version-traceid-spanid-flags
+	if len(header) != 55 || header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return ctx
+	}
+	if _, err := hex.Decode(sc.traceID[:], []byte(header[3:35])); err != nil {
+		return ctx
+	}
+	if _, err := hex.Decode(sc.spanID[:], []byte(header[36:52])); err != nil {
+		return ctx
+	}
+	if !sc.traceID.IsValid() || !sc.spanID.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	_, _ = rand.Read(id[:])
+	return id
+}
@@ -0,0 +1,335 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withFileProvider installs a provider writing to a temp file and returns a
+// func that shuts it down and returns the exported spans.
+func withFileProvider(t *testing.T) func() []jsonSpan {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	SetProvider(NewProvider(Config{ServiceName: "ntm-test"}, NewFileExporter(path)))
+	t.Cleanup(func() { SetProvider(nil) })
+
+	return func() []jsonSpan {
+		t.Helper()
+		if err := Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown: %v", err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("open trace file: %v", err)
+		}
+		defer f.Close()
+
+		var spans []jsonSpan
+		sc := bufio.NewScanner(f)
+		sc.Buffer(nil, 1<<20)
+		for sc.Scan() {
+			var req jsonRequest
+			if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+				t.Fatalf("decode line: %v", err)
+			}
+			for _, rs := range req.ResourceSpans {
+				if got := attrString(rs.Resource.Attributes, "service.name"); got != "ntm-test" {
+					t.Errorf("service.name = %q", got)
+				}
+				for _, ss := range rs.ScopeSpans {
+					spans = append(spans, ss.Spans...)
+				}
+			}
+		}
+		return spans
+	}
+}
+
+func attrString(attrs []jsonKeyValue, key string) string {
+	for _, a := range attrs {
+		if a.Key != key {
+			continue
+		}
+		switch {
+		case a.Value.StringValue != nil:
+			return *a.Value.StringValue
+		case a.Value.IntValue != nil:
+			return *a.Value.IntValue
+		}
+	}
+	return ""
+}
+
+func spanNamed(t *testing.T, spans []jsonSpan, name string) jsonSpan {
+	t.Helper()
+	for _, s := range spans {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("no span named %q in %d spans", name, len(spans))
+	return jsonSpan{}
+}
+
+func TestFileExporterParentsSpansUnderCommand(t *testing.T) {
+	finish := withFileProvider(t)
+
+	cmd := StartCommand("ntm pipeline run", String("ntm.command", "ntm pipeline run"))
+	ctx, run := Start(context.Background(), "pipeline.run")
+	turn := StartTurn(ctx, TurnInfo{Session: "proj", Pane: "%3", AgentType: "cc", Prompt: "review the diff in main.go"})
+	turn.End()
+	run.SetError(errors.New("step failed"))
+	run.End()
+	EndCommand(nil)
+
+	spans := finish()
+	if len(spans) != 3 {
+		t.Fatalf("exported %d spans, want 3", len(spans))
+	}
+	root := spanNamed(t, spans, "ntm pipeline run")
+	pipe := spanNamed(t, spans, "pipeline.run")
+	agent := spanNamed(t, spans, "agent.turn")
+
+	if root.TraceID != cmd.TraceID() || pipe.TraceID != root.TraceID || agent.TraceID != root.TraceID {
+		t.Errorf("trace IDs differ: %s %s %s", root.TraceID, pipe.TraceID, agent.TraceID)
+	}
+	if root.ParentSpanID != "" || pipe.ParentSpanID != root.SpanID || agent.ParentSpanID != pipe.SpanID {
+		t.Errorf("bad parents: root=%q pipe=%q agent=%q", root.ParentSpanID, pipe.ParentSpanID, agent.ParentSpanID)
+	}
+	if pipe.Status.Code != int(StatusError) || pipe.Status.Message != "step failed" || len(pipe.Events) != 1 {
+		t.Errorf("pipeline status = %+v, events = %d", pipe.Status, len(pipe.Events))
+	}
+	if attrString(agent.Attributes, "ntm.pane") != "%3" || attrString(agent.Attributes, "ntm.agent.type") != "cc" {
+		t.Errorf("turn attributes = %+v", agent.Attributes)
+	}
+	if attrString(agent.Attributes, "ntm.prompt.tokens_estimate") == "" {
+		t.Error("turn is missing the token estimate")
+	}
+	if CommandTraceID() != "" {
+		t.Error("EndCommand should clear the command span")
+	}
+}
+
+func TestDisabledTracingIsNoop(t *testing.T) {
+	SetProvider(nil)
+	ctx, span := Start(context.Background(), "x")
+	if span != nil || SpanFromContext(ctx) != nil {
+		t.Fatal("Start without a provider should return a nil span")
+	}
+	span.SetAttributes(String("k", "v"))
+	span.AddEvent("e")
+	span.SetError(errors.New("boom"))
+	span.End()
+	if StartCommand("ntm x") != nil || CommandTraceID() != "" || Traceparent(ctx) != "" {
+		t.Error("command span should be nil when disabled")
+	}
+	OpenTurn(ctx, TurnInfo{Session: "s", Pane: "%1"})
+	if CloseTurn("s", "%1") {
+		t.Error("OpenTurn should not register turns when disabled")
+	}
+}
+
+func TestTraceparentPropagation(t *testing.T) {
+	finish := withFileProvider(t)
+
+	const header = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := ContextWithTraceparent(context.Background(), header)
+	ctx, span := Start(ctx, "HTTP GET", WithKind(KindServer))
+	if got := Traceparent(ctx); got[:36] != header[:36] || got == header {
+		t.Errorf("Traceparent = %q, want same trace, new span", got)
+	}
+	span.End()
+
+	for _, bad := range []string{"", "garbage", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01"} {
+		if ContextWithTraceparent(context.Background(), bad) != context.Background() {
+			t.Errorf("malformed traceparent %q was accepted", bad)
+		}
+	}
+
+	spans := finish()
+	got := spanNamed(t, spans, "HTTP GET")
+	if got.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || got.ParentSpanID != "00f067aa0ba902b7" || got.Kind != int(KindServer) {
+		t.Errorf("span = %+v", got)
+	}
+}
+
+func TestTurnRegistry(t *testing.T) {
+	finish := withFileProvider(t)
+
+	OpenTurn(context.Background(), TurnInfo{Session: "b", Pane: "%1", Prompt: "first"})
+	OpenTurn(context.Background(), TurnInfo{Session: "b", Pane: "%1", Prompt: "second"})
+	OpenTurn(context.Background(), TurnInfo{Session: "a", Pane: "%2", Prompt: "other"})
+	if got := OpenTurnSessions(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("OpenTurnSessions = %v", got)
+	}
+	if !CloseTurn("b", "%1") || CloseTurn("b", "%1") {
+		t.Error("CloseTurn should close exactly once")
+	}
+
+	spans := finish() // Shutdown ends the turn still open on a/%2
+	if len(spans) != 3 {
+		t.Fatalf("exported %d spans, want 3", len(spans))
+	}
+	var superseded, ok, unfinished int
+	for _, s := range spans {
+		switch {
+		case hasAttr(s, "ntm.turn.superseded"):
+			superseded++
+		case hasAttr(s, "ntm.turn.unfinished"):
+			unfinished++
+		case s.Status.Code == int(StatusOK):
+			ok++
+		}
+	}
+	if superseded != 1 || ok != 1 || unfinished != 1 {
+		t.Errorf("superseded=%d ok=%d unfinished=%d", superseded, ok, unfinished)
+	}
+}
+
+func hasAttr(s jsonSpan, key string) bool {
+	for _, a := range s.Attributes {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOTLPExporterProtocols(t *testing.T) {
+	var gotPath, gotType, gotAuth string
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotType, gotAuth = r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	span := SpanData{
+		TraceID:    TraceID{1, 2, 3},
+		SpanID:     SpanID{4, 5},
+		Name:       "agent.turn",
+		Kind:       KindInternal,
+		Attributes: []Attribute{Int("ntm.prompt.tokens_estimate", 42), Strings("tags", []string{"a"})},
+		StatusCode: StatusError,
+	}
+	res := Resource{Attributes: []Attribute{String("service.name", "ntm")}}
+
+	exp, err := NewOTLPExporter(srv.URL, ProtocolJSON, map[string]string{"Authorization": "Bearer t"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.Export(context.Background(), res, []SpanData{span}); err != nil {
+		t.Fatalf("json export: %v", err)
+	}
+	if gotPath != "/v1/traces" || gotType != "application/json" || gotAuth != "Bearer t" {
+		t.Errorf("json request: path=%q type=%q auth=%q", gotPath, gotType, gotAuth)
+	}
+	var req jsonRequest
+	if err := json.Unmarshal(body, &req); err != nil || req.ResourceSpans[0].ScopeSpans[0].Spans[0].Name != "agent.turn" {
+		t.Errorf("json body = %s (%v)", body, err)
+	}
+
+	exp, _ = NewOTLPExporter(srv.URL+"/v1/traces", "", nil, 0)
+	if err := exp.Export(context.Background(), res, []SpanData{span}); err != nil {
+		t.Fatalf("protobuf export: %v", err)
+	}
+	if gotPath != "/v1/traces" || gotType != "application/x-protobuf" {
+		t.Errorf("protobuf request: path=%q type=%q", gotPath, gotType)
+	}
+
+	// ExportTraceServiceRequest.resource_spans[0].scope_spans[0].spans[0]
+	rs := protoFields(t, body)[1][0].([]byte)
+	ss := protoFields(t, rs)[2][0].([]byte)
+	sp := protoFields(t, protoFields(t, ss)[2][0].([]byte))
+	if string(sp[1][0].([]byte)) != string(span.TraceID[:]) || string(sp[5][0].([]byte)) != "agent.turn" {
+		t.Errorf("span fields = %v", sp)
+	}
+	if sp[6][0].(uint64) != uint64(KindInternal) || len(sp[9]) != 2 {
+		t.Errorf("kind = %v, attributes = %d", sp[6], len(sp[9]))
+	}
+	kv := protoFields(t, sp[9][0].([]byte))
+	if string(kv[1][0].([]byte)) != "ntm.prompt.tokens_estimate" || protoFields(t, kv[2][0].([]byte))[3][0].(uint64) != 42 {
+		t.Errorf("int attribute = %v", kv)
+	}
+	if protoFields(t, sp[15][0].([]byte))[3][0].(uint64) != uint64(StatusError) {
+		t.Error("status code not encoded")
+	}
+
+	if _, err := NewOTLPExporter("localhost:4318", "", nil, 0); err == nil {
+		t.Error("expected error for endpoint without scheme")
+	}
+	if _, err := NewOTLPExporter(srv.URL, "grpc", nil, 0); err == nil {
+		t.Error("expected error for unsupported protocol")
+	}
+}
+
+// protoFields decodes one protobuf message level into field number -> values
+// (uint64 for varint and fixed64, []byte for length-delimited).
+func protoFields(t *testing.T, b []byte) map[int][]interface{} {
+	t.Helper()
+	out := make(map[int][]interface{})
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			t.Fatalf("bad tag")
+		}
+		b = b[n:]
+		field := int(tag >> 3)
+		switch tag & 7 {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				t.Fatalf("bad varint")
+			}
+			out[field] = append(out[field], v)
+			b = b[n:]
+		case wireFixed64:
+			out[field] = append(out[field], binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || int(l) > len(b)-n {
+				t.Fatalf("bad length")
+			}
+			out[field] = append(out[field], b[n:n+int(l)])
+			b = b[n+int(l):]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+	}
+	return out
+}
+
+func TestConfigApplyEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=abc, x-team = infra")
+	t.Setenv("OTEL_SERVICE_NAME", "ntm-ci")
+	t.Setenv("NTM_TRACE_FILE", "/tmp/t.jsonl")
+
+	cfg := Config{Headers: map[string]string{"keep": "1"}}.ApplyEnv()
+	if cfg.Endpoint != "http://collector:4318" || cfg.ServiceName != "ntm-ci" || cfg.File != "/tmp/t.jsonl" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if cfg.Headers["api-key"] != "abc" || cfg.Headers["x-team"] != "infra" || cfg.Headers["keep"] != "1" {
+		t.Errorf("headers = %v", cfg.Headers)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://traces:4318")
+	if got := (Config{}).ApplyEnv().Endpoint; got != "http://traces:4318" {
+		t.Errorf("traces endpoint = %q", got)
+	}
+
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	if (Config{}).ApplyEnv().Enabled() {
+		t.Error("OTEL_SDK_DISABLED should disable export")
+	}
+}
@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Dicklesworthstone/ntm/internal/tokens"
+)
+
+// TurnInfo describes one prompt sent to an agent pane.
+type TurnInfo struct {
+	Session   string
+	Pane      string
+	AgentType string
+	Prompt    string
+}
+
+// StartTurn starts an "agent.turn" span covering a prompt and the agent's
+// work up to going idle. Callers that wait for idle themselves end the span;
+// asynchronous senders use OpenTurn instead.
+func StartTurn(ctx context.Context, info TurnInfo) *Span {
+	_, span := Start(ctx, "agent.turn", WithAttributes(
+		String("ntm.session", info.Session),
+		String("ntm.pane", info.Pane),
+		String("ntm.agent.type", info.AgentType),
+		Int("ntm.prompt.chars", len(info.Prompt)),
+		Int("ntm.prompt.tokens_estimate", tokens.EstimateTokens(info.Prompt)),
+	))
+	return span
+}
+
+type turnKey struct {
+	session string
+	pane    string
+}
+
+var (
+	turnsMu sync.Mutex
+	turns   = map[turnKey]*Span{}
+)
+
+// OpenTurn starts a turn whose end is observed later, when CloseTurn sees the
+// pane go idle. An open turn on the same pane is ended as superseded.
+func OpenTurn(ctx context.Context, info TurnInfo) {
+	span := StartTurn(ctx, info)
+	if span == nil {
+		return
+	}
+	key := turnKey{info.Session, info.Pane}
+
+	turnsMu.Lock()
+	prev := turns[key]
+	turns[key] = span
+	turnsMu.Unlock()
+
+	if prev != nil {
+		prev.SetAttributes(Bool("ntm.turn.superseded", true))
+		prev.End()
+	}
+}
+
+// CloseTurn ends the open turn on a pane, reporting whether there was one.
+func CloseTurn(session, pane string) bool {
+	key := turnKey{session, pane}
+	turnsMu.Lock()
+	span := turns[key]
+	delete(turns, key)
+	turnsMu.Unlock()
+
+	if span == nil {
+		return false
+	}
+	span.SetStatus(StatusOK, "")
+	span.End()
+	return true
+}
+
+// OpenTurnSessions lists sessions with at least one open turn, so idle
+// watchers can keep polling them.
+func OpenTurnSessions() []string {
+	turnsMu.Lock()
+	defer turnsMu.Unlock()
+	seen := make(map[string]bool)
+	var out []string
+	for key := range turns {
+		if !seen[key.session] {
+			seen[key.session] = true
+			out = append(out, key.session)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func closeAllTurns() {
+	turnsMu.Lock()
+	open := turns
+	turns = map[turnKey]*Span{}
+	turnsMu.Unlock()
+
+	for _, span := range open {
+		span.SetAttributes(Bool("ntm.turn.unfinished", true))
+		span.End()
+	}
+}
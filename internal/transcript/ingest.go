@@ -0,0 +1,317 @@
+package transcript
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	ctxmon "github.com/Dicklesworthstone/ntm/internal/context"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/process"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// Pane is an ntm agent pane to match against transcripts.
+type Pane struct {
+	ID        string // tmux pane ID, e.g. "%3"
+	Name      string // pane title, e.g. "proj__cc_1"; used as the agent ID
+	AgentType string // cc, cod, gmi
+	PID       int    // shell PID from tmux
+	Dir       string // fallback working directory when /proc is unavailable
+}
+
+// Match methods reported in Result.
+const (
+	MatchPID = "pid" // the agent process holds the transcript open
+	MatchCwd = "cwd" // same working directory, started after the agent
+)
+
+// Result is the transcript matched to a pane.
+type Result struct {
+	Session  string   `json:"session"`
+	PaneID   string   `json:"pane_id"`
+	PaneName string   `json:"pane_name"`
+	Method   string   `json:"method"`
+	Snapshot Snapshot `json:"snapshot"`
+}
+
+// startSlack tolerates clock skew between the agent's first transcript
+// record and the process start time.
+const startSlack = 5 * time.Second
+
+// Ingester maps panes to their agent transcripts and feeds exact usage into
+// the cost tracker and context monitor. Panes without a transcript are left
+// to the existing heuristics. It is safe for concurrent use.
+type Ingester struct {
+	Dirs    Dirs
+	Costs   *cost.CostTracker
+	Context *ctxmon.ContextMonitor
+
+	mu       sync.Mutex
+	readers  map[string]*Reader // path -> reader
+	assigned map[string]binding // session + pane ID -> transcript
+	inspect  func(pid int) Process
+	agentPID func(shellPID int) int
+}
+
+type binding struct {
+	session string
+	path    string
+	format  Format
+	pid     int
+	method  string
+}
+
+// NewIngester creates an ingester over the default transcript directories.
+// Either sink may be nil.
+func NewIngester(costs *cost.CostTracker, monitor *ctxmon.ContextMonitor) *Ingester {
+	return &Ingester{
+		Dirs:     DefaultDirs(),
+		Costs:    costs,
+		Context:  monitor,
+		readers:  make(map[string]*Reader),
+		assigned: make(map[string]binding),
+		inspect:  InspectProcess,
+		agentPID: process.GetChildPID,
+	}
+}
+
+// Sync matches the session's panes to transcripts, reads new records and
+// updates the sinks. It returns the panes that have a transcript.
+func (in *Ingester) Sync(session string, panes []Pane) []Result {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	type pending struct {
+		pane   Pane
+		format Format
+		proc   Process
+	}
+	var unmatched []pending
+	claimed := make(map[string]bool)
+	live := make(map[string]bool, len(panes))
+
+	for _, p := range panes {
+		format, ok := FormatForAgent(p.AgentType)
+		if !ok {
+			continue
+		}
+		key := bindingKey(session, p.ID)
+		live[key] = true
+		pid := in.agentPID(p.PID)
+		if b, ok := in.assigned[key]; ok && b.pid == pid {
+			continue
+		}
+		delete(in.assigned, key)
+		proc := in.inspect(pid)
+		if proc.Cwd == "" {
+			proc.Cwd = p.Dir
+		}
+		if path := in.openTranscript(format, proc); path != "" {
+			in.assigned[key] = binding{session: session, path: path, format: format, pid: pid, method: MatchPID}
+			claimed[path] = true
+			continue
+		}
+		unmatched = append(unmatched, pending{pane: p, format: format, proc: proc})
+	}
+	for key, b := range in.assigned {
+		if b.session == session && !live[key] {
+			delete(in.assigned, key)
+		}
+	}
+	for _, b := range in.assigned {
+		claimed[b.path] = true
+	}
+
+	// Panes sharing a format and directory claim transcripts in start order.
+	sort.SliceStable(unmatched, func(i, j int) bool {
+		return unmatched[i].proc.StartedAt.Before(unmatched[j].proc.StartedAt)
+	})
+	groups := make(map[string]int)
+	for _, u := range unmatched {
+		groups[string(u.format)+"\x00"+u.proc.Cwd]++
+	}
+	for _, u := range unmatched {
+		// Without a start time, only an unambiguous pane can be matched.
+		if u.proc.StartedAt.IsZero() && groups[string(u.format)+"\x00"+u.proc.Cwd] > 1 {
+			continue
+		}
+		if path := in.findByCwd(u.format, u.proc, claimed); path != "" {
+			in.assigned[bindingKey(session, u.pane.ID)] = binding{session: session, path: path, format: u.format, pid: u.proc.PID, method: MatchCwd}
+			claimed[path] = true
+		}
+	}
+
+	var results []Result
+	for _, p := range panes {
+		key := bindingKey(session, p.ID)
+		b, ok := in.assigned[key]
+		if !ok {
+			continue
+		}
+		snap, err := in.reader(b.path, b.format).Update()
+		if err != nil {
+			delete(in.assigned, key)
+			delete(in.readers, b.path)
+			continue
+		}
+		in.feed(session, p, snap)
+		results = append(results, Result{
+			Session:  session,
+			PaneID:   p.ID,
+			PaneName: p.Name,
+			Method:   b.method,
+			Snapshot: snap,
+		})
+	}
+	in.pruneReaders()
+	return results
+}
+
+func bindingKey(session, paneID string) string {
+	return session + "\x00" + paneID
+}
+
+func (in *Ingester) reader(path string, format Format) *Reader {
+	r, ok := in.readers[path]
+	if !ok {
+		r = NewReader(path, format)
+		in.readers[path] = r
+	}
+	return r
+}
+
+// pruneReaders drops readers no pane is bound to.
+func (in *Ingester) pruneReaders() {
+	used := make(map[string]bool, len(in.assigned))
+	for _, b := range in.assigned {
+		used[b.path] = true
+	}
+	for path := range in.readers {
+		if !used[path] {
+			delete(in.readers, path)
+		}
+	}
+}
+
+// openTranscript returns a transcript the agent process holds open.
+func (in *Ingester) openTranscript(format Format, proc Process) string {
+	for _, f := range proc.OpenFiles {
+		if in.Dirs.Owns(format, filepath.Clean(f)) {
+			return f
+		}
+	}
+	return ""
+}
+
+// findByCwd returns the earliest unclaimed transcript for the agent's working
+// directory that started after the agent process.
+func (in *Ingester) findByCwd(format Format, proc Process, claimed map[string]bool) string {
+	since := proc.StartedAt
+	if !since.IsZero() {
+		since = since.Add(-startSlack)
+	}
+	paths, err := in.Dirs.Candidates(format, proc.Cwd, since)
+	if err != nil {
+		return ""
+	}
+
+	var best string
+	var bestSnap Snapshot
+	for _, path := range paths {
+		if claimed[path] {
+			continue
+		}
+		snap, err := in.reader(path, format).Update()
+		if err != nil || snap.StartedAt.IsZero() {
+			continue
+		}
+		if format == FormatCodex && filepath.Clean(snap.Cwd) != filepath.Clean(proc.Cwd) {
+			continue
+		}
+		if !since.IsZero() && snap.StartedAt.Before(since) {
+			continue
+		}
+		if best == "" || betterCandidate(snap, bestSnap, !proc.StartedAt.IsZero()) {
+			best, bestSnap = path, snap
+		}
+	}
+	return best
+}
+
+// betterCandidate prefers the transcript started closest to the process
+// start, or the most recently active one when the start is unknown.
+func betterCandidate(a, b Snapshot, knownStart bool) bool {
+	if knownStart {
+		return a.StartedAt.Before(b.StartedAt)
+	}
+	return a.UpdatedAt.After(b.UpdatedAt)
+}
+
+func (in *Ingester) feed(session string, p Pane, snap Snapshot) {
+	u := snap.Usage
+	if in.Costs != nil && snap.Turns > 0 {
+		in.Costs.SetExactUsage(session, p.Name, snap.Model,
+			int(u.InputTokens), int(u.OutputTokens), int(u.CacheReadTokens), int(u.CacheWriteTokens))
+	}
+	if in.Context != nil && snap.ContextTokens > 0 {
+		in.Context.RegisterAgentWithTranscript(p.Name, p.ID, snap.Model, p.AgentType, session, snap.Path)
+		in.Context.UpdateFromUsage(p.Name, snap.Model, snap.ContextTokens, snap.ContextWindow,
+			u.InputTokens+u.CacheReadTokens+u.CacheWriteTokens, u.OutputTokens)
+	}
+}
+
+// PanesFromTmux lists the agent panes of a tmux session.
+func PanesFromTmux(ctx context.Context, session string) ([]Pane, error) {
+	panes, err := tmux.GetPanesContext(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Pane, 0, len(panes))
+	for _, p := range panes {
+		if _, ok := FormatForAgent(string(p.Type)); !ok {
+			continue
+		}
+		out = append(out, Pane{
+			ID:        p.ID,
+			Name:      p.Title,
+			AgentType: string(p.Type),
+			PID:       p.PID,
+		})
+	}
+	return out, nil
+}
+
+// Run syncs every tmux session each interval until ctx is done.
+func (in *Ingester) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		in.SyncAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncAll syncs the agent panes of every tmux session.
+func (in *Ingester) SyncAll(ctx context.Context) {
+	sessions, err := tmux.ListSessions()
+	if err != nil {
+		return
+	}
+	for _, s := range sessions {
+		if ctx.Err() != nil {
+			return
+		}
+		panes, err := PanesFromTmux(ctx, s.Name)
+		if err != nil {
+			continue
+		}
+		in.Sync(s.Name, panes)
+	}
+}
@@ -0,0 +1,145 @@
+package transcript
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dirs holds the directories the agent CLIs write transcripts to.
+type Dirs struct {
+	Claude string // ~/.claude/projects
+	Codex  string // ~/.codex/sessions
+	Gemini string // ~/.gemini/tmp
+}
+
+// DefaultDirs returns the standard transcript locations, honoring
+// CLAUDE_CONFIG_DIR and CODEX_HOME.
+func DefaultDirs() Dirs {
+	home, _ := os.UserHomeDir()
+	d := Dirs{
+		Claude: filepath.Join(home, ".claude", "projects"),
+		Codex:  filepath.Join(home, ".codex", "sessions"),
+		Gemini: filepath.Join(home, ".gemini", "tmp"),
+	}
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		d.Claude = filepath.Join(dir, "projects")
+	}
+	if dir := os.Getenv("CODEX_HOME"); dir != "" {
+		d.Codex = filepath.Join(dir, "sessions")
+	}
+	return d
+}
+
+// root returns the directory holding transcripts of the given format.
+func (d Dirs) root(format Format) string {
+	switch format {
+	case FormatClaude:
+		return d.Claude
+	case FormatCodex:
+		return d.Codex
+	case FormatGemini:
+		return d.Gemini
+	}
+	return ""
+}
+
+// Owns reports whether path is a transcript of the given format.
+func (d Dirs) Owns(format Format, path string) bool {
+	root := d.root(format)
+	if root == "" {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	switch format {
+	case FormatGemini:
+		return strings.HasSuffix(path, ".json") && filepath.Base(filepath.Dir(path)) == "chats"
+	case FormatCodex:
+		return strings.HasSuffix(path, ".jsonl") && strings.HasPrefix(filepath.Base(path), "rollout-")
+	default:
+		return strings.HasSuffix(path, ".jsonl")
+	}
+}
+
+// ClaudeProjectDir returns where Claude Code keeps transcripts for cwd:
+// the path with every non-alphanumeric character replaced by '-'.
+func (d Dirs) ClaudeProjectDir(cwd string) string {
+	var b strings.Builder
+	for _, r := range cwd {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return filepath.Join(d.Claude, b.String())
+}
+
+// GeminiProjectDir returns where Gemini CLI keeps chats for cwd, keyed by
+// the SHA-256 of the path.
+func (d Dirs) GeminiProjectDir(cwd string) string {
+	sum := sha256.Sum256([]byte(cwd))
+	return filepath.Join(d.Gemini, hex.EncodeToString(sum[:]), "chats")
+}
+
+// codexLookback bounds how many days of Codex session folders are scanned.
+const codexLookback = 7 * 24 * time.Hour
+
+// Candidates lists transcripts of the given format that may belong to an
+// agent running in cwd and modified since the given time. Codex files are
+// not filtered by cwd here since it is only known after reading them.
+func (d Dirs) Candidates(format Format, cwd string, since time.Time) ([]string, error) {
+	var pattern []string
+	switch format {
+	case FormatClaude:
+		if cwd == "" {
+			return nil, nil
+		}
+		pattern = []string{filepath.Join(d.ClaudeProjectDir(cwd), "*.jsonl")}
+	case FormatGemini:
+		if cwd == "" {
+			return nil, nil
+		}
+		pattern = []string{filepath.Join(d.GeminiProjectDir(cwd), "session-*.json")}
+	case FormatCodex:
+		// Rollouts are filed under the local date the session started.
+		from := since
+		if from.IsZero() || time.Since(from) > codexLookback {
+			from = time.Now().Add(-codexLookback)
+		}
+		for day := from.AddDate(0, 0, -1); !day.After(time.Now()); day = day.AddDate(0, 0, 1) {
+			pattern = append(pattern, filepath.Join(d.Codex,
+				strconv.Itoa(day.Year()), fmt.Sprintf("%02d", int(day.Month())), fmt.Sprintf("%02d", day.Day()),
+				"rollout-*.jsonl"))
+		}
+	default:
+		return nil, fmt.Errorf("unknown transcript format %q", format)
+	}
+
+	var out []string
+	for _, p := range pattern {
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			if !since.IsZero() && info.ModTime().Before(since) {
+				continue
+			}
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,111 @@
+package transcript
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Process describes an agent process as far as the platform exposes it.
+// Fields are left empty where /proc is unavailable.
+type Process struct {
+	PID       int
+	Cwd       string
+	StartedAt time.Time
+	// OpenFiles lists files held open by the process and its descendants.
+	OpenFiles []string
+}
+
+// userHZ is the kernel's clock tick rate for /proc/<pid>/stat, 100 on every
+// mainstream Linux build.
+const userHZ = 100
+
+// maxProcessDepth bounds the descendant walk (shell -> node -> native binary).
+const maxProcessDepth = 4
+
+// InspectProcess reads cwd, start time and open files of pid from /proc.
+func InspectProcess(pid int) Process {
+	p := Process{PID: pid}
+	if pid <= 0 {
+		return p
+	}
+	if cwd, err := os.Readlink(fmt.Sprintf("/proc/%d/cwd", pid)); err == nil {
+		p.Cwd = cwd
+	}
+	p.StartedAt = processStartTime(pid)
+	p.OpenFiles = openFiles(pid, maxProcessDepth)
+	return p
+}
+
+func openFiles(pid, depth int) []string {
+	var files []string
+	fdDir := fmt.Sprintf("/proc/%d/fd", pid)
+	if entries, err := os.ReadDir(fdDir); err == nil {
+		for _, e := range entries {
+			target, err := os.Readlink(filepath.Join(fdDir, e.Name()))
+			if err == nil && filepath.IsAbs(target) {
+				files = append(files, target)
+			}
+		}
+	}
+	if depth <= 1 {
+		return files
+	}
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/task/%d/children", pid, pid))
+	if err != nil {
+		return files
+	}
+	for _, field := range strings.Fields(string(data)) {
+		if child, err := strconv.Atoi(field); err == nil {
+			files = append(files, openFiles(child, depth-1)...)
+		}
+	}
+	return files
+}
+
+// processStartTime converts the starttime field of /proc/<pid>/stat (clock
+// ticks since boot) to wall time.
+func processStartTime(pid int) time.Time {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return time.Time{}
+	}
+	// The command name may contain spaces; fields resume after its ')'.
+	s := string(data)
+	i := strings.LastIndexByte(s, ')')
+	if i < 0 {
+		return time.Time{}
+	}
+	fields := strings.Fields(s[i+1:])
+	// starttime is field 22 overall, the 20th after "pid (comm)".
+	if len(fields) < 20 {
+		return time.Time{}
+	}
+	ticks, err := strconv.ParseInt(fields[19], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	boot := bootTime()
+	if boot.IsZero() {
+		return time.Time{}
+	}
+	return boot.Add(time.Duration(ticks) * time.Second / userHZ)
+}
+
+func bootTime() time.Time {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(line, "btime "); ok {
+			if sec, err := strconv.ParseInt(strings.TrimSpace(rest), 10, 64); err == nil {
+				return time.Unix(sec, 0)
+			}
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,102 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// sessionIDPattern bounds what is accepted as a native session ID before it
+// is appended to a shell command.
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]{0,127}$`)
+
+// resumeHintPattern matches the resume hint agent CLIs print on exit, e.g.
+// "To continue this session, run codex resume <id>".
+var resumeHintPattern = regexp.MustCompile(`\b(claude|codex|gemini)(?:\s+--resume|\s+-r|\s+resume)\s+([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})\b`)
+
+// binaries maps a transcript format to the CLI named in resume hints.
+var binaries = map[Format]string{
+	FormatClaude: "claude",
+	FormatCodex:  "codex",
+	FormatGemini: "gemini",
+}
+
+// ResumeCommand returns the agent's launch command changed to resume the
+// native session sessionID: "--resume <id>" for Claude Code and Gemini CLI,
+// "resume <id>" for Codex. The agent must be started in the session's
+// original working directory.
+func ResumeCommand(agentType, command, sessionID string) (string, error) {
+	format, ok := FormatForAgent(agentType)
+	if !ok {
+		return "", fmt.Errorf("agent type %q cannot resume native sessions", agentType)
+	}
+	if !sessionIDPattern.MatchString(sessionID) {
+		return "", fmt.Errorf("invalid native session ID %q", sessionID)
+	}
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return "", fmt.Errorf("empty launch command")
+	}
+	if format == FormatCodex {
+		return command + " resume " + sessionID, nil
+	}
+	return command + " --resume " + sessionID, nil
+}
+
+// SessionExists reports whether the agent CLI still has the native session
+// sessionID for cwd on disk, i.e. whether it can be resumed.
+func (d Dirs) SessionExists(format Format, cwd, sessionID string) bool {
+	if !sessionIDPattern.MatchString(sessionID) {
+		return false
+	}
+	switch format {
+	case FormatClaude:
+		if cwd == "" {
+			return false
+		}
+		_, err := os.Stat(filepath.Join(d.ClaudeProjectDir(cwd), sessionID+".jsonl"))
+		return err == nil
+	case FormatCodex:
+		// Rollouts are named rollout-<timestamp>-<id>.jsonl under YYYY/MM/DD.
+		matches, _ := filepath.Glob(filepath.Join(d.Codex, "*", "*", "*", "rollout-*"+sessionID+".jsonl"))
+		return len(matches) > 0
+	case FormatGemini:
+		if cwd == "" {
+			return false
+		}
+		// Chat files only carry a prefix of the ID in their name.
+		matches, _ := filepath.Glob(filepath.Join(d.GeminiProjectDir(cwd), "session-*.json"))
+		for _, m := range matches {
+			data, err := os.ReadFile(m)
+			if err != nil {
+				continue
+			}
+			var chat struct {
+				SessionID string `json:"sessionId"`
+			}
+			if json.Unmarshal(data, &chat) == nil && chat.SessionID == sessionID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SessionIDFromOutput returns the last native session ID the agent printed
+// in a resume hint, or "" if there is none. Codex prints one when it exits.
+func SessionIDFromOutput(agentType, output string) string {
+	format, ok := FormatForAgent(agentType)
+	if !ok {
+		return ""
+	}
+	matches := resumeHintPattern.FindAllStringSubmatch(output, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		if matches[i][1] == binaries[format] {
+			return matches[i][2]
+		}
+	}
+	return ""
+}
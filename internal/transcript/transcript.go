@@ -0,0 +1,417 @@
+// Package transcript reads exact token usage from the session logs that agent
+// CLIs write themselves: Claude Code project JSONL, Codex session rollouts and
+// Gemini CLI chat files. Readers tail the files incrementally, and the
+// Ingester maps them to ntm panes and feeds cost and context tracking.
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Format identifies which agent CLI wrote a transcript.
+type Format string
+
+const (
+	FormatClaude Format = "claude"
+	FormatCodex  Format = "codex"
+	FormatGemini Format = "gemini"
+)
+
+// FormatForAgent returns the transcript format written by an ntm agent type
+// (cc, cod, gmi or their long names).
+func FormatForAgent(agentType string) (Format, bool) {
+	switch strings.ToLower(agentType) {
+	case "cc", "claude", "claude-code", "claude_code":
+		return FormatClaude, true
+	case "cod", "codex":
+		return FormatCodex, true
+	case "gmi", "gemini":
+		return FormatGemini, true
+	}
+	return "", false
+}
+
+// Usage holds token counts as reported by the provider. InputTokens excludes
+// cached input, which is counted separately.
+type Usage struct {
+	InputTokens      int64 `json:"input_tokens"`
+	OutputTokens     int64 `json:"output_tokens"`
+	CacheReadTokens  int64 `json:"cache_read_tokens"`
+	CacheWriteTokens int64 `json:"cache_write_tokens"`
+}
+
+// Total returns the sum of all token counts.
+func (u Usage) Total() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+func (u *Usage) add(o Usage) {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CacheReadTokens += o.CacheReadTokens
+	u.CacheWriteTokens += o.CacheWriteTokens
+}
+
+func (u *Usage) sub(o Usage) {
+	u.InputTokens -= o.InputTokens
+	u.OutputTokens -= o.OutputTokens
+	u.CacheReadTokens -= o.CacheReadTokens
+	u.CacheWriteTokens -= o.CacheWriteTokens
+}
+
+// Snapshot is what a transcript reports so far.
+type Snapshot struct {
+	Path      string    `json:"path"`
+	Format    Format    `json:"format"`
+	SessionID string    `json:"session_id,omitempty"`
+	Cwd       string    `json:"cwd,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Usage is cumulative over every turn in the transcript.
+	Usage Usage `json:"usage"`
+	// ContextTokens is the size of the context window as of the last turn.
+	ContextTokens int64 `json:"context_tokens"`
+	// ContextWindow is the window size the agent reported (0 if unknown).
+	ContextWindow int64 `json:"context_window,omitempty"`
+	Turns         int   `json:"turns"`
+}
+
+// maxLineBytes bounds a single JSONL record; longer lines are skipped.
+const maxLineBytes = 16 << 20
+
+// Reader tails one transcript file. Update reads whatever was appended since
+// the previous call; a file that shrinks is re-read from the start.
+type Reader struct {
+	path   string
+	format Format
+
+	offset  int64
+	partial []byte
+	size    int64
+	modTime time.Time
+
+	snap       Snapshot
+	claudeMsgs map[string]Usage // message ID -> usage already counted
+}
+
+// NewReader creates a reader for path.
+func NewReader(path string, format Format) *Reader {
+	r := &Reader{path: path, format: format}
+	r.reset()
+	return r
+}
+
+func (r *Reader) reset() {
+	r.offset = 0
+	r.partial = nil
+	r.snap = Snapshot{Path: r.path, Format: r.format}
+	r.claudeMsgs = make(map[string]Usage)
+}
+
+// Snapshot returns the state as of the last Update.
+func (r *Reader) Snapshot() Snapshot {
+	return r.snap
+}
+
+// Update reads new records and returns the updated snapshot.
+func (r *Reader) Update() (Snapshot, error) {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return r.snap, err
+	}
+	if info.Size() < r.size {
+		r.reset()
+	}
+	changed := info.Size() != r.size || !info.ModTime().Equal(r.modTime)
+	r.size = info.Size()
+	r.modTime = info.ModTime()
+
+	if r.format == FormatGemini {
+		// Gemini rewrites the whole chat file on every message.
+		if !changed && r.offset > 0 {
+			return r.snap, nil
+		}
+		data, err := os.ReadFile(r.path)
+		if err != nil {
+			return r.snap, fmt.Errorf("read transcript: %w", err)
+		}
+		snap, err := parseGemini(data)
+		if err != nil {
+			return r.snap, err
+		}
+		snap.Path, snap.Format = r.path, r.format
+		r.snap = snap
+		r.offset = r.size
+		return r.snap, nil
+	}
+
+	if r.size == r.offset {
+		return r.snap, nil
+	}
+	f, err := os.Open(r.path)
+	if err != nil {
+		return r.snap, fmt.Errorf("open transcript: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(r.offset, io.SeekStart); err != nil {
+		return r.snap, fmt.Errorf("seek transcript: %w", err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return r.snap, fmt.Errorf("read transcript: %w", err)
+	}
+	r.offset += int64(len(data))
+	if len(r.partial) > 0 {
+		data = append(r.partial, data...)
+		r.partial = nil
+	}
+
+	// Keep a trailing line without newline until the agent finishes it.
+	if i := bytes.LastIndexByte(data, '\n'); i < len(data)-1 {
+		if len(data)-i-1 <= maxLineBytes {
+			r.partial = append([]byte(nil), data[i+1:]...)
+		}
+		data = data[:i+1]
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		switch r.format {
+		case FormatClaude:
+			r.applyClaude(line)
+		case FormatCodex:
+			r.applyCodex(line)
+		}
+	}
+	return r.snap, nil
+}
+
+func (r *Reader) touch(ts time.Time) {
+	if ts.IsZero() {
+		return
+	}
+	if r.snap.StartedAt.IsZero() || ts.Before(r.snap.StartedAt) {
+		r.snap.StartedAt = ts
+	}
+	if ts.After(r.snap.UpdatedAt) {
+		r.snap.UpdatedAt = ts
+	}
+}
+
+// claudeRecord is one line of a Claude Code project transcript.
+type claudeRecord struct {
+	Type        string    `json:"type"`
+	Timestamp   time.Time `json:"timestamp"`
+	SessionID   string    `json:"sessionId"`
+	Cwd         string    `json:"cwd"`
+	IsSidechain bool      `json:"isSidechain"`
+	Message     *struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+func (r *Reader) applyClaude(line []byte) {
+	var rec claudeRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return
+	}
+	r.touch(rec.Timestamp)
+	if r.snap.SessionID == "" {
+		r.snap.SessionID = rec.SessionID
+	}
+	if rec.Cwd != "" {
+		r.snap.Cwd = rec.Cwd
+	}
+	if rec.Type != "assistant" || rec.Message == nil || rec.Message.Usage == nil {
+		return
+	}
+	mu := rec.Message.Usage
+	u := Usage{
+		InputTokens:      mu.InputTokens,
+		OutputTokens:     mu.OutputTokens,
+		CacheReadTokens:  mu.CacheReadInputTokens,
+		CacheWriteTokens: mu.CacheCreationInputTokens,
+	}
+	// Claude Code writes one line per content block, each repeating the
+	// message's usage; count every message once, with its latest usage.
+	if rec.Message.ID != "" {
+		if prev, ok := r.claudeMsgs[rec.Message.ID]; ok {
+			r.snap.Usage.sub(prev)
+		} else {
+			r.snap.Turns++
+		}
+		r.claudeMsgs[rec.Message.ID] = u
+	} else {
+		r.snap.Turns++
+	}
+	r.snap.Usage.add(u)
+
+	// Subagent (sidechain) turns have their own context window.
+	if !rec.IsSidechain {
+		r.snap.ContextTokens = u.Total()
+		if m := rec.Message.Model; m != "" && !strings.HasPrefix(m, "<") {
+			r.snap.Model = m
+		}
+	}
+}
+
+// codexRecord is one line of a Codex session rollout.
+type codexRecord struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+type codexUsage struct {
+	InputTokens       int64 `json:"input_tokens"`
+	CachedInputTokens int64 `json:"cached_input_tokens"`
+	OutputTokens      int64 `json:"output_tokens"`
+	TotalTokens       int64 `json:"total_tokens"`
+}
+
+func (u codexUsage) usage() Usage {
+	// Codex counts cached tokens inside input_tokens.
+	return Usage{
+		InputTokens:     u.InputTokens - u.CachedInputTokens,
+		OutputTokens:    u.OutputTokens,
+		CacheReadTokens: u.CachedInputTokens,
+	}
+}
+
+func (r *Reader) applyCodex(line []byte) {
+	var rec codexRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return
+	}
+	r.touch(rec.Timestamp)
+
+	switch rec.Type {
+	case "session_meta":
+		var p struct {
+			ID        string    `json:"id"`
+			Cwd       string    `json:"cwd"`
+			Timestamp time.Time `json:"timestamp"`
+		}
+		if json.Unmarshal(rec.Payload, &p) == nil {
+			r.snap.SessionID = p.ID
+			r.snap.Cwd = p.Cwd
+			r.touch(p.Timestamp)
+		}
+	case "turn_context":
+		var p struct {
+			Cwd   string `json:"cwd"`
+			Model string `json:"model"`
+		}
+		if json.Unmarshal(rec.Payload, &p) == nil {
+			if p.Cwd != "" {
+				r.snap.Cwd = p.Cwd
+			}
+			if p.Model != "" {
+				r.snap.Model = p.Model
+			}
+		}
+	case "event_msg":
+		var p struct {
+			Type string `json:"type"`
+			Info *struct {
+				Total              codexUsage `json:"total_token_usage"`
+				Last               codexUsage `json:"last_token_usage"`
+				ModelContextWindow int64      `json:"model_context_window"`
+			} `json:"info"`
+		}
+		if json.Unmarshal(rec.Payload, &p) != nil || p.Type != "token_count" || p.Info == nil {
+			return
+		}
+		// total_token_usage is already cumulative.
+		r.snap.Usage = p.Info.Total.usage()
+		r.snap.Turns++
+		if p.Info.Last.TotalTokens > 0 {
+			r.snap.ContextTokens = p.Info.Last.TotalTokens
+		} else {
+			r.snap.ContextTokens = p.Info.Last.InputTokens + p.Info.Last.OutputTokens
+		}
+		if p.Info.ModelContextWindow > 0 {
+			r.snap.ContextWindow = p.Info.ModelContextWindow
+		}
+	}
+}
+
+// geminiChat is a Gemini CLI chat file (~/.gemini/tmp/<hash>/chats/*.json).
+type geminiChat struct {
+	SessionID   string    `json:"sessionId"`
+	StartTime   time.Time `json:"startTime"`
+	LastUpdated time.Time `json:"lastUpdated"`
+	Messages    []struct {
+		Type      string    `json:"type"`
+		Timestamp time.Time `json:"timestamp"`
+		Model     string    `json:"model"`
+		Tokens    *struct {
+			Input    int64 `json:"input"`
+			Output   int64 `json:"output"`
+			Cached   int64 `json:"cached"`
+			Thoughts int64 `json:"thoughts"`
+			Tool     int64 `json:"tool"`
+			Total    int64 `json:"total"`
+		} `json:"tokens"`
+	} `json:"messages"`
+}
+
+func parseGemini(data []byte) (Snapshot, error) {
+	var chat geminiChat
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return Snapshot{}, fmt.Errorf("parse gemini chat: %w", err)
+	}
+	snap := Snapshot{
+		SessionID: chat.SessionID,
+		StartedAt: chat.StartTime,
+		UpdatedAt: chat.LastUpdated,
+	}
+	for _, msg := range chat.Messages {
+		if snap.StartedAt.IsZero() {
+			snap.StartedAt = msg.Timestamp
+		}
+		if msg.Timestamp.After(snap.UpdatedAt) {
+			snap.UpdatedAt = msg.Timestamp
+		}
+		if msg.Tokens == nil {
+			continue
+		}
+		t := msg.Tokens
+		// Gemini counts cached tokens inside input and reports thinking
+		// tokens separately from output.
+		snap.Usage.add(Usage{
+			InputTokens:     t.Input - t.Cached,
+			OutputTokens:    t.Output + t.Thoughts,
+			CacheReadTokens: t.Cached,
+		})
+		snap.Turns++
+		snap.ContextTokens = t.Total
+		if snap.ContextTokens == 0 {
+			snap.ContextTokens = t.Input + t.Output + t.Thoughts + t.Tool
+		}
+		if msg.Model != "" {
+			snap.Model = msg.Model
+		}
+	}
+	return snap, nil
+}
@@ -0,0 +1,279 @@
+package transcript
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ctxmon "github.com/Dicklesworthstone/ntm/internal/context"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func claudeLine(ts time.Time, msgID string, in, out, cacheRead, cacheWrite int) string {
+	return fmt.Sprintf(`{"type":"assistant","timestamp":%q,"sessionId":"s1","cwd":"/work/proj","message":{"id":%q,"model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":%d,"output_tokens":%d,"cache_read_input_tokens":%d,"cache_creation_input_tokens":%d}}}`+"\n",
+		ts.UTC().Format(time.RFC3339Nano), msgID, in, out, cacheRead, cacheWrite)
+}
+
+func TestReaderClaude(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+	t0 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	writeFile(t, path,
+		`{"type":"user","timestamp":"2026-03-01T09:59:59Z","sessionId":"s1","cwd":"/work/proj","message":{"role":"user","content":"hi"}}`+"\n"+
+			claudeLine(t0, "msg_1", 10, 5, 1000, 200)+
+			// Second content block of the same message with final usage.
+			claudeLine(t0, "msg_1", 10, 50, 1000, 200))
+
+	r := NewReader(path, FormatClaude)
+	snap, err := r.Update()
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	want := Usage{InputTokens: 10, OutputTokens: 50, CacheReadTokens: 1000, CacheWriteTokens: 200}
+	if snap.Usage != want || snap.Turns != 1 {
+		t.Errorf("usage = %+v turns %d, want %+v turns 1", snap.Usage, snap.Turns, want)
+	}
+	if snap.Cwd != "/work/proj" || snap.SessionID != "s1" || snap.Model != "claude-sonnet-4-5-20250929" {
+		t.Errorf("snapshot meta = %+v", snap)
+	}
+	if !snap.StartedAt.Equal(t0.Add(-time.Second)) || snap.ContextTokens != 1260 {
+		t.Errorf("started %v context %d", snap.StartedAt, snap.ContextTokens)
+	}
+
+	// A line still being written is held back until it is complete.
+	line := claudeLine(t0.Add(time.Minute), "msg_2", 20, 30, 1300, 0)
+	appendFile(t, path, line[:40])
+	if snap, _ = r.Update(); snap.Turns != 1 {
+		t.Fatalf("partial line counted: turns = %d", snap.Turns)
+	}
+	appendFile(t, path, line[40:])
+	snap, _ = r.Update()
+	if snap.Turns != 2 || snap.Usage.OutputTokens != 80 || snap.ContextTokens != 1350 {
+		t.Errorf("after append: %+v", snap)
+	}
+}
+
+func TestReaderCodex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollout-2026-03-01T10-00-00-abc.jsonl")
+	writeFile(t, path, `{"timestamp":"2026-03-01T10:00:00Z","type":"session_meta","payload":{"id":"abc","cwd":"/work/proj","timestamp":"2026-03-01T10:00:00Z"}}
+{"timestamp":"2026-03-01T10:00:01Z","type":"turn_context","payload":{"cwd":"/work/proj","model":"gpt-5-codex"}}
+{"timestamp":"2026-03-01T10:00:05Z","type":"event_msg","payload":{"type":"token_count","info":null}}
+{"timestamp":"2026-03-01T10:00:09Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":5000,"cached_input_tokens":3000,"output_tokens":400,"total_tokens":5400},"last_token_usage":{"input_tokens":5000,"cached_input_tokens":3000,"output_tokens":400,"total_tokens":5400},"model_context_window":272000}}}
+{"timestamp":"2026-03-01T10:01:00Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":11000,"cached_input_tokens":8000,"output_tokens":900,"total_tokens":11900},"last_token_usage":{"input_tokens":6000,"cached_input_tokens":5000,"output_tokens":500,"total_tokens":6500},"model_context_window":272000}}}
+`)
+	snap, err := NewReader(path, FormatCodex).Update()
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	want := Usage{InputTokens: 3000, OutputTokens: 900, CacheReadTokens: 8000}
+	if snap.Usage != want {
+		t.Errorf("usage = %+v, want %+v", snap.Usage, want)
+	}
+	if snap.ContextTokens != 6500 || snap.ContextWindow != 272000 || snap.Model != "gpt-5-codex" || snap.Cwd != "/work/proj" {
+		t.Errorf("snapshot = %+v", snap)
+	}
+}
+
+func TestReaderGemini(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chats", "session-1.json")
+	writeFile(t, path, `{"sessionId":"g1","startTime":"2026-03-01T10:00:00Z","lastUpdated":"2026-03-01T10:02:00Z","messages":[
+{"type":"user","timestamp":"2026-03-01T10:00:00Z","content":"hi"},
+{"type":"gemini","timestamp":"2026-03-01T10:01:00Z","model":"gemini-2.5-pro","tokens":{"input":2000,"output":100,"cached":500,"thoughts":50,"tool":0,"total":2150}},
+{"type":"gemini","timestamp":"2026-03-01T10:02:00Z","model":"gemini-2.5-pro","tokens":{"input":2500,"output":200,"cached":2000,"thoughts":0,"tool":10,"total":2710}}]}`)
+
+	r := NewReader(path, FormatGemini)
+	snap, err := r.Update()
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	want := Usage{InputTokens: 2000, OutputTokens: 350, CacheReadTokens: 2500}
+	if snap.Usage != want || snap.Turns != 2 || snap.ContextTokens != 2710 || snap.Model != "gemini-2.5-pro" {
+		t.Errorf("snapshot = %+v", snap)
+	}
+	again, _ := r.Update()
+	if again.Usage != snap.Usage || again.Turns != snap.Turns {
+		t.Errorf("unchanged file re-counted: %+v", again)
+	}
+}
+
+func TestClaudeProjectDir(t *testing.T) {
+	d := Dirs{Claude: "/home/u/.claude/projects"}
+	if got := d.ClaudeProjectDir("/work/my.proj"); got != "/home/u/.claude/projects/-work-my-proj" {
+		t.Errorf("ClaudeProjectDir = %q", got)
+	}
+}
+
+func newTestIngester(t *testing.T, procs map[int]Process) (*Ingester, Dirs) {
+	t.Helper()
+	root := t.TempDir()
+	dirs := Dirs{
+		Claude: filepath.Join(root, "claude"),
+		Codex:  filepath.Join(root, "codex"),
+		Gemini: filepath.Join(root, "gemini"),
+	}
+	in := NewIngester(cost.NewCostTracker(""), ctxmon.NewContextMonitor(ctxmon.DefaultMonitorConfig()))
+	in.Dirs = dirs
+	in.agentPID = func(shell int) int { return shell + 1000 }
+	in.inspect = func(pid int) Process { return procs[pid] }
+	return in, dirs
+}
+
+func TestIngesterMatchesByCwdAndStartOrder(t *testing.T) {
+	start := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	in, dirs := newTestIngester(t, map[int]Process{
+		1001: {PID: 1001, Cwd: "/work/proj", StartedAt: start},
+		1002: {PID: 1002, Cwd: "/work/proj", StartedAt: start.Add(2 * time.Minute)},
+	})
+	dir := dirs.ClaudeProjectDir("/work/proj")
+	// A stale transcript from before either agent started.
+	writeFile(t, filepath.Join(dir, "old.jsonl"), claudeLine(start.Add(-time.Hour), "m0", 1, 1, 0, 0))
+	writeFile(t, filepath.Join(dir, "a.jsonl"), claudeLine(start.Add(10*time.Second), "m1", 100, 10, 0, 0))
+	writeFile(t, filepath.Join(dir, "b.jsonl"), claudeLine(start.Add(130*time.Second), "m2", 200, 20, 0, 0))
+
+	panes := []Pane{
+		{ID: "%2", Name: "proj__cc_2", AgentType: "cc", PID: 2},
+		{ID: "%1", Name: "proj__cc_1", AgentType: "cc", PID: 1},
+		{ID: "%3", Name: "proj__user_1", AgentType: "user", PID: 3},
+	}
+	results := in.Sync("proj", panes)
+	if len(results) != 2 {
+		t.Fatalf("results = %+v", results)
+	}
+	got := map[string]string{}
+	for _, r := range results {
+		got[r.PaneName] = filepath.Base(r.Snapshot.Path)
+		if r.Method != MatchCwd {
+			t.Errorf("method = %q", r.Method)
+		}
+	}
+	if got["proj__cc_1"] != "a.jsonl" || got["proj__cc_2"] != "b.jsonl" {
+		t.Errorf("matches = %v", got)
+	}
+
+	sc := in.Costs.GetSession("proj")
+	if sc == nil || sc.Agents["proj__cc_1"].InputTokens != 100 || !sc.Agents["proj__cc_2"].Exact() {
+		t.Fatalf("costs = %+v", sc)
+	}
+	est := in.Context.GetEstimate("proj__cc_1")
+	if est == nil || est.Method != ctxmon.MethodTranscript || est.TokensUsed != 110 {
+		t.Errorf("estimate = %+v", est)
+	}
+
+	// Appended usage is picked up on the next sync without re-matching.
+	appendFile(t, filepath.Join(dir, "a.jsonl"), claudeLine(start.Add(time.Minute), "m3", 5, 5, 0, 0))
+	in.Sync("proj", panes)
+	if n := in.Costs.GetSession("proj").Agents["proj__cc_1"].InputTokens; n != 105 {
+		t.Errorf("input after append = %d, want 105", n)
+	}
+}
+
+func TestIngesterMatchesOpenFile(t *testing.T) {
+	in, dirs := newTestIngester(t, nil)
+	path := filepath.Join(dirs.Codex, "2026", "03", "01", "rollout-2026-03-01T10-00-00-abc.jsonl")
+	writeFile(t, path, `{"timestamp":"2026-03-01T10:00:00Z","type":"session_meta","payload":{"id":"abc","cwd":"/elsewhere"}}
+{"timestamp":"2026-03-01T10:00:09Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":50,"output_tokens":5,"total_tokens":55},"last_token_usage":{"input_tokens":50,"output_tokens":5,"total_tokens":55}}}}
+`)
+	in.inspect = func(pid int) Process {
+		return Process{PID: pid, Cwd: "/work/proj", OpenFiles: []string{"/dev/null", path}}
+	}
+
+	results := in.Sync("proj", []Pane{{ID: "%1", Name: "proj__cod_1", AgentType: "cod", PID: 1}})
+	if len(results) != 1 || results[0].Method != MatchPID || results[0].Snapshot.Usage.InputTokens != 50 {
+		t.Fatalf("results = %+v", results)
+	}
+}
+
+func TestIngesterLeavesUnmatchedPanesToHeuristics(t *testing.T) {
+	in, _ := newTestIngester(t, map[int]Process{1001: {PID: 1001, Cwd: "/work/none", StartedAt: time.Now()}})
+	if results := in.Sync("proj", []Pane{{ID: "%1", Name: "proj__cc_1", AgentType: "cc", PID: 1}}); len(results) != 0 {
+		t.Fatalf("results = %+v", results)
+	}
+	in.Costs.RecordPrompt("proj", "proj__cc_1", "claude-sonnet-4", "hello world, how are you")
+	if a := in.Costs.GetSession("proj").Agents["proj__cc_1"]; a.Exact() || a.InputTokens == 0 {
+		t.Errorf("heuristic usage = %+v", a)
+	}
+}
+
+func TestResumeCommand(t *testing.T) {
+	id := "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b"
+	cases := []struct {
+		agent, command, want string
+	}{
+		{"cc", "claude --dangerously-skip-permissions", "claude --dangerously-skip-permissions --resume " + id},
+		{"cod", "codex -m gpt-5 --search", "codex -m gpt-5 --search resume " + id},
+		{"gmi", "gemini --yolo", "gemini --yolo --resume " + id},
+	}
+	for _, c := range cases {
+		got, err := ResumeCommand(c.agent, c.command, id)
+		if err != nil || got != c.want {
+			t.Errorf("ResumeCommand(%s) = %q, %v; want %q", c.agent, got, err, c.want)
+		}
+	}
+	if _, err := ResumeCommand("cc", "claude", "x; rm -rf /"); err == nil {
+		t.Error("expected unsafe session ID to be rejected")
+	}
+	if _, err := ResumeCommand("aider", "aider", id); err == nil {
+		t.Error("expected unsupported agent type to be rejected")
+	}
+}
+
+func TestSessionExists(t *testing.T) {
+	root := t.TempDir()
+	dirs := Dirs{
+		Claude: filepath.Join(root, "claude"),
+		Codex:  filepath.Join(root, "codex"),
+		Gemini: filepath.Join(root, "gemini"),
+	}
+	cwd := "/work/proj"
+	id := "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b"
+
+	writeFile(t, filepath.Join(dirs.ClaudeProjectDir(cwd), id+".jsonl"), "{}\n")
+	writeFile(t, filepath.Join(dirs.Codex, "2026", "10", "16", "rollout-2026-10-16T09-00-00-"+id+".jsonl"), "{}\n")
+	writeFile(t, filepath.Join(dirs.GeminiProjectDir(cwd), "session-2026-10-16T09-00-0199a1b2.json"),
+		fmt.Sprintf(`{"sessionId":%q,"messages":[]}`, id))
+
+	for _, format := range []Format{FormatClaude, FormatCodex, FormatGemini} {
+		if !dirs.SessionExists(format, cwd, id) {
+			t.Errorf("%s: session not found", format)
+		}
+		if dirs.SessionExists(format, cwd, "0199a1b2-0000-0000-0000-000000000000") {
+			t.Errorf("%s: unknown session reported as existing", format)
+		}
+	}
+	if dirs.SessionExists(FormatClaude, "/other", id) {
+		t.Error("claude session found for a different cwd")
+	}
+}
+
+func TestSessionIDFromOutput(t *testing.T) {
+	out := "Token usage: total=1200\nTo continue this session, run codex resume 0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b\n$ "
+	if got := SessionIDFromOutput("cod", out); got != "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b" {
+		t.Errorf("codex id = %q", got)
+	}
+	if got := SessionIDFromOutput("cc", out); got != "" {
+		t.Errorf("claude id from codex output = %q, want empty", got)
+	}
+}
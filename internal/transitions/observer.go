@@ -0,0 +1,133 @@
+// Package transitions keeps the persistent agent state-transition log
+// current. An Observer polls every tmux session with the unified status
+// detector, records each pane state change, and prunes old entries.
+package transitions
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+const (
+	// DefaultPollInterval is how often Run detects pane states.
+	DefaultPollInterval = 5 * time.Second
+
+	// DefaultRetention is how long recorded transitions are kept.
+	DefaultRetention = 30 * 24 * time.Hour
+
+	// PruneInterval is how often Run deletes transitions past the retention.
+	PruneInterval = time.Hour
+)
+
+// DetectFunc returns the current status of every pane in a session.
+type DetectFunc func(ctx context.Context, session string) ([]status.AgentStatus, error)
+
+// Observer records pane state changes to the transition log. Callers that
+// already poll pane status (the dashboard, ntm activity, the API) pass their
+// readings to Record; ntm serve also calls Run so the log stays current when
+// nothing else is polling.
+type Observer struct {
+	store     *state.Store
+	recorder  *state.TransitionRecorder
+	detect    DetectFunc
+	sessions  func() ([]string, error)
+	interval  time.Duration
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewObserver creates an observer backed by store.
+func NewObserver(store *state.Store) *Observer {
+	return &Observer{
+		store:     store,
+		recorder:  state.NewTransitionRecorder(store),
+		detect:    status.NewDetector().DetectAllContext,
+		sessions:  sessionNames,
+		interval:  DefaultPollInterval,
+		retention: DefaultRetention,
+		now:       time.Now,
+	}
+}
+
+// Record persists the state changes in statuses, one reading of a session.
+// Best-effort: write errors are dropped. Safe on a nil Observer.
+func (o *Observer) Record(session string, statuses []status.AgentStatus) {
+	if o == nil || session == "" {
+		return
+	}
+	for _, st := range statuses {
+		_, _ = o.recorder.Observe(state.StateObservation{
+			SessionID:  session,
+			PaneID:     st.PaneID,
+			PaneName:   st.PaneName,
+			AgentType:  st.AgentType,
+			State:      string(st.State),
+			Source:     state.TransitionSourceStatus,
+			Trigger:    st.Trigger,
+			Confidence: st.Confidence,
+			At:         st.UpdatedAt,
+		})
+	}
+}
+
+// Run observes every session and prunes the log until ctx is cancelled.
+func (o *Observer) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	var lastPrune time.Time
+	for {
+		if now := o.now(); now.Sub(lastPrune) >= PruneInterval {
+			o.Prune()
+			lastPrune = now
+		}
+		o.Tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick detects pane states in every tmux session and records the changes.
+func (o *Observer) Tick(ctx context.Context) {
+	sessions, err := o.sessions()
+	if err != nil {
+		return // No tmux server; nothing to observe
+	}
+	for _, session := range sessions {
+		if ctx.Err() != nil {
+			return
+		}
+		statuses, err := o.detect(ctx, session)
+		if err != nil {
+			continue
+		}
+		o.Record(session, statuses)
+	}
+}
+
+// Prune deletes transitions older than the retention period.
+func (o *Observer) Prune() {
+	if _, err := o.store.PruneStateTransitions(o.now().Add(-o.retention)); err != nil {
+		log.Printf("transitions: %v", err)
+	}
+}
+
+func sessionNames() ([]string, error) {
+	sessions, err := tmux.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
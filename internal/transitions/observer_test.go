@@ -0,0 +1,77 @@
+package transitions
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+)
+
+func testStore(t *testing.T) *state.Store {
+	t.Helper()
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return store
+}
+
+func TestTickRecordsStateChangesInEverySession(t *testing.T) {
+	store := testStore(t)
+	o := NewObserver(store)
+	o.sessions = func() ([]string, error) { return []string{"proj", "gone"}, nil }
+
+	states := map[string]status.AgentState{"%1": status.StateIdle}
+	o.detect = func(_ context.Context, session string) ([]status.AgentStatus, error) {
+		if session == "gone" {
+			return nil, errors.New("session not found")
+		}
+		return []status.AgentStatus{{PaneID: "%1", State: states["%1"], Trigger: "prompt", UpdatedAt: time.Now()}}, nil
+	}
+
+	o.Tick(context.Background())
+	o.Tick(context.Background())
+	states["%1"] = status.StateWorking
+	o.Tick(context.Background())
+
+	got, err := store.ListStateTransitions(state.TransitionQuery{SessionID: "proj"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ToState != "idle" || got[1].FromState != "idle" || got[1].ToState != "working" {
+		t.Fatalf("transitions = %+v", got)
+	}
+	if got[0].Source != state.TransitionSourceStatus || got[0].Trigger != "prompt" {
+		t.Errorf("first transition = %+v", got[0])
+	}
+}
+
+func TestPruneDropsTransitionsPastRetention(t *testing.T) {
+	store := testStore(t)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{now.Add(-DefaultRetention - time.Hour), now.Add(-time.Hour)} {
+		if err := store.RecordStateTransition(&state.AgentStateTransition{SessionID: "proj", PaneID: "%1", ToState: "idle", TransitionAt: at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o := NewObserver(store)
+	o.now = func() time.Time { return now }
+	o.Prune()
+
+	got, err := store.ListStateTransitions(state.TransitionQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !got[0].TransitionAt.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("transitions after prune = %+v", got)
+	}
+}
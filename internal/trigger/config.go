@@ -0,0 +1,258 @@
+// Package trigger handles inbound webhooks that start pipelines or send
+// prompts. Triggers are named and configured per project in
+// .ntm/triggers.toml; the HTTP endpoint lives in internal/serve.
+package trigger
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/Dicklesworthstone/ntm/internal/pipeline"
+)
+
+// Defaults applied to triggers that leave the setting empty.
+const (
+	DefaultSignatureHeader = "X-Hub-Signature-256"
+	DefaultDeliveryHeader  = "X-Delivery-ID"
+	DefaultRateLimit       = 60 // Deliveries per minute
+)
+
+// Action names recorded with each delivery.
+const (
+	ActionPipeline = "pipeline"
+	ActionSend     = "send"
+)
+
+// ConfigFile is the trigger file's path relative to the project directory.
+var ConfigFile = filepath.Join(".ntm", "triggers.toml")
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Config is the contents of .ntm/triggers.toml.
+type Config struct {
+	Triggers []Trigger `toml:"trigger"`
+}
+
+// Trigger maps an inbound webhook to a pipeline run or a send.
+type Trigger struct {
+	Name        string `toml:"name"`
+	Description string `toml:"description"`
+
+	// Secret is the shared HMAC-SHA256 key. SecretEnv names an environment
+	// variable holding it instead, and wins when set.
+	Secret    string `toml:"secret"`
+	SecretEnv string `toml:"secret_env"`
+
+	// SignatureHeader carries the hex HMAC of the raw body, optionally
+	// prefixed with "sha256=" (default X-Hub-Signature-256).
+	SignatureHeader string `toml:"signature_header"`
+
+	// DeliveryHeader carries the sender's unique delivery ID (default
+	// X-Delivery-ID). DeliveryPath reads it from the payload instead.
+	DeliveryHeader string `toml:"delivery_header"`
+	DeliveryPath   string `toml:"delivery_path"`
+
+	// RateLimit caps accepted deliveries per minute. Zero uses the default;
+	// a negative value disables the limit.
+	RateLimit int `toml:"rate_limit"`
+
+	// Session is the tmux session the action targets.
+	Session string `toml:"session"`
+
+	// Match lists payload expressions that must equal the given values for
+	// the delivery to run; other deliveries are acknowledged and ignored.
+	Match map[string]string `toml:"match"`
+
+	// Vars maps variable names to payload expressions such as
+	// "$.pull_request.head.ref".
+	Vars map[string]string `toml:"vars"`
+
+	Pipeline *PipelineAction `toml:"pipeline"`
+	Send     *SendAction     `toml:"send"`
+}
+
+// PipelineAction starts a pipeline run with the mapped vars.
+type PipelineAction struct {
+	Workflow string `toml:"workflow"` // Workflow file, name, or builtin:<name>
+}
+
+// SendAction sends a prompt to the session. The message may reference mapped
+// vars as ${vars.name}.
+type SendAction struct {
+	Message    string   `toml:"message"`
+	Panes      []string `toml:"panes"`
+	AgentTypes []string `toml:"agent_types"`
+	All        bool     `toml:"all"`
+}
+
+// Render substitutes mapped vars into the message. References to vars the
+// payload did not provide are an error unless they carry a default, as in
+// ${vars.branch | "main"}.
+func (a *SendAction) Render(session string, vars map[string]interface{}) (string, error) {
+	state := &pipeline.ExecutionState{Variables: vars}
+	return pipeline.NewSubstitutor(state, session, "").Substitute(a.Message)
+}
+
+// Path returns the trigger file path for projectDir.
+func Path(projectDir string) string {
+	return filepath.Join(projectDir, ConfigFile)
+}
+
+// Load reads and validates the project's trigger file. A missing file yields
+// an empty config.
+func Load(projectDir string) (*Config, error) {
+	data, err := os.ReadFile(Path(projectDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading triggers config: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates trigger TOML.
+func Parse(data []byte) (*Config, error) {
+	var cfg Config
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing triggers config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid triggers config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks every trigger and rejects duplicate names.
+func (c *Config) Validate() error {
+	seen := make(map[string]bool, len(c.Triggers))
+	for i := range c.Triggers {
+		t := &c.Triggers[i]
+		if err := t.Validate(); err != nil {
+			return err
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("duplicate trigger %q", t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return nil
+}
+
+// Get returns the named trigger, or nil.
+func (c *Config) Get(name string) *Trigger {
+	for i := range c.Triggers {
+		if c.Triggers[i].Name == name {
+			return &c.Triggers[i]
+		}
+	}
+	return nil
+}
+
+// Validate checks a trigger's settings. Secrets held in environment
+// variables are checked when a delivery arrives, not here.
+func (t *Trigger) Validate() error {
+	if !namePattern.MatchString(t.Name) {
+		return fmt.Errorf("trigger name %q must be letters, digits, '.', '_' or '-'", t.Name)
+	}
+	if t.Secret == "" && t.SecretEnv == "" {
+		return fmt.Errorf("trigger %q: secret or secret_env is required", t.Name)
+	}
+	if (t.Pipeline == nil) == (t.Send == nil) {
+		return fmt.Errorf("trigger %q: exactly one of [trigger.pipeline] or [trigger.send] is required", t.Name)
+	}
+	if strings.TrimSpace(t.Session) == "" {
+		return fmt.Errorf("trigger %q: session is required", t.Name)
+	}
+	if t.Pipeline != nil && strings.TrimSpace(t.Pipeline.Workflow) == "" {
+		return fmt.Errorf("trigger %q: pipeline.workflow is required", t.Name)
+	}
+	if t.Send != nil && strings.TrimSpace(t.Send.Message) == "" {
+		return fmt.Errorf("trigger %q: send.message is required", t.Name)
+	}
+	if t.DeliveryPath != "" {
+		if _, err := parsePath(t.DeliveryPath); err != nil {
+			return fmt.Errorf("trigger %q: delivery_path: %w", t.Name, err)
+		}
+	}
+	for expr := range t.Match {
+		if _, err := parsePath(expr); err != nil {
+			return fmt.Errorf("trigger %q: match: %w", t.Name, err)
+		}
+	}
+	for name, expr := range t.Vars {
+		if name == "" {
+			return fmt.Errorf("trigger %q: empty var name", t.Name)
+		}
+		if _, err := parsePath(expr); err != nil {
+			return fmt.Errorf("trigger %q: var %s: %w", t.Name, name, err)
+		}
+	}
+	return nil
+}
+
+// Action returns the trigger's action name.
+func (t *Trigger) Action() string {
+	if t.Pipeline != nil {
+		return ActionPipeline
+	}
+	return ActionSend
+}
+
+// ResolvedSecret returns the HMAC key, preferring SecretEnv.
+func (t *Trigger) ResolvedSecret() string {
+	if t.SecretEnv != "" {
+		if v := os.Getenv(t.SecretEnv); v != "" {
+			return v
+		}
+	}
+	return t.Secret
+}
+
+// ResolvedSignatureHeader returns the signature header name.
+func (t *Trigger) ResolvedSignatureHeader() string {
+	if t.SignatureHeader != "" {
+		return t.SignatureHeader
+	}
+	return DefaultSignatureHeader
+}
+
+// ResolvedDeliveryHeader returns the delivery ID header name.
+func (t *Trigger) ResolvedDeliveryHeader() string {
+	if t.DeliveryHeader != "" {
+		return t.DeliveryHeader
+	}
+	return DefaultDeliveryHeader
+}
+
+// DeliveryID returns the delivery's unique ID from DeliveryPath when set,
+// otherwise from the delivery header. It is empty when the sender gave none.
+func (t *Trigger) DeliveryID(h http.Header, p *Payload) (string, error) {
+	if t.DeliveryPath != "" {
+		v, ok, err := p.Lookup(t.DeliveryPath)
+		if err != nil || !ok {
+			return "", err
+		}
+		return Text(v), nil
+	}
+	return strings.TrimSpace(h.Get(t.ResolvedDeliveryHeader())), nil
+}
+
+// ResolvedRateLimit returns deliveries allowed per minute; 0 means unlimited.
+func (t *Trigger) ResolvedRateLimit() int {
+	switch {
+	case t.RateLimit < 0:
+		return 0
+	case t.RateLimit == 0:
+		return DefaultRateLimit
+	default:
+		return t.RateLimit
+	}
+}
@@ -0,0 +1,52 @@
+package trigger
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter enforces per-trigger delivery limits over a sliding one-minute
+// window. It is safe for concurrent use.
+type Limiter struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	window time.Duration
+	seen   map[string][]time.Time
+}
+
+// NewLimiter creates a limiter with a one-minute window.
+func NewLimiter() *Limiter {
+	return &Limiter{
+		now:    time.Now,
+		window: time.Minute,
+		seen:   make(map[string][]time.Time),
+	}
+}
+
+// Allow records a delivery for name and reports whether it is within limit
+// deliveries per window. When it is not, retryAfter is the time until the
+// oldest delivery in the window expires. A limit of zero always allows.
+func (l *Limiter) Allow(name string, limit int) (ok bool, retryAfter time.Duration) {
+	if limit <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	cutoff := now.Add(-l.window)
+	times := l.seen[name]
+	keep := 0
+	for keep < len(times) && !times[keep].After(cutoff) {
+		keep++
+	}
+	times = times[keep:]
+
+	if len(times) >= limit {
+		l.seen[name] = times
+		return false, times[0].Add(l.window).Sub(now)
+	}
+	l.seen[name] = append(times, now)
+	return true, 0
+}
@@ -0,0 +1,234 @@
+package trigger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Verification errors.
+var (
+	ErrMissingSignature = errors.New("missing signature")
+	ErrBadSignature     = errors.New("signature mismatch")
+	ErrNoSecret         = errors.New("trigger secret is not set")
+)
+
+// Sign returns the "sha256=<hex>" signature of body, as senders compute it.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks header, the hex HMAC-SHA256 of body with or without
+// a "sha256=" prefix, against secret in constant time.
+func VerifySignature(secret, header string, body []byte) error {
+	if secret == "" {
+		return ErrNoSecret
+	}
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return ErrMissingSignature
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(header, "sha256="))
+	if err != nil {
+		return ErrBadSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// Payload is a decoded JSON webhook body.
+type Payload struct {
+	doc interface{}
+}
+
+// ParsePayload decodes a JSON body. Numbers keep their literal form.
+func ParsePayload(body []byte) (*Payload, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON payload: %w", err)
+	}
+	return &Payload{doc: doc}, nil
+}
+
+// Lookup evaluates a path expression against the payload. Expressions start
+// at $ and use .field, ['field'] and [index] steps, e.g.
+// "$.commits[0].author.name". It reports false when the path is absent.
+func (p *Payload) Lookup(expr string) (interface{}, bool, error) {
+	steps, err := parsePath(expr)
+	if err != nil {
+		return nil, false, err
+	}
+	cur := p.doc
+	for _, st := range steps {
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			if st.isIndex {
+				return nil, false, nil
+			}
+			v, ok := node[st.key]
+			if !ok {
+				return nil, false, nil
+			}
+			cur = v
+		case []interface{}:
+			if !st.isIndex {
+				return nil, false, nil
+			}
+			i := st.index
+			if i < 0 {
+				i += len(node)
+			}
+			if i < 0 || i >= len(node) {
+				return nil, false, nil
+			}
+			cur = node[i]
+		default:
+			return nil, false, nil
+		}
+	}
+	return plain(cur), true, nil
+}
+
+// Matches reports whether every match expression resolves to its expected
+// value, compared as text.
+func (p *Payload) Matches(match map[string]string) (bool, error) {
+	for expr, want := range match {
+		v, ok, err := p.Lookup(expr)
+		if err != nil {
+			return false, err
+		}
+		if !ok || Text(v) != want {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Vars evaluates each var expression. Vars whose path is absent are left
+// out, so workflow defaults still apply.
+func (p *Payload) Vars(exprs map[string]string) (map[string]interface{}, error) {
+	vars := make(map[string]interface{}, len(exprs))
+	for name, expr := range exprs {
+		v, ok, err := p.Lookup(expr)
+		if err != nil {
+			return nil, fmt.Errorf("var %s: %w", name, err)
+		}
+		if ok {
+			vars[name] = v
+		}
+	}
+	return vars, nil
+}
+
+// Text renders a payload value as text: strings as-is, null as empty, and
+// objects and arrays as JSON.
+func Text(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// plain converts json.Number values to int64 or float64 so vars behave like
+// values decoded from a workflow file.
+func plain(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			out[k] = plain(e)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = plain(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+type pathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parsePath splits "$.a['b c'][0]" into steps.
+func parsePath(expr string) ([]pathStep, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(expr), "$")
+	if !ok {
+		return nil, fmt.Errorf("path %q must start with $", expr)
+	}
+	var steps []pathStep
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("path %q has an empty field name", expr)
+			}
+			steps = append(steps, pathStep{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q has an unclosed [", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, pathStep{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			i, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %q is not an index or quoted field", expr, inner)
+			}
+			steps = append(steps, pathStep{index: i, isIndex: true})
+		default:
+			return nil, fmt.Errorf("path %q: unexpected %q", expr, rest[:1])
+		}
+	}
+	return steps, nil
+}
@@ -0,0 +1,214 @@
+package trigger
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseValidates(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		want string
+	}{
+		{"no secret", `[[trigger]]
+name = "a"
+session = "s"
+[trigger.send]
+message = "x"`, "secret"},
+		{"no action", `[[trigger]]
+name = "a"
+secret = "k"
+session = "s"`, "exactly one"},
+		{"bad name", `[[trigger]]
+name = "a/b"
+secret = "k"
+session = "s"
+[trigger.send]
+message = "x"`, "trigger name"},
+		{"bad path", `[[trigger]]
+name = "a"
+secret = "k"
+session = "s"
+[trigger.vars]
+x = "ref"
+[trigger.send]
+message = "x"`, "must start with $"},
+		{"duplicate", `[[trigger]]
+name = "a"
+secret = "k"
+session = "s"
+[trigger.send]
+message = "x"
+[[trigger]]
+name = "a"
+secret = "k"
+session = "s"
+[trigger.send]
+message = "y"`, "duplicate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.toml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	cfg, err := Parse([]byte(`[[trigger]]
+name = "gh"
+secret = "k"
+session = "s"
+[trigger.pipeline]
+workflow = "review"`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	tr := cfg.Get("gh")
+	if tr == nil || tr.Action() != ActionPipeline || tr.ResolvedRateLimit() != DefaultRateLimit ||
+		tr.ResolvedSignatureHeader() != DefaultSignatureHeader {
+		t.Errorf("trigger = %+v", tr)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(t.TempDir())
+	if err != nil || len(cfg.Triggers) != 0 {
+		t.Fatalf("Load = %+v, %v", cfg, err)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"a":1}`)
+	sig := Sign("k", body)
+	if err := VerifySignature("k", sig, body); err != nil {
+		t.Errorf("prefixed signature: %v", err)
+	}
+	if err := VerifySignature("k", strings.TrimPrefix(sig, "sha256="), body); err != nil {
+		t.Errorf("bare signature: %v", err)
+	}
+	if err := VerifySignature("k", sig, []byte(`{"a":2}`)); err != ErrBadSignature {
+		t.Errorf("tampered body: %v", err)
+	}
+	if err := VerifySignature("k", "sha256=zz", body); err != ErrBadSignature {
+		t.Errorf("non-hex signature: %v", err)
+	}
+	if err := VerifySignature("k", "", body); err != ErrMissingSignature {
+		t.Errorf("missing signature: %v", err)
+	}
+	if err := VerifySignature("", sig, body); err != ErrNoSecret {
+		t.Errorf("no secret: %v", err)
+	}
+}
+
+func TestPayloadLookup(t *testing.T) {
+	p, err := ParsePayload([]byte(`{
+		"ref": "refs/heads/main",
+		"commits": [{"id": "abc", "author": {"name": "Ada"}}, {"id": "def"}],
+		"repository": {"full name": "org/repo", "stars": 12, "ratio": 0.5, "private": false},
+		"labels": ["bug", "p1"]
+	}`))
+	if err != nil {
+		t.Fatalf("ParsePayload: %v", err)
+	}
+
+	tests := []struct {
+		expr string
+		want string
+		ok   bool
+	}{
+		{"$.ref", "refs/heads/main", true},
+		{"$.commits[0].author.name", "Ada", true},
+		{"$.commits[-1].id", "def", true},
+		{"$.repository['full name']", "org/repo", true},
+		{`$["repository"].stars`, "12", true},
+		{"$.repository.ratio", "0.5", true},
+		{"$.repository.private", "false", true},
+		{"$.labels", `["bug","p1"]`, true},
+		{"$.commits[5].id", "", false},
+		{"$.ref.nested", "", false},
+		{"$.missing", "", false},
+	}
+	for _, tt := range tests {
+		v, ok, err := p.Lookup(tt.expr)
+		if err != nil {
+			t.Errorf("Lookup(%s): %v", tt.expr, err)
+			continue
+		}
+		if ok != tt.ok || (ok && Text(v) != tt.want) {
+			t.Errorf("Lookup(%s) = %v, %v; want %q, %v", tt.expr, v, ok, tt.want, tt.ok)
+		}
+	}
+
+	if _, _, err := p.Lookup("$.commits[x]"); err == nil {
+		t.Error("expected error for bad index")
+	}
+
+	ok, err := p.Matches(map[string]string{"$.ref": "refs/heads/main", "$.repository.stars": "12"})
+	if err != nil || !ok {
+		t.Errorf("Matches = %v, %v, want true", ok, err)
+	}
+	if ok, _ := p.Matches(map[string]string{"$.ref": "refs/heads/dev"}); ok {
+		t.Error("Matches should fail on a different value")
+	}
+
+	vars, err := p.Vars(map[string]string{"sha": "$.commits[0].id", "gone": "$.nope"})
+	if err != nil || vars["sha"] != "abc" {
+		t.Fatalf("Vars = %v, %v", vars, err)
+	}
+	if _, present := vars["gone"]; present {
+		t.Error("absent path should leave the var unset")
+	}
+}
+
+func TestDeliveryIDAndRender(t *testing.T) {
+	p, _ := ParsePayload([]byte(`{"id": 7, "title": "disk full"}`))
+	h := http.Header{}
+	h.Set(DefaultDeliveryHeader, "hdr-1")
+
+	tr := &Trigger{}
+	if id, _ := tr.DeliveryID(h, p); id != "hdr-1" {
+		t.Errorf("header delivery ID = %q", id)
+	}
+	tr.DeliveryPath = "$.id"
+	if id, _ := tr.DeliveryID(h, p); id != "7" {
+		t.Errorf("payload delivery ID = %q", id)
+	}
+
+	send := &SendAction{Message: `alert: ${vars.title} (${vars.host | "unknown"})`}
+	vars, _ := p.Vars(map[string]string{"title": "$.title"})
+	msg, err := send.Render("proj", vars)
+	if err != nil || msg != "alert: disk full (unknown)" {
+		t.Errorf("Render = %q, %v", msg, err)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter()
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("a", 2); !ok {
+			t.Fatalf("delivery %d rejected", i)
+		}
+	}
+	ok, retry := l.Allow("a", 2)
+	if ok || retry != time.Minute {
+		t.Errorf("third delivery = %v, retry %v", ok, retry)
+	}
+	if ok, _ := l.Allow("b", 2); !ok {
+		t.Error("limits should be per trigger")
+	}
+	if ok, _ := l.Allow("a", 0); !ok {
+		t.Error("zero limit should always allow")
+	}
+
+	now = now.Add(61 * time.Second)
+	if ok, _ := l.Allow("a", 2); !ok {
+		t.Error("window should have expired")
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/history"
 	"github.com/Dicklesworthstone/ntm/internal/integrations/pt"
 	"github.com/Dicklesworthstone/ntm/internal/integrations/rano"
+	"github.com/Dicklesworthstone/ntm/internal/queue"
 	"github.com/Dicklesworthstone/ntm/internal/robot"
 	"github.com/Dicklesworthstone/ntm/internal/scanner"
 	sessionPkg "github.com/Dicklesworthstone/ntm/internal/session"
@@ -42,6 +43,8 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/tokens"
 	"github.com/Dicklesworthstone/ntm/internal/tools"
 	"github.com/Dicklesworthstone/ntm/internal/tracker"
+	"github.com/Dicklesworthstone/ntm/internal/transcript"
+	"github.com/Dicklesworthstone/ntm/internal/transitions"
 	"github.com/Dicklesworthstone/ntm/internal/tui/components"
 	"github.com/Dicklesworthstone/ntm/internal/tui/dashboard/panels"
 	"github.com/Dicklesworthstone/ntm/internal/tui/icons"
@@ -70,11 +73,12 @@ type RefreshMsg struct{}
 
 // StatusUpdateMsg is sent when status detection completes
 type StatusUpdateMsg struct {
-	Statuses []status.AgentStatus
-	Time     time.Time
-	Duration time.Duration
-	Err      error
-	Gen      uint64
+	Statuses    []status.AgentStatus
+	QueueDepths map[string]int // Pending queued prompts by pane ID
+	Time        time.Time
+	Duration    time.Duration
+	Err         error
+	Gen         uint64
 }
 
 // ConfigReloadMsg is sent when configuration changes
@@ -158,6 +162,12 @@ type SpawnUpdateMsg struct {
 	Gen  uint64
 }
 
+// CostTranscriptsMsg is sent when agent transcripts have been read for
+// exact token usage
+type CostTranscriptsMsg struct {
+	Exact map[string]transcript.Snapshot // paneID -> usage, panes with turns only
+}
+
 // MetricsUpdateMsg is sent when session metrics are updated
 type MetricsUpdateMsg struct {
 	Data panels.MetricsData
@@ -366,6 +376,9 @@ type Model struct {
 
 	// Live status detection
 	detector      *status.UnifiedDetector
+	stateStore    *state.Store                  // Opened by Run; nil in tests or when unavailable
+	transitions   *transitions.Observer         // Records state changes from each status fetch
+	promptQueue   *queue.Dispatcher             // Delivers queued prompts as panes go idle
 	agentStatuses map[string]status.AgentStatus // keyed by pane ID
 	lastRefresh   time.Time
 	refreshPaused bool
@@ -515,7 +528,8 @@ type Model struct {
 	cassContext   []cass.SearchHit
 	routingScores map[string]RoutingScore // keyed by pane ID
 
-	// Cost tracking (estimated; derived from prompt history + pane output deltas)
+	// Cost tracking (exact from agent transcripts where found; otherwise estimated
+	// from prompt history + pane output deltas)
 	costInputTokens         map[string]int     // paneID -> estimated input tokens
 	costOutputTokens        map[string]int     // paneID -> estimated output tokens
 	costModels              map[string]string  // paneID -> model name (for pricing)
@@ -524,6 +538,9 @@ type Model struct {
 	costLastPromptRead      time.Time          // last time we attempted to read prompt history
 	costSnapshots           []costSnapshot     // rolling window for last-hour computations
 	costDailyBudgetUSD      float64            // 0 disables budget display
+	costTranscripts         *transcript.Ingester
+	costTranscriptsSyncing  bool                           // a transcript sync is in flight
+	costExact               map[string]transcript.Snapshot // paneID -> exact usage from the agent's transcript
 
 	// Process triage health states (from pt.HealthMonitor)
 	healthStates map[string]*pt.AgentState // pane -> health state
@@ -601,7 +618,7 @@ type Model struct {
 type PaneStatus struct {
 	LastCompaction *time.Time // When compaction was last detected
 	RecoverySent   bool       // Whether recovery prompt was sent
-	State          string     // "working", "idle", "error", "compacted"
+	State          string     // "working", "idle", "error", "compacted", "awaiting_approval"
 
 	// Context usage tracking
 	ContextTokens  int     // Estimated tokens used
@@ -613,6 +630,8 @@ type PaneStatus struct {
 	MailUnread int
 	MailUrgent int
 
+	QueuedPrompts int // Prompts waiting for this pane to go idle
+
 	TokenVelocity float64 // Estimated tokens/sec
 
 	// Local agent performance (Ollama) - best-effort estimates.
@@ -766,6 +785,8 @@ func New(session, projectDir string) Model {
 		costOutputTokens:           make(map[string]int),
 		costModels:                 make(map[string]string),
 		costLastCosts:              make(map[string]float64),
+		costTranscripts:            transcript.NewIngester(nil, nil),
+		costExact:                  make(map[string]transcript.Snapshot),
 		refreshInterval:            DefaultRefreshInterval,
 		paneRefreshInterval:        PaneRefreshInterval,
 		contextRefreshInterval:     ContextRefreshInterval,
@@ -2194,8 +2215,30 @@ func (m *Model) fetchStatuses() tea.Cmd {
 			// Keep UI responsive even if detection fails
 			return StatusUpdateMsg{Statuses: nil, Time: time.Now(), Duration: duration, Err: err, Gen: gen}
 		}
-		return StatusUpdateMsg{Statuses: statuses, Time: time.Now(), Duration: duration, Gen: gen}
+		m.transitions.Record(m.session, statuses)
+		depths := m.dispatchQueuedPrompts(statuses)
+		return StatusUpdateMsg{Statuses: statuses, QueueDepths: depths, Time: time.Now(), Duration: duration, Gen: gen}
+	}
+}
+
+// attachStore wires the state store used by the transition log and the
+// prompt queue. The caller owns the store and closes it.
+func (m *Model) attachStore(store *state.Store) {
+	m.stateStore = store
+	m.transitions = transitions.NewObserver(store)
+	m.promptQueue = queue.NewDispatcher(store)
+}
+
+// dispatchQueuedPrompts delivers queued prompts to panes that just reported
+// idle and returns the remaining queue depth per pane. Best-effort; runs on
+// the fetch goroutine.
+func (m *Model) dispatchQueuedPrompts(statuses []status.AgentStatus) map[string]int {
+	if m.session == "" || m.promptQueue == nil {
+		return nil
 	}
+	_, _ = m.promptQueue.Dispatch(m.session, statuses)
+	depths, _ := m.stateStore.QueueDepths(m.session)
+	return depths
 }
 
 // fetchHealthCmd fetches health status for all agents in the session
@@ -2753,6 +2796,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					} else {
 						modelName = "gemini-2.0-flash"
 					}
+				default:
+					// Plugin agents register their context window under their own name
+					if _, ok := tokens.PluginContextLimit(data.AgentType); ok {
+						modelName = data.AgentType
+					}
 				}
 
 				// Use variant if available
@@ -2814,13 +2862,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				// Update LIVE STATUS using local analysis (avoid waiting for slow full fetch)
-				st := m.detector.Analyze(data.PaneID, currentPane.Title, statusAgentType, data.Output, data.LastActivity)
+				st := m.detector.Analyze(m.session, data.PaneID, currentPane.Title, statusAgentType, data.Output, data.LastActivity)
 
 				state := string(st.State)
 				// Rate limit check
 				if st.State == status.StateError && st.ErrorType == status.ErrorRateLimit {
 					state = "rate_limited"
-				} else if ps.LastCompaction != nil && state != string(status.StateError) {
+				} else if ps.LastCompaction != nil && st.State != status.StateError && st.State != status.StateAwaitingApproval {
 					state = "compacted"
 				}
 				ps.State = state
@@ -2857,11 +2905,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Refresh cost panel from prompt history + accumulated output deltas.
 			now := time.Now()
-			m.updateCostFromPrompts(now)
+			followUp = tea.Batch(followUp, m.updateCostFromPrompts(now))
 			m.refreshCostPanel(now)
 		}
 		return m, followUp
 
+	case CostTranscriptsMsg:
+		m.costTranscriptsSyncing = false
+		m.costExact = msg.Exact
+		m.refreshCostPanel(time.Now())
+		return m, nil
+
 	case StatusUpdateMsg:
 		if !m.acceptUpdate(refreshStatus, msg.Gen) {
 			return m, nil
@@ -2902,11 +2956,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Rate limit should be shown with special indicator
 			if st.State == status.StateError && st.ErrorType == status.ErrorRateLimit {
 				state = "rate_limited"
-			} else if ps.LastCompaction != nil && state != string(status.StateError) {
+			} else if ps.LastCompaction != nil && st.State != status.StateError && st.State != status.StateAwaitingApproval {
 				// Compaction warning should override idle/working but not errors
 				state = "compacted"
 			}
 			ps.State = state
+			ps.QueuedPrompts = msg.QueueDepths[st.PaneID]
 
 			// Pre-calculate token velocity
 			ps.TokenVelocity = tokenVelocityFromStatus(st)
@@ -4740,6 +4795,9 @@ func (m Model) renderPaneGrid() string {
 		case "rate_limited":
 			statusIcon = "⏳"
 			statusColor = t.Maroon
+		case "awaiting_approval":
+			statusIcon = "⏸"
+			statusColor = t.Mauve
 		}
 		statusStyled := lipgloss.NewStyle().Foreground(statusColor).Bold(true).Render(statusIcon)
 
@@ -4833,6 +4891,16 @@ func (m Model) renderPaneGrid() string {
 			cardContent.WriteString(mailBadge + "\n")
 		}
 
+		// Queued prompt badge
+		if ps, ok := m.paneStatus[p.Index]; ok && ps.QueuedPrompts > 0 {
+			queueBadge := styles.TextBadge(fmt.Sprintf("⏳ %d queued", ps.QueuedPrompts), t.Peach, t.Base, styles.BadgeOptions{
+				Style:    styles.BadgeStyleCompact,
+				Bold:     false,
+				ShowIcon: false,
+			})
+			cardContent.WriteString(queueBadge + "\n")
+		}
+
 		// Health badges - show warning/error status and restart count
 		if ps, ok := m.paneStatus[p.Index]; ok {
 			// Health status badge
@@ -5244,14 +5312,17 @@ func (m *Model) recordCostOutputDelta(paneID, modelName, prevOutput, currentOutp
 	}
 }
 
-func (m *Model) updateCostFromPrompts(now time.Time) {
+// updateCostFromPrompts adds newly sent prompts to the input token estimates
+// and returns a command that refreshes exact usage from agent transcripts.
+func (m *Model) updateCostFromPrompts(now time.Time) tea.Cmd {
 	if m.session == "" {
-		return
+		return nil
 	}
 	if !m.costLastPromptRead.IsZero() && now.Sub(m.costLastPromptRead) < CostPromptRefreshInterval {
-		return
+		return nil
 	}
 	m.costLastPromptRead = now
+	syncCmd := m.syncCostTranscripts()
 
 	if m.costInputTokens == nil {
 		m.costInputTokens = make(map[string]int)
@@ -5260,7 +5331,7 @@ func (m *Model) updateCostFromPrompts(now time.Time) {
 	history, err := sessionPkg.LoadPromptHistory(m.session)
 	if err != nil {
 		m.costError = err
-		return
+		return syncCmd
 	}
 
 	// Index panes by index for history targets.
@@ -5331,6 +5402,30 @@ func (m *Model) updateCostFromPrompts(now time.Time) {
 		m.costLastPromptTimestamp = maxTs
 	}
 	m.costError = nil
+	return syncCmd
+}
+
+// syncCostTranscripts reads exact token usage from the agents' own session
+// logs off the UI loop. Panes without a transcript keep the estimates.
+func (m *Model) syncCostTranscripts() tea.Cmd {
+	if m.costTranscripts == nil || m.costTranscriptsSyncing {
+		return nil
+	}
+	m.costTranscriptsSyncing = true
+	ingester, session := m.costTranscripts, m.session
+	panes := make([]transcript.Pane, 0, len(m.panes))
+	for _, p := range m.panes {
+		panes = append(panes, transcript.Pane{ID: p.ID, Name: p.Title, AgentType: string(p.Type), PID: p.PID})
+	}
+	return func() tea.Msg {
+		exact := make(map[string]transcript.Snapshot)
+		for _, r := range ingester.Sync(session, panes) {
+			if r.Snapshot.Turns > 0 {
+				exact[r.PaneID] = r.Snapshot
+			}
+		}
+		return CostTranscriptsMsg{Exact: exact}
+	}
 }
 
 func (m *Model) resolveCostModelForPane(pane tmux.Pane) string {
@@ -5397,6 +5492,22 @@ func (m *Model) refreshCostPanel(now time.Time) {
 
 		pricing := cost.GetModelPricing(modelName)
 		costUSD := (float64(inputTokens)/1000.0)*pricing.InputPer1K + (float64(outputTokens)/1000.0)*pricing.OutputPer1K
+		if snap, ok := m.costExact[p.ID]; ok {
+			if snap.Model != "" {
+				modelName = snap.Model
+			}
+			exact := cost.AgentCost{
+				InputTokens:      int(snap.Usage.InputTokens),
+				OutputTokens:     int(snap.Usage.OutputTokens),
+				CacheReadTokens:  int(snap.Usage.CacheReadTokens),
+				CacheWriteTokens: int(snap.Usage.CacheWriteTokens),
+				Model:            modelName,
+				Source:           cost.SourceTranscript,
+			}
+			inputTokens = exact.InputTokens + exact.CacheReadTokens + exact.CacheWriteTokens
+			outputTokens = exact.OutputTokens
+			costUSD = exact.Cost()
+		}
 		total += costUSD
 
 		prevCost := m.costLastCosts[p.ID]
@@ -6392,11 +6503,22 @@ func (m Model) renderPaneDetail(width int) string {
 	case "compacted":
 		statusIcon = "⚠"
 		statusColor = t.Peach
+	case "awaiting_approval":
+		statusIcon = "⏸"
+		statusColor = t.Mauve
 	default:
 		statusIcon = "•"
 		statusColor = t.Overlay
 	}
 	lines = append(lines, "  "+lipgloss.NewStyle().Foreground(statusColor).Render(statusIcon+" "+statusText))
+	if st, ok := m.agentStatuses[p.ID]; ok && st.PendingApproval != nil {
+		prompt := st.PendingApproval
+		detail := prompt.Question
+		if prompt.Action != "" && prompt.Action != prompt.Question {
+			detail = string(prompt.Kind) + ": " + prompt.Action
+		}
+		lines = append(lines, "  "+lipgloss.NewStyle().Foreground(t.Subtext).Render(layout.TruncateWidthDefault(detail, max(width-4, 10))))
+	}
 
 	// Project Health (if warning/critical)
 	if m.healthStatus == "warning" || m.healthStatus == "critical" {
@@ -6517,6 +6639,7 @@ func activitySummaryLine(rows []PaneTableRow, t theme.Theme) string {
 	badges = append(badges, activityCountBadge("error", counts["error"], t))
 	badges = append(badges, activityCountBadge("compacted", counts["compacted"], t))
 	badges = append(badges, activityCountBadge("rate_limited", counts["rate_limited"], t))
+	badges = append(badges, activityCountBadge("awaiting_approval", counts["awaiting_approval"], t))
 	badges = append(badges, activityCountBadge("unknown", counts["unknown"], t))
 
 	var compactBadges []string
@@ -6688,6 +6811,12 @@ func (m Model) executeReplay(entry history.HistoryEntry) tea.Cmd {
 // Run starts the dashboard
 func Run(session, projectDir string) error {
 	model := New(session, projectDir)
+	if store, err := state.Open(""); err == nil {
+		defer store.Close()
+		if err := store.Migrate(); err == nil {
+			model.attachStore(store)
+		}
+	}
 	p := tea.NewProgram(model, tea.WithAltScreen())
 	_, err := p.Run()
 	return err
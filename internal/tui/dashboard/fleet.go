@@ -0,0 +1,223 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/Dicklesworthstone/ntm/internal/fleet"
+	"github.com/Dicklesworthstone/ntm/internal/tui/layout"
+	"github.com/Dicklesworthstone/ntm/internal/tui/theme"
+)
+
+// FleetUpdateMsg carries a new fleet snapshot into the fleet view.
+type FleetUpdateMsg struct {
+	Status *fleet.Status
+}
+
+type fleetKeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Refresh key.Binding
+	Quit    key.Binding
+}
+
+var fleetKeys = fleetKeyMap{
+	Up:      key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	Down:    key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	Refresh: key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+	Quit:    key.NewBinding(key.WithKeys("q", "esc", "ctrl+c"), key.WithHelp("q", "quit")),
+}
+
+// FleetModel is the dashboard's fleet mode: sessions from every `ntm serve`
+// host, grouped by host.
+type FleetModel struct {
+	fleet  *fleet.Fleet
+	status *fleet.Status
+	theme  theme.Theme
+
+	width  int
+	height int
+	offset int
+
+	refreshing bool
+}
+
+// NewFleetModel creates the fleet view over f.
+func NewFleetModel(f *fleet.Fleet) FleetModel {
+	return FleetModel{
+		fleet:  f,
+		theme:  theme.Current(),
+		width:  100,
+		height: 30,
+	}
+}
+
+// Init implements tea.Model.
+func (m FleetModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m FleetModel) refresh() tea.Cmd {
+	f := m.fleet
+	return func() tea.Msg {
+		return FleetUpdateMsg{Status: f.Poll(context.Background())}
+	}
+}
+
+// Update implements tea.Model.
+func (m FleetModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case FleetUpdateMsg:
+		m.status = msg.Status
+		m.refreshing = false
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, fleetKeys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, fleetKeys.Up):
+			if m.offset > 0 {
+				m.offset--
+			}
+		case key.Matches(msg, fleetKeys.Down):
+			m.offset++
+		case key.Matches(msg, fleetKeys.Refresh):
+			if !m.refreshing && m.fleet != nil {
+				m.refreshing = true
+				return m, m.refresh()
+			}
+		}
+	}
+	return m, nil
+}
+
+// View implements tea.Model.
+func (m FleetModel) View() string {
+	t := m.theme
+	title := lipgloss.NewStyle().Bold(true).Foreground(t.Primary)
+	dim := lipgloss.NewStyle().Foreground(t.Overlay)
+
+	if m.status == nil {
+		return title.Render("NTM Fleet") + "\n\n" + dim.Render("Polling hosts...") + "\n"
+	}
+
+	header := title.Render("NTM Fleet") + "  " + dim.Render(fleetSummaryLine(m.status))
+	if m.refreshing {
+		header += dim.Render("  refreshing…")
+	}
+
+	lines := m.bodyLines()
+	visible := m.height - 4
+	if visible < 1 {
+		visible = len(lines)
+	}
+	offset := m.offset
+	if maxOffset := len(lines) - visible; offset > maxOffset {
+		offset = max(maxOffset, 0)
+	}
+	end := min(offset+visible, len(lines))
+
+	var b strings.Builder
+	b.WriteString(header + "\n\n")
+	for _, line := range lines[offset:end] {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n" + dim.Render(fmt.Sprintf("updated %s · j/k scroll · r refresh · q quit",
+		m.status.GeneratedAt.Local().Format("15:04:05"))))
+	return b.String()
+}
+
+func (m FleetModel) bodyLines() []string {
+	t := m.theme
+	online := lipgloss.NewStyle().Foreground(t.Success).Bold(true)
+	offline := lipgloss.NewStyle().Foreground(t.Error).Bold(true)
+	warn := lipgloss.NewStyle().Foreground(t.Warning)
+	dim := lipgloss.NewStyle().Foreground(t.Overlay)
+	name := lipgloss.NewStyle().Foreground(t.Text)
+
+	var lines []string
+	for _, hs := range m.status.Hosts {
+		var head string
+		if hs.Online {
+			head = online.Render("● "+hs.Name) + dim.Render(fmt.Sprintf("  %s  %dms", hs.URL, hs.LatencyMs))
+			if hs.Version != "" {
+				head += dim.Render("  v" + strings.TrimPrefix(hs.Version, "v"))
+			}
+		} else {
+			head = offline.Render("○ "+hs.Name) + dim.Render("  "+hs.URL) + "  " + warn.Render("offline: "+hs.Error)
+			if hs.LastSeen != nil {
+				head += dim.Render("  last seen " + formatAgeShort(time.Since(*hs.LastSeen)) + " ago")
+			}
+		}
+		lines = append(lines, head)
+
+		if len(hs.Sessions) == 0 {
+			lines = append(lines, dim.Render("    no sessions"))
+		}
+		for _, s := range hs.Sessions {
+			state := "live"
+			if !s.Live {
+				state = s.Status + ", not running"
+			}
+			if hs.Stale {
+				state = "stale"
+			}
+			row := fmt.Sprintf("    %s %s %2d panes  %s",
+				name.Render(fmt.Sprintf("%-24s", layout.TruncateRunes(s.Name, 24, "…"))),
+				dim.Render(fmt.Sprintf("%-22s", state)), s.Panes, fleetAgentCounts(s.Agents))
+			lines = append(lines, row)
+		}
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+func fleetSummaryLine(st *fleet.Status) string {
+	return fmt.Sprintf("%d hosts · %d online · %d offline · %d sessions · %d agents",
+		st.Summary.Hosts, st.Summary.Online, st.Summary.Offline, st.Summary.Sessions, st.Summary.Agents)
+}
+
+func fleetAgentCounts(agents []fleet.Agent) string {
+	counts := make(map[string]int)
+	for _, a := range agents {
+		counts[a.Type]++
+	}
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		parts = append(parts, fmt.Sprintf("%s×%d", t, counts[t]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// RunFleet runs the fleet view, refreshing every interval and whenever a
+// host's event stream reports a session change.
+func RunFleet(f *fleet.Fleet, interval time.Duration) error {
+	p := tea.NewProgram(NewFleetModel(f), tea.WithAltScreen())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.Watch(ctx, interval, func(st *fleet.Status) {
+		p.Send(FleetUpdateMsg{Status: st})
+	})
+
+	_, err := p.Run()
+	return err
+}
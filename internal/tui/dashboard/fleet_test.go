@@ -0,0 +1,51 @@
+package dashboard
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Dicklesworthstone/ntm/internal/fleet"
+)
+
+func TestFleetModelGroupsSessionsByHost(t *testing.T) {
+	seen := time.Now().Add(-2 * time.Minute)
+	st := &fleet.Status{
+		GeneratedAt: time.Now(),
+		Hosts: []fleet.HostStatus{
+			{
+				Name:   "web",
+				URL:    "http://web:7337",
+				Online: true,
+				Sessions: []fleet.Session{
+					{Name: "proj", Live: true, Panes: 3, Agents: []fleet.Agent{{Type: "claude"}, {Type: "claude"}, {Type: "codex"}}},
+				},
+			},
+			{
+				Name:     "gpu",
+				URL:      "http://gpu:7337",
+				Error:    "connection refused",
+				Stale:    true,
+				LastSeen: &seen,
+				Sessions: []fleet.Session{{Name: "train", Live: true}},
+			},
+		},
+		Summary: fleet.Summary{Hosts: 2, Online: 1, Offline: 1, Sessions: 2, Agents: 3},
+	}
+
+	var m tea.Model = NewFleetModel(nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.Update(FleetUpdateMsg{Status: st})
+	view := m.View()
+
+	for _, want := range []string{"2 hosts", "web", "proj", "claude×2 codex×1", "gpu", "offline: connection refused", "last seen 2m ago", "train", "stale"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Index(view, "proj") > strings.Index(view, "gpu") {
+		t.Error("web's sessions should be listed under web, before gpu")
+	}
+}
@@ -419,6 +419,8 @@ func activityLabelAndColor(state string, t theme.Theme) (string, lipgloss.Color)
 		return "CMP", t.Peach
 	case "rate_limited":
 		return "RATE", t.Maroon
+	case "awaiting_approval":
+		return "APRV", t.Mauve
 	default:
 		return "UNK", t.Overlay
 	}
@@ -571,6 +573,9 @@ func RenderPaneRow(row PaneTableRow, dims LayoutDimensions, t theme.Theme) strin
 		case "rate_limited":
 			statusIcon = "⏳"
 			statusStyle = statusStyle.Foreground(t.Maroon).Bold(true)
+		case "awaiting_approval":
+			statusIcon = "⏸"
+			statusStyle = statusStyle.Foreground(t.Mauve).Bold(true)
 		default:
 			statusIcon = "•"
 			statusStyle = statusStyle.Foreground(t.Overlay)
@@ -794,6 +799,11 @@ func RenderPaneDetail(pane tmux.Pane, ps PaneStatus, dims LayoutDimensions, t th
 		lines = append(lines, labelStyle.Render("Model:")+variantBadge)
 	}
 
+	// Queued prompts waiting for idle
+	if ps.QueuedPrompts > 0 {
+		lines = append(lines, labelStyle.Render("Queued:")+valueStyle.Render(fmt.Sprintf("%d prompt(s)", ps.QueuedPrompts)))
+	}
+
 	lines = append(lines, "")
 
 	// Context usage section
@@ -938,6 +948,8 @@ func getStatusIconAndColor(state string, t theme.Theme, tick int) (string, lipgl
 		return "✗", t.Red
 	case "compacted":
 		return "⚠", t.Peach
+	case "awaiting_approval":
+		return "⏸", t.Mauve
 	default:
 		return "•", t.Overlay
 	}
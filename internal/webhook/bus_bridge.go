@@ -313,3 +313,11 @@ func (b *BusBridge) Close() error {
 	}
 	return nil
 }
+
+// Manager returns the bridge's webhook manager.
+func (b *BusBridge) Manager() *WebhookManager {
+	if b == nil {
+		return nil
+	}
+	return b.manager
+}
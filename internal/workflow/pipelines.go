@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Built-in pipeline workflows are reusable step blocks that pipeline files can
+// invoke with 'uses: <name>'. They use the pipeline schema, not the
+// coordination template schema of the other builtins.
+//
+//go:embed builtins/pipelines/*.yaml
+var builtinPipelineFS embed.FS
+
+const builtinPipelineDir = "builtins/pipelines"
+
+// BuiltinPipeline returns the source of the named built-in pipeline workflow.
+func BuiltinPipeline(name string) ([]byte, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, false
+	}
+	data, err := builtinPipelineFS.ReadFile(path.Join(builtinPipelineDir, name+".yaml"))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// BuiltinPipelineNames lists the built-in pipeline workflows.
+func BuiltinPipelineNames() []string {
+	entries, err := builtinPipelineFS.ReadDir(builtinPipelineDir)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".yaml"); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,29 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuiltinPipelines(t *testing.T) {
+	names := BuiltinPipelineNames()
+	if len(names) == 0 {
+		t.Fatal("expected built-in pipelines")
+	}
+	for _, name := range names {
+		data, ok := BuiltinPipeline(name)
+		if !ok {
+			t.Errorf("BuiltinPipeline(%q) not found", name)
+			continue
+		}
+		if !strings.Contains(string(data), "name: "+name+"\n") {
+			t.Errorf("built-in pipeline %s does not declare name %q", name, name)
+		}
+	}
+
+	for _, name := range []string{"", "missing", "../loader"} {
+		if _, ok := BuiltinPipeline(name); ok {
+			t.Errorf("BuiltinPipeline(%q) should not be found", name)
+		}
+	}
+}
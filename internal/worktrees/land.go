@@ -0,0 +1,223 @@
+package worktrees
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LandOutcome is the result of landing an agent branch.
+type LandOutcome string
+
+const (
+	LandMerged      LandOutcome = "merged"       // Target fast-forwarded to the rebased branch
+	LandConflict    LandOutcome = "conflict"     // Rebase onto the target conflicted
+	LandTestsFailed LandOutcome = "tests_failed" // Rebased cleanly but the test command failed
+)
+
+// maxLandAttempts bounds how often Land restarts when the target branch
+// moves while the branch is being rebased and tested.
+const maxLandAttempts = 3
+
+// maxLandOutput is how much of the test output is kept, from the end.
+const maxLandOutput = 8 * 1024
+
+// landWaitDelay bounds how long Land waits for the test command's output
+// after it has been killed, in case a child process still holds the pipe.
+const landWaitDelay = 5 * time.Second
+
+// LandOptions configures Land.
+type LandOptions struct {
+	Target      string        // Branch to fast-forward ("" = main, or master)
+	TestCommand string        // Shell command run in the rebased tree; empty skips testing
+	TestTimeout time.Duration // Treat the tests as failed after this long (0 = no limit)
+}
+
+// LandResult describes one Land run.
+type LandResult struct {
+	Outcome   LandOutcome `json:"outcome"`
+	Branch    string      `json:"branch"`
+	Target    string      `json:"target"`
+	Base      string      `json:"base"`             // Target commit the branch was rebased onto
+	Commit    string      `json:"commit,omitempty"` // New target head when merged
+	Conflicts []string    `json:"conflicts,omitempty"`
+	Output    string      `json:"output,omitempty"` // Tail of the test output
+}
+
+// Land rebases an agent's branch onto the current target in a scratch
+// worktree, runs the test command there, and fast-forwards the target only
+// when the tests pass. The user's checkout and the agent's worktree are never
+// modified; conflicts and test failures are reported in the result rather
+// than as errors.
+func (m *WorktreeManager) Land(agentName string, opts LandOptions) (*LandResult, error) {
+	branchName := fmt.Sprintf("ntm/%s/%s", m.session, agentName)
+	if _, err := m.git("rev-parse", "--verify", "--quiet", "refs/heads/"+branchName); err != nil {
+		return nil, fmt.Errorf("branch %s does not exist", branchName)
+	}
+
+	target := opts.Target
+	if target == "" {
+		var err error
+		if target, err = m.DefaultTarget(); err != nil {
+			return nil, err
+		}
+	}
+	if path, err := m.checkedOutAt(target); err != nil {
+		return nil, err
+	} else if path != "" {
+		return nil, fmt.Errorf("target branch %s is checked out at %s; the merge queue never updates a checked-out branch (run 'git switch --detach' there or use another target)", target, path)
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err := m.landOnce(branchName, target, opts)
+		if !errors.Is(err, errTargetMoved) || attempt == maxLandAttempts {
+			return result, err
+		}
+	}
+}
+
+// errTargetMoved means the target advanced between rebasing and the
+// fast-forward, so the branch must be rebased and tested again.
+var errTargetMoved = errors.New("target branch moved during landing")
+
+func (m *WorktreeManager) landOnce(branchName, target string, opts LandOptions) (*LandResult, error) {
+	result := &LandResult{Branch: branchName, Target: target}
+
+	base, err := m.git("rev-parse", "--verify", "refs/heads/"+target)
+	if err != nil {
+		return nil, fmt.Errorf("target branch %s not found: %w", target, err)
+	}
+	result.Base = base
+
+	scratch := filepath.Join(m.projectPath, ".ntm", "merge-queue", fmt.Sprintf("%s-%d", filepath.Base(branchName), time.Now().UnixNano()))
+	if err := os.MkdirAll(filepath.Dir(scratch), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create merge queue directory: %w", err)
+	}
+	if _, err := m.git("worktree", "add", "--detach", scratch, branchName); err != nil {
+		return nil, fmt.Errorf("failed to create scratch worktree: %w", err)
+	}
+	defer m.removeScratch(scratch)
+
+	if _, err := gitIn(scratch, "rebase", base); err != nil {
+		conflicts, _ := gitIn(scratch, "diff", "--name-only", "--diff-filter=U")
+		_, _ = gitIn(scratch, "rebase", "--abort")
+		result.Outcome = LandConflict
+		if conflicts != "" {
+			result.Conflicts = strings.Split(conflicts, "\n")
+		}
+		return result, nil
+	}
+
+	head, err := gitIn(scratch, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.TestCommand != "" && head != base {
+		output, passed := runLandTests(scratch, opts.TestCommand, opts.TestTimeout)
+		result.Output = output
+		if !passed {
+			result.Outcome = LandTestsFailed
+			return result, nil
+		}
+	}
+
+	// Compare-and-swap: fails if the target moved since it was read.
+	if head != base {
+		if _, err := m.git("update-ref", "refs/heads/"+target, head, base); err != nil {
+			if current, _ := m.git("rev-parse", "refs/heads/"+target); current != base {
+				return result, errTargetMoved
+			}
+			return nil, fmt.Errorf("failed to fast-forward %s: %w", target, err)
+		}
+	}
+
+	result.Outcome = LandMerged
+	result.Commit = head
+	return result, nil
+}
+
+// DefaultTarget returns "main" if the repository has that branch, else "master".
+func (m *WorktreeManager) DefaultTarget() (string, error) {
+	for _, name := range []string{"main", "master"} {
+		if _, err := m.git("rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no main or master branch found; set a target branch")
+}
+
+// checkedOutAt returns the path of the worktree that has branch checked out,
+// or "" if none does.
+func (m *WorktreeManager) checkedOutAt(branch string) (string, error) {
+	out, err := m.git("worktree", "list", "--porcelain")
+	if err != nil {
+		return "", err
+	}
+	var path string
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			path = strings.TrimPrefix(line, "worktree ")
+		case line == "branch refs/heads/"+branch:
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// removeScratch deletes a scratch worktree created by landOnce.
+func (m *WorktreeManager) removeScratch(path string) {
+	if _, err := m.git("worktree", "remove", "--force", path); err != nil {
+		_ = os.RemoveAll(path)
+		_, _ = m.git("worktree", "prune")
+	}
+}
+
+// git runs a git command in the project and returns its trimmed stdout.
+func (m *WorktreeManager) git(args ...string) (string, error) {
+	return gitIn(m.projectPath, args...)
+}
+
+func gitIn(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// runLandTests runs the test command in dir and returns the tail of its
+// combined output and whether it passed.
+func runLandTests(dir, command string, timeout time.Duration) (string, bool) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	setLandProcAttr(cmd)
+	cmd.WaitDelay = landWaitDelay
+	out, err := cmd.CombinedOutput()
+	if len(out) > maxLandOutput {
+		out = out[len(out)-maxLandOutput:]
+	}
+	output := string(out)
+	if ctx.Err() == context.DeadlineExceeded {
+		output += fmt.Sprintf("\n(test command timed out after %s)", timeout)
+	}
+	return output, err == nil
+}
@@ -0,0 +1,148 @@
+package worktrees
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := gitIn(dir, args...)
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return out
+}
+
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", name)
+	runGit(t, dir, "commit", "-m", "edit "+name)
+}
+
+// setupLandRepo creates a repo whose target branch is not checked out, with
+// an agent worktree on its own branch.
+func setupLandRepo(t *testing.T) (*WorktreeManager, string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := setupWorktreeGitRepo(t)
+	runGit(t, repo, "branch", "-M", "main")
+	commitFile(t, repo, "shared.txt", "one\n")
+	runGit(t, repo, "checkout", "--detach")
+
+	manager := NewManager(repo, "proj")
+	info, err := manager.CreateForAgent("cc_1")
+	if err != nil {
+		t.Fatalf("CreateForAgent: %v", err)
+	}
+	return manager, repo, info.Path
+}
+
+func TestLand_RebasesTestsAndFastForwards(t *testing.T) {
+	manager, repo, agentDir := setupLandRepo(t)
+	userHead := runGit(t, repo, "rev-parse", "HEAD")
+
+	commitFile(t, agentDir, "agent.txt", "agent work\n")
+	agentHead := runGit(t, agentDir, "rev-parse", "HEAD")
+
+	// The target moves on independently.
+	other := filepath.Join(t.TempDir(), "other")
+	runGit(t, repo, "worktree", "add", other, "main")
+	commitFile(t, other, "other.txt", "other work\n")
+	runGit(t, repo, "worktree", "remove", other)
+
+	result, err := manager.Land("cc_1", LandOptions{TestCommand: "test -f agent.txt && test -f other.txt && echo ok"})
+	if err != nil {
+		t.Fatalf("Land: %v", err)
+	}
+	if result.Outcome != LandMerged || result.Target != "main" || !strings.Contains(result.Output, "ok") {
+		t.Fatalf("result = %+v", result)
+	}
+	if got := runGit(t, repo, "rev-parse", "main"); got != result.Commit {
+		t.Errorf("main = %s, want %s", got, result.Commit)
+	}
+	runGit(t, repo, "merge-base", "--is-ancestor", result.Base, "main")
+
+	// Neither the user's checkout nor the agent's worktree changed.
+	if got := runGit(t, repo, "rev-parse", "HEAD"); got != userHead {
+		t.Errorf("user checkout moved to %s", got)
+	}
+	if got := runGit(t, agentDir, "rev-parse", "HEAD"); got != agentHead {
+		t.Errorf("agent worktree moved to %s", got)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(repo, ".ntm", "merge-queue")); len(entries) != 0 {
+		t.Errorf("scratch worktrees left behind: %v", entries)
+	}
+}
+
+func TestLand_ConflictAndFailingTestsLeaveTarget(t *testing.T) {
+	manager, repo, agentDir := setupLandRepo(t)
+	commitFile(t, agentDir, "agent.txt", "agent work\n")
+	before := runGit(t, repo, "rev-parse", "main")
+
+	result, err := manager.Land("cc_1", LandOptions{TestCommand: "echo broken; exit 1"})
+	if err != nil {
+		t.Fatalf("Land: %v", err)
+	}
+	if result.Outcome != LandTestsFailed || !strings.Contains(result.Output, "broken") {
+		t.Errorf("result = %+v, want tests_failed", result)
+	}
+	if got := runGit(t, repo, "rev-parse", "main"); got != before {
+		t.Error("main moved despite failing tests")
+	}
+
+	commitFile(t, agentDir, "shared.txt", "agent\n")
+	other := filepath.Join(t.TempDir(), "other")
+	runGit(t, repo, "worktree", "add", other, "main")
+	commitFile(t, other, "shared.txt", "target\n")
+	runGit(t, repo, "worktree", "remove", other)
+	before = runGit(t, repo, "rev-parse", "main")
+
+	result, err = manager.Land("cc_1", LandOptions{})
+	if err != nil {
+		t.Fatalf("Land: %v", err)
+	}
+	if result.Outcome != LandConflict || len(result.Conflicts) != 1 || result.Conflicts[0] != "shared.txt" {
+		t.Errorf("result = %+v, want conflict on shared.txt", result)
+	}
+	if got := runGit(t, repo, "rev-parse", "main"); got != before {
+		t.Error("main moved despite the conflict")
+	}
+}
+
+func TestRunLandTests_TimeoutKillsProcessGroup(t *testing.T) {
+	start := time.Now()
+	// The background sleep keeps the output pipe open unless the whole
+	// process group is killed.
+	output, passed := runLandTests(t.TempDir(), "sleep 30 & echo started; wait", 200*time.Millisecond)
+	if passed {
+		t.Error("timed-out tests reported as passing")
+	}
+	if !strings.Contains(output, "started") || !strings.Contains(output, "timed out") {
+		t.Errorf("output = %q", output)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("runLandTests returned after %s; child processes outlived the timeout", elapsed)
+	}
+}
+
+func TestLand_RefusesCheckedOutTarget(t *testing.T) {
+	manager, repo, _ := setupLandRepo(t)
+	runGit(t, repo, "checkout", "main")
+
+	if _, err := manager.Land("cc_1", LandOptions{}); err == nil || !strings.Contains(err.Error(), "checked out") {
+		t.Errorf("Land = %v, want checked-out error", err)
+	}
+	if _, err := manager.Land("cod_9", LandOptions{}); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Land unknown agent = %v", err)
+	}
+}
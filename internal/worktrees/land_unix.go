@@ -0,0 +1,21 @@
+//go:build unix
+
+package worktrees
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setLandProcAttr runs the test command in its own process group and kills
+// the whole group on timeout, so test runners and the processes they start
+// do not outlive the merge queue entry.
+func setLandProcAttr(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		if cmd.Process == nil {
+			return nil
+		}
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package worktrees
+
+import "os/exec"
+
+// setLandProcAttr sets platform-specific process attributes for test commands.
+// On Windows, the default exec.CommandContext kill behavior is used.
+func setLandProcAttr(cmd *exec.Cmd) {}